@@ -0,0 +1,446 @@
+// Command detectorcatalog generates pkg/detectors/catalog/catalog_gen.go from
+// the sources of the built-in detectors. For every detector it records
+// whether FromData verifies results, the URLs its package calls, and the
+// masked secrets it finds in the inputs of its unit tests.
+//
+// Run it with go generate from the root of the repository:
+//
+//	go generate ./pkg/detectors/catalog
+//
+// With -check, it fails instead if the catalog is out of date, without
+// writing it.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+)
+
+const (
+	detectorsDir = "pkg/detectors"
+	outputPath   = "pkg/detectors/catalog/catalog_gen.go"
+	maxEndpoints = 5
+	maxExamples  = 3
+)
+
+var (
+	// urlPat matches the URL literals of a package, up to the first
+	// formatting verb or template placeholder.
+	urlPat = regexp.MustCompile(`^https?://[A-Za-z0-9.\-]+(?::[0-9]+)?[^%{<\s"'\\]*`)
+	// regexMeta is contained by the URL literals that are patterns rather
+	// than endpoints.
+	regexMeta = regexp.MustCompile(`[\\\[\]()*+?|^$]`)
+)
+
+// pkgInfo is what the sources of a detector package tell about it.
+type pkgInfo struct {
+	id        config.DetectorID
+	verifies  bool
+	endpoints []string
+	inputs    []string
+}
+
+func main() {
+	log.SetFlags(log.Lmsgprefix)
+	log.SetPrefix("[detectorcatalog] ")
+	// pkg/common imports depaware, which defines -check on the default flag set.
+	flags := flag.NewFlagSet("detectorcatalog", flag.ExitOnError)
+	check := flags.Bool("check", false, "fail if the catalog is out of date instead of writing it")
+	_ = flags.Parse(os.Args[1:])
+
+	if err := os.Chdir(repoRoot()); err != nil {
+		log.Fatal(err)
+	}
+	pkgs, err := parsePackages()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	byID := make(map[config.DetectorID]detectors.Detector)
+	for _, d := range engine.DefaultDetectors() {
+		byID[config.GetDetectorID(d)] = d
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by hack/detectorcatalog; DO NOT EDIT.\n\npackage catalog\n\n")
+	buf.WriteString("import (\n\t\"github.com/trufflesecurity/trufflehog/v3/pkg/config\"\n")
+	buf.WriteString("\t\"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb\"\n)\n\n")
+	buf.WriteString("var generated = map[config.DetectorID]entry{\n")
+	for _, p := range pkgs {
+		d, ok := byID[p.id]
+		if !ok {
+			continue
+		}
+		// Only the first package of a detector is in the catalog.
+		delete(byID, p.id)
+		fmt.Fprintf(&buf, "\t{ID: detectorspb.DetectorType_%s, Version: %d}: {\n", p.id.ID, p.id.Version)
+		if p.verifies {
+			buf.WriteString("\t\tverifies: true,\n")
+		}
+		writeStrings(&buf, "endpoints", p.endpoints)
+		writeStrings(&buf, "examples", examples(d, p.inputs))
+		buf.WriteString("\t},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		current, err := os.ReadFile(outputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !bytes.Equal(current, src) {
+			log.Fatalf("%s is out of date, run go generate ./pkg/detectors/catalog", outputPath)
+		}
+		log.Printf("%s is up to date", outputPath)
+		return
+	}
+	if err := os.WriteFile(outputPath, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s", outputPath)
+}
+
+func repoRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			log.Fatal("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+func writeStrings(buf *bytes.Buffer, field string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "\t\t%s: []string{", field)
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.Quote(v))
+	}
+	buf.WriteString("},\n")
+}
+
+// parsePackages parses every detector package, sorted by detector.
+func parsePackages() ([]pkgInfo, error) {
+	var pkgs []pkgInfo
+	err := filepath.WalkDir(detectorsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		info, ok, err := parsePackage(path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			pkgs = append(pkgs, info)
+		}
+		return nil
+	})
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].id.ID != pkgs[j].id.ID {
+			return pkgs[i].id.ID < pkgs[j].id.ID
+		}
+		return pkgs[i].id.Version < pkgs[j].id.Version
+	})
+	return pkgs, err
+}
+
+// parsePackage parses the detector package in dir. It returns false if the
+// directory doesn't hold a detector.
+func parsePackage(dir string) (pkgInfo, bool, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return pkgInfo{}, false, err
+	}
+
+	var sources, tests []*ast.File
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_integration_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return pkgInfo{}, false, err
+		}
+		if strings.HasSuffix(path, "_test.go") {
+			tests = append(tests, file)
+		} else {
+			sources = append(sources, file)
+		}
+	}
+
+	var (
+		info     pkgInfo
+		found    bool
+		consts   = stringConsts(sources...)
+		patterns []string
+	)
+	for _, file := range sources {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil {
+				continue
+			}
+			switch fn.Name.Name {
+			case "Type":
+				if name, ok := returnedDetectorType(fn); ok {
+					id, err := config.ParseDetector(name)
+					if err != nil {
+						return pkgInfo{}, false, err
+					}
+					info.id.ID = id.ID
+					found = true
+				}
+			case "Version":
+				if v, ok := returnedInt(fn); ok {
+					info.id.Version = v
+				}
+			case "FromData":
+				info.verifies = info.verifies || usesVerify(fn)
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BasicLit:
+				if s, ok := stringValue(n); ok {
+					info.endpoints = addEndpoint(info.endpoints, s)
+				}
+			case *ast.CallExpr:
+				if sel, ok := n.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "MustCompile" && len(n.Args) == 1 {
+					if pattern, ok := evalString(n.Args[0], consts); ok {
+						patterns = append(patterns, pattern)
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// Most detectors only report secrets next to their other parts, so the
+	// samples of every pattern of the package make up a single input.
+	if sample := samplePatterns(patterns, info.id.ID.String()); sample != "" {
+		info.inputs = append(info.inputs, sample)
+	}
+	for _, file := range tests {
+		info.inputs = append(info.inputs, testInputs(file)...)
+	}
+	return info, found, nil
+}
+
+// returnedDetectorType returns X of a method returning detectorspb.DetectorType_X.
+func returnedDetectorType(fn *ast.FuncDecl) (string, bool) {
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		if sel, ok := ret.Results[0].(*ast.SelectorExpr); ok {
+			if name, ok := strings.CutPrefix(sel.Sel.Name, "DetectorType_"); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+func returnedInt(fn *ast.FuncDecl) (int, bool) {
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == token.INT {
+			v, err := strconv.Atoi(lit.Value)
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
+
+// usesVerify reports whether a FromData method reads its verify parameter.
+func usesVerify(fn *ast.FuncDecl) bool {
+	params := fn.Type.Params.List
+	if len(params) < 2 {
+		return false
+	}
+	// The parameters are (ctx context.Context, verify bool, data []byte),
+	// possibly with some of the names grouped.
+	var names []*ast.Ident
+	for _, field := range params {
+		names = append(names, field.Names...)
+	}
+	if len(names) < 2 || names[1].Name == "_" {
+		return false
+	}
+	verify := names[1].Name
+	used := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == verify {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+func addEndpoint(endpoints []string, s string) []string {
+	if len(endpoints) >= maxEndpoints {
+		return endpoints
+	}
+	url := urlPat.FindString(s)
+	if url == "" || regexMeta.MatchString(s) || strings.Contains(url, "example.com") || strings.Contains(url, "howtorotate.com") {
+		return endpoints
+	}
+	if slices.Contains(endpoints, url) {
+		return endpoints
+	}
+	return append(endpoints, url)
+}
+
+// testInputs returns the string literals of a test file, along with the
+// fmt.Sprintf calls of the file formatting its string constants, which is
+// how most detector tests build their inputs.
+func testInputs(file *ast.File) []string {
+	consts := stringConsts(file)
+
+	var inputs []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BasicLit:
+			if s, ok := stringValue(n); ok && len(s) >= 8 {
+				inputs = append(inputs, s)
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Sprintf" || len(n.Args) < 2 {
+				return true
+			}
+			format, ok := stringValue(n.Args[0])
+			if !ok {
+				return true
+			}
+			args := make([]any, 0, len(n.Args)-1)
+			for _, arg := range n.Args[1:] {
+				id, ok := arg.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				v, ok := consts[id.Name]
+				if !ok {
+					return true
+				}
+				args = append(args, v)
+			}
+			inputs = append(inputs, fmt.Sprintf(format, args...))
+		}
+		return true
+	})
+	return inputs
+}
+
+// stringConsts returns the string literal constants and variables declared
+// at the top level of files.
+func stringConsts(files ...*ast.File) map[string]string {
+	consts := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						if s, ok := stringValue(vs.Values[i]); ok {
+							consts[name.Name] = s
+						}
+					}
+				}
+			}
+		}
+	}
+	return consts
+}
+
+func stringValue(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// examples returns the masked secrets d finds in inputs, without verifying
+// them. Detectors report results in no particular order, so the examples
+// are sorted to keep the catalog reproducible.
+func examples(d detectors.Detector, inputs []string) []string {
+	var found []string
+	for _, input := range inputs {
+		for _, r := range fromData(d, input) {
+			example := r.Redacted
+			if example == "" {
+				example = output.MaskSecret(strings.TrimSpace(string(r.Raw)))
+			}
+			if example != "" {
+				found = append(found, example)
+			}
+		}
+	}
+	slices.Sort(found)
+	found = slices.Compact(found)
+	return found[:min(len(found), maxExamples)]
+}
+
+func fromData(d detectors.Detector, input string) (results []detectors.Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			results = nil
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	results, _ = d.FromData(ctx, false, []byte(input))
+	return results
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"hash/fnv"
+	"math/rand/v2"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// evalString evaluates the argument of a regexp.MustCompile call, as far as
+// it is made of string literals, constants, concatenations, and calls of
+// detectors.PrefixRegex with literal keywords.
+func evalString(expr ast.Expr, consts map[string]string) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return stringValue(e)
+	case *ast.Ident:
+		s, ok := consts[e.Name]
+		return s, ok
+	case *ast.ParenExpr:
+		return evalString(e.X, consts)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := evalString(e.X, consts)
+		if !ok {
+			return "", false
+		}
+		y, ok := evalString(e.Y, consts)
+		return x + y, ok
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "PrefixRegex" || len(e.Args) != 1 {
+			return "", false
+		}
+		list, ok := e.Args[0].(*ast.CompositeLit)
+		if !ok {
+			return "", false
+		}
+		var keywords []string
+		for _, elt := range list.Elts {
+			s, ok := evalString(elt, consts)
+			if !ok {
+				return "", false
+			}
+			keywords = append(keywords, s)
+		}
+		return detectors.PrefixRegex(keywords), true
+	}
+	return "", false
+}
+
+// samplePatterns returns a line matching each of patterns. The random parts
+// of the samples are seeded with seed, so the catalog is reproducible.
+func samplePatterns(patterns []string, seed string) string {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	g := &sampler{rng: rand.New(rand.NewPCG(h.Sum64(), 0))}
+
+	var b strings.Builder
+	for _, pattern := range patterns {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			continue
+		}
+		g.write(&b, re.Simplify())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+type sampler struct {
+	rng *rand.Rand
+}
+
+func (g *sampler) write(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(g.pick(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(g.pick([]rune{'a', 'z', '0', '9'}))
+	case syntax.OpCapture, syntax.OpPlus:
+		g.write(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for range re.Min {
+			g.write(b, re.Sub[0])
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.write(b, sub)
+		}
+	case syntax.OpAlternate:
+		g.write(b, re.Sub[0])
+	case syntax.OpWordBoundary:
+		// Patterns often put a boundary between a keyword and the secret,
+		// which only holds with a separator.
+		if s := b.String(); s != "" && isWordChar(s[len(s)-1]) {
+			b.WriteByte(' ')
+		}
+	}
+	// Empty matches, other assertions, and optional or starred expressions
+	// don't need any text.
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c < unicode.MaxASCII && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+// pick returns a random rune of the ranges of a character class,
+// preferring letters and digits, since separators in secrets are rare.
+func (g *sampler) pick(ranges []rune) rune {
+	var alnum, all []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo := ranges[i]
+		hi := min(ranges[i+1], lo+255)
+		for r := lo; r <= hi; r++ {
+			if r > unicode.MaxASCII {
+				continue
+			}
+			all = append(all, r)
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				alnum = append(alnum, r)
+			}
+		}
+	}
+	switch {
+	case len(alnum) > 0:
+		return alnum[g.rng.IntN(len(alnum))]
+	case len(all) > 0:
+		return all[g.rng.IntN(len(all))]
+	default:
+		return 'a'
+	}
+}
@@ -1,37 +1,75 @@
 package main
 
 import (
+	"bufio"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
-	_ "net/http/pprof"
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
-	"github.com/felixge/fgprof"
+	"github.com/dustin/go-humanize"
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 	"github.com/jpillora/overseer"
 	"github.com/mattn/go-isatty"
 	"go.uber.org/automaxprocs/maxprocs"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/analyzer"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/bench"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/blame"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/checkpoint"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/cleantemp"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/breachedpassword"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/catalog"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/credentialpair"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/diagnostics"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/distributed"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/envelope"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/exposure"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/managedsecrets"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ocr"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/operator"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ownership"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plugin"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/receiver"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/remediation"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reverify"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/runmanifest"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/scoring"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/server"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tracing"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/trigger"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/tui"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/triage"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/updater"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
 )
@@ -41,34 +79,143 @@ var (
 	cmd                 string
 	debug               = cli.Flag("debug", "Run in debug mode.").Bool()
 	trace               = cli.Flag("trace", "Run in trace mode.").Bool()
+	logLevel            = cli.Flag("log-level", "Log verbosity from 0 (info) to 5 (trace). Takes precedence over --debug and --trace.").PlaceHolder("0-5").IsSetByUser(&logLevelSet).Int8()
+	logFormatFlag       = cli.Flag("log-format", "Log format: console or json. Defaults to json when results are output as JSON.").Default(logFormatAuto).Enum(logFormatAuto, logFormatConsole, logFormatJSON)
+	otelEndpoint        = cli.Flag("otel-endpoint", "Export OpenTelemetry traces of the scan stages to this OTLP/HTTP collector URL.").Envar("OTEL_EXPORTER_OTLP_ENDPOINT").String()
 	profile             = cli.Flag("profile", "Enables profiling and sets a pprof and fgprof server on :18066.").Bool()
+	pprofAddr           = cli.Flag("pprof-addr", "Serve pprof and fgprof profiles under /debug/ on this address (eg. localhost:6060).").PlaceHolder("ADDR").String()
+	runtimeStats        = cli.Flag("runtime-stats-interval", "Log goroutine, heap, and GC statistics at this interval. 0 disables it.").Default("0s").Duration()
 	localDev            = cli.Flag("local-dev", "Hidden feature to disable overseer for local dev.").Hidden().Bool()
 	jsonOut             = cli.Flag("json", "Output in JSON format.").Short('j').Bool()
 	jsonLegacy          = cli.Flag("json-legacy", "Use the pre-v3.0 JSON format. Only works with git, gitlab, and github sources.").Bool()
 	gitHubActionsFormat = cli.Flag("github-actions", "Output in GitHub Actions format.").Bool()
+	dedupe              = cli.Flag("dedupe", "Collapse findings of the same secret in the same file into one finding with a list of occurrences.").Bool()
+	incidents           = cli.Flag("incidents", "Print findings of the same secret across files, repositories, and sources as one incident listing every location, once the scan has finished. Only works with the plain and json formats.").Bool()
+	incidentPrefix      = cli.Flag("incident-prefix-length", "With --incidents, also cluster secrets of the same detector that share their first N characters, such as rotated secrets of the same ID.").PlaceHolder("N").Int()
+	showSecrets         = cli.Flag("show-secrets", "Show raw secret values in output and logs instead of masking them.").Bool()
+	contextLines        = cli.Flag("context-lines", "Number of lines before and after the line of each secret to include in findings. Secrets in these lines are masked unless --show-secrets is set.").Default("0").Int()
+	outputFormat        = cli.Flag("format", "Output format: plain, json, json-legacy, github-actions, sarif, junit, csv, or template.").Default(formatPlain).IsSetByUser(&outputFormatSet).Enum(outputFormats...)
+	templateFile        = cli.Flag("template-file", "Go template used to render each result with --format template.").ExistingFile()
+	triageMode          = cli.Flag("triage", "Review findings in an interactive terminal UI as they are found instead of printing them.").Bool()
+	triageIgnoreFile    = cli.Flag("triage-ignore-file", "Append the fingerprints of the findings marked as false positives with --triage to this ignore file.").Default(ignorefile.Name).String()
+	triageOutput        = cli.Flag("triage-output", "Write the --triage decisions as JSON to this path.").PlaceHolder("PATH").String()
 	concurrency         = cli.Flag("concurrency", "Number of concurrent workers.").Default(strconv.Itoa(runtime.NumCPU())).Int()
 	noVerification      = cli.Flag("no-verification", "Don't verify the results.").Bool()
 	onlyVerified        = cli.Flag("only-verified", "Only output verified results.").Bool()
-	results             = cli.Flag("results", "Specifies which type(s) of results to output: verified, unknown, unverified. Defaults to all types.").Hidden().String()
+	results             = cli.Flag("results", "Specifies which type(s) of results to output: verified, unknown, unverified. Defaults to all types.").Hidden().IsSetByUser(&resultsSet).String()
 
 	allowVerificationOverlap   = cli.Flag("allow-verification-overlap", "Allow verification of similar credentials across detectors").Bool()
 	filterUnverified           = cli.Flag("filter-unverified", "Only output first unverified result per chunk per detector if there are more than one results.").Bool()
 	filterEntropy              = cli.Flag("filter-entropy", "Filter unverified results with Shannon entropy. Start with 3.0.").Float64()
+	classifyFiles              = cli.Flag("classify-files", "Tag findings with the categories of the files they are in (test, minified, lockfile, vendored, generated), lowering their severity.").Bool()
+	skipFileCategories         = cli.Flag("skip-file-categories", "Don't scan files of these comma-separated categories: test, minified, lockfile, vendored, generated. Implies --classify-files.").PlaceHolder("CATEGORIES").String()
+	scorerFlag                 = cli.Flag("scorer", "Score secrets before verifying them with this HTTP endpoint or executable, such as a false positive model, dropping those scoring below --score-threshold.").PlaceHolder("URL|PATH").String()
+	scoreThreshold             = cli.Flag("score-threshold", "Minimum score, from 0 to 1, of the secrets kept by --scorer.").Default("0.5").Float64()
 	scanEntireChunk            = cli.Flag("scan-entire-chunk", "Scan the entire chunk for secrets.").Hidden().Default("false").Bool()
 	compareDetectionStrategies = cli.Flag("compare-detection-strategies", "Compare different detection strategies for matching spans").Hidden().Default("false").Bool()
 	configFilename             = cli.Flag("config", "Path to configuration file.").ExistingFile()
+	detectorPlugins            = cli.Flag("detector-plugin", "Run the detectors of this external plugin executable alongside the built-in ones. Can be repeated.").PlaceHolder("PATH").ExistingFiles()
+	breachCorpus               = cli.Flag("breach-corpus", "Report the passwords and tokens assigned in scanned files whose SHA-1 or SHA-256 hash is listed in this file of hex hashes, one per line. Can be repeated.").PlaceHolder("PATH").ExistingFiles()
+	credentialKeywords         = cli.Flag("credential-keyword", "Report the usernames and passwords assigned near this keyword, such as the name of an internal system. Can be repeated.").PlaceHolder("KEYWORD").Strings()
+	credentialWordlist         = cli.Flag("credential-wordlist", "Report the usernames and passwords assigned near the keywords of this file, one per line, like --credential-keyword. Can be repeated.").PlaceHolder("PATH").ExistingFiles()
+	credentialWindow           = cli.Flag("credential-window", "Number of characters before and after a --credential-keyword the username and password are looked for in.").Default(strconv.Itoa(credentialpair.DefaultWindow)).Int()
+	verifyURLPasswords         = cli.Flag("verify-url-passwords", "Verify the passwords found in URLs by logging into their FTP, SMTP, Redis, AMQP, PostgreSQL, MongoDB and HTTP hosts.").Bool()
+	breachHIBP                 = cli.Flag("breach-hibp", "Report the passwords and tokens assigned in scanned files that Have I Been Pwned's Pwned Passwords lists, sending it the first 5 characters of their SHA-1 hashes. Only queried with verification.").Bool()
 	// rules = cli.Flag("rules", "Path to file with custom rules.").String()
 	printAvgDetectorTime = cli.Flag("print-avg-detector-time", "Print the average time spent on each detector.").Bool()
 	noUpdate             = cli.Flag("no-update", "Don't check for updates.").Bool()
-	fail                 = cli.Flag("fail", "Exit with code 183 if results are found.").Bool()
+	fail                 = cli.Flag("fail", "Exit with code 183 if results are found, or 184 if the scan encountered errors.").Bool()
+	failVerified         = cli.Flag("fail-verified", "Exit with code 183 if verified results are found, or 184 if the scan encountered errors.").Bool()
+	manifestOut          = cli.Flag("manifest-out", "Write a manifest of the run, with the version, detectors, arguments, and filter files, to rerun the same scan later with --from-manifest.").PlaceHolder("PATH").String()
+	fromManifest         = cli.Flag("from-manifest", "Rerun the scan recorded by --manifest-out with the same arguments and detectors. Flags given with it override the recorded ones and supply the redacted credentials.").PlaceHolder("PATH").String()
+	policyFile           = cli.Flag("policy", "Exit with code 183 if findings or the scan summary fail the rules of this policy file, or 184 if the scan encountered errors.").PlaceHolder("PATH").ExistingFile()
 	verifiers            = cli.Flag("verifier", "Set custom verification endpoints.").StringMap()
 	customVerifiersOnly  = cli.Flag("custom-verifiers-only", "Only use custom verification endpoints.").Bool()
 	archiveMaxSize       = cli.Flag("archive-max-size", "Maximum size of archive to scan. (Byte units eg. 512B, 2KB, 4MB)").Bytes()
 	archiveMaxDepth      = cli.Flag("archive-max-depth", "Maximum depth of archive to scan.").Int()
 	archiveTimeout       = cli.Flag("archive-timeout", "Maximum time to spend extracting an archive.").Duration()
+	columnarMaxRows      = cli.Flag("columnar-max-rows", "Maximum number of rows to read from each Parquet, Avro and ORC file, sampled across its row groups, and from each table of SQLite databases. Defaults to 10000.").Int()
+	ocrFlag              = cli.Flag("ocr", "Scan the text of PNG and JPEG images, such as screenshots, recognized by this OCR executable, such as tesseract, or HTTP endpoint. Images are skipped otherwise.").PlaceHolder("PATH|URL").String()
+	qrCodes              = cli.Flag("qr-codes", "Scan the payloads of the QR codes in PNG and JPEG images, such as TOTP provisioning URIs and Wi-Fi configs.").Bool()
+	scanTimeout          = cli.Flag("timeout", "Stop the scan after this long and report what was found so far, exiting with code 130 like interrupted scans. (e.g. 30m, 2h)").Duration()
+	detectorTimeout      = cli.Flag("detector-timeout", "Maximum time each detector spends on a chunk, including verification. Detectors exceeding it are skipped for the chunk.").Default("10s").Duration()
+	repoTimeout          = cli.Flag("repo-timeout", "Maximum time to spend scanning each git repository. The scan moves on to the next repository once it's exceeded. (e.g. 10m)").Duration()
+	chunkSize            = cli.Flag("chunk-size", "Size of the chunks sources split data into. (Byte units eg. 10KB, 64KB)").Bytes()
+	chunkOverlap         = cli.Flag("chunk-overlap", "Bytes consecutive chunks share, so secrets spanning a chunk boundary are found. Raised to fit the largest secret the enabled detectors can find. (Byte units eg. 3KB, 8KB)").Bytes()
+	memoryBudget         = cli.Flag("memory-budget", "Maximum total size of the chunks being scanned or waiting to be. Sources pause when it is reached. (Byte units eg. 512MB, 2GB)").Bytes()
+	includePathRegexes   = cli.Flag("include-path-regex", "Only scan files of git, github, gitlab and filesystem sources whose path matches this regular expression, on top of --include-paths. Can be repeated.").PlaceHolder("REGEX").Strings()
+	excludePathRegexes   = cli.Flag("exclude-path-regex", "Don't scan files of git, github, gitlab and filesystem sources whose path matches this regular expression, on top of --exclude-paths. Can be repeated.").PlaceHolder("REGEX").Strings()
+	maxFileSize          = cli.Flag("max-file-size", "Skip files of git, github, gitlab and filesystem sources larger than this. For git history, the size of the diff counts. (Byte units eg. 512KB, 10MB)").Bytes()
+	includeMIMETypes     = cli.Flag("include-mime-type", "Only scan files of git, github, gitlab and filesystem sources whose content is of this MIME type, eg. text/plain or image/*. Types derived from it, like application/json from text/plain, match too. Can be repeated.").PlaceHolder("TYPE").Strings()
+	excludeMIMETypes     = cli.Flag("exclude-mime-type", "Don't scan files of git, github, gitlab and filesystem sources whose content is of this MIME type, matched like --include-mime-type. Can be repeated.").PlaceHolder("TYPE").Strings()
 	includeDetectors     = cli.Flag("include-detectors", "Comma separated list of detector types to include. Protobuf name or IDs may be used, as well as ranges.").Default("all").String()
 	excludeDetectors     = cli.Flag("exclude-detectors", "Comma separated list of detector types to exclude. Protobuf name or IDs may be used, as well as ranges. IDs defined here take precedence over the include list.").String()
 	jobReportFile        = cli.Flag("output-report", "Write a scan report to the provided path.").Hidden().OpenFile(os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	checkpointStore      = cli.Flag("checkpoint-store", "Periodically save scan progress so an interrupted scan can be resumed with --resume. Accepts a directory, sqlite:// path, or redis:// URL.").String()
+	checkpointInterval   = cli.Flag("checkpoint-interval", "How often scan progress is saved to --checkpoint-store.").Default("30s").Duration()
+	resumeRun            = cli.Flag("resume", "Resume the interrupted scan with this run ID from --checkpoint-store.").PlaceHolder("RUN-ID").String()
+	incrementalState     = cli.Flag("incremental-state", "Remember the last scanned commit of each git repository and the ETag of each bucket object in this file, and only scan what changed since on later runs.").PlaceHolder("PATH").String()
+	coordinatorListen    = cli.Flag("coordinator-listen", "Distribute the scan: enumerate the source and lease its units to workers connecting to this address instead of scanning them locally.").PlaceHolder("ADDR").String()
+	coordinatorAddr      = cli.Flag("coordinator", "Run as a worker of the coordinator at this address, scanning the units it leases out and reporting findings back to it. Workers must be started with the same scan command as the coordinator.").PlaceHolder("ADDR").String()
+	coordinatorToken     = cli.Flag("coordinator-token", "Shared secret workers authenticate to the coordinator with.").Envar("TRUFFLEHOG_COORDINATOR_TOKEN").String()
+	coordinatorTLSCert   = cli.Flag("coordinator-tls-cert", "Serve workers over TLS with this certificate.").ExistingFile()
+	coordinatorTLSKey    = cli.Flag("coordinator-tls-key", "Private key of --coordinator-tls-cert.").ExistingFile()
+	coordinatorTLS       = cli.Flag("coordinator-tls", "Connect to the coordinator over TLS.").Bool()
+	htmlReportFile       = cli.Flag("report", "Write a standalone HTML report of the findings to the provided path.").OpenFile(os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	summaryFile          = cli.Flag("summary-file", "Write a JSON summary of the scan, with finding counts per detector and verification state, to the provided path.").OpenFile(os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	groupBy              = cli.Flag("group-by", "Split --report into a report per repository or owner next to it, summarize each of them in --summary-file, send --webhook-url batches per group, and send a digest per group to chat webhooks instead of a message per finding. Grouping by owner implies --owners.").Enum(output.GroupByRepository, output.GroupByOwner)
+	webhookURLs          = cli.Flag("webhook-url", "POST findings as JSON to the provided URL. Can be repeated.").Strings()
+	webhookSecret        = cli.Flag("webhook-secret", "Secret used to sign webhook requests with HMAC-SHA256.").Envar("TRUFFLEHOG_WEBHOOK_SECRET").String()
+	webhookBatchSize     = cli.Flag("webhook-batch-size", "Number of findings to send per webhook request.").Default("1").Int()
+	slackWebhooks        = cli.Flag("slack-webhook", "Post finding notifications to a Slack incoming webhook. Prefix with high=, medium=, or low= to only send findings of at least that severity. Can be repeated.").PlaceHolder("[SEVERITY=]URL").Strings()
+	teamsWebhooks        = cli.Flag("teams-webhook", "Post finding notifications to a Microsoft Teams incoming webhook. Prefix with high=, medium=, or low= to only send findings of at least that severity. Can be repeated.").PlaceHolder("[SEVERITY=]URL").Strings()
+	githubPR             = cli.Flag("github-pr", "Report findings on a GitHub pull request with review comments and a check run.").PlaceHolder("OWNER/REPO#NUMBER").String()
+	githubPRToken        = cli.Flag("github-pr-token", "GitHub token used to report on the pull request.").Envar("GITHUB_TOKEN").String()
+	githubPREndpoint     = cli.Flag("github-pr-endpoint", "GitHub API endpoint used to report on the pull request.").Default("https://api.github.com").String()
+
+	githubCodeScanning         = cli.Flag("github-code-scanning", "Upload the verified findings to the code scanning alerts of this GitHub repository as a SARIF analysis.").PlaceHolder("OWNER/REPO").String()
+	githubCodeScanningCommit   = cli.Flag("github-code-scanning-commit", "Full SHA of the commit the code scanning analysis is for.").Envar("GITHUB_SHA").String()
+	githubCodeScanningRef      = cli.Flag("github-code-scanning-ref", "Git ref the code scanning analysis is for, such as refs/heads/main.").Envar("GITHUB_REF").String()
+	githubCodeScanningToken    = cli.Flag("github-code-scanning-token", "GitHub token with the security_events scope used to upload the code scanning analysis.").Envar("GITHUB_TOKEN").String()
+	githubCodeScanningEndpoint = cli.Flag("github-code-scanning-endpoint", "GitHub API endpoint used to upload the code scanning analysis.").Default("https://api.github.com").String()
+
+	cleanupPRs           = cli.Flag("cleanup-prs", "Open a pull request on every private GitHub repository with verified findings, replacing the secrets in its default branch with placeholders.").Bool()
+	cleanupPRReplacement = cli.Flag("cleanup-pr-replacement", "Go template of the text secrets are replaced with by cleanup pull requests.").Default(output.DefaultCleanupReplacement).String()
+	cleanupPRCanaries    = cli.Flag("cleanup-pr-canaries", "YAML file of canary tokens, by detector, that replacement templates can plant in place of the secrets.").ExistingFile()
+	cleanupPRToken       = cli.Flag("cleanup-pr-token", "GitHub token used to open the cleanup pull requests.").Envar("GITHUB_TOKEN").String()
+	cleanupPREndpoint    = cli.Flag("cleanup-pr-endpoint", "GitHub API endpoint used to open the cleanup pull requests.").Default("https://api.github.com").String()
+
+	gitlabMR             = cli.Flag("gitlab-mr", "Report findings on a GitLab merge request with discussions on the offending lines.").PlaceHolder("GROUP/PROJECT#IID").String()
+	gitlabMRToken        = cli.Flag("gitlab-mr-token", "GitLab token used to report on the merge request.").Envar("GITLAB_TOKEN").String()
+	gitlabMREndpoint     = cli.Flag("gitlab-mr-endpoint", "GitLab endpoint used to report on the merge request.").Default("https://gitlab.com").String()
+	bitbucketPR          = cli.Flag("bitbucket-pr", "Report findings on a Bitbucket Cloud pull request with comments on the offending lines.").PlaceHolder("WORKSPACE/REPO#NUMBER").String()
+	bitbucketPRToken     = cli.Flag("bitbucket-pr-token", "Bitbucket access token, or username:app-password, used to report on the pull request.").Envar("BITBUCKET_TOKEN").String()
+	databaseURL          = cli.Flag("database-url", "Record findings, scan runs, and per-repository stats in a database. Accepts postgres:// URLs or an SQLite file path.").Envar("TRUFFLEHOG_DATABASE_URL").String()
+	databaseRetention    = cli.Flag("database-retention", "Purge findings of --database-url that no run has found for this long, such as 90d, once a run finishes. 0 keeps them.").Default("0").String()
+	databaseRunRetention = cli.Flag("database-run-retention", "Purge scan runs of --database-url that started this long ago, such as 365d, once a run finishes. 0 keeps them.").Default("0").String()
+	databaseHashSecrets  = cli.Flag("database-hash-secrets", "Store SHA-256 hashes of secrets in --database-url instead of the secrets.").Bool()
+	resultsArchive       = cli.Flag("results-archive", "Archive the run's findings and a summary manifest to an object store prefix.").PlaceHolder("s3://BUCKET/PREFIX|gs://BUCKET/PREFIX").String()
+	resultsArchiveKMSKey = cli.Flag("results-archive-kms-key", "KMS key used to encrypt archived results: an SSE-KMS key for S3 or a Cloud KMS key name for GCS.").String()
+	secretsEncryptionKey = cli.Flag("secrets-encryption-key", "Encrypt the secrets stored by --database-url and --results-archive for an age recipient or an AWS KMS key, instead of masking them. Can be provided with environment variable TRUFFLEHOG_SECRETS_KEY.").PlaceHolder("age1...|awskms://KEY-ID").Envar("TRUFFLEHOG_SECRETS_KEY").String()
+	publishTo            = cli.Flag("publish", "Publish findings as JSON messages to a Kafka topic, SQS queue, or Pub/Sub topic. Can be repeated.").PlaceHolder("kafka://BROKERS/TOPIC|sqs://QUEUE-URL|pubsub://PROJECT/TOPIC").Strings()
+	syslogOutput         = cli.Flag("syslog-output", "Send findings as CEF or LEEF records to a syslog server.").PlaceHolder("udp|tcp|tls://HOST:PORT").String()
+	syslogOutputFormat   = cli.Flag("syslog-output-format", "Record format used for --syslog-output: cef or leef.").Default(output.SyslogFormatCEF).Enum(output.SyslogFormatCEF, output.SyslogFormatLEEF)
+	jiraURL              = cli.Flag("jira-url", "Open a Jira issue for every unique verified finding in the Jira instance at this URL.").String()
+	jiraProject          = cli.Flag("jira-project", "Key of the Jira project issues are opened in.").String()
+	jiraIssueType        = cli.Flag("jira-issue-type", "Type of the Jira issues opened for findings.").Default("Bug").String()
+	jiraToken            = cli.Flag("jira-token", "Jira personal access token, or email:api-token for Jira Cloud.").Envar("JIRA_TOKEN").String()
+	serviceNowURL        = cli.Flag("servicenow-url", "Open a ServiceNow incident for every unique verified finding in the instance at this URL.").String()
+	serviceNowToken      = cli.Flag("servicenow-token", "ServiceNow OAuth token, or username:password.").Envar("SERVICENOW_TOKEN").String()
+	ticketSummary        = cli.Flag("ticket-summary-template", "Go template for the summary of Jira issues and ServiceNow incidents.").Default(output.DefaultTicketSummary).String()
+	notifyRate           = cli.Flag("notify-rate-limit", "Maximum number of notifications sent to each Slack or Teams webhook per minute.").Default(strconv.Itoa(output.DefaultNotificationRate)).Int()
+	managedSecrets       = cli.Flag("managed-secrets", "Annotate findings with the secret manager entries holding the same secret, to guide rotation. Can be repeated.").PlaceHolder("vault:PATH|aws-sm:[PREFIX]|gcp-sm:projects/PROJECT").Strings()
+	attributeOwners      = cli.Flag("owners", "Attribute findings to their owners, from the CODEOWNERS file of their repository or the author of the commit that introduced them. Repositories are cloned again without credentials.").Bool()
+	ownersFile           = cli.Flag("owners-file", "YAML file mapping owners, repositories, and paths to teams, and teams to their Slack or Teams webhooks. Implies --owners.").PlaceHolder("PATH").ExistingFile()
+	blameFindings        = cli.Flag("blame", "Annotate findings in files of a git work tree, such as a checkout scanned with the filesystem source, with the commit and author that introduced their secret.").Bool()
+	trackExposure        = cli.Flag("exposure", "Annotate git findings with how long their secret has been exposed and whether the repository is public, and raise the severity of those exposed widely. Repositories are cloned again without credentials.").Bool()
+	remediate            = cli.Flag("remediate", "Run the remediation playbook of a detector for its verified findings, such as deactivating leaked AWS access keys or revoking GitHub tokens. Can be repeated.").PlaceHolder("DETECTOR").Strings()
+	remediationCommands  = cli.Flag("remediation-command", "Executable run with the finding as JSON on stdin to remediate the verified findings of a detector enabled with --remediate. Can be repeated.").PlaceHolder("DETECTOR=PATH").Strings()
+	remediationDryRun    = cli.Flag("remediation-dry-run", "Log what the remediation playbooks would do without running them.").Bool()
 
 	gitScan             = cli.Command("git", "Find credentials in git repositories.")
 	gitScanURI          = gitScan.Arg("uri", "Git repository URL. https://, file://, or ssh:// schema expected.").Required().String()
@@ -79,6 +226,7 @@ var (
 	gitScanBranch       = gitScan.Flag("branch", "Branch to scan.").String()
 	gitScanMaxDepth     = gitScan.Flag("max-depth", "Maximum depth of commits to scan.").Int()
 	gitScanBare         = gitScan.Flag("bare", "Scan bare repository (e.g. useful while using in pre-receive hooks)").Bool()
+	gitScanRemote       = gitScan.Flag("remote", "Name of the remote whose URL identifies the repository in findings. Repositories without remotes are identified by their path.").Default("origin").String()
 	_                   = gitScan.Flag("allow", "No-op flag for backwards compat.").Bool()
 	_                   = gitScan.Flag("entropy", "No-op flag for backwards compat.").Bool()
 	_                   = gitScan.Flag("regex", "No-op flag for backwards compat.").Bool()
@@ -87,7 +235,7 @@ var (
 	githubScanEndpoint   = githubScan.Flag("endpoint", "GitHub endpoint.").Default("https://api.github.com").String()
 	githubScanRepos      = githubScan.Flag("repo", `GitHub repository to scan. You can repeat this flag. Example: "https://github.com/dustin-decker/secretsandstuff"`).Strings()
 	githubScanOrgs       = githubScan.Flag("org", `GitHub organization to scan. You can repeat this flag. Example: "trufflesecurity"`).Strings()
-	githubScanToken      = githubScan.Flag("token", "GitHub token. Can be provided with environment variable GITHUB_TOKEN.").Envar("GITHUB_TOKEN").String()
+	githubScanToken      = githubScan.Flag("token", "GitHub token. You can repeat this flag to rotate between tokens as their rate limits are hit. Can be provided with environment variable GITHUB_TOKEN, one token per line.").Envar("GITHUB_TOKEN").Strings()
 	githubIncludeForks   = githubScan.Flag("include-forks", "Include forks in scan.").Bool()
 	githubIncludeMembers = githubScan.Flag("include-members", "Include organization member repositories in scan.").Bool()
 	githubIncludeRepos   = githubScan.Flag("include-repos", `Repositories to include in an org scan. This can also be a glob pattern. You can repeat this flag. Must use Github repo full name. Example: "trufflesecurity/trufflehog", "trufflesecurity/t*"`).Strings()
@@ -99,6 +247,12 @@ var (
 	githubScanIssueComments = githubScan.Flag("issue-comments", "Include issue descriptions and comments in scan.").Bool()
 	githubScanPRComments    = githubScan.Flag("pr-comments", "Include pull request descriptions and comments in scan.").Bool()
 	githubScanGistComments  = githubScan.Flag("gist-comments", "Include gist comments in scan.").Bool()
+	githubSkipArchived      = githubScan.Flag("skip-archived", "Skip archived repositories in an org or user scan.").Bool()
+	githubExcludeTopics     = githubScan.Flag("exclude-topic", "Skip repositories with this topic in an org or user scan. You can repeat this flag.").Strings()
+	githubIncludeTopics     = githubScan.Flag("include-topic", "Only scan repositories with this topic in an org or user scan. You can repeat this flag.").Strings()
+	githubLanguages         = githubScan.Flag("language", `Only scan repositories whose primary language is this in an org or user scan. You can repeat this flag. Example: "Go"`).Strings()
+	githubPushedWithin      = githubScan.Flag("pushed-within", `Only scan repositories pushed to this recently in an org or user scan. Example: "8760h"`).Duration()
+	githubMaxRepoSize       = githubScan.Flag("max-repo-size", "Only scan repositories of at most this size in an org or user scan. (Byte units eg. 512KB, 2GB)").Bytes()
 
 	// GitHub Cross Fork Object Reference Experimental Feature
 	githubExperimentalScan = cli.Command("github-experimental", "Run an experimental GitHub scan. Must specify at least one experimental sub-module to run: object-discovery.")
@@ -114,9 +268,17 @@ var (
 	// TODO: Add more GitLab options
 	gitlabScanEndpoint     = gitlabScan.Flag("endpoint", "GitLab endpoint.").Default("https://gitlab.com").String()
 	gitlabScanRepos        = gitlabScan.Flag("repo", "GitLab repo url. You can repeat this flag. Leave empty to scan all repos accessible with provided credential. Example: https://gitlab.com/org/repo.git").Strings()
-	gitlabScanToken        = gitlabScan.Flag("token", "GitLab token. Can be provided with environment variable GITLAB_TOKEN.").Envar("GITLAB_TOKEN").Required().String()
+	gitlabScanToken        = gitlabScan.Flag("token", "GitLab token. You can repeat this flag to rotate between tokens as their rate limits are hit. Can be provided with environment variable GITLAB_TOKEN, one token per line.").Envar("GITLAB_TOKEN").Required().Strings()
 	gitlabScanIncludePaths = gitlabScan.Flag("include-paths", "Path to file with newline separated regexes for files to include in scan.").Short('i').String()
 	gitlabScanExcludePaths = gitlabScan.Flag("exclude-paths", "Path to file with newline separated regexes for files to exclude in scan.").Short('x').String()
+	gitlabScanExcludeRepos = gitlabScan.Flag("exclude-repos", `Projects to skip when scanning all accessible projects. This can also be a glob pattern. You can repeat this flag. Must use the project path with its namespace. Example: "org/demo-*"`).Strings()
+	gitlabSkipArchived     = gitlabScan.Flag("skip-archived", "Skip archived projects when scanning all accessible projects.").Bool()
+	gitlabSkipForks        = gitlabScan.Flag("skip-forks", "Skip forked projects when scanning all accessible projects.").Bool()
+	gitlabExcludeTopics    = gitlabScan.Flag("exclude-topic", "Skip projects with this topic when scanning all accessible projects. You can repeat this flag.").Strings()
+	gitlabIncludeTopics    = gitlabScan.Flag("include-topic", "Only scan projects with this topic when scanning all accessible projects. You can repeat this flag.").Strings()
+	gitlabLanguages        = gitlabScan.Flag("language", `Only scan projects whose primary language is this when scanning all accessible projects. You can repeat this flag. Example: "Go"`).Strings()
+	gitlabPushedWithin     = gitlabScan.Flag("pushed-within", `Only scan projects with activity this recently when scanning all accessible projects. Example: "8760h"`).Duration()
+	gitlabMaxRepoSize      = gitlabScan.Flag("max-repo-size", "Only scan projects whose repository is at most this size when scanning all accessible projects. (Byte units eg. 512KB, 2GB)").Bytes()
 
 	filesystemScan  = cli.Command("filesystem", "Find credentials in a filesystem.")
 	filesystemPaths = filesystemScan.Arg("path", "Path to file or directory to scan.").Strings()
@@ -126,6 +288,7 @@ var (
 	// filesystemScanRecursive = filesystemScan.Flag("recursive", "Scan recursively.").Short('r').Bool()
 	filesystemScanIncludePaths = filesystemScan.Flag("include-paths", "Path to file with newline separated regexes for files to include in scan.").Short('i').String()
 	filesystemScanExcludePaths = filesystemScan.Flag("exclude-paths", "Path to file with newline separated regexes for files to exclude in scan.").Short('x').String()
+	filesystemWatch            = filesystemScan.Flag("watch", "Keep running after the paths are scanned, and scan files as they are created or modified until interrupted.").Bool()
 
 	s3Scan              = cli.Command("s3", "Find credentials in S3 buckets.")
 	s3ScanKey           = s3Scan.Flag("key", "S3 key used to authenticate. Can be provided with environment variable AWS_ACCESS_KEY_ID.").Envar("AWS_ACCESS_KEY_ID").String()
@@ -154,7 +317,7 @@ var (
 	syslogProtocol = syslogScan.Flag("protocol", "Protocol to listen on. udp or tcp").String()
 	syslogTLSCert  = syslogScan.Flag("cert", "Path to TLS cert.").String()
 	syslogTLSKey   = syslogScan.Flag("key", "Path to TLS key.").String()
-	syslogFormat   = syslogScan.Flag("format", "Log format. Can be rfc3164 or rfc5424").String()
+	syslogFormat   = syslogScan.Flag("syslog-format", "Log format. Can be rfc3164 or rfc5424.").String()
 
 	circleCiScan      = cli.Command("circleci", "Scan CircleCI")
 	circleCiScanToken = circleCiScan.Flag("token", "CircleCI token. Can also be provided with environment variable").Envar("CIRCLECI_TOKEN").Required().String()
@@ -230,10 +393,193 @@ var (
 	huggingfaceIncludeDiscussions = huggingfaceScan.Flag("include-discussions", "Include discussions in scan.").Bool()
 	huggingfaceIncludePrs         = huggingfaceScan.Flag("include-prs", "Include pull requests in scan.").Bool()
 
+	scanCmd           = cli.Command("scan", "Find credentials in a source configured by its connection, as in the serve job API, and in the sources of the --config file.")
+	scanSourceType    = scanCmd.Flag("source-type", `Type of the source to scan, such as "git" or "s3".`).String()
+	scanConnectionRaw = scanCmd.Flag("connection", "Connection of the source as JSON, in the format of its message in sources.proto. Credential references are resolved as in --config files. Can be provided with environment variable TRUFFLEHOG_CONNECTION.").Envar("TRUFFLEHOG_CONNECTION").PlaceHolder("JSON").String()
+
+	serveCmd          = cli.Command("serve", "Run a server that scans sources submitted to its job API.")
+	serveListen       = serveCmd.Flag("listen", "Address to serve the job API on.").Default("localhost:8080").String()
+	serveGRPC         = serveCmd.Flag("grpc-listen", "Address to also serve the gRPC Jobs service on, which streams the findings and progress of jobs as they are scanned.").PlaceHolder("ADDR").String()
+	serveToken        = serveCmd.Flag("token", "Bearer token clients must authenticate with. Can be provided with environment variable TRUFFLEHOG_SERVER_TOKEN.").Envar("TRUFFLEHOG_SERVER_TOKEN").String()
+	serveMaxJobs      = serveCmd.Flag("max-jobs", "Maximum number of jobs to run at once. Further jobs are queued by priority.").Default("4").Int()
+	serveTenantMax    = serveCmd.Flag("tenant-max-jobs", "Maximum number of jobs of a tenant to run at once. 0 for no limit.").Default("0").Int()
+	serveTenantQuotas = serveCmd.Flag("tenant-quota", "Maximum number of jobs of a tenant to run at once, overriding --tenant-max-jobs. Can be repeated.").PlaceHolder("TENANT=JOBS").StringMap()
+	serveState        = serveCmd.Flag("state-file", "Save stored sources, schedules, and their run history to this file, and restore them on start.").PlaceHolder("PATH").String()
+	serveStateKey     = serveCmd.Flag("state-key", "Encrypt the connections and credentials of stored sources in --state-file with this key. Can be provided with environment variable TRUFFLEHOG_STATE_KEY.").Envar("TRUFFLEHOG_STATE_KEY").String()
+	serveTenants      = serveCmd.Flag("tenants-file", "YAML file of tenants, each with its own token, detector overrides, and policy, and only seeing its own jobs, sources, and schedules.").PlaceHolder("PATH").String()
+	serveAPIKeys      = serveCmd.Flag("api-keys-file", "YAML file of API keys clients may authenticate with, each granting the submit, read, or admin role, optionally for a single tenant.").PlaceHolder("PATH").String()
+	serveOIDCIssuer   = serveCmd.Flag("oidc-issuer", "Let clients authenticate with tokens issued by this OIDC provider.").PlaceHolder("URL").String()
+	serveOIDCAudience = serveCmd.Flag("oidc-audience", "Audience OIDC tokens must be issued for.").String()
+	serveOIDCRoles    = serveCmd.Flag("oidc-roles-claim", "Claim of OIDC tokens listing the roles of the client.").Default("roles").String()
+	serveOIDCTenant   = serveCmd.Flag("oidc-tenant-claim", "Claim of OIDC tokens naming the tenant the client is limited to. Tokens without it are rejected.").String()
+	serveAuditLog     = serveCmd.Flag("audit-log", "Append the audit log of API requests to this file as JSON lines instead of logging it.").PlaceHolder("PATH").String()
+
+	operatorCmd       = cli.Command("operator", "Run a Kubernetes controller that scans the sources described by SecretScan resources as jobs.")
+	operatorNamespace = operatorCmd.Flag("namespace", "Namespace to watch SecretScans in. Defaults to all namespaces.").String()
+	operatorImage     = operatorCmd.Flag("image", "Image of scan jobs, unless a SecretScan sets its own.").Default("trufflesecurity/trufflehog:latest").String()
+	operatorWebhook   = operatorCmd.Flag("summary-webhook-url", "URL to POST the findings summary of every finished scan to, unless a SecretScan sets its own.").String()
+	operatorResync    = operatorCmd.Flag("resync-interval", "How often to check every SecretScan for scans that are due or finished.").Default("30s").Duration()
+
+	listenCmd   = cli.Command("listen", "Scan the git repositories of scan requests received from a message queue, acknowledging each once its findings have been delivered to the configured outputs.")
+	listenQueue = listenCmd.Arg("queue", "Queue to receive scan requests from.").Required().PlaceHolder("sqs://QUEUE-URL|pubsub://PROJECT/SUBSCRIPTION|amqp://HOST/VHOST?queue=QUEUE").String()
+
+	receiveCmd               = cli.Command("receive", "Serve GitHub, GitLab, Bitbucket Cloud, and Azure DevOps webhooks, scanning the commits of every push and pull or merge request and reporting the findings on them.")
+	receiveListen            = receiveCmd.Flag("listen", "Address to receive webhooks on, at /github, /gitlab, /bitbucket, and /azure-devops.").Default("localhost:8090").String()
+	receiveGitHubSecret      = receiveCmd.Flag("github-secret", "Secret GitHub webhooks are signed with. GitHub webhooks are only accepted if it is set. Can be provided with environment variable TRUFFLEHOG_GITHUB_WEBHOOK_SECRET.").Envar("TRUFFLEHOG_GITHUB_WEBHOOK_SECRET").String()
+	receiveGitHubToken       = receiveCmd.Flag("github-token", "GitHub App installation token to clone repositories and create check runs and reviews with. Can be provided with environment variable GITHUB_TOKEN.").Envar("GITHUB_TOKEN").String()
+	receiveGitHubEndpoint    = receiveCmd.Flag("github-endpoint", "GitHub API endpoint, for GitHub Enterprise Server.").String()
+	receiveGitLabSecret      = receiveCmd.Flag("gitlab-secret", "Secret token of GitLab webhooks. GitLab webhooks are only accepted if it is set. Can be provided with environment variable TRUFFLEHOG_GITLAB_WEBHOOK_SECRET.").Envar("TRUFFLEHOG_GITLAB_WEBHOOK_SECRET").String()
+	receiveGitLabToken       = receiveCmd.Flag("gitlab-token", "GitLab token to clone repositories and set commit statuses and comment merge requests with. Can be provided with environment variable GITLAB_TOKEN.").Envar("GITLAB_TOKEN").String()
+	receiveGitLabEndpoint    = receiveCmd.Flag("gitlab-endpoint", "GitLab endpoint, for self-managed GitLab.").String()
+	receiveBitbucketSecret   = receiveCmd.Flag("bitbucket-secret", "Secret Bitbucket webhooks are signed with. Bitbucket webhooks are only accepted if it is set. Can be provided with environment variable TRUFFLEHOG_BITBUCKET_WEBHOOK_SECRET.").Envar("TRUFFLEHOG_BITBUCKET_WEBHOOK_SECRET").String()
+	receiveBitbucketToken    = receiveCmd.Flag("bitbucket-token", "Bitbucket access token, or username:app-password, to clone repositories and set build statuses and comment pull requests with. Can be provided with environment variable BITBUCKET_TOKEN.").Envar("BITBUCKET_TOKEN").String()
+	receiveBitbucketEndpoint = receiveCmd.Flag("bitbucket-endpoint", "Bitbucket API endpoint.").String()
+	receiveAzureSecret       = receiveCmd.Flag("azure-devops-secret", "Basic authentication password of Azure DevOps service hooks. Service hooks are only accepted if it is set. Can be provided with environment variable TRUFFLEHOG_AZURE_DEVOPS_WEBHOOK_SECRET.").Envar("TRUFFLEHOG_AZURE_DEVOPS_WEBHOOK_SECRET").String()
+	receiveAzureToken        = receiveCmd.Flag("azure-devops-token", "Azure DevOps personal access token to clone repositories and set commit statuses with. Can be provided with environment variable AZURE_DEVOPS_EXT_PAT.").Envar("AZURE_DEVOPS_EXT_PAT").String()
+	receiveMaxScans          = receiveCmd.Flag("max-scans", "Maximum number of webhooks to scan at once. Further scans wait.").Default("4").Int()
+
+	pluginCmd       = cli.Command("plugin", "Develop external detector plugins.")
+	pluginNewCmd    = pluginCmd.Command("new", "Generate the Go project of a new detector plugin.")
+	pluginNewName   = pluginNewCmd.Arg("name", "Name of the plugin's detector, such as acme-token.").Required().String()
+	pluginNewDir    = pluginNewCmd.Flag("dir", "Directory to write the project to. Defaults to the plugin name.").String()
+	pluginNewModule = pluginNewCmd.Flag("module", "Go module path of the project. Defaults to the plugin name.").String()
+
+	detectorsCmd          = cli.Command("detectors", "Inspect the detectors a scan would run.")
+	detectorsListCmd      = detectorsCmd.Command("list", "List the detectors selected by --include-detectors and --exclude-detectors, with their keywords and whether they verify results.")
+	detectorsDescribeCmd  = detectorsCmd.Command("describe", "Describe a detector: its keywords, the endpoints it verifies against, and examples of its masked findings.")
+	detectorsDescribeName = detectorsDescribeCmd.Arg("name", "Name of the detector, as used by --include-detectors, such as github or github.v2.").Required().String()
+
+	validateCmd        = cli.Command("validate", "Check the sources of the --config file without scanning them: their settings, credentials, whether what they point at can be reached, and how much there is to scan.")
+	validateNoEstimate = validateCmd.Flag("no-estimate", "Don't count the units, such as repositories or files, each source would scan.").Bool()
+	validateTimeout    = validateCmd.Flag("source-timeout", "How long to spend checking each source.").Default("1m").Duration()
+
+	sourcesCmd          = cli.Command("sources", "Check the sources of the --config file.")
+	sourcesCheckCmd     = sourcesCmd.Command("check", "Run the health check of each source of the --config file, which checks its credentials and that what it points at can be reached without scanning it. Exits with code 1 if a check fails, so scheduled jobs can alert on broken credentials.")
+	sourcesCheckTimeout = sourcesCheckCmd.Flag("source-timeout", "How long to spend checking each source.").Default("1m").Duration()
+
+	benchCmd    = cli.Command("bench", "Measure how fast each stage of the scan pipeline and each detector gets through a corpus, without verification.")
+	benchSize   = benchCmd.Flag("size", "Size of the generated synthetic corpus. (Byte units eg. 64MB, 1GB)").Default("32MB").Bytes()
+	benchSeed   = benchCmd.Flag("seed", "Seed of the generated synthetic corpus.").Default("1").Uint64()
+	benchCorpus = benchCmd.Flag("corpus", "Replay the files in this directory, such as a checked out repository, instead of a synthetic corpus.").ExistingDir()
+	benchTop    = benchCmd.Flag("top", "Number of the slowest detectors to report. 0 reports all of them.").Default("20").Int()
+
+	reportCmd       = cli.Command("report", "Report on the scan runs recorded by --database-url.")
+	reportDriftCmd  = reportCmd.Command("drift", "Show the secrets introduced and remediated between two scan runs.")
+	reportDriftFrom = reportDriftCmd.Flag("from", "ID of the earlier scan run. Defaults to the last finished run that started before --to.").String()
+	reportDriftTo   = reportDriftCmd.Flag("to", "ID of the later scan run. Defaults to the latest finished run.").String()
+
+	adminCmd             = cli.Command("admin", "Maintain the data recorded by --database-url.")
+	adminPurgeCmd        = adminCmd.Command("purge", "Purge remediated findings and old scan runs, and hash stored secrets.")
+	adminPurgeRemediated = adminPurgeCmd.Flag("remediated-after", "Purge findings that no run has found for this long, such as 90d.").String()
+	adminPurgeRuns       = adminPurgeCmd.Flag("runs-after", "Purge scan runs that started this long ago, such as 365d.").String()
+	adminPurgeHash       = adminPurgeCmd.Flag("hash-secrets", "Replace the stored secrets with their SHA-256 hashes.").Bool()
+	adminDecryptCmd      = adminCmd.Command("decrypt", "Decrypt secrets stored with --secrets-encryption-key.")
+	adminDecryptValues   = adminDecryptCmd.Arg("values", "Encrypted secrets to decrypt. Read from stdin, one per line, if none are given.").Strings()
+	adminDecryptIdentity = adminDecryptCmd.Flag("identity", "age identity file of the recipient secrets were encrypted for. Secrets encrypted with AWS KMS are decrypted with the default AWS credentials.").PlaceHolder("PATH").ExistingFile()
+
+	reverifyCmd      = cli.Command("reverify", "Verify the findings of earlier --json output again, without scanning their sources, and tell which of their secrets have been revoked since.")
+	reverifyFindings = reverifyCmd.Arg("findings", "File of the findings, one JSON finding per line.").Required().ExistingFile()
+	reverifyIdentity = reverifyCmd.Flag("identity", "age identity file of the recipient secrets were encrypted for with --secrets-encryption-key. Secrets encrypted with AWS KMS are decrypted with the default AWS credentials.").PlaceHolder("PATH").ExistingFile()
+
+	diffCmd    = cli.Command("diff", "Compare the findings of two files of --json output by fingerprint, and show the new, resolved, and persisting ones. Exits with code 183 if new verified secrets appear, or any new finding with --fail.")
+	diffOld    = diffCmd.Arg("old", "File of the earlier findings, one JSON finding per line.").Required().ExistingFile()
+	diffLatest = diffCmd.Arg("new", "File of the later findings, one JSON finding per line.").Required().ExistingFile()
+
 	analyzeCmd = analyzer.Command(cli)
 	usingTUI   = false
 )
 
+// cmdArgs are the parsed command line arguments, which are those recorded by
+// the run manifest of --from-manifest followed by the given ones.
+var cmdArgs []string
+
+// replayManifest is the manifest of --from-manifest.
+var replayManifest *runmanifest.Manifest
+
+// manifestOptions returns how run manifests record the command line.
+func manifestOptions() runmanifest.Options {
+	model := cli.Model()
+
+	return runmanifest.Options{
+		// Flags are looked up in the command named by the positional
+		// arguments, then in its parents.
+		Lookup: func(positional []string, name string) (string, bool, bool) {
+			groups := []*kingpin.FlagGroupModel{model.FlagGroupModel}
+			commands := model.CmdGroupModel
+			for _, arg := range positional {
+				i := slices.IndexFunc(commands.Commands, func(c *kingpin.CmdModel) bool {
+					return c.Name == arg || slices.Contains(c.Aliases, arg)
+				})
+				if i < 0 {
+					break
+				}
+				groups = append(groups, commands.Commands[i].FlagGroupModel)
+				commands = commands.Commands[i].CmdGroupModel
+			}
+			for i := len(groups) - 1; i >= 0; i-- {
+				for _, f := range groups[i].Flags {
+					if f.Name == name || f.Short != 0 && string(f.Short) == name {
+						return f.Name, !f.IsBoolFlag(), true
+					}
+				}
+			}
+			return "", false, false
+		},
+		Secrets: []string{
+			"api-key", "bitbucket-pr-token", "cleanup-pr-token", "coordinator-token", "database-url", "github-code-scanning-token", "github-pr-token",
+			"gitlab-mr-token", "jira-token", "key", "password", "secret", "service-token",
+			"servicenow-token", "session-token", "slack-webhook", "teams-webhook", "token", "webhook-secret",
+		},
+		Files: []string{"config", "include-paths", "exclude-paths", "cleanup-pr-canaries"},
+		Omit:  []string{"from-manifest", "manifest-out"},
+	}
+}
+
+// logLevelSet records whether --log-level was passed, since 0 is a valid level.
+var logLevelSet bool
+
+// outputFormatSet and resultsSet record whether --format and --results were
+// passed, since they take precedence over the configuration file.
+var outputFormatSet, resultsSet bool
+
+// Supported values for the --log-format flag.
+const (
+	logFormatAuto    = "auto"
+	logFormatConsole = "console"
+	logFormatJSON    = "json"
+)
+
+// Supported values for the --format flag.
+const (
+	formatPlain         = "plain"
+	formatJSON          = "json"
+	formatJSONLegacy    = "json-legacy"
+	formatGitHubActions = "github-actions"
+	formatSARIF         = "sarif"
+	formatJUnit         = "junit"
+	formatCSV           = "csv"
+	formatTemplate      = "template"
+)
+
+// Exit codes used to report the outcome of a scan when --fail,
+// --fail-verified or --policy is set. Fatal errors always exit with
+// exitCodeFatal, and interrupted scans with exitCodeInterrupted unless
+// findings fail them.
+const (
+	exitCodeClean       = 0
+	exitCodeFatal       = 1
+	exitCodeInterrupted = 130
+	exitCodeFindings    = 183
+	exitCodeScanErrors  = 184
+)
+
+// errScanTimeout stops scans that ran longer than --timeout.
+var errScanTimeout = errors.New("scan timed out")
+
+var outputFormats = []string{
+	formatPlain, formatJSON, formatJSONLegacy, formatGitHubActions, formatSARIF, formatJUnit, formatCSV, formatTemplate,
+}
+
 func init() {
 	_, _ = maxprocs.Set()
 
@@ -262,9 +608,23 @@ func init() {
 		usingTUI = true
 	}
 
-	cmd = kingpin.MustParse(cli.Parse(os.Args[1:]))
+	cmdArgs = os.Args[1:]
+	path, err := runmanifest.FlagValue(cmdArgs, "from-manifest", manifestOptions())
+	cli.FatalIfError(err, "")
+	if path != "" {
+		replayManifest, err = runmanifest.Read(path)
+		cli.FatalIfError(err, "could not read run manifest")
+		dir, err := cleantemp.MkdirTemp()
+		cli.FatalIfError(err, "could not restore the files of the run manifest")
+		cmdArgs, err = replayManifest.ReplayArgs(cmdArgs, dir, manifestOptions())
+		cli.FatalIfError(err, "could not replay run manifest")
+	}
+
+	cmd = kingpin.MustParse(cli.Parse(cmdArgs))
 
 	switch {
+	case logLevelSet:
+		log.SetLevel(*logLevel)
 	case *trace:
 		log.SetLevel(5)
 	case *debug:
@@ -275,8 +635,13 @@ func init() {
 func main() {
 	// setup logger
 	logFormat := log.WithConsoleSink
-	if *jsonOut {
+	switch *logFormatFlag {
+	case logFormatJSON:
 		logFormat = log.WithJSONSink
+	case logFormatAuto:
+		if resolveOutputFormat() == formatJSON {
+			logFormat = log.WithJSONSink
+		}
 	}
 	logger, sync := log.New("trufflehog", logFormat(os.Stderr))
 	// make it the default logger for contexts
@@ -316,6 +681,12 @@ func run(state overseer.State) {
 
 	ctx, cancel := context.WithCancelCause(context.Background())
 	defer cancel(nil)
+	// scanCtx is cancelled by the first signal during a scan. That stops the
+	// sources, while the chunks they already sent are still scanned and their
+	// findings reported.
+	scanCtx, stopScan := context.WithCancelCause(ctx)
+	defer stopScan(nil)
+	var scanning atomic.Bool
 
 	go func() {
 		if err := cleantemp.CleanTempArtifacts(ctx); err != nil {
@@ -330,6 +701,20 @@ func run(state overseer.State) {
 	signal.Notify(killSignal, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
 		<-killSignal
+		code := exitCodeClean
+		if scanning.Load() {
+			received := time.Now()
+			logger.Info("Received signal, stopping the scan. Send it again to exit immediately.")
+			stopScan(fmt.Errorf("canceling scan due to signal"))
+			// A Ctrl+C reaches both this process and the overseer parent,
+			// which forwards it, so repeats right after the first are ignored.
+			for range killSignal {
+				if time.Since(received) > time.Second {
+					break
+				}
+			}
+			code = exitCodeInterrupted
+		}
 		logger.Info("Received signal, shutting down.")
 		cancel(fmt.Errorf("canceling context due to signal"))
 
@@ -338,15 +723,31 @@ func run(state overseer.State) {
 		} else {
 			logger.Info("cleaned temporary artifacts")
 		}
-		os.Exit(0)
+		os.Exit(code)
 	}()
 
 	logger.V(2).Info(fmt.Sprintf("trufflehog %s", version.BuildVersion))
 
-	if *githubScanToken != "" {
+	// flushTraces exports buffered spans. It is deferred, and must also be
+	// called before exiting with os.Exit.
+	flushTraces := func() {}
+	if *otelEndpoint != "" {
+		shutdown, err := tracing.Init(ctx, *otelEndpoint)
+		if err != nil {
+			logFatal(err, "could not configure tracing")
+		}
+		flushTraces = func() {
+			if err := shutdown(context.Background()); err != nil {
+				logger.Error(err, "could not export traces")
+			}
+		}
+		defer flushTraces()
+	}
+
+	if token, _ := splitTokens(*githubScanToken); token != "" {
 		// NOTE: this kludge is here to do an authenticated shallow commit
 		// TODO: refactor to better pass credentials
-		os.Setenv("GITHUB_TOKEN", *githubScanToken)
+		os.Setenv("GITHUB_TOKEN", token)
 	}
 
 	// When setting a base commit, chunks must be scanned in order.
@@ -354,27 +755,64 @@ func run(state overseer.State) {
 		*concurrency = 1
 	}
 
-	if *profile {
-		runtime.SetBlockProfileRate(1)
-		runtime.SetMutexProfileFraction(-1)
-		go func() {
-			router := http.NewServeMux()
-			router.Handle("/debug/pprof/", http.DefaultServeMux)
-			router.Handle("/debug/fgprof", fgprof.Handler())
-			logger.Info("starting pprof and fgprof server on :18066 /debug/pprof and /debug/fgprof")
-			if err := http.ListenAndServe(":18066", router); err != nil {
-				logger.Error(err, "error serving pprof and fgprof")
-			}
-		}()
+	// --profile predates --pprof-addr and serves on a fixed port.
+	if *pprofAddr == "" && *profile {
+		*pprofAddr = ":18066"
+	}
+	if *pprofAddr != "" {
+		if err := diagnostics.Serve(ctx, *pprofAddr); err != nil {
+			logFatal(err, "could not serve profiles")
+		}
+	}
+	if *runtimeStats > 0 {
+		go diagnostics.LogRuntimeStats(ctx, *runtimeStats)
 	}
 
 	conf := &config.Config{}
 	if *configFilename != "" {
 		var err error
-		conf, err = config.Read(*configFilename)
+		conf, err = config.Read(ctx, *configFilename)
 		if err != nil {
 			logFatal(err, "error parsing the provided configuration file")
 		}
+		if err := applyConfigSettings(conf); err != nil {
+			logFatal(err, "invalid settings in the provided configuration file")
+		}
+	}
+	for _, path := range *detectorPlugins {
+		detector, err := plugin.LoadDetector(ctx, path)
+		if err != nil {
+			logFatal(err, "could not load detector plugin")
+		}
+		defer detector.Close()
+		conf.Detectors = append(conf.Detectors, detector)
+	}
+	if len(*breachCorpus) > 0 || *breachHIBP {
+		corpus, err := breachedpassword.LoadCorpus(*breachCorpus...)
+		if err != nil {
+			logFatal(err, "could not load breach corpus")
+		}
+		logger.V(2).Info("loaded breach corpus", "hashes", corpus.Len())
+		conf.Detectors = append(conf.Detectors, breachedpassword.New(corpus, *breachHIBP))
+	}
+	if len(*credentialKeywords) > 0 || len(*credentialWordlist) > 0 {
+		keywords, err := credentialpair.LoadWordlist(*credentialWordlist...)
+		if err != nil {
+			logFatal(err, "could not load credential wordlist")
+		}
+		keywords = append(keywords, *credentialKeywords...)
+		conf.Detectors = append(conf.Detectors, credentialpair.New(keywords, *credentialWindow))
+	}
+	var scorer scoring.Scorer
+	if *scorerFlag != "" {
+		s, err := scoring.New(ctx, *scorerFlag)
+		if err != nil {
+			logFatal(err, "could not start scorer")
+		}
+		if closer, ok := s.(io.Closer); ok {
+			defer closer.Close()
+		}
+		scorer = s
 	}
 
 	if *archiveMaxSize != 0 {
@@ -386,21 +824,340 @@ func run(state overseer.State) {
 	if *archiveTimeout != 0 {
 		handlers.SetArchiveMaxTimeout(*archiveTimeout)
 	}
+	var recognizer ocr.Recognizer
+	if *ocrFlag != "" {
+		r, err := ocr.New(*ocrFlag)
+		if err != nil {
+			logFatal(err, "could not set up OCR")
+		}
+		recognizer = r
+	}
+	if *scanTimeout != 0 {
+		var cancelTimeout context.CancelFunc
+		scanCtx, cancelTimeout = context.WithTimeoutCause(scanCtx, *scanTimeout, errScanTimeout)
+		defer cancelTimeout()
+	}
 
 	// Set how the engine will print its results.
 	var printer engine.Printer
-	switch {
-	case *jsonLegacy:
+	format := resolveOutputFormat()
+	switch format {
+	case formatJSONLegacy:
 		printer = new(output.LegacyJSONPrinter)
-	case *jsonOut:
+	case formatJSON:
 		printer = new(output.JSONPrinter)
-	case *gitHubActionsFormat:
+	case formatGitHubActions:
 		printer = new(output.GitHubActionsPrinter)
+	case formatSARIF:
+		printer = output.NewSARIFPrinter(os.Stdout)
+	case formatJUnit:
+		printer = output.NewJUnitPrinter(os.Stdout)
+	case formatCSV:
+		printer = output.NewCSVPrinter(os.Stdout)
+	case formatTemplate:
+		if *templateFile == "" {
+			logFatal(fmt.Errorf("--template-file is required with --format template"), "could not configure output")
+		}
+		text, err := os.ReadFile(*templateFile)
+		if err != nil {
+			logFatal(err, "could not read output template")
+		}
+		printer, err = output.NewTemplatePrinter(os.Stdout, string(text))
+		if err != nil {
+			logFatal(err, "could not configure output")
+		}
 	default:
 		printer = new(output.PlainPrinter)
 	}
 
-	if !*jsonLegacy && !*jsonOut {
+	if !*showSecrets && format == formatJSONLegacy {
+		// The legacy format embeds the full commit diff, so masking the
+		// secret field alone would give a false sense of safety.
+		logger.Info("secrets are not masked in the legacy JSON format")
+	}
+
+	var triageSession *triage.Session
+	if *triageMode {
+		if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+			logFatal(fmt.Errorf("stdin and stdout must be a terminal"), "could not start --triage")
+		}
+		// The triage UI takes over the terminal, so it replaces the printer.
+		triageSession = triage.New()
+		printer, format = triageSession, formatPlain
+	}
+	stdoutPrinter := decoratePrinter(printer, format != formatJSONLegacy)
+	if *incidents {
+		p, err := incidentPrinter(format)
+		if err != nil {
+			logFatal(err, "could not configure output")
+		}
+		stdoutPrinter = p
+	}
+	dispatchers := engine.MultiDispatcher{engine.NewPrinterDispatcher(stdoutPrinter)}
+	if *htmlReportFile != nil {
+		defer (*htmlReportFile).Close()
+		// The report masks secrets itself, so it is not wrapped for redaction.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(output.NewHTMLReportPrinter(*htmlReportFile)))
+		if *groupBy != "" {
+			// The reports of groups are created with their first finding, and
+			// closed with the report of every finding.
+			var groupReports []*os.File
+			defer func() {
+				for _, f := range groupReports {
+					f.Close()
+				}
+			}()
+			reportPath := (*htmlReportFile).Name()
+			grouped := output.NewGroupedPrinter(*groupBy, func(group string) (*output.HTMLReportPrinter, error) {
+				f, err := os.Create(output.GroupFileName(reportPath, group))
+				if err != nil {
+					return nil, err
+				}
+				groupReports = append(groupReports, f)
+				return output.NewHTMLReportPrinter(f), nil
+			})
+			dispatchers = append(dispatchers, engine.NewPrinterDispatcher(grouped))
+		}
+	}
+	var policyPrinter *output.PolicyPrinter
+	if *policyFile != "" {
+		pol, err := policy.Load(*policyFile)
+		if err != nil {
+			logFatal(err, "could not load policy")
+		}
+		policyPrinter = output.NewPolicyPrinter(pol)
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(policyPrinter))
+	}
+	var summary *output.SummaryPrinter
+	if *summaryFile != nil {
+		summary = output.NewSummaryPrinter(output.WithSummaryGroups(*groupBy))
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(summary))
+	}
+	if len(*webhookURLs) > 0 {
+		opts := []output.WebhookOption{
+			output.WithWebhookSecret(*webhookSecret),
+			output.WithWebhookBatchSize(*webhookBatchSize),
+		}
+		var webhook engine.Printer = output.NewWebhookPrinter(*webhookURLs, opts...)
+		if *groupBy != "" {
+			// Every group is batched and delivered on its own.
+			webhook = output.NewGroupedPrinter(*groupBy, func(group string) (*output.WebhookPrinter, error) {
+				return output.NewWebhookPrinter(*webhookURLs, append(opts[:len(opts):len(opts)], output.WithWebhookGroup(group))...), nil
+			})
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(webhook, true)))
+	}
+	// Sinks persisting findings store their secrets encrypted with a data key
+	// of the run.
+	var sealer *envelope.Sealer
+	if *secretsEncryptionKey != "" && (*databaseURL != "" || *resultsArchive != "") {
+		if *databaseHashSecrets {
+			logFatal(errors.New("--database-hash-secrets and --secrets-encryption-key can't be used together"), "could not configure secret encryption")
+		}
+		key, err := envelope.ParseKey(*secretsEncryptionKey)
+		if err != nil {
+			logFatal(err, "invalid --secrets-encryption-key")
+		}
+		if sealer, err = envelope.NewSealer(ctx, key); err != nil {
+			logFatal(err, "could not configure secret encryption")
+		}
+	}
+	// Reports and admin commands read the database rather than recording a
+	// run in it.
+	if *databaseURL != "" && cmd != reportDriftCmd.FullCommand() && cmd != adminPurgeCmd.FullCommand() && cmd != adminDecryptCmd.FullCommand() {
+		var opts []output.DatabaseOption
+		if *databaseHashSecrets {
+			opts = append(opts, output.WithHashedSecrets())
+		}
+		remediated, err := output.ParseRetention(*databaseRetention)
+		if err != nil {
+			logFatal(err, "invalid --database-retention")
+		}
+		runs, err := output.ParseRetention(*databaseRunRetention)
+		if err != nil {
+			logFatal(err, "invalid --database-run-retention")
+		}
+		if remediated > 0 || runs > 0 {
+			opts = append(opts, output.WithRetention(output.RetentionPolicy{Remediated: remediated, Runs: runs}))
+		}
+		db, err := output.NewDatabasePrinter(ctx, *databaseURL, opts...)
+		if err != nil {
+			logFatal(err, "could not configure database output")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decorateStoredPrinter(db, sealer)))
+	}
+	if *resultsArchive != "" {
+		archive, err := output.NewArchivePrinter(ctx, *resultsArchive, *resultsArchiveKMSKey)
+		if err != nil {
+			logFatal(err, "could not configure results archive")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decorateStoredPrinter(archive, sealer)))
+	}
+	for _, dest := range *publishTo {
+		queue, err := output.NewQueuePrinter(ctx, dest)
+		if err != nil {
+			logFatal(err, "could not configure message publishing")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(queue, true)))
+	}
+	if *syslogOutput != "" {
+		syslog, err := output.NewSyslogPrinter(*syslogOutput, *syslogOutputFormat)
+		if err != nil {
+			logFatal(err, "could not configure syslog output")
+		}
+		// Records mask secrets themselves.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(syslog, false)))
+	}
+	var teams *ownership.Teams
+	if *ownersFile != "" {
+		var err error
+		if teams, err = ownership.LoadTeams(*ownersFile); err != nil {
+			logFatal(err, "could not load team mapping")
+		}
+	}
+	for _, chat := range []struct {
+		webhooks   []string
+		newPrinter func([]output.NotificationRoute, ...output.NotificationOption) *output.NotificationPrinter
+		teamRoute  func(ownership.Team) string
+	}{
+		{*slackWebhooks, output.NewSlackPrinter, func(t ownership.Team) string { return t.SlackWebhook }},
+		{*teamsWebhooks, output.NewTeamsPrinter, func(t ownership.Team) string { return t.TeamsWebhook }},
+	} {
+		routes := make([]output.NotificationRoute, 0, len(chat.webhooks))
+		for _, webhook := range chat.webhooks {
+			route, err := output.ParseNotificationRoute(webhook)
+			if err != nil {
+				logFatal(err, "could not configure notifications")
+			}
+			routes = append(routes, route)
+		}
+		if teams != nil {
+			// Teams only receive the findings attributed to them.
+			for _, team := range teams.Teams {
+				if chat.teamRoute(team) == "" {
+					continue
+				}
+				route, err := output.ParseNotificationRoute(chat.teamRoute(team))
+				if err != nil {
+					logFatal(err, "could not configure notifications of team "+team.Name)
+				}
+				route.Owner = team.Name
+				routes = append(routes, route)
+			}
+		}
+		if len(routes) == 0 {
+			continue
+		}
+		notifier := chat.newPrinter(routes, output.WithNotificationRate(*notifyRate), output.WithNotificationDigests(*groupBy))
+		// Notifications mask secrets themselves.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(notifier, false)))
+	}
+
+	if *githubPR != "" {
+		pr, err := output.ParsePullRequestRef(*githubPR)
+		if err != nil {
+			logFatal(err, "could not configure pull request reporting")
+		}
+		prPrinter, err := output.NewGitHubPRPrinter(pr, *githubPRToken, *githubPREndpoint)
+		if err != nil {
+			logFatal(err, "could not configure pull request reporting")
+		}
+		// Comments are placed per occurrence and mask secrets themselves, so
+		// the printer is neither deduplicated nor redacted.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(prPrinter))
+	}
+	if *githubCodeScanning != "" {
+		codeScanning, err := output.NewGitHubCodeScanningPrinter(*githubCodeScanning, *githubCodeScanningCommit, *githubCodeScanningRef, *githubCodeScanningToken, *githubCodeScanningEndpoint)
+		if err != nil {
+			logFatal(err, "could not configure code scanning uploads")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(codeScanning, true)))
+	}
+	if *cleanupPRs {
+		var canaries output.Canaries
+		if *cleanupPRCanaries != "" {
+			var err error
+			if canaries, err = output.LoadCanaries(*cleanupPRCanaries); err != nil {
+				logFatal(err, "could not configure cleanup pull requests")
+			}
+		}
+		cleanup, err := output.NewGitHubCleanupPrinter(*cleanupPRToken, *cleanupPREndpoint, *cleanupPRReplacement, canaries)
+		if err != nil {
+			logFatal(err, "could not configure cleanup pull requests")
+		}
+		// Secrets have to be found in the files to be replaced, so results
+		// aren't redacted.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(cleanup))
+	}
+	if *gitlabMR != "" {
+		mr, err := output.ParsePullRequestRef(*gitlabMR)
+		if err != nil {
+			logFatal(err, "could not configure merge request reporting")
+		}
+		mrPrinter, err := output.NewGitLabMRPrinter(mr, *gitlabMRToken, *gitlabMREndpoint)
+		if err != nil {
+			logFatal(err, "could not configure merge request reporting")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(mrPrinter))
+	}
+	if *bitbucketPR != "" {
+		pr, err := output.ParsePullRequestRef(*bitbucketPR)
+		if err != nil {
+			logFatal(err, "could not configure pull request reporting")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(output.NewBitbucketPRPrinter(pr, *bitbucketPRToken, "")))
+	}
+	if *jiraURL != "" {
+		if *jiraProject == "" {
+			logFatal(fmt.Errorf("--jira-project is required"), "could not configure Jira tickets")
+		}
+		tickets, err := output.NewJiraPrinter(*jiraURL, *jiraProject, *jiraIssueType, *jiraToken, *ticketSummary)
+		if err != nil {
+			logFatal(err, "could not configure Jira tickets")
+		}
+		// Tickets mask secrets themselves.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(tickets, false)))
+	}
+	if *serviceNowURL != "" {
+		tickets, err := output.NewServiceNowPrinter(*serviceNowURL, *serviceNowToken, *ticketSummary)
+		if err != nil {
+			logFatal(err, "could not configure ServiceNow incidents")
+		}
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(decoratePrinter(tickets, false)))
+	}
+
+	if len(*remediate) > 0 || len(*remediationCommands) > 0 {
+		playbooks, err := remediation.Playbooks(*remediate, *remediationCommands)
+		if err != nil {
+			logFatal(err, "could not configure remediation")
+		}
+		// Playbooks need the secrets themselves, so results aren't redacted.
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(remediation.NewPrinter(playbooks, *remediationDryRun)))
+	}
+
+	var dispatcher engine.ResultsDispatcher = dispatchers
+	if len(dispatchers) == 1 {
+		dispatcher = dispatchers[0]
+	}
+	if len(*managedSecrets) > 0 {
+		// Results are annotated before any printer redacts their secrets.
+		index, err := managedsecrets.Load(ctx, *managedSecrets)
+		if err != nil {
+			logFatal(err, "could not index managed secrets")
+		}
+		dispatcher = managedsecrets.NewDispatcher(index, dispatcher)
+	}
+	if *trackExposure {
+		dispatcher = exposure.NewDispatcher(exposure.NewTracker(), dispatcher)
+	}
+	if *blameFindings {
+		dispatcher = blame.NewDispatcher(blame.NewResolver(), dispatcher)
+	}
+	if *attributeOwners || teams != nil || *groupBy == output.GroupByOwner {
+		dispatcher = ownership.NewDispatcher(ownership.NewResolver(teams), dispatcher)
+	}
+
+	if format != formatJSONLegacy && format != formatJSON {
 		fmt.Fprintf(os.Stderr, "🐷🔑🐷  TruffleHog. Unearth your secrets. 🐷🔑🐷\n\n")
 	}
 
@@ -413,6 +1170,10 @@ func run(state overseer.State) {
 	if err != nil {
 		logFatal(err, "failed to configure results flag")
 	}
+	skipCategories, err := classify.Parse(*skipFileCategories)
+	if err != nil {
+		logFatal(err, "failed to configure skip file categories flag")
+	}
 
 	engConf := engine.Config{
 		Concurrency: *concurrency,
@@ -426,17 +1187,51 @@ func run(state overseer.State) {
 		ExcludeDetectors:      *excludeDetectors,
 		CustomVerifiersOnly:   *customVerifiersOnly,
 		VerifierEndpoints:     *verifiers,
-		Dispatcher:            engine.NewPrinterDispatcher(printer),
+		Dispatcher:            dispatcher,
 		FilterUnverified:      *filterUnverified,
 		FilterEntropy:         *filterEntropy,
 		VerificationOverlap:   *allowVerificationOverlap,
 		Results:               parsedResults,
 		PrintAvgDetectorTime:  *printAvgDetectorTime,
 		ShouldScanEntireChunk: *scanEntireChunk,
+		ChunkSize:             int(*chunkSize),
+		ChunkOverlap:          int(*chunkOverlap),
+		ContextLines:          *contextLines,
+		Classify:              *classifyFiles,
+		SkipCategories:        skipCategories,
+		Scorer:                scorer,
+		ScoreThreshold:        *scoreThreshold,
+		DetectorTimeout:       *detectorTimeout,
+		RedactLogs:            !*showSecrets,
+		RepoTimeout:           *repoTimeout,
+		OCR:                   recognizer,
+		QRCodes:               *qrCodes,
+		ColumnarMaxRows:       *columnarMaxRows,
+		VerifyURLPasswords:    *verifyURLPasswords,
+	}
+
+	if replayManifest != nil {
+		if replayManifest.Version != version.BuildVersion {
+			logger.Info("replaying the run manifest of another version", "manifest_version", replayManifest.Version, "version", version.BuildVersion)
+		}
+		include, unknown := replayManifest.IncludeDetectors()
+		if len(unknown) > 0 {
+			logger.Info("skipping the detectors of the run manifest this version doesn't have", "detectors", unknown)
+		}
+		if include == "" {
+			logFatal(errors.New("none of its detectors exist in this version"), "could not replay run manifest")
+		}
+		// The recorded detectors replace the selection of the recorded
+		// include and exclude lists, which may differ between versions.
+		engConf.IncludeDetectors, engConf.ExcludeDetectors = include, ""
+		if missing := replayManifest.Missing(cmdArgs, manifestOptions()); len(missing) > 0 {
+			logger.Info("the run manifest redacted credentials that weren't given again", "redacted", missing)
+		}
 	}
 
 	if *compareDetectionStrategies {
-		if err := compareScans(ctx, cmd, engConf); err != nil {
+		scanning.Store(true)
+		if err := compareScans(scanCtx, cmd, engConf, conf.Sources); err != nil {
 			logFatal(err, "error comparing detection strategies")
 		}
 		return
@@ -446,14 +1241,395 @@ func run(state overseer.State) {
 	switch topLevelSubCommand {
 	case analyzeCmd.FullCommand():
 		analyzer.Run(cmd)
+	case pluginCmd.FullCommand():
+		dir, module := *pluginNewDir, *pluginNewModule
+		if dir == "" {
+			dir = *pluginNewName
+		}
+		if module == "" {
+			module = *pluginNewName
+		}
+		if err := plugin.Scaffold(dir, *pluginNewName, module); err != nil {
+			logFatal(err, "could not generate plugin")
+		}
+		logger.Info("generated detector plugin", "dir", dir)
+	case detectorsCmd.FullCommand():
+		dets, err := engine.SelectDetectors(engConf)
+		if err != nil {
+			logFatal(err, "could not select detectors")
+		}
+		infos := catalog.List(dets)
+		if cmd == detectorsDescribeCmd.FullCommand() {
+			if infos, err = catalog.Find(dets, *detectorsDescribeName); err != nil {
+				logFatal(err, "could not describe detector")
+			}
+			if len(infos) == 0 {
+				logFatal(fmt.Errorf("%s isn't selected by --include-detectors and --exclude-detectors", *detectorsDescribeName), "could not describe detector")
+			}
+		}
+		switch {
+		case *jsonOut:
+			if err := json.NewEncoder(os.Stdout).Encode(infos); err != nil {
+				logFatal(err, "could not write detectors")
+			}
+		case cmd == detectorsDescribeCmd.FullCommand():
+			printDetectorDescriptions(os.Stdout, infos)
+		default:
+			printDetectorList(os.Stdout, infos)
+		}
+	case validateCmd.FullCommand():
+		if len(conf.Sources) == 0 {
+			logFatal(errors.New("--config file with sources is required"), "nothing to validate")
+		}
+		results := validateSources(ctx, conf.Sources, !*validateNoEstimate, *validateTimeout)
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+				logFatal(err, "could not write validation results")
+			}
+		} else {
+			printValidation(os.Stdout, results, true)
+		}
+		for _, result := range results {
+			if !result.Valid {
+				os.Exit(exitCodeFatal)
+			}
+		}
+	case sourcesCmd.FullCommand():
+		if len(conf.Sources) == 0 {
+			logFatal(errors.New("--config file with sources is required"), "nothing to check")
+		}
+		results := checkSourcesHealth(ctx, conf.Sources, *sourcesCheckTimeout)
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+				logFatal(err, "could not write health check results")
+			}
+		} else {
+			printValidation(os.Stdout, results, false)
+		}
+		for _, result := range results {
+			if !result.Valid {
+				os.Exit(exitCodeFatal)
+			}
+		}
+	case benchCmd.FullCommand():
+		corpus := bench.Synthetic(int64(*benchSize), *benchSeed, engConf.Detectors)
+		if *benchCorpus != "" {
+			if corpus, err = bench.Load(*benchCorpus); err != nil {
+				logFatal(err, "could not load benchmark corpus")
+			}
+		}
+		logger.Info("running benchmark", "files", len(corpus), "bytes", corpus.Size())
+		report, err := bench.Run(ctx, engConf, corpus)
+		if err != nil {
+			logFatal(err, "error running benchmark")
+		}
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+				logFatal(err, "could not write benchmark report")
+			}
+			return
+		}
+		printBenchReport(os.Stdout, report, *benchTop)
+	case serveCmd.FullCommand():
+		// The configured outputs receive the new findings of scheduled runs.
+		srv := server.New(ctx, engConf, *serveToken, *serveMaxJobs)
+		quotas := make(map[string]int, len(*serveTenantQuotas))
+		for tenant, jobs := range *serveTenantQuotas {
+			n, err := strconv.Atoi(jobs)
+			if err != nil || n < 0 {
+				logFatal(fmt.Errorf("invalid job quota %q of tenant %s", jobs, tenant), "could not configure tenant quotas")
+			}
+			quotas[tenant] = n
+		}
+		srv.SetTenantQuotas(*serveTenantMax, quotas)
+		if *serveTenants != "" {
+			tenants, err := server.LoadTenants(*serveTenants)
+			if err != nil {
+				logFatal(err, "could not load tenants")
+			}
+			if err := srv.SetTenants(tenants); err != nil {
+				logFatal(err, "could not configure tenants")
+			}
+		}
+		if *serveAPIKeys != "" {
+			keys, err := server.LoadAPIKeys(*serveAPIKeys)
+			if err != nil {
+				logFatal(err, "could not load API keys")
+			}
+			if err := srv.SetAPIKeys(keys); err != nil {
+				logFatal(err, "could not configure API keys")
+			}
+		}
+		if *serveOIDCIssuer != "" {
+			cfg := server.OIDCConfig{
+				Issuer:      *serveOIDCIssuer,
+				Audience:    *serveOIDCAudience,
+				RolesClaim:  *serveOIDCRoles,
+				TenantClaim: *serveOIDCTenant,
+			}
+			if err := srv.SetOIDC(ctx, cfg); err != nil {
+				logFatal(err, "could not configure OIDC")
+			}
+		}
+		if *serveAuditLog != "" {
+			f, err := os.OpenFile(*serveAuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				logFatal(err, "could not open audit log")
+			}
+			defer f.Close()
+			srv.SetAuditLog(f)
+		}
+		if *serveStateKey != "" {
+			srv.SetStateKey(*serveStateKey)
+		}
+		if *serveState != "" {
+			if err := srv.LoadSchedules(*serveState); err != nil {
+				logFatal(err, "could not load schedules")
+			}
+		}
+		if *serveGRPC != "" {
+			lis, err := net.Listen("tcp", *serveGRPC)
+			if err != nil {
+				logFatal(err, "could not listen for gRPC clients")
+			}
+			logger.Info("serving gRPC jobs service", "address", lis.Addr().String())
+			go func() {
+				if err := srv.GRPCServer().Serve(lis); err != nil {
+					logFatal(err, "error serving gRPC jobs service")
+				}
+			}()
+		}
+		logger.Info("serving job API", "address", *serveListen)
+		if err := http.ListenAndServe(*serveListen, srv.Handler()); err != nil {
+			logFatal(err, "error serving job API")
+		}
+	case reportCmd.FullCommand():
+		if *databaseURL == "" {
+			logFatal(errors.New("--database-url is required"), "could not report drift")
+		}
+		report, err := output.DatabaseDrift(ctx, *databaseURL, *reportDriftFrom, *reportDriftTo)
+		if err != nil {
+			logFatal(err, "could not report drift")
+		}
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+				logFatal(err, "could not write drift report")
+			}
+			return
+		}
+		printDriftReport(os.Stdout, report)
+	case adminCmd.FullCommand():
+		switch cmd {
+		case adminPurgeCmd.FullCommand():
+			if *databaseURL == "" {
+				logFatal(errors.New("--database-url is required"), "could not purge")
+			}
+			var policy output.RetentionPolicy
+			var err error
+			if *adminPurgeRemediated != "" {
+				if policy.Remediated, err = output.ParseRetention(*adminPurgeRemediated); err != nil {
+					logFatal(err, "invalid --remediated-after")
+				}
+			}
+			if *adminPurgeRuns != "" {
+				if policy.Runs, err = output.ParseRetention(*adminPurgeRuns); err != nil {
+					logFatal(err, "invalid --runs-after")
+				}
+			}
+			policy.HashSecrets = *adminPurgeHash
+			if policy == (output.RetentionPolicy{}) {
+				logFatal(errors.New("one of --remediated-after, --runs-after, or --hash-secrets is required"), "could not purge")
+			}
+			result, err := output.PurgeDatabase(ctx, *databaseURL, policy)
+			if err != nil {
+				logFatal(err, "could not purge")
+			}
+			if *jsonOut {
+				if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+					logFatal(err, "could not write purge result")
+				}
+				return
+			}
+			fmt.Printf("Purged %d findings and %d scan runs, hashed %d secrets.\n", result.Findings, result.Runs, result.HashedSecrets)
+		case adminDecryptCmd.FullCommand():
+			var identities string
+			if *adminDecryptIdentity != "" {
+				data, err := os.ReadFile(*adminDecryptIdentity)
+				if err != nil {
+					logFatal(err, "could not read age identity")
+				}
+				identities = string(data)
+			}
+			opener, err := envelope.NewOpener(identities)
+			if err != nil {
+				logFatal(err, "could not load age identity")
+			}
+			values := *adminDecryptValues
+			if len(values) == 0 {
+				scanner := bufio.NewScanner(os.Stdin)
+				for scanner.Scan() {
+					if line := strings.TrimSpace(scanner.Text()); line != "" {
+						values = append(values, line)
+					}
+				}
+				if err := scanner.Err(); err != nil {
+					logFatal(err, "could not read encrypted secrets")
+				}
+			}
+			for _, value := range values {
+				secret, err := opener.Open(ctx, value)
+				if err != nil {
+					logFatal(err, "could not decrypt secret")
+				}
+				fmt.Println(string(secret))
+			}
+		}
+	case reverifyCmd.FullCommand():
+		dets, err := engine.SelectDetectors(engConf)
+		if err != nil {
+			logFatal(err, "could not select detectors")
+		}
+		var identities string
+		if *reverifyIdentity != "" {
+			data, err := os.ReadFile(*reverifyIdentity)
+			if err != nil {
+				logFatal(err, "could not read age identity")
+			}
+			identities = string(data)
+		}
+		opener, err := envelope.NewOpener(identities)
+		if err != nil {
+			logFatal(err, "could not load age identity")
+		}
+		f, err := os.Open(*reverifyFindings)
+		if err != nil {
+			logFatal(err, "could not open findings")
+		}
+		outcomes, err := reverify.New(dets, opener).Run(ctx, f, *concurrency)
+		_ = f.Close()
+		if err != nil {
+			logFatal(err, "could not reverify findings")
+		}
+		if *jsonOut {
+			enc := json.NewEncoder(os.Stdout)
+			for _, outcome := range outcomes {
+				if err := enc.Encode(outcome); err != nil {
+					logFatal(err, "could not write reverified findings")
+				}
+			}
+		} else {
+			printReverify(os.Stdout, outcomes)
+		}
+		if summary := reverify.Summarize(outcomes); (*fail || *failVerified) && summary[reverify.Live] > 0 {
+			os.Exit(exitCodeFindings)
+		}
+	case diffCmd.FullCommand():
+		oldFile, err := os.Open(*diffOld)
+		if err != nil {
+			logFatal(err, "could not open old findings")
+		}
+		latestFile, err := os.Open(*diffLatest)
+		if err != nil {
+			logFatal(err, "could not open new findings")
+		}
+		diff, err := output.DiffResults(oldFile, latestFile)
+		_ = oldFile.Close()
+		_ = latestFile.Close()
+		if err != nil {
+			logFatal(err, "could not compare findings")
+		}
+		if *jsonOut {
+			if err := json.NewEncoder(os.Stdout).Encode(diff); err != nil {
+				logFatal(err, "could not write findings diff")
+			}
+		} else {
+			printResultsDiff(os.Stdout, diff)
+		}
+		if diff.NewVerified() > 0 || (*fail && len(diff.New) > 0) {
+			os.Exit(exitCodeFindings)
+		}
+	case operatorCmd.FullCommand():
+		controller, err := operator.New(operator.Config{
+			Namespace:      *operatorNamespace,
+			Image:          *operatorImage,
+			WebhookURL:     *operatorWebhook,
+			ResyncInterval: *operatorResync,
+		})
+		if err != nil {
+			logFatal(err, "could not start operator")
+		}
+		logger.Info("watching SecretScans", "namespace", *operatorNamespace)
+		controller.Run(ctx)
+	case listenCmd.FullCommand():
+		consumer, err := trigger.New(ctx, engConf, *listenQueue)
+		if err != nil {
+			logFatal(err, "could not connect to message queue")
+		}
+		if err := consumer.Run(ctx); err != nil {
+			logFatal(err, "error receiving scan requests")
+		}
+	case receiveCmd.FullCommand():
+		if *receiveGitHubSecret == "" && *receiveGitLabSecret == "" && *receiveBitbucketSecret == "" && *receiveAzureSecret == "" {
+			logFatal(errors.New("--github-secret, --gitlab-secret, --bitbucket-secret, or --azure-devops-secret is required"), "could not receive webhooks")
+		}
+		rcv := receiver.New(ctx, engConf, receiver.Config{
+			GitHubSecret:   *receiveGitHubSecret,
+			GitHubToken:    *receiveGitHubToken,
+			GitHubEndpoint: *receiveGitHubEndpoint,
+			GitLabSecret:   *receiveGitLabSecret,
+			GitLabToken:    *receiveGitLabToken,
+			GitLabEndpoint: *receiveGitLabEndpoint,
+
+			BitbucketSecret:   *receiveBitbucketSecret,
+			BitbucketToken:    *receiveBitbucketToken,
+			BitbucketEndpoint: *receiveBitbucketEndpoint,
+			AzureDevOpsSecret: *receiveAzureSecret,
+			AzureDevOpsToken:  *receiveAzureToken,
+
+			MaxScans: *receiveMaxScans,
+		})
+		logger.Info("receiving webhooks", "address", *receiveListen)
+		if err := http.ListenAndServe(*receiveListen, rcv.Handler()); err != nil {
+			logFatal(err, "error receiving webhooks")
+		}
 	default:
-		metrics, err := runSingleScan(ctx, cmd, engConf)
+		var triageDone chan []triage.Finding
+		if triageSession != nil {
+			triageDone = make(chan []triage.Finding, 1)
+			go func() {
+				findings, err := triageSession.Run()
+				if err != nil {
+					logger.Error(err, "error running triage UI")
+				}
+				// Quitting the UI ends the scan too.
+				stopScan(fmt.Errorf("triage UI closed"))
+				triageDone <- findings
+			}()
+		}
+
+		if *manifestOut != "" {
+			writeRunManifest(logger, engConf)
+		}
+
+		scanning.Store(true)
+		metrics, err := runSingleScan(scanCtx, cmd, engConf, conf.Sources)
 		if err != nil {
 			logFatal(err, "error running scan")
 		}
+		if triageSession != nil {
+			triageSession.ScanFinished()
+			writeTriageDecisions(logger, <-triageDone)
+		}
 
 		// Print results.
-		logger.Info("finished scanning",
+		msg := "finished scanning"
+		switch {
+		case context.Cause(scanCtx) == errScanTimeout:
+			msg = "scan timed out, results are partial"
+		case metrics.interrupted:
+			msg = "scan interrupted, results are partial"
+		}
+		logger.Info(msg,
 			"chunks", metrics.ChunksScanned,
 			"bytes", metrics.BytesScanned,
 			"verified_secrets", metrics.VerifiedSecretsFound,
@@ -461,15 +1637,49 @@ func run(state overseer.State) {
 			"scan_duration", metrics.ScanDuration.String(),
 			"trufflehog_version", version.BuildVersion,
 		)
+		if len(metrics.DetectorTimeouts) > 0 || len(metrics.DetectorPanics) > 0 {
+			logger.Info("some detectors were skipped for chunks they timed out or panicked on",
+				"timeouts", metrics.DetectorTimeouts,
+				"panics", metrics.DetectorPanics,
+			)
+		}
 
-		if metrics.hasFoundResults && *fail {
-			logger.V(2).Info("exiting with code 183 because results were found")
-			os.Exit(183)
+		stats := output.ScanStats{
+			ChunksScanned: metrics.ChunksScanned,
+			BytesScanned:  metrics.BytesScanned,
+			Duration:      metrics.ScanDuration,
+			Errors:        metrics.scanErrors,
+			Interrupted:   metrics.interrupted,
+		}
+		if policyPrinter != nil {
+			violations, err := policyPrinter.Evaluate(stats)
+			if err != nil {
+				logger.Error(err, "could not evaluate every policy rule")
+			}
+			for _, v := range violations {
+				logger.Info("policy violation", "rule", v.Rule, "detector", v.Detector, "location", v.Location)
+			}
+			metrics.policyFailed = len(violations) > 0
+		}
+
+		code := scanExitCode(metrics)
+		if summary != nil {
+			stats.ExitCode = code
+			if err := summary.WriteSummary(*summaryFile, stats); err != nil {
+				logger.Error(err, "could not write scan summary")
+			}
+			(*summaryFile).Close()
+		}
+
+		if code != exitCodeClean {
+			logger.V(2).Info("exiting with non-zero code", "code", code)
+			flushTraces()
+			os.Exit(code)
 		}
 	}
 }
 
-func compareScans(ctx context.Context, cmd string, cfg engine.Config) error {
+func compareScans(ctx context.Context, cmd string, cfg engine.Config, configSources []config.Source) error {
 	var (
 		entireMetrics    metrics
 		maxLengthMetrics metrics
@@ -483,14 +1693,14 @@ func compareScans(ctx context.Context, cmd string, cfg engine.Config) error {
 		defer wg.Done()
 		// Run scan with entire chunk span calculator.
 		cfg.ShouldScanEntireChunk = true
-		entireMetrics, err = runSingleScan(ctx, cmd, cfg)
+		entireMetrics, err = runSingleScan(ctx, cmd, cfg, configSources)
 		if err != nil {
 			ctx.Logger().Error(err, "error running scan with entire chunk span calculator")
 		}
 	}()
 
 	// Run scan with max-length span calculator.
-	maxLengthMetrics, err = runSingleScan(ctx, cmd, cfg)
+	maxLengthMetrics, err = runSingleScan(ctx, cmd, cfg, configSources)
 	if err != nil {
 		return fmt.Errorf("error running scan with custom span calculator: %v", err)
 	}
@@ -520,10 +1730,35 @@ func compareMetrics(customMetrics, entireMetrics engine.Metrics) error {
 type metrics struct {
 	engine.Metrics
 	hasFoundResults bool
+	// scanErrors is the number of non-fatal errors reported by the sources.
+	scanErrors uint64
+	// interrupted is set when the sources were stopped before finishing.
+	interrupted bool
+	// policyFailed is set when the scan broke a rule of the --policy file.
+	policyFailed bool
 }
 
-func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics, error) {
-	var scanMetrics metrics
+// scanExitCode determines the exit code for a finished scan based on the
+// --fail, --fail-verified and --policy flags. Findings take precedence over
+// an interruption, which takes precedence over scan errors.
+func scanExitCode(m metrics) int {
+	switch {
+	case *fail && m.hasFoundResults:
+		return exitCodeFindings
+	case *failVerified && m.VerifiedSecretsFound > 0:
+		return exitCodeFindings
+	case m.policyFailed:
+		return exitCodeFindings
+	case m.interrupted:
+		return exitCodeInterrupted
+	case (*fail || *failVerified || *policyFile != "") && m.scanErrors > 0:
+		return exitCodeScanErrors
+	default:
+		return exitCodeClean
+	}
+}
+
+func runSingleScan(ctx context.Context, cmd string, cfg engine.Config, configSources []config.Source) (scanMetrics metrics, err error) {
 
 	// Setup job report writer if provided
 	var jobReportWriter io.WriteCloser
@@ -567,6 +1802,72 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 		sources.WithBufferedOutput(defaultOutputBufferSize),
 	}
 
+	errorCounter := new(sources.ErrorCountHook)
+	opts = append(opts, sources.WithReportHook(errorCounter))
+
+	if *checkpointStore != "" {
+		store, err := checkpoint.Open(*checkpointStore)
+		if err != nil {
+			return scanMetrics, fmt.Errorf("could not open checkpoint store: %v", err)
+		}
+		defer store.Close()
+		runID := *resumeRun
+		if runID == "" {
+			runID = uuid.NewString()
+		}
+		ctx.Logger().Info("saving scan progress, resume an interrupted scan with --resume", "run_id", runID)
+		opts = append(opts, sources.WithCheckpoints(store, runID, *checkpointInterval))
+	} else if *resumeRun != "" {
+		return scanMetrics, fmt.Errorf("--resume requires --checkpoint-store")
+	}
+
+	if *incrementalState != "" {
+		state, err := incremental.Open(*incrementalState)
+		if err != nil {
+			return scanMetrics, err
+		}
+		cfg.Incremental = state
+	}
+
+	var coordinator *distributed.Coordinator
+	switch {
+	case *coordinatorListen != "" && *coordinatorAddr != "":
+		return scanMetrics, fmt.Errorf("--coordinator-listen and --coordinator can't be used together")
+	case *coordinatorListen != "":
+		var tlsConfig *tls.Config
+		if *coordinatorTLSCert != "" || *coordinatorTLSKey != "" {
+			cert, err := tls.LoadX509KeyPair(*coordinatorTLSCert, *coordinatorTLSKey)
+			if err != nil {
+				return scanMetrics, fmt.Errorf("could not load coordinator TLS certificate: %v", err)
+			}
+			tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+		coordinator = distributed.NewCoordinator(*coordinatorToken, tlsConfig)
+		opts = append(opts, sources.WithUnitDispatcher(coordinator))
+	case *coordinatorAddr != "":
+		var tlsConfig *tls.Config
+		if *coordinatorTLS {
+			tlsConfig = &tls.Config{}
+		}
+		worker, err := distributed.NewWorker(*coordinatorAddr, *coordinatorToken, tlsConfig, cfg.Concurrency)
+		if err != nil {
+			return scanMetrics, err
+		}
+		worker.Start(ctx)
+		defer func() {
+			if err := worker.Close(ctx); err != nil {
+				ctx.Logger().Error(err, "could not report findings to coordinator")
+			}
+		}()
+		opts = append(opts, sources.WithUnitLeaser(worker))
+		// The coordinator outputs the findings of every worker.
+		cfg.Dispatcher = engine.NewPrinterDispatcher(worker)
+	}
+
+	if *memoryBudget > 0 {
+		opts = append(opts, sources.WithMemoryBudget(int64(*memoryBudget)))
+	}
+
 	if jobReportWriter != nil {
 		unitHook, finishedMetrics := sources.NewUnitHook(ctx)
 		opts = append(opts, sources.WithReportHook(unitHook))
@@ -575,11 +1876,27 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 
 	cfg.SourceManager = sources.NewManager(opts...)
 
-	eng, err := engine.NewEngine(ctx, &cfg)
+	// The engine isn't stopped by an interruption, so it can scan the chunks
+	// the sources already sent.
+	engCtx := context.WithoutCancel(ctx)
+	eng, err := engine.NewEngine(engCtx, &cfg)
 	if err != nil {
 		return scanMetrics, fmt.Errorf("error initializing engine: %v", err)
 	}
-	eng.Start(ctx)
+	eng.Start(engCtx)
+
+	if coordinator != nil {
+		lis, err := net.Listen("tcp", *coordinatorListen)
+		if err != nil {
+			return scanMetrics, fmt.Errorf("could not listen for workers: %v", err)
+		}
+		go func() {
+			if err := coordinator.Serve(ctx, lis, eng.ResultsChan()); err != nil {
+				ctx.Logger().Error(err, "coordinator stopped serving workers")
+			}
+		}()
+		defer coordinator.Stop()
+	}
 
 	defer func() {
 		// Clean up temporary artifacts.
@@ -588,6 +1905,16 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 		}
 	}()
 
+	// Sources failing to start because the scan was interrupted don't lose
+	// the findings of those already running.
+	finished := false
+	defer func() {
+		if err != nil && !finished && ctx.Err() != nil {
+			ctx.Logger().V(2).Info("scan interrupted while starting sources", "error", err)
+			scanMetrics, err = finishScan(ctx, engCtx, eng, errorCounter)
+		}
+	}()
+
 	switch cmd {
 	case gitScan.FullCommand():
 		gitCfg := sources.GitConfig{
@@ -599,12 +1926,14 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 			MaxDepth:         *gitScanMaxDepth,
 			Bare:             *gitScanBare,
 			ExcludeGlobs:     *gitScanExcludeGlobs,
+			FilterRules:      fileFilterRules(),
+			Remote:           *gitScanRemote,
 		}
 		if err = eng.ScanGit(ctx, gitCfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan Git: %v", err)
 		}
 	case githubScan.FullCommand():
-		filter, err := common.FilterFromFiles(*githubScanIncludePaths, *githubScanExcludePaths)
+		filter, err := common.FilterFromFiles(*githubScanIncludePaths, *githubScanExcludePaths, sources.FilterOptions(fileFilterRules())...)
 		if err != nil {
 			return scanMetrics, fmt.Errorf("could not create filter: %v", err)
 		}
@@ -612,9 +1941,11 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 			return scanMetrics, fmt.Errorf("invalid config: you must specify at least one organization or repository")
 		}
 
+		token, tokens := splitTokens(*githubScanToken)
 		cfg := sources.GithubConfig{
 			Endpoint:                   *githubScanEndpoint,
-			Token:                      *githubScanToken,
+			Token:                      token,
+			Tokens:                     tokens,
 			IncludeForks:               *githubIncludeForks,
 			IncludeMembers:             *githubIncludeMembers,
 			IncludeWikis:               *githubIncludeWikis,
@@ -627,6 +1958,12 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 			IncludePullRequestComments: *githubScanPRComments,
 			IncludeGistComments:        *githubScanGistComments,
 			Filter:                     filter,
+			SkipArchived:               *githubSkipArchived,
+			ExcludeTopics:              *githubExcludeTopics,
+			IncludeTopics:              *githubIncludeTopics,
+			Languages:                  *githubLanguages,
+			PushedWithin:               *githubPushedWithin,
+			MaxRepoSize:                int64(*githubMaxRepoSize),
 		}
 		if err := eng.ScanGitHub(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan Github: %v", err)
@@ -643,16 +1980,26 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 			return scanMetrics, fmt.Errorf("failed to scan using Github Experimental: %v", err)
 		}
 	case gitlabScan.FullCommand():
-		filter, err := common.FilterFromFiles(*gitlabScanIncludePaths, *gitlabScanExcludePaths)
+		filter, err := common.FilterFromFiles(*gitlabScanIncludePaths, *gitlabScanExcludePaths, sources.FilterOptions(fileFilterRules())...)
 		if err != nil {
 			return scanMetrics, fmt.Errorf("could not create filter: %v", err)
 		}
 
+		token, tokens := splitTokens(*gitlabScanToken)
 		cfg := sources.GitlabConfig{
-			Endpoint: *gitlabScanEndpoint,
-			Token:    *gitlabScanToken,
-			Repos:    *gitlabScanRepos,
-			Filter:   filter,
+			Endpoint:      *gitlabScanEndpoint,
+			Token:         token,
+			Tokens:        tokens,
+			Repos:         *gitlabScanRepos,
+			ExcludeRepos:  *gitlabScanExcludeRepos,
+			Filter:        filter,
+			SkipArchived:  *gitlabSkipArchived,
+			SkipForks:     *gitlabSkipForks,
+			ExcludeTopics: *gitlabExcludeTopics,
+			IncludeTopics: *gitlabIncludeTopics,
+			Languages:     *gitlabLanguages,
+			PushedWithin:  *gitlabPushedWithin,
+			MaxRepoSize:   int64(*gitlabMaxRepoSize),
 		}
 		if err := eng.ScanGitLab(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan GitLab: %v", err)
@@ -668,6 +2015,8 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 			Paths:            paths,
 			IncludePathsFile: *filesystemScanIncludePaths,
 			ExcludePathsFile: *filesystemScanExcludePaths,
+			FilterRules:      fileFilterRules(),
+			Watch:            *filesystemWatch,
 		}
 		if err = eng.ScanFileSystem(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan filesystem: %v", err)
@@ -828,20 +2177,101 @@ func runSingleScan(ctx context.Context, cmd string, cfg engine.Config) (metrics,
 		if err := eng.ScanHuggingface(ctx, cfg); err != nil {
 			return scanMetrics, fmt.Errorf("failed to scan HuggingFace: %v", err)
 		}
+	case scanCmd.FullCommand():
+		scanSources := configSources
+		if *scanSourceType != "" {
+			var connection json.RawMessage
+			if *scanConnectionRaw != "" {
+				if connection, err = config.ResolveReferences(ctx, json.RawMessage(*scanConnectionRaw)); err != nil {
+					return scanMetrics, fmt.Errorf("invalid config: %w", err)
+				}
+			}
+			scanSources = append([]config.Source{{Type: *scanSourceType, Connection: connection}}, scanSources...)
+		}
+		if len(scanSources) == 0 {
+			return scanMetrics, fmt.Errorf("invalid config: --source-type or a --config file with sources is required")
+		}
+		// Connections are all validated before any source starts scanning.
+		connections := make([]proto.Message, len(scanSources))
+		for i, source := range scanSources {
+			kind, ok := engine.ParseSourceType(source.Type)
+			if !ok {
+				return scanMetrics, fmt.Errorf("invalid config: unknown source type %q", source.Type)
+			}
+			if connections[i], err = engine.ParseConnection(kind, source.Connection); err != nil {
+				return scanMetrics, fmt.Errorf("invalid config: %w", err)
+			}
+			if scanSources[i].Name == "" {
+				scanSources[i].Name = "trufflehog - " + engine.SourceTypeName(kind)
+			}
+		}
+		for i, source := range scanSources {
+			if _, err := eng.ScanConnection(ctx, source.Name, connections[i]); err != nil {
+				return scanMetrics, fmt.Errorf("failed to scan %s: %v", source.Name, err)
+			}
+		}
 	default:
 		return scanMetrics, fmt.Errorf("invalid command: %s", cmd)
 	}
 
+	finished = true
+	return finishScan(ctx, engCtx, eng, errorCounter)
+}
+
+// finishScan waits for the engine to scan and report every chunk the sources
+// sent, and flush its outputs. Sources stopped by an interruption aren't
+// reported as errors.
+func finishScan(ctx, engCtx context.Context, eng *engine.Engine, errorCounter *sources.ErrorCountHook) (metrics, error) {
 	// Wait for all workers to finish.
-	if err = eng.Finish(ctx); err != nil {
-		return scanMetrics, fmt.Errorf("engine failed to finish execution: %v", err)
+	err := eng.Finish(engCtx)
+	interrupted := ctx.Err() != nil
+	switch {
+	case err == nil:
+	case !interrupted:
+		return metrics{}, fmt.Errorf("engine failed to finish execution: %v", err)
+	case !errors.Is(err, ctx.Err()):
+		// Whatever was found is still reported.
+		ctx.Logger().Error(err, "error finishing interrupted scan")
+	}
+	// Only record incremental state once every chunk has been scanned, so an
+	// interrupted run scans the same history again.
+	if !interrupted {
+		if err := eng.Incremental().Save(); err != nil {
+			return metrics{}, err
+		}
 	}
 
 	if *printAvgDetectorTime {
 		printAverageDetectorTime(eng)
 	}
 
-	return metrics{Metrics: eng.GetMetrics(), hasFoundResults: eng.HasFoundResults()}, nil
+	return metrics{
+		Metrics:         eng.GetMetrics(),
+		hasFoundResults: eng.HasFoundResults(),
+		scanErrors:      errorCounter.Count(),
+		interrupted:     interrupted,
+	}, nil
+}
+
+// applyConfigSettings applies the output and verification settings of the
+// configuration file. Flags setting the same options take precedence.
+func applyConfigSettings(conf *config.Config) error {
+	if format := conf.Output.Format; format != "" && !outputFormatSet {
+		if !slices.Contains(outputFormats, format) {
+			return fmt.Errorf("invalid output format %q, valid formats are %s", format, strings.Join(outputFormats, ", "))
+		}
+		*outputFormat = format
+	}
+	if len(conf.Output.Results) > 0 && !resultsSet {
+		*results = strings.Join(conf.Output.Results, ",")
+	}
+	if enabled := conf.Verification.Enabled; enabled != nil && !*enabled {
+		*noVerification = true
+	}
+	if conf.Verification.AllowOverlap {
+		*allowVerificationOverlap = true
+	}
+	return nil
 }
 
 // parseResults ensures that users provide valid CSV input to `--results`.
@@ -868,16 +2298,124 @@ func parseResults(input *string) (map[string]struct{}, error) {
 	return results, nil
 }
 
+// resolveOutputFormat returns the output format selected by the user. The
+// older boolean format flags take precedence over --format for backwards
+// compatibility.
+func resolveOutputFormat() string {
+	switch {
+	case *jsonLegacy:
+		return formatJSONLegacy
+	case *jsonOut:
+		return formatJSON
+	case *gitHubActionsFormat:
+		return formatGitHubActions
+	default:
+		return *outputFormat
+	}
+}
+
+// writeTriageDecisions saves the decisions made in the triage UI: the false
+// positives to --triage-ignore-file, and every finding to --triage-output.
+func writeTriageDecisions(logger logr.Logger, findings []triage.Finding) {
+	counts := make(map[triage.Decision]int)
+	for _, f := range findings {
+		counts[f.Decision]++
+	}
+	logger.Info("finished triage",
+		"findings", len(findings),
+		"confirmed", counts[triage.Confirmed],
+		"false_positives", counts[triage.FalsePositive],
+		"untriaged", counts[triage.Untriaged],
+	)
+
+	if *triageIgnoreFile != "" {
+		added, err := triage.AppendFalsePositives(*triageIgnoreFile, findings)
+		if err != nil {
+			logger.Error(err, "could not save false positives")
+		} else if added > 0 {
+			logger.Info("added false positives to ignore file", "path", *triageIgnoreFile, "count", added)
+		}
+	}
+	if *triageOutput != "" {
+		if err := triage.WriteDecisions(*triageOutput, findings); err != nil {
+			logger.Error(err, "could not save triage decisions")
+		}
+	}
+}
+
+// writeRunManifest writes the manifest of --manifest-out. The scan goes on
+// without it if it can't be written.
+func writeRunManifest(logger logr.Logger, engConf engine.Config) {
+	dets, err := engine.SelectDetectors(engConf)
+	if err != nil {
+		logger.Error(err, "could not write run manifest")
+		return
+	}
+	manifest, err := runmanifest.New(cmdArgs, dets, manifestOptions())
+	if err == nil {
+		err = manifest.Write(*manifestOut)
+	}
+	if err != nil {
+		logger.Error(err, "could not write run manifest")
+		return
+	}
+	logger.V(1).Info("wrote run manifest", "path", *manifestOut, "detectors", len(manifest.Detectors))
+}
+
+// decoratePrinter applies the output options shared by every result sink:
+// secret masking when redact is set and --show-secrets isn't, and
+// deduplication when --dedupe is set.
+func decoratePrinter(printer engine.Printer, redact bool) engine.Printer {
+	if redact && !*showSecrets {
+		printer = output.NewRedactingPrinter(printer)
+	}
+	if *dedupe {
+		printer = output.NewDedupingPrinter(printer)
+	}
+	return printer
+}
+
+// decorateStoredPrinter decorates the printer of a sink persisting findings,
+// which encrypts their secrets with sealer rather than masking them if it's
+// set.
+func decorateStoredPrinter(printer engine.Printer, sealer *envelope.Sealer) engine.Printer {
+	if sealer == nil {
+		return decoratePrinter(printer, true)
+	}
+	return decoratePrinter(output.NewEncryptingPrinter(printer, sealer), false)
+}
+
+// incidentPrinter returns the printer of --incidents, which replaces the
+// printer of the output format. It masks secrets itself, and clusters more
+// findings than --dedupe, so it isn't decorated.
+func incidentPrinter(format string) (engine.Printer, error) {
+	if *triageMode {
+		return nil, errors.New("--incidents can't be used with --triage")
+	}
+	opts := []output.IncidentOption{output.WithIncidentPrefixLength(*incidentPrefix)}
+	switch format {
+	case formatPlain:
+	case formatJSON:
+		opts = append(opts, output.WithIncidentJSON())
+	default:
+		return nil, fmt.Errorf("--incidents only works with the plain and json formats, not %s", format)
+	}
+	if *showSecrets {
+		opts = append(opts, output.WithIncidentSecrets())
+	}
+	return output.NewIncidentPrinter(os.Stdout, opts...), nil
+}
+
 // logFatalFunc returns a log.Fatal style function. Calling the returned
 // function will terminate the program without cleanup.
 func logFatalFunc(logger logr.Logger) func(error, string, ...any) {
 	return func(err error, message string, keyAndVals ...any) {
 		logger.Error(err, message, keyAndVals...)
 		if err != nil {
-			os.Exit(1)
+			os.Exit(exitCodeFatal)
 			return
 		}
-		os.Exit(0)
+		os.Exit(exitCodeClean)
 	}
 }
 
@@ -895,6 +2433,32 @@ func commaSeparatedToSlice(s []string) []string {
 	return result
 }
 
+// splitTokens returns the first of the non-empty tokens of a repeated token
+// flag, and the others to rotate between with it.
+func splitTokens(flag []string) (string, []string) {
+	var tokens []string
+	for _, token := range flag {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	return tokens[0], tokens[1:]
+}
+
+// fileFilterRules returns the file filter rules of the global filter flags.
+func fileFilterRules() *sourcespb.Filter {
+	return &sourcespb.Filter{
+		IncludePaths:     *includePathRegexes,
+		ExcludePaths:     *excludePathRegexes,
+		MaxSize:          int64(*maxFileSize),
+		IncludeMimeTypes: *includeMIMETypes,
+		ExcludeMimeTypes: *excludeMIMETypes,
+	}
+}
+
 func printAverageDetectorTime(e *engine.Engine) {
 	fmt.Fprintln(
 		os.Stderr,
@@ -904,3 +2468,257 @@ func printAverageDetectorTime(e *engine.Engine) {
 		fmt.Fprintf(os.Stderr, "%s: %s\n", detectorName, duration)
 	}
 }
+
+// printDetectorList prints one line per detector for `detectors list`.
+func printDetectorList(w io.Writer, infos []catalog.Info) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DETECTOR\tVERIFIES\tKEYWORDS")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", info.Name, verifiesText(info), strings.Join(info.Keywords, ", "))
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%d detectors\n", len(infos))
+}
+
+// printDetectorDescriptions prints every detail of the detectors for
+// `detectors describe`.
+func printDetectorDescriptions(w io.Writer, infos []catalog.Info) {
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Detector:    %s\n", info.Name)
+		if info.Description != "" {
+			fmt.Fprintf(w, "Description: %s\n", info.Description)
+		}
+		fmt.Fprintf(w, "Keywords:    %s\n", strings.Join(info.Keywords, ", "))
+		fmt.Fprintf(w, "Verifies:    %s\n", verifiesText(info))
+		if info.CustomEndpoints {
+			fmt.Fprintln(w, "             Custom endpoints can be set with --verifier.")
+		}
+		if len(info.Endpoints) > 0 {
+			fmt.Fprintln(w, "Endpoints:")
+			for _, endpoint := range info.Endpoints {
+				fmt.Fprintf(w, "  %s\n", endpoint)
+			}
+		}
+		if len(info.Examples) > 0 {
+			fmt.Fprintln(w, "Examples:")
+			for _, example := range info.Examples {
+				fmt.Fprintf(w, "  %s\n", example)
+			}
+		}
+	}
+}
+
+func verifiesText(info catalog.Info) string {
+	switch {
+	case !info.BuiltIn && !info.Verifies:
+		return "unknown"
+	case info.Verifies:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// sourceValidation is the outcome of `validate` or `sources check` for one
+// source of the config file.
+type sourceValidation struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+	// Units is unset when the units weren't counted.
+	Units *int `json:"units,omitempty"`
+}
+
+func validateSources(ctx context.Context, configSources []config.Source, estimate bool, timeout time.Duration) []sourceValidation {
+	return checkConfigSources(ctx, configSources, timeout, func(ctx context.Context, name string, connection proto.Message) ([]error, *int) {
+		check := engine.ValidateConnection(ctx, name, connection, estimate)
+		if check.Units < 0 {
+			return check.Errors, nil
+		}
+		return check.Errors, &check.Units
+	})
+}
+
+func checkSourcesHealth(ctx context.Context, configSources []config.Source, timeout time.Duration) []sourceValidation {
+	return checkConfigSources(ctx, configSources, timeout, func(ctx context.Context, name string, connection proto.Message) ([]error, *int) {
+		if err := engine.CheckConnectionHealth(ctx, name, connection); err != nil {
+			return []error{err}, nil
+		}
+		return nil, nil
+	})
+}
+
+// checkConfigSources runs check on the connection of each source, giving it
+// up to timeout.
+func checkConfigSources(ctx context.Context, configSources []config.Source, timeout time.Duration, check func(context.Context, string, proto.Message) ([]error, *int)) []sourceValidation {
+	results := make([]sourceValidation, 0, len(configSources))
+	for _, source := range configSources {
+		result := sourceValidation{Name: source.Name, Type: source.Type}
+		if result.Name == "" {
+			result.Name = "trufflehog - " + source.Type
+		}
+		var errs []error
+		if kind, ok := engine.ParseSourceType(source.Type); !ok {
+			errs = append(errs, fmt.Errorf("unknown source type %q", source.Type))
+		} else if connection, err := engine.ParseConnection(kind, source.Connection); err != nil {
+			errs = append(errs, err)
+		} else {
+			result.Type = engine.SourceTypeName(kind)
+			if source.Name == "" {
+				result.Name = "trufflehog - " + result.Type
+			}
+			sourceCtx, cancel := context.WithTimeout(ctx, timeout)
+			errs, result.Units = check(sourceCtx, result.Name, connection)
+			cancel()
+		}
+		for _, err := range errs {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.Valid = len(errs) == 0
+		results = append(results, result)
+	}
+	return results
+}
+
+// printValidation prints results as a table, with a UNITS column if
+// withUnits is set, followed by the errors of each source.
+func printValidation(w io.Writer, results []sourceValidation, withUnits bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "SOURCE\tTYPE\tSTATUS"
+	if withUnits {
+		header += "\tUNITS"
+	}
+	fmt.Fprintln(tw, header)
+	for _, result := range results {
+		status, units := "ok", "-"
+		if !result.Valid {
+			status = "failed"
+		}
+		if result.Units != nil {
+			units = strconv.Itoa(*result.Units)
+		}
+		row := fmt.Sprintf("%s\t%s\t%s", result.Name, result.Type, status)
+		if withUnits {
+			row += "\t" + units
+		}
+		fmt.Fprintln(tw, row)
+	}
+	_ = tw.Flush()
+	for _, result := range results {
+		if len(result.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", result.Name)
+		for _, err := range result.Errors {
+			// Joined errors are on several lines.
+			fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(err, "\n", "\n  "))
+		}
+	}
+}
+
+// printDriftReport prints the findings introduced and remediated between the
+// runs of `report drift`.
+func printDriftReport(w io.Writer, report *output.DriftReport) {
+	format := "2006-01-02 15:04:05"
+	fmt.Fprintf(w, "Drift from run %s (%s) to run %s (%s)\n",
+		report.From.ID, report.From.StartedAt.Format(format), report.To.ID, report.To.StartedAt.Format(format))
+	for _, section := range []struct {
+		name     string
+		findings []output.DriftFinding
+	}{{"Introduced", report.Introduced}, {"Remediated", report.Remediated}} {
+		fmt.Fprintf(w, "\n%s: %d\n", section.name, len(section.findings))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range section.findings {
+			mark := " "
+			if f.Verified {
+				mark = "✅"
+			}
+			where := f.Link
+			if where == "" {
+				where = strings.TrimSuffix(fmt.Sprintf("%s %s", f.Repository, f.File), " ")
+			}
+			introduced := fmt.Sprintf("first seen %s", f.FirstSeenAt.Format(format))
+			if f.IntroducedCommit != "" {
+				introduced = fmt.Sprintf("introduced in %s", f.IntroducedCommit)
+				if f.IntroducedAt != nil {
+					introduced += " on " + f.IntroducedAt.Format(format)
+				}
+			}
+			fmt.Fprintf(tw, "  %s %s\t%s\t%s\t%s\n", mark, f.Detector, f.Secret, where, introduced)
+		}
+		_ = tw.Flush()
+	}
+}
+
+// printResultsDiff prints the new, resolved, and persisting findings of
+// `diff`.
+func printResultsDiff(w io.Writer, diff *output.ResultsDiff) {
+	for i, section := range []struct {
+		name     string
+		findings []output.DiffFinding
+	}{{"New", diff.New}, {"Resolved", diff.Resolved}, {"Persisting", diff.Persisting}} {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s: %d\n", section.name, len(section.findings))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range section.findings {
+			mark := " "
+			if f.Verified {
+				mark = "✅"
+			}
+			where := f.Link
+			if where == "" {
+				where = strings.TrimSuffix(fmt.Sprintf("%s %s", f.Repository, f.File), " ")
+				if f.Line > 0 {
+					where = fmt.Sprintf("%s:%d", where, f.Line)
+				}
+			}
+			fmt.Fprintf(tw, "  %s %s\t%s\t%s\n", mark, f.Detector, f.Secret, where)
+		}
+		_ = tw.Flush()
+	}
+}
+
+// printReverify prints a table of the reverified findings, followed by the
+// number of findings of each status.
+func printReverify(w io.Writer, outcomes []reverify.Outcome) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tSTATUS\tDETECTOR\tSECRET\tREASON")
+	for _, o := range outcomes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", o.Line, o.Status, o.DetectorName, o.Redacted, o.Reason)
+	}
+	_ = tw.Flush()
+
+	summary := reverify.Summarize(outcomes)
+	fmt.Fprintf(w, "\n%d findings: %d live, %d revoked, %d unverified, %d unknown, %d skipped.\n",
+		len(outcomes), summary[reverify.Live], summary[reverify.Revoked], summary[reverify.Unverified], summary[reverify.Unknown], summary[reverify.Skipped])
+}
+
+func printBenchReport(w io.Writer, report *bench.Report, top int) {
+	fmt.Fprintf(w, "Corpus: %s in %d files and %d chunks\nEngine: %d scanner workers, %d findings\n\n",
+		humanize.Bytes(uint64(report.Bytes)), report.Files, report.Chunks, report.Concurrency, report.Findings)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tDATA\tTIME\tMB/s")
+	for _, s := range report.Stages {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\n", s.Name, humanize.Bytes(uint64(s.Bytes)), s.Duration.Round(time.Millisecond), s.MBPerSec)
+	}
+	_ = tw.Flush()
+
+	detectors := report.Detectors
+	if top > 0 && len(detectors) > top {
+		detectors = detectors[:top]
+	}
+	fmt.Fprintf(w, "\nSlowest %d of the %d detectors that matched:\n\n", len(detectors), len(report.Detectors))
+	fmt.Fprintln(tw, "DETECTOR\tCALLS\tMATCHED\tRESULTS\tTIME\tMB/s")
+	for _, d := range detectors {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%s\t%.2f\n", d.Name, d.Calls, humanize.Bytes(uint64(d.MatchedBytes)), d.Results, d.Duration.Round(time.Millisecond), d.MBPerSec)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w, "\nMB/s is the corpus size divided by the time of the stage or detector alone.")
+}
@@ -0,0 +1,264 @@
+// Package bench measures how fast the scan pipeline gets through a corpus,
+// stage by stage and detector by detector, so slow detectors and regressions
+// stand out and users can size the machines they scan on.
+package bench
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Names of the measured stages, in the order they run.
+const (
+	// StageChunk splits the corpus files into chunks.
+	StageChunk = "chunk"
+	// StageDecode runs every decoder on every chunk.
+	StageDecode = "decode"
+	// StageKeywords finds the detectors whose keywords are in the decoded
+	// chunks.
+	StageKeywords = "keywords"
+	// StageDetect runs the matched detectors, without verification.
+	StageDetect = "detect"
+	// StageEngine runs the whole engine on the chunks with all its workers.
+	StageEngine = "engine"
+)
+
+// detectionTimeout bounds a single FromData call, as in the engine.
+const detectionTimeout = 10 * time.Second
+
+// StageResult is the measurement of one stage. Every rate is relative to the
+// size of the corpus, so the rate of a stage is how fast the corpus would be
+// scanned if that stage was the only work.
+type StageResult struct {
+	Name string `json:"name"`
+	// Bytes is how much data the stage processed, which differs from the
+	// corpus size once chunks overlap and decoders add data.
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration_ns"`
+	MBPerSec float64       `json:"mb_per_sec"`
+}
+
+// DetectorResult is the time one detector took in the detect stage.
+type DetectorResult struct {
+	Name string `json:"name"`
+	// Calls is the number of times FromData was called, once per match.
+	Calls int `json:"calls"`
+	// MatchedBytes is the size of the data FromData was called on.
+	MatchedBytes int64         `json:"matched_bytes"`
+	Results      int           `json:"results"`
+	Errors       int           `json:"errors"`
+	Duration     time.Duration `json:"duration_ns"`
+	MBPerSec     float64       `json:"mb_per_sec"`
+}
+
+// Report is the result of a benchmark.
+type Report struct {
+	Files       int   `json:"files"`
+	Bytes       int64 `json:"bytes"`
+	Chunks      int   `json:"chunks"`
+	Concurrency int   `json:"concurrency"`
+	// Findings is the number of results the engine stage reported.
+	Findings uint64        `json:"findings"`
+	Stages   []StageResult `json:"stages"`
+	// Detectors are the detectors that matched the corpus, slowest first.
+	Detectors []DetectorResult `json:"detectors"`
+}
+
+// Run scans corpus with the detectors, decoders, and chunking of cfg. The
+// single-threaded stages are measured one at a time, then the engine stage
+// scans the same chunks with cfg.Concurrency workers. Verification is always
+// disabled so the measurements don't depend on the network, and results are
+// discarded.
+func Run(ctx context.Context, cfg engine.Config, corpus Corpus) (*Report, error) {
+	var discarded discardDispatcher
+	cfg.Verify = false
+	cfg.Dispatcher = &discarded
+	cfg.SourceManager = sources.NewManager()
+	eng, err := engine.NewEngine(ctx, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := newPipeline(eng.Detectors(), cfg.Decoders, cfg.ShouldScanEntireChunk)
+	size := corpus.Size()
+	report := &Report{Files: len(corpus), Bytes: size, Concurrency: cfg.Concurrency}
+	stage := func(name string, bytes int64, d time.Duration) {
+		report.Stages = append(report.Stages, StageResult{Name: name, Bytes: bytes, Duration: d, MBPerSec: mbPerSec(size, d)})
+	}
+
+	start := time.Now()
+	chunks, err := chunkCorpus(ctx, corpus)
+	if err != nil {
+		return nil, err
+	}
+	stage(StageChunk, size, time.Since(start))
+	report.Chunks = len(chunks)
+
+	start = time.Now()
+	decoded := p.decode(chunks)
+	stage(StageDecode, dataSize(chunks), time.Since(start))
+
+	start = time.Now()
+	matches := p.findMatches(decoded)
+	stage(StageKeywords, dataSize(decoded), time.Since(start))
+
+	stats := make(map[ahocorasick.DetectorKey]*DetectorResult)
+	var matchedBytes int64
+	var detectTime time.Duration
+	for _, m := range matches {
+		s, ok := stats[m.Key]
+		if !ok {
+			s = &DetectorResult{Name: m.Key.String()}
+			stats[m.Key] = s
+		}
+		for _, data := range m.Matches() {
+			start := time.Now()
+			results, err := detect(ctx, m.Detector, data)
+			elapsed := time.Since(start)
+			s.Calls++
+			s.MatchedBytes += int64(len(data))
+			s.Results += len(results)
+			s.Duration += elapsed
+			if err != nil {
+				s.Errors++
+			}
+			matchedBytes += int64(len(data))
+			detectTime += elapsed
+		}
+	}
+	stage(StageDetect, matchedBytes, detectTime)
+	for _, s := range stats {
+		s.MBPerSec = mbPerSec(size, s.Duration)
+		report.Detectors = append(report.Detectors, *s)
+	}
+	slices.SortFunc(report.Detectors, func(a, b DetectorResult) int {
+		return cmp.Or(cmp.Compare(b.Duration, a.Duration), cmp.Compare(a.Name, b.Name))
+	})
+
+	start = time.Now()
+	eng.Start(ctx)
+	for _, chunk := range chunks {
+		c := *chunk
+		eng.ScanChunk(&c)
+	}
+	if err := eng.Finish(ctx); err != nil {
+		return nil, fmt.Errorf("error finishing engine stage: %w", err)
+	}
+	stage(StageEngine, dataSize(chunks), time.Since(start))
+	report.Findings = discarded.n.Load()
+
+	return report, nil
+}
+
+// pipeline runs the stages of the engine one at a time.
+type pipeline struct {
+	decoders []decoders.Decoder
+	core     *ahocorasick.Core
+}
+
+func newPipeline(dets []detectors.Detector, decs []decoders.Decoder, scanEntireChunk bool) *pipeline {
+	if len(decs) == 0 {
+		decs = decoders.DefaultDecoders()
+	}
+	var opts []ahocorasick.CoreOption
+	if scanEntireChunk {
+		opts = append(opts, ahocorasick.WithSpanCalculator(new(ahocorasick.EntireChunkSpanCalculator)))
+	}
+	return &pipeline{decoders: decs, core: ahocorasick.NewAhoCorasickCore(dets, opts...)}
+}
+
+// chunkCorpus splits the files of corpus into filesystem chunks with the
+// default chunk reader.
+func chunkCorpus(ctx context.Context, corpus Corpus) ([]*sources.Chunk, error) {
+	readChunks := sources.NewChunkReader()
+	var chunks []*sources.Chunk
+	for _, f := range corpus {
+		for res := range readChunks(ctx, bytes.NewReader(f.Data)) {
+			if err := res.Error(); err != nil {
+				return nil, fmt.Errorf("could not chunk %s: %w", f.Path, err)
+			}
+			chunks = append(chunks, &sources.Chunk{
+				SourceType: sourcespb.SourceType_SOURCE_TYPE_FILESYSTEM,
+				SourceName: "bench",
+				SourceMetadata: &source_metadatapb.MetaData{
+					Data: &source_metadatapb.MetaData_Filesystem{
+						Filesystem: &source_metadatapb.Filesystem{File: f.Path},
+					},
+				},
+				Data: res.Bytes(),
+			})
+		}
+	}
+	return chunks, nil
+}
+
+// decode returns the chunks every decoder made of chunks.
+func (p *pipeline) decode(chunks []*sources.Chunk) []*sources.Chunk {
+	var decoded []*sources.Chunk
+	for _, chunk := range chunks {
+		for _, decoder := range p.decoders {
+			// Decoders may replace the data of the chunk they're given.
+			c := *chunk
+			if d := decoder.FromChunk(&c); d != nil {
+				decoded = append(decoded, d.Chunk)
+			}
+		}
+	}
+	return decoded
+}
+
+// findMatches returns the detectors matching each chunk.
+func (p *pipeline) findMatches(chunks []*sources.Chunk) []*ahocorasick.DetectorMatch {
+	var matches []*ahocorasick.DetectorMatch
+	for _, chunk := range chunks {
+		matches = append(matches, p.core.FindDetectorMatches(chunk.Data)...)
+	}
+	return matches
+}
+
+// detect calls FromData on data, turning a panic into an error.
+func detect(ctx context.Context, d detectors.Detector, data []byte) (results []detectors.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, detectionTimeout)
+	defer cancel()
+	return d.FromData(ctx, false, data)
+}
+
+func dataSize(chunks []*sources.Chunk) int64 {
+	var n int64
+	for _, c := range chunks {
+		n += int64(len(c.Data))
+	}
+	return n
+}
+
+func mbPerSec(bytes int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(bytes) / 1e6 / d.Seconds()
+}
+
+// discardDispatcher counts the results of the engine stage.
+type discardDispatcher struct{ n atomic.Uint64 }
+
+func (d *discardDispatcher) Dispatch(context.Context, detectors.ResultWithMetadata) error {
+	d.n.Add(1)
+	return nil
+}
@@ -0,0 +1,159 @@
+package bench
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// File is a file of a corpus.
+type File struct {
+	Path string
+	Data []byte
+}
+
+// Corpus is the data a benchmark scans.
+type Corpus []File
+
+// Size returns the total size of the files in bytes.
+func (c Corpus) Size() int64 {
+	var n int64
+	for _, f := range c {
+		n += int64(len(f.Data))
+	}
+	return n
+}
+
+// Load reads every regular file under dir into a corpus, so a checked out
+// repository or a directory of real data can be replayed. Git metadata is
+// skipped.
+func Load(dir string) (Corpus, error) {
+	var corpus Corpus
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		corpus = append(corpus, File{Path: rel, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load corpus: %w", err)
+	}
+	if len(corpus) == 0 {
+		return nil, fmt.Errorf("no files in corpus %s", dir)
+	}
+	return corpus, nil
+}
+
+const (
+	syntheticRepos       = 8
+	syntheticMinFileSize = 4 << 10
+	syntheticMaxFileSize = 256 << 10
+	// One line in secretLineOdds assigns a token next to a detector keyword,
+	// and one in encodedLineOdds holds such a line base64 encoded.
+	secretLineOdds  = 20
+	encodedLineOdds = 200
+)
+
+var syntheticWords = []string{
+	"client", "request", "response", "config", "handler", "server", "buffer",
+	"cache", "session", "user", "account", "record", "index", "result",
+}
+
+// Synthetic generates about size bytes of Go-like source files spread over a
+// few repositories. Some lines assign random tokens next to the keywords of
+// dets, so keyword matching and detection have work to do, and a few are
+// base64 encoded for the decoders. The same arguments always generate the
+// same corpus.
+func Synthetic(size int64, seed uint64, dets []detectors.Detector) Corpus {
+	var keywords []string
+	for _, d := range dets {
+		keywords = append(keywords, d.Keywords()...)
+	}
+	slices.Sort(keywords)
+	keywords = slices.Compact(keywords)
+
+	g := &generator{rng: rand.New(rand.NewPCG(seed, seed)), keywords: keywords}
+	var corpus Corpus
+	for i := 0; size > 0; i++ {
+		n := min(size, int64(syntheticMinFileSize+g.rng.IntN(syntheticMaxFileSize-syntheticMinFileSize)))
+		corpus = append(corpus, File{
+			Path: fmt.Sprintf("repo%02d/pkg%03d/file%05d.go", i%syntheticRepos, i/syntheticRepos%100, i),
+			Data: g.file(int(n)),
+		})
+		size -= n
+	}
+	return corpus
+}
+
+type generator struct {
+	rng      *rand.Rand
+	keywords []string
+}
+
+func (g *generator) file(size int) []byte {
+	var b strings.Builder
+	b.Grow(size + 256)
+	b.WriteString("package main\n\n")
+	for b.Len() < size {
+		b.WriteString(g.line())
+	}
+	return []byte(b.String()[:size])
+}
+
+func (g *generator) line() string {
+	switch {
+	case len(g.keywords) > 0 && g.rng.IntN(secretLineOdds) == 0:
+		return g.secretLine()
+	case len(g.keywords) > 0 && g.rng.IntN(encodedLineOdds) == 0:
+		return fmt.Sprintf("\tblob := %q\n", base64.StdEncoding.EncodeToString([]byte(g.secretLine())))
+	}
+	switch g.rng.IntN(4) {
+	case 0:
+		return fmt.Sprintf("// %s updates the %s of the %s.\n", g.word(), g.word(), g.word())
+	case 1:
+		return fmt.Sprintf("\t%s, err := %s.Get(ctx, %q)\n", g.word(), g.word(), g.word())
+	case 2:
+		return fmt.Sprintf("\tif err != nil {\n\t\treturn fmt.Errorf(\"could not read %s: %%w\", err)\n\t}\n", g.word())
+	default:
+		return fmt.Sprintf("\t%s%d := %d\n", g.word(), g.rng.IntN(100), g.rng.IntN(1<<16))
+	}
+}
+
+func (g *generator) secretLine() string {
+	keyword := g.keywords[g.rng.IntN(len(g.keywords))]
+	return fmt.Sprintf("\t%s_%s = %q\n", keyword, g.word(), g.token(32+g.rng.IntN(17)))
+}
+
+func (g *generator) word() string { return syntheticWords[g.rng.IntN(len(syntheticWords))] }
+
+const tokenAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (g *generator) token(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = tokenAlphabet[g.rng.IntN(len(tokenAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,205 @@
+// Package blame finds the commits that introduced the secrets of findings in
+// files on disk that are in a git work tree, such as a checkout scanned with
+// the filesystem source.
+package blame
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Resolver blames findings, remembering the commit that introduced each
+// secret in each file.
+type Resolver struct {
+	mu     sync.Mutex
+	blamed map[[3]string]*blamed
+}
+
+type blamed struct {
+	once  sync.Once
+	blame *detectors.Blame
+}
+
+// NewResolver creates a Resolver.
+func NewResolver() *Resolver {
+	return &Resolver{blamed: make(map[[3]string]*blamed)}
+}
+
+// Blame returns the commit that introduced the secret of r, or nil if r
+// wasn't found in a committed file of a git work tree. Files extracted from
+// archives can't be blamed.
+func (res *Resolver) Blame(ctx context.Context, r *detectors.ResultWithMetadata) *detectors.Blame {
+	fs := r.SourceMetadata.GetFilesystem()
+	if fs.GetFile() == "" || len(r.SourceMetadata.GetProvenance()) > 0 {
+		return nil
+	}
+	file, err := filepath.Abs(fs.GetFile())
+	if err != nil {
+		return nil
+	}
+	root := WorkTree(file)
+	if root == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return nil
+	}
+	path := filepath.ToSlash(rel)
+	secret := strings.TrimSpace(string(r.Raw))
+
+	res.mu.Lock()
+	key := [3]string{root, path, secret}
+	b, ok := res.blamed[key]
+	if !ok {
+		b = new(blamed)
+		res.blamed[key] = b
+	}
+	res.mu.Unlock()
+
+	b.once.Do(func() {
+		logger := ctx.Logger().WithValues("file", file)
+		// The oldest commit that added the secret to the file introduced it,
+		// even if its line changed since. Secrets that were decoded aren't in
+		// the file as they are, so their line is blamed instead.
+		introduced, err := Introduced(ctx, root, path, secret)
+		if err != nil {
+			logger.V(3).Info("could not find the commit that introduced secret", "error", err)
+		}
+		if introduced != nil {
+			b.blame = introduced
+			return
+		}
+		if b.blame, err = Line(ctx, root, path, fs.GetLine()); err != nil {
+			logger.V(3).Info("could not blame file", "error", err)
+		}
+	})
+	return b.blame
+}
+
+// WorkTree returns the root of the git work tree file is in, if it is in one.
+func WorkTree(file string) string {
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+// Introduced returns the oldest commit of HEAD that added secret to the file
+// at path in the work tree at root, or nil if none did.
+func Introduced(ctx context.Context, root, path, secret string) (*detectors.Blame, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	out, err := runGit(ctx, root, "log", "HEAD", "--format=%H%x00%an%x00%ae%x00%aI", "-S"+secret, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	commits := strings.Split(strings.TrimSpace(out), "\n")
+	fields := strings.Split(commits[len(commits)-1], "\x00")
+	if len(fields) != 4 {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return nil, err
+	}
+	return &detectors.Blame{Commit: fields[0], Author: fields[1], Email: fields[2], Date: date.UTC()}, nil
+}
+
+// Line returns the commit that last changed line of the file at path in the
+// work tree at root, or nil if the line isn't committed. Lines of filesystem
+// findings count from 0, while git counts them from 1.
+func Line(ctx context.Context, root, path string, line int64) (*detectors.Blame, error) {
+	if line < 0 {
+		return nil, nil
+	}
+	n := strconv.FormatInt(line+1, 10)
+	out, err := runGit(ctx, root, "blame", "--porcelain", "-L", n+","+n, "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b detectors.Blame
+	lines := bufio.NewScanner(strings.NewReader(out))
+	if lines.Scan() {
+		b.Commit, _, _ = strings.Cut(lines.Text(), " ")
+	}
+	// Lines that aren't committed are blamed on a commit of zeros.
+	if strings.Trim(b.Commit, "0") == "" {
+		return nil, nil
+	}
+	for lines.Scan() {
+		header, value, _ := strings.Cut(lines.Text(), " ")
+		switch header {
+		case "author":
+			b.Author = value
+		case "author-mail":
+			b.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				b.Date = time.Unix(secs, 0).UTC()
+			}
+		}
+	}
+	return &b, nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// resultsDispatcher mirrors engine.ResultsDispatcher, which can't be
+// referenced here without an import cycle.
+type resultsDispatcher interface {
+	Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error
+}
+
+// Dispatcher annotates results with the commit that introduced their secret
+// before dispatching them.
+type Dispatcher struct {
+	resolver *Resolver
+	next     resultsDispatcher
+}
+
+// NewDispatcher creates a Dispatcher that blames results with resolver and
+// hands them to next.
+func NewDispatcher(resolver *Resolver, next resultsDispatcher) *Dispatcher {
+	return &Dispatcher{resolver: resolver, next: next}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error {
+	result.Blame = d.resolver.Blame(ctx, &result)
+	return d.next.Dispatch(ctx, result)
+}
+
+// Flush flushes the next dispatcher if it buffers its output.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if f, ok := d.next.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
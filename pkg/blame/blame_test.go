@@ -0,0 +1,80 @@
+package blame
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// commitFile commits file with content in the repository at dir as author on date.
+func commitFile(t *testing.T, dir, file, content, author string, date time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "update " + file}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date.Format(time.RFC3339), "GIT_COMMITTER_DATE="+date.Format(time.RFC3339),
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func fileResult(file, raw string, line int64) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: file, Line: line}},
+		},
+		Result: detectors.Result{Raw: []byte(raw)},
+	}
+}
+
+func TestResolver_Blame(t *testing.T) {
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	added := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	edited := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	commitFile(t, dir, "config.env", "KEY=AKIAEXAMPLE\n", "alice", added)
+	commitFile(t, dir, "config.env", "KEY=AKIAEXAMPLE # rotate\nOTHER=value\n", "bob", edited)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.env"), []byte("KEY=AKIAUNCOMMITTED\n"), 0o644))
+
+	ctx := context.Background()
+	res := NewResolver()
+	file := filepath.Join(dir, "config.env")
+
+	// The secret was introduced by the first commit, even though the second
+	// last changed its line.
+	b := res.Blame(ctx, fileResult(file, "AKIAEXAMPLE", 0))
+	require.NotNil(t, b)
+	assert.Equal(t, "alice", b.Author)
+	assert.Equal(t, "alice@example.com", b.Email)
+	assert.Equal(t, added, b.Date)
+	assert.Len(t, b.Commit, 40)
+
+	// Decoded secrets aren't in the file, so their line is blamed.
+	b = res.Blame(ctx, fileResult(file, "decoded", 1))
+	require.NotNil(t, b)
+	assert.Equal(t, "bob", b.Author)
+	assert.Equal(t, edited, b.Date)
+
+	assert.Nil(t, res.Blame(ctx, fileResult(filepath.Join(dir, "new.env"), "AKIAUNCOMMITTED", 0)))
+	assert.Nil(t, res.Blame(ctx, fileResult(filepath.Join(t.TempDir(), "config.env"), "AKIAEXAMPLE", 0)))
+
+	nested := fileResult(file, "AKIAEXAMPLE", 0)
+	nested.SourceMetadata.Provenance = []*source_metadatapb.Container{{Format: "zip", Path: ".env"}}
+	assert.Nil(t, res.Blame(ctx, nested))
+}
@@ -0,0 +1,49 @@
+// Package checkpoint persists the progress of the sources in a scan so an
+// interrupted scan can be resumed without redoing completed work.
+package checkpoint
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// State is the saved progress of a single source in a scan run.
+type State struct {
+	// ResumeInfo is the source's Progress.EncodedResumeInfo.
+	ResumeInfo string `json:"resume_info,omitempty"`
+	// CompletedUnits lists the IDs of the source units that were fully
+	// chunked.
+	CompletedUnits []string `json:"completed_units,omitempty"`
+	// Done is set once the source has finished without error.
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store saves checkpoints keyed by run ID and source name.
+type Store interface {
+	// Load returns the saved state of source in run, or nil if there is none.
+	Load(ctx context.Context, runID, source string) (*State, error)
+	Save(ctx context.Context, runID, source string, state State) error
+	Close() error
+}
+
+// Open opens the checkpoint store at dest, which is one of:
+//
+//	redis://[:password@]host:port[/db]
+//	sqlite:///path/to/checkpoints.db
+//	file:///path/to/dir, or a plain directory path
+func Open(dest string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dest, "redis://"), strings.HasPrefix(dest, "rediss://"):
+		return newRedisStore(dest)
+	case strings.HasPrefix(dest, "sqlite://"):
+		return newSQLiteStore(strings.TrimPrefix(dest, "sqlite://"))
+	case dest == "":
+		return nil, fmt.Errorf("empty checkpoint store")
+	default:
+		return newFileStore(strings.TrimPrefix(dest, "file://"))
+	}
+}
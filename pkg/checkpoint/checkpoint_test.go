@@ -0,0 +1,45 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestStores(t *testing.T) {
+	dir := t.TempDir()
+	for name, dest := range map[string]string{
+		"file":   "file://" + filepath.Join(dir, "checkpoints"),
+		"sqlite": "sqlite://" + filepath.Join(dir, "checkpoints.db"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store, err := Open(dest)
+			require.NoError(t, err)
+			defer store.Close()
+
+			state, err := store.Load(ctx, "run", "trufflehog - github")
+			require.NoError(t, err)
+			assert.Nil(t, state)
+
+			want := State{ResumeInfo: "a\tb", CompletedUnits: []string{"repo:a"}, UpdatedAt: time.Now().UTC().Truncate(time.Second)}
+			require.NoError(t, store.Save(ctx, "run", "trufflehog - github", want))
+			want.Done = true
+			require.NoError(t, store.Save(ctx, "run", "trufflehog - github", want))
+
+			state, err = store.Load(ctx, "run", "trufflehog - github")
+			require.NoError(t, err)
+			require.NotNil(t, state)
+			assert.Equal(t, want, *state)
+
+			state, err = store.Load(ctx, "other-run", "trufflehog - github")
+			require.NoError(t, err)
+			assert.Nil(t, state)
+		})
+	}
+}
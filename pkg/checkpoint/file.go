@@ -0,0 +1,76 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// fileStore saves each checkpoint as a JSON file under <dir>/<run id>/.
+type fileStore struct{ dir string }
+
+func newFileStore(dir string) (*fileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create checkpoint directory: %w", err)
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+// path returns the file for a source. Source names are hashed because they
+// may contain characters that aren't valid in file names.
+func (s *fileStore) path(runID, source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(s.dir, filepath.Base(runID), hex.EncodeToString(sum[:8])+".json")
+}
+
+func (s *fileStore) Load(_ context.Context, runID, source string) (*State, error) {
+	data, err := os.ReadFile(s.path(runID, source))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+	state := new(State)
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("could not decode checkpoint: %w", err)
+	}
+	return state, nil
+}
+
+func (s *fileStore) Save(_ context.Context, runID, source string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	path := s.path(runID, source)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("could not create checkpoint directory: %w", err)
+	}
+	// Write to a temporary file and rename it so an interrupted write never
+	// leaves a truncated checkpoint.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *fileStore) Close() error { return nil }
@@ -0,0 +1,65 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	redisKeyPrefix = "trufflehog:checkpoint:"
+	// redisTTL bounds how long an abandoned run's checkpoints are kept.
+	redisTTL = 30 * 24 * time.Hour
+)
+
+type redisStore struct{ client *redis.Client }
+
+func newRedisStore(dest string) (*redisStore, error) {
+	opts, err := redis.ParseURL(dest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not connect to Redis: %w", err)
+	}
+	return &redisStore{client: client}, nil
+}
+
+func redisKey(runID, source string) string {
+	return redisKeyPrefix + runID + ":" + source
+}
+
+func (s *redisStore) Load(ctx context.Context, runID, source string) (*State, error) {
+	data, err := s.client.WithContext(ctx).Get(redisKey(runID, source)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+	state := new(State)
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("could not decode checkpoint: %w", err)
+	}
+	return state, nil
+}
+
+func (s *redisStore) Save(ctx context.Context, runID, source string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := s.client.WithContext(ctx).Set(redisKey(runID, source), data, redisTTL).Err(); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStore) Close() error { return s.client.Close() }
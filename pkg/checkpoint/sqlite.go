@@ -0,0 +1,70 @@
+package checkpoint
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	createCheckpointsTable = `CREATE TABLE IF NOT EXISTS checkpoints (
+	run_id     TEXT NOT NULL,
+	source     TEXT NOT NULL,
+	state      TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (run_id, source)
+)`
+	loadCheckpointQuery = `SELECT state FROM checkpoints WHERE run_id = ? AND source = ?`
+	saveCheckpointQuery = `INSERT INTO checkpoints (run_id, source, state, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (run_id, source) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at`
+)
+
+type sqliteStore struct{ db *sql.DB }
+
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open checkpoint database: %w", err)
+	}
+	// SQLite only supports a single writer.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(createCheckpointsTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create checkpoint table: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Load(ctx context.Context, runID, source string) (*State, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, loadCheckpointQuery, runID, source).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+	state := new(State)
+	if err := json.Unmarshal([]byte(data), state); err != nil {
+		return nil, fmt.Errorf("could not decode checkpoint: %w", err)
+	}
+	return state, nil
+}
+
+func (s *sqliteStore) Save(ctx context.Context, runID, source string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, saveCheckpointQuery, runID, source, string(data), state.UpdatedAt); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Close() error { return s.db.Close() }
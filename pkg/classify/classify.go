@@ -0,0 +1,226 @@
+// Package classify tags chunks with the categories of files they come from,
+// such as test fixtures and lockfiles, which are full of strings that look
+// like secrets but rarely are. The categories are guessed from the path of the
+// file and the start of its data, so a file can be in several categories, or
+// in none.
+package classify
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// Category is a kind of file secrets are found in.
+type Category string
+
+const (
+	// Test is a test, or a fixture, mock, or other data used by tests.
+	Test Category = "test"
+	// Minified is minified JavaScript or CSS.
+	Minified Category = "minified"
+	// Lockfile is a package manager lockfile.
+	Lockfile Category = "lockfile"
+	// Vendored is a copy of a dependency.
+	Vendored Category = "vendored"
+	// Generated is code generated by a tool, such as protobuf bindings.
+	Generated Category = "generated"
+)
+
+// Categories are all the categories, in the order Classify returns them.
+var Categories = []Category{Test, Minified, Lockfile, Vendored, Generated}
+
+// Parse parses a comma-separated list of categories.
+func Parse(s string) ([]Category, error) {
+	var categories []Category
+	for _, name := range strings.Split(s, ",") {
+		c := Category(strings.ToLower(strings.TrimSpace(name)))
+		switch {
+		case c == "":
+		case !slices.Contains(Categories, c):
+			return nil, fmt.Errorf("invalid file category %q, valid categories are %s", name, Categories)
+		case !slices.Contains(categories, c):
+			categories = append(categories, c)
+		}
+	}
+	return categories, nil
+}
+
+var (
+	testDirs = []string{
+		"test", "tests", "__tests__", "spec", "specs", "testdata", "testing",
+		"fixture", "fixtures", "__fixtures__", "mock", "mocks", "__mocks__",
+	}
+	vendoredDirs = []string{
+		"vendor", "node_modules", "bower_components", "third_party",
+		"third-party", "thirdparty", "site-packages", "pods", ".yarn",
+	}
+	lockfiles = []string{
+		"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml",
+		"bun.lock", "composer.lock", "gemfile.lock", "cargo.lock", "go.sum",
+		"poetry.lock", "pipfile.lock", "uv.lock", "pdm.lock", "podfile.lock",
+		"packages.lock.json", "pubspec.lock", "mix.lock", "flake.lock",
+		"gradle.lockfile", "package.resolved",
+	}
+	// manifests declare dependencies and the registries they're fetched
+	// from, which are often private.
+	manifests = []string{
+		"package.json", ".npmrc", ".yarnrc", ".yarnrc.yml", "bunfig.toml",
+		"requirements.txt", "constraints.txt", "pipfile", "pyproject.toml",
+		"pip.conf", "pip.ini", ".pypirc", "gemfile", ".gemrc", "composer.json",
+		"auth.json", "cargo.toml", "build.gradle", "build.gradle.kts",
+		"settings.gradle", "settings.gradle.kts", "gradle.properties",
+		"pom.xml", "settings.xml", "nuget.config", "packages.config",
+	}
+	// credentialFiles hold credentials as named values, one per line.
+	credentialFiles = []string{
+		".env", ".envrc", ".npmrc", ".yarnrc.yml", ".pypirc", ".netrc", "_netrc",
+		".git-credentials", ".pgpass", ".s3cfg", ".boto", ".my.cnf", ".dockercfg",
+		"credentials",
+	}
+	generatedSuffixes = []string{
+		".pb.go", ".pb.gw.go", ".pb.validate.go", "_pb2.py", "_pb2_grpc.py",
+		"_pb2.pyi", "_pb.js", "_pb.d.ts", ".pb.cc", ".pb.h", ".pb.swift",
+		".pb.dart", ".pb.rs", ".g.dart", ".designer.cs",
+	}
+	generatedMarkers = [][]byte{
+		[]byte("DO NOT EDIT"),
+		[]byte("Generated by the protocol buffer compiler"),
+		[]byte("@generated"),
+		[]byte("<auto-generated"),
+	}
+	minifiedSuffixes = []string{".min.js", ".min.mjs", ".min.css", "-min.js", ".bundle.js"}
+	scriptExts       = []string{".js", ".mjs", ".cjs", ".css"}
+)
+
+const (
+	// headerSize is how much of the data is searched for the markers of
+	// generated code, which are in the comment at the top of the file.
+	headerSize = 1024
+	// minifiedLineLength is the average line length above which a script
+	// is considered minified.
+	minifiedLineLength = 500
+)
+
+// Classify returns the categories of the file at path with the given data,
+// which is the whole file or a chunk of it. The path may be empty, in which
+// case only the data is looked at.
+func Classify(filePath string, data []byte) []Category {
+	slashed := strings.ReplaceAll(filePath, "\\", "/")
+	p := strings.ToLower(slashed)
+	dirs := strings.Split(path.Dir(p), "/")
+	name := path.Base(p)
+
+	var categories []Category
+	if p != "" && (hasAny(dirs, testDirs) || isTestFile(path.Base(slashed))) {
+		categories = append(categories, Test)
+	}
+	if hasSuffix(name, minifiedSuffixes) || (hasSuffix(name, scriptExts) && isMinified(data)) {
+		categories = append(categories, Minified)
+	}
+	if slices.Contains(lockfiles, name) {
+		categories = append(categories, Lockfile)
+	}
+	if hasAny(dirs, vendoredDirs) {
+		categories = append(categories, Vendored)
+	}
+	if hasSuffix(name, generatedSuffixes) || isGenerated(data) {
+		categories = append(categories, Generated)
+	}
+	return categories
+}
+
+// IsDependencyFile reports whether the file at path is a lockfile or a
+// dependency manifest, such as package.json or requirements.txt.
+func IsDependencyFile(filePath string) bool {
+	name := strings.ToLower(path.Base(strings.ReplaceAll(filePath, "\\", "/")))
+	return slices.Contains(lockfiles, name) || slices.Contains(manifests, name) ||
+		// Requirements are often split, as in requirements-dev.txt.
+		(strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"))
+}
+
+// IsCredentialFile reports whether the file at path is a dotenv file or a
+// dotfile tools read credentials from, such as .npmrc or .netrc.
+func IsCredentialFile(filePath string) bool {
+	name := strings.ToLower(path.Base(strings.ReplaceAll(filePath, "\\", "/")))
+	return slices.Contains(credentialFiles, name) ||
+		// Environments get their own dotenv files, as in .env.production
+		// and prod.env.
+		strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env")
+}
+
+// File returns the file of the source metadata, from whichever of its fields
+// is named file or filename, or an empty string if it has neither.
+func File(md *source_metadatapb.MetaData) string {
+	if md == nil || md.Data == nil {
+		return ""
+	}
+	m := md.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("data"))
+	if field == nil || field.Kind() != protoreflect.MessageKind {
+		return ""
+	}
+	source := m.Get(field).Message()
+	fields := source.Descriptor().Fields()
+	for _, name := range []protoreflect.Name{"file", "filename"} {
+		if f := fields.ByName(name); f != nil && f.Kind() == protoreflect.StringKind {
+			if file := source.Get(f).String(); file != "" {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// isTestFile reports whether name is named like a test. The name isn't
+// lowercased, so that FooTest.java is a test but latest.js isn't.
+func isTestFile(name string) bool {
+	stem := strings.TrimSuffix(name, path.Ext(name))
+	lower := strings.ToLower(stem)
+	return strings.HasSuffix(lower, "_test") || strings.HasSuffix(lower, ".test") ||
+		strings.HasSuffix(lower, ".spec") || strings.HasSuffix(lower, "_spec") ||
+		strings.HasPrefix(lower, "test_") ||
+		// Java, Kotlin and C# tests, such as FooTest.java and FooTests.cs.
+		(len(stem) > len("Tests") && (strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests")))
+}
+
+func isMinified(data []byte) bool {
+	if len(data) < minifiedLineLength {
+		return false
+	}
+	return len(data)/(bytes.Count(data, []byte("\n"))+1) > minifiedLineLength
+}
+
+func isGenerated(data []byte) bool {
+	header := data[:min(len(data), headerSize)]
+	for _, marker := range generatedMarkers {
+		if bytes.Contains(header, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAny(dirs, names []string) bool {
+	for _, dir := range dirs {
+		if slices.Contains(names, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package classify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestClassify(t *testing.T) {
+	minified := []byte(strings.Repeat("var a=1;", 200))
+	tests := []struct {
+		path string
+		data []byte
+		want []Category
+	}{
+		{path: "config/settings.yaml"},
+		{path: "latest.js", data: []byte("const a = 1;\n")},
+		{path: "pkg/engine/engine_test.go", want: []Category{Test}},
+		{path: "src/test/java/com/example/ClientTest.java", want: []Category{Test}},
+		{path: "src/components/Button.spec.tsx", want: []Category{Test}},
+		{path: "test_settings.py", want: []Category{Test}},
+		{path: "spec/fixtures/keys.pem", want: []Category{Test}},
+		{path: "Contest.cs"},
+		{path: "static/app.min.js", want: []Category{Minified}},
+		{path: "static/app.js", data: minified, want: []Category{Minified}},
+		{path: "README.md", data: minified},
+		{path: "web/package-lock.json", want: []Category{Lockfile}},
+		{path: "Cargo.lock", want: []Category{Lockfile}},
+		{path: "node_modules/left-pad/package-lock.json", want: []Category{Lockfile, Vendored}},
+		{path: "vendor/github.com/aws/aws-sdk-go/aws/config.go", want: []Category{Vendored}},
+		{path: `C:\src\Pods\Alamofire\Source\Session.swift`, want: []Category{Vendored}},
+		{path: "pkg/pb/detectorspb/detectors.pb.go", want: []Category{Generated}},
+		{path: "api/service_pb2.py", want: []Category{Generated}},
+		{path: "internal/mocks/client.go", data: []byte("// Code generated by mockery. DO NOT EDIT.\n"), want: []Category{Test, Generated}},
+		{data: []byte("// Generated by the protocol buffer compiler.  DO NOT EDIT!\n"), want: []Category{Generated}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Classify(tt.path, tt.data), tt.path)
+	}
+}
+
+func TestParse(t *testing.T) {
+	categories, err := Parse("test, Lockfile,,test")
+	require.NoError(t, err)
+	assert.Equal(t, []Category{Test, Lockfile}, categories)
+
+	categories, err = Parse("")
+	require.NoError(t, err)
+	assert.Empty(t, categories)
+
+	_, err = Parse("test,docs")
+	assert.ErrorContains(t, err, `"docs"`)
+}
+
+func TestFile(t *testing.T) {
+	assert.Equal(t, "main.go", File(&source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: "main.go"}},
+	}))
+	assert.Equal(t, "/tmp/keys.txt", File(&source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "/tmp/keys.txt"}},
+	}))
+	assert.Empty(t, File(&source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Syslog{Syslog: &source_metadatapb.Syslog{Hostname: "host"}},
+	}))
+	assert.Empty(t, File(nil))
+}
+
+func TestIsDependencyFile(t *testing.T) {
+	for _, file := range []string{"package-lock.json", "web/yarn.lock", `app\Pipfile.lock`, "requirements-dev.txt", ".npmrc", "Gemfile", "pom.xml"} {
+		assert.True(t, IsDependencyFile(file), file)
+	}
+	for _, file := range []string{"", "main.go", "requirements.md", "lockfile.json"} {
+		assert.False(t, IsDependencyFile(file), file)
+	}
+}
+
+func TestIsCredentialFile(t *testing.T) {
+	for _, file := range []string{".env", "app/.env.production", "prod.env", `home\.netrc`, ".git-credentials", ".npmrc", ".aws/credentials"} {
+		assert.True(t, IsCredentialFile(file), file)
+	}
+	for _, file := range []string{"", "main.go", "environment.go", ".envoy.yaml", "credentials.json"} {
+		assert.False(t, IsCredentialFile(file), file)
+	}
+}
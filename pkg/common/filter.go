@@ -3,19 +3,99 @@ package common
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
+// filterSniffLen is how many bytes from the start of an object PassReader
+// reads ahead to detect its MIME type.
+const filterSniffLen = 3072
+
+// Filter decides which objects of a source get scanned. Exclude rules always
+// win, and every kind of include rule (paths, MIME types) that is set must
+// match for an object to pass.
 type Filter struct {
 	include *FilterRuleSet
 	exclude *FilterRuleSet
+
+	maxSize     int64
+	includeMIME []string
+	excludeMIME []string
 }
 
 type FilterRuleSet []regexp.Regexp
 
+// FilterOption configures a Filter in addition to its rules files.
+type FilterOption func(*Filter) error
+
+// WithIncludePaths adds regular expressions a path must match to pass.
+func WithIncludePaths(patterns ...string) FilterOption {
+	return func(filter *Filter) error {
+		rules, err := compileFilterRules(patterns)
+		if err != nil {
+			return fmt.Errorf("could not create include rules: %w", err)
+		}
+		*filter.include = append(*filter.include, rules...)
+		return nil
+	}
+}
+
+// WithExcludePaths adds regular expressions that exclude the paths they match.
+func WithExcludePaths(patterns ...string) FilterOption {
+	return func(filter *Filter) error {
+		rules, err := compileFilterRules(patterns)
+		if err != nil {
+			return fmt.Errorf("could not create exclude rules: %w", err)
+		}
+		*filter.exclude = append(*filter.exclude, rules...)
+		return nil
+	}
+}
+
+// WithMaxSize excludes objects larger than size bytes. Zero means no limit.
+func WithMaxSize(size int64) FilterOption {
+	return func(filter *Filter) error {
+		if size < 0 {
+			return fmt.Errorf("invalid max size: %d", size)
+		}
+		filter.maxSize = size
+		return nil
+	}
+}
+
+// WithIncludeMIMETypes only passes content detected as one of the MIME types.
+// A type matches its subtypes, eg. "text/plain" matches "application/json",
+// and "image/*" matches every image type.
+func WithIncludeMIMETypes(types ...string) FilterOption {
+	return func(filter *Filter) error {
+		types, err := normalizeMIMETypes(types)
+		if err != nil {
+			return err
+		}
+		filter.includeMIME = append(filter.includeMIME, types...)
+		return nil
+	}
+}
+
+// WithExcludeMIMETypes excludes content detected as one of the MIME types,
+// matched the same way as WithIncludeMIMETypes.
+func WithExcludeMIMETypes(types ...string) FilterOption {
+	return func(filter *Filter) error {
+		types, err := normalizeMIMETypes(types)
+		if err != nil {
+			return err
+		}
+		filter.excludeMIME = append(filter.excludeMIME, types...)
+		return nil
+	}
+}
+
 // FilterEmpty returns a Filter that always passes.
 func FilterEmpty() *Filter {
 	filter, err := FilterFromFiles("", "")
@@ -26,8 +106,9 @@ func FilterEmpty() *Filter {
 	return filter
 }
 
-// FilterFromFiles creates a Filter using the rules in the provided include and exclude files.
-func FilterFromFiles(includeFilterPath, excludeFilterPath string) (*Filter, error) {
+// FilterFromFiles creates a Filter using the rules in the provided include and exclude files,
+// and the options.
+func FilterFromFiles(includeFilterPath, excludeFilterPath string, opts ...FilterOption) (*Filter, error) {
 	includeRules, err := FilterRulesFromFile(includeFilterPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not create include rules: %s", err)
@@ -37,15 +118,20 @@ func FilterFromFiles(includeFilterPath, excludeFilterPath string) (*Filter, erro
 		return nil, fmt.Errorf("could not create exclude rules: %s", err)
 	}
 
-	// If no includeFilterPath is provided, every pattern should pass the include rules.
-	if includeFilterPath == "" {
-		includeRules = &FilterRuleSet{*regexp.MustCompile("")}
-	}
-
 	filter := &Filter{
 		include: includeRules,
 		exclude: excludeRules,
 	}
+	for _, opt := range opts {
+		if err := opt(filter); err != nil {
+			return nil, err
+		}
+	}
+
+	// If no include rules are provided, every pattern should pass the include rules.
+	if includeFilterPath == "" && len(*filter.include) == 0 {
+		filter.include = &FilterRuleSet{*regexp.MustCompile("")}
+	}
 
 	return filter, nil
 }
@@ -92,6 +178,33 @@ func FilterRulesFromFile(source string) (*FilterRuleSet, error) {
 	return &rules, nil
 }
 
+func compileFilterRules(patterns []string) (FilterRuleSet, error) {
+	rules := make(FilterRuleSet, 0, len(patterns))
+	for _, p := range patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("can not compile regular expression: %s", p)
+		}
+		rules = append(rules, *pattern)
+	}
+	return rules, nil
+}
+
+func normalizeMIMETypes(types []string) ([]string, error) {
+	normalized := make([]string, 0, len(types))
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if major, minor, ok := strings.Cut(t, "/"); !ok || major == "" || minor == "" {
+			return nil, fmt.Errorf("invalid MIME type: %q", t)
+		}
+		normalized = append(normalized, t)
+	}
+	return normalized, nil
+}
+
 // Pass returns true if the include FilterRuleSet matches the pattern and the exclude FilterRuleSet does not match.
 func (filter *Filter) Pass(object string) bool {
 	if filter == nil {
@@ -102,6 +215,71 @@ func (filter *Filter) Pass(object string) bool {
 	return !excluded && included
 }
 
+// MaxSize returns the max size of objects that pass, or 0 if there is no limit.
+func (filter *Filter) MaxSize() int64 {
+	if filter == nil {
+		return 0
+	}
+	return filter.maxSize
+}
+
+// PassSize returns false if the filter has a max size and size exceeds it.
+func (filter *Filter) PassSize(size int64) bool {
+	if filter == nil || filter.maxSize == 0 {
+		return true
+	}
+	return size <= filter.maxSize
+}
+
+// SniffsContent returns true if the filter has MIME type rules, so callers
+// know whether reading ahead for PassContent is worth it.
+func (filter *Filter) SniffsContent() bool {
+	if filter == nil {
+		return false
+	}
+	return len(filter.includeMIME) > 0 || len(filter.excludeMIME) > 0
+}
+
+// PassContent detects the MIME type of head, the start of an object, and
+// returns true if it passes the MIME type rules.
+func (filter *Filter) PassContent(head []byte) bool {
+	if !filter.SniffsContent() {
+		return true
+	}
+	mime := mimetype.Detect(head)
+	if mimeMatches(filter.excludeMIME, mime) {
+		return false
+	}
+	return len(filter.includeMIME) == 0 || mimeMatches(filter.includeMIME, mime)
+}
+
+// PassReader calls PassContent with the start of r. The returned reader still
+// yields all of r.
+func (filter *Filter) PassReader(r io.Reader) (io.Reader, bool) {
+	if !filter.SniffsContent() {
+		return r, true
+	}
+	br := bufio.NewReaderSize(r, filterSniffLen)
+	head, _ := br.Peek(filterSniffLen)
+	return br, filter.PassContent(head)
+}
+
+// mimeMatches returns true if any of the types matches mime or one of its parents.
+func mimeMatches(types []string, mime *mimetype.MIME) bool {
+	for ; mime != nil; mime = mime.Parent() {
+		detected, _, _ := strings.Cut(mime.String(), ";")
+		for _, t := range types {
+			if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(detected, prefix) {
+				return true
+			}
+			if mime.Is(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Matches will return true if any of the regular expressions in the FilterRuleSet match the pattern.
 func (rules *FilterRuleSet) Matches(object string) bool {
 	if rules == nil {
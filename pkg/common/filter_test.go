@@ -1,8 +1,10 @@
 package common
 
 import (
+	"io"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -176,3 +178,119 @@ func testFilterWriteFile(filename string, content []byte) error {
 	}
 	return f.Close()
 }
+
+func TestFilterOptions(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	json := []byte(`{"key": "value"}`)
+	text := []byte("hello world\n")
+
+	type filterTest struct {
+		opts    []FilterOption
+		pattern string
+		size    int64
+		content []byte
+		pass    bool
+	}
+	tests := map[string]filterTest{
+		"NoOptionsPass": {
+			pattern: "main.go", size: 1 << 30, content: png, pass: true,
+		},
+		"IncludePathsPass": {
+			opts:    []FilterOption{WithIncludePaths(`^src/`, `\.go$`)},
+			pattern: "main.go", content: text, pass: true,
+		},
+		"IncludePathsFiltered": {
+			opts:    []FilterOption{WithIncludePaths(`^src/`)},
+			pattern: "main.go", content: text, pass: false,
+		},
+		"ExcludePathsFiltered": {
+			opts:    []FilterOption{WithExcludePaths(`^vendor/`)},
+			pattern: "vendor/lib.go", content: text, pass: false,
+		},
+		"ExcludeWinsOverInclude": {
+			opts:    []FilterOption{WithIncludePaths(`\.go$`), WithExcludePaths(`_test\.go$`)},
+			pattern: "main_test.go", content: text, pass: false,
+		},
+		"MaxSizePass": {
+			opts:    []FilterOption{WithMaxSize(100)},
+			pattern: "main.go", size: 100, content: text, pass: true,
+		},
+		"MaxSizeFiltered": {
+			opts:    []FilterOption{WithMaxSize(100)},
+			pattern: "main.go", size: 101, content: text, pass: false,
+		},
+		"IncludeMIMEPass": {
+			opts:    []FilterOption{WithIncludeMIMETypes("text/plain")},
+			pattern: "config.json", content: json, pass: true,
+		},
+		"IncludeMIMEFiltered": {
+			opts:    []FilterOption{WithIncludeMIMETypes("text/plain")},
+			pattern: "logo.png", content: png, pass: false,
+		},
+		"ExcludeMIMEWildcardFiltered": {
+			opts:    []FilterOption{WithExcludeMIMETypes("image/*")},
+			pattern: "logo.png", content: png, pass: false,
+		},
+		"ExcludeMIMEWildcardPass": {
+			opts:    []FilterOption{WithExcludeMIMETypes("image/*")},
+			pattern: "README", content: text, pass: true,
+		},
+		"ExcludeMIMEWinsOverInclude": {
+			opts:    []FilterOption{WithIncludeMIMETypes("text/*"), WithExcludeMIMETypes("application/json")},
+			pattern: "config.json", content: json, pass: false,
+		},
+		"PathAndMIMEMustBothPass": {
+			opts:    []FilterOption{WithIncludePaths(`\.png$`), WithIncludeMIMETypes("text/plain")},
+			pattern: "logo.png", content: png, pass: false,
+		},
+	}
+
+	for name, test := range tests {
+		filter, err := FilterFromFiles("", "", test.opts...)
+		if err != nil {
+			t.Fatalf("%s: failed to create filter: %s", name, err)
+		}
+		pass := filter.Pass(test.pattern) && filter.PassSize(test.size) && filter.PassContent(test.content)
+		if pass != test.pass {
+			t.Errorf("%s: unexpected filter result. pattern: %q, pass: %t", name, test.pattern, pass)
+		}
+	}
+}
+
+func TestFilterOptionsInvalid(t *testing.T) {
+	for name, opt := range map[string]FilterOption{
+		"IncludePaths": WithIncludePaths("("),
+		"ExcludePaths": WithExcludePaths("["),
+		"MaxSize":      WithMaxSize(-1),
+		"MIMEType":     WithIncludeMIMETypes("text"),
+	} {
+		if _, err := FilterFromFiles("", "", opt); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFilterNil(t *testing.T) {
+	var filter *Filter
+	if !filter.Pass("a") || !filter.PassSize(1<<40) || !filter.PassContent(nil) || filter.SniffsContent() {
+		t.Error("nil filter should pass everything")
+	}
+}
+
+func TestFilterPassReader(t *testing.T) {
+	filter, err := FilterFromFiles("", "", WithExcludeMIMETypes("image/png"))
+	if err != nil {
+		t.Fatalf("failed to create filter: %s", err)
+	}
+	text := strings.Repeat("hello world\n", 1000)
+	r, pass := filter.PassReader(strings.NewReader(text))
+	if !pass {
+		t.Error("text should pass")
+	}
+	if data, _ := io.ReadAll(r); string(data) != text {
+		t.Error("reader should yield all of the content")
+	}
+	if _, pass := filter.PassReader(strings.NewReader("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")); pass {
+		t.Error("png should be filtered")
+	}
+}
@@ -1,35 +1,90 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+
 	"github.com/trufflesecurity/trufflehog/v3/pkg/custom_detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/custom_detectorspb"
-	"github.com/trufflesecurity/trufflehog/v3/pkg/protoyaml"
 )
 
 // Config holds user supplied configuration.
 type Config struct {
 	Detectors []detectors.Detector
+	// Sources are scanned by the scan command.
+	Sources      []Source
+	Output       Output
+	Verification Verification
+}
+
+// Source is a source to scan declared in a configuration file.
+type Source struct {
+	// Name of the source. Defaults to "trufflehog - <type>".
+	Name string `json:"name"`
+	// Type of the source, such as "git" or "s3".
+	Type string `json:"type"`
+	// Connection configures the source, in the JSON form of its message in
+	// sources.proto. Its credential references are resolved when the
+	// configuration is read.
+	Connection json.RawMessage `json:"connection"`
+}
+
+// Output holds the output settings of a configuration file. Flags setting the
+// same options take precedence.
+type Output struct {
+	// Format is the output format, as accepted by --format.
+	Format string `json:"format"`
+	// Results are the types of results to output, as accepted by --results.
+	Results []string `json:"results"`
+}
+
+// Verification holds the verification settings of a configuration file.
+type Verification struct {
+	// Enabled turns verification off when set to false.
+	Enabled *bool `json:"enabled"`
+	// AllowOverlap allows verifying similar credentials across detectors.
+	AllowOverlap bool `json:"allow_overlap"`
 }
 
 // Read parses a given filename into a Config.
-func Read(filename string) (*Config, error) {
+func Read(ctx context.Context, filename string) (*Config, error) {
 	input, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	return NewYAML(input)
+	return NewYAML(ctx, input)
 }
 
-// NewYAML parses the given YAML data into a Config.
-func NewYAML(input []byte) (*Config, error) {
-	// Parse the raw YAML into a structure.
-	var messages custom_detectorspb.CustomDetectors
-	if err := protoyaml.UnmarshalStrict(input, &messages); err != nil {
+// NewYAML parses the given YAML data into a Config. Credential references in
+// source connections are resolved with ctx.
+func NewYAML(ctx context.Context, input []byte) (*Config, error) {
+	// Parse the raw YAML into a structure. Detectors are kept raw so they
+	// can be parsed strictly against their proto definition.
+	var file struct {
+		Detectors    json.RawMessage `json:"detectors"`
+		Sources      []Source        `json:"sources"`
+		Output       Output          `json:"output"`
+		Verification Verification    `json:"verification"`
+	}
+	if err := yaml.UnmarshalStrict(input, &file); err != nil {
 		return nil, err
 	}
+	var messages custom_detectorspb.CustomDetectors
+	if len(file.Detectors) > 0 {
+		raw, err := json.Marshal(map[string]json.RawMessage{"detectors": file.Detectors})
+		if err != nil {
+			return nil, err
+		}
+		if err := protojson.Unmarshal(raw, &messages); err != nil {
+			return nil, err
+		}
+	}
 	// Convert the structured YAML into detectors.
 	var d []detectors.Detector
 	for _, detectorConfig := range messages.Detectors {
@@ -39,7 +94,29 @@ func NewYAML(input []byte) (*Config, error) {
 		}
 		d = append(d, detector)
 	}
+
+	refs := newReferences(ctx)
+	defer refs.close()
+	for i := range file.Sources {
+		source := &file.Sources[i]
+		if source.Type == "" {
+			return nil, fmt.Errorf("source %d: missing type", i+1)
+		}
+		if len(source.Connection) == 0 || string(source.Connection) == "null" {
+			source.Connection = nil
+			continue
+		}
+		connection, err := refs.resolve(source.Connection)
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", i+1, err)
+		}
+		source.Connection = connection
+	}
+
 	return &Config{
-		Detectors: d,
+		Detectors:    d,
+		Sources:      file.Sources,
+		Output:       file.Output,
+		Verification: file.Verification,
 	}, nil
 }
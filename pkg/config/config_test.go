@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewYAML(t *testing.T) {
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_token")
+	t.Setenv("TEST_BUCKET", "bucket")
+
+	conf, err := NewYAML(context.Background(), []byte(`
+detectors:
+- name: internal-token
+  keywords: [internal]
+  regex:
+    token: 'internal_[a-z0-9]{16}'
+sources:
+- name: backend
+  type: git
+  connection:
+    uri: https://github.com/example/backend.git
+- type: github
+  connection:
+    organizations: [example]
+    token: ${env:TEST_GITHUB_TOKEN}
+- type: s3
+  connection:
+    buckets: ["${env:TEST_BUCKET}-logs", "${env:TEST_BUCKET}-data"]
+    max_object_size: 1048576
+- type: filesystem
+output:
+  format: sarif
+  results: [verified, unknown]
+verification:
+  enabled: false
+  allow_overlap: true
+`))
+	require.NoError(t, err)
+	assert.Len(t, conf.Detectors, 1)
+
+	require.Len(t, conf.Sources, 4)
+	assert.Equal(t, "backend", conf.Sources[0].Name)
+	assert.Equal(t, "git", conf.Sources[0].Type)
+	assert.JSONEq(t, `{"uri": "https://github.com/example/backend.git"}`, string(conf.Sources[0].Connection))
+	assert.JSONEq(t, `{"organizations": ["example"], "token": "ghp_token"}`, string(conf.Sources[1].Connection))
+	assert.JSONEq(t, `{"buckets": ["bucket-logs", "bucket-data"], "max_object_size": 1048576}`, string(conf.Sources[2].Connection))
+	assert.Nil(t, conf.Sources[3].Connection)
+
+	assert.Equal(t, Output{Format: "sarif", Results: []string{"verified", "unknown"}}, conf.Output)
+	require.NotNil(t, conf.Verification.Enabled)
+	assert.False(t, *conf.Verification.Enabled)
+	assert.True(t, conf.Verification.AllowOverlap)
+}
+
+func TestNewYAML_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":              "source:\n- type: git\n",
+		"unknown detector field":   "detectors:\n- name: x\n  keyword: [x]\n",
+		"missing source type":      "sources:\n- connection: {uri: x}\n",
+		"unset variable":           "sources:\n- type: git\n  connection: {uri: '${env:TEST_UNSET_VARIABLE}'}\n",
+		"unknown provider":         "sources:\n- type: git\n  connection: {uri: '${nope:x}'}\n",
+		"unknown verification key": "verification:\n  enable: true\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewYAML(context.Background(), []byte(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	vaultRequests := 0
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vaultRequests++
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/ci":
+			_, _ = w.Write([]byte(`{"data": {"data": {"token": "kv2-token"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/ci":
+			_, _ = w.Write([]byte(`{"data": {"token": "kv1-token", "port": 8200}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	t.Setenv("TEST_JSON_SECRET", `{"user": "admin", "password": "hunter2"}`)
+
+	refs := newReferences(context.Background())
+	defer refs.close()
+	connection, err := refs.resolve(json.RawMessage(`{
+		"file": "${file:` + tokenFile + `}",
+		"kv2": "${vault:secret/data/ci#token}",
+		"kv1": "${vault:kv/ci#token}",
+		"port": "${vault:kv/ci#port}",
+		"again": "${vault:secret/data/ci#token}",
+		"basic": "${env:TEST_JSON_SECRET#user}:${env:TEST_JSON_SECRET#password}"
+	}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"file": "file-token",
+		"kv2": "kv2-token",
+		"kv1": "kv1-token",
+		"port": "8200",
+		"again": "kv2-token",
+		"basic": "admin:hunter2"
+	}`, string(connection))
+	// References are only resolved once.
+	assert.Equal(t, 3, vaultRequests)
+
+	for _, ref := range []string{
+		"${vault:secret/data/ci}",
+		"${vault:secret/data/missing#token}",
+		"${env:TEST_JSON_SECRET#missing}",
+		"${file:" + filepath.Join(dir, "missing") + "}",
+	} {
+		_, err := refs.resolve(json.RawMessage(`"` + ref + `"`))
+		assert.Error(t, err, ref)
+	}
+}
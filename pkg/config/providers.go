@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// provider reads credentials from a store.
+type provider struct {
+	read func(ctx context.Context, name string) (string, error)
+	// requiresKey is set for stores whose credentials are always key-value
+	// maps, so references must select a key.
+	requiresKey bool
+	close       func()
+}
+
+// providers create the provider of each reference prefix:
+//
+//	${env:NAME}                          environment variable
+//	${file:PATH}                         file contents, without trailing newlines
+//	${aws-sm:SECRET-ID}                  AWS Secrets Manager secret name or ARN
+//	${gcp-sm:projects/P/secrets/S}       GCP Secret Manager secret, optionally with /versions/V
+//	${vault:PATH#KEY}                    key of a Vault secret, such as secret/data/ci#token
+//
+// Secrets holding JSON objects can be narrowed to one key with #KEY.
+var providers = map[string]func(ctx context.Context) (*provider, error){
+	"env":    newEnvProvider,
+	"file":   newFileProvider,
+	"aws-sm": newAWSProvider,
+	"gcp-sm": newGCPProvider,
+	"vault":  newVaultProvider,
+}
+
+func newEnvProvider(context.Context) (*provider, error) {
+	return &provider{read: func(_ context.Context, name string) (string, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	}}, nil
+}
+
+func newFileProvider(context.Context) (*provider, error) {
+	return &provider{read: func(_ context.Context, name string) (string, error) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}}, nil
+}
+
+func newAWSProvider(context.Context) (*provider, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, err
+	}
+	return &provider{read: func(ctx context.Context, name string) (string, error) {
+		cfg := aws.NewConfig()
+		// Secrets referenced by ARN are read from their region.
+		if parsed, err := arn.Parse(name); err == nil {
+			cfg = cfg.WithRegion(parsed.Region)
+		}
+		out, err := secretsmanager.New(sess, cfg).GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(name),
+		})
+		if err != nil {
+			return "", err
+		}
+		if out.SecretString != nil {
+			return *out.SecretString, nil
+		}
+		return string(out.SecretBinary), nil
+	}}, nil
+}
+
+func newGCPProvider(ctx context.Context) (*provider, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &provider{
+		read: func(ctx context.Context, name string) (string, error) {
+			if !strings.Contains(name, "/versions/") {
+				name += "/versions/latest"
+			}
+			resp, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+			if err != nil {
+				return "", err
+			}
+			return string(resp.GetPayload().GetData()), nil
+		},
+		close: func() { _ = client.Close() },
+	}, nil
+}
+
+// newVaultProvider reads secrets from the Vault server at VAULT_ADDR, with the
+// token in VAULT_TOKEN or ~/.vault-token.
+func newVaultProvider(context.Context) (*provider, error) {
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set")
+		}
+		data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set and ~/.vault-token can't be read")
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	namespace := os.Getenv("VAULT_NAMESPACE")
+	client := common.SaneHttpClient()
+
+	return &provider{
+		read: func(ctx context.Context, path string) (string, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+			if err != nil {
+				return "", err
+			}
+			req.Header.Set("X-Vault-Token", token)
+			if namespace != "" {
+				req.Header.Set("X-Vault-Namespace", namespace)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+			}
+			var secret struct {
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+				return "", err
+			}
+			// Version 2 of the KV secrets engine nests the secret's data
+			// next to its metadata.
+			if data, ok := secret.Data["data"]; ok {
+				if _, ok := secret.Data["metadata"]; ok {
+					return string(data), nil
+				}
+			}
+			data, err := json.Marshal(secret.Data)
+			return string(data), err
+		},
+		requiresKey: true,
+	}, nil
+}
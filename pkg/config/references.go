@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// referencePattern matches credential references such as ${env:GITHUB_TOKEN},
+// which name a provider and the credential to read from it.
+var referencePattern = regexp.MustCompile(`\$\{([a-z][a-z0-9-]*):([^}]+)\}`)
+
+// references resolves the credential references of a configuration file. The
+// clients of remote providers are created on first use and shared by every
+// reference, and each reference is only resolved once.
+type references struct {
+	ctx       context.Context
+	providers map[string]*provider
+	cache     map[string]string
+}
+
+// ResolveReferences replaces the credential references in the string values of
+// a JSON document, such as a source connection, with the credentials they
+// reference.
+func ResolveReferences(ctx context.Context, data json.RawMessage) (json.RawMessage, error) {
+	refs := newReferences(ctx)
+	defer refs.close()
+	return refs.resolve(data)
+}
+
+func newReferences(ctx context.Context) *references {
+	return &references{ctx: ctx, providers: make(map[string]*provider), cache: make(map[string]string)}
+}
+
+// close releases the clients of the providers used.
+func (r *references) close() {
+	for _, p := range r.providers {
+		if p.close != nil {
+			p.close()
+		}
+	}
+}
+
+// resolve replaces the credential references in the string values of a JSON
+// document with the credentials they reference, so secrets don't have to be
+// written into configuration files.
+func (r *references) resolve(data json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as they are written instead of converting them to floats.
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	value, err := r.resolveValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func (r *references) resolveValue(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v)
+	case []any:
+		for i := range v {
+			resolved, err := r.resolveValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+	case map[string]any:
+		for key, item := range v {
+			resolved, err := r.resolveValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+	}
+	return value, nil
+}
+
+func (r *references) resolveString(s string) (string, error) {
+	var err error
+	resolved := referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		var value string
+		if value, err = r.lookup(ref); err != nil {
+			err = fmt.Errorf("could not resolve %s: %w", ref, err)
+		}
+		return value
+	})
+	return resolved, err
+}
+
+func (r *references) lookup(ref string) (string, error) {
+	if value, ok := r.cache[ref]; ok {
+		return value, nil
+	}
+	match := referencePattern.FindStringSubmatch(ref)
+	name, key, hasKey := strings.Cut(match[2], "#")
+	p, err := r.provider(match[1])
+	if err != nil {
+		return "", err
+	}
+	value, err := p.read(r.ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if hasKey {
+		if value, err = selectKey(value, key); err != nil {
+			return "", err
+		}
+	} else if p.requiresKey {
+		return "", fmt.Errorf("%s references must select a key with #KEY", match[1])
+	}
+	r.cache[ref] = value
+	return value, nil
+}
+
+func (r *references) provider(name string) (*provider, error) {
+	if p, ok := r.providers[name]; ok {
+		return p, nil
+	}
+	newProvider, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown credential provider %q", name)
+	}
+	p, err := newProvider(r.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not set up %s credential provider: %w", name, err)
+	}
+	r.providers[name] = p
+	return p, nil
+}
+
+// selectKey returns a key of a credential holding a JSON object, such as the
+// key-value secrets of AWS Secrets Manager.
+func selectKey(value, key string) (string, error) {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return "", fmt.Errorf("credential isn't a JSON object to select key %q from", key)
+	}
+	switch v := object[key].(type) {
+	case nil:
+		return "", fmt.Errorf("credential has no key %q", key)
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	}
+}
@@ -123,6 +123,14 @@ func WithTimeoutCause(parent Context, timeout time.Duration, cause error) (Conte
 	return lCtx, cancel
 }
 
+// WithoutCancel returns context.WithoutCancel with the log object propagated.
+func WithoutCancel(parent Context) Context {
+	return logCtx{
+		log:     parent.Logger(),
+		Context: context.WithoutCancel(parent),
+	}
+}
+
 // Cause returns the context.Cause of the context.
 func Cause(ctx context.Context) error {
 	return context.Cause(ctx)
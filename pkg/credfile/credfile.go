@@ -0,0 +1,269 @@
+// Package credfile parses the files tools read credentials from by name, such
+// as dotenv files, .npmrc, .pypirc, .netrc and .git-credentials. A value named
+// like a secret, such as DB_PASSWORD or _authToken, is a secret whatever it
+// looks like, so it can be found even when no detector recognizes it, and the
+// name of any secret found in such a file tells what it is for.
+package credfile
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Entry is a named value of a credential file.
+type Entry struct {
+	// Name is the name of the value, prefixed with its section in INI files,
+	// as in "pypi.password". Values of files that don't name them, such as
+	// the passwords of .netrc and .git-credentials, are named password.
+	Name  string
+	Value string
+}
+
+// minValueLength is the length below which values are too short to be
+// secrets.
+const minValueLength = 4
+
+var (
+	// secretWords are the words names of secrets end with, as in API_KEY
+	// and _authToken.
+	secretWords = []string{
+		"secret", "secrets", "token", "password", "passwd", "pwd", "pass",
+		"passphrase", "key", "apikey", "credential", "credentials", "auth",
+		"authtoken", "pat",
+	}
+	// publicWords make a key that follows them public, as in PUBLIC_KEY.
+	publicWords = []string{"public", "pub", "publishable"}
+	// attributeWords are the words names end with when they describe a
+	// secret rather than hold it, as in SECRET_NAME and TOKEN_TTL.
+	attributeWords = []string{
+		"name", "names", "path", "file", "dir", "url", "uri", "id", "arn",
+		"region", "version", "type", "enabled", "ttl", "expiry", "length",
+	}
+	// placeholders are values examples are filled in with.
+	placeholders = []string{
+		"null", "none", "nil", "true", "false", "undefined", "changeme",
+		"example", "redacted", "secret", "password", "token", "value",
+	}
+	// netrcKeywords start the lines of .netrc files.
+	netrcKeywords = []string{"machine", "default", "login", "password", "account", "macdef"}
+)
+
+// Parse returns the entries of data, which is the whole of a credential file
+// or a chunk of it, in order. Each line is parsed as whichever of the formats
+// of credential files it's in: assignments such as KEY=value, export
+// KEY="value" and key: value, INI sections, .netrc tokens, URLs with
+// passwords as in .git-credentials, and the host:port:database:user:password
+// lines of .pgpass. Values that are placeholders or references to other
+// variables, such as ${DB_PASSWORD}, aren't entries.
+func Parse(data []byte) []Entry {
+	var entries []Entry
+	var section string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		lineEntries, assigned := parseLine(line)
+		for _, e := range lineEntries {
+			if section != "" && assigned {
+				e.Name = section + "." + e.Name
+			}
+			if !IsPlaceholder(e.Value) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries
+}
+
+// parseLine returns the entries of line, and whether it assigns a value to a
+// name, which is then in the section of the line.
+func parseLine(line string) ([]Entry, bool) {
+	if password, ok := urlPassword(line); ok {
+		return []Entry{{Name: "password", Value: password}}, false
+	}
+	if password, ok := pgpassPassword(line); ok {
+		return []Entry{{Name: "password", Value: password}}, false
+	}
+	if name, value, ok := assignment(line); ok {
+		return []Entry{{Name: name, Value: value}}, true
+	}
+
+	fields := strings.Fields(line)
+	if !slices.Contains(netrcKeywords, fields[0]) {
+		return nil, false
+	}
+	var entries []Entry
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "password" || fields[i] == "account" {
+			entries = append(entries, Entry{Name: fields[i], Value: fields[i+1]})
+		}
+	}
+	return entries, false
+}
+
+// urlPassword returns the password of line if it's a URL with one, as the
+// lines of .git-credentials are.
+func urlPassword(line string) (string, bool) {
+	if strings.ContainsFunc(line, unicode.IsSpace) || !strings.Contains(line, "://") {
+		return "", false
+	}
+	u, err := url.Parse(line)
+	if err != nil || u.Scheme == "" {
+		return "", false
+	}
+	return u.User.Password()
+}
+
+// pgpassPassword returns the password of line if it's a line of a .pgpass
+// file, in which colons and backslashes of the password are escaped.
+func pgpassPassword(line string) (string, bool) {
+	fields := strings.SplitN(line, ":", 5)
+	if len(fields) != 5 || strings.ContainsAny(line, " \t") {
+		return "", false
+	}
+	if port := fields[1]; port != "*" && strings.ContainsFunc(port, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		return "", false
+	}
+	for _, f := range fields[:4] {
+		if f == "" || strings.ContainsAny(f, "=/") {
+			return "", false
+		}
+	}
+	return strings.NewReplacer(`\:`, ":", `\\`, `\`).Replace(fields[4]), true
+}
+
+// assignment returns the name and value of line if it assigns a value with an
+// equals sign, as dotenv and INI files do, or a colon, as YAML does.
+func assignment(line string) (string, string, bool) {
+	line = strings.TrimPrefix(line, "export ")
+	i := strings.Index(line, "=")
+	if colon := strings.Index(line, ": "); colon >= 0 && (i < 0 || colon < i) {
+		i = colon
+	}
+	if i <= 0 {
+		return "", "", false
+	}
+	name := strings.Trim(strings.TrimSpace(line[:i]), `"'`)
+	if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+		return "", "", false
+	}
+	return name, unquote(strings.TrimSpace(line[i+1:])), true
+}
+
+// unquote returns the value between the quotes of value if it's quoted, or
+// value without its inline comment if it isn't.
+func unquote(value string) string {
+	if value == "" {
+		return ""
+	}
+	if q := value[0]; q == '"' || q == '\'' || q == '`' {
+		if end := strings.IndexByte(value[1:], q); end >= 0 {
+			return value[1 : end+1]
+		}
+		return value[1:]
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// IsPlaceholder reports whether value is too short or too plain to be a
+// secret, or refers to a secret kept elsewhere.
+func IsPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	switch {
+	case len(value) < minValueLength,
+		strings.Trim(value, value[:1]) == "",
+		strings.HasPrefix(value, "$"),
+		strings.Contains(value, "${"),
+		strings.Contains(value, "{{"),
+		strings.Contains(value, "%("),
+		strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">"),
+		strings.HasPrefix(lower, "your"),
+		strings.Contains(lower, "xxxx"),
+		strings.Contains(lower, "****"),
+		slices.Contains(placeholders, lower):
+		return true
+	}
+	return false
+}
+
+// SensitiveName reports whether name is the name of a secret, such as
+// DB_PASSWORD, stripeSecretKey, or the //registry.npmjs.org/:_authToken of an
+// .npmrc file, rather than of a setting such as PUBLIC_KEY or TOKEN_TTL.
+func SensitiveName(name string) bool {
+	if strings.HasPrefix(name, "//") {
+		// The registry of an .npmrc setting is a URL, whose words don't
+		// matter.
+		name = name[strings.LastIndex(name, ":")+1:]
+	}
+	words := splitWords(name)
+	if len(words) == 0 {
+		return false
+	}
+	last := words[len(words)-1]
+	switch {
+	case slices.Contains(secretWords, last):
+		return last != "key" || len(words) == 1 || !slices.Contains(publicWords, words[len(words)-2])
+	case slices.Contains(attributeWords, last):
+		return false
+	}
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "secret") || strings.Contains(lower, "password") || strings.Contains(lower, "token")
+}
+
+// splitWords splits name into its lowercased words, which are separated by
+// punctuation, as in API_KEY and pypi.password, or by case, as in authToken.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	prev := rune(0)
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = word[:0]
+		case unicode.IsUpper(r) && unicode.IsLower(prev) && len(word) > 0:
+			words = append(words, string(word))
+			word = append(word[:0], unicode.ToLower(r))
+		default:
+			word = append(word, unicode.ToLower(r))
+		}
+		prev = r
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// Variable returns the name of the entry of data whose value is, or contains,
+// secret, or an empty string if none does.
+func Variable(data, secret []byte) string {
+	s := strings.TrimSpace(string(secret))
+	if s == "" {
+		return ""
+	}
+	entries := Parse(data)
+	for _, e := range entries {
+		if e.Value == s {
+			return e.Name
+		}
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Value, s) {
+			return e.Name
+		}
+	}
+	return ""
+}
@@ -60,6 +60,7 @@ func (d *EscapedUnicode) FromChunk(chunk *sources.Chunk) *DecodableChunk {
 				SourceMetadata: chunk.SourceMetadata,
 				SourceType:     chunk.SourceType,
 				Verify:         chunk.Verify,
+				IgnoreFile:     chunk.IgnoreFile,
 			},
 		}
 	} else {
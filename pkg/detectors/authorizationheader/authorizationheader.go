@@ -0,0 +1,213 @@
+package authorizationheader
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the credentials sent in the Authorization headers of HTTP
+// requests: those written in code and config, recorded in HTTP archives,
+// and given to curl. The headers don't tell which service they're for
+// outside of a request, so the credentials aren't verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+const (
+	// minBearerLen is the length below which bearer tokens are taken for
+	// placeholders.
+	minBearerLen = 16
+)
+
+var (
+	headerPat = regexp.MustCompile(`(?i)\bauthorization["']?\s*[:=,]\s*["'` + "`" + `]?(basic|bearer)\s+([A-Za-z0-9+/._~-]+=*)`)
+	// HTTP archives hold the headers of requests as name and value pairs.
+	harPat  = regexp.MustCompile(`(?i)"name"\s*:\s*"authorization"\s*,\s*"value"\s*:\s*"(basic|bearer)\s+([A-Za-z0-9+/._~-]+=*)"`)
+	curlPat = regexp.MustCompile(`(?i)\bcurl\b(?:[^\n]|\\\n)*?\s(?:-u|--user)(?:\s+|=)["']?([^\s"':]+:[^\s"']+)`)
+
+	// issuerPrefixes are the prefixes of the opaque bearer tokens of
+	// services.
+	issuerPrefixes = []struct{ prefix, issuer string }{
+		{"github_pat_", "github"},
+		{"ghp_", "github"},
+		{"gho_", "github"},
+		{"ghu_", "github"},
+		{"ghs_", "github"},
+		{"glpat-", "gitlab"},
+		{"xoxb-", "slack"},
+		{"xoxp-", "slack"},
+		{"xoxa-", "slack"},
+		{"xoxe-", "slack"},
+		{"ya29.", "google"},
+		{"sk-ant-", "anthropic"},
+		{"sk-", "openai"},
+		{"shpat_", "shopify"},
+		{"npm_", "npm"},
+		{"hf_", "huggingface"},
+		{"dop_v1_", "digitalocean"},
+		{"EAA", "facebook"},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"authorization", "curl"}
+}
+
+// FromData will find the credentials of Authorization headers and of curl
+// command lines in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+	seen := make(map[string]struct{})
+	add := func(r *detectors.Result) {
+		if r == nil {
+			return
+		}
+		if _, ok := seen[string(r.Raw)]; ok {
+			return
+		}
+		seen[string(r.Raw)] = struct{}{}
+		results = append(results, *r)
+	}
+
+	for _, source := range []struct {
+		name string
+		pat  *regexp.Regexp
+	}{{"header", headerPat}, {"har", harPat}} {
+		for _, match := range source.pat.FindAllStringSubmatch(dataStr, -1) {
+			if strings.EqualFold(match[1], "basic") {
+				add(basicResult(source.name, match[2]))
+			} else {
+				add(bearerResult(source.name, match[2]))
+			}
+		}
+	}
+	for _, match := range curlPat.FindAllStringSubmatch(dataStr, -1) {
+		add(credentialsResult("curl", match[1]))
+	}
+
+	return results, nil
+}
+
+// basicResult decodes the credentials of basic authentication.
+func basicResult(source, encoded string) *detectors.Result {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		if decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "=")); err != nil {
+			return nil
+		}
+	}
+	return credentialsResult(source, string(decoded))
+}
+
+// credentialsResult reports a username and password joined by a colon.
+func credentialsResult(source, credentials string) *detectors.Result {
+	username, password, ok := strings.Cut(credentials, ":")
+	if !ok || password == "" || !isPrintable(credentials) || isVariable(username) || isVariable(password) {
+		return nil
+	}
+	return &detectors.Result{
+		DetectorType: detectorspb.DetectorType_AuthorizationHeader,
+		Raw:          []byte(credentials),
+		Redacted:     username,
+		ExtraData: map[string]string{
+			"scheme":   "basic",
+			"source":   source,
+			"username": username,
+		},
+	}
+}
+
+// bearerResult reports a bearer token, with its issuer, if it's a JWT or
+// has the prefix of a service's tokens.
+func bearerResult(source, token string) *detectors.Result {
+	if len(token) < minBearerLen || !strings.ContainsAny(token, "0123456789") {
+		return nil
+	}
+	r := &detectors.Result{
+		DetectorType: detectorspb.DetectorType_AuthorizationHeader,
+		Raw:          []byte(token),
+		Redacted:     token[:8] + "...",
+		ExtraData: map[string]string{
+			"scheme": "bearer",
+			"source": source,
+		},
+	}
+	if issuer, ok := jwtIssuer(token); ok {
+		r.ExtraData["token_type"] = "jwt"
+		if issuer != "" {
+			r.ExtraData["issuer"] = issuer
+		}
+		return r
+	}
+	for _, p := range issuerPrefixes {
+		if strings.HasPrefix(token, p.prefix) {
+			r.ExtraData["issuer"] = p.issuer
+			break
+		}
+	}
+	return r
+}
+
+// jwtIssuer returns the iss claim of token, if it's a JWT.
+func jwtIssuer(token string) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if !decodeJWTPart(parts[0], &header) || header.Alg == "" {
+		return "", false
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+	}
+	if !decodeJWTPart(parts[1], &claims) {
+		return "", false
+	}
+	return claims.Iss, true
+}
+
+func decodeJWTPart(part string, v any) bool {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	return err == nil && json.Unmarshal(b, v) == nil
+}
+
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isVariable tells whether a value is a variable or a template placeholder
+// rather than a literal.
+func isVariable(s string) bool {
+	return strings.HasPrefix(s, "$") || strings.HasPrefix(s, "%") || strings.Contains(s, "{{") || strings.HasPrefix(s, "<")
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_AuthorizationHeader
+}
+
+func (s Scanner) Description() string {
+	return "The basic credentials and bearer tokens of Authorization headers, as written in code, recorded in HTTP archives and passed to curl, give the access of the user or client the requests were made as."
+}
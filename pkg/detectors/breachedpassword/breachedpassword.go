@@ -0,0 +1,174 @@
+package breachedpassword
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the passwords and tokens assigned in code and config that
+// are known to have been breached: those whose hash is in a corpus of
+// breached hashes, or, with verification, those Have I Been Pwned's Pwned
+// Passwords lists. It's not run by default, as it reports the values of any
+// assignment that looks like a password.
+type Scanner struct {
+	corpus *Corpus
+	hibp   bool
+
+	client *http.Client
+	// hibpURL is overridden in tests.
+	hibpURL string
+
+	// counts caches the number of times values have been seen in breaches,
+	// by their SHA-1, as Pwned Passwords tells.
+	counts sync.Map
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	assignmentPat = regexp.MustCompile(`(?i)([a-z0-9_.-]*(?:pass(?:word|wd|phrase)?|pwd|secret|token|api[_-]?key)[a-z0-9_.-]*)["']?\s*(?::=|=>|=|:)\s*["']?([^\s"'` + "`" + `<>;,]{6,128})`)
+)
+
+const hibpRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// New creates a Scanner that checks values against corpus, which may be
+// nil, and against Pwned Passwords if hibp is set.
+func New(corpus *Corpus, hibp bool) *Scanner {
+	return &Scanner{corpus: corpus, hibp: hibp}
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s *Scanner) Keywords() []string {
+	return []string{"pass", "pwd", "secret", "token", "api_key", "api-key", "apikey"}
+}
+
+// FromData will find the breached values of assignments in a given set of
+// bytes. Pwned Passwords is only queried with verification, and only with
+// the first 5 characters of the SHA-1 of a value.
+func (s *Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	seen := make(map[string]struct{})
+	for _, match := range assignmentPat.FindAllStringSubmatch(string(data), -1) {
+		name, value := match[1], match[2]
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+
+		extraData := map[string]string{"name": name}
+		var count int
+		if hash, c, ok := s.lookupCorpus(value); ok {
+			extraData["breach_source"] = "corpus"
+			extraData["hash"] = hash
+			count = c
+		} else if verify && s.hibp {
+			c, hibpErr := s.pwnedCount(ctx, value)
+			if hibpErr != nil {
+				err = hibpErr
+				continue
+			}
+			if c == 0 {
+				continue
+			}
+			extraData["breach_source"] = "hibp"
+			extraData["hash"] = "sha1"
+			count = c
+		} else {
+			continue
+		}
+		if count > 0 {
+			extraData["breach_count"] = strconv.Itoa(count)
+		}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_BreachedPassword,
+			Raw:          []byte(value),
+			Redacted:     name,
+			ExtraData:    extraData,
+		})
+	}
+	return results, err
+}
+
+func (s *Scanner) lookupCorpus(value string) (string, int, bool) {
+	if s.corpus == nil {
+		return "", 0, false
+	}
+	return s.corpus.lookup(value)
+}
+
+// pwnedCount returns the number of times Pwned Passwords has seen value in
+// breaches. The range of the hashes that share the prefix of its hash is
+// padded, so the response doesn't tell how many there are.
+func (s *Scanner) pwnedCount(ctx context.Context, value string) (int, error) {
+	sum := sha1.Sum([]byte(value))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if count, ok := s.counts.Load(hash); ok {
+		return count.(int), nil
+	}
+
+	client := s.client
+	if client == nil {
+		client = defaultClient
+	}
+	endpoint := s.hibpURL
+	if endpoint == "" {
+		endpoint = hibpRangeURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+hash[:5], nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+
+	count := 0
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		suffix, countStr, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(suffix, hash[5:]) {
+			count, _ = strconv.Atoi(countStr)
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	s.counts.Store(hash, count)
+	return count, nil
+}
+
+func (s *Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_BreachedPassword
+}
+
+func (s *Scanner) Description() string {
+	return "Passwords and tokens known to have been breached, by their hashes in a breach corpus or in Have I Been Pwned's Pwned Passwords, are tried first by credential stuffing, wherever they're reused."
+}
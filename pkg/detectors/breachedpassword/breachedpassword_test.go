@@ -0,0 +1,156 @@
+package breachedpassword
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeCorpus(t *testing.T, lines ...string) *Corpus {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "corpus.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	corpus, err := LoadCorpus(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return corpus
+}
+
+func TestBreachedPassword_Pattern(t *testing.T) {
+	corpus := writeCorpus(t,
+		"# hashes of leaked credentials",
+		strings.ToUpper(sha1Hex("Summer2019!"))+":1523",
+		sha256Hex("ghp_reusedTokenFromAnOldLeak"),
+	)
+	d := New(corpus, false)
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name:  "sha1 with count",
+			input: `DB_PASSWORD="Summer2019!"`,
+			want: map[string]map[string]string{
+				"Summer2019!": {"name": "DB_PASSWORD", "breach_source": "corpus", "hash": "sha1", "breach_count": "1523"},
+			},
+		},
+		{
+			name:  "sha256",
+			input: "github:\n  token: ghp_reusedTokenFromAnOldLeak\n",
+			want: map[string]map[string]string{
+				"ghp_reusedTokenFromAnOldLeak": {"name": "token", "breach_source": "corpus", "hash": "sha256"},
+			},
+		},
+		{
+			name:  "not breached",
+			input: `password := "correct-horse-battery-staple"`,
+			want:  map[string]map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]map[string]string, len(results))
+			for _, r := range results {
+				actual[string(r.Raw)] = r.ExtraData
+			}
+
+			if diff := cmp.Diff(test.want, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestBreachedPassword_HIBP(t *testing.T) {
+	hash := strings.ToUpper(sha1Hex("hunter2hunter2"))
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.Header.Get("Add-Padding") != "true" {
+			t.Errorf("request isn't padded")
+		}
+		fmt.Fprintf(w, "0000000000000000000000000000000000A:0\r\n%s:42\r\n", hash[5:])
+	}))
+	defer server.Close()
+
+	d := New(nil, true)
+	d.hibpURL = server.URL + "/range/"
+	input := []byte("password=hunter2hunter2\npasswd=not-in-the-range\npassword=hunter2hunter2")
+
+	// Pwned Passwords isn't queried without verification.
+	results, err := d.FromData(context.Background(), false, input)
+	if err != nil || len(results) != 0 || len(requests) != 0 {
+		t.Fatalf("FromData() without verification = %v, %v after %d requests", results, err, len(requests))
+	}
+
+	results, err = d.FromData(context.Background(), true, input)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	want := map[string]map[string]string{
+		"hunter2hunter2": {"name": "password", "breach_source": "hibp", "hash": "sha1", "breach_count": "42"},
+	}
+	actual := make(map[string]map[string]string, len(results))
+	for _, r := range results {
+		actual[string(r.Raw)] = r.ExtraData
+	}
+	if diff := cmp.Diff(want, actual); diff != "" {
+		t.Errorf("diff: (-want +got)\n%s", diff)
+	}
+	// Only the prefixes of the hashes are sent.
+	if len(requests) != 2 || requests[0] != "/range/"+hash[:5] {
+		t.Errorf("requests = %v", requests)
+	}
+}
+
+func TestLoadCorpus_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.txt")
+	if err := os.WriteFile(path, []byte(sha1Hex("a")+"\nnot-a-hash\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCorpus(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("LoadCorpus() error = %v, want an error on line 2", err)
+	}
+}
@@ -0,0 +1,85 @@
+package breachedpassword
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Corpus holds the SHA-1 and SHA-256 hashes of breached passwords and
+// tokens, and the number of times they've been seen in breaches, where
+// known.
+type Corpus struct {
+	sha1   map[[sha1.Size]byte]int
+	sha256 map[[sha256.Size]byte]int
+}
+
+// LoadCorpus loads the hashes of files of one hex-encoded hash per line. A
+// hash can be followed by a colon and the number of times it's been seen, as
+// in the files Have I Been Pwned publishes, and lines starting with # are
+// comments. The hashes are all held in memory.
+func LoadCorpus(paths ...string) (*Corpus, error) {
+	c := &Corpus{sha1: make(map[[sha1.Size]byte]int), sha256: make(map[[sha256.Size]byte]int)}
+	for _, path := range paths {
+		if err := c.load(path); err != nil {
+			return nil, fmt.Errorf("could not load breach corpus %s: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Corpus) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, countStr, hasCount := strings.Cut(line, ":")
+		count := 0
+		if hasCount {
+			if count, err = strconv.Atoi(strings.TrimSpace(countStr)); err != nil {
+				return fmt.Errorf("line %d: invalid count %q", n, countStr)
+			}
+		}
+		sum, err := hex.DecodeString(strings.TrimSpace(hash))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid hash: %w", n, err)
+		}
+		switch len(sum) {
+		case sha1.Size:
+			c.sha1[[sha1.Size]byte(sum)] = count
+		case sha256.Size:
+			c.sha256[[sha256.Size]byte(sum)] = count
+		default:
+			return fmt.Errorf("line %d: expected a SHA-1 or SHA-256 hash, got %d bytes", n, len(sum))
+		}
+	}
+	return scanner.Err()
+}
+
+// Len returns the number of hashes of the corpus.
+func (c *Corpus) Len() int { return len(c.sha1) + len(c.sha256) }
+
+// lookup returns the algorithm of the hash of value the corpus holds, and
+// the number of times value has been seen in breaches, or 0 if unknown.
+func (c *Corpus) lookup(value string) (string, int, bool) {
+	if count, ok := c.sha1[sha1.Sum([]byte(value))]; ok {
+		return "sha1", count, true
+	}
+	if count, ok := c.sha256[sha256.Sum256([]byte(value))]; ok {
+		return "sha256", count, true
+	}
+	return "", 0, false
+}
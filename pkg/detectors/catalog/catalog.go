@@ -0,0 +1,102 @@
+// Package catalog describes what the detectors look for and what they do
+// with what they find: their keywords, whether they verify results and
+// against which endpoints, and examples of their masked findings.
+package catalog
+
+//go:generate go run ../../../hack/detectorcatalog
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// entry is what the sources of a built-in detector tell about it. The
+// entries are generated by hack/detectorcatalog.
+type entry struct {
+	verifies  bool
+	endpoints []string
+	examples  []string
+}
+
+// Info describes a detector.
+type Info struct {
+	// Name identifies the detector in --include-detectors and
+	// --exclude-detectors.
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Keywords    []string `json:"keywords"`
+	// Verifies is set for detectors that check their results against the
+	// provider.
+	Verifies bool `json:"verifies"`
+	// Endpoints are the URLs the detector calls, as found in its sources.
+	// Verification may call others, such as an endpoint found next to the
+	// secret.
+	Endpoints []string `json:"endpoints,omitempty"`
+	// CustomEndpoints is set for detectors that can verify against the
+	// endpoints given with --verifier.
+	CustomEndpoints bool `json:"custom_endpoints,omitempty"`
+	// Examples are masked secrets the detector finds in its test inputs.
+	Examples []string `json:"examples,omitempty"`
+	// BuiltIn is unset for detectors of plugins and custom detectors of the
+	// config file, which aren't in the catalog.
+	BuiltIn bool `json:"built_in"`
+}
+
+// Describe describes d.
+func Describe(d detectors.Detector) Info {
+	id := config.GetDetectorID(d)
+	info := Info{
+		Name:        id.String(),
+		Description: d.Description(),
+		Keywords:    d.Keywords(),
+	}
+	if e, ok := generated[id]; ok {
+		info.BuiltIn = true
+		info.Verifies = e.verifies
+		info.Endpoints = slices.Clone(e.endpoints)
+		info.Examples = slices.Clone(e.examples)
+	}
+	if c, ok := d.(detectors.CloudProvider); ok {
+		if endpoint := c.CloudEndpoint(); endpoint != "" && !slices.Contains(info.Endpoints, endpoint) {
+			info.Endpoints = append([]string{endpoint}, info.Endpoints...)
+		}
+	}
+	if _, ok := d.(detectors.EndpointCustomizer); ok {
+		info.CustomEndpoints = true
+		info.Verifies = true
+	}
+	return info
+}
+
+// List describes dets, sorted by name.
+func List(dets []detectors.Detector) []Info {
+	infos := make([]Info, 0, len(dets))
+	for _, d := range dets {
+		infos = append(infos, Describe(d))
+	}
+	slices.SortStableFunc(infos, func(a, b Info) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return infos
+}
+
+// Find describes the detectors of dets that name refers to, which is parsed
+// like the names of --include-detectors. A name without a version refers to
+// every version of the detector.
+func Find(dets []detectors.Detector, name string) ([]Info, error) {
+	want, err := config.ParseDetector(name)
+	if err != nil {
+		return nil, err
+	}
+	var matched []detectors.Detector
+	for _, d := range dets {
+		id := config.GetDetectorID(d)
+		if id.ID == want.ID && (want.Version == 0 || id.Version == want.Version) {
+			matched = append(matched, d)
+		}
+	}
+	return List(matched), nil
+}
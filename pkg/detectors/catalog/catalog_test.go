@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/aws"
+	githubv1 "github.com/trufflesecurity/trufflehog/v3/pkg/detectors/github/v1"
+	githubv2 "github.com/trufflesecurity/trufflehog/v3/pkg/detectors/github/v2"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type pluginDetector struct{}
+
+func (pluginDetector) FromData(context.Context, bool, []byte) ([]detectors.Result, error) {
+	return nil, nil
+}
+func (pluginDetector) Keywords() []string             { return []string{"acme_"} }
+func (pluginDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_CustomRegex }
+func (pluginDetector) Description() string            { return "Acme tokens." }
+
+func TestDescribe(t *testing.T) {
+	info := Describe(&githubv2.Scanner{})
+	assert.Equal(t, "Github.v2", info.Name)
+	assert.True(t, info.BuiltIn)
+	assert.True(t, info.Verifies)
+	assert.Contains(t, info.Endpoints, "https://api.github.com")
+	assert.Contains(t, info.Keywords, "ghp_")
+	require.NotEmpty(t, info.Examples)
+	for _, example := range info.Examples {
+		assert.Contains(t, example, "****")
+	}
+
+	info = Describe(pluginDetector{})
+	assert.Equal(t, Info{Name: "CustomRegex", Description: "Acme tokens.", Keywords: []string{"acme_"}}, info)
+}
+
+func TestFind(t *testing.T) {
+	dets := []detectors.Detector{&githubv2.Scanner{}, aws.New(), &githubv1.Scanner{}}
+
+	infos, err := Find(dets, "github")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "Github.v1", infos[0].Name)
+	assert.Equal(t, "Github.v2", infos[1].Name)
+
+	infos, err = Find(dets, "GITHUB.v2")
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "Github.v2", infos[0].Name)
+
+	infos, err = Find(dets, "slack")
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+
+	_, err = Find(dets, "nosuchdetector")
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	infos := List([]detectors.Detector{&githubv2.Scanner{}, aws.New(), &githubv1.Scanner{}})
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"AWS", "Github.v1", "Github.v2"}, names)
+}
+
+func TestGeneratedCoversDefaultDetectors(t *testing.T) {
+	for _, d := range engine.DefaultDetectors() {
+		id := config.GetDetectorID(d)
+		_, ok := generated[id]
+		assert.True(t, ok, "%s is missing from the catalog, run go generate ./pkg/detectors/catalog", id)
+	}
+}
@@ -0,0 +1,59 @@
+package credentialfile
+
+import (
+	"context"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/credfile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the values of dotenv files and credential dotfiles, such as
+// .npmrc and .netrc, that are named like secrets. Their values are often
+// generic passwords and tokens no other detector recognizes, so they're found
+// by their names instead, and can't be verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.FileDetector = (*Scanner)(nil)
+
+// Keywords are used for efficiently pre-filtering chunks. The scanner has
+// none, because it's run on every chunk of credential files instead.
+func (s Scanner) Keywords() []string {
+	return nil
+}
+
+// DetectsFile returns true for dotenv files and credential dotfiles.
+func (s Scanner) DetectsFile(path string) bool {
+	return classify.IsCredentialFile(path)
+}
+
+// FromData will find the secrets named like secrets in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	seen := make(map[string]struct{})
+	for _, e := range credfile.Parse(data) {
+		if !credfile.SensitiveName(e.Name) {
+			continue
+		}
+		if _, ok := seen[e.Value]; ok {
+			continue
+		}
+		seen[e.Value] = struct{}{}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_Generic,
+			Raw:          []byte(e.Value),
+		})
+	}
+	return results, nil
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_Generic
+}
+
+func (s Scanner) Description() string {
+	return "Secret named like a password or token in a dotenv file or credential dotfile, such as .npmrc or .netrc."
+}
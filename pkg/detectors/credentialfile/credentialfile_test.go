@@ -0,0 +1,86 @@
+package credentialfile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestCredentialFile_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		file  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "dotenv",
+			file:  "app/.env.production",
+			input: "DEBUG=true\nDB_PASSWORD=\"correct horse\"\nPUBLIC_KEY=not-a-secret\nSESSION_SECRET=s3ss10n\nOTHER_SECRET=s3ss10n\n",
+			want:  []string{"correct horse", "s3ss10n"},
+		},
+		{
+			name:  "npmrc",
+			file:  ".npmrc",
+			input: "registry=https://registry.example.com/\n//registry.example.com/:_authToken=generic-token\n",
+			want:  []string{"generic-token"},
+		},
+		{
+			name:  "netrc",
+			file:  "/home/ci/.netrc",
+			input: "machine example.com login ci password hunter22\n",
+			want:  []string{"hunter22"},
+		},
+		{
+			name:  "placeholders",
+			file:  ".env.example",
+			input: "API_TOKEN=<your token>\nDB_PASSWORD=${DB_PASSWORD}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindFileDetectorMatches(test.file, []byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("file %q not matched", test.file)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, matchedDetectors[0].Matches()[0])
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]struct{}, len(results))
+			for _, r := range results {
+				actual[string(r.Raw)] = struct{}{}
+			}
+			expected := make(map[string]struct{}, len(test.want))
+			for _, v := range test.want {
+				expected[v] = struct{}{}
+			}
+
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestCredentialFile_DetectsFile(t *testing.T) {
+	d := Scanner{}
+	if !d.DetectsFile(".git-credentials") {
+		t.Error("expected .git-credentials to be detected")
+	}
+	if d.DetectsFile("main.go") {
+		t.Error("expected main.go not to be detected")
+	}
+}
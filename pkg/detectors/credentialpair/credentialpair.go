@@ -0,0 +1,166 @@
+package credentialpair
+
+import (
+	"context"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the usernames and passwords assigned near keywords users
+// supply, such as the names of internal systems, whose passwords have no
+// format of their own. It's not run by default, as it has no keywords until
+// some are given, and its findings can't be verified.
+type Scanner struct {
+	keywords []string
+	window   int
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	userPat = regexp.MustCompile(`(?i)([a-z0-9_.-]*(?:user(?:name)?|login))["']?\s*(?::=|=>|=|:)\s*["']?([^\s"'` + "`" + `<>;,]{1,64})`)
+	passPat = regexp.MustCompile(`(?i)([a-z0-9_.-]*(?:pass(?:word|wd|phrase)?|pwd)[a-z0-9_.-]*)["']?\s*(?::=|=>|=|:)\s*["']?([^\s"'` + "`" + `<>;,]{4,128})`)
+)
+
+// DefaultWindow is the number of characters before and after a keyword the
+// username and password are looked for in, unless New is given another.
+const DefaultWindow = 256
+
+// New creates a Scanner that reports the usernames and passwords assigned
+// within window characters of any of the keywords, matched regardless of
+// case. A window that isn't positive is DefaultWindow.
+func New(keywords []string, window int) *Scanner {
+	s := &Scanner{window: window}
+	if s.window <= 0 {
+		s.window = DefaultWindow
+	}
+	seen := make(map[string]struct{}, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			continue
+		}
+		if _, ok := seen[keyword]; ok {
+			continue
+		}
+		seen[keyword] = struct{}{}
+		s.keywords = append(s.keywords, keyword)
+	}
+	return s
+}
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s *Scanner) Keywords() []string {
+	return s.keywords
+}
+
+// assignment is a value assigned to a name, at the offset of the name.
+type assignment struct {
+	start       int
+	name, value string
+}
+
+// FromData will find the usernames and passwords assigned near the keywords
+// in a given set of bytes.
+func (s *Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+	users := findAssignments(userPat, dataStr)
+	passwords := findAssignments(passPat, dataStr)
+	if len(users) == 0 || len(passwords) == 0 {
+		return nil, nil
+	}
+
+	lower := strings.ToLower(dataStr)
+	seen := make(map[string]struct{})
+	for _, keyword := range s.keywords {
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], keyword)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			end := start + len(keyword)
+			offset = end
+
+			user, ok := s.nearest(users, start, end, start)
+			if !ok {
+				continue
+			}
+			// Of the passwords within the window, the one next to the
+			// username goes with it.
+			password, ok := s.nearest(passwords, start, end, user.start)
+			if !ok {
+				continue
+			}
+			pair := user.value + ":" + password.value
+			if _, ok := seen[pair]; ok {
+				continue
+			}
+			seen[pair] = struct{}{}
+
+			results = append(results, detectors.Result{
+				DetectorType: detectorspb.DetectorType_CredentialPair,
+				Unverifiable: true,
+				Raw:          []byte(password.value),
+				RawV2:        []byte(pair),
+				Redacted:     user.value,
+				ExtraData: map[string]string{
+					"keyword":  keyword,
+					"username": user.value,
+					"name":     password.name,
+				},
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func findAssignments(pat *regexp.Regexp, data string) []assignment {
+	var assignments []assignment
+	for _, match := range pat.FindAllStringSubmatchIndex(data, -1) {
+		value := data[match[4]:match[5]]
+		// Skip variables and template placeholders.
+		if strings.HasPrefix(value, "$") || strings.HasPrefix(value, "{{") || strings.HasPrefix(value, "%") {
+			continue
+		}
+		assignments = append(assignments, assignment{start: match[2], name: data[match[2]:match[3]], value: value})
+	}
+	return assignments
+}
+
+// nearest returns the assignment closest to offset among those within the
+// window of the keyword between start and end.
+func (s *Scanner) nearest(assignments []assignment, start, end, offset int) (assignment, bool) {
+	var (
+		best     assignment
+		bestDist = -1
+	)
+	for _, a := range assignments {
+		if a.start < start-s.window || a.start > end+s.window {
+			continue
+		}
+		dist := a.start - offset
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = a, dist
+		}
+	}
+	return best, bestDist >= 0
+}
+
+func (s *Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_CredentialPair
+}
+
+func (s *Scanner) Description() string {
+	return "Usernames and passwords assigned near the names of internal systems log into those systems, whose passwords have no format that other detectors could recognize."
+}
@@ -0,0 +1,122 @@
+package credentialpair
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestCredentialPair_Pattern(t *testing.T) {
+	d := New([]string{"Jira", "ledger-db", " "}, 64)
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name: "env file",
+			input: `JIRA_URL=https://jira.corp.example
+JIRA_USER=svc-deploy
+JIRA_PASSWORD=Tr0ub4dor&3`,
+			want: map[string]map[string]string{
+				"svc-deploy:Tr0ub4dor&3": {"keyword": "jira", "username": "svc-deploy", "name": "JIRA_PASSWORD"},
+			},
+		},
+		{
+			name: "yaml",
+			input: `ledger-db:
+  login: ledger_rw
+  passwd: "c0rrect-h0rse"`,
+			want: map[string]map[string]string{
+				"ledger_rw:c0rrect-h0rse": {"keyword": "ledger-db", "username": "ledger_rw", "name": "passwd"},
+			},
+		},
+		{
+			name: "nearest pair",
+			input: `jira:
+  username: alice
+  password: alice-secret
+jira_backup:
+  username: bob
+  password: bob-secret`,
+			want: map[string]map[string]string{
+				"alice:alice-secret": {"keyword": "jira", "username": "alice", "name": "password"},
+				"bob:bob-secret":     {"keyword": "jira", "username": "bob", "name": "password"},
+			},
+		},
+		{
+			name: "outside window",
+			input: `# jira
+` + strings.Repeat("#\n", 64) + `username=alice
+password=alice-secret`,
+			want: map[string]map[string]string{},
+		},
+		{
+			name:  "no username",
+			input: `JIRA_PASSWORD=Tr0ub4dor&3`,
+			want:  map[string]map[string]string{},
+		},
+		{
+			name: "placeholder",
+			input: `JIRA_USER=svc-deploy
+JIRA_PASSWORD=${JIRA_PASSWORD}`,
+			want: map[string]map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]map[string]string, len(results))
+			for _, r := range results {
+				if !r.Unverifiable {
+					t.Errorf("result %s isn't unverifiable", r.RawV2)
+				}
+				actual[string(r.RawV2)] = r.ExtraData
+			}
+
+			if diff := cmp.Diff(test.want, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestLoadWordlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "systems.txt")
+	if err := os.WriteFile(path, []byte("# internal systems\njira\n\n  ledger-db  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadWordlist(path)
+	if err != nil {
+		t.Fatalf("LoadWordlist() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"jira", "ledger-db"}, got); diff != "" {
+		t.Errorf("LoadWordlist() diff: (-want +got)\n%s", diff)
+	}
+
+	if _, err := LoadWordlist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadWordlist() of a missing file succeeded")
+	}
+}
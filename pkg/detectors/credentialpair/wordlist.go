@@ -0,0 +1,41 @@
+package credentialpair
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadWordlist reads the keywords of files of one keyword per line. Blank
+// lines and lines starting with # are skipped.
+func LoadWordlist(paths ...string) ([]string, error) {
+	var keywords []string
+	for _, path := range paths {
+		words, err := loadWordlist(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not load wordlist %s: %w", path, err)
+		}
+		keywords = append(keywords, words...)
+	}
+	return keywords, nil
+}
+
+func loadWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
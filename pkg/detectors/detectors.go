@@ -7,11 +7,14 @@ import (
 	"math/big"
 	"net/url"
 	"strings"
+	"time"
 	"unicode"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reachability"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -80,6 +83,19 @@ type EndpointCustomizer interface {
 	UseFoundEndpoints(bool)
 }
 
+// CategorySkipper is an optional interface that a detector can implement to
+// not be run on chunks of some categories, when the engine classifies chunks.
+type CategorySkipper interface {
+	SkipCategories() []classify.Category
+}
+
+// FileDetector is an optional interface that a detector can implement to be
+// run on the whole of every chunk of the files it detects, whether or not the
+// chunk contains its keywords.
+type FileDetector interface {
+	DetectsFile(path string) bool
+}
+
 type CloudProvider interface {
 	CloudEndpoint() string
 }
@@ -92,6 +108,9 @@ type Result struct {
 	// DecoderType is the type of Decoder.
 	DecoderType detectorspb.DecoderType
 	Verified    bool
+	// Unverifiable is set on results of secrets no API can verify, such as webhook signing secrets, which only the
+	// services signing with them check. They are rated like results whose verification failed.
+	Unverifiable bool
 	// Raw contains the raw secret identifier data. Prefer IDs over secrets since it is used for deduping after hashing.
 	Raw []byte
 	// RawV2 contains the raw secret identifier that is a combination of both the ID and the secret.
@@ -168,6 +187,60 @@ type ResultWithMetadata struct {
 	Result
 	// Data from the sources.Chunk which this result was emitted for
 	Data []byte
+	// Fingerprint is a stable identifier of the finding, derived from the detector, the secret, and its location.
+	// It is consistent across scans so findings can be tracked between runs.
+	Fingerprint string
+	// Occurrences holds the metadata of every location the same finding was seen at when results are deduplicated.
+	Occurrences []*source_metadatapb.MetaData
+	// Context holds the lines of the chunk around the secret, when the engine is configured to capture them.
+	Context *LineContext
+	// Categories are the categories of the file the secret was found in, when the engine is configured to classify chunks.
+	Categories []classify.Category
+	// Variable is the name the secret is assigned to, when it was found in a dotenv file, a credential dotfile or a
+	// mobile app config, or the attribute it's the value of, when it was found in an infrastructure as code template.
+	Variable string
+	// Address is the address of the template block the secret was found in, such as aws_instance.web.
+	Address string
+	// Exposure is how long the secret has been exposed in its git repository, when the findings are annotated with it.
+	Exposure *Exposure
+	// Reachability is who can read the repository or bucket the secret was found in, when its source tells.
+	Reachability reachability.Scope
+	// Owners are the teams or people that own the secret's location, when findings are attributed to their owners.
+	Owners []string
+	// Blame is the commit that introduced the secret, when findings in git work trees are blamed.
+	Blame *Blame
+}
+
+// LineContext is the line a secret was found on and the lines surrounding it.
+type LineContext struct {
+	Before []string `json:",omitempty"`
+	Line   string
+	After  []string `json:",omitempty"`
+}
+
+// Exposure is how long a secret found in a git repository has been exposed,
+// and who it has been exposed to.
+type Exposure struct {
+	// IntroducedAt is the date of the commit that introduced the secret.
+	IntroducedAt time.Time
+	// RemovedAt is the date of the commit that removed the secret, if the
+	// repository's HEAD doesn't have it anymore.
+	RemovedAt *time.Time `json:",omitempty"`
+	// Days is how long the secret was exposed for: until it was removed or,
+	// if it wasn't, until the scan.
+	Days int
+	// Public is whether the repository is public, if that is known.
+	Public *bool `json:",omitempty"`
+}
+
+// Blame is the commit that introduced a secret found in a file of a git
+// work tree.
+type Blame struct {
+	Commit string
+	Author string
+	Email  string
+	// Date is the author date of the commit.
+	Date time.Time
 }
 
 // CopyMetadata returns a detector result with included metadata from the source chunk.
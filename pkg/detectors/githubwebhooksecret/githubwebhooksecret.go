@@ -0,0 +1,68 @@
+package githubwebhooksecret
+
+import (
+	"context"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the secrets GitHub signs the deliveries of webhooks with,
+// assigned to variables named after them. These secrets have no format of
+// their own, and only the servers receiving the deliveries check the
+// signatures, so they can't be verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	// The settings of webhooks and GitHub Apps, as in GITHUB_WEBHOOK_SECRET
+	// and githubAppWebhookSecret.
+	keyPat = regexp.MustCompile(`(?i)(github[_.-]?(?:app[_.-]?)?webhook[_.-]?secret)["']?\s*(?::=|=>|=|:)\s*["']?([^\s"'` + "`" + `<>;,]{8,128})`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"webhook"}
+}
+
+// FromData will find GitHub webhook secrets in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	seen := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		name, secret := match[1], match[2]
+		// Skip variables and template placeholders.
+		if strings.HasPrefix(secret, "$") || strings.HasPrefix(secret, "{{") || strings.HasPrefix(secret, "%") {
+			continue
+		}
+		if _, ok := seen[secret]; ok {
+			continue
+		}
+		seen[secret] = struct{}{}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_GitHubWebhookSecret,
+			Unverifiable: true,
+			Raw:          []byte(secret),
+			Redacted:     name,
+			ExtraData:    map[string]string{"name": name},
+		})
+	}
+
+	return results, nil
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_GitHubWebhookSecret
+}
+
+func (s Scanner) Description() string {
+	return "GitHub webhook secrets sign the deliveries GitHub sends to webhook receivers. Anyone with one can forge deliveries, such as pushes that trigger deployments, that the receiver accepts as GitHub's."
+}
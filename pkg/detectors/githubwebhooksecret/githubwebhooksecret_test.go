@@ -0,0 +1,70 @@
+package githubwebhooksecret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestGitHubWebhookSecret_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "env",
+			input: "GITHUB_WEBHOOK_SECRET=Tr0ub4dor-and-3-horses\nGITHUB_TOKEN=unrelated",
+			want:  []string{"Tr0ub4dor-and-3-horses"},
+		},
+		{
+			name:  "github app config",
+			input: "githubApp:\n  webhookSecret: not-this-one\n  github_app_webhook_secret: 'c0rrect-h0rse-battery'\n",
+			want:  []string{"c0rrect-h0rse-battery"},
+		},
+		{
+			name:  "variable",
+			input: `github_webhook_secret = "${{ secrets.GITHUB_WEBHOOK_SECRET }}"`,
+			want:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]struct{}, len(results))
+			for _, r := range results {
+				if !r.Unverifiable {
+					t.Errorf("result %s isn't flagged unverifiable", r.Raw)
+				}
+				actual[string(r.Raw)] = struct{}{}
+			}
+			expected := make(map[string]struct{}, len(test.want))
+			for _, v := range test.want {
+				expected[v] = struct{}{}
+			}
+
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package googleapikey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/mobileconfig"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the Google API keys of mobile app configs: the Firebase keys
+// of google-services.json and GoogleService-Info.plist files, and those in
+// the string resources of Android apps, such as a Maps key. The configs also
+// say which app the keys are for, which the keys are often restricted to, so
+// they're verified as that app.
+type Scanner struct {
+	client *http.Client
+}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.FileDetector = (*Scanner)(nil)
+
+var (
+	defaultClient = common.SaneHttpClient()
+
+	keyPat = regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks. The scanner has
+// none, because it's run on every chunk of mobile app configs instead.
+func (s Scanner) Keywords() []string {
+	return nil
+}
+
+// DetectsFile returns true for mobile app configs.
+func (s Scanner) DetectsFile(path string) bool {
+	return mobileconfig.IsConfigFile(path)
+}
+
+// FromData will find and optionally verify the Google API keys of a mobile app config in a given set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	seen := make(map[string]struct{})
+	for _, e := range mobileconfig.Parse(data) {
+		for _, key := range keyPat.FindAllString(e.Value, -1) {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			s1 := detectors.Result{
+				DetectorType: detectorspb.DetectorType_GoogleApiKey,
+				Raw:          []byte(key),
+				ExtraData:    appData(e.App),
+			}
+
+			if verify {
+				client := s.client
+				if client == nil {
+					client = defaultClient
+				}
+
+				isVerified, projectID, verificationErr := verifyMatch(ctx, client, key, e.App)
+				s1.Verified = isVerified
+				if projectID != "" {
+					s1.ExtraData["project_id"] = projectID
+				}
+				s1.SetVerificationError(verificationErr, key)
+			}
+
+			results = append(results, s1)
+		}
+	}
+	return results, nil
+}
+
+// appData returns the extra data of a key for app.
+func appData(app mobileconfig.App) map[string]string {
+	data := make(map[string]string)
+	for k, v := range map[string]string{
+		"project_id":      app.ProjectID,
+		"android_package": app.AndroidPackage,
+		"ios_bundle":      app.IOSBundle,
+	} {
+		if v != "" {
+			data[k] = v
+		}
+	}
+	return data
+}
+
+// verifyMatch reads the Firebase Auth config of the key's project, which
+// every key of a Firebase project can, as the app the key is for. It returns
+// the ID of the project if the key is valid.
+func verifyMatch(ctx context.Context, client *http.Client, key string, app mobileconfig.App) (bool, string, error) {
+	u := "https://www.googleapis.com/identitytoolkit/v3/relyingparty/getProjectConfig?key=" + url.QueryEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, "", err
+	}
+	// Keys restricted to an app only work for requests from it.
+	if app.AndroidPackage != "" {
+		req.Header.Set("X-Android-Package", app.AndroidPackage)
+	}
+	if app.AndroidCert != "" {
+		req.Header.Set("X-Android-Cert", strings.ToUpper(strings.ReplaceAll(app.AndroidCert, ":", "")))
+	}
+	if app.IOSBundle != "" {
+		req.Header.Set("X-Ios-Bundle-Identifier", app.IOSBundle)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		var config struct {
+			ProjectID string `json:"projectId"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&config)
+		return true, config.ProjectID, nil
+	case http.StatusBadRequest, http.StatusForbidden:
+		// The key is invalid, or restricted to other apps or APIs.
+		return false, "", nil
+	default:
+		return false, "", fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_GoogleApiKey
+}
+
+func (s Scanner) Description() string {
+	return "Google API keys authorize requests to Google APIs, such as Firebase and Maps, and are billed to their project. Mobile apps ship them in google-services.json, GoogleService-Info.plist and string resources."
+}
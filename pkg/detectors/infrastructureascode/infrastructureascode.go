@@ -0,0 +1,62 @@
+package infrastructureascode
+
+import (
+	"context"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/iac"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the secrets Terraform, HCL and CloudFormation templates leak:
+// credentials hardcoded into providers, resources and variable defaults,
+// secrets in the user data scripts of instances, and outputs of credentials
+// that aren't marked sensitive. What makes them secrets is where they are in
+// the template, so they can't be verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interfaces at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.FileDetector = (*Scanner)(nil)
+
+// Keywords are used for efficiently pre-filtering chunks. The scanner has
+// none, because it's run on every chunk of template files instead.
+func (s Scanner) Keywords() []string {
+	return nil
+}
+
+// DetectsFile returns true for the files templates are written in.
+func (s Scanner) DetectsFile(path string) bool {
+	return iac.IsTemplateFile(path)
+}
+
+// FromData will find the secrets leaked by the template in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	t := iac.Parse(data)
+	if t == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]struct{})
+	for _, f := range t.Findings() {
+		if _, ok := seen[f.Value]; ok {
+			continue
+		}
+		seen[f.Value] = struct{}{}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_InfrastructureAsCode,
+			Raw:          []byte(f.Value),
+			ExtraData:    map[string]string{"finding": string(f.Kind)},
+		})
+	}
+	return results, nil
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_InfrastructureAsCode
+}
+
+func (s Scanner) Description() string {
+	return "Credential leaked by a Terraform, HCL or CloudFormation template: hardcoded into a provider or resource, in an instance's user data, or output without being marked sensitive."
+}
@@ -0,0 +1,106 @@
+package infrastructureascode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestInfrastructureAsCode_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		file  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name: "terraform",
+			file: "infra/main.tf",
+			input: `provider "aws" {
+  region     = "us-east-1"
+  secret_key = "tf-secret-key"
+}
+
+resource "aws_instance" "web" {
+  user_data = <<EOF
+export DB_PASSWORD=boot-pass
+EOF
+}
+
+output "key_secret" {
+  value = aws_iam_access_key.ci.secret
+}
+
+output "password" {
+  value     = random_password.db.result
+  sensitive = true
+}
+`,
+			want: map[string]string{
+				"tf-secret-key":                "hardcoded credential",
+				"boot-pass":                    "secret in user data",
+				"aws_iam_access_key.ci.secret": "credential output not marked sensitive",
+			},
+		},
+		{
+			name: "cloudformation",
+			file: "stack.yaml",
+			input: `Resources:
+  Database:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      MasterUserPassword: cfn-pass
+      MasterUsername: !Ref DBUser
+`,
+			want: map[string]string{"cfn-pass": "hardcoded credential"},
+		},
+		{
+			name:  "not a template",
+			file:  "config.yaml",
+			input: "password: not-a-template\n",
+			want:  map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindFileDetectorMatches(test.file, []byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("file %q not matched", test.file)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, matchedDetectors[0].Matches()[0])
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]string, len(results))
+			for _, r := range results {
+				actual[string(r.Raw)] = r.ExtraData["finding"]
+			}
+
+			if diff := cmp.Diff(test.want, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
+
+func TestInfrastructureAsCode_DetectsFile(t *testing.T) {
+	d := Scanner{}
+	if !d.DetectsFile("modules/vpc/main.tf") {
+		t.Error("expected main.tf to be detected")
+	}
+	if d.DetectsFile("main.go") {
+		t.Error("expected main.go not to be detected")
+	}
+}
@@ -0,0 +1,223 @@
+package oauthclientsecret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the secrets of OAuth clients configured next to their client
+// IDs, whatever the authorization server. Secrets configured with the token
+// endpoint of their server are verified by requesting a token from it.
+type Scanner struct {
+	detectors.DefaultMultiPartCredentialProvider
+	client *http.Client
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+// maxSettingDistance is the number of bytes within which the settings of a
+// client are taken to be its own.
+const maxSettingDistance = 1024
+
+var (
+	// The client settings of most libraries and config files, as in
+	// client_secret, clientSecret and OAUTH_CLIENT_SECRET.
+	secretPat = regexp.MustCompile(`(?i)client[_.-]?secret["']?\s*(?::=|=>|=|:)\s*["']?([A-Za-z0-9._~+/=-]{8,128})`)
+	idPat     = regexp.MustCompile(`(?i)client[_.-]?id["']?\s*(?::=|=>|=|:)\s*["']?([A-Za-z0-9._~@:/-]{3,128})`)
+
+	tokenEndpointPat = regexp.MustCompile(`https://[\w.-]+(?::\d{1,5})?/[^\s"'<>]*token\b`)
+	issuerPat        = regexp.MustCompile(`(?i)(?:issuer|authority|iss)["']?\s*(?::=|=>|=|:)\s*["']?(https://[^\s"'<>]+)`)
+
+	defaultClient = detectors.DetectorHttpClientWithNoLocalAddresses
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"client_secret", "clientsecret", "client-secret", "client.secret"}
+}
+
+// setting is a setting found in the data, at the offset of its value.
+type setting struct {
+	offset int
+	value  string
+}
+
+// findSettings returns the values of the first capture group of pat.
+func findSettings(pat *regexp.Regexp, data string) []setting {
+	var settings []setting
+	for _, match := range pat.FindAllStringSubmatchIndex(data, -1) {
+		settings = append(settings, setting{offset: match[2], value: data[match[2]:match[3]]})
+	}
+	return settings
+}
+
+// nearest returns the value of the setting closest to offset, within
+// maxSettingDistance.
+func nearest(settings []setting, offset int) string {
+	best, bestDistance := "", maxSettingDistance+1
+	for _, s := range settings {
+		distance := s.offset - offset
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < bestDistance {
+			best, bestDistance = s.value, distance
+		}
+	}
+	return best
+}
+
+// FromData will find and optionally verify OAuth client secrets in a given
+// set of bytes.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	ids := findSettings(idPat, dataStr)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var endpoints []setting
+	for _, match := range tokenEndpointPat.FindAllStringIndex(dataStr, -1) {
+		endpoints = append(endpoints, setting{offset: match[0], value: dataStr[match[0]:match[1]]})
+	}
+	issuers := findSettings(issuerPat, dataStr)
+
+	seen := make(map[string]struct{})
+	for _, secret := range findSettings(secretPat, dataStr) {
+		if isPlaceholder(secret.value) {
+			continue
+		}
+		clientID := nearest(ids, secret.offset)
+		if clientID == "" {
+			continue
+		}
+		if _, ok := seen[clientID+":"+secret.value]; ok {
+			continue
+		}
+		seen[clientID+":"+secret.value] = struct{}{}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_OAuthClientSecret,
+			Raw:          []byte(secret.value),
+			RawV2:        []byte(clientID + ":" + secret.value),
+			Redacted:     clientID,
+			ExtraData:    map[string]string{"client_id": clientID},
+		}
+		endpoint := nearest(endpoints, secret.offset)
+		issuer := nearest(issuers, secret.offset)
+		if endpoint != "" {
+			s1.ExtraData["token_endpoint"] = endpoint
+			if issuer == "" {
+				if u, err := url.Parse(endpoint); err == nil {
+					issuer = u.Scheme + "://" + u.Host
+				}
+			}
+		}
+		if issuer != "" {
+			s1.ExtraData["issuer"] = issuer
+		}
+
+		if verify && endpoint != "" {
+			client := s.client
+			if client == nil {
+				client = defaultClient
+			}
+			isVerified, verificationErr := verifyClient(ctx, client, endpoint, clientID, secret.value)
+			s1.Verified = isVerified
+			s1.SetVerificationError(verificationErr, secret.value)
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// isPlaceholder tells whether a secret is a variable or a template
+// placeholder rather than a literal.
+func isPlaceholder(secret string) bool {
+	return strings.HasPrefix(secret, "$") || strings.HasPrefix(secret, "%") || strings.Trim(secret, "xX*.-_") == ""
+}
+
+// verifyClient requests a token for the client with the client credentials
+// grant, authenticating it with HTTP basic authentication, and again with the
+// credentials in the form if the server doesn't accept those. Servers only
+// tell a client isn't allowed the grant once they've authenticated it.
+func verifyClient(ctx context.Context, client *http.Client, endpoint, clientID, secret string) (bool, error) {
+	for _, basic := range []bool{true, false} {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		if !basic {
+			form.Set("client_id", clientID)
+			form.Set("client_secret", secret)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		if basic {
+			req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(secret))
+		}
+
+		verified, authFailed, err := tokenResponse(client, req)
+		if err != nil || !authFailed {
+			return verified, err
+		}
+	}
+	return false, nil
+}
+
+// tokenResponse tells whether a token request was made by an authenticated
+// client, and else whether its authentication failed.
+func tokenResponse(client *http.Client, req *http.Request) (verified, authFailed bool, err error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return false, false, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode == http.StatusOK {
+		return true, false, nil
+	}
+	if res.StatusCode >= 500 {
+		return false, false, fmt.Errorf("unexpected HTTP response status %d", res.StatusCode)
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc6749#section-5.2
+	var body struct {
+		Error string `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(&body)
+	switch body.Error {
+	case "unauthorized_client", "unsupported_grant_type", "invalid_scope":
+		return true, false, nil
+	case "invalid_client":
+		return false, true, nil
+	default:
+		return false, false, nil
+	}
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_OAuthClientSecret
+}
+
+func (s Scanner) Description() string {
+	return "OAuth client secrets authenticate applications to an authorization server, which grants them tokens to act as themselves or on behalf of the users who authorized them."
+}
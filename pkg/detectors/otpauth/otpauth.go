@@ -0,0 +1,98 @@
+package otpauth
+
+import (
+	"context"
+	"encoding/base32"
+	"net/url"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the otpauth:// URIs two-factor authentication is set up
+// with, which are shown as QR codes to be scanned by authenticator apps. Their
+// secrets generate the one-time passwords of an account for as long as its
+// second factor isn't reset, so they can't be verified without logging in.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var uriPat = regexp.MustCompile(`(?i)otpauth://(?:totp|hotp)/[^\s"'<>]+`)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"otpauth://"}
+}
+
+// FromData will find otpauth URIs in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	seen := make(map[string]struct{})
+	for _, match := range uriPat.FindAllString(string(data), -1) {
+		u, err := url.Parse(match)
+		if err != nil {
+			continue
+		}
+		query := u.Query()
+		secret := strings.ToUpper(strings.TrimRight(query.Get("secret"), "="))
+		if !isSecret(secret) {
+			continue
+		}
+		if _, ok := seen[secret]; ok {
+			continue
+		}
+		seen[secret] = struct{}{}
+
+		// The label is the account, prefixed by the issuer and a colon.
+		label := strings.TrimPrefix(u.Path, "/")
+		issuer, account, found := strings.Cut(label, ":")
+		if !found {
+			issuer, account = "", label
+		}
+		if i := query.Get("issuer"); i != "" {
+			issuer = i
+		}
+
+		extraData := map[string]string{
+			"type":    strings.ToLower(u.Host),
+			"account": strings.TrimSpace(account),
+		}
+		for key, value := range map[string]string{
+			"issuer":    issuer,
+			"algorithm": query.Get("algorithm"),
+			"digits":    query.Get("digits"),
+		} {
+			if value != "" {
+				extraData[key] = value
+			}
+		}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_OTPAuth,
+			Raw:          []byte(secret),
+			RawV2:        []byte(match),
+			Redacted:     label,
+			ExtraData:    extraData,
+		})
+	}
+	return results, nil
+}
+
+// isSecret returns true if s is the base32 of an HMAC key, which is at least
+// 80 bits.
+func isSecret(s string) bool {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	return err == nil && len(key) >= 10
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_OTPAuth
+}
+
+func (s Scanner) Description() string {
+	return "otpauth URIs provision the TOTP and HOTP second factors of accounts in authenticator apps. Their secrets generate the account's one-time passwords."
+}
@@ -0,0 +1,72 @@
+package otpauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestOTPAuth_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name:  "totp",
+			input: "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			want: map[string]map[string]string{
+				"JBSWY3DPEHPK3PXP": {"type": "totp", "issuer": "Example", "account": "alice@example.com"},
+			},
+		},
+		{
+			name:  "hotp with parameters",
+			input: `<img alt="otpauth://hotp/ACME%20Co:bob?secret=gezdgnbvgy3tqojqgezdgnbvgy3tqojq&algorithm=SHA256&digits=8&counter=3">`,
+			want: map[string]map[string]string{
+				"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ": {"type": "hotp", "issuer": "ACME Co", "account": "bob", "algorithm": "SHA256", "digits": "8"},
+			},
+		},
+		{
+			name:  "invalid secret",
+			input: "otpauth://totp/alice?secret=not-base32!",
+			want:  map[string]map[string]string{},
+		},
+		{
+			name:  "short secret",
+			input: "otpauth://totp/alice?secret=JBSWY3DP",
+			want:  map[string]map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]map[string]string, len(results))
+			for _, r := range results {
+				actual[string(r.Raw)] = r.ExtraData
+			}
+
+			if diff := cmp.Diff(test.want, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package samlsigningkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	regexp "github.com/wasilibs/go-re2"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the private keys SAML identity and service providers sign
+// assertions and requests with, left in the metadata XML that describes
+// them. The metadata is meant to be published with the public certificates
+// of the keys, and anyone with a key can sign assertions the providers that
+// trust the metadata accept. The keys can't be verified without a provider to
+// sign in to.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.MaxSecretSizeProvider = (*Scanner)(nil)
+
+// maxMetadataSize is how far after the start of an entity its keys are
+// searched for.
+const maxMetadataSize = 16 << 10
+
+var (
+	entityPat = regexp.MustCompile(`<(?:[\w-]+:)?EntityDescriptor\b[^>]*?\sentityID\s*=\s*["']([^"']+)["']`)
+	// Keys are embedded as PEM blocks, or as the base64 DER of elements such
+	// as the PrivateKey of SimpleSAMLphp and Shibboleth configurations.
+	pemKeyPat     = regexp.MustCompile(`-----\s*?BEGIN[ A-Z0-9_-]*?PRIVATE KEY\s*?-----[\s\S]*?----\s*?END[ A-Z0-9_-]*? PRIVATE KEY\s*?-----`)
+	elementKeyPat = regexp.MustCompile(`<(?:[\w-]+:)?\w*PrivateKey\b[^>]*>\s*([A-Za-z0-9+/=\s]{100,}?)\s*<`)
+	certPat       = regexp.MustCompile(`<(?:[\w-]+:)?X509Certificate>\s*([A-Za-z0-9+/=\s]{100,}?)\s*<`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"entityid"}
+}
+
+// MaxSecretSize returns the maximum size of the metadata of an entity this
+// detector finds keys in.
+func (s Scanner) MaxSecretSize() int64 { return maxMetadataSize }
+
+// key is a private key found in metadata, at the offset it starts at.
+type key struct {
+	offset int
+	raw    string
+	signer crypto.Signer
+}
+
+// FromData will find the private keys of SAML metadata in a given set of
+// bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	entities := entityPat.FindAllStringSubmatchIndex(dataStr, -1)
+	if len(entities) == 0 {
+		return nil, nil
+	}
+
+	var keys []key
+	for _, match := range pemKeyPat.FindAllStringIndex(dataStr, -1) {
+		raw := normalizePEM(dataStr[match[0]:match[1]])
+		parsed, err := ssh.ParseRawPrivateKey([]byte(raw))
+		if err != nil {
+			continue
+		}
+		if signer, ok := parsed.(crypto.Signer); ok {
+			keys = append(keys, key{offset: match[0], raw: raw, signer: signer})
+		}
+	}
+	for _, match := range elementKeyPat.FindAllStringSubmatchIndex(dataStr, -1) {
+		raw := stripSpace(dataStr[match[2]:match[3]])
+		if signer := parseDERKey(raw); signer != nil {
+			keys = append(keys, key{offset: match[2], raw: raw, signer: signer})
+		}
+	}
+
+	var certs []*x509.Certificate
+	for _, match := range certPat.FindAllStringSubmatch(dataStr, -1) {
+		der, err := base64.StdEncoding.DecodeString(stripSpace(match[1]))
+		if err != nil {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(der); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, k := range keys {
+		if _, ok := seen[k.raw]; ok {
+			continue
+		}
+		seen[k.raw] = struct{}{}
+
+		// A key belongs to the entity it's nested in, the last to start
+		// before it.
+		entityID := dataStr[entities[0][2]:entities[0][3]]
+		for _, entity := range entities {
+			if entity[0] < k.offset {
+				entityID = dataStr[entity[2]:entity[3]]
+			}
+		}
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_SAMLSigningKey,
+			Raw:          []byte(k.raw),
+			Redacted:     entityID,
+			ExtraData: map[string]string{
+				"issuer":   entityID,
+				"key_type": keyType(k.signer.Public()),
+			},
+		}
+		for _, cert := range certs {
+			if publicKey, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); ok && publicKey.Equal(k.signer.Public()) {
+				s1.ExtraData["certificate_subject"] = cert.Subject.String()
+				s1.ExtraData["certificate_expires"] = cert.NotAfter.UTC().Format(time.RFC3339)
+				break
+			}
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// parseDERKey parses a base64 DER private key, in PKCS #8, PKCS #1 or SEC 1.
+func parseDERKey(encoded string) crypto.Signer {
+	der, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	if parsed, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, _ := parsed.(crypto.Signer)
+		return signer
+	}
+	if rsaKey, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return rsaKey
+	}
+	if ecKey, err := x509.ParseECPrivateKey(der); err == nil {
+		return ecKey
+	}
+	return nil
+}
+
+func keyType(publicKey crypto.PublicKey) string {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return "rsa"
+	case *ecdsa.PublicKey:
+		return "ecdsa"
+	case ed25519.PublicKey:
+		return "ed25519"
+	default:
+		return "unknown"
+	}
+}
+
+// normalizePEM puts the base64 of a PEM block, which metadata often indents
+// or joins onto one line, on lines of its own.
+func normalizePEM(block string) string {
+	begin := strings.Index(block[5:], "-----") + 10
+	end := strings.LastIndex(block[:len(block)-5], "-----")
+	if begin > end {
+		return block
+	}
+	return strings.TrimSpace(block[:begin]) + "\n" + stripSpace(block[begin:end]) + "\n" + strings.TrimSpace(block[end:])
+}
+
+func stripSpace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_SAMLSigningKey
+}
+
+func (s Scanner) Description() string {
+	return "The private keys SAML identity and service providers sign with, left in the metadata that publishes their certificates, sign assertions that log into every service provider trusting the metadata."
+}
@@ -0,0 +1,129 @@
+package samlsigningkey
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+// newKeyPair returns a private key, in PKCS #8, and a certificate of it.
+func newKeyPair(t *testing.T, commonName string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, cert
+}
+
+func TestSAMLSigningKey_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	idpKey, idpCert := newKeyPair(t, "idp.example.com")
+	spKey, _ := newKeyPair(t, "sp.example.com")
+	idpPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: idpKey}))
+	spBase64 := base64.StdEncoding.EncodeToString(spKey)
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name: "pem key with its signing certificate",
+			input: `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com/saml">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data><ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idpCert) + `</ds:X509Certificate></ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <!-- ` + strings.ReplaceAll(idpPEM, "\n", "\n      ") + ` -->
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`,
+			want: map[string]map[string]string{
+				"https://idp.example.com/saml": {
+					"issuer":              "https://idp.example.com/saml",
+					"key_type":            "ecdsa",
+					"certificate_subject": "CN=idp.example.com",
+					"certificate_expires": "2034-01-01T00:00:00Z",
+				},
+			},
+		},
+		{
+			name: "element key of the second entity",
+			input: `<EntitiesDescriptor>
+  <EntityDescriptor entityID="https://idp.example.com/saml"></EntityDescriptor>
+  <EntityDescriptor entityID='urn:example:sp'>
+    <SPSSODescriptor>
+      <PrivateKey>
+        ` + spBase64 + `
+      </PrivateKey>
+    </SPSSODescriptor>
+  </EntityDescriptor>
+</EntitiesDescriptor>`,
+			want: map[string]map[string]string{
+				"urn:example:sp": {"issuer": "urn:example:sp", "key_type": "ecdsa"},
+			},
+		},
+		{
+			name:  "certificate only",
+			input: `<EntityDescriptor entityID="https://idp.example.com/saml"><X509Certificate>` + base64.StdEncoding.EncodeToString(idpCert) + `</X509Certificate></EntityDescriptor>`,
+			want:  map[string]map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]map[string]string, len(results))
+			for _, r := range results {
+				actual[r.Redacted] = r.ExtraData
+			}
+
+			if diff := cmp.Diff(test.want, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package slacksigningsecret
+
+import (
+	"context"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the secrets Slack signs the requests it sends to apps with,
+// assigned to variables named after them. Only the apps check the
+// signatures, so the secrets can't be verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	// The settings of Slack apps, as in SLACK_SIGNING_SECRET and the
+	// signingSecret option of Bolt apps.
+	keyPat = regexp.MustCompile(`(?i)((?:slack[_.-]?)?signing[_.-]?secret)["']?\s*(?::=|=>|=|:)\s*["']?([a-f0-9]{32})\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"signing_secret", "signingsecret", "signing-secret", "signing.secret"}
+}
+
+// FromData will find Slack signing secrets in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	seen := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		name, secret := match[1], match[2]
+		if _, ok := seen[secret]; ok {
+			continue
+		}
+		seen[secret] = struct{}{}
+
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_SlackSigningSecret,
+			Unverifiable: true,
+			Raw:          []byte(secret),
+			Redacted:     name,
+			ExtraData:    map[string]string{"name": name},
+		})
+	}
+
+	return results, nil
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_SlackSigningSecret
+}
+
+func (s Scanner) Description() string {
+	return "Slack signing secrets sign the requests Slack sends to an app, such as slash commands and interactions. Anyone with one can forge requests the app accepts as Slack's, acting as any user of the workspace."
+}
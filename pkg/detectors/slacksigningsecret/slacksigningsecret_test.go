@@ -0,0 +1,70 @@
+package slacksigningsecret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
+)
+
+func TestSlackSigningSecret_Pattern(t *testing.T) {
+	d := Scanner{}
+	ahoCorasickCore := ahocorasick.NewAhoCorasickCore([]detectors.Detector{d})
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "not hex",
+			input: `SLACK_SIGNING_SECRET=8f742231b10e8888abcd99yyyzzz85a5`,
+			want:  nil,
+		},
+		{
+			name:  "bolt app",
+			input: "const app = new App({\n  token: process.env.SLACK_BOT_TOKEN,\n  signingSecret: \"8f742231b10e8888abcd99fe7a1c85a5\",\n});",
+			want:  []string{"8f742231b10e8888abcd99fe7a1c85a5"},
+		},
+		{
+			name:  "slack signing secret",
+			input: `slack.signing-secret: 0d73c9e2b4a6f8e1c3d5b7a9f0e2c4d6`,
+			want:  []string{"0d73c9e2b4a6f8e1c3d5b7a9f0e2c4d6"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchedDetectors := ahoCorasickCore.FindDetectorMatches([]byte(test.input))
+			if len(matchedDetectors) == 0 {
+				t.Errorf("keywords '%v' not matched by: %s", d.Keywords(), test.input)
+				return
+			}
+
+			results, err := d.FromData(context.Background(), false, []byte(test.input))
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+
+			actual := make(map[string]struct{}, len(results))
+			for _, r := range results {
+				if !r.Unverifiable {
+					t.Errorf("result %s isn't flagged unverifiable", r.Raw)
+				}
+				actual[string(r.Raw)] = struct{}{}
+			}
+			expected := make(map[string]struct{}, len(test.want))
+			for _, v := range test.want {
+				expected[v] = struct{}{}
+			}
+
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("%s diff: (-want +got)\n%s", test.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package stripewebhooksecret
+
+import (
+	"context"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Scanner finds the secrets Stripe signs the events it sends to webhook
+// endpoints with. Only the endpoints check the signatures, so the secrets
+// can't be verified.
+type Scanner struct{}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+
+var (
+	// Endpoint secrets of the dashboard have 32 characters, and those of
+	// the Stripe CLI 64 hex characters.
+	keyPat = regexp.MustCompile(`\b(whsec_[a-zA-Z0-9]{32,64})\b`)
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"whsec_"}
+}
+
+// FromData will find Stripe webhook signing secrets in a given set of bytes.
+func (s Scanner) FromData(_ context.Context, _ bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	uniqueMatches := make(map[string]struct{})
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		uniqueMatches[match[1]] = struct{}{}
+	}
+
+	for match := range uniqueMatches {
+		results = append(results, detectors.Result{
+			DetectorType: detectorspb.DetectorType_StripeWebhookSecret,
+			Unverifiable: true,
+			Raw:          []byte(match),
+			Redacted:     match[:10] + "...",
+		})
+	}
+
+	return results, nil
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_StripeWebhookSecret
+}
+
+func (s Scanner) Description() string {
+	return "Stripe webhook signing secrets sign the events Stripe sends to a webhook endpoint. Anyone with one can forge events, such as payments that never happened, that the endpoint accepts as Stripe's."
+}
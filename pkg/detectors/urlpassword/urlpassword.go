@@ -0,0 +1,257 @@
+package urlpassword
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	regexp "github.com/wasilibs/go-re2"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/ftp"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/mongodb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/postgres"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/rabbitmq"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/redis"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/uri"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const (
+	// smtpAuthFailed is the reply to invalid credentials.
+	// https://datatracker.ietf.org/doc/html/rfc4954#section-6
+	smtpAuthFailed = 535
+
+	defaultVerificationTimeout = 10 * time.Second
+)
+
+// verificationKey is the context key of the setting of WithVerification.
+type verificationKey struct{}
+
+// WithVerification returns a copy of ctx in which the passwords found are
+// verified, when verification is requested, if enabled is set. The setting is
+// scoped to the context so engines with different settings can run in the
+// same process.
+func WithVerification(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, verificationKey{}, enabled)
+}
+
+// verificationEnabled returns whether the passwords found are verified in
+// ctx.
+func verificationEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(verificationKey{}).(bool)
+	return enabled
+}
+
+// Scanner finds the passwords embedded in the userinfo of URLs, whatever
+// their scheme. Live verification is opt-in, as it logs into the hosts of
+// the URLs, and only supported for the protocols verifiers exist for.
+type Scanner struct {
+	// Verification timeout. Defaults to 10 seconds if unset.
+	verificationTimeout time.Duration
+}
+
+// Ensure the Scanner satisfies the interface at compile time.
+var _ detectors.Detector = (*Scanner)(nil)
+var _ detectors.CustomFalsePositiveChecker = (*Scanner)(nil)
+
+var (
+	keyPat = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]{1,19})://([^\s:/?#@"'<>]{1,128}):([^\s/?#@"'<>]{1,128})@([-.\w%]+|\[[0-9a-fA-F:.]+\])(?::\d{1,5})?[^\s"'<>]*`)
+
+	// verifiers verify the URLs of a scheme with the detector of its
+	// protocol.
+	verifiers = map[string]detectors.Detector{
+		"ftp":         ftp.Scanner{},
+		"redis":       redis.Scanner{},
+		"rediss":      redis.Scanner{},
+		"amqp":        rabbitmq.Scanner{},
+		"amqps":       rabbitmq.Scanner{},
+		"postgres":    postgres.Scanner{},
+		"postgresql":  postgres.Scanner{},
+		"mongodb":     mongodb.Scanner{},
+		"mongodb+srv": mongodb.Scanner{},
+		"http":        &uri.Scanner{},
+		"https":       &uri.Scanner{},
+	}
+)
+
+// Keywords are used for efficiently pre-filtering chunks.
+// Use identifiers in the secret preferably, or the provider name.
+func (s Scanner) Keywords() []string {
+	return []string{"://"}
+}
+
+// FromData will find and optionally verify passwords in URLs in a given set
+// of bytes. URLs are only verified if URL password verification is enabled.
+func (s Scanner) FromData(ctx context.Context, verify bool, data []byte) (results []detectors.Result, err error) {
+	dataStr := string(data)
+
+	for _, match := range keyPat.FindAllStringSubmatch(dataStr, -1) {
+		urlMatch := match[0]
+		password := match[3]
+
+		// Skip findings where the password only has "*" characters, this is a redacted password
+		// Also include the url encoded "*" characters: "%2A"
+		if strings.Trim(password, "*") == "" || strings.Trim(password, "%2A") == "" {
+			continue
+		}
+
+		parsedURL, err := url.Parse(urlMatch)
+		if err != nil {
+			continue
+		}
+		if _, ok := parsedURL.User.Password(); !ok || parsedURL.Host == "" {
+			continue
+		}
+		scheme := strings.ToLower(parsedURL.Scheme)
+
+		rawURL := *parsedURL
+		// Removing the path causes possible deduplication issues if some paths have credentials and some do not.
+		rawURL.Path, rawURL.RawPath, rawURL.RawQuery, rawURL.Fragment = "", "", "", ""
+
+		s1 := detectors.Result{
+			DetectorType: detectorspb.DetectorType_URLPassword,
+			Raw:          []byte(rawURL.String()),
+			RawV2:        []byte(parsedURL.String()),
+			Redacted:     detectors.RedactURL(rawURL),
+			ExtraData: map[string]string{
+				"scheme":   scheme,
+				"host":     parsedURL.Hostname(),
+				"username": parsedURL.User.Username(),
+			},
+		}
+		if port := parsedURL.Port(); port != "" {
+			s1.ExtraData["port"] = port
+		}
+
+		if verify && verificationEnabled(ctx) {
+			isVerified, verificationErr := s.verify(ctx, scheme, parsedURL)
+			s1.Verified = isVerified
+			s1.SetVerificationError(verificationErr, password)
+		}
+
+		if !s1.Verified {
+			// Skip unverified findings where the password starts with a `$` - it's almost certainly a variable.
+			if strings.HasPrefix(password, "$") {
+				continue
+			}
+		}
+
+		results = append(results, s1)
+	}
+
+	return results, nil
+}
+
+// verify logs into the host of u with its credentials, as the protocol of
+// scheme does. URLs of protocols without a verifier are left unverified.
+func (s Scanner) verify(ctx context.Context, scheme string, u *url.URL) (bool, error) {
+	switch scheme {
+	case "smtp", "smtps", "submission":
+		timeout := s.verificationTimeout
+		if timeout == 0 {
+			timeout = defaultVerificationTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return verifySMTP(ctx, scheme, u)
+	}
+
+	verifier, ok := verifiers[scheme]
+	if !ok {
+		return false, nil
+	}
+	results, err := verifier.FromData(ctx, true, []byte(u.String()))
+	if err != nil {
+		return false, err
+	}
+	for _, r := range results {
+		if r.Verified {
+			return true, nil
+		}
+		if err := r.VerificationError(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// verifySMTP authenticates to a mail server with PLAIN, over implicit TLS
+// for smtps, and after STARTTLS for the other schemes if the server offers
+// it.
+func verifySMTP(ctx context.Context, scheme string, u *url.URL) (bool, error) {
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		switch scheme {
+		case "smtps":
+			port = "465"
+		case "submission":
+			port = "587"
+		default:
+			port = "25"
+		}
+	}
+	addr := net.JoinHostPort(host, port)
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	var err error
+	if scheme == "smtps" {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && scheme != "smtps" {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return false, err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); !ok {
+		return false, errors.New("the server doesn't support authentication")
+	}
+
+	password, _ := u.User.Password()
+	err = c.Auth(smtp.PlainAuth("", u.User.Username(), password, host))
+	var replyErr *textproto.Error
+	switch {
+	case err == nil:
+		_ = c.Quit()
+		return true, nil
+	case errors.As(err, &replyErr) && replyErr.Code == smtpAuthFailed:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (s Scanner) IsFalsePositive(_ detectors.Result) (bool, string) {
+	return false, ""
+}
+
+func (s Scanner) Type() detectorspb.DetectorType {
+	return detectorspb.DetectorType_URLPassword
+}
+
+func (s Scanner) Description() string {
+	return "This detector identifies passwords embedded in URLs of any scheme, such as those of FTP, SMTP, Redis, AMQP and HTTP servers, which give access to the services the URLs point to."
+}
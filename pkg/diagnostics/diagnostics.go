@@ -0,0 +1,115 @@
+// Package diagnostics serves runtime profiles and logs runtime statistics,
+// which help tell a stuck scan from a slow one.
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/felixge/fgprof"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Handler serves the net/http/pprof profiles under /debug/pprof/ and an
+// fgprof profile, which also samples goroutines blocked on I/O, at
+// /debug/fgprof.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/fgprof", fgprof.Handler())
+	return mux
+}
+
+// Serve listens on addr and serves Handler in the background until ctx is
+// cancelled. Block profiling is enabled, since it is otherwise empty.
+func Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", addr, err)
+	}
+	runtime.SetBlockProfileRate(1)
+	runtime.SetMutexProfileFraction(-1)
+
+	srv := &http.Server{Handler: Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	go func() {
+		ctx.Logger().Info("serving pprof and fgprof", "addr", ln.Addr().String(), "paths", []string{"/debug/pprof/", "/debug/fgprof"})
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ctx.Logger().Error(err, "error serving pprof and fgprof")
+		}
+	}()
+	return nil
+}
+
+// Stats is a snapshot of the Go runtime.
+type Stats struct {
+	Goroutines int
+	HeapAlloc  uint64
+	HeapInuse  uint64
+	Sys        uint64
+	NumGC      uint32
+	// LastGCPause is the duration of the most recent GC pause.
+	LastGCPause time.Duration
+	// GCCPUFraction is the fraction of CPU time used by the GC since start.
+	GCCPUFraction float64
+}
+
+// ReadStats returns the current runtime statistics. It briefly stops the
+// world, so it shouldn't be called in a tight loop.
+func ReadStats() Stats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	var lastPause time.Duration
+	if m.NumGC > 0 {
+		lastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+	return Stats{
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAlloc:     m.HeapAlloc,
+		HeapInuse:     m.HeapInuse,
+		Sys:           m.Sys,
+		NumGC:         m.NumGC,
+		LastGCPause:   lastPause,
+		GCCPUFraction: m.GCCPUFraction,
+	}
+}
+
+// LogRuntimeStats logs ReadStats every interval until ctx is cancelled.
+func LogRuntimeStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logStats(ctx, ReadStats())
+		}
+	}
+}
+
+func logStats(ctx context.Context, s Stats) {
+	ctx.Logger().Info("runtime stats",
+		"goroutines", s.Goroutines,
+		"heap_alloc", humanize.IBytes(s.HeapAlloc),
+		"heap_inuse", humanize.IBytes(s.HeapInuse),
+		"sys", humanize.IBytes(s.Sys),
+		"num_gc", s.NumGC,
+		"last_gc_pause", s.LastGCPause.String(),
+		"gc_cpu_fraction", fmt.Sprintf("%.4f", s.GCCPUFraction),
+	)
+}
@@ -0,0 +1,77 @@
+package diagnostics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+)
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline"} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err, path)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err, path)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.NotEmpty(t, body, path)
+	}
+
+	resp, err := http.Get(srv.URL + "/debug/fgprof?seconds=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Bind a free port, then serve on it.
+	ln := httptest.NewUnstartedServer(nil).Listener
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	require.NoError(t, Serve(ctx, addr))
+
+	resp, err := http.Get("http://" + addr + "/debug/pprof/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Error(t, Serve(ctx, addr), "address already in use")
+}
+
+func TestLogRuntimeStats(t *testing.T) {
+	var buf bytes.Buffer
+	logger, flush := log.New("trufflehog", log.WithConsoleSink(&buf))
+	ctx, cancel := context.WithTimeout(context.WithLogger(context.Background(), logger), 50*time.Millisecond)
+	defer cancel()
+
+	LogRuntimeStats(ctx, 10*time.Millisecond)
+	require.NoError(t, flush())
+
+	out := buf.String()
+	assert.Contains(t, out, "runtime stats")
+	for _, key := range []string{"goroutines", "heap_alloc", "heap_inuse", "num_gc", "last_gc_pause"} {
+		assert.Contains(t, out, key)
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	stats := ReadStats()
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAlloc)
+	assert.GreaterOrEqual(t, stats.Sys, stats.HeapInuse)
+}
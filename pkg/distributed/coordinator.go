@@ -0,0 +1,391 @@
+// Package distributed scans a source across several processes. A coordinator
+// enumerates the units of the source and leases them over gRPC to workers,
+// which chunk and scan them and report their findings back. Coordinator and
+// workers are started with the same scan command so every process can
+// initialize the source itself; only units and findings are exchanged.
+package distributed
+
+import (
+	stdcontext "context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/distributedpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	// defaultLeaseTimeout is how long a worker can go without a heartbeat
+	// before its leases are handed to other workers.
+	defaultLeaseTimeout = time.Minute
+	// defaultPollInterval is how often the coordinator checks for expired
+	// leases and finished sources.
+	defaultPollInterval = time.Second
+)
+
+// Coordinator is a sources.UnitDispatcher that leases units to workers over
+// gRPC and feeds the findings they report into the local engine.
+type Coordinator struct {
+	distributedpb.UnimplementedCoordinatorServer
+
+	token        string
+	tlsConfig    *tls.Config
+	leaseTimeout time.Duration
+	pollInterval time.Duration
+	log          logr.Logger
+	server       *grpc.Server
+
+	mu      sync.Mutex
+	queues  map[string]*unitQueue
+	leases  map[string]*lease
+	workers map[string]*workerState
+	closed  bool
+
+	// resultsMu guards results against being written to once the last
+	// source finished, after which the engine may close it.
+	resultsMu sync.RWMutex
+	results   chan<- detectors.ResultWithMetadata
+	active    int
+}
+
+// unitQueue holds the units of one source being dispatched.
+type unitQueue struct {
+	job     sources.JobProgressRef
+	kind    sourcespb.SourceType
+	done    func(sources.SourceUnit, error)
+	pending []*lease
+	leased  map[string]*lease
+	// enumerated is set once every unit of the source has been queued.
+	enumerated bool
+	// workers are the workers that were leased units of the source.
+	workers map[string]struct{}
+
+	total, completed int
+}
+
+type lease struct {
+	id      string
+	queue   *unitQueue
+	unit    sources.SourceUnit
+	data    []byte
+	worker  string
+	expires time.Time
+	chunks  uint64
+	bytes   uint64
+}
+
+type workerState struct {
+	lastSeen time.Time
+	left     bool
+}
+
+// NewCoordinator creates a Coordinator. Workers must authenticate with token
+// if it isn't empty. If tlsConfig is nil, connections aren't encrypted.
+func NewCoordinator(token string, tlsConfig *tls.Config) *Coordinator {
+	return &Coordinator{
+		token:        token,
+		tlsConfig:    tlsConfig,
+		leaseTimeout: defaultLeaseTimeout,
+		pollInterval: defaultPollInterval,
+		queues:       make(map[string]*unitQueue),
+		leases:       make(map[string]*lease),
+		workers:      make(map[string]*workerState),
+	}
+}
+
+// Serve accepts worker connections on lis until Stop is called. Findings
+// reported by workers are sent to results, which must stay open until every
+// source dispatched by the coordinator has finished.
+func (c *Coordinator) Serve(ctx context.Context, lis net.Listener, results chan<- detectors.ResultWithMetadata) error {
+	c.resultsMu.Lock()
+	c.results = results
+	c.resultsMu.Unlock()
+
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(c.authenticate)}
+	if c.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(c.tlsConfig)))
+	}
+	c.mu.Lock()
+	c.log = ctx.Logger()
+	c.server = grpc.NewServer(opts...)
+	c.mu.Unlock()
+	distributedpb.RegisterCoordinatorServer(c.server, c)
+
+	ctx.Logger().Info("waiting for workers", "address", lis.Addr().String())
+	return c.server.Serve(lis)
+}
+
+// Stop stops serving workers. Workers asking for units of sources the
+// coordinator doesn't know about are told there are none left from then on.
+func (c *Coordinator) Stop() {
+	c.mu.Lock()
+	c.closed = true
+	server := c.server
+	c.mu.Unlock()
+	if server != nil {
+		server.GracefulStop()
+	}
+}
+
+func (c *Coordinator) authenticate(ctx stdcontext.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if c.token != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var token string
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = strings.TrimPrefix(values[0], "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid coordinator token")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// DispatchUnits implements sources.UnitDispatcher. It returns once every unit
+// has been scanned and every worker that scanned one has reported all of its
+// findings, or has stopped responding.
+func (c *Coordinator) DispatchUnits(ctx context.Context, job sources.JobProgressRef, kind sourcespb.SourceType, units <-chan sources.SourceUnit, done func(sources.SourceUnit, error)) error {
+	q := &unitQueue{
+		job:     job,
+		kind:    kind,
+		done:    done,
+		leased:  make(map[string]*lease),
+		workers: make(map[string]struct{}),
+	}
+	c.mu.Lock()
+	if _, ok := c.queues[job.SourceName]; ok {
+		c.mu.Unlock()
+		return fmt.Errorf("source %q is already being dispatched", job.SourceName)
+	}
+	c.queues[job.SourceName] = q
+	c.mu.Unlock()
+
+	c.resultsMu.Lock()
+	c.active++
+	c.resultsMu.Unlock()
+	defer func() {
+		c.resultsMu.Lock()
+		c.active--
+		c.resultsMu.Unlock()
+		c.mu.Lock()
+		delete(c.queues, job.SourceName)
+		c.mu.Unlock()
+	}()
+
+	queueErr := make(chan error, 1)
+	go func() {
+		queueErr <- c.queueUnits(ctx, q, units)
+	}()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-queueErr:
+			if err != nil {
+				return err
+			}
+			queueErr = nil
+		case <-ticker.C:
+		}
+		c.expireLeases(ctx)
+		if c.finished(q) {
+			ctx.Logger().Info("distributed source finished", "units", q.completed)
+			return nil
+		}
+	}
+}
+
+// queueUnits encodes every unit received on units and queues it for workers
+// to lease.
+func (c *Coordinator) queueUnits(ctx context.Context, q *unitQueue, units <-chan sources.SourceUnit) error {
+	for unit := range units {
+		data, err := json.Marshal(unit)
+		if err != nil {
+			return fmt.Errorf("could not encode source unit: %w", err)
+		}
+		c.mu.Lock()
+		q.pending = append(q.pending, &lease{id: common.RandomID(16), queue: q, unit: unit, data: data})
+		q.total++
+		c.mu.Unlock()
+	}
+	c.mu.Lock()
+	q.enumerated = true
+	c.mu.Unlock()
+	ctx.Logger().V(1).Info("queued source units for workers", "units", q.total)
+	return nil
+}
+
+// expireLeases returns the units leased to workers that stopped sending
+// heartbeats to the front of their queue.
+func (c *Coordinator) expireLeases(ctx context.Context) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, l := range c.leases {
+		if now.Before(l.expires) {
+			continue
+		}
+		ctx.Logger().Info("worker lease expired, requeueing unit", "worker", l.worker, "unit", l.unit.Display())
+		delete(c.leases, id)
+		delete(l.queue.leased, id)
+		l.worker, l.chunks, l.bytes = "", 0, 0
+		l.id = common.RandomID(16)
+		l.queue.pending = append([]*lease{l}, l.queue.pending...)
+	}
+}
+
+// finished reports whether every unit of q has been scanned and the workers
+// that scanned them are done reporting findings.
+func (c *Coordinator) finished(q *unitQueue) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !q.enumerated || len(q.pending) > 0 || len(q.leased) > 0 {
+		return false
+	}
+	for id := range q.workers {
+		w := c.workers[id]
+		if !w.left && time.Since(w.lastSeen) < c.leaseTimeout {
+			return false
+		}
+	}
+	return true
+}
+
+// seen records that a worker is alive. The caller must hold c.mu.
+func (c *Coordinator) seen(workerID string) *workerState {
+	w, ok := c.workers[workerID]
+	if !ok {
+		w = new(workerState)
+		c.workers[workerID] = w
+	}
+	w.lastSeen = time.Now()
+	return w
+}
+
+func (c *Coordinator) LeaseUnit(_ stdcontext.Context, req *distributedpb.LeaseUnitRequest) (*distributedpb.LeaseUnitResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := c.seen(req.GetWorkerId())
+
+	q, ok := c.queues[req.GetSourceName()]
+	if !ok {
+		// The source hasn't started yet, unless the scan is over.
+		return &distributedpb.LeaseUnitResponse{Done: c.closed}, nil
+	}
+	if q.kind != req.GetSourceType() {
+		return nil, status.Errorf(codes.InvalidArgument, "source %q is a %s source, not %s", req.GetSourceName(), q.kind, req.GetSourceType())
+	}
+	if len(q.pending) == 0 {
+		return &distributedpb.LeaseUnitResponse{Done: q.enumerated && len(q.leased) == 0}, nil
+	}
+
+	l := q.pending[0]
+	q.pending = q.pending[1:]
+	l.worker = req.GetWorkerId()
+	l.expires = time.Now().Add(c.leaseTimeout)
+	q.leased[l.id] = l
+	q.workers[l.worker] = struct{}{}
+	c.leases[l.id] = l
+	w.left = false
+	return &distributedpb.LeaseUnitResponse{LeaseId: l.id, Unit: l.data}, nil
+}
+
+func (c *Coordinator) Heartbeat(_ stdcontext.Context, req *distributedpb.HeartbeatRequest) (*distributedpb.HeartbeatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen(req.GetWorkerId())
+	for _, p := range req.GetProgress() {
+		if l, ok := c.leases[p.GetLeaseId()]; ok && l.worker == req.GetWorkerId() {
+			l.expires = time.Now().Add(c.leaseTimeout)
+			l.chunks, l.bytes = p.GetChunks(), p.GetBytes()
+		}
+	}
+	return &distributedpb.HeartbeatResponse{}, nil
+}
+
+func (c *Coordinator) CompleteUnit(_ stdcontext.Context, req *distributedpb.CompleteUnitRequest) (*distributedpb.CompleteUnitResponse, error) {
+	c.mu.Lock()
+	c.seen(req.GetWorkerId())
+	l, ok := c.leases[req.GetProgress().GetLeaseId()]
+	if !ok || l.worker != req.GetWorkerId() {
+		// The lease expired and the unit was queued again.
+		c.mu.Unlock()
+		return &distributedpb.CompleteUnitResponse{}, nil
+	}
+	q := l.queue
+	delete(c.leases, l.id)
+	delete(q.leased, l.id)
+	q.completed++
+	completed, total := q.completed, q.total
+	c.mu.Unlock()
+
+	var err error
+	if msg := req.GetError(); msg != "" {
+		err = fmt.Errorf("worker %s: %s", req.GetWorkerId(), msg)
+	}
+	q.done(l.unit, err)
+	c.log.V(1).Info("unit scanned",
+		"worker", req.GetWorkerId(),
+		"unit", l.unit.Display(),
+		"chunks", req.GetProgress().GetChunks(),
+		"bytes", req.GetProgress().GetBytes(),
+		"units_completed", completed,
+		"units_total", total,
+	)
+	return &distributedpb.CompleteUnitResponse{}, nil
+}
+
+func (c *Coordinator) ReportFindings(ctx stdcontext.Context, req *distributedpb.ReportFindingsRequest) (*distributedpb.ReportFindingsResponse, error) {
+	c.mu.Lock()
+	c.seen(req.GetWorkerId())
+	jobs := make(map[string]sources.JobProgressRef, len(c.queues))
+	for name, q := range c.queues {
+		jobs[name] = q.job
+	}
+	c.mu.Unlock()
+
+	c.resultsMu.RLock()
+	defer c.resultsMu.RUnlock()
+	if c.active == 0 || c.results == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no source is being scanned")
+	}
+	for _, f := range req.GetFindings() {
+		job, ok := jobs[f.GetSourceName()]
+		if !ok {
+			job = sources.JobProgressRef{SourceName: f.GetSourceName()}
+		}
+		select {
+		case c.results <- fromFinding(f, job):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return &distributedpb.ReportFindingsResponse{}, nil
+}
+
+func (c *Coordinator) Leave(_ stdcontext.Context, req *distributedpb.LeaveRequest) (*distributedpb.LeaveResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen(req.GetWorkerId()).left = true
+	return &distributedpb.LeaveResponse{}, nil
+}
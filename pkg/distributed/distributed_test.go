@@ -0,0 +1,192 @@
+package distributed
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/distributedpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// unitSource enumerates a fixed list of units, producing one chunk per unit
+// with the unit ID as its data.
+type unitSource struct {
+	sources.CommonSourceUnitUnmarshaller
+	units []string
+}
+
+func (s *unitSource) Type() sourcespb.SourceType        { return sourcespb.SourceType_SOURCE_TYPE_GIT }
+func (s *unitSource) SourceID() sources.SourceID        { return 1 }
+func (s *unitSource) JobID() sources.JobID              { return 1 }
+func (s *unitSource) GetProgress() *sources.Progress    { return nil }
+func (s *unitSource) HealthCheck(context.Context) error { return nil }
+func (s *unitSource) Init(context.Context, string, sources.JobID, sources.SourceID, bool, *anypb.Any, int) error {
+	return nil
+}
+func (s *unitSource) Chunks(context.Context, chan *sources.Chunk, ...sources.ChunkingTarget) error {
+	return nil
+}
+
+func (s *unitSource) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
+	for _, id := range s.units {
+		if err := reporter.UnitOk(ctx, sources.CommonSourceUnit{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *unitSource) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporter sources.ChunkReporter) error {
+	id, _ := unit.SourceUnitID()
+	return reporter.ChunkOk(ctx, sources.Chunk{SourceName: "test", Data: []byte(id)})
+}
+
+func startCoordinator(t *testing.T, ctx context.Context, token string) (*Coordinator, string, chan detectors.ResultWithMetadata) {
+	t.Helper()
+	c := NewCoordinator(token, nil)
+	c.pollInterval = 10 * time.Millisecond
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	results := make(chan detectors.ResultWithMetadata, 16)
+	go func() { _ = c.Serve(ctx, lis, results) }()
+	t.Cleanup(c.Stop)
+	return c, lis.Addr().String(), results
+}
+
+func TestDistributedScan(t *testing.T) {
+	ctx := context.Background()
+	units := []string{"a", "b", "c", "d", "e"}
+	c, addr, results := startCoordinator(t, ctx, "token")
+
+	mgr := sources.NewManager(sources.WithSourceUnits(), sources.WithUnitDispatcher(c))
+	_, err := mgr.Run(ctx, "test", &unitSource{units: units})
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		scanned []string
+	)
+	for i := 0; i < 2; i++ {
+		w, err := NewWorker(addr, "token", nil, 1)
+		require.NoError(t, err)
+		w.pollInterval = 10 * time.Millisecond
+		w.Start(ctx)
+
+		workerMgr := sources.NewManager(sources.WithSourceUnits(), sources.WithUnitLeaser(w))
+		_, err = workerMgr.Run(ctx, "test", &unitSource{})
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Stand in for the engine: report a finding for every chunk.
+			go func() { assert.NoError(t, workerMgr.Wait()) }()
+			for chunk := range workerMgr.Chunks() {
+				mu.Lock()
+				scanned = append(scanned, string(chunk.Data))
+				mu.Unlock()
+				assert.NoError(t, w.Print(ctx, &detectors.ResultWithMetadata{
+					SourceName: chunk.SourceName,
+					Result:     detectors.Result{Raw: chunk.Data},
+				}))
+			}
+			assert.NoError(t, w.Close(ctx))
+		}()
+	}
+
+	require.NoError(t, mgr.Wait())
+	wg.Wait()
+	close(results)
+
+	var found []string
+	for r := range results {
+		assert.Equal(t, "test", r.SourceName)
+		assert.Equal(t, sources.JobID(1), r.JobID)
+		found = append(found, string(r.Raw))
+	}
+	sort.Strings(found)
+	sort.Strings(scanned)
+	assert.Equal(t, units, scanned, "every unit should be scanned exactly once")
+	assert.Equal(t, units, found)
+}
+
+func TestWorker_InvalidToken(t *testing.T) {
+	ctx := context.Background()
+	_, addr, _ := startCoordinator(t, ctx, "token")
+
+	w, err := NewWorker(addr, "wrong", nil, 1)
+	require.NoError(t, err)
+	defer w.conn.Close()
+	err = w.LeaseUnits(ctx, "test", sourcespb.SourceType_SOURCE_TYPE_GIT, &unitSource{}, nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), err)
+}
+
+func TestCoordinator_ExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	c := NewCoordinator("", nil)
+	c.pollInterval = 10 * time.Millisecond
+	c.leaseTimeout = 50 * time.Millisecond
+
+	units := make(chan sources.SourceUnit, 1)
+	units <- sources.CommonSourceUnit{ID: "a"}
+	close(units)
+	var completed []string
+	dispatched := make(chan error, 1)
+	go func() {
+		dispatched <- c.DispatchUnits(ctx, sources.JobProgressRef{SourceName: "test"}, sourcespb.SourceType_SOURCE_TYPE_GIT, units, func(unit sources.SourceUnit, err error) {
+			assert.NoError(t, err)
+			id, _ := unit.SourceUnitID()
+			completed = append(completed, id)
+		})
+	}()
+
+	lease := func(worker string) *distributedpb.LeaseUnitResponse {
+		resp, err := c.LeaseUnit(ctx, &distributedpb.LeaseUnitRequest{WorkerId: worker, SourceName: "test", SourceType: sourcespb.SourceType_SOURCE_TYPE_GIT})
+		require.NoError(t, err)
+		return resp
+	}
+	var first *distributedpb.LeaseUnitResponse
+	require.Eventually(t, func() bool {
+		first = lease("dead")
+		return first.GetLeaseId() != ""
+	}, time.Second, 5*time.Millisecond)
+
+	// The first worker never reports back, so the unit is leased again once
+	// its lease expires.
+	var second *distributedpb.LeaseUnitResponse
+	require.Eventually(t, func() bool {
+		second = lease("alive")
+		return second.GetLeaseId() != ""
+	}, time.Second, 5*time.Millisecond)
+	assert.NotEqual(t, first.GetLeaseId(), second.GetLeaseId())
+	assert.Equal(t, first.GetUnit(), second.GetUnit())
+
+	_, err := c.CompleteUnit(ctx, &distributedpb.CompleteUnitRequest{WorkerId: "dead", Progress: &distributedpb.UnitProgress{LeaseId: first.GetLeaseId()}})
+	require.NoError(t, err)
+	_, err = c.CompleteUnit(ctx, &distributedpb.CompleteUnitRequest{WorkerId: "alive", Progress: &distributedpb.UnitProgress{LeaseId: second.GetLeaseId()}})
+	require.NoError(t, err)
+	_, err = c.Leave(ctx, &distributedpb.LeaveRequest{WorkerId: "alive"})
+	require.NoError(t, err)
+
+	select {
+	case err := <-dispatched:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("dispatch didn't finish")
+	}
+	assert.Equal(t, []string{"a"}, completed)
+	assert.False(t, lease("alive").GetDone(), "finished sources are forgotten until the coordinator stops")
+}
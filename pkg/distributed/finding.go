@@ -0,0 +1,63 @@
+package distributed
+
+import (
+	"errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/distributedpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// ToFinding converts a result into the message workers report it to the
+// coordinator with, which the server also streams the findings of jobs as.
+func ToFinding(r *detectors.ResultWithMetadata) *distributedpb.Finding {
+	f := &distributedpb.Finding{
+		SourceName:              r.SourceName,
+		SourceType:              r.SourceType,
+		SourceMetadata:          r.SourceMetadata,
+		DetectorType:            r.DetectorType,
+		DetectorName:            r.DetectorName,
+		DecoderType:             r.DecoderType,
+		Verified:                r.Verified,
+		Raw:                     r.Raw,
+		RawV2:                   r.RawV2,
+		Redacted:                r.Redacted,
+		ExtraData:               r.ExtraData,
+		StructuredData:          r.StructuredData,
+		AnalysisInfo:            r.AnalysisInfo,
+		IsWordlistFalsePositive: r.IsWordlistFalsePositive,
+	}
+	if err := r.VerificationError(); err != nil {
+		f.VerificationError = err.Error()
+	}
+	return f
+}
+
+// fromFinding converts a finding reported by a worker back into a result of
+// the job it belongs to on the coordinator.
+func fromFinding(f *distributedpb.Finding, job sources.JobProgressRef) detectors.ResultWithMetadata {
+	r := detectors.ResultWithMetadata{
+		IsWordlistFalsePositive: f.GetIsWordlistFalsePositive(),
+		SourceMetadata:          f.GetSourceMetadata(),
+		SourceID:                job.SourceID,
+		JobID:                   job.JobID,
+		SourceType:              f.GetSourceType(),
+		SourceName:              f.GetSourceName(),
+		Result: detectors.Result{
+			DetectorType:   f.GetDetectorType(),
+			DetectorName:   f.GetDetectorName(),
+			DecoderType:    f.GetDecoderType(),
+			Verified:       f.GetVerified(),
+			Raw:            f.GetRaw(),
+			RawV2:          f.GetRawV2(),
+			Redacted:       f.GetRedacted(),
+			ExtraData:      f.GetExtraData(),
+			StructuredData: f.GetStructuredData(),
+			AnalysisInfo:   f.GetAnalysisInfo(),
+		},
+	}
+	if msg := f.GetVerificationError(); msg != "" {
+		r.SetVerificationError(errors.New(msg))
+	}
+	return r
+}
@@ -0,0 +1,269 @@
+package distributed
+
+import (
+	stdcontext "context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/distributedpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+const (
+	defaultHeartbeatInterval = 10 * time.Second
+	// findingsBatchSize is the number of findings sent to the coordinator
+	// per request.
+	findingsBatchSize = 100
+)
+
+// Worker is a sources.UnitLeaser that scans units leased from a coordinator.
+// It is also a printer that reports findings back to the coordinator, so it
+// should be the only output of the worker's engine.
+type Worker struct {
+	id                string
+	conn              *grpc.ClientConn
+	client            distributedpb.CoordinatorClient
+	slots             chan struct{}
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	stopHeartbeat     chan struct{}
+	heartbeatDone     chan struct{}
+
+	mu       sync.Mutex
+	leases   map[string]*workerLease
+	findings []*distributedpb.Finding
+}
+
+type workerLease struct {
+	id     string
+	chunks uint64
+	bytes  uint64
+}
+
+// NewWorker creates a Worker that scans at most concurrency units leased from
+// the coordinator at addr at once. If tlsConfig is nil, the connection isn't
+// encrypted.
+func NewWorker(addr, token string, tlsConfig *tls.Config, concurrency int) (*Worker, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: token, secure: tlsConfig != nil}))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to coordinator: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		id:                hostname + "-" + common.RandomID(8),
+		conn:              conn,
+		client:            distributedpb.NewCoordinatorClient(conn),
+		slots:             make(chan struct{}, concurrency),
+		pollInterval:      defaultPollInterval,
+		heartbeatInterval: defaultHeartbeatInterval,
+		leases:            make(map[string]*workerLease),
+	}, nil
+}
+
+// Start sends heartbeats to the coordinator until Close is called so it
+// knows the worker is still scanning its units.
+func (w *Worker) Start(ctx context.Context) {
+	w.stopHeartbeat = make(chan struct{})
+	w.heartbeatDone = make(chan struct{})
+	go func() {
+		defer close(w.heartbeatDone)
+		ticker := time.NewTicker(w.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopHeartbeat:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := w.heartbeat(ctx); err != nil {
+				ctx.Logger().Error(err, "could not send heartbeat to coordinator")
+			}
+		}
+	}()
+}
+
+func (w *Worker) heartbeat(ctx context.Context) error {
+	w.mu.Lock()
+	req := &distributedpb.HeartbeatRequest{WorkerId: w.id}
+	for _, l := range w.leases {
+		req.Progress = append(req.Progress, &distributedpb.UnitProgress{LeaseId: l.id, Chunks: l.chunks, Bytes: l.bytes})
+	}
+	w.mu.Unlock()
+	_, err := w.client.Heartbeat(ctx, req)
+	return err
+}
+
+// Close flushes any findings that haven't been reported, tells the
+// coordinator the worker is done, and closes the connection.
+func (w *Worker) Close(ctx context.Context) error {
+	if w.stopHeartbeat != nil {
+		close(w.stopHeartbeat)
+		<-w.heartbeatDone
+	}
+	err := w.Flush(ctx)
+	if err == nil {
+		_, err = w.client.Leave(ctx, &distributedpb.LeaveRequest{WorkerId: w.id})
+	}
+	if closeErr := w.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// LeaseUnits implements sources.UnitLeaser.
+func (w *Worker) LeaseUnits(ctx context.Context, sourceName string, kind sourcespb.SourceType, unmarshaller sources.SourceUnitUnmarshaller, reporter sources.UnitReporter) error {
+	for {
+		// Wait for a free slot so the worker never holds more leases
+		// than it can scan.
+		if err := common.CancellableWrite(ctx, w.slots, struct{}{}); err != nil {
+			return err
+		}
+		resp, err := w.client.LeaseUnit(ctx, &distributedpb.LeaseUnitRequest{
+			WorkerId:   w.id,
+			SourceName: sourceName,
+			SourceType: kind,
+		}, grpc.WaitForReady(true))
+		if err != nil {
+			<-w.slots
+			return fmt.Errorf("could not lease unit from coordinator: %w", err)
+		}
+		if resp.GetLeaseId() == "" {
+			<-w.slots
+			if resp.GetDone() {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+
+		unit, err := unmarshaller.UnmarshalSourceUnit(resp.GetUnit())
+		if err != nil {
+			w.complete(ctx, &workerLease{id: resp.GetLeaseId()}, err)
+			<-w.slots
+			if err := reporter.UnitErr(ctx, fmt.Errorf("could not decode leased unit: %w", err)); err != nil {
+				return err
+			}
+			continue
+		}
+		w.mu.Lock()
+		w.leases[leaseKey(unit)] = &workerLease{id: resp.GetLeaseId()}
+		w.mu.Unlock()
+		if err := reporter.UnitOk(ctx, unit); err != nil {
+			return err
+		}
+	}
+}
+
+// ReportChunk implements sources.UnitLeaser.
+func (w *Worker) ReportChunk(unit sources.SourceUnit, chunk *sources.Chunk) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if l, ok := w.leases[leaseKey(unit)]; ok {
+		l.chunks++
+		l.bytes += uint64(len(chunk.Data))
+	}
+}
+
+// ReleaseUnit implements sources.UnitLeaser.
+func (w *Worker) ReleaseUnit(ctx context.Context, unit sources.SourceUnit, err error) {
+	key := leaseKey(unit)
+	w.mu.Lock()
+	l, ok := w.leases[key]
+	delete(w.leases, key)
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+	w.complete(ctx, l, err)
+	<-w.slots
+}
+
+func (w *Worker) complete(ctx context.Context, l *workerLease, unitErr error) {
+	req := &distributedpb.CompleteUnitRequest{
+		WorkerId: w.id,
+		Progress: &distributedpb.UnitProgress{LeaseId: l.id, Chunks: l.chunks, Bytes: l.bytes},
+	}
+	if unitErr != nil {
+		req.Error = unitErr.Error()
+	}
+	// Report the unit even if the scan was cancelled, so the coordinator
+	// doesn't wait for the lease to expire.
+	if _, err := w.client.CompleteUnit(context.WithoutCancel(ctx), req); err != nil {
+		ctx.Logger().Error(err, "could not report completed unit to coordinator")
+	}
+}
+
+func leaseKey(unit sources.SourceUnit) string {
+	id, kind := unit.SourceUnitID()
+	return string(kind) + ":" + id
+}
+
+// Print queues a finding to be reported to the coordinator.
+func (w *Worker) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	w.mu.Lock()
+	w.findings = append(w.findings, ToFinding(r))
+	full := len(w.findings) >= findingsBatchSize
+	w.mu.Unlock()
+	if full {
+		return w.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush reports every queued finding to the coordinator.
+func (w *Worker) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	findings := w.findings
+	w.findings = nil
+	w.mu.Unlock()
+	if len(findings) == 0 {
+		return nil
+	}
+	_, err := w.client.ReportFindings(ctx, &distributedpb.ReportFindingsRequest{WorkerId: w.id, Findings: findings})
+	if err != nil {
+		return fmt.Errorf("could not report %d findings to coordinator: %w", len(findings), err)
+	}
+	return nil
+}
+
+// tokenCredentials authenticates requests to the coordinator with a shared
+// token.
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(stdcontext.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool { return t.secure }
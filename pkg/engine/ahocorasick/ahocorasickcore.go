@@ -2,6 +2,7 @@ package ahocorasick
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	ahocorasick "github.com/BobuSumisu/aho-corasick"
@@ -9,6 +10,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/custom_detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plugin"
 )
 
 // DetectorKey is used to identify a detector in the keywordsToDetectors map.
@@ -38,6 +40,19 @@ func (k DetectorKey) Loggable() map[string]any {
 // Type returns the detector type of the key.
 func (k DetectorKey) Type() detectorspb.DetectorType { return k.detectorType }
 
+// String returns the detector type of the key, followed by its version or
+// custom detector name if it has one.
+func (k DetectorKey) String() string {
+	s := k.detectorType.String()
+	if k.version > 0 {
+		s += fmt.Sprintf(" v%d", k.version)
+	}
+	if k.customDetectorName != "" {
+		s += " " + k.customDetectorName
+	}
+	return s
+}
+
 // spanCalculator is an interface that defines a method for calculating a match span
 // in the chunk data. This allows for different strategies to be used without changing the core logic.
 type spanCalculator interface {
@@ -62,6 +77,28 @@ func (e *EntireChunkSpanCalculator) calculateSpan(params spanCalculationParams)
 	return matchSpan{startOffset: 0, endOffset: int64(len(params.chunkData))}
 }
 
+// defaultOffsetRadius is how far either side of a keyword detectors search
+// unless they say otherwise.
+const defaultOffsetRadius int64 = 512
+
+// MaxMatchSize returns how many bytes of chunk data a secret found by the
+// detector may take up. Chunks need to overlap by at least this much for
+// secrets split between two of them to still be found.
+func MaxMatchSize(d detectors.Detector) int64 {
+	var size int64
+	if provider, ok := d.(detectors.MultiPartCredentialProvider); ok {
+		// The parts may be found on either side of the keyword.
+		size = 2 * provider.MaxCredentialSpan()
+	}
+	if provider, ok := d.(detectors.MaxSecretSizeProvider); ok {
+		size = max(size, provider.MaxSecretSize())
+	}
+	if size == 0 {
+		size = defaultOffsetRadius
+	}
+	return size
+}
+
 // adjustableSpanCalculator is a strategy that calculates match spans. It uses a default offset magnitude
 // or values provided by specific detectors to adjust the start and end indices of the span, allowing
 // for more granular control over the match.
@@ -124,7 +161,9 @@ type Core struct {
 	// some consuming code a little cleaner.)
 	keywordsToDetectors map[string][]DetectorKey
 	detectorsByKey      map[DetectorKey]detectors.Detector
-	spanCalculator      spanCalculator // Strategy for calculating match spans
+	// fileDetectors are the detectors that implement detectors.FileDetector.
+	fileDetectors  []DetectorKey
+	spanCalculator spanCalculator // Strategy for calculating match spans
 }
 
 // NewAhoCorasickCore allocates and initializes a new instance of AhoCorasickCore. It uses the
@@ -134,9 +173,13 @@ func NewAhoCorasickCore(allDetectors []detectors.Detector, opts ...CoreOption) *
 	keywordsToDetectors := make(map[string][]DetectorKey)
 	detectorsByKey := make(map[DetectorKey]detectors.Detector, len(allDetectors))
 	var keywords []string
+	var fileDetectors []DetectorKey
 	for _, d := range allDetectors {
 		key := CreateDetectorKey(d)
 		detectorsByKey[key] = d
+		if _, ok := d.(detectors.FileDetector); ok {
+			fileDetectors = append(fileDetectors, key)
+		}
 		for _, kw := range d.Keywords() {
 			kwLower := strings.ToLower(kw)
 			keywords = append(keywords, kwLower)
@@ -144,10 +187,10 @@ func NewAhoCorasickCore(allDetectors []detectors.Detector, opts ...CoreOption) *
 		}
 	}
 
-	const defaultOffsetRadius int64 = 512
 	core := &Core{
 		keywordsToDetectors: keywordsToDetectors,
 		detectorsByKey:      detectorsByKey,
+		fileDetectors:       fileDetectors,
 		prefilter:           *ahocorasick.NewTrieBuilder().AddStrings(keywords).Build(),
 		spanCalculator:      newAdjustableSpanCalculator(defaultOffsetRadius), // Default span calculator
 	}
@@ -276,8 +319,29 @@ func (ac *Core) FindDetectorMatches(chunkData []byte) []*DetectorMatch {
 	return uniqueDetectors
 }
 
+// FindFileDetectorMatches returns a match of the whole of chunkData for each
+// detector that implements detectors.FileDetector and detects the file at
+// path, which is the file chunkData is from.
+func (ac *Core) FindFileDetectorMatches(path string, chunkData []byte) []*DetectorMatch {
+	if path == "" {
+		return nil
+	}
+	var matches []*DetectorMatch
+	for _, k := range ac.fileDetectors {
+		detector := ac.detectorsByKey[k]
+		if !detector.(detectors.FileDetector).DetectsFile(path) {
+			continue
+		}
+		match := &DetectorMatch{Key: k, Detector: detector}
+		match.addMatchSpan(matchSpan{startOffset: 0, endOffset: int64(len(chunkData))})
+		match.extractMatches(chunkData)
+		matches = append(matches, match)
+	}
+	return matches
+}
+
 // CreateDetectorKey creates a unique key for each detector from its type, version, and, for
-// custom regex detectors, its name.
+// custom regex and plugin detectors, its name.
 func CreateDetectorKey(d detectors.Detector) DetectorKey {
 	detectorType := d.Type()
 	var version int
@@ -285,8 +349,11 @@ func CreateDetectorKey(d detectors.Detector) DetectorKey {
 		version = v.Version()
 	}
 	var customDetectorName string
-	if r, ok := d.(*custom_detectors.CustomRegexWebhook); ok {
+	switch r := d.(type) {
+	case *custom_detectors.CustomRegexWebhook:
 		customDetectorName = r.GetName()
+	case *plugin.Detector:
+		customDetectorName = r.Name()
 	}
 	return DetectorKey{detectorType: detectorType, version: version, customDetectorName: customDetectorName}
 }
@@ -133,6 +133,25 @@ func (testDetectorV6) Description() string { return "" }
 
 func (testDetectorV6) StartOffset() int64 { return 1 }
 
+var _ detectors.Detector = (*testFileDetector)(nil)
+var _ detectors.FileDetector = (*testFileDetector)(nil)
+
+type testFileDetector struct{}
+
+func (testFileDetector) FromData(context.Context, bool, []byte) ([]detectors.Result, error) {
+	return make([]detectors.Result, 0), nil
+}
+
+func (testFileDetector) Keywords() []string { return nil }
+
+func (testFileDetector) Type() detectorspb.DetectorType { return TestDetectorType }
+
+func (testFileDetector) Version() int { return 7 }
+
+func (testFileDetector) Description() string { return "" }
+
+func (testFileDetector) DetectsFile(path string) bool { return path == ".env" }
+
 var _ detectors.Detector = (*testDetectorV1)(nil)
 var _ detectors.Detector = (*testDetectorV2)(nil)
 var _ detectors.Versioner = (*testDetectorV1)(nil)
@@ -195,6 +214,21 @@ func TestAhoCorasickCore_NoDuplicateDetectorsMatched(t *testing.T) {
 	assert.ElementsMatch(t, allDetectors, matchingDetectors)
 }
 
+func TestAhoCorasickCore_FindFileDetectorMatches(t *testing.T) {
+	ac := NewAhoCorasickCore([]detectors.Detector{testDetectorV1{}, testFileDetector{}})
+	data := []byte("TOKEN=xyz\nOTHER=def\n")
+
+	assert.Empty(t, ac.FindDetectorMatches(data))
+	assert.Empty(t, ac.FindFileDetectorMatches("main.go", data))
+	assert.Empty(t, ac.FindFileDetectorMatches("", data))
+
+	matches := ac.FindFileDetectorMatches(".env", data)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, testFileDetector{}, matches[0].Detector)
+		assert.Equal(t, [][]byte{data}, matches[0].Matches())
+	}
+}
+
 func TestFindDetectorMatches(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -436,3 +470,9 @@ func TestFindDetectorMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxMatchSize(t *testing.T) {
+	assert.Equal(t, int64(512), MaxMatchSize(testDetectorV3{}))
+	assert.Equal(t, int64(30), MaxMatchSize(testDetectorV4{}))
+	assert.Equal(t, int64(10), MaxMatchSize(testDetectorV5{}))
+}
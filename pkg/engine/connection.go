@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plugin"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/circleci"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/docker"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/elasticsearch"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/filesystem"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/gcs"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/github"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/gitlab"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/huggingface"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/jenkins"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/postman"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/s3"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/travisci"
+)
+
+// ParseSourceType parses a source type name such as "git", with or without its
+// SOURCE_TYPE_ prefix.
+func ParseSourceType(name string) (sourcespb.SourceType, bool) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SOURCE_TYPE_") {
+		name = "SOURCE_TYPE_" + name
+	}
+	kind, ok := sourcespb.SourceType_value[name]
+	return sourcespb.SourceType(kind), ok
+}
+
+// SourceTypeName returns the name of a source type as accepted by
+// ParseSourceType, such as "git".
+func SourceTypeName(kind sourcespb.SourceType) string {
+	return strings.ToLower(strings.TrimPrefix(kind.String(), "SOURCE_TYPE_"))
+}
+
+// ParseConnection decodes the JSON connection of a source of the given type
+// into the message returned by NewConnection. Empty data configures the source
+// with its defaults.
+func ParseConnection(kind sourcespb.SourceType, data []byte) (proto.Message, error) {
+	connection := NewConnection(kind)
+	if connection == nil {
+		return nil, fmt.Errorf("%s sources can't be scanned from a connection", SourceTypeName(kind))
+	}
+	if len(data) > 0 {
+		if err := protojson.Unmarshal(data, connection); err != nil {
+			return nil, fmt.Errorf("invalid %s connection: %w", SourceTypeName(kind), err)
+		}
+	}
+	return connection, nil
+}
+
+// NewConnection returns an empty connection message for sources of the given
+// type, or nil if they can't be scanned with ScanConnection.
+func NewConnection(kind sourcespb.SourceType) proto.Message {
+	switch kind {
+	case git.SourceType:
+		return &sourcespb.Git{}
+	case github.SourceType:
+		return &sourcespb.GitHub{}
+	case gitlab.SourceType:
+		return &sourcespb.GitLab{}
+	case filesystem.SourceType:
+		return &sourcespb.Filesystem{}
+	case s3.SourceType:
+		return &sourcespb.S3{}
+	case gcs.SourceType:
+		return &sourcespb.GCS{}
+	case circleci.SourceType:
+		return &sourcespb.CircleCI{}
+	case travisci.SourceType:
+		return &sourcespb.TravisCI{}
+	case docker.SourceType:
+		return &sourcespb.Docker{}
+	case postman.SourceType:
+		return &sourcespb.Postman{}
+	case elasticsearch.SourceType:
+		return &sourcespb.Elasticsearch{}
+	case jenkins.SourceType:
+		return &sourcespb.Jenkins{}
+	case huggingface.SourceType:
+		return &sourcespb.Huggingface{}
+	case plugin.SourceType:
+		return &sourcespb.Plugin{}
+	default:
+		return nil
+	}
+}
+
+// ScanConnection scans the source configured by connection, which must be one
+// of the messages returned by NewConnection. Unlike the other Scan methods, it
+// returns a reference to the running job so callers can follow its progress.
+func (e *Engine) ScanConnection(ctx context.Context, sourceName string, connection proto.Message) (sources.JobProgressRef, error) {
+	source, configure, err := newConnectionSource(connection)
+	if err != nil {
+		return sources.JobProgressRef{}, err
+	}
+	if postmanSource, ok := source.(*postman.Source); ok {
+		keywords := make(map[string]struct{})
+		for key := range e.ahoCorasickCore.KeywordsToDetectors() {
+			keywords[key] = struct{}{}
+		}
+		postmanSource.DetectorKeywords = keywords
+	}
+
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return sources.JobProgressRef{}, fmt.Errorf("could not marshal %s connection: %w", source.Type(), err)
+	}
+
+	sourceID, jobID, _ := e.sourceManager.GetIDs(ctx, sourceName, source.Type())
+	if err := source.Init(ctx, sourceName, jobID, sourceID, true, &conn, e.concurrency); err != nil {
+		return sources.JobProgressRef{}, err
+	}
+	if configure != nil {
+		configure()
+	}
+	return e.sourceManager.Run(ctx, sourceName, source)
+}
+
+// ConnectionCheck is the outcome of ValidateConnection.
+type ConnectionCheck struct {
+	// Errors are the problems found with the connection, its credentials, or
+	// what it points at.
+	Errors []error
+	// Units is the number of units, such as repositories or files, the
+	// source would scan. It is -1 if the units weren't counted.
+	Units int
+}
+
+// ValidateConnection checks the source configured by connection without
+// scanning it. The source is initialized, which catches invalid settings and
+// credentials, and sources that implement sources.Validator probe what they
+// would scan. When estimate is set, the units of sources that implement
+// sources.SourceUnitEnumerator are counted.
+func ValidateConnection(ctx context.Context, sourceName string, connection proto.Message, estimate bool) ConnectionCheck {
+	check := ConnectionCheck{Units: -1}
+	source, uriUnits, errs := initCheckedSource(ctx, sourceName, connection)
+	check.Errors = errs
+	if source == nil {
+		return check
+	}
+
+	if validator, ok := source.(sources.Validator); ok {
+		check.Errors = append(check.Errors, validator.Validate(ctx)...)
+	}
+	if enumerator, ok := source.(sources.SourceUnitEnumerator); ok && estimate {
+		counter := &unitCounter{}
+		if err := enumerator.Enumerate(ctx, counter); err != nil {
+			counter.errs = append(counter.errs, err)
+		}
+		check.Units = uriUnits + counter.units
+		check.Errors = append(check.Errors, counter.errs...)
+	}
+	return check
+}
+
+// CheckConnectionHealth initializes the source configured by connection and
+// runs its HealthCheck, which checks its credentials and connectivity without
+// scanning it.
+func CheckConnectionHealth(ctx context.Context, sourceName string, connection proto.Message) error {
+	source, _, errs := initCheckedSource(ctx, sourceName, connection)
+	if source != nil {
+		if err := source.HealthCheck(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// initCheckedSource initializes the source configured by connection to be
+// checked rather than scanned. The repository of a git URI is probed instead
+// of cloned, and counted in uriUnits. The source is nil if it couldn't be
+// initialized.
+func initCheckedSource(ctx context.Context, sourceName string, connection proto.Message) (source sources.Source, uriUnits int, errs []error) {
+	switch c := connection.(type) {
+	case *sourcespb.Git:
+		// Initializing a git source clones the repository of its URI, which is
+		// only probed here.
+		if uri := c.GetUri(); uri != "" {
+			if err := git.PingURI(ctx, uri); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", uri, err))
+			}
+			c = proto.Clone(c).(*sourcespb.Git)
+			c.Uri = ""
+			connection = c
+			uriUnits = 1
+		}
+	case *sourcespb.Filesystem:
+		// Watched paths would be enumerated until ctx is done.
+		if c.GetWatch() {
+			c = proto.Clone(c).(*sourcespb.Filesystem)
+			c.Watch = false
+			connection = c
+		}
+	}
+
+	source, configure, err := newConnectionSource(connection)
+	if err != nil {
+		return nil, uriUnits, append(errs, err)
+	}
+	var conn anypb.Any
+	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
+		return nil, uriUnits, append(errs, fmt.Errorf("could not marshal %s connection: %w", source.Type(), err))
+	}
+	if err := source.Init(ctx, sourceName, 0, 0, false, &conn, runtime.NumCPU()); err != nil {
+		return nil, uriUnits, append(errs, err)
+	}
+	if configure != nil {
+		configure()
+	}
+	return source, uriUnits, errs
+}
+
+// unitCounter is a sources.UnitReporter that counts the units it is given.
+type unitCounter struct {
+	units int
+	errs  []error
+}
+
+func (c *unitCounter) UnitOk(context.Context, sources.SourceUnit) error {
+	c.units++
+	return nil
+}
+
+func (c *unitCounter) UnitErr(_ context.Context, err error) error {
+	c.errs = append(c.errs, err)
+	return nil
+}
+
+// newConnectionSource returns an uninitialized source for connection. The
+// returned configure func, if any, is to be called after the source is
+// initialized.
+func newConnectionSource(connection proto.Message) (sources.Source, func(), error) {
+	switch c := connection.(type) {
+	case *sourcespb.Git:
+		return &git.Source{}, nil, nil
+	case *sourcespb.GitHub:
+		filter, err := common.FilterFromFiles("", "", sources.FilterOptions(c.GetFilter())...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid github filter: %w", err)
+		}
+		githubSource := &github.Source{}
+		configure := func() {
+			githubSource.WithScanOptions(git.NewScanOptions(git.ScanOptionFilter(filter), git.ScanOptionLogOptions(&gogit.LogOptions{})))
+		}
+		return githubSource, configure, nil
+	case *sourcespb.GitLab:
+		filter, err := common.FilterFromFiles("", "", sources.FilterOptions(c.GetFilter())...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gitlab filter: %w", err)
+		}
+		gitlabSource := &gitlab.Source{}
+		configure := func() {
+			gitlabSource.WithScanOptions(git.NewScanOptions(git.ScanOptionFilter(filter), git.ScanOptionLogOptions(&gogit.LogOptions{})))
+		}
+		return gitlabSource, configure, nil
+	case *sourcespb.Filesystem:
+		return &filesystem.Source{}, nil, nil
+	case *sourcespb.S3:
+		return &s3.Source{}, nil, nil
+	case *sourcespb.GCS:
+		return &gcs.Source{}, nil, nil
+	case *sourcespb.CircleCI:
+		return &circleci.Source{}, nil, nil
+	case *sourcespb.TravisCI:
+		return &travisci.Source{}, nil, nil
+	case *sourcespb.Docker:
+		return &docker.Source{}, nil, nil
+	case *sourcespb.Postman:
+		return &postman.Source{}, nil, nil
+	case *sourcespb.Elasticsearch:
+		return &elasticsearch.Source{}, nil, nil
+	case *sourcespb.Jenkins:
+		return &jenkins.Source{}, nil, nil
+	case *sourcespb.Huggingface:
+		return &huggingface.Source{}, nil, nil
+	case *sourcespb.Plugin:
+		return &plugin.Source{}, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported connection type %T", connection)
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestValidateConnection(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content"), 0o644))
+	}
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	check := ValidateConnection(ctx, "fs", &sourcespb.Filesystem{Paths: []string{dir}, Watch: true}, true)
+	assert.Empty(t, check.Errors)
+	assert.Equal(t, 2, check.Units)
+
+	check = ValidateConnection(ctx, "fs", &sourcespb.Filesystem{Paths: []string{dir, missing}}, false)
+	assert.NotEmpty(t, check.Errors)
+	assert.Equal(t, -1, check.Units)
+
+	out, err := exec.Command("git", "init", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	connection := &sourcespb.Git{Uri: "file://" + dir, Directories: []string{dir}}
+	check = ValidateConnection(ctx, "git", connection, true)
+	assert.Empty(t, check.Errors)
+	assert.Equal(t, 2, check.Units)
+	assert.NotEmpty(t, connection.Uri, "the connection shouldn't be modified")
+
+	check = ValidateConnection(ctx, "git", &sourcespb.Git{Uri: "file://" + missing}, true)
+	require.Len(t, check.Errors, 1)
+	assert.ErrorContains(t, check.Errors[0], missing)
+}
+
+func TestCheckConnectionHealth(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	assert.NoError(t, CheckConnectionHealth(ctx, "fs", &sourcespb.Filesystem{Paths: []string{dir}}))
+	assert.ErrorContains(t, CheckConnectionHealth(ctx, "fs", &sourcespb.Filesystem{Paths: []string{dir, missing}}), missing)
+
+	out, err := exec.Command("git", "init", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.NoError(t, CheckConnectionHealth(ctx, "git", &sourcespb.Git{Uri: "file://" + dir}))
+	assert.ErrorContains(t, CheckConnectionHealth(ctx, "git", &sourcespb.Git{Uri: "file://" + missing}), missing)
+}
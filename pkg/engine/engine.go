@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"maps"
 	"runtime"
+	"runtime/debug"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,23 +17,49 @@ import (
 	"github.com/adrg/strutil"
 	"github.com/adrg/strutil/metrics"
 	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/credfile"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors/urlpassword"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/engine/ahocorasick"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/iac"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/log"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/mobileconfig"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ocr"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reachability"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/scoring"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tracing"
 )
 
-const detectionTimeout = 10 * time.Second
+// defaultDetectorTimeout bounds each run of a detector on a chunk, unless
+// Config.DetectorTimeout is set.
+const defaultDetectorTimeout = 10 * time.Second
+
+// detectorGrace is how long a detector has to return once its timeout is
+// reached, before it's abandoned.
+const detectorGrace = time.Second
+
+var (
+	errDetectorTimeout = errors.New("detector didn't return after its timeout")
+	errDetectorPanic   = errors.New("detector panicked")
+)
 
 var errOverlap = errors.New(
 	"More than one detector has found this result. For your safety, verification has been disabled." +
@@ -43,6 +73,10 @@ type Metrics struct {
 	VerifiedSecretsFound   uint64
 	UnverifiedSecretsFound uint64
 	AvgDetectorTime        map[string]time.Duration
+	// DetectorTimeouts and DetectorPanics count the chunks each detector was
+	// abandoned on because it didn't return after its timeout, or panicked.
+	DetectorTimeouts map[string]uint64
+	DetectorPanics   map[string]uint64
 
 	scanStartTime time.Time
 	ScanDuration  time.Duration
@@ -88,6 +122,50 @@ func (p *PrinterDispatcher) Dispatch(ctx context.Context, result detectors.Resul
 	return p.printer.Print(ctx, &result)
 }
 
+// Flush flushes the underlying printer if it buffers its output.
+func (p *PrinterDispatcher) Flush(ctx context.Context) error {
+	if f, ok := p.printer.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// MultiDispatcher sends every result to each of its dispatchers in order.
+// It allows results to be printed and delivered to additional sinks, such as
+// reports, in the same scan.
+type MultiDispatcher []ResultsDispatcher
+
+// Dispatch sends the result to all dispatchers, returning the combined errors.
+func (m MultiDispatcher) Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error {
+	var errs []error
+	for _, d := range m {
+		if err := d.Dispatch(ctx, result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush flushes all dispatchers that buffer their output.
+func (m MultiDispatcher) Flush(ctx context.Context) error {
+	var errs []error
+	for _, d := range m {
+		if f, ok := d.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flusher is an optional interface that a Printer or ResultsDispatcher can
+// implement when it buffers results and needs to emit them once all results
+// have been dispatched, e.g. formats that produce a single document.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
 // Config used to configure the engine.
 type Config struct {
 	// Number of concurrent scanner workers,
@@ -144,6 +222,65 @@ type Config struct {
 
 	// VerificationOverlapWorkerMultiplier is used to determine the number of verification overlap workers to spawn.
 	VerificationOverlapWorkerMultiplier int
+
+	// DetectorTimeout bounds each run of a detector on a chunk, including
+	// verification. It defaults to 10 seconds.
+	DetectorTimeout time.Duration
+
+	// ChunkSize is the size of the chunks sources split data into. It defaults
+	// to sources.ChunkSize.
+	ChunkSize int
+	// ChunkOverlap is how many bytes consecutive chunks share, so secrets
+	// spanning a chunk boundary are still found. It defaults to
+	// sources.PeekSize, and is raised to fit the largest secret the detectors
+	// can find.
+	ChunkOverlap int
+
+	// ContextLines is how many lines before and after the line of a secret
+	// are captured in its result. No context is captured if it is zero.
+	ContextLines int
+
+	// Classify tags chunks with the categories of files they come from, such
+	// as tests and lockfiles, which are set on the results found in them.
+	Classify bool
+	// SkipCategories are the categories of chunks that aren't scanned. Setting
+	// it classifies chunks.
+	SkipCategories []classify.Category
+
+	// Scorer scores the secrets detectors find before they are verified.
+	// Secrets scoring below ScoreThreshold are dropped without being
+	// verified or reported.
+	Scorer         scoring.Scorer
+	ScoreThreshold float64
+
+	// Incremental is the record of earlier scans the sources consult to only
+	// scan what changed since, and record what they scanned in. Incremental
+	// scanning is off if it is nil.
+	Incremental *incremental.State
+
+	// RepoTimeout is the maximum time spent scanning each git repository whose
+	// scan options don't set one. Repositories have no time limit if it is
+	// zero.
+	RepoTimeout time.Duration
+
+	// OCR recognizes the text of PNG and JPEG images, which are skipped if it
+	// is nil.
+	OCR ocr.Recognizer
+	// QRCodes decodes the QR codes of PNG and JPEG images, scanning their
+	// payloads.
+	QRCodes bool
+	// ColumnarMaxRows is the maximum number of rows read from columnar data
+	// files. It defaults to 10,000.
+	ColumnarMaxRows int
+
+	// VerifyURLPasswords verifies the passwords found in URLs by logging into
+	// their hosts, which isn't done by default.
+	VerifyURLPasswords bool
+
+	// RedactLogs registers the raw secrets of results with the log package
+	// as soon as they are found, so they are scrubbed from every log entry
+	// written afterwards.
+	RedactLogs bool
 }
 
 // Engine represents the core scanning engine responsible for detecting secrets in input data.
@@ -173,6 +310,29 @@ type Engine struct {
 	// By default, the engine will only scan a subset of the chunk if a detector matches the chunk.
 	// If this flag is set to true, the engine will scan the entire chunk.
 	scanEntireChunk bool
+	// contextLines is how many lines around a secret are captured in its result.
+	contextLines int
+	// classify and skipCategories control whether chunks are classified,
+	// and which of their categories aren't scanned.
+	classify       bool
+	skipCategories []classify.Category
+	// scorer scores results before they are verified, if it is set.
+	scorer         scoring.Scorer
+	scoreThreshold float64
+	// redactLogs registers the secrets of results with the log redactor.
+	redactLogs bool
+	// incremental is the state of incremental scanning of the sources.
+	incremental *incremental.State
+	// repoTimeout bounds the scan of each git repository.
+	repoTimeout time.Duration
+	// ocr recognizes the text of images, if it is set.
+	ocr ocr.Recognizer
+	// qrCodes decodes the QR codes of images.
+	qrCodes bool
+	// columnarMaxRows limits the rows read from columnar data files.
+	columnarMaxRows int
+	// verifyURLPasswords verifies the passwords found in URLs.
+	verifyURLPasswords bool
 
 	// ahoCorasickHandler manages the Aho-Corasick trie and related keyword lookups.
 	ahoCorasickCore *ahocorasick.Core
@@ -211,6 +371,8 @@ type Engine struct {
 	notificationWorkerMultiplier int
 	// verificationOverlapWorkerMultiplier is used to calculate the number of verification overlap workers.
 	verificationOverlapWorkerMultiplier int
+	// detectorTimeout bounds each run of a detector on a chunk.
+	detectorTimeout time.Duration
 }
 
 // NewEngine creates a new Engine instance with the provided configuration.
@@ -228,10 +390,23 @@ func NewEngine(ctx context.Context, cfg *Config) (*Engine, error) {
 		verificationOverlap:                 cfg.VerificationOverlap,
 		sourceManager:                       cfg.SourceManager,
 		scanEntireChunk:                     cfg.ShouldScanEntireChunk,
+		contextLines:                        cfg.ContextLines,
+		classify:                            cfg.Classify || len(cfg.SkipCategories) > 0,
+		skipCategories:                      cfg.SkipCategories,
+		scorer:                              cfg.Scorer,
+		scoreThreshold:                      cfg.ScoreThreshold,
+		redactLogs:                          cfg.RedactLogs,
+		incremental:                         cfg.Incremental,
+		repoTimeout:                         cfg.RepoTimeout,
+		ocr:                                 cfg.OCR,
+		qrCodes:                             cfg.QRCodes,
+		columnarMaxRows:                     cfg.ColumnarMaxRows,
+		verifyURLPasswords:                  cfg.VerifyURLPasswords,
 		detectorVerificationOverrides:       cfg.DetectorVerificationOverrides,
 		detectorWorkerMultiplier:            cfg.DetectorWorkerMultiplier,
 		notificationWorkerMultiplier:        cfg.NotificationWorkerMultiplier,
 		verificationOverlapWorkerMultiplier: cfg.VerificationOverlapWorkerMultiplier,
+		detectorTimeout:                     cfg.DetectorTimeout,
 	}
 	if engine.sourceManager == nil {
 		return nil, fmt.Errorf("source manager is required")
@@ -292,6 +467,11 @@ func NewEngine(ctx context.Context, cfg *Config) (*Engine, error) {
 	}
 	engine.applyFilters(filters...)
 
+	if err := engine.setChunkSizes(ctx, cfg.ChunkSize, cfg.ChunkOverlap); err != nil {
+		return nil, err
+	}
+	engine.sourceManager.SetSourceContext(engine.sourceContext)
+
 	if results := cfg.Results; len(results) > 0 {
 		_, ok := results["verified"]
 		engine.notifyVerifiedResults = ok
@@ -338,6 +518,10 @@ func (e *Engine) setDefaults(ctx context.Context) {
 		e.verificationOverlapWorkerMultiplier = 1
 	}
 
+	if e.detectorTimeout <= 0 {
+		e.detectorTimeout = defaultDetectorTimeout
+	}
+
 	// Default decoders handle common encoding formats.
 	if len(e.decoders) == 0 {
 		e.decoders = decoders.DefaultDecoders()
@@ -358,6 +542,55 @@ func (e *Engine) setDefaults(ctx context.Context) {
 	ctx.Logger().V(4).Info("default engine options set")
 }
 
+// setChunkSizes configures how the sources of the source manager split data
+// into chunks. The overlap is raised to fit the largest secret the detectors
+// can find.
+func (e *Engine) setChunkSizes(ctx context.Context, chunkSize, overlap int) error {
+	if chunkSize < 0 {
+		return fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+	if overlap < 0 {
+		return fmt.Errorf("invalid chunk overlap %d", overlap)
+	}
+	if overlap == 0 {
+		overlap = sources.PeekSize
+	}
+
+	var needed int64
+	for _, d := range e.detectors {
+		needed = max(needed, ahocorasick.MaxMatchSize(d))
+	}
+	if int64(overlap) < needed {
+		ctx.Logger().V(2).Info("raising chunk overlap to fit the largest secrets", "overlap", overlap, "needed", needed)
+		overlap = int(needed)
+	}
+
+	e.sourceManager.SetChunkSizes(chunkSize, overlap)
+	return nil
+}
+
+// sourceContext scopes the settings of the engine for its sources to ctx, so
+// engines with different settings can run in the same process.
+func (e *Engine) sourceContext(ctx context.Context) context.Context {
+	ctx = incremental.WithState(ctx, e.incremental)
+	ctx = git.WithRepoTimeout(ctx, e.repoTimeout)
+	if e.ocr != nil {
+		ctx = handlers.WithOCR(ctx, e.ocr)
+	}
+	ctx = handlers.WithQRCodes(ctx, e.qrCodes)
+	return handlers.WithColumnarMaxRows(ctx, e.columnarMaxRows)
+}
+
+// detectorContext scopes the settings of the engine for its detectors to ctx,
+// like sourceContext does for its sources.
+func (e *Engine) detectorContext(ctx context.Context) context.Context {
+	return context.WithLogger(urlpassword.WithVerification(ctx, e.verifyURLPasswords), ctx.Logger())
+}
+
+// Incremental returns the state of incremental scanning the sources of the
+// engine record what they scanned in, or nil if it is off.
+func (e *Engine) Incremental() *incremental.State { return e.incremental }
+
 func buildDetectorSets(cfg *Config) (map[config.DetectorID]struct{}, map[config.DetectorID]struct{}, error) {
 	includeList, err := config.ParseDetectors(cfg.IncludeDetectors)
 	if err != nil {
@@ -384,6 +617,24 @@ func buildDetectorSets(cfg *Config) (map[config.DetectorID]struct{}, map[config.
 	return includeDetectorSet, excludeDetectorSet, nil
 }
 
+// SelectDetectors returns the detectors of cfg that its include and exclude
+// lists select, which are the detectors an engine created with cfg runs.
+func SelectDetectors(cfg Config) ([]detectors.Detector, error) {
+	includeDetectorSet, excludeDetectorSet, err := buildDetectorSets(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return filterDetectors(func(d detectors.Detector) bool {
+		if len(includeDetectorSet) > 0 {
+			if _, ok := getWithDetectorID(d, includeDetectorSet); !ok {
+				return false
+			}
+		}
+		_, excluded := getWithDetectorID(d, excludeDetectorSet)
+		return !excluded
+	}, cfg.Detectors), nil
+}
+
 func parseCustomVerifierEndpoints(endpoints map[string]string) (map[config.DetectorID][]string, error) {
 	if len(endpoints) == 0 {
 		return nil, nil
@@ -531,6 +782,10 @@ func (r *verificationOverlapTracker) increment() {
 
 const ignoreTag = "trufflehog:ignore"
 
+// Detectors returns the detectors the engine scans with, once the include
+// and exclude filters have been applied.
+func (e *Engine) Detectors() []detectors.Detector { return e.detectors }
+
 // HasFoundResults returns true if any results are found.
 func (e *Engine) HasFoundResults() bool {
 	return atomic.LoadUint32(&e.numFoundResults) > 0
@@ -555,6 +810,8 @@ func (e *Engine) GetMetrics() Metrics {
 	}
 
 	result.ScanDuration = e.metrics.getScanDuration()
+	result.DetectorTimeouts = maps.Clone(e.metrics.DetectorTimeouts)
+	result.DetectorPanics = maps.Clone(e.metrics.DetectorPanics)
 
 	return result
 }
@@ -633,11 +890,12 @@ func (e *Engine) startWorkers(ctx context.Context) {
 	e.startScannerWorkers(ctx)
 
 	// Detector workers apply keyword matching, regexes and API calls to detect secrets in chunks.
-	e.startDetectorWorkers(ctx)
+	detectorCtx := e.detectorContext(ctx)
+	e.startDetectorWorkers(detectorCtx)
 
 	// verificationOverlap workers handle verification of chunks that have been detected by multiple detectors.
 	// They ensure that verification is disabled for any secrets that have been detected by multiple detectors.
-	e.startVerificationOverlapWorkers(ctx)
+	e.startVerificationOverlapWorkers(detectorCtx)
 
 	// ResultsDispatcher workers communicate detected issues to the user or any downstream systems.
 	// We want 1/4th of the notifier workers as the number of scanner workers.
@@ -721,6 +979,12 @@ func (e *Engine) Finish(ctx context.Context) error {
 	close(e.results)    // Detector workers are done, close the results channel and call it a day.
 	e.WgNotifier.Wait() // Wait for the notifier workers to finish notifying results.
 
+	if f, ok := e.dispatcher.(Flusher); ok {
+		if flushErr := f.Flush(ctx); flushErr != nil {
+			err = errors.Join(err, fmt.Errorf("error flushing results: %w", flushErr))
+		}
+	}
+
 	e.metrics.ScanDuration = time.Since(e.metrics.scanStartTime)
 
 	return err
@@ -747,6 +1011,32 @@ type detectableChunk struct {
 	chunk    sources.Chunk
 	decoder  detectorspb.DecoderType
 	wgDoneFn func()
+	refs     *chunkRefs
+	// categories are the categories of the file the chunk comes from.
+	categories []classify.Category
+}
+
+// chunkRefs counts the detectable and verification overlap chunks in flight
+// for a chunk from the source manager, which releases the chunk from its
+// memory budget once none are left.
+type chunkRefs struct {
+	n       atomic.Int64
+	release func()
+}
+
+func (e *Engine) newChunkRefs(chunk *sources.Chunk) *chunkRefs {
+	refs := &chunkRefs{release: func() { e.sourceManager.ReleaseChunk(chunk) }}
+	// The scanner worker holds a reference until it has decoded the chunk.
+	refs.n.Store(1)
+	return refs
+}
+
+func (r *chunkRefs) add() { r.n.Add(1) }
+
+func (r *chunkRefs) done() {
+	if r.n.Add(-1) == 0 {
+		r.release()
+	}
 }
 
 // verificationOverlapChunk is a decoded chunk that has multiple detectors that match it.
@@ -757,67 +1047,109 @@ type verificationOverlapChunk struct {
 	decoder                     detectorspb.DecoderType
 	detectors                   []*ahocorasick.DetectorMatch
 	verificationOverlapWgDoneFn func()
+	refs                        *chunkRefs
+	categories                  []classify.Category
 }
 
 func (e *Engine) scannerWorker(ctx context.Context) {
 	var wgDetect sync.WaitGroup
 	var wgVerificationOverlap sync.WaitGroup
 
-	for chunk := range e.ChunksChan() {
-		startTime := time.Now()
-		sourceVerify := chunk.Verify
-		for _, decoder := range e.decoders {
-			decodeStart := time.Now()
-			decoded := decoder.FromChunk(chunk)
-			decodeTime := time.Since(decodeStart).Microseconds()
-			decodeLatency.WithLabelValues(decoder.Type().String(), chunk.SourceName).Observe(float64(decodeTime))
-
-			if decoded == nil {
-				ctx.Logger().V(5).Info("decoder not applicable for chunk", "decoder", decoder.Type().String(), "chunk", chunk)
-				continue
+	// Chunks come in the batches their sources emit them in, so the workers
+	// don't contend for a channel receive per chunk.
+	for batch := range e.sourceManager.ChunkBatches() {
+		for _, chunk := range batch {
+			startTime := time.Now()
+			_, span := tracing.StartSpan(ctx, "engine.chunk",
+				attribute.String("source_name", chunk.SourceName),
+				attribute.Int("bytes", len(chunk.Data)),
+			)
+			sourceVerify := chunk.Verify
+			refs := e.newChunkRefs(chunk)
+			decoders := e.decoders
+			var categories []classify.Category
+			if e.classify {
+				categories = classify.Classify(classify.File(chunk.SourceMetadata), chunk.Data)
+				if skipsCategory(e.skipCategories, categories) {
+					// The chunk still counts as scanned, but nothing is
+					// searched for in it.
+					ctx.Logger().V(5).Info("skipping chunk", "categories", categories, "chunk", chunk)
+					decoders = nil
+				}
 			}
+			for _, decoder := range decoders {
+				decodeStart := time.Now()
+				decoded := decoder.FromChunk(chunk)
+				decodeTime := time.Since(decodeStart).Microseconds()
+				decodeLatency.WithLabelValues(decoder.Type().String(), chunk.SourceName).Observe(float64(decodeTime))
+
+				if decoded == nil {
+					ctx.Logger().V(5).Info("decoder not applicable for chunk", "decoder", decoder.Type().String(), "chunk", chunk)
+					continue
+				}
 
-			matchingDetectors := e.ahoCorasickCore.FindDetectorMatches(decoded.Chunk.Data)
-			if len(matchingDetectors) > 1 && !e.verificationOverlap {
-				wgVerificationOverlap.Add(1)
-				e.verificationOverlapChunksChan <- verificationOverlapChunk{
-					chunk:                       *decoded.Chunk,
-					detectors:                   matchingDetectors,
-					decoder:                     decoded.DecoderType,
-					verificationOverlapWgDoneFn: wgVerificationOverlap.Done,
+				matchingDetectors := e.ahoCorasickCore.FindDetectorMatches(decoded.Chunk.Data)
+				// File detectors don't verify what they find, so they're left
+				// out of the verification overlap check, which would otherwise
+				// keep the secrets of credential files from being verified.
+				fileDetectors := e.ahoCorasickCore.FindFileDetectorMatches(classify.File(decoded.Chunk.SourceMetadata), decoded.Chunk.Data)
+				if len(categories) > 0 {
+					skipped := func(m *ahocorasick.DetectorMatch) bool {
+						skipper, ok := m.Detector.(detectors.CategorySkipper)
+						return ok && skipsCategory(skipper.SkipCategories(), categories)
+					}
+					matchingDetectors = slices.DeleteFunc(matchingDetectors, skipped)
+					fileDetectors = slices.DeleteFunc(fileDetectors, skipped)
+				}
+				if len(matchingDetectors) > 1 && !e.verificationOverlap {
+					wgVerificationOverlap.Add(1)
+					refs.add()
+					e.verificationOverlapChunksChan <- verificationOverlapChunk{
+						chunk:                       *decoded.Chunk,
+						detectors:                   matchingDetectors,
+						decoder:                     decoded.DecoderType,
+						verificationOverlapWgDoneFn: wgVerificationOverlap.Done,
+						refs:                        refs,
+						categories:                  categories,
+					}
+					matchingDetectors = nil
 				}
-				continue
-			}
 
-			for _, detector := range matchingDetectors {
-				decoded.Chunk.Verify = e.shouldVerifyChunk(sourceVerify, detector, e.detectorVerificationOverrides)
-				wgDetect.Add(1)
-				e.detectableChunksChan <- detectableChunk{
-					chunk:    *decoded.Chunk,
-					detector: detector,
-					decoder:  decoded.DecoderType,
-					wgDoneFn: wgDetect.Done,
+				for _, detector := range append(matchingDetectors, fileDetectors...) {
+					decoded.Chunk.Verify = e.shouldVerifyChunk(sourceVerify, detector, e.detectorVerificationOverrides)
+					wgDetect.Add(1)
+					refs.add()
+					e.detectableChunksChan <- detectableChunk{
+						chunk:      *decoded.Chunk,
+						detector:   detector,
+						decoder:    decoded.DecoderType,
+						wgDoneFn:   wgDetect.Done,
+						refs:       refs,
+						categories: categories,
+					}
 				}
 			}
+			refs.done()
+
+			dataSize := float64(len(chunk.Data))
+
+			scanBytesPerChunk.Observe(dataSize)
+			jobBytesScanned.WithLabelValues(
+				strconv.Itoa(int(chunk.JobID)),
+				chunk.SourceType.String(),
+				chunk.SourceName,
+			).Add(dataSize)
+			chunksScannedLatency.Observe(float64(time.Since(startTime).Microseconds()))
+			jobChunksScanned.WithLabelValues(
+				strconv.Itoa(int(chunk.JobID)),
+				chunk.SourceType.String(),
+				chunk.SourceName,
+			).Inc()
+
+			atomic.AddUint64(&e.metrics.ChunksScanned, 1)
+			atomic.AddUint64(&e.metrics.BytesScanned, uint64(dataSize))
+			span.End()
 		}
-
-		dataSize := float64(len(chunk.Data))
-
-		scanBytesPerChunk.Observe(dataSize)
-		jobBytesScanned.WithLabelValues(
-			strconv.Itoa(int(chunk.JobID)),
-			chunk.SourceType.String(),
-			chunk.SourceName,
-		).Add(dataSize)
-		chunksScannedLatency.Observe(float64(time.Since(startTime).Microseconds()))
-		jobChunksScanned.WithLabelValues(
-			strconv.Itoa(int(chunk.JobID)),
-			chunk.SourceType.String(),
-			chunk.SourceName,
-		).Inc()
-
-		atomic.AddUint64(&e.metrics.ChunksScanned, 1)
-		atomic.AddUint64(&e.metrics.BytesScanned, uint64(dataSize))
 	}
 
 	wgVerificationOverlap.Wait()
@@ -921,9 +1253,7 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 			// DO NOT VERIFY at this stage of the pipeline.
 			matchedBytes := detector.Matches()
 			for _, match := range matchedBytes {
-				ctx, cancel := context.WithTimeout(ctx, time.Second*2)
-				results, err := detector.FromData(ctx, false, match)
-				cancel()
+				results, err := e.fromData(ctx, detector, false, match, chunk.refs, 2*time.Second)
 				if err != nil {
 					ctx.Logger().V(2).Error(
 						err, "error finding results in chunk during verification overlap",
@@ -974,10 +1304,11 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 						e.processResult(
 							ctx,
 							detectableChunk{
-								chunk:    chunk.chunk,
-								detector: detector,
-								decoder:  chunk.decoder,
-								wgDoneFn: wgDetect.Done,
+								chunk:      chunk.chunk,
+								detector:   detector,
+								decoder:    chunk.decoder,
+								wgDoneFn:   wgDetect.Done,
+								categories: chunk.categories,
 							},
 							res,
 							isFalsePositive,
@@ -995,12 +1326,15 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 
 		for _, detector := range detectorKeysWithResults {
 			wgDetect.Add(1)
+			chunk.refs.add()
 			chunk.chunk.Verify = e.shouldVerifyChunk(chunk.chunk.Verify, detector, e.detectorVerificationOverrides)
 			e.detectableChunksChan <- detectableChunk{
-				chunk:    chunk.chunk,
-				detector: detector,
-				decoder:  chunk.decoder,
-				wgDoneFn: wgDetect.Done,
+				chunk:      chunk.chunk,
+				detector:   detector,
+				decoder:    chunk.decoder,
+				wgDoneFn:   wgDetect.Done,
+				refs:       chunk.refs,
+				categories: chunk.categories,
 			}
 		}
 
@@ -1013,6 +1347,7 @@ func (e *Engine) verificationOverlapWorker(ctx context.Context) {
 		}
 
 		chunk.verificationOverlapWgDoneFn()
+		chunk.refs.done()
 	}
 
 	wgDetect.Wait()
@@ -1031,10 +1366,30 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	if e.printAvgDetectorTime {
 		start = time.Now()
 	}
+	// The chunk is done even if processing its results panics, or the
+	// detection would never finish.
+	defer func() {
+		data.wgDoneFn()
+		data.refs.done()
+	}()
 	defer common.Recover(ctx)
 
 	ctx = context.WithValue(ctx, "detector", data.detector.Key.Loggable())
 
+	// Detectors verify the secrets they find, so a chunk that is verified
+	// is traced as the verify stage and the time is dominated by the
+	// detector's network calls.
+	stage := "engine.detect"
+	if data.chunk.Verify {
+		stage = "engine.verify"
+	}
+	ctx, span := tracing.StartSpan(ctx, stage,
+		attribute.String("detector", data.detector.Type().String()),
+		attribute.String("decoder", data.decoder.String()),
+		attribute.String("source_name", data.chunk.SourceName),
+	)
+	defer span.End()
+
 	isFalsePositive := detectors.GetFalsePositiveCheck(data.detector)
 
 	var matchCount int
@@ -1048,13 +1403,10 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 		matchCount++
 		detectBytesPerMatch.Observe(float64(len(matchBytes)))
 
-		ctx, cancel := context.WithTimeout(ctx, detectionTimeout)
-		t := time.AfterFunc(detectionTimeout+1*time.Second, func() {
-			ctx.Logger().Error(nil, "a detector ignored the context timeout")
-		})
-		results, err := data.detector.Detector.FromData(ctx, data.chunk.Verify, matchBytes)
-		t.Stop()
-		cancel()
+		// Secrets are scored before they are verified, so a scored chunk is
+		// verified in a second pass.
+		verify := data.chunk.Verify && e.scorer == nil
+		results, err := e.fromData(ctx, data.detector, verify, matchBytes, data.refs, e.detectorTimeout)
 		if err != nil {
 			ctx.Logger().Error(err, "error finding results in chunk")
 			continue
@@ -1092,6 +1444,9 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 		if data.chunk.SecretID == 0 {
 			results = e.filterResults(ctx, data.detector, results)
 		}
+		if e.scorer != nil && len(results) > 0 {
+			results = e.scoreResults(ctx, data, matchBytes, results)
+		}
 
 		for _, res := range results {
 			e.processResult(ctx, data, res, isFalsePositive)
@@ -1099,8 +1454,128 @@ func (e *Engine) detectChunk(ctx context.Context, data detectableChunk) {
 	}
 
 	matchesPerChunk.Observe(float64(matchCount))
+}
 
-	data.wgDoneFn()
+// fromData runs the detector on data, which is part of the chunk of refs,
+// with timeout. Detectors that panic, or ignore the timeout for longer than
+// detectorGrace, are recorded and return an error, and the ones that don't
+// return are abandoned, so the scan goes on without them.
+func (e *Engine) fromData(
+	ctx context.Context,
+	detector *ahocorasick.DetectorMatch,
+	verify bool,
+	data []byte,
+	refs *chunkRefs,
+	timeout time.Duration,
+) ([]detectors.Result, error) {
+	type detection struct {
+		results []detectors.Result
+		err     error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Buffered, as an abandoned detector still sends when it returns.
+	done := make(chan detection, 1)
+	// The detector holds its own reference to the chunk, so the data of an
+	// abandoned detector isn't released to be reused while it still reads it.
+	refs.add()
+	go func() {
+		defer refs.done()
+		defer func() {
+			if r := recover(); r != nil {
+				e.recordDetectorFailure(detector, errDetectorPanic)
+				ctx.Logger().Error(fmt.Errorf("%w: %v", errDetectorPanic, r), "skipping chunk for detector",
+					"stack", string(debug.Stack()))
+				done <- detection{err: fmt.Errorf("%w: %v", errDetectorPanic, r)}
+			}
+		}()
+		results, err := detector.Detector.FromData(ctx, verify, data)
+		done <- detection{results, err}
+	}()
+
+	t := time.NewTimer(timeout + detectorGrace)
+	defer t.Stop()
+	select {
+	case d := <-done:
+		return d.results, d.err
+	case <-t.C:
+		e.recordDetectorFailure(detector, errDetectorTimeout)
+		ctx.Logger().Error(errDetectorTimeout, "skipping chunk for detector", "timeout", timeout)
+		return nil, errDetectorTimeout
+	}
+}
+
+// recordDetectorFailure counts a chunk detector was abandoned on because of
+// err, errDetectorTimeout or errDetectorPanic.
+func (e *Engine) recordDetectorFailure(detector *ahocorasick.DetectorMatch, err error) {
+	name := detector.Key.String()
+	e.metrics.mu.Lock()
+	defer e.metrics.mu.Unlock()
+	switch {
+	case errors.Is(err, errDetectorTimeout):
+		if e.metrics.DetectorTimeouts == nil {
+			e.metrics.DetectorTimeouts = make(map[string]uint64)
+		}
+		e.metrics.DetectorTimeouts[name]++
+		detectorTimeouts.WithLabelValues(name).Inc()
+	case errors.Is(err, errDetectorPanic):
+		if e.metrics.DetectorPanics == nil {
+			e.metrics.DetectorPanics = make(map[string]uint64)
+		}
+		e.metrics.DetectorPanics[name]++
+		detectorPanics.WithLabelValues(name).Inc()
+	}
+}
+
+// scoreResults drops the unverified results found in match that score below
+// the score threshold, and verifies the others if the chunk is verified. If
+// scoring fails, every result is kept, so that an unavailable scorer doesn't
+// hide secrets.
+func (e *Engine) scoreResults(
+	ctx context.Context,
+	data detectableChunk,
+	match []byte,
+	results []detectors.Result,
+) []detectors.Result {
+	file := classify.File(data.chunk.SourceMetadata)
+	candidates := make([]scoring.Candidate, len(results))
+	for i, res := range results {
+		candidates[i] = scoring.Candidate{
+			Detector: res.DetectorType.String(),
+			Secret:   string(res.Raw),
+			SecretV2: string(res.RawV2),
+			Match:    string(match),
+			File:     file,
+		}
+	}
+	scores, err := e.scorer.Score(ctx, candidates)
+	if err != nil {
+		ctx.Logger().Error(err, "error scoring results, keeping them")
+	}
+
+	kept := make(map[string]struct{}, len(results))
+	for i, res := range results {
+		if err == nil && scores[i] < e.scoreThreshold {
+			ctx.Logger().V(3).Info("dropping result scoring below the threshold", "score", scores[i])
+			resultsScoredOut.Inc()
+			continue
+		}
+		kept[string(res.Raw)+string(res.RawV2)] = struct{}{}
+	}
+	dropped := func(res detectors.Result) bool {
+		_, ok := kept[string(res.Raw)+string(res.RawV2)]
+		return !ok
+	}
+	if len(kept) == 0 || !data.chunk.Verify {
+		return slices.DeleteFunc(results, dropped)
+	}
+
+	verified, err := e.fromData(ctx, data.detector, true, match, data.refs, e.detectorTimeout)
+	if err != nil {
+		ctx.Logger().Error(err, "error verifying scored results")
+		return nil
+	}
+	return slices.DeleteFunc(verified, dropped)
 }
 
 func (e *Engine) filterResults(
@@ -1129,12 +1604,28 @@ func (e *Engine) filterResults(
 	return results
 }
 
+// redactSecrets registers the raw secret of a result with the log redactor.
+// Multi-part secrets often store the ID in Raw and append the secret to form
+// RawV2, so the secret part is registered on its own as well.
+func redactSecrets(res detectors.Result) {
+	raw, rawV2 := strings.TrimSpace(string(res.Raw)), strings.TrimSpace(string(res.RawV2))
+	secrets := []string{raw, rawV2}
+	if rest, ok := strings.CutPrefix(rawV2, raw); ok && raw != "" {
+		secrets = append(secrets, strings.TrimSpace(rest))
+	}
+	log.RedactGlobally(secrets...)
+}
+
 func (e *Engine) processResult(
 	ctx context.Context,
 	data detectableChunk,
 	res detectors.Result,
 	isFalsePositive func(detectors.Result) (bool, string),
 ) {
+	if e.redactLogs {
+		redactSecrets(res)
+	}
+
 	ignoreLinePresent := false
 	if SupportsLineNumbers(data.chunk.SourceType) {
 		copyChunk := data.chunk
@@ -1155,7 +1646,26 @@ func (e *Engine) processResult(
 	}
 
 	secret := detectors.CopyMetadata(&data.chunk, res)
+	// The data of the chunk may be reused once it's released, before the
+	// result is dispatched.
+	secret.Data = bytes.Clone(secret.Data)
+	secret.Raw, secret.RawV2 = bytes.Clone(secret.Raw), bytes.Clone(secret.RawV2)
 	secret.DecoderType = data.decoder
+	secret.Categories = data.categories
+	secret.Reachability = reachability.Of(data.chunk.SourceMetadata)
+	switch file := classify.File(data.chunk.SourceMetadata); {
+	case classify.IsCredentialFile(file):
+		secret.Variable = credfile.Variable(data.chunk.Data, res.Raw)
+	case mobileconfig.IsConfigFile(file):
+		secret.Variable = mobileconfig.Variable(data.chunk.Data, res.Raw)
+	case iac.IsTemplateFile(file):
+		secret.Address, secret.Variable = iac.Locate(data.chunk.Data, res.Raw)
+	}
+
+	if suppressed(data.chunk.IgnoreFile, &secret) {
+		resultsSuppressed.Inc()
+		return
+	}
 
 	if !res.Verified && res.Raw != nil {
 		isFp, _ := isFalsePositive(res)
@@ -1165,6 +1675,25 @@ func (e *Engine) processResult(
 	e.results <- secret
 }
 
+// skipsCategory returns true if any of categories is skipped.
+func skipsCategory(skipped, categories []classify.Category) bool {
+	for _, c := range categories {
+		if slices.Contains(skipped, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressed returns true if the .trufflehogignore file of the result's chunk
+// suppresses its detector or the finding itself.
+func suppressed(ignore *ignorefile.File, result *detectors.ResultWithMetadata) bool {
+	if ignore.SuppressesDetector(result.DetectorType.String(), result.DetectorName) {
+		return true
+	}
+	return ignore.HasFingerprints() && ignore.SuppressesFingerprint(output.Fingerprint(result))
+}
+
 func (e *Engine) notifierWorker(ctx context.Context) {
 	for result := range e.ResultsChan() {
 		startTime := time.Now()
@@ -1199,13 +1728,24 @@ func (e *Engine) notifierWorker(ctx context.Context) {
 		}
 		e.dedupeCache.Add(key, result.DecoderType)
 
+		result.Fingerprint = output.Fingerprint(&result)
+		if e.contextLines > 0 {
+			result.Context = lineContext(result.Data, result.Raw, e.contextLines)
+		}
+
 		if result.Verified {
 			atomic.AddUint64(&e.metrics.VerifiedSecretsFound, 1)
 		} else {
 			atomic.AddUint64(&e.metrics.UnverifiedSecretsFound, 1)
 		}
 
-		if err := e.dispatcher.Dispatch(ctx, result); err != nil {
+		dispatchCtx, span := tracing.StartSpan(ctx, "engine.output",
+			attribute.String("detector", result.DetectorType.String()),
+			attribute.Bool("verified", result.Verified),
+		)
+		err := e.dispatcher.Dispatch(dispatchCtx, result)
+		tracing.End(span, err)
+		if err != nil {
 			ctx.Logger().Error(err, "error notifying result")
 		}
 
@@ -1284,6 +1824,9 @@ func FragmentFirstLineAndLink(chunk *sources.Chunk) (int64, *int64, string) {
 	case *source_metadatapb.MetaData_AzureRepos:
 		fragmentStart = &metadata.AzureRepos.Line
 		link = metadata.AzureRepos.Link
+	case *source_metadatapb.MetaData_Plugin:
+		// Plugin links are opaque, so only the line is updated.
+		fragmentStart = &metadata.Plugin.Line
 	default:
 		return 0, nil, ""
 	}
@@ -22,8 +22,10 @@ func (e *Engine) ScanGit(ctx context.Context, c sources.GitConfig) error {
 		ExcludeGlobs:     c.ExcludeGlobs,
 		IncludePathsFile: c.IncludePathsFile,
 		ExcludePathsFile: c.ExcludePathsFile,
+		Filter:           c.FilterRules,
 		MaxDepth:         int64(c.MaxDepth),
 		SkipBinaries:     c.SkipBinaries,
+		Remote:           c.Remote,
 	}
 	var conn anypb.Any
 	if err := anypb.MarshalFrom(&conn, connection, proto.MarshalOptions{}); err != nil {
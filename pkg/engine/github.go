@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"time"
+
 	gogit "github.com/go-git/go-git/v5"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -27,7 +30,12 @@ func (e *Engine) ScanGitHub(ctx context.Context, c sources.GithubConfig) error {
 		IncludeGistComments:        c.IncludeGistComments,
 		IncludeWikis:               c.IncludeWikis,
 		SkipBinaries:               c.SkipBinaries,
+		Tokens:                     c.Tokens,
+	}
+	if c.SkipArchived || len(c.ExcludeTopics) > 0 {
+		connection.Exclude = &sourcespb.RepoExclusions{Archived: c.SkipArchived, Topics: c.ExcludeTopics}
 	}
+	connection.Select = repoSelection(c.IncludeTopics, c.Languages, c.PushedWithin, c.MaxRepoSize)
 	if len(c.Token) > 0 {
 		connection.Credential = &sourcespb.GitHub_Token{
 			Token: c.Token,
@@ -61,3 +69,20 @@ func (e *Engine) ScanGitHub(ctx context.Context, c sources.GithubConfig) error {
 	_, err = e.sourceManager.Run(ctx, sourceName, githubSource)
 	return err
 }
+
+// repoSelection returns the selection of the repositories of an org source
+// listing, or nil if every repository is selected. Sizes are rounded up to
+// kilobytes.
+func repoSelection(topics, languages []string, pushedWithin time.Duration, maxSize int64) *sourcespb.RepoSelection {
+	if len(topics) == 0 && len(languages) == 0 && pushedWithin <= 0 && maxSize <= 0 {
+		return nil
+	}
+	selection := &sourcespb.RepoSelection{Topics: topics, Languages: languages}
+	if pushedWithin > 0 {
+		selection.PushedWithin = durationpb.New(pushedWithin)
+	}
+	if maxSize > 0 {
+		selection.MaxSizeKb = (maxSize + 1023) / 1024
+	}
+	return selection
+}
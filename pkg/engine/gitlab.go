@@ -24,7 +24,11 @@ func (e *Engine) ScanGitLab(ctx context.Context, c sources.GitlabConfig) error {
 	}
 	scanOptions := git.NewScanOptions(opts...)
 
-	connection := &sourcespb.GitLab{SkipBinaries: c.SkipBinaries}
+	connection := &sourcespb.GitLab{SkipBinaries: c.SkipBinaries, Tokens: c.Tokens, IgnoreRepos: c.ExcludeRepos}
+	if c.SkipArchived || c.SkipForks || len(c.ExcludeTopics) > 0 {
+		connection.Exclude = &sourcespb.RepoExclusions{Archived: c.SkipArchived, Forks: c.SkipForks, Topics: c.ExcludeTopics}
+	}
+	connection.Select = repoSelection(c.IncludeTopics, c.Languages, c.PushedWithin, c.MaxRepoSize)
 
 	switch {
 	case len(c.Token) > 0:
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// maxContextLineLength is the number of bytes of a context line that are
+// kept. Longer lines, such as those of minified files, are cut, and the line
+// of the secret is cut around the secret.
+const maxContextLineLength = 256
+
+// lineContext returns the line of data the secret raw is on, along with up to
+// n lines before and after it. It returns nil if raw isn't in data, as
+// happens for secrets that were decoded.
+func lineContext(data, raw []byte, n int) *detectors.LineContext {
+	secret := bytes.TrimSpace(raw)
+	i := bytes.Index(data, secret)
+	if len(secret) == 0 || i < 0 {
+		return nil
+	}
+
+	start := bytes.LastIndexByte(data[:i], '\n') + 1
+	end := len(data)
+	if j := bytes.IndexByte(data[i:], '\n'); j >= 0 {
+		end = i + j
+	}
+	ctx := &detectors.LineContext{Line: contextLine(data[start:end], i-start, len(secret))}
+
+	before := data[:start]
+	for len(ctx.Before) < n && len(before) > 0 {
+		before = before[:len(before)-1]
+		lineStart := bytes.LastIndexByte(before, '\n') + 1
+		ctx.Before = append([]string{contextLine(before[lineStart:], 0, 0)}, ctx.Before...)
+		before = before[:lineStart]
+	}
+	after := data[end:]
+	for len(ctx.After) < n && len(after) > 0 {
+		after = after[1:]
+		line, rest, _ := bytes.Cut(after, []byte("\n"))
+		if len(rest) == 0 && len(line) == 0 {
+			break
+		}
+		ctx.After = append(ctx.After, contextLine(line, 0, 0))
+		after = after[len(line):]
+	}
+	return ctx
+}
+
+// contextLine returns line without its trailing carriage return, cut to
+// maxContextLineLength bytes around the secret at offset, if there is one.
+func contextLine(line []byte, offset, secretLen int) string {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) <= maxContextLineLength {
+		return string(line)
+	}
+	start := 0
+	if secretLen > 0 {
+		// Center the secret, but don't cut it.
+		start = max(0, min(offset-(maxContextLineLength-secretLen)/2, len(line)-maxContextLineLength, offset))
+	}
+	end := max(start+maxContextLineLength, min(offset+secretLen, len(line)))
+	cut := string(line[start:end])
+	if start > 0 {
+		cut = "..." + cut
+	}
+	if end < len(line) {
+		cut += "..."
+	}
+	return strings.ToValidUTF8(cut, "")
+}
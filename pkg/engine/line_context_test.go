@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestLineContext(t *testing.T) {
+	data := []byte("one\ntwo\r\n\nkey = AKIASECRET # test\nfour\nfive\nsix\n")
+
+	assert.Equal(t, &detectors.LineContext{
+		Before: []string{"two", ""},
+		Line:   "key = AKIASECRET # test",
+		After:  []string{"four", "five"},
+	}, lineContext(data, []byte("AKIASECRET"), 2))
+
+	assert.Equal(t, &detectors.LineContext{
+		Before: []string{"one", "two", ""},
+		Line:   "key = AKIASECRET # test",
+		After:  []string{"four", "five", "six"},
+	}, lineContext(data, []byte("AKIASECRET\n"), 10))
+
+	assert.Equal(t, &detectors.LineContext{Line: "AKIASECRET"}, lineContext([]byte("AKIASECRET"), []byte("AKIASECRET"), 3))
+	assert.Nil(t, lineContext(data, []byte("QUtJQVNFQ1JFVA=="), 2))
+	assert.Nil(t, lineContext(data, nil, 2))
+}
+
+func TestLineContextLongLines(t *testing.T) {
+	minified := strings.Repeat("a", 1000) + "AKIASECRET" + strings.Repeat("b", 1000)
+	ctx := lineContext([]byte(strings.Repeat("c", 1000)+"\n"+minified), []byte("AKIASECRET"), 1)
+
+	assert.Contains(t, ctx.Line, "AKIASECRET")
+	assert.True(t, strings.HasPrefix(ctx.Line, "...a"))
+	assert.True(t, strings.HasSuffix(ctx.Line, "b..."))
+	assert.Len(t, ctx.Line, maxContextLineLength+6)
+	assert.Equal(t, []string{strings.Repeat("c", maxContextLineLength) + "..."}, ctx.Before)
+}
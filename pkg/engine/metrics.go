@@ -46,6 +46,26 @@ var (
 		[]string{"detector_name"},
 	)
 
+	detectorTimeouts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: common.MetricsNamespace,
+			Subsystem: common.MetricsSubsystem,
+			Name:      "detector_timeouts",
+			Help:      "Total number of chunks a detector was abandoned on because it didn't return after its timeout.",
+		},
+		[]string{"detector_name"},
+	)
+
+	detectorPanics = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: common.MetricsNamespace,
+			Subsystem: common.MetricsSubsystem,
+			Name:      "detector_panics",
+			Help:      "Total number of chunks a detector panicked on.",
+		},
+		[]string{"detector_name"},
+	)
+
 	jobBytesScanned = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: common.MetricsNamespace,
 		Subsystem: common.MetricsSubsystem,
@@ -112,4 +132,18 @@ var (
 		Help:      "Time taken to notify a chunk in milliseconds.",
 		Buckets:   prometheus.ExponentialBuckets(5, 2, 12),
 	})
+
+	resultsSuppressed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "results_suppressed_total",
+		Help:      "Total number of results suppressed by .trufflehogignore files.",
+	})
+
+	resultsScoredOut = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "results_scored_out_total",
+		Help:      "Total number of results dropped for scoring below the score threshold.",
+	})
 )
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// Scan is a scan of a fixed set of sources, for programs that embed the
+// engine rather than run trufflehog. Each run creates its own source manager
+// and engine. The settings of an engine are scoped to the contexts its sources
+// and detectors run with rather than set on the process, so scans with
+// different configurations can run concurrently in the same process. Only
+// Config.RedactLogs affects the rest of the process, as secrets are scrubbed
+// from every log entry once registered.
+type Scan struct {
+	cfg     Config
+	sources []sources.Source
+}
+
+// Summary is the outcome of a Scan.
+type Summary struct {
+	Metrics
+	// Jobs are the metrics of the jobs of the sources, in their order, with
+	// the errors the sources reported.
+	Jobs []sources.JobProgressMetrics
+}
+
+// Errors returns the errors reported by the sources of the scan.
+func (s Summary) Errors() []error {
+	var errs []error
+	for _, job := range s.Jobs {
+		errs = append(errs, job.Errors...)
+	}
+	return errs
+}
+
+// NewScan creates a Scan of srcs, which must be initialized, with dets and
+// decs, that dispatches every result to each of sinks. Empty dets and decs
+// are the default detectors and decoders. cfg configures the rest of the
+// engine; its Detectors, Decoders, Dispatcher, and SourceManager are
+// replaced.
+func NewScan(cfg Config, srcs []sources.Source, dets []detectors.Detector, decs []decoders.Decoder, sinks ...ResultsDispatcher) (*Scan, error) {
+	if len(srcs) == 0 {
+		return nil, errors.New("no sources to scan")
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("no sinks to dispatch results to")
+	}
+	cfg.Detectors = dets
+	cfg.Decoders = decs
+	cfg.Dispatcher = MultiDispatcher(sinks)
+	cfg.SourceManager = nil
+	return &Scan{cfg: cfg, sources: srcs}, nil
+}
+
+// Run scans the sources until they are done, or ctx is cancelled, and waits
+// for their results to be dispatched and the sinks to be flushed. Sources
+// usually can't be scanned again, so Run should only be called once.
+func (s *Scan) Run(ctx context.Context) (Summary, error) {
+	cfg := s.cfg
+	cfg.SourceManager = sources.NewManager(
+		sources.WithConcurrentUnits(cfg.Concurrency),
+		sources.WithSourceUnits(),
+	)
+	eng, err := NewEngine(ctx, &cfg)
+	if err != nil {
+		return Summary{}, err
+	}
+	eng.Start(ctx)
+
+	refs := make([]sources.JobProgressRef, 0, len(s.sources))
+	for _, source := range s.sources {
+		var ref sources.JobProgressRef
+		if ref, err = cfg.SourceManager.Run(ctx, SourceTypeName(source.Type()), source); err != nil {
+			break
+		}
+		refs = append(refs, ref)
+	}
+	// The engine must be finished even if a source couldn't be started.
+	err = errors.Join(err, eng.Finish(ctx))
+
+	summary := Summary{Metrics: eng.GetMetrics()}
+	for _, ref := range refs {
+		summary.Jobs = append(summary.Jobs, ref.Snapshot())
+	}
+	return summary, err
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/filesystem"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+func TestScan_Run(t *testing.T) {
+	ctx := context.Background()
+	newSource := func(file string) sources.Source {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(fakeDetectorKeyword), 0o644))
+		conn, err := anypb.New(&sourcespb.Filesystem{Paths: []string{dir}})
+		require.NoError(t, err)
+		source := new(filesystem.Source)
+		require.NoError(t, source.Init(ctx, file, 0, 0, true, conn, 1))
+		return source
+	}
+
+	printer := new(collectPrinter)
+	scan, err := NewScan(Config{Concurrency: 1, Verify: true},
+		[]sources.Source{newSource("a.txt"), newSource("b.txt")},
+		[]detectors.Detector{fakeDetectorV1{}}, nil,
+		NewPrinterDispatcher(printer),
+	)
+	require.NoError(t, err)
+
+	summary, err := scan.Run(ctx)
+	require.NoError(t, err)
+	assert.Len(t, printer.results, 2)
+	assert.Equal(t, uint64(2), summary.VerifiedSecretsFound)
+	assert.Len(t, summary.Jobs, 2)
+	assert.Empty(t, summary.Errors())
+}
+
+func TestNewScan_Invalid(t *testing.T) {
+	_, err := NewScan(Config{}, nil, nil, nil, NewPrinterDispatcher(new(discardPrinter)))
+	assert.Error(t, err)
+	_, err = NewScan(Config{}, []sources.Source{new(filesystem.Source)}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestEngine_ScopedSettings(t *testing.T) {
+	ctx := context.Background()
+	newEngine := func(cfg Config) *Engine {
+		t.Helper()
+		cfg.SourceManager = sources.NewManager()
+		cfg.Detectors = []detectors.Detector{fakeDetectorV1{}}
+		eng, err := NewEngine(ctx, &cfg)
+		require.NoError(t, err)
+		return eng
+	}
+	state, err := incremental.Open(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+	a := newEngine(Config{Incremental: state, RepoTimeout: time.Minute})
+	b := newEngine(Config{})
+
+	// Each engine's sources only see its own settings.
+	aCtx, bCtx := a.sourceContext(ctx), b.sourceContext(ctx)
+	assert.Same(t, state, incremental.FromContext(aCtx))
+	assert.Nil(t, incremental.FromContext(bCtx))
+	assert.Equal(t, time.Minute, git.DefaultRepoTimeout(aCtx))
+	assert.Zero(t, git.DefaultRepoTimeout(bCtx))
+	assert.Nil(t, incremental.FromContext(ctx))
+}
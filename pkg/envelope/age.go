@@ -0,0 +1,94 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Data keys are wrapped as age files (https://age-encryption.org/v1) for
+// X25519 recipients, so keys made by age-keygen work, and a wrapped key can
+// be opened with the age CLI too.
+
+// ageRecipient wraps data keys for the owner of an age X25519 identity.
+type ageRecipient struct {
+	recipient *age.X25519Recipient
+}
+
+func parseAgeRecipient(s string) (*ageRecipient, error) {
+	recipient, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age recipient %q: %w", s, err)
+	}
+	return &ageRecipient{recipient: recipient}, nil
+}
+
+func (r *ageRecipient) Scheme() string { return SchemeAge }
+
+func (r *ageRecipient) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	var file bytes.Buffer
+	w, err := age.Encrypt(&file, r.recipient)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return file.Bytes(), nil
+}
+
+func (r *ageRecipient) Unwrap(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("an age recipient can't unwrap keys, its identity is needed")
+}
+
+// ageIdentity unwraps the data keys wrapped for its recipient, and wraps
+// them for it too.
+type ageIdentity struct {
+	identity *age.X25519Identity
+	ageRecipient
+}
+
+// parseAgeIdentities parses an identity file as written by age-keygen.
+func parseAgeIdentities(data string) ([]*ageIdentity, error) {
+	parsed, err := age.ParseIdentities(strings.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identities: %w", err)
+	}
+	identities := make([]*ageIdentity, 0, len(parsed))
+	for _, identity := range parsed {
+		x25519, ok := identity.(*age.X25519Identity)
+		if !ok {
+			return nil, fmt.Errorf("unsupported age identity %T", identity)
+		}
+		identities = append(identities, newAgeIdentity(x25519))
+	}
+	return identities, nil
+}
+
+func parseAgeIdentity(s string) (*ageIdentity, error) {
+	identity, err := age.ParseX25519Identity(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity: %w", err)
+	}
+	return newAgeIdentity(identity), nil
+}
+
+func newAgeIdentity(identity *age.X25519Identity) *ageIdentity {
+	return &ageIdentity{identity: identity, ageRecipient: ageRecipient{recipient: identity.Recipient()}}
+}
+
+func (i *ageIdentity) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(wrapped), i.identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
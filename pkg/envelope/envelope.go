@@ -0,0 +1,189 @@
+// Package envelope encrypts secrets for storage with envelope encryption:
+// every Sealer encrypts its secrets with a random data key, which is itself
+// wrapped with a key kept elsewhere, such as an AWS KMS key or an age
+// identity, and stored next to them.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Schemes of the keys that wrap data keys.
+const (
+	SchemeAge    = "age"
+	SchemeAWSKMS = "awskms"
+)
+
+// Prefix starts every sealed value, which is
+// "enc:v1:SCHEME:WRAPPED-DATA-KEY:NONCE-AND-CIPHERTEXT", both base64-encoded.
+// The ciphertext authenticates the header before it, so a secret can't be
+// opened under another scheme or wrapped key.
+const Prefix = "enc:v1:"
+
+var encoding = base64.RawURLEncoding
+
+// KeyWrapper wraps the data keys secrets are encrypted with.
+type KeyWrapper interface {
+	Scheme() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// ParseKey returns the wrapper of key: an age recipient (age1...) or
+// identity (AGE-SECRET-KEY-1...), or an AWS KMS key as awskms://KEY-ID,
+// where the ID may be an ARN or alias.
+func ParseKey(key string) (KeyWrapper, error) {
+	switch {
+	case strings.HasPrefix(key, "age1"):
+		return parseAgeRecipient(key)
+	case strings.HasPrefix(key, "AGE-SECRET-KEY-1"):
+		return parseAgeIdentity(key)
+	case strings.HasPrefix(key, "awskms://"):
+		return newAWSKMS(strings.TrimPrefix(key, "awskms://")), nil
+	default:
+		return nil, errors.New("unsupported encryption key: expected an age recipient or identity, or awskms://KEY-ID")
+	}
+}
+
+// Sealer encrypts secrets with a data key of its own.
+type Sealer struct {
+	scheme  string
+	wrapped string
+	aead    cipher.AEAD
+}
+
+// NewSealer creates a Sealer with a new data key wrapped by wrapper.
+func NewSealer(ctx context.Context, wrapper KeyWrapper) (*Sealer, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err := wrapper.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{scheme: wrapper.Scheme(), wrapped: encoding.EncodeToString(wrapped), aead: aead}, nil
+}
+
+// Seal encrypts a secret.
+func (s *Sealer) Seal(secret []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(secret)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	header := sealedHeader(s.scheme, s.wrapped)
+	sealed := s.aead.Seal(nonce, nonce, secret, []byte(header))
+	return header + encoding.EncodeToString(sealed), nil
+}
+
+func sealedHeader(scheme, wrapped string) string {
+	return Prefix + scheme + ":" + wrapped + ":"
+}
+
+// IsSealed reports whether value was sealed by a Sealer.
+func IsSealed(value string) bool { return strings.HasPrefix(value, Prefix) }
+
+// Opener decrypts sealed secrets, caching the data keys it unwraps.
+type Opener struct {
+	wrappers map[string][]KeyWrapper
+
+	mu   sync.Mutex
+	keys map[string]cipher.AEAD
+}
+
+// NewOpener creates an Opener of the secrets whose data keys were wrapped
+// for the given age identities, or with AWS KMS keys the caller may decrypt
+// with.
+func NewOpener(ageIdentities string) (*Opener, error) {
+	o := &Opener{wrappers: make(map[string][]KeyWrapper), keys: make(map[string]cipher.AEAD)}
+	if ageIdentities != "" {
+		identities, err := parseAgeIdentities(ageIdentities)
+		if err != nil {
+			return nil, err
+		}
+		for _, identity := range identities {
+			o.wrappers[SchemeAge] = append(o.wrappers[SchemeAge], identity)
+		}
+	}
+	// Data keys wrapped with KMS name their key.
+	o.wrappers[SchemeAWSKMS] = []KeyWrapper{newAWSKMS("")}
+	return o, nil
+}
+
+// Open decrypts a value sealed by a Sealer.
+func (o *Opener) Open(ctx context.Context, value string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if !IsSealed(value) || len(parts) != 3 {
+		return nil, errors.New("value isn't sealed")
+	}
+	scheme, wrapped := parts[0], parts[1]
+	sealed, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("sealed value isn't valid base64")
+	}
+	aead, err := o.dataKey(ctx, scheme, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed value is truncated")
+	}
+	secret, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(sealedHeader(scheme, wrapped)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt sealed value: %w", err)
+	}
+	return secret, nil
+}
+
+func (o *Opener) dataKey(ctx context.Context, scheme, wrapped string) (cipher.AEAD, error) {
+	o.mu.Lock()
+	aead, ok := o.keys[wrapped]
+	o.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+	raw, err := encoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, errors.New("wrapped data key isn't valid base64")
+	}
+	wrappers, ok := o.wrappers[scheme]
+	if !ok || len(wrappers) == 0 {
+		return nil, fmt.Errorf("no %s key to unwrap the data key with", scheme)
+	}
+	var errs []error
+	for _, w := range wrappers {
+		dataKey, err := w.Unwrap(ctx, raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if aead, err = newAEAD(dataKey); err != nil {
+			return nil, err
+		}
+		o.mu.Lock()
+		o.keys[wrapped] = aead
+		o.mu.Unlock()
+		return aead, nil
+	}
+	return nil, fmt.Errorf("could not unwrap data key: %w", errors.Join(errs...))
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
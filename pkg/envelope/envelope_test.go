@@ -0,0 +1,137 @@
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateAgeIdentity returns a new age identity as written by age-keygen.
+func generateAgeIdentity(t *testing.T) (identity, recipient string) {
+	t.Helper()
+	key, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return key.String(), key.Recipient().String()
+}
+
+func TestSealer_Age(t *testing.T) {
+	ctx := context.Background()
+	identity, recipient := generateAgeIdentity(t)
+	wrapper, err := ParseKey(recipient)
+	require.NoError(t, err)
+	sealer, err := NewSealer(ctx, wrapper)
+	require.NoError(t, err)
+
+	sealed, err := sealer.Seal([]byte("AKIAEXAMPLE"))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "AKIAEXAMPLE")
+	other, err := sealer.Seal([]byte("AKIAEXAMPLE"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, other, "every secret should have its own nonce")
+
+	opener, err := NewOpener("# created: today\n" + identity + "\n")
+	require.NoError(t, err)
+	secret, err := opener.Open(ctx, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", string(secret))
+
+	otherIdentity, _ := generateAgeIdentity(t)
+	wrongOpener, err := NewOpener(otherIdentity)
+	require.NoError(t, err)
+	_, err = wrongOpener.Open(ctx, sealed)
+	assert.Error(t, err)
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	_, err = opener.Open(ctx, tampered)
+	assert.Error(t, err)
+	_, err = opener.Open(ctx, "AKIAEXAMPLE")
+	assert.Error(t, err)
+}
+
+func TestAgeIdentity_Unwrap(t *testing.T) {
+	ctx := context.Background()
+	identity, _ := generateAgeIdentity(t)
+	parsed, err := parseAgeIdentity(identity)
+	require.NoError(t, err)
+	wrapped, err := parsed.Wrap(ctx, []byte("data key"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(wrapped), "age-encryption.org/v1\n-> X25519 "))
+
+	dataKey, err := parsed.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(dataKey))
+
+	// The header MAC covers the stanzas.
+	tampered := []byte(strings.Replace(string(wrapped), "-> X25519 ", "-> X25519 extra ", 1))
+	_, err = parsed.Unwrap(ctx, tampered)
+	assert.Error(t, err)
+}
+
+// prefixWrapper wraps a data key behind a byte its Unwrap ignores, so
+// different wrapped keys hold the same data key.
+type prefixWrapper struct{ prefix byte }
+
+func (w prefixWrapper) Scheme() string { return "prefix" }
+
+func (w prefixWrapper) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	return append([]byte{w.prefix}, dataKey...), nil
+}
+
+func (w prefixWrapper) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	return wrapped[1:], nil
+}
+
+func TestOpener_TamperedHeader(t *testing.T) {
+	ctx := context.Background()
+	sealer, err := NewSealer(ctx, prefixWrapper{prefix: 1})
+	require.NoError(t, err)
+	sealed, err := sealer.Seal([]byte("AKIAEXAMPLE"))
+	require.NoError(t, err)
+
+	opener := &Opener{
+		wrappers: map[string][]KeyWrapper{"prefix": {prefixWrapper{}}, "other": {prefixWrapper{}}},
+		keys:     make(map[string]cipher.AEAD),
+	}
+	secret, err := opener.Open(ctx, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", string(secret))
+
+	parts := strings.Split(strings.TrimPrefix(sealed, Prefix), ":")
+	require.Len(t, parts, 3)
+	wrapped, err := encoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	wrapped[0] = 2
+	for name, tampered := range map[string]string{
+		"scheme":      sealedHeader("other", parts[1]) + parts[2],
+		"wrapped key": sealedHeader("prefix", encoding.EncodeToString(wrapped)) + parts[2],
+	} {
+		// Both headers unwrap the same data key, which only the
+		// authenticated header tells apart.
+		_, err := opener.Open(ctx, tampered)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	_, recipient := generateAgeIdentity(t)
+	for key, scheme := range map[string]string{recipient: SchemeAge, "awskms://alias/trufflehog": SchemeAWSKMS} {
+		wrapper, err := ParseKey(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, scheme, wrapper.Scheme())
+	}
+	for _, key := range []string{"", "age1invalid", "AGE-SECRET-KEY-1INVALID", "hunter2"} {
+		_, err := ParseKey(key)
+		assert.Error(t, err, key)
+	}
+	_, err := NewOpener("# only comments\n")
+	assert.Error(t, err)
+}
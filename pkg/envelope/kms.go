@@ -0,0 +1,97 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// awsKMS wraps data keys with an AWS KMS key, using the default AWS
+// credentials. Wrapped keys are the ARN of the key, a newline, and the KMS
+// ciphertext, so they're unwrapped in the key's region.
+type awsKMS struct {
+	keyID string
+
+	once    sync.Once
+	sess    *session.Session
+	err     error
+	mu      sync.Mutex
+	clients map[string]kmsiface.KMSAPI
+}
+
+func newAWSKMS(keyID string) *awsKMS {
+	return &awsKMS{keyID: keyID, clients: make(map[string]kmsiface.KMSAPI)}
+}
+
+func (k *awsKMS) Scheme() string { return SchemeAWSKMS }
+
+// client returns a client of the region, or of the default region if it's
+// empty. The session is only created once it's needed.
+func (k *awsKMS) client(region string) (kmsiface.KMSAPI, error) {
+	k.once.Do(func() {
+		k.sess, k.err = session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	})
+	if k.err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", k.err)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if c, ok := k.clients[region]; ok {
+		return c, nil
+	}
+	var c kmsiface.KMSAPI = kms.New(k.sess)
+	if region != "" {
+		c = kms.New(k.sess, &aws.Config{Region: aws.String(region)})
+	}
+	k.clients[region] = c
+	return c, nil
+}
+
+// keyRegion returns the region of a key ARN, or "" for key IDs and aliases
+// of the default region.
+func keyRegion(keyID string) string {
+	if parsed, err := arn.Parse(keyID); err == nil {
+		return parsed.Region
+	}
+	return ""
+}
+
+func (k *awsKMS) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	if k.keyID == "" {
+		return nil, errors.New("no AWS KMS key to wrap data keys with")
+	}
+	c, err := k.client(keyRegion(k.keyID))
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.EncryptWithContext(ctx, &kms.EncryptInput{KeyId: aws.String(k.keyID), Plaintext: dataKey})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(aws.StringValue(out.KeyId)+"\n"), out.CiphertextBlob...), nil
+}
+
+func (k *awsKMS) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	keyID, blob, ok := bytes.Cut(wrapped, []byte("\n"))
+	if !ok || !strings.HasPrefix(string(keyID), "arn:") {
+		return nil, errors.New("invalid AWS KMS wrapped key")
+	}
+	c, err := k.client(keyRegion(string(keyID)))
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.DecryptWithContext(ctx, &kms.DecryptInput{KeyId: aws.String(string(keyID)), CiphertextBlob: blob})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,64 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyARN = "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+// fakeKMS "encrypts" by reversing the plaintext.
+type fakeKMS struct {
+	kmsiface.KMSAPI
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+func (fakeKMS) EncryptWithContext(_ aws.Context, in *kms.EncryptInput, _ ...request.Option) (*kms.EncryptOutput, error) {
+	return &kms.EncryptOutput{KeyId: aws.String(testKeyARN), CiphertextBlob: reverse(in.Plaintext)}, nil
+}
+
+func (fakeKMS) DecryptWithContext(_ aws.Context, in *kms.DecryptInput, _ ...request.Option) (*kms.DecryptOutput, error) {
+	if aws.StringValue(in.KeyId) != testKeyARN {
+		return nil, errors.New("wrong key")
+	}
+	return &kms.DecryptOutput{Plaintext: reverse(in.CiphertextBlob)}, nil
+}
+
+func TestAWSKMS(t *testing.T) {
+	ctx := context.Background()
+	k := newAWSKMS("alias/trufflehog")
+	// The alias is in the default region, and the ARN it resolves to names
+	// the key's.
+	k.clients[""] = fakeKMS{}
+	k.clients["eu-west-1"] = fakeKMS{}
+
+	wrapped, err := k.Wrap(ctx, []byte("data key"))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(wrapped, []byte(testKeyARN+"\n")))
+	dataKey, err := k.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(dataKey))
+
+	_, err = k.Unwrap(ctx, []byte("not wrapped"))
+	assert.Error(t, err)
+	_, err = newAWSKMS("").Wrap(ctx, []byte("data key"))
+	assert.Error(t, err)
+	assert.Equal(t, "eu-west-1", keyRegion(testKeyARN))
+	assert.Equal(t, "", keyRegion("alias/trufflehog"))
+}
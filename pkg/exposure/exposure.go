@@ -0,0 +1,312 @@
+// Package exposure works out how long the secrets found in git repositories
+// have been exposed, from the history of the repositories, and whether the
+// repositories are public.
+package exposure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// Tracker works out the exposure of the secrets of findings. Repositories are
+// cloned again, without credentials, the first time one of their findings is
+// annotated, and removed by Close.
+type Tracker struct {
+	client    *http.Client
+	githubAPI string
+	now       func() time.Time
+	repos     repoCache
+
+	mu         sync.Mutex
+	visibility map[string]*visibility
+	windows    map[[2]string]*window
+}
+
+// repoCache is the git.RepoCache repositories are read from.
+type repoCache interface {
+	Path(ctx context.Context, repoURL string) (string, error)
+	Close()
+}
+
+// visibility is whether a repository is public, if that is known.
+type visibility struct {
+	once   sync.Once
+	public *bool
+}
+
+// window is when a secret was added to the history of a repository, and
+// removed from it.
+type window struct {
+	once       sync.Once
+	introduced time.Time
+	removed    *time.Time
+	err        error
+}
+
+// NewTracker creates a Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		client:     common.SaneHttpClient(),
+		githubAPI:  "https://api.github.com",
+		now:        time.Now,
+		repos:      git.NewRepoCache(),
+		visibility: make(map[string]*visibility),
+		windows:    make(map[[2]string]*window),
+	}
+}
+
+// location is the part of the metadata of a git finding exposure needs.
+type location struct {
+	repository string
+	timestamp  string
+	visibility source_metadatapb.Visibility
+	gitlab     bool
+}
+
+func locate(md *source_metadatapb.MetaData) (location, bool) {
+	switch m := md.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		return location{repository: m.Git.Repository, timestamp: m.Git.Timestamp, visibility: source_metadatapb.Visibility_unknown}, true
+	case *source_metadatapb.MetaData_Github:
+		return location{repository: m.Github.Repository, timestamp: m.Github.Timestamp, visibility: m.Github.Visibility}, true
+	case *source_metadatapb.MetaData_Gitlab:
+		return location{repository: m.Gitlab.Repository, timestamp: m.Gitlab.Timestamp, visibility: source_metadatapb.Visibility_unknown, gitlab: true}, true
+	default:
+		return location{}, false
+	}
+}
+
+// Exposure returns the exposure of the secret of r, or nil if r wasn't found
+// in a git repository.
+func (t *Tracker) Exposure(ctx context.Context, r *detectors.ResultWithMetadata) *detectors.Exposure {
+	loc, ok := locate(r.SourceMetadata)
+	if !ok || loc.repository == "" {
+		return nil
+	}
+	logger := ctx.Logger().WithValues("repository", loc.repository)
+
+	// The commit of the finding introduced the secret unless the history of
+	// the repository has it in an older one, such as in another file.
+	introduced := parseCommitTime(loc.timestamp)
+	var removed *time.Time
+	if path, err := t.repos.Path(ctx, loc.repository); err != nil {
+		logger.V(2).Info("could not clone repository to find when secrets were removed", "error", err)
+	} else if w := t.window(ctx, path, secretOf(r)); w.err != nil {
+		logger.V(2).Info("could not find when secret was removed", "error", w.err)
+	} else if !w.introduced.IsZero() {
+		if introduced.IsZero() || w.introduced.Before(introduced) {
+			introduced = w.introduced
+		}
+		removed = w.removed
+	}
+	if introduced.IsZero() {
+		return nil
+	}
+
+	end := t.now()
+	if removed != nil {
+		end = *removed
+	}
+	exposure := &detectors.Exposure{IntroducedAt: introduced.UTC(), RemovedAt: removed, Public: t.public(ctx, loc)}
+	if days := int(end.Sub(introduced) / (24 * time.Hour)); days > 0 {
+		exposure.Days = days
+	}
+	return exposure
+}
+
+// window finds the commits of the HEAD of the repository at path that added
+// or removed secret: the oldest introduced it and, if HEAD doesn't have it,
+// the newest removed it.
+func (t *Tracker) window(ctx context.Context, path, secret string) *window {
+	t.mu.Lock()
+	key := [2]string{path, secret}
+	w, ok := t.windows[key]
+	if !ok {
+		w = new(window)
+		t.windows[key] = w
+	}
+	t.mu.Unlock()
+
+	w.once.Do(func() {
+		out, err := runGit(ctx, path, "log", "HEAD", "--format=%cI", "-S"+secret)
+		if err != nil {
+			w.err = err
+			return
+		}
+		dates := strings.Fields(out)
+		if len(dates) == 0 {
+			return
+		}
+		if w.introduced, w.err = time.Parse(time.RFC3339, dates[len(dates)-1]); w.err != nil {
+			return
+		}
+		// git grep exits with 1 if nothing matches.
+		_, err = runGit(ctx, path, "grep", "--quiet", "--fixed-strings", "-e", secret, "HEAD")
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			removed, err := time.Parse(time.RFC3339, dates[0])
+			if err != nil {
+				w.err = err
+				return
+			}
+			removed = removed.UTC()
+			w.removed = &removed
+		default:
+			w.err = err
+		}
+	})
+	return w
+}
+
+// public tells whether the repository of loc is public from its metadata or,
+// for repositories on GitHub and GitLab, from whether their API shows it
+// without credentials.
+func (t *Tracker) public(ctx context.Context, loc location) *bool {
+	switch loc.visibility {
+	case source_metadatapb.Visibility_public:
+		return ptr(true)
+	case source_metadatapb.Visibility_private, source_metadatapb.Visibility_shared:
+		return ptr(false)
+	}
+
+	t.mu.Lock()
+	v, ok := t.visibility[loc.repository]
+	if !ok {
+		v = new(visibility)
+		t.visibility[loc.repository] = v
+	}
+	t.mu.Unlock()
+
+	v.once.Do(func() {
+		u, err := url.Parse(loc.repository)
+		if err != nil || u.Host == "" {
+			return
+		}
+		path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+		var endpoint string
+		switch {
+		case u.Host == "github.com":
+			endpoint = t.githubAPI + "/repos/" + path
+		case loc.gitlab || u.Host == "gitlab.com":
+			endpoint = u.Scheme + "://" + u.Host + "/api/v4/projects/" + url.PathEscape(path)
+		default:
+			return
+		}
+		public, err := t.visible(ctx, endpoint)
+		if err != nil {
+			ctx.Logger().V(2).Info("could not tell whether repository is public", "repository", loc.repository, "error", err)
+			return
+		}
+		v.public = &public
+	})
+	return v.public
+}
+
+// visible tells whether endpoint can be read without credentials.
+func (t *Tracker) visible(ctx context.Context, endpoint string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := t.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound, http.StatusUnauthorized:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s returned %s", endpoint, res.Status)
+	}
+}
+
+// Close removes the repositories that were cloned.
+func (t *Tracker) Close() {
+	t.repos.Close()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.visibility = make(map[string]*visibility)
+	t.windows = make(map[[2]string]*window)
+}
+
+func secretOf(r *detectors.ResultWithMetadata) string {
+	return strings.TrimSpace(string(r.Raw))
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// commitTimeLayouts are the formats git sources write commit timestamps in.
+var commitTimeLayouts = []string{"2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 -0700 MST", time.RFC3339}
+
+func parseCommitTime(timestamp string) time.Time {
+	for _, layout := range commitTimeLayouts {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// resultsDispatcher mirrors engine.ResultsDispatcher, which can't be
+// referenced here without an import cycle.
+type resultsDispatcher interface {
+	Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error
+}
+
+// Dispatcher annotates results with the exposure of their secrets before
+// dispatching them.
+type Dispatcher struct {
+	tracker *Tracker
+	next    resultsDispatcher
+}
+
+// NewDispatcher creates a Dispatcher that annotates results with tracker and
+// hands them to next.
+func NewDispatcher(tracker *Tracker, next resultsDispatcher) *Dispatcher {
+	return &Dispatcher{tracker: tracker, next: next}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error {
+	result.Exposure = d.tracker.Exposure(ctx, &result)
+	return d.next.Dispatch(ctx, result)
+}
+
+// Flush flushes the next dispatcher if it buffers its output, then removes
+// the repositories the tracker cloned.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	defer d.tracker.Close()
+	if f, ok := d.next.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
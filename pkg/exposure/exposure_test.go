@@ -0,0 +1,140 @@
+package exposure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// commitFile commits file with content in the repository at dir on date.
+func commitFile(t *testing.T, dir, file, content string, date time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "update " + file}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date.Format(time.RFC3339), "GIT_COMMITTER_DATE="+date.Format(time.RFC3339),
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func gitResult(repo, raw, timestamp string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{Repository: repo, Timestamp: timestamp}},
+		},
+		Result: detectors.Result{Raw: []byte(raw)},
+	}
+}
+
+func TestTracker_Exposure(t *testing.T) {
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	day := func(n int) time.Time { return time.Date(2024, 1, n, 12, 0, 0, 0, time.UTC) }
+	commitFile(t, dir, "config.env", "KEY=AKIAREMOVED\n", day(1))
+	commitFile(t, dir, "other.env", "KEY=AKIAREMOVED\nTOKEN=AKIAKEPT\n", day(5))
+	commitFile(t, dir, "config.env", "KEY=\n", day(8))
+	commitFile(t, dir, "other.env", "TOKEN=AKIAKEPT\n", day(11))
+
+	ctx := context.Background()
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return day(31) }
+	repo := "file://" + dir
+
+	// The finding in other.env is newer than the one that introduced the
+	// secret in config.env.
+	exposure := tracker.Exposure(ctx, gitResult(repo, "AKIAREMOVED", "2024-01-05 12:00:00 +0000"))
+	require.NotNil(t, exposure)
+	assert.Equal(t, day(1), exposure.IntroducedAt)
+	require.NotNil(t, exposure.RemovedAt)
+	assert.Equal(t, day(11), *exposure.RemovedAt)
+	assert.Equal(t, 10, exposure.Days)
+	assert.Nil(t, exposure.Public, "a local repository's visibility is unknown")
+
+	exposure = tracker.Exposure(ctx, gitResult(repo, "AKIAKEPT", "2024-01-05 12:00:00 +0000"))
+	require.NotNil(t, exposure)
+	assert.Equal(t, day(5), exposure.IntroducedAt)
+	assert.Nil(t, exposure.RemovedAt)
+	assert.Equal(t, 26, exposure.Days)
+
+	// Secrets the history of HEAD doesn't have are exposed since the commit
+	// they were found in.
+	exposure = tracker.Exposure(ctx, gitResult(repo, "AKIABRANCH", "2024-01-21 12:00:00 +0000"))
+	require.NotNil(t, exposure)
+	assert.Equal(t, day(21), exposure.IntroducedAt)
+	assert.Equal(t, 10, exposure.Days)
+
+	assert.Nil(t, tracker.Exposure(ctx, &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "a"}}},
+	}))
+}
+
+// unreachableRepos is a repository cache that can't clone anything.
+type unreachableRepos struct{}
+
+func (unreachableRepos) Path(context.Context, string) (string, error) { return "", assert.AnError }
+func (unreachableRepos) Close()                                       {}
+
+func TestTracker_Public(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		if r.URL.Path == "/repos/org/private" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	tracker := NewTracker()
+	tracker.githubAPI = srv.URL
+	tracker.repos = unreachableRepos{}
+	timestamp := "2024-01-01 12:00:00 +0000"
+
+	exposure := tracker.Exposure(ctx, gitResult("https://github.com/org/public.git", "AKIA", timestamp))
+	require.NotNil(t, exposure.Public)
+	assert.True(t, *exposure.Public)
+	exposure = tracker.Exposure(ctx, gitResult("https://github.com/org/private.git", "AKIA", timestamp))
+	require.NotNil(t, exposure.Public)
+	assert.False(t, *exposure.Public)
+
+	exposure = tracker.Exposure(ctx, &detectors.ResultWithMetadata{SourceMetadata: &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Gitlab{Gitlab: &source_metadatapb.Gitlab{Repository: srv.URL + "/group/project.git", Timestamp: timestamp}},
+	}})
+	require.NotNil(t, exposure.Public)
+	assert.True(t, *exposure.Public)
+
+	// The visibility in the metadata of GitHub findings is used as it is.
+	exposure = tracker.Exposure(ctx, &detectors.ResultWithMetadata{SourceMetadata: &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Github{Github: &source_metadatapb.Github{
+			Repository: "https://github.com/org/secret.git", Timestamp: timestamp, Visibility: source_metadatapb.Visibility_private,
+		}},
+	}})
+	require.NotNil(t, exposure.Public)
+	assert.False(t, *exposure.Public)
+
+	// Repositories elsewhere have an unknown visibility.
+	exposure = tracker.Exposure(ctx, gitResult("https://example.com/org/repo.git", "AKIA", timestamp))
+	assert.Nil(t, exposure.Public)
+
+	// The visibility of each repository is only looked up once.
+	tracker.Exposure(ctx, gitResult("https://github.com/org/public.git", "AKIAOTHER", timestamp))
+	assert.Equal(t, []string{"/repos/org/public", "/repos/org/private", "/api/v4/projects/group%2Fproject"}, paths)
+}
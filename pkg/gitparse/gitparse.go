@@ -219,6 +219,21 @@ func NewParser(options ...Option) *Parser {
 	return parser
 }
 
+// diffArgs fix the format of the patches git prints to the one the parser
+// reads, whatever the git config of the user or the repository sets.
+var diffArgs = []string{
+	"--no-color",
+	"--no-ext-diff",
+	// Scan the content of files as it's committed, not as a textconv driver
+	// shows it.
+	"--no-textconv",
+	"--src-prefix=a/",
+	"--dst-prefix=b/",
+	// Renamed files only have a diff of their changes, which is why the
+	// filters below keep renames.
+	"--find-renames",
+}
+
 // RepoPath parses the output of the `git log` command for the `source` path.
 // The Diff chan will return diffs in the order they are parsed from the log.
 func (c *Parser) RepoPath(
@@ -237,9 +252,11 @@ func (c *Parser) RepoPath(
 		"--date=format:%a %b %d %H:%M:%S %Y %z",
 		"--pretty=fuller", // https://git-scm.com/docs/git-log#_pretty_formats
 		"--notes",         // https://git-scm.com/docs/git-log#Documentation/git-log.txt---notesltrefgt
+		"--no-show-signature",
 	}
+	args = append(args, diffArgs...)
 	if abbreviatedLog {
-		args = append(args, "--diff-filter=AM")
+		args = append(args, "--diff-filter=AMR")
 	}
 	if head != "" {
 		args = append(args, head)
@@ -250,7 +267,7 @@ func (c *Parser) RepoPath(
 		args = append(args, "--", ".", ":(exclude)"+glob)
 	}
 
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	absPath, err := filepath.Abs(source)
 	if err == nil {
 		if !isBare {
@@ -276,9 +293,10 @@ func (c *Parser) RepoPath(
 // Staged parses the output of the `git diff` command for the `source` path.
 func (c *Parser) Staged(ctx context.Context, source string) (chan *Diff, error) {
 	// Provide the --cached flag to diff to get the diff of the staged changes.
-	args := []string{"-C", source, "diff", "-p", "--cached", "--full-history", "--diff-filter=AM", "--date=format:%a %b %d %H:%M:%S %Y %z"}
+	args := []string{"-C", source, "diff", "-p", "--cached", "--full-history", "--diff-filter=AMR", "--date=format:%a %b %d %H:%M:%S %Y %z"}
+	args = append(args, diffArgs...)
 
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 
 	absPath, err := filepath.Abs(source)
 	if err == nil {
@@ -377,7 +395,9 @@ func (c *Parser) FromReader(ctx context.Context, stdOut io.Reader, diffChan chan
 						"latest_state", latestState.String(),
 					)
 				}
-				diffChan <- currentDiff
+				if !sendDiff(ctx, diffChan, currentDiff) {
+					return
+				}
 				currentCommit.Size += currentDiff.Len()
 				currentCommit.hasDiffs = true
 			}
@@ -388,7 +408,9 @@ func (c *Parser) FromReader(ctx context.Context, stdOut io.Reader, diffChan chan
 					// Initialize an empty Diff instance associated with the given commit.
 					// Since this diff represents "no changes", we only need to set the commit.
 					// This is required to ensure commits that have no diffs are still processed.
-					diffChan <- &Diff{Commit: currentCommit}
+					if !sendDiff(ctx, diffChan, &Diff{Commit: currentCommit}) {
+						return
+					}
 				}
 			}
 
@@ -454,7 +476,9 @@ func (c *Parser) FromReader(ctx context.Context, stdOut io.Reader, diffChan chan
 						"latest_state", latestState.String(),
 					)
 				}
-				diffChan <- currentDiff
+				if !sendDiff(ctx, diffChan, currentDiff) {
+					return
+				}
 				currentCommit.hasDiffs = true
 			}
 
@@ -514,7 +538,9 @@ func (c *Parser) FromReader(ctx context.Context, stdOut io.Reader, diffChan chan
 						"latest_state", latestState.String(),
 					)
 				}
-				diffChan <- currentDiff
+				if !sendDiff(ctx, diffChan, currentDiff) {
+					return
+				}
 			}
 			currentDiff = diff(currentCommit, withPathB(currentDiff.PathB))
 
@@ -963,6 +989,17 @@ func isCommitSeparatorLine(latestState ParseState, line []byte) bool {
 	return false
 }
 
+// sendDiff sends d on diffChan, unless ctx is done first, as nothing may
+// receive from diffChan anymore then. It reports whether d was sent.
+func sendDiff(ctx context.Context, diffChan chan *Diff, d *Diff) bool {
+	select {
+	case diffChan <- d:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func cleanupParse(ctx context.Context, currentCommit *Commit, currentDiff *Diff, diffChan chan *Diff, totalLogSize *int) {
 	if err := currentDiff.finalize(); err != nil {
 		ctx.Logger().Error(err, "failed to finalize diff")
@@ -972,7 +1009,9 @@ func cleanupParse(ctx context.Context, currentCommit *Commit, currentDiff *Diff,
 	// Ignore empty or binary diffs (this condition may be redundant).
 	if currentDiff != nil && (currentDiff.Len() > 0 || currentDiff.IsBinary) {
 		currentDiff.Commit = currentCommit
-		diffChan <- currentDiff
+		if !sendDiff(ctx, diffChan, currentDiff) {
+			return
+		}
 	}
 	if currentCommit != nil {
 		if totalLogSize != nil {
@@ -2,6 +2,9 @@ package gitparse
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -1495,6 +1498,84 @@ func TestMaxCommitSize(t *testing.T) {
 
 }
 
+func TestFromReaderCancelled(t *testing.T) {
+	parser := NewParser()
+	diffChan := make(chan *Diff) // Unbuffered, and never received from.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		parser.FromReader(ctx, strings.NewReader(commitLog), diffChan, false)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("parser blocked sending diffs after its context was cancelled")
+	}
+}
+
+func TestRepoPath_GitConfig(t *testing.T) {
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitCmd("init")
+	// Config that changes the format of the patches git prints.
+	gitCmd("config", "color.ui", "always")
+	gitCmd("config", "diff.noprefix", "true")
+	gitCmd("config", "diff.mnemonicPrefix", "true")
+
+	content := strings.Repeat("line\n", 20)
+	if err := os.WriteFile(filepath.Join(repoPath, "old.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "add old.txt")
+	gitCmd("mv", "old.txt", "new.txt")
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte(content+"secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "rename old.txt")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	diffChan, err := NewParser().RepoPath(ctx, repoPath, "", true, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for diff := range diffChan {
+		if diff.PathB == "" {
+			continue
+		}
+		paths = append(paths, diff.PathB)
+		if diff.PathB == "new.txt" {
+			rc, err := diff.ReadCloser()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			_, _ = b.ReadFrom(rc)
+			_ = rc.Close()
+			// Only the changes of the renamed file are scanned.
+			if got := strings.TrimSpace(b.String()); got != "secret" {
+				t.Errorf("diff of new.txt = %q, want %q", got, "secret")
+			}
+		}
+	}
+	if want := []string{"new.txt", "old.txt"}; strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
 const commitLog = `commit e50b135fd29e91b2fbb25923797f5ecffe59f359
 Author: lionzxy <nikita@kulikof.ru>
 AuthorDate:   Wed Mar 1 18:20:04 2017 +0300
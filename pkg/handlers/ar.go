@@ -22,8 +22,8 @@ func newARHandler() *arHandler {
 
 // HandleFile processes AR formatted files. This function needs to be implemented to extract or
 // manage data from AR files according to specific requirements.
-func (h *arHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
-	archiveChan := make(chan []byte, defaultBufferSize)
+func (h *arHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	archiveChan := make(chan handledData, defaultBufferSize)
 
 	if feature.ForceSkipArchives.Load() {
 		close(archiveChan)
@@ -71,7 +71,7 @@ func (h *arHandler) HandleFile(ctx logContext.Context, input fileReader) (chan [
 	return archiveChan, nil
 }
 
-func (h *arHandler) processARFiles(ctx logContext.Context, reader *deb.Ar, archiveChan chan []byte) error {
+func (h *arHandler) processARFiles(ctx logContext.Context, reader *deb.Ar, archiveChan chan handledData) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -94,7 +94,7 @@ func (h *arHandler) processARFiles(ctx logContext.Context, reader *deb.Ar, archi
 				return fmt.Errorf("error creating mime-type reader: %w", err)
 			}
 
-			if err := h.handleNonArchiveContent(fileCtx, rdr, archiveChan); err != nil {
+			if err := h.handleNonArchiveContent(withContainer(fileCtx, "ar", arEntry.Name), rdr, archiveChan); err != nil {
 				fileCtx.Logger().Error(err, "error handling archive content in AR")
 				h.metrics.incErrors()
 			}
@@ -16,8 +16,10 @@ import (
 type ctxKey int
 
 const (
-	depthKey          ctxKey = iota
-	defaultBufferSize        = 512
+	depthKey ctxKey = iota
+	provenanceKey
+
+	defaultBufferSize = 512
 )
 
 var (
@@ -44,8 +46,8 @@ func newArchiveHandler() *archiveHandler {
 // utilizing a single output channel. It first tries to identify the input as an archive. If it is an archive,
 // it processes it accordingly; otherwise, it handles the input as non-archive content.
 // The function returns a channel that will receive the extracted data bytes and an error if the initial setup fails.
-func (h *archiveHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
-	dataChan := make(chan []byte, defaultBufferSize)
+func (h *archiveHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
 
 	if feature.ForceSkipArchives.Load() {
 		close(dataChan)
@@ -92,7 +94,7 @@ var ErrMaxDepthReached = errors.New("max archive depth reached")
 // It takes a reader from which it attempts to identify and process the archive format. Depending on the archive type,
 // it either decompresses or extracts the contents directly, sending data to the provided channel.
 // Returns an error if the archive cannot be processed due to issues like exceeding maximum depth or unsupported formats.
-func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader fileReader, archiveChan chan []byte) error {
+func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader fileReader, archiveChan chan handledData) error {
 	ctx.Logger().V(4).Info("Starting archive processing", "depth", depth)
 	defer ctx.Logger().V(4).Info("Finished archive processing", "depth", depth)
 
@@ -131,9 +133,14 @@ func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader f
 		}
 		defer rdr.Close()
 
-		return h.openArchive(ctx, depth+1, rdr, archiveChan)
+		// Only the compression of a compressed archive is undone here; its archive format is its own level.
+		format := reader.format.Name()
+		if compressed, ok := reader.format.(archiver.CompressedArchive); ok && compressed.Compression != nil {
+			format = compressed.Compression.Name()
+		}
+		return h.openArchive(withContainer(ctx, format, ""), depth+1, rdr, archiveChan)
 	case archiver.Extractor:
-		err := archive.Extract(logContext.WithValue(ctx, depthKey, depth+1), reader, nil, h.extractorHandler(archiveChan))
+		err := archive.Extract(logContext.WithValue(ctx, depthKey, depth+1), reader, nil, h.extractorHandler(reader.format.Name(), archiveChan))
 		if err != nil {
 			return fmt.Errorf("error extracting archive with format: %s: %w", reader.format.Name(), err)
 		}
@@ -147,7 +154,8 @@ func (h *archiveHandler) openArchive(ctx logContext.Context, depth int, reader f
 // It logs the extraction, checks for cancellation, and decides whether to skip the file based on its name or type,
 // particularly for binary files if configured to skip. If the file is not skipped, it recursively calls openArchive
 // to handle nested archives or to continue processing based on the file's content and depth in the archive structure.
-func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.Context, archiver.File) error {
+// The file is recorded as an entry of an archive of the given format in the provenance of its content.
+func (h *archiveHandler) extractorHandler(format string, archiveChan chan handledData) func(context.Context, archiver.File) error {
 	return func(ctx context.Context, file archiver.File) error {
 		lCtx := logContext.WithValues(
 			logContext.AddLogger(ctx),
@@ -177,7 +185,7 @@ func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.
 			return nil
 		}
 
-		if common.SkipFile(file.Name()) || common.IsBinary(file.Name()) {
+		if SkipFile(lCtx, file.Name()) || common.IsBinary(file.Name()) {
 			lCtx.Logger().V(2).Info("skipping file: extension is ignored")
 			h.metrics.incFilesSkipped()
 			return nil
@@ -219,6 +227,6 @@ func (h *archiveHandler) extractorHandler(archiveChan chan []byte) func(context.
 		h.metrics.observeFileSize(fileSize)
 
 		lCtx.Logger().V(4).Info("Processed file successfully", "filename", file.Name(), "size", file.Size())
-		return h.openArchive(lCtx, depth, rdr, archiveChan)
+		return h.openArchive(withContainer(lCtx, format, file.NameInArchive), depth, rdr, archiveChan)
 	}
 }
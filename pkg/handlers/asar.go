@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/feature"
+)
+
+// maxASARHeaderSize caps the size of the header of an asar archive, which
+// lists its files, so that a corrupt size isn't read into memory.
+const maxASARHeaderSize = 64 << 20 // 64 MB
+
+// asarHandler handles the asar archives Electron apps package their code in,
+// such as resources/app.asar.
+//
+// An asar archive is a header followed by the content of its files. The
+// header is a JSON tree of the files, each with its size and its offset
+// from the end of the header, wrapped in two Chromium pickles: the first
+// holds the size of the second, which holds the length of the JSON and the
+// JSON itself.
+type asarHandler struct{ *archiveHandler }
+
+// newASARHandler creates an asarHandler.
+func newASARHandler() *asarHandler {
+	return &asarHandler{archiveHandler: &archiveHandler{defaultHandler: newDefaultHandler(asarHandlerType)}}
+}
+
+// asarEntry is a file or a directory in the header of an asar archive.
+// Files packed outside the archive, in app.asar.unpacked, are unpacked.
+type asarEntry struct {
+	Files    map[string]asarEntry `json:"files"`
+	Size     int64                `json:"size"`
+	Offset   string               `json:"offset"`
+	Unpacked bool                 `json:"unpacked"`
+	Link     string               `json:"link"`
+}
+
+// HandleFile extracts the files of an asar archive, handling nested archives
+// in them as the archive handler does.
+func (h *asarHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	if feature.ForceSkipArchives.Load() {
+		close(dataChan)
+		return dataChan, nil
+	}
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		if err = h.extractFiles(ctx, input, dataChan); err != nil {
+			ctx.Logger().Error(err, "error extracting asar archive")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+func (h *asarHandler) extractFiles(ctx logContext.Context, input fileReader, dataChan chan handledData) error {
+	root, base, err := readASARHeader(input)
+	if err != nil {
+		return err
+	}
+
+	var walk func(dir string, entry asarEntry) error
+	walk = func(dir string, entry asarEntry) error {
+		// Entries are extracted in order so that chunks are reported the
+		// same way every time.
+		for _, name := range slices.Sorted(maps.Keys(entry.Files)) {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			child, entryPath := entry.Files[name], path.Join(dir, name)
+			if child.Files != nil {
+				if err := walk(entryPath, child); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := h.extractFile(ctx, input, base, entryPath, child, dataChan); err != nil {
+				if common.IsDone(ctx) {
+					return ctx.Err()
+				}
+				ctx.Logger().Error(err, "error handling asar entry", "filename", entryPath)
+				h.metrics.incErrors()
+			}
+		}
+		return nil
+	}
+	return walk("", root)
+}
+
+// extractFile handles the file of the archive input at entryPath, whose
+// content starts at base plus its offset.
+func (h *asarHandler) extractFile(ctx logContext.Context, input fileReader, base int64, entryPath string, entry asarEntry, dataChan chan handledData) error {
+	lCtx := logContext.WithValues(ctx, "filename", entryPath, "size", entry.Size)
+	switch {
+	case entry.Link != "" || entry.Unpacked:
+		lCtx.Logger().V(3).Info("skipping symlink or unpacked file")
+		return nil
+	case int(entry.Size) > maxSize:
+		lCtx.Logger().V(2).Info("skipping file: size exceeds max allowed", "size", entry.Size, "limit", maxSize)
+		h.metrics.incFilesSkipped()
+		return nil
+	case SkipFile(ctx, entryPath) || common.IsBinary(entryPath):
+		lCtx.Logger().V(2).Info("skipping file: extension is ignored")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+
+	offset, err := strconv.ParseInt(entry.Offset, 10, 64)
+	if err != nil || offset < 0 || entry.Size < 0 {
+		return fmt.Errorf("invalid offset %q or size %d", entry.Offset, entry.Size)
+	}
+
+	rdr, err := newFileReader(io.NewSectionReader(input, base+offset, entry.Size))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			lCtx.Logger().V(5).Info("empty reader, skipping file")
+			return nil
+		}
+		return fmt.Errorf("error creating custom reader: %w", err)
+	}
+	defer rdr.Close()
+
+	h.metrics.incFilesProcessed()
+	h.metrics.observeFileSize(entry.Size)
+
+	return h.openArchive(withContainer(lCtx, "asar", entryPath), 1, rdr, dataChan)
+}
+
+// readASARHeader returns the root of the header of the asar archive input,
+// and the offset its files start at.
+func readASARHeader(input fileReader) (asarEntry, int64, error) {
+	var sizes [16]byte
+	if _, err := input.ReadAt(sizes[:], 0); err != nil {
+		return asarEntry{}, 0, fmt.Errorf("error reading header size: %w", err)
+	}
+	headerSize := int64(binary.LittleEndian.Uint32(sizes[4:8]))
+	jsonSize := int64(binary.LittleEndian.Uint32(sizes[12:16]))
+	if binary.LittleEndian.Uint32(sizes[0:4]) != 4 || jsonSize > headerSize || jsonSize > maxASARHeaderSize {
+		return asarEntry{}, 0, fmt.Errorf("invalid asar header")
+	}
+
+	header := make([]byte, jsonSize)
+	if _, err := input.ReadAt(header, 16); err != nil {
+		return asarEntry{}, 0, fmt.Errorf("error reading header: %w", err)
+	}
+	var root asarEntry
+	if err := json.Unmarshal(header, &root); err != nil {
+		return asarEntry{}, 0, fmt.Errorf("error parsing header: %w", err)
+	}
+	return root, 8 + headerSize, nil
+}
+
+// isASAR matches asar archives by their header: the size of the first
+// pickle, and the start of the JSON of the second.
+func isASAR(raw []byte, _ uint32) bool {
+	return len(raw) > 16 && binary.LittleEndian.Uint32(raw[0:4]) == 4 &&
+		bytes.HasPrefix(raw[16:], []byte(`{"files":`))
+}
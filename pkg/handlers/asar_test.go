@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestHandleASARFile(t *testing.T) {
+	file, err := os.Open("testdata/test.asar")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rdr, err := newFileReader(file)
+	assert.NoError(t, err)
+	defer rdr.Close()
+	assert.Equal(t, asarMime, mimeType(rdr.mime.String()))
+
+	handler := newASARHandler()
+	archiveChan, err := handler.HandleFile(context.AddLogger(ctx), rdr)
+	assert.NoError(t, err)
+
+	// Symlinks and unpacked files aren't in the archive, and nested archives
+	// are extracted.
+	var paths []string
+	var content strings.Builder
+	for data := range archiveChan {
+		var levels []string
+		for _, c := range data.provenance {
+			levels = append(levels, c.GetFormat()+":"+c.GetPath())
+		}
+		paths = append(paths, strings.Join(levels, " "))
+		content.Write(data.data)
+	}
+	assert.Equal(t, []string{
+		"asar:lib/bundle.zip zip:nested.js",
+		"asar:lib/config.js",
+		"asar:main.js",
+		"asar:package.json",
+	}, paths)
+	assert.Contains(t, content.String(), "sk_live_example_token_1234567890")
+	assert.Contains(t, content.String(), "nested-token")
+}
+
+func TestReadASARHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "wrong first pickle", header: "\x08\x00\x00\x00\x10\x00\x00\x00\x0c\x00\x00\x00\x02\x00\x00\x00{}"},
+		{name: "json longer than header", header: "\x04\x00\x00\x00\x08\x00\x00\x00\x04\x00\x00\x00\xff\x00\x00\x00{}"},
+		{name: "truncated", header: "\x04\x00\x00\x00"},
+		{name: "invalid json", header: "\x04\x00\x00\x00\x0c\x00\x00\x00\x08\x00\x00\x00\x02\x00\x00\x00{x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdr, err := newFileReader(strings.NewReader(tt.header))
+			assert.NoError(t, err)
+			defer rdr.Close()
+
+			_, _, err = readASARHeader(rdr)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// avroHandler handles Avro object container files, reading the string values
+// of their records.
+type avroHandler struct{ *defaultHandler }
+
+// newAvroHandler creates an avroHandler.
+func newAvroHandler() *avroHandler {
+	return &avroHandler{defaultHandler: newDefaultHandler(avroHandlerType)}
+}
+
+// HandleFile reads the string values of the first records of an Avro file.
+// The records of Avro files aren't sampled across the file, as there's no
+// telling how many blocks of them it has before reading it to the end.
+func (h *avroHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var ocfr *goavro.OCFReader
+		if ocfr, err = goavro.NewOCFReader(input); err != nil {
+			ctx.Logger().Error(err, "error reading Avro file")
+			return
+		}
+		var schema avroSchema
+		if schema, err = parseAvroSchema(ocfr.Codec().Schema()); err != nil {
+			ctx.Logger().Error(err, "error reading Avro schema")
+			return
+		}
+
+		if err = h.handleRows(ctx, "avro", func(w *rowWriter) error { return writeAvroRows(ocfr, schema, columnarMaxRows(ctx), w) }, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling Avro file")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+func writeAvroRows(ocfr *goavro.OCFReader, schema avroSchema, maxRows int, w *rowWriter) error {
+	for rows := 0; rows < maxRows && ocfr.Scan(); rows++ {
+		datum, err := ocfr.Read()
+		if err != nil {
+			return err
+		}
+		if err := schema.write(w, "", schema.root, datum); err != nil {
+			return err
+		}
+		if err := w.endRow(); err != nil {
+			return err
+		}
+	}
+	return ocfr.Err()
+}
+
+// avroSchema is the schema of the records of an Avro file, which the values
+// decoded from it are walked along: unions are decoded as maps from the name
+// of the type of their value to it, which the schema tells apart from the
+// records and the maps of the file.
+type avroSchema struct {
+	root any
+	// named are the named types of the schema, by their names and their full
+	// names.
+	named map[string]avroNamed
+}
+
+// avroNamed is the definition of a named type, a record, an enum or a fixed.
+type avroNamed struct {
+	def      map[string]any
+	fullName string
+}
+
+func parseAvroSchema(schema string) (avroSchema, error) {
+	s := avroSchema{named: make(map[string]avroNamed)}
+	if err := json.Unmarshal([]byte(schema), &s.root); err != nil {
+		return s, fmt.Errorf("error parsing schema: %w", err)
+	}
+	s.define(s.root, "")
+	return s, nil
+}
+
+// define adds the named types defined in a schema, whose enclosing namespace
+// is namespace.
+func (s avroSchema) define(schema any, namespace string) {
+	switch t := schema.(type) {
+	case []any:
+		for _, member := range t {
+			s.define(member, namespace)
+		}
+	case map[string]any:
+		if name, ok := t["name"].(string); ok {
+			if ns, ok := t["namespace"].(string); ok {
+				namespace = ns
+			}
+			full := name
+			if !strings.Contains(name, ".") && namespace != "" {
+				full = namespace + "." + name
+			}
+			s.named[name] = avroNamed{def: t, fullName: full}
+			s.named[full] = s.named[name]
+			if i := strings.LastIndex(full, "."); i >= 0 {
+				namespace = full[:i]
+			}
+		}
+		if fields, ok := t["fields"].([]any); ok {
+			for _, f := range fields {
+				if field, ok := f.(map[string]any); ok {
+					s.define(field["type"], namespace)
+				}
+			}
+		}
+		s.define(t["items"], namespace)
+		s.define(t["values"], namespace)
+		if _, ok := t["type"].(string); !ok {
+			s.define(t["type"], namespace)
+		}
+	}
+}
+
+// write writes the string values of datum, whose schema is schema, named
+// after the fields and map keys they're in.
+func (s avroSchema) write(w *rowWriter, name string, schema, datum any) error {
+	if datum == nil {
+		return nil
+	}
+	switch t := schema.(type) {
+	case string:
+		if n, ok := s.named[t]; ok {
+			return s.write(w, name, n.def, datum)
+		}
+		if v, ok := datum.(string); ok && t == "string" {
+			return w.value(name, v)
+		}
+	case []any:
+		union, ok := datum.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for typeName, v := range union {
+			for _, member := range t {
+				if s.typeName(member) == typeName {
+					return s.write(w, name, member, v)
+				}
+			}
+		}
+	case map[string]any:
+		switch t["type"] {
+		case "record", "error":
+			record, ok := datum.(map[string]any)
+			if !ok {
+				return nil
+			}
+			fields, _ := t["fields"].([]any)
+			for _, f := range fields {
+				field, ok := f.(map[string]any)
+				if !ok {
+					continue
+				}
+				fieldName, _ := field["name"].(string)
+				if err := s.write(w, joinColumn(name, fieldName), field["type"], record[fieldName]); err != nil {
+					return err
+				}
+			}
+		case "array":
+			items, _ := datum.([]any)
+			for _, item := range items {
+				if err := s.write(w, name, t["items"], item); err != nil {
+					return err
+				}
+			}
+		case "map":
+			values, _ := datum.(map[string]any)
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			for _, key := range keys {
+				if err := s.write(w, joinColumn(name, key), t["values"], values[key]); err != nil {
+					return err
+				}
+			}
+		case "enum", "fixed":
+		default:
+			// A primitive type with attributes, such as a logical type.
+			return s.write(w, name, t["type"], datum)
+		}
+	}
+	return nil
+}
+
+// typeName returns the name a union decodes a value of schema under: the full
+// name of named types, and the name of the type of others.
+func (s avroSchema) typeName(schema any) string {
+	switch t := schema.(type) {
+	case string:
+		if n, ok := s.named[t]; ok {
+			return n.fullName
+		}
+		return t
+	case map[string]any:
+		if name, ok := t["name"].(string); ok {
+			if n, ok := s.named[name]; ok {
+				return n.fullName
+			}
+			return name
+		}
+		name, _ := t["type"].(string)
+		return name
+	default:
+		return ""
+	}
+}
+
+func joinColumn(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
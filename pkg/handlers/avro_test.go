@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestHandleAvroFile(t *testing.T) {
+	const schema = `{
+  "type": "record",
+  "name": "Deployment",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "api_key", "type": ["null", "string"]},
+    {"name": "hosts", "type": {"type": "array", "items": "string"}},
+    {"name": "env", "type": {"type": "map", "values": "string"}},
+    {"name": "db", "type": ["null", {
+      "type": "record",
+      "name": "Database",
+      "fields": [{"name": "password", "type": "string"}, {"name": "port", "type": "int"}]
+    }]},
+    {"name": "replica", "type": ["null", "Database"]}
+  ]
+}`
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: schema, CompressionName: goavro.CompressionDeflateLabel})
+	require.NoError(t, err)
+	require.NoError(t, w.Append([]any{
+		map[string]any{
+			"id":      int64(1),
+			"api_key": goavro.Union("string", "sk_live_avro_token_1"),
+			"hosts":   []any{"a.example.com", "b.example.com"},
+			"env":     map[string]any{"TOKEN": "env-token", "DEBUG": "1"},
+			"db":      goavro.Union("com.example.Database", map[string]any{"password": "db-password", "port": int32(5432)}),
+			"replica": nil,
+		},
+		map[string]any{
+			"id":      int64(2),
+			"api_key": nil,
+			"hosts":   []any{},
+			"env":     map[string]any{},
+			"db":      nil,
+			"replica": goavro.Union("com.example.Database", map[string]any{"password": "replica-password", "port": int32(5433)}),
+		},
+	}))
+
+	assert.Equal(t,
+		"api_key: sk_live_avro_token_1\nhosts: a.example.com\nhosts: b.example.com\nenv.DEBUG: 1\nenv.TOKEN: env-token\ndb.password: db-password\n\n"+
+			"replica.password: replica-password\n\n",
+		handleRowsFile(t, buf.Bytes(), avroMime, "avro"))
+
+	ctx := WithColumnarMaxRows(context.Background(), 1)
+	assert.NotContains(t, handleRowsFileContext(t, ctx, buf.Bytes(), avroMime, "avro"), "replica-password")
+}
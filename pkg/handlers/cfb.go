@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// cfbMagic starts the header of compound files.
+var cfbMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+const (
+	cfbEndOfChain = 0xFFFFFFFE
+
+	cfbStorage = 1
+	cfbStream  = 2
+)
+
+// compoundFile is a Microsoft compound file, the file system in a file of
+// Outlook messages and legacy Office documents. Its storages and streams are
+// directory entries, and its content is in sectors chained by a FAT. Streams
+// shorter than the cutoff are in the mini stream, in mini sectors chained by
+// the mini FAT.
+//
+// See https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-cfb.
+type compoundFile struct {
+	r          io.ReaderAt
+	sectorSize int64
+	cutoff     uint64
+	fat        []uint32
+	miniFAT    []uint32
+	miniStream []byte
+	entries    []cfbEntry
+}
+
+// cfbEntry is a storage or a stream of a compound file. The entries of a
+// storage are a tree, whose root is its child, of their siblings.
+type cfbEntry struct {
+	name               string
+	kind               byte
+	left, right, child uint32
+	start              uint32
+	size               uint64
+}
+
+// openCompoundFile reads the FATs and the directory of the compound file r
+// of the given size.
+func openCompoundFile(r io.ReaderAt, size int64) (*compoundFile, error) {
+	var header [512]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return nil, fmt.Errorf("error reading header: %w", err)
+	}
+	if !bytes.Equal(header[:8], cfbMagic) {
+		return nil, fmt.Errorf("not a compound file")
+	}
+	shift, miniShift := binary.LittleEndian.Uint16(header[30:32]), binary.LittleEndian.Uint16(header[32:34])
+	if (shift != 9 && shift != 12) || miniShift != 6 {
+		return nil, fmt.Errorf("unsupported sector size")
+	}
+
+	cf := &compoundFile{
+		r:          r,
+		sectorSize: 1 << shift,
+		cutoff:     uint64(binary.LittleEndian.Uint32(header[56:60])),
+	}
+	numFAT := int64(binary.LittleEndian.Uint32(header[44:48]))
+	if numFAT*cf.sectorSize > size {
+		return nil, fmt.Errorf("FAT is longer than the file")
+	}
+
+	// The sectors of the FAT are listed by the DIFAT, which starts in the
+	// header and continues in a chain of its own sectors.
+	var difat []uint32
+	for i := 76; i < len(header); i += 4 {
+		difat = append(difat, binary.LittleEndian.Uint32(header[i:]))
+	}
+	next := binary.LittleEndian.Uint32(header[68:72])
+	for n := 0; next < cfbEndOfChain && int64(len(difat)) < numFAT; n++ {
+		if int64(n)*cf.sectorSize > size {
+			return nil, fmt.Errorf("DIFAT has a cycle")
+		}
+		sector, err := cf.sector(next)
+		if err != nil {
+			return nil, err
+		}
+		ids := sectorIDs(sector)
+		difat, next = append(difat, ids[:len(ids)-1]...), ids[len(ids)-1]
+	}
+	if int64(len(difat)) < numFAT {
+		return nil, fmt.Errorf("DIFAT is truncated")
+	}
+	for _, id := range difat[:numFAT] {
+		sector, err := cf.sector(id)
+		if err != nil {
+			return nil, err
+		}
+		cf.fat = append(cf.fat, sectorIDs(sector)...)
+	}
+
+	dir, err := cf.chain(binary.LittleEndian.Uint32(header[48:52]), uint64(size))
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	for i := 0; i+128 <= len(dir); i += 128 {
+		e := parseCFBEntry(dir[i : i+128])
+		if shift == 9 {
+			// Version 3 files may leave garbage in the high half of sizes.
+			e.size &= 0xFFFFFFFF
+		}
+		cf.entries = append(cf.entries, e)
+	}
+	if len(cf.entries) == 0 {
+		return nil, fmt.Errorf("directory is empty")
+	}
+
+	if n := binary.LittleEndian.Uint32(header[64:68]); n > 0 {
+		miniFAT, err := cf.chain(binary.LittleEndian.Uint32(header[60:64]), uint64(n)*uint64(cf.sectorSize))
+		if err != nil {
+			return nil, fmt.Errorf("error reading mini FAT: %w", err)
+		}
+		cf.miniFAT = sectorIDs(miniFAT)
+	}
+	// The mini stream is the stream of the root entry.
+	if root := cf.entries[0]; root.size > 0 {
+		if cf.miniStream, err = cf.chain(root.start, root.size); err != nil {
+			return nil, fmt.Errorf("error reading mini stream: %w", err)
+		}
+	}
+	return cf, nil
+}
+
+func parseCFBEntry(raw []byte) cfbEntry {
+	nameLen := min(int(binary.LittleEndian.Uint16(raw[64:66])), 64)
+	name := make([]uint16, 0, nameLen/2)
+	for i := 0; i+1 < nameLen; i += 2 {
+		if c := binary.LittleEndian.Uint16(raw[i:]); c != 0 {
+			name = append(name, c)
+		}
+	}
+	return cfbEntry{
+		name:  string(utf16.Decode(name)),
+		kind:  raw[66],
+		left:  binary.LittleEndian.Uint32(raw[68:72]),
+		right: binary.LittleEndian.Uint32(raw[72:76]),
+		child: binary.LittleEndian.Uint32(raw[76:80]),
+		start: binary.LittleEndian.Uint32(raw[116:120]),
+		size:  binary.LittleEndian.Uint64(raw[120:128]),
+	}
+}
+
+// children returns the indexes of the entries of the storage at index i.
+func (cf *compoundFile) children(i int) []int {
+	var (
+		ids  []int
+		seen = make(map[uint32]bool)
+		walk func(id uint32)
+	)
+	walk = func(id uint32) {
+		if int(id) >= len(cf.entries) || seen[id] {
+			return
+		}
+		seen[id] = true
+		walk(cf.entries[id].left)
+		ids = append(ids, int(id))
+		walk(cf.entries[id].right)
+	}
+	walk(cf.entries[i].child)
+	return ids
+}
+
+// stream returns the content of the stream at index i.
+func (cf *compoundFile) stream(i int) ([]byte, error) {
+	e := cf.entries[i]
+	if e.size > uint64(maxSize) {
+		return nil, fmt.Errorf("stream %q exceeds max size", e.name)
+	}
+	if e.size >= cf.cutoff {
+		return cf.chain(e.start, e.size)
+	}
+
+	data := make([]byte, 0, e.size)
+	for id, n := e.start, 0; id < cfbEndOfChain && uint64(len(data)) < e.size; n++ {
+		off := int(id) * 64
+		if int(id) >= len(cf.miniFAT) || off+64 > len(cf.miniStream) || n > len(cf.miniFAT) {
+			return nil, fmt.Errorf("stream %q has an invalid mini sector chain", e.name)
+		}
+		data = append(data, cf.miniStream[off:off+64]...)
+		id = cf.miniFAT[id]
+	}
+	if uint64(len(data)) < e.size {
+		return nil, fmt.Errorf("stream %q is truncated", e.name)
+	}
+	return data[:e.size], nil
+}
+
+// chain returns up to size bytes of the sectors chained from start.
+func (cf *compoundFile) chain(start uint32, size uint64) ([]byte, error) {
+	var data []byte
+	for id, n := start, 0; id < cfbEndOfChain && uint64(len(data)) < size; n++ {
+		if int(id) >= len(cf.fat) || n > len(cf.fat) {
+			return nil, fmt.Errorf("invalid sector chain")
+		}
+		sector, err := cf.sector(id)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, sector...)
+		id = cf.fat[id]
+	}
+	if uint64(len(data)) > size {
+		data = data[:size]
+	}
+	return data, nil
+}
+
+func (cf *compoundFile) sector(id uint32) ([]byte, error) {
+	sector := make([]byte, cf.sectorSize)
+	n, err := cf.r.ReadAt(sector, (int64(id)+1)*cf.sectorSize)
+	// The last sector may be cut short.
+	if err != nil && !(errors.Is(err, io.EOF) && n > 0) {
+		return nil, fmt.Errorf("error reading sector %d: %w", id, err)
+	}
+	return sector, nil
+}
+
+func sectorIDs(sector []byte) []uint32 {
+	ids := make([]uint32, len(sector)/4)
+	for i := range ids {
+		ids[i] = binary.LittleEndian.Uint32(sector[i*4:])
+	}
+	return ids
+}
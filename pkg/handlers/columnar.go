@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// magicPrefix matches the files that start with magic, as columnar data
+// files and minidumps do.
+func magicPrefix(magic string) func([]byte, uint32) bool {
+	return func(raw []byte, _ uint32) bool { return bytes.HasPrefix(raw, []byte(magic)) }
+}
+
+// defaultColumnarMaxRows is the maximum number of rows read from a columnar
+// data file unless WithColumnarMaxRows sets another. The tables of data lakes
+// are far larger than what can be scanned of them, and a column that holds
+// secrets in some rows is likely to hold them in the rows that are read.
+const defaultColumnarMaxRows = 10_000
+
+// columnarMaxRowsKey is the context key of the limit set by
+// WithColumnarMaxRows.
+type columnarMaxRowsKey struct{}
+
+// WithColumnarMaxRows returns a copy of ctx in which at most rows rows are
+// read from Parquet, Avro, ORC and SQLite files. A limit of 0 keeps the
+// default. The limit is scoped to the context like WithOCR.
+func WithColumnarMaxRows(ctx logContext.Context, rows int) logContext.Context {
+	return logContext.WithValue(ctx, columnarMaxRowsKey{}, rows)
+}
+
+// columnarMaxRows returns the maximum number of rows read from a columnar
+// data file in ctx.
+func columnarMaxRows(ctx logContext.Context) int {
+	if rows, ok := ctx.Value(columnarMaxRowsKey{}).(int); ok && rows > 0 {
+		return rows
+	}
+	return defaultColumnarMaxRows
+}
+
+// rowsPerGroup returns the number of rows read from each of the row groups of
+// a file when at most maxRows are, which are sampled evenly: files are often
+// appended to a group at a time, so the groups written last may hold secrets
+// the first don't.
+func rowsPerGroup(maxRows, groups int) int {
+	if groups <= 1 {
+		return maxRows
+	}
+	return max(1, (maxRows+groups-1)/groups)
+}
+
+// columnValue is a value of a row and the name of its column.
+type columnValue struct{ column, value string }
+
+// rowWriter writes the string values of the rows of a columnar data file as
+// text, each on a line after the name of its column, so the detectors that
+// look for a keyword before a secret find column names such as api_key.
+type rowWriter struct {
+	w       *bufio.Writer
+	written bool
+}
+
+// value writes the value of a column of the current row.
+func (w *rowWriter) value(column, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	w.written = true
+	if _, err := w.w.WriteString(column); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(": "); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(value); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// endRow separates the values of the current row from those of the next.
+func (w *rowWriter) endRow() error {
+	if !w.written {
+		return nil
+	}
+	w.written = false
+	return w.w.WriteByte('\n')
+}
+
+// handleRows handles the text of the rows write writes, as the content of a
+// file of a format, while they're being read from it.
+func (h *defaultHandler) handleRows(
+	ctx logContext.Context,
+	format string,
+	write func(*rowWriter) error,
+	dataChan chan handledData,
+) error {
+	pr, pw := io.Pipe()
+	go func() {
+		w := &rowWriter{w: bufio.NewWriter(pw)}
+		err := write(w)
+		if err == nil {
+			err = w.w.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	// Stop the writer if the rows aren't all read.
+	defer pr.Close()
+
+	rdr, err := newMimeTypeReader(pr)
+	if err != nil {
+		return err
+	}
+	return h.handleNonArchiveContent(withContainer(ctx, format, ""), rdr, dataChan)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// minDumpStringLen is the number of characters below which the runs of
+	// printable characters in a memory dump are taken for noise.
+	minDumpStringLen = 6
+	// maxDumpStringLen is the number of characters above which a run is
+	// split, so a page of text doesn't make a single line.
+	maxDumpStringLen = 4096
+)
+
+// coreDumpHandler handles the memory captured from crashed processes, as
+// ELF core dumps and Windows minidumps, reading the strings in it.
+type coreDumpHandler struct{ *defaultHandler }
+
+// newCoreDumpHandler creates a coreDumpHandler.
+func newCoreDumpHandler() *coreDumpHandler {
+	return &coreDumpHandler{defaultHandler: newDefaultHandler(coreDumpHandlerType)}
+}
+
+// HandleFile extracts the strings of a memory dump, as the strings binutil
+// does, each on a line after its offset in the dump. Unlike the extraction
+// the UTF-8 decoder falls back to, strings are kept apart from each other,
+// and those in UTF-16, as Windows processes hold theirs, are extracted too.
+func (h *coreDumpHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	format := "core"
+	if mimeType(input.mime.String()) == minidumpMime {
+		format = "minidump"
+	}
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		write := func(w *rowWriter) error { return writeDumpStrings(bufio.NewReader(input), w) }
+		if err = h.handleRows(ctx, format, write, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling memory dump")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// writeDumpStrings writes the runs of printable ASCII characters of r, and
+// those of UTF-16LE characters in the ASCII range, at the hexadecimal
+// offsets they start at.
+func writeDumpStrings(r io.ByteReader, w *rowWriter) error {
+	var (
+		ascii dumpString
+		// A UTF-16 character is a printable byte followed by a zero byte,
+		// and the runs of them start at even and at odd offsets.
+		utf16 [2]dumpString
+		prev  byte
+	)
+	for offset := int64(0); ; offset++ {
+		c, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			for _, s := range []*dumpString{&ascii, &utf16[0], &utf16[1]} {
+				if err := s.flush(w); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := ascii.add(w, offset, c, isDumpChar(c)); err != nil {
+			return err
+		}
+		if offset > 0 {
+			if err := utf16[(offset-1)%2].add(w, offset-1, prev, isDumpChar(prev) && c == 0); err != nil {
+				return err
+			}
+		}
+		prev = c
+	}
+}
+
+func isDumpChar(c byte) bool { return c == '\t' || c >= 0x20 && c <= 0x7e }
+
+// dumpString is a run of the characters of a memory dump being read.
+type dumpString struct {
+	start int64
+	chars []byte
+}
+
+// add adds the character c at offset to the run, if ok, or else ends the
+// run.
+func (s *dumpString) add(w *rowWriter, offset int64, c byte, ok bool) error {
+	if !ok {
+		return s.flush(w)
+	}
+	if len(s.chars) == 0 {
+		s.start = offset
+	}
+	s.chars = append(s.chars, c)
+	if len(s.chars) == maxDumpStringLen {
+		return s.flush(w)
+	}
+	return nil
+}
+
+// flush writes the run, if it's long enough, and starts the next one.
+func (s *dumpString) flush(w *rowWriter) error {
+	defer func() { s.chars = s.chars[:0] }()
+	if len(s.chars) < minDumpStringLen {
+		return nil
+	}
+	return w.value("0x"+strconv.FormatInt(s.start, 16), string(s.chars))
+}
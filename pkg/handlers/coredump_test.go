@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// utf16LE encodes ASCII text in UTF-16LE.
+func utf16LE(s string) []byte {
+	b := make([]byte, 0, 2*len(s))
+	for _, c := range []byte(s) {
+		b = append(b, c, 0)
+	}
+	return b
+}
+
+func TestHandleCoreDumpFile(t *testing.T) {
+	// The header of an ELF core dump of a 64-bit little-endian process.
+	header := make([]byte, 64)
+	copy(header, "\x7fELF\x02\x01\x01")
+	binary.LittleEndian.PutUint16(header[16:], 4)
+
+	var dump bytes.Buffer
+	dump.Write(header)
+	dump.WriteString("\x00\x01DB_PASSWORD=hunter2hunter2\x00abc\x00")
+	dump.WriteString("\x05")
+	dump.Write(utf16LE("token=ghp_utf16_token"))
+	dump.WriteString("\xff\xfe")
+	dump.WriteString("split\x00apart\x00strings")
+
+	assert.Equal(t,
+		"0x42: DB_PASSWORD=hunter2hunter2\n"+
+			"0x62: token=ghp_utf16_token\n"+
+			"0x9a: strings\n",
+		handleRowsFile(t, dump.Bytes(), coreDumpMime, "core"))
+}
+
+func TestHandleMinidumpFile(t *testing.T) {
+	var dump bytes.Buffer
+	dump.WriteString("MDMP\x93\xa7\x00\x00")
+	dump.Write(make([]byte, 24))
+	// A string at an odd offset.
+	dump.WriteString("\x01")
+	dump.Write(utf16LE(`C:\Users\svc\.aws\credentials`))
+
+	assert.Equal(t,
+		"0x21: C:\\Users\\svc\\.aws\\credentials\n",
+		handleRowsFile(t, dump.Bytes(), minidumpMime, "minidump"))
+}
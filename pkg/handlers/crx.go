@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mholt/archiver/v4"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/feature"
+)
+
+// crxMagic starts the header of Chrome extensions.
+var crxMagic = []byte("Cr24")
+
+// crxHandler handles Chrome extensions, which are zip archives behind a
+// header that holds the keys and signatures of the extension.
+type crxHandler struct{ *archiveHandler }
+
+// newCRXHandler creates a crxHandler.
+func newCRXHandler() *crxHandler {
+	return &crxHandler{archiveHandler: &archiveHandler{defaultHandler: newDefaultHandler(crxHandlerType)}}
+}
+
+// HandleFile extracts the zip archive of a Chrome extension, handling its
+// entries as those of any other zip archive.
+func (h *crxHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	if feature.ForceSkipArchives.Load() {
+		close(dataChan)
+		return dataChan, nil
+	}
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var zipReader *io.SectionReader
+		zipReader, err = crxZip(input)
+		if err != nil {
+			ctx.Logger().Error(err, "error reading CRX header")
+			return
+		}
+
+		err = archiver.Zip{}.Extract(logContext.WithValue(ctx, depthKey, 1), zipReader, nil, h.extractorHandler("crx", dataChan))
+		if err != nil {
+			ctx.Logger().Error(err, "error extracting CRX archive")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// crxZip returns the zip archive of the Chrome extension input. Version 2
+// headers hold a public key and a signature, and version 3 headers hold a
+// protobuf of the length they're prefixed with.
+func crxZip(input fileReader) (*io.SectionReader, error) {
+	var header [16]byte
+	if _, err := input.ReadAt(header[:], 0); err != nil {
+		return nil, fmt.Errorf("error reading header: %w", err)
+	}
+	if !bytes.Equal(header[:4], crxMagic) {
+		return nil, fmt.Errorf("not a CRX file")
+	}
+
+	var offset int64
+	switch version := binary.LittleEndian.Uint32(header[4:8]); version {
+	case 2:
+		offset = 16 + int64(binary.LittleEndian.Uint32(header[8:12])) + int64(binary.LittleEndian.Uint32(header[12:16]))
+	case 3:
+		offset = 12 + int64(binary.LittleEndian.Uint32(header[8:12]))
+	default:
+		return nil, fmt.Errorf("unsupported CRX version %d", version)
+	}
+
+	size, err := input.Size()
+	if err != nil {
+		return nil, fmt.Errorf("error getting file size: %w", err)
+	}
+	if offset > size {
+		return nil, fmt.Errorf("CRX header is longer than the file")
+	}
+	return io.NewSectionReader(input, offset, size-offset), nil
+}
+
+// isCRX3 matches the version 3 Chrome extensions the mimetype package
+// doesn't, which have replaced version 2.
+func isCRX3(raw []byte, _ uint32) bool {
+	return len(raw) >= 12 && bytes.HasPrefix(raw, crxMagic) && binary.LittleEndian.Uint32(raw[4:8]) == 3
+}
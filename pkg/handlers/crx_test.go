@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestHandleCRXFile(t *testing.T) {
+	file, err := os.Open("testdata/test.crx")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rdr, err := newFileReader(file)
+	assert.NoError(t, err)
+	defer rdr.Close()
+	assert.Equal(t, crxMime, mimeType(rdr.mime.String()))
+
+	handler := newCRXHandler()
+	archiveChan, err := handler.HandleFile(context.AddLogger(ctx), rdr)
+	assert.NoError(t, err)
+
+	// The icon is skipped as binary.
+	var paths []string
+	var content strings.Builder
+	for data := range archiveChan {
+		paths = append(paths, data.provenance[len(data.provenance)-1].GetPath())
+		content.Write(data.data)
+	}
+	assert.Equal(t, []string{"manifest.json", "background.js"}, paths)
+	assert.Contains(t, content.String(), "sk_live_example_token_1234567890")
+}
+
+func TestCRXZip(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{name: "version 2", header: "Cr24\x02\x00\x00\x00\x03\x00\x00\x00\x03\x00\x00\x00keysig"},
+		{name: "version 3", header: "Cr24\x03\x00\x00\x00\x04\x00\x00\x00head"},
+		{name: "unknown version", header: "Cr24\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", wantErr: true},
+		{name: "header past the end", header: "Cr24\x03\x00\x00\x00\xff\x00\x00\x00\x00\x00\x00\x00", wantErr: true},
+		{name: "not a CRX", header: "PK\x03\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdr, err := newFileReader(strings.NewReader(tt.header + "zipdata"))
+			assert.NoError(t, err)
+			defer rdr.Close()
+
+			zip, err := crxZip(rdr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			data := make([]byte, zip.Size())
+			_, err = zip.ReadAt(data, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, "zipdata", string(data))
+		})
+	}
+}
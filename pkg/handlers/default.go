@@ -8,6 +8,7 @@ import (
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ocr"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -30,9 +31,9 @@ func newDefaultHandler(handlerType handlerType) *defaultHandler {
 // utilizing a single output channel. It first tries to identify the input as an archive. If it is an archive,
 // it processes it accordingly; otherwise, it handles the input as non-archive content.
 // The function returns a channel that will receive the extracted data bytes and an error if the initial setup fails.
-func (h *defaultHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
+func (h *defaultHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
 	// Shared channel for both archive and non-archive content.
-	dataChan := make(chan []byte, defaultBufferSize)
+	dataChan := make(chan handledData, defaultBufferSize)
 
 	go func() {
 		defer close(dataChan)
@@ -72,9 +73,12 @@ func (h *defaultHandler) measureLatencyAndHandleErrors(start time.Time, err erro
 // on the type, particularly for binary files. It manages reading file chunks and writing them to the archive channel,
 // effectively collecting the final bytes for further processing. This function is a key component in ensuring that all
 // file content, regardless of being an archive or not, is handled appropriately.
-func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader mimeTypeReader, archiveChan chan []byte) error {
+func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader mimeTypeReader, archiveChan chan handledData) error {
 	mimeExt := reader.mimeExt
 
+	if scanImages(ctx) && ocr.IsImage(string(reader.mimeName)) {
+		return h.handleImage(ctx, reader, archiveChan)
+	}
 	if common.SkipFile(mimeExt) || common.IsBinary(mimeExt) {
 		ctx.Logger().V(2).Info("skipping file: extension is ignored", "ext", mimeExt)
 		h.metrics.incFilesSkipped()
@@ -83,6 +87,7 @@ func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader
 		return nil
 	}
 
+	provenance := provenanceOf(ctx)
 	chunkReader := sources.NewChunkReader()
 	for data := range chunkReader(ctx, reader) {
 		if err := data.Error(); err != nil {
@@ -91,7 +96,7 @@ func (h *defaultHandler) handleNonArchiveContent(ctx logContext.Context, reader
 			continue
 		}
 
-		if err := common.CancellableWrite(ctx, archiveChan, data.Bytes()); err != nil {
+		if err := common.CancellableWrite(ctx, archiveChan, handledData{data: data.Bytes(), provenance: provenance}); err != nil {
 			return err
 		}
 		h.metrics.incBytesProcessed(len(data.Bytes()))
@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/mholt/archiver/v4"
+	"google.golang.org/protobuf/proto"
 
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/iobuf"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -126,9 +130,45 @@ func newFileReader(r io.Reader) (fileReader, error) {
 
 // FileHandler represents a handler for files.
 // It has a single method, HandleFile, which takes a context and a fileReader as input,
-// and returns a channel of byte slices and an error.
+// and returns a channel of the handled data and an error.
 type FileHandler interface {
-	HandleFile(ctx logContext.Context, reader fileReader) (chan []byte, error)
+	HandleFile(ctx logContext.Context, reader fileReader) (chan handledData, error)
+}
+
+// handledData is content read by a FileHandler, along with the containers it
+// was extracted from, outermost first.
+type handledData struct {
+	data       []byte
+	provenance []*source_metadatapb.Container
+}
+
+// withContainer returns a context for the content of an entry at path in a
+// container of the given format. Decompressed content has no path.
+func withContainer(ctx logContext.Context, format, path string) logContext.Context {
+	return withLevel(ctx, &source_metadatapb.Container{
+		Format: strings.TrimPrefix(format, "."),
+		Path:   path,
+	})
+}
+
+// withMessagePart returns a context for the content of the numbered MIME
+// part of an email message of the given format. Only attachments have a
+// filename.
+func withMessagePart(ctx logContext.Context, format, filename, part string) logContext.Context {
+	return withLevel(ctx, &source_metadatapb.Container{Format: format, Path: filename, Part: part})
+}
+
+func withLevel(ctx logContext.Context, container *source_metadatapb.Container) logContext.Context {
+	parents := provenanceOf(ctx)
+	// The parents are capped so that siblings don't share the appended level.
+	provenance := append(parents[:len(parents):len(parents)], container)
+	return logContext.WithValue(ctx, provenanceKey, provenance)
+}
+
+// provenanceOf returns the containers the content handled with ctx is nested in.
+func provenanceOf(ctx context.Context) []*source_metadatapb.Container {
+	provenance, _ := ctx.Value(provenanceKey).([]*source_metadatapb.Container)
+	return provenance
 }
 
 // fileHandlingConfig encapsulates configuration settings that control the behavior of file processing.
@@ -154,10 +194,22 @@ func WithSkipArchives(skip bool) func(*fileHandlingConfig) {
 type handlerType string
 
 const (
-	archiveHandlerType handlerType = "archive"
-	arHandlerType      handlerType = "ar"
-	rpmHandlerType     handlerType = "rpm"
-	defaultHandlerType handlerType = "default"
+	archiveHandlerType  handlerType = "archive"
+	arHandlerType       handlerType = "ar"
+	rpmHandlerType      handlerType = "rpm"
+	crxHandlerType      handlerType = "crx"
+	asarHandlerType     handlerType = "asar"
+	emlHandlerType      handlerType = "eml"
+	msgHandlerType      handlerType = "msg"
+	parquetHandlerType  handlerType = "parquet"
+	avroHandlerType     handlerType = "avro"
+	orcHandlerType      handlerType = "orc"
+	sqliteHandlerType   handlerType = "sqlite"
+	sqlDumpHandlerType  handlerType = "sqldump"
+	coreDumpHandlerType handlerType = "coredump"
+	harHandlerType      handlerType = "har"
+	postmanHandlerType  handlerType = "postman"
+	defaultHandlerType  handlerType = "default"
 )
 
 type mimeType string
@@ -168,6 +220,20 @@ const (
 	unixArMime   mimeType = "application/x-unix-archive"
 	arMime       mimeType = "application/x-archive"
 	debMime      mimeType = "application/vnd.debian.binary-package"
+	crxMime      mimeType = "application/x-chrome-extension"
+	asarMime     mimeType = "application/x-asar"
+	emlMime      mimeType = "message/rfc822"
+	msgMime      mimeType = "application/vnd.ms-outlook"
+	oleMime      mimeType = "application/x-ole-storage"
+	parquetMime  mimeType = "application/vnd.apache.parquet"
+	avroMime     mimeType = "application/vnd.apache.avro"
+	orcMime      mimeType = "application/vnd.apache.orc"
+	sqliteMime   mimeType = "application/vnd.sqlite3"
+	sqlDumpMime  mimeType = "application/sql"
+	coreDumpMime mimeType = "application/x-coredump"
+	minidumpMime mimeType = "application/x-minidump"
+	harMime      mimeType = "application/har+json"
+	postmanMime  mimeType = "application/vnd.postman.collection+json"
 	textMime     mimeType = "text/plain; charset=utf-8"
 	xmlMime      mimeType = "text/xml"
 	jsonMime     mimeType = "application/json"
@@ -194,6 +260,24 @@ const (
 	tclMime      mimeType = "application/x-tcl"
 )
 
+func init() {
+	// The mimetype package doesn't know asar archives, nor the version 3
+	// Chrome extensions that have replaced version 2, nor columnar data
+	// files and minidumps, and takes email messages and SQL dumps for plain
+	// text, and HTTP archives and Postman collections for any JSON.
+	root := mimetype.Lookup("application/octet-stream")
+	root.Extend(isCRX3, string(crxMime), ".crx")
+	root.Extend(isASAR, string(asarMime), ".asar")
+	root.Extend(magicPrefix("PAR1"), string(parquetMime), ".parquet")
+	root.Extend(magicPrefix("Obj\x01"), string(avroMime), ".avro")
+	root.Extend(magicPrefix("ORC"), string(orcMime), ".orc")
+	root.Extend(magicPrefix("MDMP\x93\xa7"), string(minidumpMime), ".dmp")
+	mimetype.Lookup("text/plain").Extend(isEML, string(emlMime), ".eml")
+	mimetype.Lookup("text/plain").Extend(isSQLDump, string(sqlDumpMime), ".sql")
+	mimetype.Lookup("application/json").Extend(isHAR, string(harMime), ".har")
+	mimetype.Lookup("application/json").Extend(isPostmanCollection, string(postmanMime), ".json")
+}
+
 // skipArchiverMimeTypes is a set of MIME types that should bypass archiver library processing because they are either
 // text-based or archives not supported by the library.
 var skipArchiverMimeTypes = map[mimeType]struct{}{
@@ -202,6 +286,20 @@ var skipArchiverMimeTypes = map[mimeType]struct{}{
 	debMime:      {},
 	rpmMime:      {},
 	cpioMime:     {},
+	crxMime:      {},
+	asarMime:     {},
+	emlMime:      {},
+	msgMime:      {},
+	oleMime:      {},
+	parquetMime:  {},
+	avroMime:     {},
+	orcMime:      {},
+	sqliteMime:   {},
+	sqlDumpMime:  {},
+	coreDumpMime: {},
+	minidumpMime: {},
+	harMime:      {},
+	postmanMime:  {},
 	textMime:     {},
 	xmlMime:      {},
 	jsonMime:     {},
@@ -233,6 +331,16 @@ var skipArchiverMimeTypes = map[mimeType]struct{}{
 // This method uses specialized handlers for specific file types:
 // - arHandler is used for Unix archives and Debian packages ('arMime', 'unixArMime', and 'debMime').
 // - rpmHandler is used for RPM and CPIO archives ('rpmMime' and 'cpioMime').
+// - crxHandler is used for Chrome extensions ('crxMime').
+// - asarHandler is used for the asar archives of Electron apps ('asarMime').
+// - emlHandler is used for email messages in MIME format ('emlMime').
+// - msgHandler is used for Outlook messages and unknown compound files ('msgMime' and 'oleMime').
+// - parquetHandler, avroHandler and orcHandler are used for columnar data files ('parquetMime', 'avroMime' and 'orcMime').
+// - sqliteHandler is used for SQLite databases ('sqliteMime').
+// - sqlDumpHandler is used for the SQL dumps of MySQL, PostgreSQL and SQLite ('sqlDumpMime').
+// - coreDumpHandler is used for ELF core dumps and Windows minidumps ('coreDumpMime' and 'minidumpMime').
+// - harHandler is used for HTTP archives ('harMime').
+// - postmanHandler is used for exported Postman collections ('postmanMime').
 // - archiveHandler is used for common archive formats supported by the archiver library (.zip, .tar, .gz, etc.).
 // - defaultHandler is used for non-archive files.
 // The selected handler is then returned, ready to handle the file according to its specific format and requirements.
@@ -242,6 +350,32 @@ func selectHandler(mimeT mimeType, isGenericArchive bool) FileHandler {
 		return newARHandler()
 	case rpmMime, cpioMime:
 		return newRPMHandler()
+	case crxMime:
+		return newCRXHandler()
+	case asarMime:
+		return newASARHandler()
+	case emlMime:
+		return newEMLHandler()
+	case msgMime, oleMime:
+		// Outlook messages are only told apart from other compound files if
+		// their directory is near the start of the file.
+		return newMSGHandler()
+	case parquetMime:
+		return newParquetHandler()
+	case avroMime:
+		return newAvroHandler()
+	case orcMime:
+		return newORCHandler()
+	case sqliteMime:
+		return newSQLiteHandler()
+	case sqlDumpMime:
+		return newSQLDumpHandler()
+	case coreDumpMime, minidumpMime:
+		return newCoreDumpHandler()
+	case harMime:
+		return newHARHandler()
+	case postmanMime:
+		return newPostmanHandler()
 	default:
 		if isGenericArchive {
 			return newArchiveHandler()
@@ -326,7 +460,7 @@ func HandleFile(
 // is done. It returns true if all chunks are processed successfully, otherwise returns false on errors or cancellation.
 func handleChunks(
 	ctx logContext.Context,
-	handlerChan chan []byte,
+	handlerChan chan handledData,
 	chunkSkel *sources.Chunk,
 	reporter sources.ChunkReporter,
 ) error {
@@ -334,6 +468,11 @@ func handleChunks(
 		return fmt.Errorf("handler channel is nil")
 	}
 
+	// Chunks of the same entry share its metadata.
+	var (
+		innermost *source_metadatapb.Container
+		metadata  *source_metadatapb.MetaData
+	)
 	for {
 		select {
 		case data, open := <-handlerChan:
@@ -342,7 +481,14 @@ func handleChunks(
 				return nil
 			}
 			chunk := *chunkSkel
-			chunk.Data = data
+			chunk.Data = data.data
+			if n := len(data.provenance); n > 0 {
+				if data.provenance[n-1] != innermost {
+					innermost = data.provenance[n-1]
+					metadata = withProvenance(chunkSkel.SourceMetadata, data.provenance)
+				}
+				chunk.SourceMetadata = metadata
+			}
 			if err := reporter.ChunkOk(ctx, chunk); err != nil {
 				return fmt.Errorf("error reporting chunk: %w", err)
 			}
@@ -351,3 +497,14 @@ func handleChunks(
 		}
 	}
 }
+
+// withProvenance returns a copy of md that records the containers a chunk was
+// extracted from.
+func withProvenance(md *source_metadatapb.MetaData, provenance []*source_metadatapb.Container) *source_metadatapb.MetaData {
+	nested := &source_metadatapb.MetaData{}
+	if md != nil {
+		nested = proto.Clone(md).(*source_metadatapb.MetaData)
+	}
+	nested.Provenance = append(nested.Provenance, provenance...)
+	return nested
+}
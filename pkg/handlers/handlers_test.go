@@ -18,6 +18,7 @@ import (
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -292,6 +293,18 @@ func TestHandleFileAR(t *testing.T) {
 	assert.Equal(t, wantChunkCount, len(reporter.Ch))
 }
 
+func TestHandleFileXPI(t *testing.T) {
+	// Firefox extensions are zip archives.
+	wantChunkCount := 2
+	reporter := sources.ChanReporter{Ch: make(chan *sources.Chunk, wantChunkCount)}
+
+	file, err := os.Open("testdata/test.xpi")
+	assert.Nil(t, err)
+
+	assert.NoError(t, HandleFile(context.Background(), file, &sources.Chunk{}, reporter))
+	assert.Equal(t, wantChunkCount, len(reporter.Ch))
+}
+
 func BenchmarkHandleAR(b *testing.B) {
 	file, err := os.Open("testdata/test.deb")
 	assert.Nil(b, err)
@@ -684,3 +697,36 @@ func setupTempGitRepoCommon(t *testing.T, fileName string, fileSize int, isUnsup
 
 	return tempDir
 }
+
+func TestHandleFileProvenance(t *testing.T) {
+	file, err := os.Open("testdata/nested-compressed-archive.tar.gz")
+	assert.Nil(t, err)
+
+	skel := &sources.Chunk{SourceMetadata: &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "nested-compressed-archive.tar.gz"}},
+	}}
+	chunkCh := make(chan *sources.Chunk)
+	go func() {
+		defer close(chunkCh)
+		err := HandleFile(logContext.Background(), file, skel, sources.ChanReporter{Ch: chunkCh})
+		assert.NoError(t, err)
+	}()
+
+	var got []string
+	for chunk := range chunkCh {
+		var levels []string
+		for _, c := range chunk.SourceMetadata.GetProvenance() {
+			levels = append(levels, c.GetFormat()+":"+c.GetPath())
+		}
+		got = append(got, strings.Join(levels, " "))
+		assert.Equal(t, "nested-compressed-archive.tar.gz", chunk.SourceMetadata.GetFilesystem().GetFile())
+	}
+	want := []string{
+		"gz: tar:InnerDirectory.zip zip:InnerDirectory/File1.txt",
+		"gz: tar:InnerDirectory.zip zip:InnerDirectory/File2.txt",
+		"gz: tar:InnerDirectory.zip zip:InnerDirectory/File3.txt",
+		"gz: tar:outerfile.txt",
+	}
+	assert.ElementsMatch(t, want, got)
+	assert.Empty(t, skel.SourceMetadata.GetProvenance())
+}
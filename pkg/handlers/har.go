@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// isHAR matches the HTTP archives browsers and proxies export, whose log
+// holds the version of the format, the tool that wrote it and its entries.
+func isHAR(raw []byte, _ uint32) bool {
+	raw = bytes.TrimLeft(bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return bytes.HasPrefix(raw, []byte("{")) &&
+		bytes.Contains(raw, []byte(`"log"`)) &&
+		(bytes.Contains(raw, []byte(`"creator"`)) || bytes.Contains(raw, []byte(`"entries"`)))
+}
+
+// harPair is a name and value pair of an HTTP archive: a header, a query
+// parameter, a cookie or a form parameter.
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harEntry is a request an HTTP archive recorded, and the response to it.
+type harEntry struct {
+	Request struct {
+		Method      string    `json:"method"`
+		URL         string    `json:"url"`
+		Headers     []harPair `json:"headers"`
+		QueryString []harPair `json:"queryString"`
+		Cookies     []harPair `json:"cookies"`
+		PostData    *struct {
+			Text   string    `json:"text"`
+			Params []harPair `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int       `json:"status"`
+		Headers []harPair `json:"headers"`
+		Cookies []harPair `json:"cookies"`
+		Content struct {
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// harHandler handles HTTP archives (HAR), reading each of the requests they
+// recorded on its own.
+type harHandler struct{ *defaultHandler }
+
+// newHARHandler creates a harHandler.
+func newHARHandler() *harHandler {
+	return &harHandler{defaultHandler: newDefaultHandler(harHandlerType)}
+}
+
+// HandleFile handles the entries of an HTTP archive as they're decoded, each
+// as the text of its request and its response at the URL of the request: the
+// headers, cookies and parameters, each on a line after its name, and the
+// bodies. An archive that can't be decoded before its first entry is handled
+// as it is.
+func (h *harHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var entries int
+		entries, err = h.handleEntries(ctx, input, dataChan)
+		if err != nil && entries == 0 && !common.IsDone(ctx) {
+			ctx.Logger().V(2).Info("handling HTTP archive as it is", "error", err)
+			if _, err = input.Seek(0, io.SeekStart); err == nil {
+				err = h.handleNonArchiveContent(ctx, newMimeTypeReaderFromFileReader(input), dataChan)
+			}
+			return
+		}
+		if err != nil {
+			ctx.Logger().Error(err, "error handling HTTP archive")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// handleEntries decodes the entries of the log of an archive one at a time,
+// returning the number handled.
+func (h *harHandler) handleEntries(ctx logContext.Context, r io.Reader, dataChan chan handledData) (int, error) {
+	dec := json.NewDecoder(r)
+	if err := enterJSONObject(dec, "log"); err != nil {
+		return 0, err
+	}
+	if err := enterJSONObject(dec, "entries"); err != nil {
+		return 0, err
+	}
+	if err := expectJSONDelim(dec, '['); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for dec.More() {
+		if common.IsDone(ctx) {
+			return n, ctx.Err()
+		}
+		var entry harEntry
+		if err := dec.Decode(&entry); err != nil {
+			return n, fmt.Errorf("error decoding entry %d: %w", n, err)
+		}
+		write := func(w *rowWriter) error { return writeHAREntry(&entry, w) }
+		if err := h.handleRequest(ctx, "har", entry.Request.URL, write, dataChan); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// writeHAREntry writes the request line, headers, cookies, parameters and
+// body of a request, and the headers, cookies and body of its response, the
+// bodies as they are.
+func writeHAREntry(entry *harEntry, w *rowWriter) error {
+	req := entry.Request
+	if _, err := w.w.WriteString(req.Method + " " + req.URL + "\n"); err != nil {
+		return err
+	}
+	for _, pairs := range [][]harPair{req.Headers, req.Cookies, req.QueryString} {
+		if err := writeHARPairs(pairs, w); err != nil {
+			return err
+		}
+	}
+	if req.PostData != nil {
+		if err := writeHARPairs(req.PostData.Params, w); err != nil {
+			return err
+		}
+		if err := writeVerbatim(req.PostData.Text, w); err != nil {
+			return err
+		}
+	}
+
+	res := entry.Response
+	if _, err := w.w.WriteString("\nHTTP " + strconv.Itoa(res.Status) + "\n"); err != nil {
+		return err
+	}
+	for _, pairs := range [][]harPair{res.Headers, res.Cookies} {
+		if err := writeHARPairs(pairs, w); err != nil {
+			return err
+		}
+	}
+	body := res.Content.Text
+	if res.Content.Encoding == "base64" {
+		if decoded, err := base64.StdEncoding.DecodeString(body); err == nil {
+			body = string(decoded)
+		}
+	}
+	return writeVerbatim(body, w)
+}
+
+func writeHARPairs(pairs []harPair, w *rowWriter) error {
+	for _, p := range pairs {
+		if err := w.value(p.Name, p.Value); err != nil {
+			return err
+		}
+	}
+	return w.endRow()
+}
+
+// writeVerbatim writes a body as it is, on lines of its own.
+func writeVerbatim(text string, w *rowWriter) error {
+	if text == "" {
+		return nil
+	}
+	if _, err := w.w.WriteString(text); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString("\n\n")
+	return err
+}
+
+// handleRequest handles the text of a request that write writes, as an entry
+// of a file of a format at the URL of the request.
+func (h *defaultHandler) handleRequest(
+	ctx logContext.Context,
+	format, url string,
+	write func(*rowWriter) error,
+	dataChan chan handledData,
+) error {
+	var buf bytes.Buffer
+	w := &rowWriter{w: bufio.NewWriter(&buf)}
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+
+	rdr, err := newMimeTypeReader(&buf)
+	if err != nil {
+		return err
+	}
+	return h.handleNonArchiveContent(withContainer(ctx, format, url), rdr, dataChan)
+}
+
+// enterJSONObject reads the opening of an object, and its members up to the
+// value of key.
+func enterJSONObject(dec *json.Decoder, key string) error {
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == key {
+			return nil
+		}
+		// Skip the value of another member.
+		var skipped json.RawMessage
+		if err := dec.Decode(&skipped); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("no %q member", key)
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return errors.New("expected " + delim.String())
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ocr"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/qrcode"
+)
+
+// maxImagePixels caps the size of the images QR codes are decoded in, as
+// they're decoded whole.
+const maxImagePixels = 25_000_000
+
+// recognizerKey is the context key of the recognizer set by WithOCR.
+type recognizerKey struct{}
+
+// WithOCR returns a copy of ctx in which the text of PNG and JPEG images is
+// recognized with r, the images being skipped otherwise. The recognizer is
+// scoped to the context so engines with different recognizers can run in the
+// same process.
+func WithOCR(ctx logContext.Context, r ocr.Recognizer) logContext.Context {
+	return logContext.WithValue(ctx, recognizerKey{}, r)
+}
+
+// recognizer returns the recognizer of the text of images in ctx, which
+// isn't scanned if it's nil.
+func recognizer(ctx logContext.Context) ocr.Recognizer {
+	r, _ := ctx.Value(recognizerKey{}).(ocr.Recognizer)
+	return r
+}
+
+// qrCodesKey is the context key of the setting of WithQRCodes.
+type qrCodesKey struct{}
+
+// WithQRCodes returns a copy of ctx in which the QR codes of PNG and JPEG
+// images are decoded if enabled is set, their payloads being scanned. The
+// setting is scoped to the context like WithOCR.
+func WithQRCodes(ctx logContext.Context, enabled bool) logContext.Context {
+	return logContext.WithValue(ctx, qrCodesKey{}, enabled)
+}
+
+// decodeQRCodes returns whether the QR codes of images are decoded in ctx.
+func decodeQRCodes(ctx logContext.Context) bool {
+	enabled, _ := ctx.Value(qrCodesKey{}).(bool)
+	return enabled
+}
+
+// scanImages returns true if anything in images is scanned in ctx.
+func scanImages(ctx logContext.Context) bool { return recognizer(ctx) != nil || decodeQRCodes(ctx) }
+
+// SkipFile returns true if a file is skipped because of its extension, which
+// images aren't if anything in them is scanned in ctx.
+func SkipFile(ctx logContext.Context, filename string) bool {
+	return common.SkipFile(filename) && !(scanImages(ctx) && ocr.IsImageFile(filename))
+}
+
+// handleImage handles the payloads of the QR codes and the text of an image.
+// Each is content of its own, since where it is in the image can't be told
+// from where it is in the content.
+func (h *defaultHandler) handleImage(ctx logContext.Context, reader mimeTypeReader, archiveChan chan handledData) error {
+	img, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return fmt.Errorf("error reading image: %w", err)
+	}
+	if len(img) > maxSize {
+		ctx.Logger().V(2).Info("skipping image: size exceeds max allowed", "limit", maxSize)
+		h.metrics.incFilesSkipped()
+		_, _ = io.Copy(io.Discard, reader)
+		return nil
+	}
+
+	if decodeQRCodes(ctx) {
+		for _, payload := range h.qrCodePayloads(ctx, img) {
+			if err := h.handleImageContent(withContainer(ctx, "qr", ""), payload, archiveChan); err != nil {
+				return err
+			}
+		}
+	}
+
+	r := recognizer(ctx)
+	if r == nil {
+		return nil
+	}
+	text, err := r.Recognize(ctx, img)
+	if err != nil {
+		return fmt.Errorf("error recognizing text: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return h.handleImageContent(withContainer(ctx, "ocr", ""), text, archiveChan)
+}
+
+// qrCodePayloads returns the payloads of the QR codes of an image. Images that
+// can't be decoded have none, as their text may still be recognized.
+func (h *defaultHandler) qrCodePayloads(ctx logContext.Context, img []byte) []string {
+	config, _, err := image.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		ctx.Logger().V(2).Info("skipping QR codes: image can't be decoded", "error", err)
+		return nil
+	}
+	if config.Width*config.Height > maxImagePixels {
+		ctx.Logger().V(2).Info("skipping QR codes: image exceeds max allowed pixels", "limit", maxImagePixels)
+		return nil
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(img))
+	if err != nil {
+		ctx.Logger().V(2).Info("skipping QR codes: image can't be decoded", "error", err)
+		return nil
+	}
+	return qrcode.Decode(decoded)
+}
+
+func (h *defaultHandler) handleImageContent(ctx logContext.Context, content string, archiveChan chan handledData) error {
+	rdr, err := newMimeTypeReader(strings.NewReader(content))
+	if err != nil {
+		return err
+	}
+	return h.handleNonArchiveContent(ctx, rdr, archiveChan)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	stdctx "context"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// fakeRecognizer recognizes the same text in every image.
+type fakeRecognizer struct {
+	text   string
+	images int
+}
+
+func (r *fakeRecognizer) Recognize(_ stdctx.Context, _ []byte) (string, error) {
+	r.images++
+	return r.text, nil
+}
+
+func TestHandleImageOCR(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	handle := func(ctx context.Context) []handledData {
+		rdr, err := newFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		defer rdr.Close()
+
+		dataChan, err := newDefaultHandler(defaultHandlerType).HandleFile(context.AddLogger(ctx), rdr)
+		require.NoError(t, err)
+		var handled []handledData
+		for data := range dataChan {
+			handled = append(handled, data)
+		}
+		return handled
+	}
+
+	// Images are skipped without a recognizer.
+	assert.Empty(t, handle(ctx))
+
+	recognizer := &fakeRecognizer{text: "$ export STRIPE_KEY=sk_live_screenshot_token_1234\n"}
+	handled := handle(WithOCR(ctx, recognizer))
+	assert.Equal(t, 1, recognizer.images)
+	require.Len(t, handled, 1)
+	assert.Equal(t, recognizer.text, string(handled[0].data))
+	require.Len(t, handled[0].provenance, 1)
+	assert.Equal(t, "ocr", handled[0].provenance[0].GetFormat())
+}
+
+func TestHandleImageQRCodes(t *testing.T) {
+	file, err := os.Open("testdata/otpauth-qr.png")
+	require.NoError(t, err)
+	defer file.Close()
+	rdr, err := newFileReader(file)
+	require.NoError(t, err)
+	defer rdr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	dataChan, err := newDefaultHandler(defaultHandlerType).HandleFile(context.AddLogger(WithQRCodes(ctx, true)), rdr)
+	require.NoError(t, err)
+	var handled []handledData
+	for data := range dataChan {
+		handled = append(handled, data)
+	}
+
+	require.Len(t, handled, 1)
+	assert.Equal(t, "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example", string(handled[0].data))
+	require.Len(t, handled[0].provenance, 1)
+	assert.Equal(t, "qr", handled[0].provenance[0].GetFormat())
+}
+
+func TestSkipFile(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, SkipFile(ctx, "docs/screenshot.png"))
+	assert.False(t, SkipFile(ctx, "main.go"))
+
+	ocrCtx := WithOCR(ctx, &fakeRecognizer{})
+	assert.False(t, SkipFile(ocrCtx, "docs/screenshot.png"))
+	assert.False(t, SkipFile(ocrCtx, "photo.JPG"))
+	assert.True(t, SkipFile(ocrCtx, "anim.gif"))
+
+	assert.False(t, SkipFile(WithQRCodes(ctx, true), "docs/screenshot.png"))
+}
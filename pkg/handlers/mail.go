@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// maxEMLHeaderSize caps the size of the header of an email message.
+const maxEMLHeaderSize = 1 << 20 // 1 MB
+
+// emlHandler handles email messages in MIME format, such as .eml files.
+//
+// The content of a message is in the leaves of a tree of MIME parts, encoded
+// as base64 or quoted-printable, so each leaf is decoded and handled on its
+// own: the text of the message, its attachments, and the parts of messages
+// forwarded as attachments. Parts are numbered as IMAP numbers them.
+type emlHandler struct{ *archiveHandler }
+
+// newEMLHandler creates an emlHandler.
+func newEMLHandler() *emlHandler {
+	return &emlHandler{archiveHandler: &archiveHandler{defaultHandler: newDefaultHandler(emlHandlerType)}}
+}
+
+// HandleFile decodes the parts of an email message, extracting archives
+// attached to it.
+func (h *emlHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		if err = h.handleMessage(ctx, input, "", 0, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling email message")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// handleMessage handles the header and the body of a message. The parts of a
+// message nested in the part numbered prefix are numbered from it.
+func (h *emlHandler) handleMessage(ctx logContext.Context, r io.Reader, prefix string, depth int, dataChan chan handledData) error {
+	if depth >= maxDepth {
+		h.metrics.incMaxArchiveDepthCount()
+		return ErrMaxDepthReached
+	}
+
+	body := bufio.NewReader(r)
+	raw, header, err := readMessageHeader(body)
+	if err != nil {
+		return err
+	}
+
+	rdr, err := newMimeTypeReader(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	if err := h.handleNonArchiveContent(withMessagePart(ctx, "eml", "", joinPart(prefix, "HEADER")), rdr, dataChan); err != nil {
+		return err
+	}
+
+	// The body of a message that isn't multipart is its first part.
+	mediaType, params := partType(header)
+	if strings.HasPrefix(mediaType, "multipart/") {
+		return h.handleMultipart(ctx, body, params["boundary"], prefix, depth, dataChan)
+	}
+	return h.handleLeaf(ctx, header, body, joinPart(prefix, "1"), dataChan)
+}
+
+// handleMultipart handles the parts of the multipart part numbered part.
+func (h *emlHandler) handleMultipart(ctx logContext.Context, body io.Reader, boundary, part string, depth int, dataChan chan handledData) error {
+	if boundary == "" {
+		return fmt.Errorf("multipart part %q has no boundary", part)
+	}
+
+	mr := multipart.NewReader(body, boundary)
+	for i := 1; ; i++ {
+		if common.IsDone(ctx) {
+			return ctx.Err()
+		}
+		// Raw parts are decoded here, since multipart decodes only
+		// quoted-printable parts.
+		p, err := mr.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading part %s: %w", joinPart(part, strconv.Itoa(i)), err)
+		}
+
+		child := joinPart(part, strconv.Itoa(i))
+		mediaType, params := partType(p.Header)
+		switch {
+		case strings.HasPrefix(mediaType, "multipart/"):
+			err = h.handleMultipart(ctx, p, params["boundary"], child, depth, dataChan)
+		case mediaType == "message/rfc822":
+			err = h.handleMessage(ctx, p, child, depth+1, dataChan)
+		default:
+			err = h.handleLeaf(ctx, p.Header, p, child, dataChan)
+		}
+		if err != nil {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			ctx.Logger().Error(err, "error handling email part", "part", child)
+			h.metrics.incErrors()
+		}
+	}
+}
+
+// handleLeaf decodes the content of the part numbered part, which may be an
+// archive if it's attached.
+func (h *emlHandler) handleLeaf(ctx logContext.Context, header textproto.MIMEHeader, body io.Reader, part string, dataChan chan handledData) error {
+	filename := partFilename(header)
+	lCtx := logContext.WithValues(ctx, "part", part, "filename", filename)
+	if filename != "" && (SkipFile(ctx, filename) || common.IsBinary(filename)) {
+		lCtx.Logger().V(2).Info("skipping attachment: extension is ignored")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+
+	rdr, err := newFileReader(decodePart(header, body))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			lCtx.Logger().V(5).Info("empty reader, skipping part")
+			return nil
+		}
+		return fmt.Errorf("error creating custom reader: %w", err)
+	}
+	defer rdr.Close()
+
+	return h.openArchive(withMessagePart(lCtx, "eml", filename, part), 1, rdr, dataChan)
+}
+
+// readMessageHeader reads the header of a message from r, returning it both
+// as it is and parsed. r is left at the start of the body.
+func readMessageHeader(r *bufio.Reader) ([]byte, textproto.MIMEHeader, error) {
+	var raw []byte
+	for {
+		line, err := r.ReadBytes('\n')
+		raw = append(raw, line...)
+		if len(bytes.TrimRight(line, "\r\n")) == 0 || errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading message header: %w", err)
+		}
+		if len(raw) > maxEMLHeaderSize {
+			return nil, nil, fmt.Errorf("message header exceeds %d bytes", maxEMLHeaderSize)
+		}
+	}
+
+	header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(raw))).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("error parsing message header: %w", err)
+	}
+	return raw, header, nil
+}
+
+// partType returns the media type of a part, which is text/plain if it's
+// missing or invalid.
+func partType(header textproto.MIMEHeader) (string, map[string]string) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return "text/plain", nil
+	}
+	return mediaType, params
+}
+
+// partFilename returns the filename of an attached part, if any.
+func partFilename(header textproto.MIMEHeader) string {
+	var filename string
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		filename = params["filename"]
+	}
+	if filename == "" {
+		if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+			filename = params["name"]
+		}
+	}
+	// Names are often encoded-words rather than RFC 2231 parameters.
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+	return filename
+}
+
+// decodePart undoes the transfer encoding of the body of a part.
+func decodePart(header textproto.MIMEHeader, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// joinPart returns the number of the part numbered n within part.
+func joinPart(part, n string) string {
+	if part == "" {
+		return n
+	}
+	return part + "." + n
+}
+
+// isEML matches email messages in MIME format: text that starts with a
+// header field, and whose header has a MIME-Version field.
+func isEML(raw []byte, _ uint32) bool {
+	name, _, ok := bytes.Cut(raw, []byte(":"))
+	if !ok || len(name) == 0 || bytes.ContainsAny(name, " \t\r\n") {
+		return false
+	}
+	header := raw
+	if end := bytes.Index(raw, []byte("\n\n")); end >= 0 {
+		header = raw[:end]
+	}
+	if end := bytes.Index(header, []byte("\r\n\r\n")); end >= 0 {
+		header = header[:end]
+	}
+	for _, line := range bytes.Split(header, []byte("\n")) {
+		if len(line) > len("mime-version:") && bytes.EqualFold(line[:len("mime-version:")], []byte("mime-version:")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// The properties of Outlook messages are streams named after their tag and
+// type, such as __substg1.0_1000001F for the body as a Unicode string.
+const (
+	msgPropPrefix   = "__substg1.0_"
+	msgAttachPrefix = "__attach_version1.0_#"
+
+	msgHeaders        = "007D" // the transport headers
+	msgBody           = "1000"
+	msgHTMLBody       = "1013"
+	msgAttachData     = "3701"
+	msgAttachFilename = "3704"
+	msgAttachLongName = "3707"
+
+	msgUnicode  = "001F"
+	msgString8  = "001E"
+	msgBinary   = "0102"
+	msgEmbedded = "000D"
+)
+
+// msgHandler handles Outlook messages, which are compound files with a
+// stream for each property of a message, and a storage for each attachment.
+//
+// Parts are numbered as those of the emlHandler: the text of a message, its
+// headers and its bodies, is its first part, and each attachment is a part
+// after it. The parts of an attached message are numbered from its own.
+type msgHandler struct{ *archiveHandler }
+
+// newMSGHandler creates a msgHandler.
+func newMSGHandler() *msgHandler {
+	return &msgHandler{archiveHandler: &archiveHandler{defaultHandler: newDefaultHandler(msgHandlerType)}}
+}
+
+// HandleFile reads the text and the attachments of an Outlook message,
+// extracting attached archives.
+func (h *msgHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var size int64
+		if size, err = input.Size(); err != nil {
+			ctx.Logger().Error(err, "error getting file size")
+			return
+		}
+		var cf *compoundFile
+		if cf, err = openCompoundFile(input, size); err != nil {
+			ctx.Logger().Error(err, "error reading Outlook message")
+			return
+		}
+		if props, attachments := msgEntries(cf, 0); len(props) == 0 && len(attachments) == 0 {
+			// Other compound files are scanned as they are.
+			if _, err = input.Seek(0, io.SeekStart); err == nil {
+				err = h.handleNonArchiveContent(ctx, newMimeTypeReaderFromFileReader(input), dataChan)
+			}
+			return
+		}
+		if err = h.handleMessage(ctx, cf, 0, "", 0, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling Outlook message")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// handleMessage handles the message in the storage at index i, whose parts
+// are numbered from prefix.
+func (h *msgHandler) handleMessage(ctx logContext.Context, cf *compoundFile, i int, prefix string, depth int, dataChan chan handledData) error {
+	if depth >= maxDepth {
+		h.metrics.incMaxArchiveDepthCount()
+		return ErrMaxDepthReached
+	}
+
+	props, attachments := msgEntries(cf, i)
+
+	textCtx := withMessagePart(ctx, "msg", "", joinPart(prefix, "1"))
+	for _, tag := range []string{msgHeaders, msgBody, msgHTMLBody} {
+		text, err := msgProperty(cf, props, tag)
+		if err != nil {
+			ctx.Logger().Error(err, "error reading message property", "tag", tag)
+			h.metrics.incErrors()
+			continue
+		}
+		if len(text) == 0 {
+			continue
+		}
+		rdr, err := newMimeTypeReader(bytes.NewReader(text))
+		if err != nil {
+			return err
+		}
+		if err := h.handleNonArchiveContent(textCtx, rdr, dataChan); err != nil {
+			return err
+		}
+	}
+
+	for n, a := range attachments {
+		if common.IsDone(ctx) {
+			return ctx.Err()
+		}
+		part := joinPart(prefix, strconv.Itoa(n+2))
+		if err := h.handleAttachment(ctx, cf, a, part, depth, dataChan); err != nil {
+			if common.IsDone(ctx) {
+				return ctx.Err()
+			}
+			ctx.Logger().Error(err, "error handling attachment", "part", part)
+			h.metrics.incErrors()
+		}
+	}
+	return nil
+}
+
+// handleAttachment handles the attachment in the storage at index i, which
+// is either a file or a message.
+func (h *msgHandler) handleAttachment(ctx logContext.Context, cf *compoundFile, i int, part string, depth int, dataChan chan handledData) error {
+	props, _ := msgEntries(cf, i)
+	if embedded, ok := props[msgPropPrefix+msgAttachData+msgEmbedded]; ok && cf.entries[embedded].kind == cfbStorage {
+		return h.handleMessage(ctx, cf, embedded, part, depth+1, dataChan)
+	}
+
+	name, err := msgProperty(cf, props, msgAttachLongName)
+	if err != nil || len(name) == 0 {
+		name, _ = msgProperty(cf, props, msgAttachFilename)
+	}
+	filename := string(name)
+	lCtx := logContext.WithValues(ctx, "part", part, "filename", filename)
+	if filename != "" && (SkipFile(ctx, filename) || common.IsBinary(filename)) {
+		lCtx.Logger().V(2).Info("skipping attachment: extension is ignored")
+		h.metrics.incFilesSkipped()
+		return nil
+	}
+
+	data, err := msgProperty(cf, props, msgAttachData)
+	if err != nil {
+		return err
+	}
+	rdr, err := newFileReader(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, ErrEmptyReader) {
+			lCtx.Logger().V(5).Info("empty reader, skipping attachment")
+			return nil
+		}
+		return fmt.Errorf("error creating custom reader: %w", err)
+	}
+	defer rdr.Close()
+
+	return h.openArchive(withMessagePart(lCtx, "msg", filename, part), 1, rdr, dataChan)
+}
+
+// msgEntries returns the indexes of the properties of the storage at index i
+// by name, and those of its attachments in order.
+func msgEntries(cf *compoundFile, i int) (map[string]int, []int) {
+	props := make(map[string]int)
+	var attachments []int
+	for _, c := range cf.children(i) {
+		name := cf.entries[c].name
+		switch {
+		case strings.HasPrefix(name, msgPropPrefix):
+			props[name] = c
+		case strings.HasPrefix(name, msgAttachPrefix) && cf.entries[c].kind == cfbStorage:
+			attachments = append(attachments, c)
+		}
+	}
+	// The tree of the entries of a storage is sorted by the length of their
+	// names and then by name, and attachments are numbered with eight hex
+	// digits, so they're in order.
+	return props, attachments
+}
+
+// msgProperty returns the value of the property with the given tag, decoding
+// strings to UTF-8. It's empty if the message doesn't have the property.
+func msgProperty(cf *compoundFile, props map[string]int, tag string) ([]byte, error) {
+	for _, typ := range []string{msgUnicode, msgString8, msgBinary} {
+		i, ok := props[msgPropPrefix+tag+typ]
+		if !ok || cf.entries[i].kind != cfbStream {
+			continue
+		}
+		data, err := cf.stream(i)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case msgUnicode:
+			units := make([]uint16, len(data)/2)
+			for j := range units {
+				units[j] = binary.LittleEndian.Uint16(data[j*2:])
+			}
+			data = []byte(string(utf16.Decode(units)))
+			return bytes.TrimRight(data, "\x00"), nil
+		case msgString8:
+			return bytes.TrimRight(data, "\x00"), nil
+		default:
+			return data, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"io"
+	"time"
+
+	"github.com/scritchley/orc"
+	"github.com/scritchley/orc/proto"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// orcHandler handles ORC files, reading the values of their string columns.
+type orcHandler struct{ *defaultHandler }
+
+// newORCHandler creates an orcHandler.
+func newORCHandler() *orcHandler {
+	return &orcHandler{defaultHandler: newDefaultHandler(orcHandlerType)}
+}
+
+// HandleFile reads the string values of a sample of the rows of an ORC file.
+func (h *orcHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var size int64
+		if size, err = input.Size(); err != nil {
+			ctx.Logger().Error(err, "error getting file size")
+			return
+		}
+		var r *orc.Reader
+		if r, err = orc.NewReader(io.NewSectionReader(input, 0, size)); err != nil {
+			ctx.Logger().Error(err, "error reading ORC file")
+			return
+		}
+		defer r.Close()
+
+		if err = h.handleRows(ctx, "orc", func(w *rowWriter) error { return writeORCRows(r, columnarMaxRows(ctx), w) }, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling ORC file")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// writeORCRows writes the values of the top-level string columns of the rows
+// sampled from each stripe of an ORC file, at most maxRows in all.
+func writeORCRows(r *orc.Reader, maxRows int, w *rowWriter) error {
+	schema := r.Schema()
+	var columns []string
+	for _, name := range schema.Columns() {
+		column, err := schema.GetField(name)
+		if err != nil {
+			return err
+		}
+		switch column.Type().GetKind() {
+		case proto.Type_STRING, proto.Type_VARCHAR, proto.Type_CHAR:
+			columns = append(columns, name)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	stripes, err := r.NumStripes()
+	if err != nil {
+		return err
+	}
+	perStripe := rowsPerGroup(maxRows, stripes)
+	c := r.Select(columns...)
+	for c.Stripes() {
+		for rows := 0; rows < perStripe && c.Next(); rows++ {
+			for i, v := range c.Row() {
+				if s, ok := v.(string); ok {
+					if err := w.value(columns[i], s); err != nil {
+						return err
+					}
+				}
+			}
+			if err := w.endRow(); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Err()
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scritchley/orc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestHandleORCFile(t *testing.T) {
+	schema, err := orc.ParseSchema("struct<id:int,username:string,password:varchar(64),tags:array<string>>")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := orc.NewWriter(&buf, orc.SetSchema(schema))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(int64(1), "alice", "orc-password-1", []string{"nested"}))
+	require.NoError(t, w.Write(int64(2), "bob", nil, []string{}))
+	require.NoError(t, w.Close())
+
+	// Only top-level string columns are read.
+	assert.Equal(t,
+		"username: alice\npassword: orc-password-1\n\nusername: bob\n\n",
+		handleRowsFile(t, buf.Bytes(), orcMime, "orc"))
+
+	ctx := WithColumnarMaxRows(context.Background(), 1)
+	assert.Equal(t, "username: alice\npassword: orc-password-1\n\n", handleRowsFileContext(t, ctx, buf.Bytes(), orcMime, "orc"))
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/schema"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// parquetBatchSize is the number of values read from a column at a time.
+const parquetBatchSize = 1024
+
+// parquetHandler handles Parquet files, reading the values of their string
+// columns.
+type parquetHandler struct{ *defaultHandler }
+
+// newParquetHandler creates a parquetHandler.
+func newParquetHandler() *parquetHandler {
+	return &parquetHandler{defaultHandler: newDefaultHandler(parquetHandlerType)}
+}
+
+// HandleFile reads the string values of a sample of the rows of a Parquet
+// file.
+func (h *parquetHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var size int64
+		if size, err = input.Size(); err != nil {
+			ctx.Logger().Error(err, "error getting file size")
+			return
+		}
+		var r *file.Reader
+		if r, err = file.NewParquetReader(io.NewSectionReader(input, 0, size)); err != nil {
+			ctx.Logger().Error(err, "error reading Parquet file")
+			return
+		}
+		defer r.Close()
+
+		if err = h.handleRows(ctx, "parquet", func(w *rowWriter) error { return writeParquetRows(r, columnarMaxRows(ctx), w) }, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling Parquet file")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// writeParquetRows writes the string values of the rows sampled from each row
+// group of a Parquet file, at most maxRows in all.
+func writeParquetRows(r *file.Reader, maxRows int, w *rowWriter) error {
+	s := r.MetaData().Schema
+	var columns []int
+	for i := 0; i < s.NumColumns(); i++ {
+		if isParquetString(s.Column(i)) {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	perGroup := rowsPerGroup(maxRows, r.NumRowGroups())
+	for g := 0; g < r.NumRowGroups(); g++ {
+		rg := r.RowGroup(g)
+		rows := make([][]columnValue, min(int(rg.NumRows()), perGroup))
+		for _, i := range columns {
+			if err := readParquetColumn(rg, i, s.Column(i), rows); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			for _, v := range row {
+				if err := w.value(v.column, v.value); err != nil {
+					return err
+				}
+			}
+			if err := w.endRow(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isParquetString returns true for the columns of strings, which are byte
+// arrays annotated as UTF-8, or not annotated at all by older writers.
+func isParquetString(c *schema.Column) bool {
+	if c.PhysicalType() != parquet.Types.ByteArray {
+		return false
+	}
+	switch c.LogicalType().(type) {
+	case schema.StringLogicalType, schema.EnumLogicalType, schema.JSONLogicalType, schema.NoLogicalType:
+		return true
+	default:
+		return false
+	}
+}
+
+// readParquetColumn appends the values of column i of a row group to the rows
+// they're in, as the name of the column and the value. A new row starts at
+// each value or null whose repetition level is 0, and a value is only stored
+// if its definition level is the maximum, below which it's null.
+func readParquetColumn(rg *file.RowGroupReader, i int, c *schema.Column, rows [][]columnValue) error {
+	col, err := rg.Column(i)
+	if err != nil {
+		return err
+	}
+	cr, ok := col.(*file.ByteArrayColumnChunkReader)
+	if !ok {
+		return fmt.Errorf("unexpected reader for column %s: %T", c.Path(), col)
+	}
+
+	var (
+		values = make([]parquet.ByteArray, parquetBatchSize)
+		defs   = make([]int16, parquetBatchSize)
+		reps   = make([]int16, parquetBatchSize)
+	)
+	row := -1
+	for cr.HasNext() {
+		levels, _, err := cr.ReadBatch(parquetBatchSize, values, defs, reps)
+		if err != nil {
+			return err
+		}
+		v := 0
+		for l := 0; l < int(levels); l++ {
+			if c.MaxRepetitionLevel() == 0 || reps[l] == 0 {
+				row++
+			}
+			if row >= len(rows) {
+				return nil
+			}
+			if c.MaxDefinitionLevel() == 0 || defs[l] == c.MaxDefinitionLevel() {
+				rows[row] = append(rows[row], columnValue{c.Path(), string(values[v])})
+				v++
+			}
+		}
+	}
+	return cr.Err()
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// writeTestParquet writes a Parquet file of two row groups with an integer, an
+// optional string and a repeated string column.
+func writeTestParquet(t *testing.T) []byte {
+	t.Helper()
+	str := func(name string, rep parquet.Repetition) schema.Node {
+		return schema.MustPrimitive(schema.NewPrimitiveNodeLogical(name, rep, schema.StringLogicalType{}, parquet.Types.ByteArray, -1, -1))
+	}
+	root := schema.MustGroup(schema.NewGroupNode("schema", parquet.Repetitions.Required, schema.FieldList{
+		schema.NewInt64Node("id", parquet.Repetitions.Required, -1),
+		str("api_key", parquet.Repetitions.Optional),
+		str("tags", parquet.Repetitions.Repeated),
+	}, -1))
+
+	var buf bytes.Buffer
+	w := file.NewParquetWriter(&buf, root)
+	groups := []struct {
+		ids        []int64
+		keys       []parquet.ByteArray
+		keyDefs    []int16
+		tags       []parquet.ByteArray
+		tagDefs    []int16
+		tagRepeats []int16
+	}{
+		{
+			// Three rows, the second without a key or tags.
+			ids:        []int64{1, 2, 3},
+			keys:       []parquet.ByteArray{parquet.ByteArray("sk_live_parquet_token_1"), parquet.ByteArray("sk_live_parquet_token_2")},
+			keyDefs:    []int16{1, 0, 1},
+			tags:       []parquet.ByteArray{parquet.ByteArray("prod"), parquet.ByteArray("billing")},
+			tagDefs:    []int16{1, 1, 0, 0},
+			tagRepeats: []int16{0, 1, 0, 0},
+		},
+		{
+			ids:        []int64{4},
+			keys:       []parquet.ByteArray{parquet.ByteArray("sk_live_parquet_token_3")},
+			keyDefs:    []int16{1},
+			tags:       []parquet.ByteArray{parquet.ByteArray("ci")},
+			tagDefs:    []int16{1},
+			tagRepeats: []int16{0},
+		},
+	}
+	for _, g := range groups {
+		rg := w.AppendRowGroup()
+		cw, err := rg.NextColumn()
+		require.NoError(t, err)
+		_, err = cw.(*file.Int64ColumnChunkWriter).WriteBatch(g.ids, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, cw.Close())
+
+		cw, err = rg.NextColumn()
+		require.NoError(t, err)
+		_, err = cw.(*file.ByteArrayColumnChunkWriter).WriteBatch(g.keys, g.keyDefs, nil)
+		require.NoError(t, err)
+		require.NoError(t, cw.Close())
+
+		cw, err = rg.NextColumn()
+		require.NoError(t, err)
+		_, err = cw.(*file.ByteArrayColumnChunkWriter).WriteBatch(g.tags, g.tagDefs, g.tagRepeats)
+		require.NoError(t, err)
+		require.NoError(t, cw.Close())
+		require.NoError(t, rg.Close())
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// handleRowsFile returns the content the handler of a file of rows, such as
+// a columnar data file, handles, checking that it's all extracted from format.
+func handleRowsFile(t *testing.T, data []byte, mime mimeType, format string) string {
+	t.Helper()
+	return handleRowsFileContext(t, context.Background(), data, mime, format)
+}
+
+func handleRowsFileContext(t *testing.T, ctx context.Context, data []byte, mime mimeType, format string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rdr, err := newFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer rdr.Close()
+	require.Equal(t, mime, mimeType(rdr.mime.String()))
+
+	dataChan, err := selectHandler(mime, false).HandleFile(context.AddLogger(ctx), rdr)
+	require.NoError(t, err)
+	var content strings.Builder
+	for data := range dataChan {
+		require.Len(t, data.provenance, 1)
+		assert.Equal(t, format, data.provenance[0].GetFormat())
+		content.Write(data.data)
+	}
+	return content.String()
+}
+
+func TestHandleParquetFile(t *testing.T) {
+	assert.Equal(t,
+		"api_key: sk_live_parquet_token_1\ntags: prod\ntags: billing\n\n"+
+			"api_key: sk_live_parquet_token_2\n\n"+
+			"api_key: sk_live_parquet_token_3\ntags: ci\n\n",
+		handleRowsFile(t, writeTestParquet(t), parquetMime, "parquet"))
+}
+
+func TestHandleParquetFileSampling(t *testing.T) {
+	ctx := WithColumnarMaxRows(context.Background(), 2)
+
+	// A row is read from each of the two row groups.
+	assert.Equal(t,
+		"api_key: sk_live_parquet_token_1\ntags: prod\ntags: billing\n\n"+
+			"api_key: sk_live_parquet_token_3\ntags: ci\n\n",
+		handleRowsFileContext(t, ctx, writeTestParquet(t), parquetMime, "parquet"))
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// postmanSchema is in the schema URL of exported Postman collections.
+const postmanSchema = "schema.getpostman.com/json/collection"
+
+// isPostmanCollection matches exported Postman collections by their schema.
+func isPostmanCollection(raw []byte, _ uint32) bool {
+	return bytes.Contains(raw, []byte(postmanSchema))
+}
+
+// postmanValue is a value of a collection that's usually a string, but can
+// be a number or a boolean, which are kept as they're written.
+type postmanValue string
+
+func (v *postmanValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		if string(b) != "null" {
+			*v = postmanValue(b)
+		}
+		return nil
+	}
+	*v = postmanValue(s)
+	return nil
+}
+
+// postmanPair is a key and value pair of a collection: a variable, a header,
+// a query or form parameter, or a setting of an authentication method.
+type postmanPair struct {
+	Key   string       `json:"key"`
+	Value postmanValue `json:"value"`
+}
+
+// postmanPairs are a list of pairs, which headers can also be written as,
+// one "Key: value" line each.
+type postmanPairs []postmanPair
+
+func (p *postmanPairs) UnmarshalJSON(b []byte) error {
+	var pairs []postmanPair
+	if err := json.Unmarshal(b, &pairs); err == nil {
+		*p = pairs
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			*p = append(*p, postmanPair{Key: strings.TrimSpace(key), Value: postmanValue(strings.TrimSpace(value))})
+		}
+	}
+	return nil
+}
+
+// postmanURL is the URL of a request, written either as a string or as an
+// object of its raw string and parts.
+type postmanURL struct {
+	Raw   string       `json:"raw"`
+	Query postmanPairs `json:"query"`
+}
+
+func (u *postmanURL) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &u.Raw); err == nil {
+		return nil
+	}
+	type url postmanURL
+	return json.Unmarshal(b, (*url)(u))
+}
+
+// postmanAuth is the authentication method of a collection, a folder or a
+// request, and its settings under the name of its type.
+type postmanAuth map[string]json.RawMessage
+
+// pairs returns the settings of the method, which version 2.0 of the format
+// writes as an object rather than a list of pairs.
+func (a postmanAuth) pairs() postmanPairs {
+	var method string
+	if err := json.Unmarshal(a["type"], &method); err != nil {
+		return nil
+	}
+	var pairs postmanPairs
+	if err := json.Unmarshal(a[method], &pairs); err == nil && len(pairs) > 0 {
+		return pairs
+	}
+	var settings map[string]postmanValue
+	if err := json.Unmarshal(a[method], &settings); err != nil {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		pairs = append(pairs, postmanPair{Key: key, Value: settings[key]})
+	}
+	return pairs
+}
+
+// postmanScript is the source of a pre-request or test script, as a list of
+// lines or a string.
+type postmanScript []string
+
+func (s *postmanScript) UnmarshalJSON(b []byte) error {
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err == nil {
+		*s = lines
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		*s = []string{text}
+	}
+	return nil
+}
+
+type postmanEvent struct {
+	Script struct {
+		Exec postmanScript `json:"exec"`
+	} `json:"script"`
+}
+
+type postmanRequest struct {
+	Method string       `json:"method"`
+	URL    postmanURL   `json:"url"`
+	Header postmanPairs `json:"header"`
+	Body   *struct {
+		Raw        string       `json:"raw"`
+		URLEncoded postmanPairs `json:"urlencoded"`
+		FormData   postmanPairs `json:"formdata"`
+	} `json:"body"`
+	Auth postmanAuth `json:"auth"`
+}
+
+// postmanItem is a request of a collection, with the responses saved as its
+// examples, or a folder of items. A collection is the root folder.
+type postmanItem struct {
+	Item     []postmanItem   `json:"item"`
+	Request  *postmanRequest `json:"request"`
+	Response []struct {
+		OriginalRequest *postmanRequest `json:"originalRequest"`
+		Header          postmanPairs    `json:"header"`
+		Body            string          `json:"body"`
+	} `json:"response"`
+	Auth     postmanAuth    `json:"auth"`
+	Variable postmanPairs   `json:"variable"`
+	Event    []postmanEvent `json:"event"`
+}
+
+// postmanHandler handles exported Postman collections, reading each of their
+// requests on its own.
+type postmanHandler struct{ *defaultHandler }
+
+// newPostmanHandler creates a postmanHandler.
+func newPostmanHandler() *postmanHandler {
+	return &postmanHandler{defaultHandler: newDefaultHandler(postmanHandlerType)}
+}
+
+// HandleFile handles each of the requests of a collection as the text of its
+// request line, headers, parameters, body, authentication, scripts and
+// example responses at its URL, and the variables, authentication and
+// scripts of the collection and its folders together, without a URL. A
+// collection that can't be decoded is handled as it is.
+func (h *postmanHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var collection postmanItem
+		if err = json.NewDecoder(input).Decode(&collection); err != nil {
+			ctx.Logger().V(2).Info("handling Postman collection as it is", "error", err)
+			if _, err = input.Seek(0, io.SeekStart); err == nil {
+				err = h.handleNonArchiveContent(ctx, newMimeTypeReaderFromFileReader(input), dataChan)
+			}
+			return
+		}
+
+		var folders []*postmanItem
+		if err = h.handleItem(ctx, &collection, &folders, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling Postman collection")
+			return
+		}
+		write := func(w *rowWriter) error {
+			for _, folder := range folders {
+				if err := writePostmanSettings(folder, w); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err = h.handleRequest(ctx, "postman", "", write, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling Postman collection")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// handleItem handles the requests of an item and of the items in it, adding
+// the folders to folders.
+func (h *postmanHandler) handleItem(ctx logContext.Context, item *postmanItem, folders *[]*postmanItem, dataChan chan handledData) error {
+	if common.IsDone(ctx) {
+		return ctx.Err()
+	}
+	if item.Request == nil {
+		*folders = append(*folders, item)
+		for i := range item.Item {
+			if err := h.handleItem(ctx, &item.Item[i], folders, dataChan); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	write := func(w *rowWriter) error {
+		if err := writePostmanRequest(item.Request, w); err != nil {
+			return err
+		}
+		if err := writePostmanSettings(item, w); err != nil {
+			return err
+		}
+		for _, res := range item.Response {
+			if res.OriginalRequest != nil {
+				if err := writePostmanRequest(res.OriginalRequest, w); err != nil {
+					return err
+				}
+			}
+			if err := writePostmanPairs(res.Header, w); err != nil {
+				return err
+			}
+			if err := writeVerbatim(res.Body, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return h.handleRequest(ctx, "postman", item.Request.URL.Raw, write, dataChan)
+}
+
+// writePostmanRequest writes the request line, headers, query parameters,
+// body and authentication settings of a request.
+func writePostmanRequest(req *postmanRequest, w *rowWriter) error {
+	if _, err := w.w.WriteString(req.Method + " " + req.URL.Raw + "\n"); err != nil {
+		return err
+	}
+	for _, pairs := range []postmanPairs{req.Header, req.URL.Query, req.Auth.pairs()} {
+		if err := writePostmanPairs(pairs, w); err != nil {
+			return err
+		}
+	}
+	if req.Body == nil {
+		return nil
+	}
+	for _, pairs := range []postmanPairs{req.Body.URLEncoded, req.Body.FormData} {
+		if err := writePostmanPairs(pairs, w); err != nil {
+			return err
+		}
+	}
+	return writeVerbatim(req.Body.Raw, w)
+}
+
+// writePostmanSettings writes the variables, authentication settings and
+// scripts of an item.
+func writePostmanSettings(item *postmanItem, w *rowWriter) error {
+	for _, pairs := range []postmanPairs{item.Variable, item.Auth.pairs()} {
+		if err := writePostmanPairs(pairs, w); err != nil {
+			return err
+		}
+	}
+	for _, event := range item.Event {
+		if err := writeVerbatim(strings.Join(event.Script.Exec, "\n"), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePostmanPairs(pairs postmanPairs, w *rowWriter) error {
+	for _, p := range pairs {
+		if err := w.value(p.Key, string(p.Value)); err != nil {
+			return err
+		}
+	}
+	return w.endRow()
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlePostmanCollection(t *testing.T) {
+	collection := `{
+  "info": {
+    "_postman_id": "0c1e6f2a-3b4d-4e5f-8a9b-0c1d2e3f4a5b",
+    "name": "Billing API",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "item": [
+    {
+      "name": "Invoices",
+      "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+      "item": [
+        {
+          "name": "Create invoice",
+          "request": {
+            "method": "POST",
+            "header": [{"key": "X-Api-Key", "value": "ak_live_7d1f9b"}],
+            "url": {
+              "raw": "https://billing.example.com/invoices?draft=true",
+              "query": [{"key": "draft", "value": "true"}]
+            },
+            "body": {"mode": "raw", "raw": "{\"amount\": 42}"}
+          },
+          "response": [
+            {
+              "name": "Created",
+              "header": [{"key": "Location", "value": "/invoices/1"}],
+              "body": "{\"id\": 1}"
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "name": "Login",
+      "event": [{"listen": "prerequest", "script": {"exec": ["pm.environment.set(\"password\", \"Sup3rS3cret!\");"]}}],
+      "request": {
+        "method": "POST",
+        "header": "Content-Type: application/x-www-form-urlencoded",
+        "url": "https://billing.example.com/login",
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "username", "value": "svc"}, {"key": "retries", "value": 3}]},
+        "auth": {"type": "basic", "basic": {"username": "svc", "password": "Sup3rS3cret!"}}
+      }
+    }
+  ],
+  "variable": [{"key": "token", "value": "eyJhbGciOiJIUzI1NiJ9.e30.c2ln"}]
+}`
+
+	assert.Equal(t, map[string]string{
+		"https://billing.example.com/invoices?draft=true": "POST https://billing.example.com/invoices?draft=true\n" +
+			"X-Api-Key: ak_live_7d1f9b\n\n" +
+			"draft: true\n\n" +
+			"{\"amount\": 42}\n\n" +
+			"Location: /invoices/1\n\n" +
+			"{\"id\": 1}\n\n",
+		"https://billing.example.com/login": "POST https://billing.example.com/login\n" +
+			"Content-Type: application/x-www-form-urlencoded\n\n" +
+			"password: Sup3rS3cret!\nusername: svc\n\n" +
+			"username: svc\nretries: 3\n\n" +
+			"pm.environment.set(\"password\", \"Sup3rS3cret!\");\n\n",
+		"": "token: eyJhbGciOiJIUzI1NiJ9.e30.c2ln\n\n" +
+			"token: {{token}}\n\n",
+	}, handleRequestsFile(t, []byte(collection), postmanMime, "postman"))
+}
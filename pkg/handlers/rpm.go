@@ -22,8 +22,8 @@ func newRPMHandler() *rpmHandler {
 
 // HandleFile processes RPM formatted files. Further implementation is required to appropriately
 // handle RPM specific archive operations.
-func (h *rpmHandler) HandleFile(ctx logContext.Context, input fileReader) (chan []byte, error) {
-	archiveChan := make(chan []byte, defaultBufferSize)
+func (h *rpmHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	archiveChan := make(chan handledData, defaultBufferSize)
 
 	if feature.ForceSkipArchives.Load() {
 		close(archiveChan)
@@ -78,7 +78,7 @@ func (h *rpmHandler) HandleFile(ctx logContext.Context, input fileReader) (chan
 	return archiveChan, nil
 }
 
-func (h *rpmHandler) processRPMFiles(ctx logContext.Context, reader rpmutils.PayloadReader, archiveChan chan []byte) error {
+func (h *rpmHandler) processRPMFiles(ctx logContext.Context, reader rpmutils.PayloadReader, archiveChan chan handledData) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -101,7 +101,7 @@ func (h *rpmHandler) processRPMFiles(ctx logContext.Context, reader rpmutils.Pay
 				return fmt.Errorf("error creating mime-type reader: %w", err)
 			}
 
-			if err := h.handleNonArchiveContent(fileCtx, rdr, archiveChan); err != nil {
+			if err := h.handleNonArchiveContent(withContainer(fileCtx, "rpm", fileInfo.Name()), rdr, archiveChan); err != nil {
 				fileCtx.Logger().Error(err, "error handling archive content in RPM")
 				h.metrics.incErrors()
 			}
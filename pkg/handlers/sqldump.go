@@ -0,0 +1,665 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// sqlDumpHeaders start the dumps of the tools that write them, after the
+// lines of the comment boxes pg_dump draws around its header.
+var sqlDumpHeaders = [][]byte{
+	[]byte("-- MySQL dump"),
+	[]byte("-- MariaDB dump"),
+	[]byte("-- phpMyAdmin SQL Dump"),
+	[]byte("-- PostgreSQL database dump"),
+	[]byte("PRAGMA foreign_keys=OFF;"),
+	[]byte("/*!40"),
+}
+
+// isSQLDump matches SQL dumps by the header of the tool that wrote them.
+func isSQLDump(raw []byte, _ uint32) bool {
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	for {
+		raw = bytes.TrimLeft(raw, " \t\r\n")
+		if !bytes.HasPrefix(raw, []byte("--\n")) && !bytes.HasPrefix(raw, []byte("--\r\n")) {
+			break
+		}
+		raw = raw[bytes.IndexByte(raw, '\n')+1:]
+	}
+	for _, header := range sqlDumpHeaders {
+		if bytes.HasPrefix(raw, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMySQLDump tells the dumps of MySQL and MariaDB, whose strings escape
+// quotes with backslashes, apart from those that double them.
+func isMySQLDump(raw []byte) bool {
+	for _, header := range [][]byte{[]byte("-- MySQL"), []byte("-- MariaDB"), []byte("-- phpMyAdmin"), []byte("/*!")} {
+		if bytes.Contains(raw, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSQLStatementSize is the size above which a statement of a dump is
+// handled as it is, rather than parsed.
+const maxSQLStatementSize = 16 << 20
+
+// sqlDumpHandler handles SQL dumps, reading the values of the rows they
+// insert.
+type sqlDumpHandler struct{ *defaultHandler }
+
+// newSQLDumpHandler creates a sqlDumpHandler.
+func newSQLDumpHandler() *sqlDumpHandler {
+	return &sqlDumpHandler{defaultHandler: newDefaultHandler(sqlDumpHandlerType)}
+}
+
+// HandleFile handles a SQL dump with the values of the INSERT statements and
+// COPY blocks it holds written a row at a time, each after the name of its
+// table and column, which the dump only gives in the CREATE TABLE statement
+// of the table. The other statements are handled as they are.
+func (h *sqlDumpHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		d := newSQLDumpReader(input)
+		if err = h.handleRows(ctx, "sql", d.write, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling SQL dump")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// sqlDumpReader reads the statements of a SQL dump.
+type sqlDumpReader struct {
+	r *bufio.Reader
+	// mysql is set for the dumps of MySQL and MariaDB, whose strings escape
+	// with backslashes and whose comments may start with #.
+	mysql bool
+	// columns are the columns of the tables the dump creates, by the names
+	// of the tables.
+	columns map[string][]string
+
+	// The state of the statement being read, which continues across reads
+	// of its parts when it's too large.
+	stmt    []byte
+	quote   byte
+	escapes bool
+	escaped bool
+	comment byte
+}
+
+func newSQLDumpReader(r io.Reader) *sqlDumpReader {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	return &sqlDumpReader{r: br, mysql: isMySQLDump(head), columns: make(map[string][]string)}
+}
+
+// write writes the statements of the dump, the INSERT statements and COPY
+// blocks of which as rows.
+func (d *sqlDumpReader) write(w *rowWriter) error {
+	partial := false
+	for {
+		stmt, complete, err := d.statement()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if partial || !complete {
+			if _, err := w.w.Write(stmt); err != nil {
+				return err
+			}
+		} else if err := d.writeStatement(w, stmt); err != nil {
+			return err
+		}
+		partial = !complete
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// statement reads the next statement of the dump, up to the semicolon that
+// ends it, or the part of it read so far if it's too large.
+func (d *sqlDumpReader) statement() ([]byte, bool, error) {
+	d.stmt = d.stmt[:0]
+	for len(d.stmt) < maxSQLStatementSize {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return d.stmt, true, err
+		}
+		d.stmt = append(d.stmt, c)
+		switch {
+		case d.escaped:
+			d.escaped = false
+		case d.comment == '-':
+			if c == '\n' {
+				d.comment = 0
+			}
+		case d.comment == '*':
+			if c == '/' && bytes.HasSuffix(d.stmt, []byte("*/")) {
+				d.comment = 0
+			}
+		case d.quote != 0:
+			if c == '\\' && d.quote == '\'' && d.escapes {
+				d.escaped = true
+			} else if c == d.quote {
+				d.quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			// Backslashes escape the characters of the strings of MySQL
+			// and of the E'' strings of PostgreSQL, and a quote that
+			// follows the one that has just ended a string is one of its
+			// characters.
+			if c == '\'' && (len(d.stmt) < 2 || d.stmt[len(d.stmt)-2] != '\'') {
+				prev := byte(0)
+				if len(d.stmt) >= 2 {
+					prev = d.stmt[len(d.stmt)-2]
+				}
+				d.escapes = d.mysql || prev == 'E' || prev == 'e'
+			}
+			d.quote = c
+		case c == '-' && bytes.HasSuffix(d.stmt, []byte("--")), c == '#' && d.mysql:
+			d.comment = '-'
+		case c == '*' && bytes.HasSuffix(d.stmt, []byte("/*")):
+			d.comment = '*'
+		case c == ';':
+			return d.stmt, true, nil
+		}
+	}
+	return d.stmt, false, nil
+}
+
+// writeStatement writes a statement of the dump, as rows if it inserts them.
+func (d *sqlDumpReader) writeStatement(w *rowWriter, stmt []byte) error {
+	l := &sqlLexer{s: stmt, mysql: d.mysql}
+	l.skipSpace()
+	start := l.i
+
+	switch {
+	case l.keyword("INSERT"), l.keyword("REPLACE"):
+		if table, columns, rows, ok := d.parseInsert(l); ok {
+			if _, err := w.w.Write(stmt[:start]); err != nil {
+				return err
+			}
+			return writeSQLRows(w, table, columns, rows)
+		}
+	case l.keyword("CREATE"):
+		d.parseCreateTable(l)
+	case l.keyword("COPY"):
+		if _, err := w.w.Write(stmt); err != nil {
+			return err
+		}
+		if table, columns, ok := d.parseCopy(l); ok {
+			return d.writeCopy(w, table, columns)
+		}
+		return nil
+	}
+	_, err := w.w.Write(stmt)
+	return err
+}
+
+// parseInsert parses the table, the columns and the rows of the values of an
+// INSERT statement, one of which is nil for the values that aren't strings.
+func (d *sqlDumpReader) parseInsert(l *sqlLexer) (string, []string, [][]*string, bool) {
+	for l.keyword("LOW_PRIORITY") || l.keyword("DELAYED") || l.keyword("HIGH_PRIORITY") || l.keyword("IGNORE") ||
+		l.keyword("OR") || l.keyword("REPLACE") || l.keyword("ROLLBACK") || l.keyword("ABORT") || l.keyword("FAIL") {
+	}
+	l.keyword("INTO")
+	table, ok := l.name()
+	if !ok {
+		return "", nil, nil, false
+	}
+	columns, ok := l.columnList()
+	if !ok {
+		return "", nil, nil, false
+	}
+	if columns == nil {
+		columns = d.columns[table]
+	}
+	if !l.keyword("VALUES") && !l.keyword("VALUE") {
+		return "", nil, nil, false
+	}
+
+	var rows [][]*string
+	for {
+		row, ok := l.tuple()
+		if !ok {
+			return "", nil, nil, false
+		}
+		rows = append(rows, row)
+		if !l.punct(',') {
+			return table, columns, rows, true
+		}
+	}
+}
+
+// parseCreateTable remembers the columns of the table a CREATE TABLE
+// statement creates.
+func (d *sqlDumpReader) parseCreateTable(l *sqlLexer) {
+	for l.keyword("TEMPORARY") || l.keyword("TEMP") || l.keyword("UNLOGGED") {
+	}
+	if !l.keyword("TABLE") {
+		return
+	}
+	if l.keyword("IF") && !(l.keyword("NOT") && l.keyword("EXISTS")) {
+		return
+	}
+	table, ok := l.name()
+	if !ok || !l.punct('(') {
+		return
+	}
+
+	var columns []string
+	for {
+		l.skipSpace()
+		if !sqlTableConstraint(l) {
+			column, ok := l.name()
+			if !ok {
+				return
+			}
+			columns = append(columns, column)
+		}
+		// Skip the rest of the definition.
+		if !l.skipExpr() {
+			return
+		}
+		if !l.punct(',') {
+			break
+		}
+	}
+	d.columns[table] = columns
+}
+
+// sqlTableConstraint returns true at the start of the definitions of a
+// CREATE TABLE statement that aren't columns. The indexes of MySQL tables
+// are defined there too, whose dumps quote the names of all columns.
+func sqlTableConstraint(l *sqlLexer) bool {
+	keywords := []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE"}
+	if l.mysql {
+		keywords = append(keywords, "KEY", "INDEX", "FULLTEXT", "SPATIAL")
+	}
+	for _, kw := range keywords {
+		if l.peekKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCopy parses the table and the columns of the COPY statements of
+// PostgreSQL, which are followed by the rows they copy.
+func (d *sqlDumpReader) parseCopy(l *sqlLexer) (string, []string, bool) {
+	table, ok := l.name()
+	if !ok {
+		return "", nil, false
+	}
+	columns, ok := l.columnList()
+	if !ok || !l.keyword("FROM") || !l.keyword("STDIN") {
+		return "", nil, false
+	}
+	if columns == nil {
+		columns = d.columns[table]
+	}
+	return table, columns, true
+}
+
+// writeCopy writes the rows of a COPY block, which are lines of values
+// separated by tabs up to the line \., the first of which follows the
+// statement on its line.
+func (d *sqlDumpReader) writeCopy(w *rowWriter, table string, columns []string) error {
+	rest, err := d.r.ReadString('\n')
+	if _, err := w.w.WriteString(rest); err != nil {
+		return err
+	}
+	for err == nil {
+		var line string
+		line, err = d.r.ReadString('\n')
+		if end := strings.TrimRight(line, "\r\n"); end == `\.` {
+			_, err := w.w.WriteString(line)
+			return err
+		} else if end == "" && err != nil {
+			break
+		}
+
+		var row []*string
+		for _, field := range strings.Split(strings.TrimRight(line, "\r\n"), "\t") {
+			if field == `\N` {
+				row = append(row, nil)
+				continue
+			}
+			value := unescapeSQL(field)
+			row = append(row, &value)
+		}
+		if err := writeSQLRows(w, table, columns, [][]*string{row}); err != nil {
+			return err
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// writeSQLRows writes the string values of rows of a table, named after the
+// table and their columns, or after the table alone if their columns aren't
+// known.
+func writeSQLRows(w *rowWriter, table string, columns []string, rows [][]*string) error {
+	for _, row := range rows {
+		for i, v := range row {
+			if v == nil {
+				continue
+			}
+			column := table
+			if i < len(columns) {
+				column = table + "." + columns[i]
+			}
+			if err := w.value(column, *v); err != nil {
+				return err
+			}
+		}
+		if err := w.endRow(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlLexer reads the tokens of a statement.
+type sqlLexer struct {
+	s     []byte
+	i     int
+	mysql bool
+}
+
+// skipSpace skips whitespace and comments.
+func (l *sqlLexer) skipSpace() {
+	for l.i < len(l.s) {
+		switch rest := l.s[l.i:]; {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n':
+			l.i++
+		case bytes.HasPrefix(rest, []byte("--")) || (rest[0] == '#' && l.mysql):
+			end := bytes.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			l.i += end + 1
+		case bytes.HasPrefix(rest, []byte("/*")):
+			end := bytes.Index(rest[2:], []byte("*/"))
+			if end < 0 {
+				l.i = len(l.s)
+			} else {
+				l.i += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isSQLIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// word returns the unquoted word at the lexer, without reading it.
+func (l *sqlLexer) word() string {
+	l.skipSpace()
+	end := l.i
+	for end < len(l.s) && isSQLIdentChar(l.s[end]) {
+		end++
+	}
+	return string(l.s[l.i:end])
+}
+
+func (l *sqlLexer) peekKeyword(kw string) bool { return strings.EqualFold(l.word(), kw) }
+
+// keyword reads the keyword kw, if it's next.
+func (l *sqlLexer) keyword(kw string) bool {
+	if !l.peekKeyword(kw) {
+		return false
+	}
+	l.i += len(kw)
+	return true
+}
+
+// punct reads the character c, if it's next.
+func (l *sqlLexer) punct(c byte) bool {
+	l.skipSpace()
+	if l.i < len(l.s) && l.s[l.i] == c {
+		l.i++
+		return true
+	}
+	return false
+}
+
+// name reads a name, which may be quoted, and qualified by the names of the
+// schema and the database it's in, which are dropped.
+func (l *sqlLexer) name() (string, bool) {
+	var name string
+	for {
+		l.skipSpace()
+		if l.i >= len(l.s) {
+			return "", false
+		}
+		switch c := l.s[l.i]; c {
+		case '`', '"', '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := bytes.IndexByte(l.s[l.i+1:], closing)
+			if end < 0 {
+				return "", false
+			}
+			name = string(l.s[l.i+1 : l.i+1+end])
+			l.i += end + 2
+		default:
+			if name = l.word(); name == "" {
+				return "", false
+			}
+			l.i += len(name)
+		}
+		if l.i >= len(l.s) || l.s[l.i] != '.' {
+			return name, true
+		}
+		l.i++
+	}
+}
+
+// columnList reads the parenthesized list of column names that may follow
+// the name of a table, returning nil if there isn't one.
+func (l *sqlLexer) columnList() ([]string, bool) {
+	if !l.punct('(') {
+		return nil, true
+	}
+	columns := []string{}
+	for {
+		column, ok := l.name()
+		if !ok {
+			return nil, false
+		}
+		columns = append(columns, column)
+		if l.punct(')') {
+			return columns, true
+		}
+		if !l.punct(',') {
+			return nil, false
+		}
+	}
+}
+
+// tuple reads a parenthesized list of values.
+func (l *sqlLexer) tuple() ([]*string, bool) {
+	if !l.punct('(') {
+		return nil, false
+	}
+	var values []*string
+	for {
+		value, ok := l.value()
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+		if l.punct(')') {
+			return values, true
+		}
+		if !l.punct(',') {
+			return nil, false
+		}
+	}
+}
+
+// value reads a value, returning the string it is, or nil if it's any other
+// expression.
+func (l *sqlLexer) value() (*string, bool) {
+	l.skipSpace()
+	var value *string
+	rest := l.s[l.i:]
+	switch {
+	case len(rest) > 0 && rest[0] == '\'':
+		s, ok := l.quoted(l.mysql)
+		if !ok {
+			return nil, false
+		}
+		value = &s
+	case len(rest) > 1 && rest[1] == '\'' && (rest[0] == 'E' || rest[0] == 'e' || rest[0] == 'N' || rest[0] == 'n'):
+		l.i++
+		s, ok := l.quoted(l.mysql || rest[0] == 'E' || rest[0] == 'e')
+		if !ok {
+			return nil, false
+		}
+		value = &s
+	case len(rest) > 0 && rest[0] == '_' && !strings.EqualFold(l.word(), "_binary"):
+		// A string of a character set, such as _utf8mb4'...'.
+		l.i += len(l.word())
+		l.skipSpace()
+		if l.i < len(l.s) && l.s[l.i] == '\'' {
+			s, ok := l.quoted(l.mysql)
+			if !ok {
+				return nil, false
+			}
+			value = &s
+		}
+	}
+	// Skip the rest of the expression, such as the cast of the string.
+	return value, l.skipExpr()
+}
+
+// quoted reads a string in single quotes.
+func (l *sqlLexer) quoted(backslashEscapes bool) (string, bool) {
+	var b strings.Builder
+	for i := l.i + 1; i < len(l.s); i++ {
+		switch c := l.s[i]; {
+		case c == '\\' && backslashEscapes && i+1 < len(l.s):
+			i++
+			b.WriteByte(unescapeSQLByte(l.s[i]))
+		case c == '\'' && i+1 < len(l.s) && l.s[i+1] == '\'':
+			i++
+			b.WriteByte('\'')
+		case c == '\'':
+			l.i = i + 1
+			return b.String(), true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// skipExpr skips to the comma or the closing parenthesis that ends the
+// expression at the lexer.
+func (l *sqlLexer) skipExpr() bool {
+	depth := 0
+	for {
+		l.skipSpace()
+		if l.i >= len(l.s) {
+			return false
+		}
+		switch c := l.s[l.i]; c {
+		case '\'':
+			if _, ok := l.quoted(l.mysql || l.i > 0 && (l.s[l.i-1] == 'E' || l.s[l.i-1] == 'e')); !ok {
+				return false
+			}
+			continue
+		case '"', '`':
+			end := bytes.IndexByte(l.s[l.i+1:], c)
+			if end < 0 {
+				return false
+			}
+			l.i += end + 2
+			continue
+		case '(':
+			depth++
+		case ')', ',':
+			if depth == 0 {
+				return true
+			}
+			if c == ')' {
+				depth--
+			}
+		case ';':
+			return false
+		}
+		l.i++
+	}
+}
+
+// unescapeSQL decodes the backslash escapes of a value of a COPY block.
+func unescapeSQL(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			b.WriteByte(unescapeSQLByte(s[i]))
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unescapeSQLByte(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'v':
+		return '\v'
+	case '0':
+		return 0
+	case 'Z':
+		return 0x1a
+	default:
+		return c
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSQLDumpFile(t *testing.T) {
+	testCases := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{
+			name: "MySQL",
+			dump: "-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)\n" +
+				"/*!40101 SET NAMES utf8mb4 */;\n" +
+				"CREATE TABLE `users` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` varchar(255) DEFAULT 'a, b',\n" +
+				"  `aws_secret` text,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  KEY `name` (`name`(10))\n" +
+				") ENGINE=InnoDB;\n" +
+				"INSERT INTO `users` VALUES (1,'O\\'Brien; Jr','secret\\nkey'),(2,_utf8mb4'b\\\\ob',NULL),(3,NULL,_binary 'raw');\n",
+			want: "-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)\n" +
+				"/*!40101 SET NAMES utf8mb4 */;\n" +
+				"CREATE TABLE `users` (\n" +
+				"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` varchar(255) DEFAULT 'a, b',\n" +
+				"  `aws_secret` text,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  KEY `name` (`name`(10))\n" +
+				") ENGINE=InnoDB;\n" +
+				"users.name: O'Brien; Jr\nusers.aws_secret: secret\nkey\n\n" +
+				"users.name: b\\ob\n\n\n",
+		},
+		{
+			name: "PostgreSQL",
+			dump: "--\n-- PostgreSQL database dump\n--\n\n" +
+				"CREATE TABLE public.tokens (\n    id integer NOT NULL,\n    token text,\n    scopes text[]\n);\n" +
+				"COPY public.tokens (id, token, scopes) FROM stdin;\n" +
+				"1\tghp_copy_token\t{repo}\n" +
+				"2\t\\N\tline\\tbreak\n" +
+				"\\.\n" +
+				"INSERT INTO public.tokens (token, id) VALUES (E'it''s\\n', 3), ('C:\\', 4);\n",
+			want: "--\n-- PostgreSQL database dump\n--\n\n" +
+				"CREATE TABLE public.tokens (\n    id integer NOT NULL,\n    token text,\n    scopes text[]\n);\n" +
+				"COPY public.tokens (id, token, scopes) FROM stdin;\n" +
+				"tokens.id: 1\ntokens.token: ghp_copy_token\ntokens.scopes: {repo}\n\n" +
+				"tokens.id: 2\ntokens.scopes: line\tbreak\n\n" +
+				"\\.\n" +
+				"tokens.token: it's\n\n\ntokens.token: C:\\\n\n\n",
+		},
+		{
+			name: "SQLite",
+			dump: "PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n" +
+				"CREATE TABLE config(key TEXT, value TEXT);\n" +
+				"INSERT INTO config VALUES('stripe_key','sk_live_dump_token');\n" +
+				"INSERT INTO config SELECT 'a', 'b';\n" +
+				"COMMIT;\n",
+			want: "PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n" +
+				"CREATE TABLE config(key TEXT, value TEXT);\n" +
+				"config.key: stripe_key\nconfig.value: sk_live_dump_token\n\n" +
+				"\nINSERT INTO config SELECT 'a', 'b';\n" +
+				"COMMIT;\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, handleRowsFile(t, []byte(tc.dump), sqlDumpMime, "sql"))
+		})
+	}
+}
+
+func TestIsSQLDump(t *testing.T) {
+	assert.True(t, isSQLDump([]byte("\xef\xbb\xbf-- phpMyAdmin SQL Dump\n"), 0))
+	assert.False(t, isSQLDump([]byte("-- migrations\nCREATE TABLE users (id int);\n"), 0))
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// sqliteHandler handles SQLite databases, reading the text values of their
+// tables.
+type sqliteHandler struct{ *defaultHandler }
+
+// newSQLiteHandler creates a sqliteHandler.
+func newSQLiteHandler() *sqliteHandler {
+	return &sqliteHandler{defaultHandler: newDefaultHandler(sqliteHandlerType)}
+}
+
+// HandleFile reads the text values of the first rows of each table of a
+// SQLite database. The database is read in place through a virtual file
+// system of its own, rather than copied to the disk for the driver to open.
+func (h *sqliteHandler) HandleFile(ctx logContext.Context, input fileReader) (chan handledData, error) {
+	dataChan := make(chan handledData, defaultBufferSize)
+
+	go func() {
+		defer close(dataChan)
+
+		// Update the metrics for the file processing.
+		start := time.Now()
+		var err error
+		defer func() {
+			h.measureLatencyAndHandleErrors(start, err)
+			h.metrics.incFilesProcessed()
+		}()
+
+		var size int64
+		if size, err = input.Size(); err != nil {
+			ctx.Logger().Error(err, "error getting file size")
+			return
+		}
+		var db *sql.DB
+		var closeDB func()
+		if db, closeDB, err = openSQLite(io.NewSectionReader(input, 0, size)); err != nil {
+			ctx.Logger().Error(err, "error opening SQLite database")
+			return
+		}
+		defer closeDB()
+
+		if err = h.handleRows(ctx, "sqlite", func(w *rowWriter) error { return writeSQLiteRows(ctx, db, w) }, dataChan); err != nil {
+			ctx.Logger().Error(err, "error handling SQLite database")
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// openSQLite opens the database r holds, read-only. Databases in WAL mode
+// are opened as if they weren't, as their WAL file isn't at hand.
+func openSQLite(r *io.SectionReader) (*sql.DB, func(), error) {
+	vfsName, err := registerSQLiteFS()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error registering file system: %w", err)
+	}
+	name := sqliteFiles.add(r)
+	db, err := sql.Open("sqlite", "file:"+name+"?vfs="+vfsName+"&mode=ro&immutable=1")
+	if err != nil {
+		sqliteFiles.remove(name)
+		return nil, nil, err
+	}
+	// The reader of the database isn't safe for concurrent use.
+	db.SetMaxOpenConns(1)
+	return db, func() {
+		_ = db.Close()
+		sqliteFiles.remove(name)
+	}, nil
+}
+
+// writeSQLiteRows writes the text values of the first rows of each table of
+// db, named after the table and the column they're in. The tables that can't
+// be read, such as the virtual tables of modules the driver lacks, are
+// skipped.
+func writeSQLiteRows(ctx logContext.Context, db *sql.DB, w *rowWriter) error {
+	tables, err := sqliteTables(db)
+	if err != nil {
+		return fmt.Errorf("error listing tables: %w", err)
+	}
+	for _, table := range tables {
+		if err := writeSQLiteTable(db, table, columnarMaxRows(ctx), w); err != nil {
+			ctx.Logger().V(2).Info("error reading SQLite table", "table", table, "error", err)
+		}
+	}
+	return nil
+}
+
+func sqliteTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func writeSQLiteTable(db *sql.DB, table string, maxRows int, w *rowWriter) error {
+	rows, err := db.Query("SELECT * FROM "+quoteSQLiteIdentifier(table)+" LIMIT ?", maxRows)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		for i, v := range values {
+			// Only TEXT values are read, as any column can hold them.
+			if s, ok := v.(string); ok {
+				if err := w.value(table+"."+columns[i], s); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.endRow(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func quoteSQLiteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteFS is the file system of the SQLite databases being read. The
+// driver opens databases by name, rather than from readers, so they're read
+// through a virtual file system, which is registered once as there is no
+// unregistering it safely.
+type sqliteFS struct {
+	mu    sync.Mutex
+	files map[string]*io.SectionReader
+	next  uint64
+}
+
+var (
+	sqliteFiles      = &sqliteFS{files: make(map[string]*io.SectionReader)}
+	registerSQLiteFS = sync.OnceValues(func() (string, error) {
+		name, _, err := vfs.New(sqliteFiles)
+		return name, err
+	})
+)
+
+// add adds a database to the file system, returning its name.
+func (f *sqliteFS) add(r *io.SectionReader) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	name := "db" + strconv.FormatUint(f.next, 10)
+	f.files[name] = r
+	return name
+}
+
+func (f *sqliteFS) remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, name)
+}
+
+func (f *sqliteFS) Open(name string) (fs.File, error) {
+	f.mu.Lock()
+	r, ok := f.files[name]
+	f.mu.Unlock()
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &sqliteFile{SectionReader: io.NewSectionReader(r, 0, r.Size()), name: name}, nil
+}
+
+// sqliteFile is a database file opened from a sqliteFS.
+type sqliteFile struct {
+	*io.SectionReader
+	name string
+}
+
+// sqliteVersionOffset is the offset of the file format versions to write and
+// to read a database with in its header, which are 2 in WAL mode and 1 in
+// rollback journal mode.
+const sqliteVersionOffset = 18
+
+// Read reads the database, as if it were in rollback journal mode. The
+// driver expects a short read, rather than an error, at the end of the file.
+func (f *sqliteFile) Read(p []byte) (int, error) {
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(f.SectionReader, p)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	for i := int64(sqliteVersionOffset); i < sqliteVersionOffset+2; i++ {
+		if i >= offset && i < offset+int64(n) && p[i-offset] == 2 {
+			p[i-offset] = 1
+		}
+	}
+	return n, err
+}
+
+func (f *sqliteFile) Stat() (fs.FileInfo, error) {
+	return sqliteFileInfo{name: f.name, size: f.Size()}, nil
+}
+
+func (f *sqliteFile) Close() error { return nil }
+
+type sqliteFileInfo struct {
+	name string
+	size int64
+}
+
+func (i sqliteFileInfo) Name() string       { return i.name }
+func (i sqliteFileInfo) Size() int64        { return i.size }
+func (i sqliteFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i sqliteFileInfo) ModTime() time.Time { return time.Time{} }
+func (i sqliteFileInfo) IsDir() bool        { return false }
+func (i sqliteFileInfo) Sys() any           { return nil }
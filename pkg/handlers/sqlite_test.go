@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// writeTestSQLite writes a SQLite database of users and their tokens, in the
+// journal mode given.
+func writeTestSQLite(t *testing.T, journalMode string) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		"PRAGMA journal_mode=" + journalMode,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, avatar BLOB)",
+		`CREATE TABLE "api tokens" (user_id INTEGER, token VARCHAR(64), note)`,
+		"INSERT INTO users (name, avatar) VALUES ('alice', x'89504e47'), ('bob', NULL), ('  ', NULL)",
+		`INSERT INTO "api tokens" VALUES (1, 'sk_live_sqlite_token_1', 42), (2, 'sk_live_sqlite_token_2', 'ci')`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHandleSQLiteFile(t *testing.T) {
+	want := "users.name: alice\n\nusers.name: bob\n\n" +
+		"api tokens.token: sk_live_sqlite_token_1\n\n" +
+		"api tokens.token: sk_live_sqlite_token_2\napi tokens.note: ci\n\n"
+
+	t.Run("rollback journal", func(t *testing.T) {
+		assert.Equal(t, want, handleRowsFile(t, writeTestSQLite(t, "DELETE"), sqliteMime, "sqlite"))
+	})
+	t.Run("WAL", func(t *testing.T) {
+		assert.Equal(t, want, handleRowsFile(t, writeTestSQLite(t, "WAL"), sqliteMime, "sqlite"))
+	})
+}
+
+func TestHandleSQLiteFileMaxRows(t *testing.T) {
+	ctx := WithColumnarMaxRows(context.Background(), 1)
+
+	assert.Equal(t,
+		"users.name: alice\n\napi tokens.token: sk_live_sqlite_token_1\n\n",
+		handleRowsFileContext(t, ctx, writeTestSQLite(t, "DELETE"), sqliteMime, "sqlite"))
+}
@@ -0,0 +1,201 @@
+package iac
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cloudFormationSections are the sections of CloudFormation templates whose
+// entries are blocks, and the kinds of the blocks.
+var cloudFormationSections = map[string]string{
+	"Resources":  "resource",
+	"Outputs":    "output",
+	"Parameters": "variable",
+}
+
+// terraformJSONBlocks are the kinds of the blocks of Terraform JSON
+// configurations, and how many levels of names they're nested under.
+var terraformJSONBlocks = map[string]int{
+	"resource": 2,
+	"data":     2,
+	"provider": 1,
+	"module":   1,
+	"output":   1,
+	"variable": 1,
+	"locals":   0,
+}
+
+// parseCloudFormation parses a CloudFormation template in YAML or JSON.
+// Templates that don't parse, such as chunks of larger templates, are nil.
+func parseCloudFormation(data []byte) *Template {
+	root := parseYAML(data)
+	if root == nil {
+		return nil
+	}
+	t := new(Template)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		kind, ok := cloudFormationSections[root.Content[i].Value]
+		section := root.Content[i+1]
+		if !ok || section.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(section.Content); j += 2 {
+			key, value := section.Content[j], section.Content[j+1]
+			b := Block{
+				Kind:      kind,
+				Name:      key.Value,
+				Address:   root.Content[i].Value + "." + key.Value,
+				StartLine: key.Line - 1,
+				EndLine:   lastLine(value) - 1,
+			}
+			b.Attributes = yamlAttributes(value, "")
+			// Properties are what resources are made of, so they're
+			// named without their section.
+			for k := range b.Attributes {
+				b.Attributes[k].Name = strings.TrimPrefix(b.Attributes[k].Name, "Properties.")
+			}
+			t.Blocks = append(t.Blocks, b)
+		}
+	}
+	return t
+}
+
+// parseTerraformJSON parses a Terraform configuration in JSON, as in
+// main.tf.json.
+func parseTerraformJSON(data []byte) *Template {
+	root := parseYAML(data)
+	if root == nil {
+		return nil
+	}
+	t := new(Template)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		kind := root.Content[i].Value
+		depth, ok := terraformJSONBlocks[kind]
+		if !ok {
+			continue
+		}
+		var add func(node *yaml.Node, labels []string, keyLine int)
+		add = func(node *yaml.Node, labels []string, keyLine int) {
+			if len(labels) < depth {
+				if node.Kind != yaml.MappingNode {
+					return
+				}
+				for j := 0; j+1 < len(node.Content); j += 2 {
+					add(node.Content[j+1], append(labels, node.Content[j].Value), node.Content[j].Line)
+				}
+				return
+			}
+			b := Block{Kind: kind, Address: hclAddress(kind, labels), StartLine: keyLine - 1, EndLine: lastLine(node) - 1}
+			if len(labels) > 0 {
+				b.Name = labels[len(labels)-1]
+			}
+			b.Attributes = yamlAttributes(node, "")
+			t.Blocks = append(t.Blocks, b)
+		}
+		add(root.Content[i+1], nil, root.Content[i].Line)
+	}
+	return t
+}
+
+// parseYAML returns the top-level mapping of data, or nil if data isn't a
+// YAML or JSON mapping.
+func parseYAML(data []byte) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	if root := doc.Content[0]; root.Kind == yaml.MappingNode {
+		return root
+	}
+	return nil
+}
+
+// yamlAttributes returns the attributes of the mapping node, whose names are
+// prefixed with prefix. Attributes whose values are intrinsic functions, as
+// in !Ref DBPassword and {"Fn::GetAtt": [...]}, or interpolations, as in
+// "${var.name}", aren't literals, and their value is the strings in them,
+// one per line, which start with the script of a !Sub or the name of what
+// they refer to. Lists are flattened into the attribute they're the value of.
+func yamlAttributes(node *yaml.Node, prefix string) []Attribute {
+	var attrs []Attribute
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			attrs = append(attrs, yamlAttributes(item, prefix)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			name := key.Value
+			if prefix != "" {
+				name = prefix + "." + key.Value
+			}
+			if value.Kind == yaml.ScalarNode || isIntrinsic(value) {
+				attrs = append(attrs, Attribute{
+					Name:      name,
+					Value:     strings.Join(yamlStrings(value), "\n"),
+					Literal:   isLiteral(value),
+					StartLine: key.Line - 1,
+					EndLine:   lastLine(value) - 1,
+				})
+				continue
+			}
+			attrs = append(attrs, yamlAttributes(value, name)...)
+		}
+	}
+	return attrs
+}
+
+// isIntrinsic reports whether node calls a CloudFormation intrinsic function,
+// with either a short tag or a single key.
+func isIntrinsic(node *yaml.Node) bool {
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		return true
+	}
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return false
+	}
+	key := node.Content[0].Value
+	return key == "Ref" || strings.HasPrefix(key, "Fn::")
+}
+
+// isLiteral reports whether node is a string that doesn't refer to anything
+// else, such as a dynamic reference to Secrets Manager or a Terraform
+// interpolation.
+func isLiteral(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!str" &&
+		!strings.HasPrefix(node.Value, "{{resolve:") && !strings.Contains(node.Value, "${")
+}
+
+// yamlStrings returns the strings of node, depth first.
+func yamlStrings(node *yaml.Node) []string {
+	if node.Kind == yaml.ScalarNode {
+		return []string{node.Value}
+	}
+	var values []string
+	for i, child := range node.Content {
+		// The keys of mappings are the names of functions, not their
+		// arguments.
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		values = append(values, yamlStrings(child)...)
+	}
+	return values
+}
+
+// lastLine returns the last line of node, counting from 1 as yaml does.
+func lastLine(node *yaml.Node) int {
+	last := node.Line + strings.Count(strings.TrimRight(node.Value, "\n"), "\n")
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalars start on the line after their indicator.
+		last++
+	}
+	for _, child := range node.Content {
+		if l := lastLine(child); l > last {
+			last = l
+		}
+	}
+	return last
+}
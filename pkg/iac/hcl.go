@@ -0,0 +1,353 @@
+package iac
+
+import (
+	"bytes"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokHeredoc
+	tokPunct
+	tokNewline
+	tokOther
+)
+
+// token is a token of an HCL file. The text of strings and heredocs is their
+// content, without quotes or delimiters.
+type token struct {
+	kind               tokenKind
+	text               string
+	start, end         int
+	startLine, endLine int
+	// interpolated is true for strings and heredocs that interpolate
+	// expressions, as in "${var.name}".
+	interpolated bool
+}
+
+// lexHCL splits data into tokens, leaving out comments. It doesn't fail on
+// data that isn't valid HCL, such as a chunk that starts inside a string,
+// which is lexed as well as it can be.
+func lexHCL(data []byte) []token {
+	var toks []token
+	line := 0
+	for i := 0; i < len(data); {
+		c := data[i]
+		start, startLine := i, line
+		switch {
+		case c == '\n':
+			toks = append(toks, token{kind: tokNewline, start: i, end: i + 1, startLine: line, endLine: line})
+			line++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '#' || bytes.HasPrefix(data[i:], []byte("//")):
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			continue
+		case bytes.HasPrefix(data[i:], []byte("/*")):
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(data) - i - 4
+			}
+			line += bytes.Count(data[i:i+end+4], []byte("\n"))
+			i += end + 4
+			continue
+		case c == '"':
+			text, interpolated, n := lexString(data[i:])
+			i += n
+			line += bytes.Count(data[start:i], []byte("\n"))
+			toks = append(toks, token{kind: tokString, text: text, interpolated: interpolated})
+		case bytes.HasPrefix(data[i:], []byte("<<")):
+			text, n, ok := lexHeredoc(data[i:])
+			if !ok {
+				i += 2
+				toks = append(toks, token{kind: tokOther, text: "<<"})
+				break
+			}
+			i += n
+			line += bytes.Count(data[start:i], []byte("\n"))
+			toks = append(toks, token{kind: tokHeredoc, text: text, interpolated: strings.Contains(text, "${") || strings.Contains(text, "%{")})
+		case isIdentStart(c):
+			for i < len(data) && isIdentPart(data[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokIdent, text: string(data[start:i])})
+		case strings.IndexByte("{}[]()=,:?.", c) >= 0:
+			i++
+			toks = append(toks, token{kind: tokPunct, text: string(c)})
+		default:
+			i++
+			toks = append(toks, token{kind: tokOther, text: string(c)})
+		}
+		t := &toks[len(toks)-1]
+		t.start, t.end, t.startLine, t.endLine = start, i, startLine, line
+	}
+	return toks
+}
+
+// lexString lexes the quoted string at the start of data, returning its
+// unescaped content, whether it interpolates expressions, and its length.
+// Interpolations may contain strings of their own. A string that isn't
+// closed by the end of its line ends there.
+func lexString(data []byte) (string, bool, int) {
+	var b strings.Builder
+	interpolated := false
+	depth := 0
+	i := 1
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			return b.String(), interpolated, i
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch data[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(data[i])
+			}
+			continue
+		case depth == 0 && c == '"':
+			return b.String(), interpolated, i + 1
+		case (c == '$' || c == '%') && i+1 < len(data) && data[i+1] == '{':
+			interpolated = true
+			depth++
+			b.WriteString(string(data[i : i+2]))
+			i++
+			continue
+		case depth > 0 && c == '{':
+			depth++
+		case depth > 0 && c == '}':
+			depth--
+		case depth > 0 && c == '"':
+			// A string in an interpolation.
+			_, _, n := lexString(data[i:])
+			b.Write(data[i : i+n])
+			i += n - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), interpolated, i
+}
+
+// lexHeredoc lexes the heredoc at the start of data, as in <<EOF or <<-EOF,
+// returning its content and its length, or false if data doesn't start with
+// one. A heredoc that isn't closed ends at the end of data.
+func lexHeredoc(data []byte) (string, int, bool) {
+	i := 2
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+	delimStart := i
+	for i < len(data) && isIdentPart(data[i]) {
+		i++
+	}
+	delim := string(data[delimStart:i])
+	if delim == "" || i >= len(data) || (data[i] != '\n' && data[i] != '\r') {
+		return "", 0, false
+	}
+	body := bytes.IndexByte(data[i:], '\n') + i + 1
+	var content []string
+	for pos := body; pos < len(data); {
+		end := bytes.IndexByte(data[pos:], '\n')
+		if end < 0 {
+			end = len(data) - pos
+		}
+		line := string(data[pos : pos+end])
+		if strings.TrimSpace(line) == delim {
+			return strings.Join(content, "\n"), pos + end, true
+		}
+		content = append(content, line)
+		pos += end + 1
+	}
+	return strings.Join(content, "\n"), len(data), true
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c == '-' || ('0' <= c && c <= '9')
+}
+
+// hclParser parses the blocks and attributes of HCL tokens.
+type hclParser struct {
+	data []byte
+	toks []token
+	pos  int
+}
+
+func parseHCL(data []byte) *Template {
+	p := &hclParser{data: data, toks: lexHCL(data)}
+	t := new(Template)
+	top := Block{StartLine: -1}
+	p.parseBody(t, &top, "", true)
+	if len(top.Attributes) > 0 {
+		top.StartLine = top.Attributes[0].StartLine
+		top.EndLine = top.Attributes[len(top.Attributes)-1].EndLine
+		t.Blocks = append(t.Blocks, top)
+	}
+	return t
+}
+
+// parseBody parses the body of block until its closing brace, adding the
+// attributes of nested blocks under prefix. At the top level, blocks are
+// added to t and stray closing braces, such as those of a chunk that starts
+// inside a block, are skipped.
+func (p *hclParser) parseBody(t *Template, block *Block, prefix string, top bool) {
+	for p.pos < len(p.toks) {
+		tok := p.toks[p.pos]
+		switch {
+		case tok.kind == tokNewline:
+			p.pos++
+		case tok.kind == tokPunct && tok.text == "}":
+			p.pos++
+			if !top {
+				return
+			}
+		case p.isAttribute(top):
+			p.pos += 2
+			if p.isPunct(p.pos, "{") {
+				// The attributes of objects, such as the variables of
+				// a Lambda function's environment, are nested.
+				p.pos++
+				p.parseBody(t, block, prefix+tok.text+".", false)
+				continue
+			}
+			start := p.pos
+			p.skipExpression()
+			block.Attributes = append(block.Attributes, p.attribute(prefix+tok.text, tok, start, p.pos))
+		case tok.kind == tokIdent:
+			labels, open, ok := p.blockHeader()
+			if !ok {
+				p.skipExpression()
+				continue
+			}
+			p.pos = open + 1
+			if !top {
+				p.parseBody(t, block, prefix+tok.text+".", false)
+				continue
+			}
+			b := Block{Kind: tok.text, StartLine: tok.startLine}
+			if len(labels) > 0 {
+				b.Name = labels[len(labels)-1]
+			}
+			b.Address = hclAddress(tok.text, labels)
+			p.parseBody(t, &b, "", false)
+			b.EndLine = p.toks[p.pos-1].endLine
+			t.Blocks = append(t.Blocks, b)
+		default:
+			// Closing brackets end expressions, so they're skipped on
+			// their own.
+			start := p.pos
+			p.skipExpression()
+			if p.pos == start {
+				p.pos++
+			}
+		}
+	}
+}
+
+// isAttribute reports whether the current token is the name of an attribute,
+// which is followed by an equals sign. Objects may also quote their keys and
+// follow them with a colon.
+func (p *hclParser) isAttribute(top bool) bool {
+	tok := p.toks[p.pos]
+	switch {
+	case tok.kind == tokIdent && p.isPunct(p.pos+1, "="):
+		return !p.isPunct(p.pos+2, "=")
+	case top:
+		return false
+	case tok.kind == tokIdent || (tok.kind == tokString && !tok.interpolated):
+		return p.isPunct(p.pos+1, "=") || p.isPunct(p.pos+1, ":")
+	}
+	return false
+}
+
+// blockHeader returns the labels of the block whose type is the current
+// token, and the position of its opening brace, or false if the current
+// token doesn't start a block.
+func (p *hclParser) blockHeader() ([]string, int, bool) {
+	var labels []string
+	for i := p.pos + 1; i < len(p.toks); i++ {
+		switch tok := p.toks[i]; {
+		case tok.kind == tokIdent || (tok.kind == tokString && !tok.interpolated):
+			labels = append(labels, tok.text)
+		case tok.kind == tokPunct && tok.text == "{":
+			return labels, i, true
+		default:
+			return nil, 0, false
+		}
+	}
+	return nil, 0, false
+}
+
+// skipExpression moves past the expression at the current token, which ends
+// at the end of its line, unless a bracket is still open, or at the closing
+// brace of its block.
+func (p *hclParser) skipExpression() {
+	depth := 0
+	for ; p.pos < len(p.toks); p.pos++ {
+		tok := p.toks[p.pos]
+		switch {
+		case tok.kind == tokNewline && depth == 0:
+			return
+		case tok.kind == tokPunct && strings.Contains("{[(", tok.text):
+			depth++
+		case tok.kind == tokPunct && strings.Contains("}])", tok.text):
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// attribute returns the attribute name, whose expression is the tokens from
+// start to end.
+func (p *hclParser) attribute(name string, nameTok token, start, end int) Attribute {
+	a := Attribute{Name: name, StartLine: nameTok.startLine, EndLine: nameTok.endLine}
+	if start >= end {
+		return a
+	}
+	first, last := p.toks[start], p.toks[end-1]
+	a.EndLine = last.endLine
+	if start+1 == end && (first.kind == tokString || first.kind == tokHeredoc) {
+		a.Value, a.Literal = first.text, !first.interpolated
+		return a
+	}
+	a.Value = strings.TrimSpace(string(p.data[first.start:last.end]))
+	return a
+}
+
+func (p *hclParser) isPunct(i int, punct string) bool {
+	return i < len(p.toks) && p.toks[i].kind == tokPunct && p.toks[i].text == punct
+}
+
+// hclAddress returns the address Terraform refers to a block of kind with
+// labels by.
+func hclAddress(kind string, labels []string) string {
+	switch {
+	case kind == "resource" && len(labels) == 2:
+		return labels[0] + "." + labels[1]
+	case kind == "data" && len(labels) == 2:
+		return "data." + labels[0] + "." + labels[1]
+	case kind == "variable" && len(labels) == 1:
+		return "var." + labels[0]
+	case kind == "locals":
+		return "local"
+	}
+	return strings.Join(append([]string{kind}, labels...), ".")
+}
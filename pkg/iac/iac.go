@@ -0,0 +1,305 @@
+// Package iac parses infrastructure as code templates, Terraform and other
+// HCL configurations and CloudFormation templates, into the blocks they're
+// made of, so that secrets found in them can be told by the address of the
+// resource they're in rather than just a line number. It also flags the
+// secrets templates are known to leak: credentials hardcoded into providers
+// and resources, secrets in the scripts instances run at boot, and outputs of
+// credentials that aren't marked sensitive.
+package iac
+
+import (
+	"bytes"
+	"encoding/base64"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/credfile"
+)
+
+// Template is a parsed template.
+type Template struct {
+	Blocks []Block
+}
+
+// Block is a top-level block of a template, such as a resource, or a section
+// entry of a CloudFormation template.
+type Block struct {
+	// Kind is the type of the block, such as resource, provider, output or
+	// variable. The resources, outputs and parameters of CloudFormation
+	// templates are resource, output and variable blocks. Attributes that
+	// aren't in a block, such as those of .tfvars files, are in a block
+	// without a kind.
+	Kind string
+	// Name is the name of the block, such as the name of a variable or the
+	// logical ID of a CloudFormation resource.
+	Name string
+	// Address is how the block is referred to, as in aws_instance.web,
+	// provider.aws or var.db_password, and Resources.Database in
+	// CloudFormation templates.
+	Address string
+	// StartLine and EndLine are the lines of the data the block spans,
+	// counting from 0.
+	StartLine, EndLine int
+	Attributes         []Attribute
+}
+
+// Attribute is an attribute of a block.
+type Attribute struct {
+	// Name is the path of the attribute in its block, as in password, or
+	// connection.password for attributes of nested blocks.
+	Name string
+	// Value is the value of the attribute if it's a string, or the text of
+	// its expression if it isn't, such as aws_iam_access_key.ci.secret.
+	Value string
+	// Literal is true if the value is a string that doesn't refer to
+	// anything else.
+	Literal bool
+	// StartLine and EndLine are the lines of the data the attribute spans,
+	// counting from 0.
+	StartLine, EndLine int
+}
+
+var (
+	// cloudFormationPat matches the version or the typed resources every
+	// CloudFormation template has, in YAML and JSON.
+	cloudFormationPat = regexp.MustCompile(`AWSTemplateFormatVersion|["']?Type["']?\s*:\s*["']?(?:AWS|Alexa|Custom)::`)
+	// hclPat matches a block header or an attribute of an HCL file.
+	hclPat = regexp.MustCompile(`(?m)^\s*[A-Za-z_][\w-]*(?:\s+(?:"[^"\n]*"|[A-Za-z_][\w-]*))*\s*\{\s*$|^\s*[A-Za-z_][\w-]*\s*=[^=]`)
+	// templateSuffixes are the suffixes of the files templates are written
+	// in. CloudFormation templates are told apart from other YAML and JSON
+	// files by what's in them.
+	templateSuffixes = []string{
+		".tf", ".tf.json", ".tfvars", ".tfvars.json", ".hcl", ".template",
+		".yaml", ".yml", ".json",
+	}
+)
+
+// IsTemplateFile reports whether the file at path may be a template.
+func IsTemplateFile(filePath string) bool {
+	name := strings.ToLower(path.Base(strings.ReplaceAll(filePath, "\\", "/")))
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses data, which is a whole template or a chunk of it, or returns
+// nil if it isn't a template. The format of the template is told from data:
+// CloudFormation templates have typed resources, Terraform JSON starts with a
+// brace, and HCL has blocks or attributes.
+func Parse(data []byte) *Template {
+	trimmed := bytes.TrimSpace(data)
+	var t *Template
+	switch {
+	case len(trimmed) == 0:
+		return nil
+	case cloudFormationPat.Match(data):
+		t = parseCloudFormation(data)
+	case trimmed[0] == '{':
+		t = parseTerraformJSON(data)
+	case hclPat.Match(data):
+		t = parseHCL(data)
+	}
+	if t == nil || len(t.Blocks) == 0 {
+		return nil
+	}
+	return t
+}
+
+// Locate returns the address of the block of the template data that secret
+// is in, and the attribute it's the value of, if data is a template.
+func Locate(data, secret []byte) (address, attribute string) {
+	secret = bytes.TrimSpace(secret)
+	i := bytes.Index(data, secret)
+	if len(secret) == 0 || i < 0 {
+		return "", ""
+	}
+	t := Parse(data)
+	if t == nil {
+		return "", ""
+	}
+	return t.Locate(bytes.Count(data[:i], []byte("\n")))
+}
+
+// Locate returns the address of the block line is in, and the attribute it's
+// in, if any. Blocks and attributes that span fewer lines are preferred, so
+// that the attribute of a nested block is found rather than the block.
+func (t *Template) Locate(line int) (address, attribute string) {
+	span := -1
+	for _, b := range t.Blocks {
+		for _, a := range b.Attributes {
+			if a.StartLine <= line && line <= a.EndLine && (span < 0 || a.EndLine-a.StartLine < span) {
+				address, attribute, span = b.Address, a.Name, a.EndLine-a.StartLine
+			}
+		}
+	}
+	if span >= 0 {
+		return address, attribute
+	}
+	for _, b := range t.Blocks {
+		if b.Kind != "" && b.StartLine <= line && line <= b.EndLine && (span < 0 || b.EndLine-b.StartLine < span) {
+			address, span = b.Address, b.EndLine-b.StartLine
+		}
+	}
+	return address, ""
+}
+
+// FindingKind is a kind of secret templates leak.
+type FindingKind string
+
+const (
+	// HardcodedCredential is a credential written into a provider, a
+	// resource, or the default of a variable, rather than passed in.
+	HardcodedCredential FindingKind = "hardcoded credential"
+	// UserDataSecret is a secret in the script an instance runs at boot,
+	// which anyone who can describe the instance can read.
+	UserDataSecret FindingKind = "secret in user data"
+	// UnprotectedOutput is an output of a credential that isn't marked
+	// sensitive, so it's printed by every plan and apply.
+	UnprotectedOutput FindingKind = "credential output not marked sensitive"
+)
+
+// Finding is a secret leaked by a template.
+type Finding struct {
+	Kind      FindingKind
+	Address   string
+	Attribute string
+	// Value is the secret, or what an unprotected output refers to.
+	Value string
+}
+
+// userDataAttributes are the attributes of the boot scripts of instances,
+// lowercased without their underscores: user_data and UserData for AWS,
+// custom_data for Azure, and metadata_startup_script for Google Cloud.
+var userDataAttributes = []string{"userdata", "userdatabase64", "customdata", "metadatastartupscript"}
+
+// Findings returns the secrets the template leaks.
+func (t *Template) Findings() []Finding {
+	var findings []Finding
+	for _, b := range t.Blocks {
+		switch b.Kind {
+		case "variable":
+			if !credfile.SensitiveName(b.Name) {
+				continue
+			}
+			for _, a := range b.Attributes {
+				if strings.EqualFold(a.Name, "default") && a.Literal && !credfile.IsPlaceholder(a.Value) {
+					findings = append(findings, Finding{Kind: HardcodedCredential, Address: b.Address, Attribute: a.Name, Value: a.Value})
+				}
+			}
+		case "output":
+			findings = append(findings, outputFindings(b)...)
+		default:
+			for _, a := range b.Attributes {
+				switch {
+				case isUserData(a.Name):
+					findings = append(findings, userDataFindings(b, a)...)
+				case a.Literal && sensitiveAttribute(a.Name) && !credfile.IsPlaceholder(a.Value):
+					findings = append(findings, Finding{Kind: HardcodedCredential, Address: b.Address, Attribute: a.Name, Value: a.Value})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// outputFindings returns the output b if it outputs a credential without
+// being marked sensitive. CloudFormation outputs can't be marked sensitive.
+func outputFindings(b Block) []Finding {
+	var value *Attribute
+	for i, a := range b.Attributes {
+		switch strings.ToLower(a.Name) {
+		case "sensitive":
+			if a.Value == "true" {
+				return nil
+			}
+		case "value":
+			value = &b.Attributes[i]
+		}
+	}
+	if value == nil {
+		return nil
+	}
+	// The finding is the reference to the credential, or the start of the
+	// value of an output named like one.
+	ref, ok := secretReference(value.Value)
+	if !ok {
+		if !credfile.SensitiveName(b.Name) {
+			return nil
+		}
+		ref, _, _ = strings.Cut(value.Value, "\n")
+	}
+	return []Finding{{Kind: UnprotectedOutput, Address: b.Address, Attribute: value.Name, Value: ref}}
+}
+
+// userDataFindings returns the secrets assigned to variables named like
+// secrets in the boot script a, which may be base64 encoded.
+func userDataFindings(b Block, a Attribute) []Finding {
+	script := a.Value
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(script)); err == nil && isText(decoded) {
+		script = string(decoded)
+	}
+	var findings []Finding
+	for _, e := range credfile.Parse([]byte(script)) {
+		if credfile.SensitiveName(e.Name) {
+			findings = append(findings, Finding{Kind: UserDataSecret, Address: b.Address, Attribute: a.Name, Value: e.Value})
+		}
+	}
+	return findings
+}
+
+func isUserData(name string) bool {
+	last := strings.ToLower(strings.ReplaceAll(lastSegment(name), "_", ""))
+	for _, a := range userDataAttributes {
+		if last == a {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveAttribute reports whether the attribute name is named like a
+// secret. An attribute named key alone is left out, since it's the key of an
+// object or a tag far more often than a secret.
+func sensitiveAttribute(name string) bool {
+	last := lastSegment(name)
+	return !strings.EqualFold(last, "key") && credfile.SensitiveName(last)
+}
+
+// secretReference returns the reference of the expression to something named
+// like a secret, as aws_iam_access_key.ci.secret, var.db_password and the
+// !GetAtt Key.SecretAccessKey of CloudFormation are, or to a password or key
+// generated by Terraform.
+func secretReference(expr string) (string, bool) {
+	refs := strings.FieldsFunc(expr, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.'
+	})
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "random_password.") || strings.HasPrefix(ref, "tls_private_key.") || sensitiveAttribute(ref) {
+			return ref, true
+		}
+	}
+	return "", false
+}
+
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// isText reports whether b is printable text, as a decoded script is.
+func isText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+// Package ignorefile parses .trufflehogignore files, which let repository
+// owners keep the suppressions of their findings next to their code.
+//
+// The file uses gitignore syntax for the paths that aren't scanned, plus
+// entries suppressing the findings of a detector or a single finding:
+//
+//	# Paths, relative to the root of the repository or directory.
+//	vendor/
+//	*.min.js
+//	!vendor/internal/
+//
+//	# Findings of a detector, by its name.
+//	detector:URI
+//
+//	# A single finding, by the fingerprint trufflehog reports for it.
+//	fingerprint:3f2a...
+package ignorefile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// Name is the name of the file at the root of a repository or directory.
+const Name = ".trufflehogignore"
+
+const (
+	detectorPrefix    = "detector:"
+	fingerprintPrefix = "fingerprint:"
+)
+
+// File holds the entries of a .trufflehogignore file. A nil File ignores
+// nothing.
+type File struct {
+	paths        gitignore.Matcher
+	detectors    map[string]struct{}
+	fingerprints map[string]struct{}
+}
+
+// Parse reads a .trufflehogignore file.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{
+		detectors:    make(map[string]struct{}),
+		fingerprints: make(map[string]struct{}),
+	}
+	var patterns []gitignore.Pattern
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		switch {
+		case strings.TrimSpace(line) == "", strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, detectorPrefix):
+			name := strings.TrimSpace(strings.TrimPrefix(line, detectorPrefix))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: missing detector name", n)
+			}
+			f.detectors[strings.ToLower(name)] = struct{}{}
+		case strings.HasPrefix(line, fingerprintPrefix):
+			fingerprint := strings.TrimSpace(strings.TrimPrefix(line, fingerprintPrefix))
+			if fingerprint == "" {
+				return nil, fmt.Errorf("line %d: missing fingerprint", n)
+			}
+			f.fingerprints[strings.ToLower(fingerprint)] = struct{}{}
+		default:
+			patterns = append(patterns, gitignore.ParsePattern(line, nil))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	f.paths = gitignore.NewMatcher(patterns)
+	return f, nil
+}
+
+// Load reads the .trufflehogignore file in dir. It returns nil if there is none.
+func Load(dir string) (*File, error) {
+	file, err := os.Open(filepath.Join(dir, Name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	f, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Name(), err)
+	}
+	return f, nil
+}
+
+// Ignores returns true if path, relative to the root the file was found at,
+// shouldn't be scanned.
+func (f *File) Ignores(path string, isDir bool) bool {
+	if f == nil || path == "" || path == "." {
+		return false
+	}
+	return f.paths.Match(strings.Split(filepath.ToSlash(path), "/"), isDir)
+}
+
+// SuppressesDetector returns true if the findings of the detector known by
+// any of the names are suppressed. Names are case-insensitive.
+func (f *File) SuppressesDetector(names ...string) bool {
+	if f == nil {
+		return false
+	}
+	for _, name := range names {
+		if _, ok := f.detectors[strings.ToLower(name)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressesFingerprint returns true if the finding with the fingerprint is
+// suppressed.
+func (f *File) SuppressesFingerprint(fingerprint string) bool {
+	if f == nil {
+		return false
+	}
+	_, ok := f.fingerprints[strings.ToLower(fingerprint)]
+	return ok
+}
+
+// HasFingerprints returns true if the file suppresses findings by
+// fingerprint, so callers can skip computing them otherwise.
+func (f *File) HasFingerprints() bool {
+	return f != nil && len(f.fingerprints) > 0
+}
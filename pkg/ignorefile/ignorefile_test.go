@@ -0,0 +1,78 @@
+package ignorefile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = `# Generated and vendored code.
+vendor/
+*.min.js
+!vendor/keep/
+
+detector:URI
+detector: JDBC
+fingerprint:ABC123
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader(testFile))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "vendor", isDir: true, ignored: true},
+		{path: "vendor/lib/lib.go", ignored: true},
+		{path: "src/vendor/lib.go", ignored: true},
+		{path: "vendor/keep", isDir: true},
+		{path: "static/app.min.js", ignored: true},
+		{path: "static/app.js"},
+		{path: "README.md"},
+		{path: "."},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ignored, f.Ignores(tt.path, tt.isDir), tt.path)
+	}
+
+	assert.True(t, f.SuppressesDetector("uri"))
+	assert.True(t, f.SuppressesDetector("AWS", "JDBC"))
+	assert.False(t, f.SuppressesDetector("AWS", ""))
+	assert.True(t, f.SuppressesFingerprint("abc123"))
+	assert.False(t, f.SuppressesFingerprint("def456"))
+	assert.True(t, f.HasFingerprints())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("*.log\ndetector:\n"))
+	assert.ErrorContains(t, err, "line 2")
+	_, err = Parse(strings.NewReader("fingerprint:   \n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	f, err := Load(dir)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, Name), []byte(testFile), 0o644))
+	f, err = Load(dir)
+	require.NoError(t, err)
+	assert.True(t, f.Ignores("vendor/lib.go", false))
+}
+
+func TestNilFile(t *testing.T) {
+	var f *File
+	assert.False(t, f.Ignores("vendor/lib.go", false))
+	assert.False(t, f.SuppressesDetector("URI"))
+	assert.False(t, f.SuppressesFingerprint("abc123"))
+	assert.False(t, f.HasFingerprints())
+}
@@ -0,0 +1,157 @@
+// Package incremental records what earlier scans have covered so later scans
+// only need to scan what changed since: the last scanned commit of every git
+// repository and the ETag of every scanned bucket object.
+//
+// Sources consult the state of their context, set with WithState. Every method
+// of a nil *State is a no-op, so sources don't need to check whether
+// incremental scanning is on.
+package incremental
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const stateVersion = 1
+
+// stateKey is the context key of the state set by WithState.
+type stateKey struct{}
+
+// WithState returns a copy of ctx in which sources consult and record s.
+// Passing nil disables incremental scanning. The state is scoped to the
+// context so scans with different states can run in the same process.
+func WithState(ctx context.Context, s *State) context.Context {
+	return context.WithValue(ctx, stateKey{}, s)
+}
+
+// FromContext returns the state of ctx, or nil if incremental scanning is
+// off.
+func FromContext(ctx context.Context) *State {
+	s, _ := ctx.Value(stateKey{}).(*State)
+	return s
+}
+
+// State is the record of earlier scans, stored as a JSON file.
+type State struct {
+	path string
+
+	mu   sync.Mutex
+	data stateData
+}
+
+type stateData struct {
+	Version int `json:"version"`
+	// Commits maps a repository to the HEAD commit of its last scan.
+	Commits map[string]string `json:"commits"`
+	// Objects maps a bucket object URL to its ETag when it was last scanned.
+	Objects map[string]string `json:"objects"`
+}
+
+// Open loads the state stored at path. A missing file is an empty state.
+func Open(path string) (*State, error) {
+	s := &State{path: path, data: stateData{
+		Version: stateVersion,
+		Commits: make(map[string]string),
+		Objects: make(map[string]string),
+	}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read incremental scan state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return nil, fmt.Errorf("could not decode incremental scan state: %w", err)
+	}
+	if s.data.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported incremental scan state version %d", s.data.Version)
+	}
+	if s.data.Commits == nil {
+		s.data.Commits = make(map[string]string)
+	}
+	if s.data.Objects == nil {
+		s.data.Objects = make(map[string]string)
+	}
+	return s, nil
+}
+
+// LastCommit returns the commit repo was at when it was last scanned, or an
+// empty string if it hasn't been scanned.
+func (s *State) LastCommit(repo string) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Commits[repo]
+}
+
+// SetLastCommit records that repo has been scanned up to commit.
+func (s *State) SetLastCommit(repo, commit string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Commits[repo] = commit
+}
+
+// Unchanged reports whether object was already scanned with the same etag.
+func (s *State) Unchanged(object, etag string) bool {
+	if s == nil || etag == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Objects[object] == etag
+}
+
+// SetObject records that object has been scanned with etag.
+func (s *State) SetObject(object, etag string) {
+	if s == nil || etag == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Objects[object] = etag
+}
+
+// Save writes the state back to its file. It should only be called once a
+// scan has finished, so an interrupted scan is rescanned in full.
+func (s *State) Save() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.Marshal(s.data)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it so an interrupted write never
+	// loses the previous state.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".trufflehog-state-*")
+	if err != nil {
+		return fmt.Errorf("could not write incremental scan state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write incremental scan state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write incremental scan state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("could not write incremental scan state: %w", err)
+	}
+	return nil
+}
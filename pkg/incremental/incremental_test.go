@@ -0,0 +1,50 @@
+package incremental
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := Open(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.LastCommit("https://github.com/org/repo.git"))
+	assert.False(t, s.Unchanged("s3://bucket/key", "etag"))
+
+	s.SetLastCommit("https://github.com/org/repo.git", "abc123")
+	s.SetObject("s3://bucket/key", "etag")
+	require.NoError(t, s.Save())
+
+	s, err = Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", s.LastCommit("https://github.com/org/repo.git"))
+	assert.True(t, s.Unchanged("s3://bucket/key", "etag"))
+	assert.False(t, s.Unchanged("s3://bucket/key", "new-etag"))
+	assert.False(t, s.Unchanged("s3://bucket/key", ""), "objects without an ETag should always be scanned")
+}
+
+func TestWithState(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+	scoped := WithState(ctx, s)
+	assert.Same(t, s, FromContext(scoped))
+	assert.Nil(t, FromContext(ctx), "the parent context is unchanged")
+}
+
+func TestNilState(t *testing.T) {
+	var s *State
+	s.SetLastCommit("repo", "abc123")
+	s.SetObject("s3://bucket/key", "etag")
+	assert.Empty(t, s.LastCommit("repo"))
+	assert.False(t, s.Unchanged("s3://bucket/key", "etag"))
+	assert.NoError(t, s.Save())
+}
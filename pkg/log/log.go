@@ -32,7 +32,7 @@ func New(service string, configs ...logConfig) (logr.Logger, func() error) {
 		if config.err != nil {
 			continue
 		}
-		cores = append(cores, config.core)
+		cores = append(cores, newRedactionCore(config.core, globalRedactor))
 		if config.cleanup != nil {
 			cleanupFuncs = append(cleanupFuncs, config.cleanup)
 		}
@@ -144,7 +144,7 @@ func AddSink(l logr.Logger, sink logConfig) (logr.Logger, func() error, error) {
 		return l, nil, errors.New("unsupported logr implementation")
 	}
 	zapLogger = zapLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
-		return zapcore.NewTee(core, sink.core)
+		return zapcore.NewTee(core, newRedactionCore(sink.core, globalRedactor))
 	}))
 	return zapr.NewLogger(zapLogger), firstErrorFunc(zapLogger.Sync, sink.cleanup), nil
 }
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// secretRedactor holds the set of secrets that must never be written to a log
+// sink. The replacer is rebuilt lazily whenever the set changes.
+type secretRedactor struct {
+	mu       sync.RWMutex
+	secrets  map[string]struct{}
+	replacer *strings.Replacer
+}
+
+var globalRedactor = &secretRedactor{secrets: make(map[string]struct{})}
+
+const redactedPlaceholder = "[REDACTED]"
+
+// RedactGlobally registers secrets that will be replaced with [REDACTED] in
+// every log entry written after the call, across all loggers created by New.
+func RedactGlobally(secrets ...string) {
+	globalRedactor.add(secrets...)
+}
+
+func (r *secretRedactor) add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range secrets {
+		// Very short values would redact unrelated text.
+		if len(s) < 4 {
+			continue
+		}
+		if _, ok := r.secrets[s]; ok {
+			continue
+		}
+		r.secrets[s] = struct{}{}
+		r.replacer = nil
+	}
+}
+
+func (r *secretRedactor) redact(s string) string {
+	r.mu.RLock()
+	if len(r.secrets) == 0 {
+		r.mu.RUnlock()
+		return s
+	}
+	replacer := r.replacer
+	r.mu.RUnlock()
+
+	if replacer == nil {
+		r.mu.Lock()
+		if r.replacer == nil {
+			secrets := make([]string, 0, len(r.secrets))
+			for secret := range r.secrets {
+				secrets = append(secrets, secret)
+			}
+			// The replacer prefers earlier pairs when secrets overlap, so
+			// longer secrets go first to be replaced whole.
+			sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+			oldnew := make([]string, 0, len(secrets)*2)
+			for _, secret := range secrets {
+				oldnew = append(oldnew, secret, redactedPlaceholder)
+			}
+			r.replacer = strings.NewReplacer(oldnew...)
+		}
+		replacer = r.replacer
+		r.mu.Unlock()
+	}
+	return replacer.Replace(s)
+}
+
+func (r *secretRedactor) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.secrets) == 0
+}
+
+// redactionCore is a zapcore.Core that scrubs registered secrets from the
+// message and string-like fields of every entry before passing it on. It must
+// wrap a leaf core, since it takes over the wrapped core's Check.
+type redactionCore struct {
+	zapcore.Core
+	redactor *secretRedactor
+}
+
+func newRedactionCore(core zapcore.Core, redactor *secretRedactor) zapcore.Core {
+	return &redactionCore{Core: core, redactor: redactor}
+}
+
+func (c *redactionCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactionCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactionCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactionCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.redactor.empty() {
+		return c.Core.Write(ent, fields)
+	}
+	ent.Message = c.redactor.redact(ent.Message)
+	return c.Core.Write(ent, c.redactFields(fields))
+}
+
+func (c *redactionCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	if c.redactor.empty() {
+		return fields
+	}
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = c.redactor.redact(f.String)
+		case zapcore.ErrorType, zapcore.StringerType, zapcore.ReflectType:
+			// Only replace the field when it actually contained a secret, so
+			// structured fields keep their encoding otherwise.
+			if f.Interface == nil {
+				break
+			}
+			var orig string
+			if err, ok := f.Interface.(error); ok {
+				orig = err.Error()
+			} else {
+				orig = fmt.Sprint(f.Interface)
+			}
+			if redacted := c.redactor.redact(orig); redacted != orig {
+				f = zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: redacted}
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
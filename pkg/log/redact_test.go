@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedactionCore(t *testing.T) {
+	var buffer bytes.Buffer
+	redactor := &secretRedactor{secrets: make(map[string]struct{})}
+	core := zapcore.NewCore(
+		zapcore.NewConsoleEncoder(defaultEncoderConfig()),
+		zapcore.AddSync(&buffer),
+		zap.NewAtomicLevelAt(zapcore.DebugLevel),
+	)
+	logger := zapr.NewLogger(zap.New(newRedactionCore(core, redactor)))
+
+	logger.Info("before hunter2abc registered")
+	redactor.add("hunter2abc", "ab")
+	logger.Info("found hunter2abc", "value", "x-hunter2abc-y", "count", 1)
+	logger.Error(errors.New("auth failed for hunter2abc"), "verification")
+	assert.NoError(t, logger.GetSink().(zapr.Underlier).GetUnderlying().Sync())
+
+	lines := splitLines(buffer.String())
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "hunter2abc")
+	assert.Equal(t, "info-0\tfound [REDACTED]\t{\"value\": \"x-[REDACTED]-y\", \"count\": 1}", lines[1])
+	assert.Contains(t, lines[2], "auth failed for [REDACTED]")
+	assert.NotContains(t, lines[2], "hunter2abc")
+
+	// Values shorter than the minimum length are ignored.
+	assert.Equal(t, "abc", redactor.redact("abc"))
+}
+
+func TestRedactOverlappingSecrets(t *testing.T) {
+	// The order the secrets are replaced in mustn't depend on map iteration,
+	// so build the replacer several times.
+	for i := 0; i < 20; i++ {
+		redactor := &secretRedactor{secrets: make(map[string]struct{})}
+		redactor.add("abcd", "abcdefgh", "efgh1234")
+		assert.Equal(t, "x [REDACTED] y [REDACTED]", redactor.redact("x abcdefgh y abcd"))
+	}
+}
@@ -0,0 +1,160 @@
+// Package managedsecrets tells whether found secrets are managed by a secret
+// manager, such as Vault or a cloud provider's secret manager, so a finding
+// can point to where the secret has to be rotated.
+package managedsecrets
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// ExtraDataKey is the extra data of the findings that holds the locations of
+// the managed secrets they match.
+const ExtraDataKey = "managed_secret"
+
+// store lists the secrets of a secret manager.
+type store interface {
+	// walk calls fn with the location and the value of every secret.
+	walk(ctx context.Context, fn func(location, value string)) error
+}
+
+// stores create the store of each spec prefix:
+//
+//	vault:PATH                    secrets under a path of a KV mount, such as secret/ci
+//	aws-sm:[PREFIX]               AWS Secrets Manager secrets, optionally only those whose name starts with PREFIX
+//	gcp-sm:projects/PROJECT       GCP Secret Manager secrets of a project
+//
+// The prefixes match those of the credential references of config files.
+var stores = map[string]func(ctx context.Context, path string) (store, error){
+	"vault":  newVaultStore,
+	"aws-sm": newAWSStore,
+	"gcp-sm": newGCPStore,
+}
+
+// Index holds the hashes of the values of managed secrets. The values
+// themselves aren't kept.
+type Index struct {
+	locations map[[sha256.Size]byte][]string
+}
+
+// Load indexes the secrets of the secret managers of specs.
+func Load(ctx context.Context, specs []string) (*Index, error) {
+	idx := &Index{locations: make(map[[sha256.Size]byte][]string)}
+	for _, spec := range specs {
+		prefix, path, ok := strings.Cut(spec, ":")
+		newStore, known := stores[prefix]
+		if !ok || !known {
+			return nil, fmt.Errorf("invalid secret manager %q: expected one of vault:PATH, aws-sm:[PREFIX] or gcp-sm:projects/PROJECT", spec)
+		}
+		s, err := newStore(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to %s: %w", spec, err)
+		}
+		before := len(idx.locations)
+		if err := s.walk(ctx, idx.add); err != nil {
+			return nil, fmt.Errorf("could not list the secrets of %s: %w", spec, err)
+		}
+		ctx.Logger().V(2).Info("indexed managed secrets", "secret_manager", spec, "values", len(idx.locations)-before)
+	}
+	return idx, nil
+}
+
+// add indexes the value of the secret at location. Values that are JSON
+// objects are indexed by each of their keys as well, as location#key.
+func (idx *Index) add(location, value string) {
+	idx.addValue(location, value)
+
+	var fields map[string]any
+	if json.Unmarshal([]byte(value), &fields) != nil {
+		return
+	}
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if field, ok := fields[key].(string); ok {
+			idx.addValue(location+"#"+key, field)
+		}
+	}
+}
+
+func (idx *Index) addValue(location, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	sum := sha256.Sum256([]byte(value))
+	if !slices.Contains(idx.locations[sum], location) {
+		idx.locations[sum] = append(idx.locations[sum], location)
+	}
+}
+
+// Lookup returns the locations of the managed secrets that have the value of
+// the secret of r.
+func (idx *Index) Lookup(r *detectors.Result) []string {
+	raw, rawV2 := strings.TrimSpace(string(r.Raw)), strings.TrimSpace(string(r.RawV2))
+	candidates := []string{raw, rawV2}
+	// Multi-part secrets often store the ID in Raw and append the secret to
+	// form RawV2, and the secret alone is what a secret manager would hold.
+	if rest, ok := strings.CutPrefix(rawV2, raw); ok && raw != "" {
+		candidates = append(candidates, strings.TrimSpace(rest))
+	}
+
+	var locations []string
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		for _, location := range idx.locations[sha256.Sum256([]byte(candidate))] {
+			if !slices.Contains(locations, location) {
+				locations = append(locations, location)
+			}
+		}
+	}
+	return locations
+}
+
+// resultsDispatcher mirrors engine.ResultsDispatcher, which can't be
+// referenced here without an import cycle.
+type resultsDispatcher interface {
+	Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error
+}
+
+// Dispatcher annotates the results that match a managed secret with the
+// locations of the secret before dispatching them.
+type Dispatcher struct {
+	index *Index
+	next  resultsDispatcher
+}
+
+// NewDispatcher creates a Dispatcher that annotates results with the managed
+// secrets of index and hands them to next.
+func NewDispatcher(index *Index, next resultsDispatcher) *Dispatcher {
+	return &Dispatcher{index: index, next: next}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error {
+	if locations := d.index.Lookup(&result.Result); len(locations) > 0 {
+		// The extra data can be shared with other results of the same
+		// detector, so it is copied rather than modified.
+		extra := maps.Clone(result.ExtraData)
+		if extra == nil {
+			extra = make(map[string]string, 1)
+		}
+		extra[ExtraDataKey] = strings.Join(locations, ", ")
+		result.ExtraData = extra
+	}
+	return d.next.Dispatch(ctx, result)
+}
+
+// Flush flushes the next dispatcher if it buffers its output.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if f, ok := d.next.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
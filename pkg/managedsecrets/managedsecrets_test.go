@@ -0,0 +1,138 @@
+package managedsecrets
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func newIndex() *Index {
+	return &Index{locations: make(map[[sha256.Size]byte][]string)}
+}
+
+func TestIndexLookup(t *testing.T) {
+	idx := newIndex()
+	idx.add("aws-sm:prod/github", `{"token": "ghp_managed", "port": 443}`)
+	idx.add("vault:secret/data/ci", `{"token": "ghp_managed"}`)
+	idx.add("gcp-sm:projects/p/secrets/stripe", "sk_live_managed\n")
+
+	assert.Equal(t, []string{"aws-sm:prod/github#token", "vault:secret/data/ci#token"}, idx.Lookup(&detectors.Result{Raw: []byte("ghp_managed")}))
+	assert.Equal(t, []string{"gcp-sm:projects/p/secrets/stripe"}, idx.Lookup(&detectors.Result{Raw: []byte("sk_live_managed")}))
+	assert.Equal(t, []string{"gcp-sm:projects/p/secrets/stripe"}, idx.Lookup(&detectors.Result{Raw: []byte("acct"), RawV2: []byte("acctsk_live_managed")}))
+	assert.Empty(t, idx.Lookup(&detectors.Result{Raw: []byte("ghp_other")}))
+	assert.Empty(t, idx.Lookup(&detectors.Result{Raw: []byte("443")}))
+}
+
+type recordingDispatcher struct {
+	results []detectors.ResultWithMetadata
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, r detectors.ResultWithMetadata) error {
+	d.results = append(d.results, r)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	idx := newIndex()
+	idx.add("vault:secret/data/ci", `{"token": "ghp_managed"}`)
+	next := new(recordingDispatcher)
+	d := NewDispatcher(idx, next)
+
+	extra := map[string]string{"account": "octocat"}
+	ctx := context.Background()
+	require.NoError(t, d.Dispatch(ctx, detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte("ghp_managed"), ExtraData: extra}}))
+	require.NoError(t, d.Dispatch(ctx, detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte("ghp_other"), ExtraData: extra}}))
+
+	require.Len(t, next.results, 2)
+	assert.Equal(t, map[string]string{"account": "octocat", ExtraDataKey: "vault:secret/data/ci#token"}, next.results[0].ExtraData)
+	assert.Equal(t, map[string]string{"account": "octocat"}, next.results[1].ExtraData)
+	assert.Equal(t, map[string]string{"account": "octocat"}, extra)
+}
+
+func TestVaultStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/sys/internal/ui/mounts/secret/apps":
+			_, _ = w.Write([]byte(`{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`))
+		case "LIST /v1/secret/metadata/apps":
+			_, _ = w.Write([]byte(`{"data": {"keys": ["ci", "web/"]}}`))
+		case "LIST /v1/secret/metadata/apps/web/":
+			_, _ = w.Write([]byte(`{"data": {"keys": ["stripe"]}}`))
+		case "GET /v1/secret/data/apps/ci":
+			_, _ = w.Write([]byte(`{"data": {"data": {"token": "ghp_managed"}, "metadata": {"version": 3}}}`))
+		case "GET /v1/secret/data/apps/web/stripe":
+			_, _ = w.Write([]byte(`{"data": {"data": {"key": "sk_live_managed"}, "metadata": {"version": 1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+
+	ctx := context.Background()
+	idx, err := Load(ctx, []string{"vault:secret/apps"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vault:secret/data/apps/ci#token"}, idx.Lookup(&detectors.Result{Raw: []byte("ghp_managed")}))
+	assert.Equal(t, []string{"vault:secret/data/apps/web/stripe#key"}, idx.Lookup(&detectors.Result{Raw: []byte("sk_live_managed")}))
+
+	_, err = Load(ctx, []string{"vault:kv/apps"})
+	assert.Error(t, err)
+}
+
+type fakeSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+	values map[string]string
+}
+
+func (f *fakeSecretsManager) ListSecretsPagesWithContext(_ aws.Context, _ *secretsmanager.ListSecretsInput, fn func(*secretsmanager.ListSecretsOutput, bool) bool, _ ...request.Option) error {
+	for name := range f.values {
+		if !fn(&secretsmanager.ListSecretsOutput{SecretList: []*secretsmanager.SecretListEntry{{Name: aws.String(name)}}}, false) {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeSecretsManager) GetSecretValueWithContext(_ aws.Context, in *secretsmanager.GetSecretValueInput, _ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	value := f.values[aws.StringValue(in.SecretId)]
+	if value == "" {
+		return nil, &secretsmanager.ResourceNotFoundException{}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func TestAWSStore(t *testing.T) {
+	s := &awsStore{
+		client: &fakeSecretsManager{values: map[string]string{
+			"prod/github":  `{"token": "ghp_managed"}`,
+			"prod/deleted": "",
+			"staging/prod": "sk_live_managed",
+		}},
+		prefix: "prod/",
+	}
+	idx := newIndex()
+	require.NoError(t, s.walk(context.Background(), idx.add))
+	assert.Equal(t, []string{"aws-sm:prod/github#token"}, idx.Lookup(&detectors.Result{Raw: []byte("ghp_managed")}))
+	assert.Empty(t, idx.Lookup(&detectors.Result{Raw: []byte("sk_live_managed")}))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	ctx := context.Background()
+	for _, spec := range []string{"vault", "keepass:db", "gcp-sm:my-project"} {
+		_, err := Load(ctx, []string{spec})
+		assert.Error(t, err, spec)
+	}
+}
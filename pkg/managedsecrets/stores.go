@@ -0,0 +1,278 @@
+package managedsecrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"google.golang.org/api/iterator"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// errNotFound is returned by vaultStore.get for paths that don't exist.
+var errNotFound = errors.New("not found")
+
+// vaultStore lists the secrets under a path of a KV secrets engine of the
+// Vault server at VAULT_ADDR, with the token in VAULT_TOKEN or ~/.vault-token.
+type vaultStore struct {
+	client    *http.Client
+	addr      string
+	token     string
+	namespace string
+
+	// mount is the path of the KV mount, with a trailing slash, and rel the
+	// path of the secrets inside of it.
+	mount, rel string
+	version2   bool
+}
+
+func newVaultStore(ctx context.Context, path string) (store, error) {
+	s := &vaultStore{
+		client:    common.SaneHttpClient(),
+		addr:      strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+	if s.addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	if s.token == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set")
+		}
+		data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return nil, errors.New("VAULT_TOKEN is not set and ~/.vault-token can't be read")
+		}
+		s.token = strings.TrimSpace(string(data))
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, errors.New("the path of a KV mount is required")
+	}
+	var mount struct {
+		Path    string            `json:"path"`
+		Options map[string]string `json:"options"`
+	}
+	if err := s.get(ctx, http.MethodGet, "sys/internal/ui/mounts/"+path, &mount); err != nil {
+		return nil, fmt.Errorf("could not look up the mount of %s: %w", path, err)
+	}
+	s.mount = mount.Path
+	s.rel = strings.Trim(strings.TrimPrefix(path, strings.TrimSuffix(s.mount, "/")), "/")
+	s.version2 = mount.Options["version"] == "2"
+	return s, nil
+}
+
+func (s *vaultStore) walk(ctx context.Context, fn func(location, value string)) error {
+	return s.walkPath(ctx, s.rel, fn)
+}
+
+// walkPath walks the secrets under rel, which is a secret if it isn't a
+// folder.
+func (s *vaultStore) walkPath(ctx context.Context, rel string, fn func(location, value string)) error {
+	listPath := s.mount + rel
+	if s.version2 {
+		listPath = s.mount + "metadata/" + rel
+	}
+	var list struct {
+		Keys []string `json:"keys"`
+	}
+	err := s.get(ctx, "LIST", listPath, &list)
+	if errors.Is(err, errNotFound) {
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			return nil
+		}
+		return s.read(ctx, rel, fn)
+	}
+	if err != nil {
+		return err
+	}
+	if rel != "" && !strings.HasSuffix(rel, "/") {
+		rel += "/"
+	}
+	for _, key := range list.Keys {
+		if strings.HasSuffix(key, "/") {
+			err = s.walkPath(ctx, rel+key, fn)
+		} else {
+			err = s.read(ctx, rel+key, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read reads the secret at rel. Its location is the path it is read from,
+// which can be used in a ${vault:PATH#KEY} credential reference as is.
+func (s *vaultStore) read(ctx context.Context, rel string, fn func(location, value string)) error {
+	path := s.mount + rel
+	if s.version2 {
+		path = s.mount + "data/" + rel
+	}
+	var secret map[string]json.RawMessage
+	if err := s.get(ctx, http.MethodGet, path, &secret); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+	if s.version2 {
+		// Version 2 of the KV secrets engine nests the secret's data next
+		// to its metadata.
+		data := secret["data"]
+		secret = nil
+		if err := json.Unmarshal(data, &secret); err != nil {
+			return fmt.Errorf("could not read %s: %w", path, err)
+		}
+	}
+	value, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	fn("vault:"+path, string(value))
+	return nil
+}
+
+// get sends a request for path and decodes the data of the response into v.
+func (s *vaultStore) get(ctx context.Context, method, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.addr+"/v1/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return errNotFound
+	default:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body.Data, v)
+}
+
+// awsStore lists the AWS Secrets Manager secrets of the default region with
+// the default AWS credentials.
+type awsStore struct {
+	client secretsmanageriface.SecretsManagerAPI
+	prefix string
+}
+
+func newAWSStore(_ context.Context, prefix string) (store, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, err
+	}
+	return &awsStore{client: secretsmanager.New(sess), prefix: prefix}, nil
+}
+
+func (s *awsStore) walk(ctx context.Context, fn func(location, value string)) error {
+	input := &secretsmanager.ListSecretsInput{}
+	if s.prefix != "" {
+		input.Filters = []*secretsmanager.Filter{{
+			Key:    aws.String(secretsmanager.FilterNameStringTypeName),
+			Values: []*string{aws.String(s.prefix)},
+		}}
+	}
+	var names []string
+	err := s.client.ListSecretsPagesWithContext(ctx, input, func(page *secretsmanager.ListSecretsOutput, _ bool) bool {
+		for _, secret := range page.SecretList {
+			// The name filter matches prefixes of any word of the name.
+			if strings.HasPrefix(aws.StringValue(secret.Name), s.prefix) {
+				names = append(names, aws.StringValue(secret.Name))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		out, err := s.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+		if err != nil {
+			// Secrets scheduled for deletion, or that the credentials don't
+			// have access to, are skipped.
+			ctx.Logger().V(1).Info("skipping secret that can't be read", "secret", name, "error", err)
+			continue
+		}
+		value := string(out.SecretBinary)
+		if out.SecretString != nil {
+			value = *out.SecretString
+		}
+		fn("aws-sm:"+name, value)
+	}
+	return nil
+}
+
+// gcpStore lists the latest versions of the GCP Secret Manager secrets of a
+// project with the application default credentials.
+type gcpStore struct {
+	project string
+}
+
+func newGCPStore(_ context.Context, project string) (store, error) {
+	if !strings.HasPrefix(project, "projects/") || strings.Count(project, "/") != 1 {
+		return nil, fmt.Errorf("invalid project %q: expected projects/PROJECT", project)
+	}
+	return &gcpStore{project: project}, nil
+}
+
+func (s *gcpStore) walk(ctx context.Context, fn func(location, value string)) error {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	secrets := client.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{Parent: s.project})
+	for {
+		secret, err := secrets.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+			Name: secret.GetName() + "/versions/latest",
+		})
+		if err != nil {
+			// Secrets without an enabled version are skipped.
+			ctx.Logger().V(1).Info("skipping secret that can't be read", "secret", secret.GetName(), "error", err)
+			continue
+		}
+		fn("gcp-sm:"+secret.GetName(), string(resp.GetPayload().GetData()))
+	}
+}
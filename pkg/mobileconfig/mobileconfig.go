@@ -0,0 +1,204 @@
+// Package mobileconfig parses the config files mobile apps ship with, which
+// the Firebase and Google Maps SDKs read their API keys from: the
+// google-services.json of Android apps, the GoogleService-Info.plist of iOS
+// apps, and the string resources of Android apps, such as
+// res/values/strings.xml.
+package mobileconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// App is the app a config file belongs to. Google API keys are often
+// restricted to the apps they're made for, which present themselves with
+// these when they call Google APIs.
+type App struct {
+	ProjectID string
+	// AndroidPackage and AndroidCert are the package name of an Android app
+	// and the SHA-1 fingerprint of the certificate it's signed with.
+	AndroidPackage string
+	AndroidCert    string
+	// IOSBundle is the bundle ID of an iOS app.
+	IOSBundle string
+}
+
+// Entry is a named value of a config file.
+type Entry struct {
+	Name  string
+	Value string
+	App   App
+}
+
+// IsConfigFile reports whether the file at path is a google-services.json, a
+// GoogleService-Info.plist, or one of them for another environment, as in
+// GoogleService-Info-Staging.plist, or an Android resource file, which are in
+// a values directory of an app's resources.
+func IsConfigFile(filePath string) bool {
+	slashed := strings.ReplaceAll(filePath, "\\", "/")
+	name := strings.ToLower(path.Base(slashed))
+	switch {
+	case name == "google-services.json":
+		return true
+	case strings.HasPrefix(name, "googleservice-info") && strings.HasSuffix(name, ".plist"):
+		return true
+	case strings.HasSuffix(name, ".xml"):
+		return strings.HasPrefix(path.Base(path.Dir(slashed)), "values")
+	}
+	return false
+}
+
+// Parse returns the named string values of data, which is a whole config
+// file or a chunk of it. The format is told from data: google-services.json
+// is a JSON object, and property lists and Android resources are XML. Binary
+// property lists aren't parsed.
+func Parse(data []byte) []Entry {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil
+	case trimmed[0] == '{':
+		return parseGoogleServices(data)
+	}
+	return parseXML(data)
+}
+
+// Variable returns the name of the value of data that secret is in, if any.
+func Variable(data, secret []byte) string {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return ""
+	}
+	for _, e := range Parse(data) {
+		if strings.Contains(e.Value, string(secret)) {
+			return e.Name
+		}
+	}
+	return ""
+}
+
+// googleServices is the part of a google-services.json the SDKs read keys
+// and the apps they're for from.
+type googleServices struct {
+	ProjectInfo map[string]any `json:"project_info"`
+	Client      []struct {
+		ClientInfo struct {
+			AndroidClientInfo struct {
+				PackageName string `json:"package_name"`
+			} `json:"android_client_info"`
+		} `json:"client_info"`
+		OAuthClient []struct {
+			AndroidInfo struct {
+				PackageName     string `json:"package_name"`
+				CertificateHash string `json:"certificate_hash"`
+			} `json:"android_info"`
+		} `json:"oauth_client"`
+		APIKey []struct {
+			CurrentKey string `json:"current_key"`
+		} `json:"api_key"`
+	} `json:"client"`
+}
+
+// parseGoogleServices returns the project info of a google-services.json,
+// and the API keys of its clients, which are named api_key.
+func parseGoogleServices(data []byte) []Entry {
+	var config googleServices
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	projectID, _ := config.ProjectInfo["project_id"].(string)
+
+	var entries []Entry
+	for _, name := range slices.Sorted(maps.Keys(config.ProjectInfo)) {
+		if s, ok := config.ProjectInfo[name].(string); ok {
+			entries = append(entries, Entry{Name: name, Value: s, App: App{ProjectID: projectID}})
+		}
+	}
+	for _, client := range config.Client {
+		app := App{ProjectID: projectID, AndroidPackage: client.ClientInfo.AndroidClientInfo.PackageName}
+		for _, oauth := range client.OAuthClient {
+			if oauth.AndroidInfo.PackageName == app.AndroidPackage && oauth.AndroidInfo.CertificateHash != "" {
+				app.AndroidCert = oauth.AndroidInfo.CertificateHash
+				break
+			}
+		}
+		for _, key := range client.APIKey {
+			if key.CurrentKey != "" {
+				entries = append(entries, Entry{Name: "api_key", Value: key.CurrentKey, App: app})
+			}
+		}
+	}
+	return entries
+}
+
+// parseXML returns the strings of a property list, named by the keys before
+// them, and the strings of Android resources, named by their name attribute.
+// Since chunks of files may not be well-formed, what's before the first
+// error is returned.
+func parseXML(data []byte) []Entry {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+
+	var (
+		entries []Entry
+		// key is the name of the next string, and text the text of the
+		// element being read.
+		key  string
+		text strings.Builder
+	)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			text.Reset()
+			if tok.Name.Local == "string" {
+				for _, attr := range tok.Attr {
+					if attr.Name.Local == "name" {
+						key = attr.Value
+					}
+				}
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "key":
+				key = strings.TrimSpace(text.String())
+			case "string":
+				if key != "" {
+					entries = append(entries, Entry{Name: key, Value: strings.TrimSpace(text.String())})
+				}
+				key = ""
+			default:
+				// A key followed by anything but a string, such as
+				// <true/>, names nothing.
+				key = ""
+			}
+			text.Reset()
+		}
+	}
+
+	// Property lists, and the resources the Google services plugin
+	// generates, name the project and app they're for.
+	var app App
+	for _, e := range entries {
+		switch e.Name {
+		case "PROJECT_ID", "project_id":
+			app.ProjectID = e.Value
+		case "BUNDLE_ID":
+			app.IOSBundle = e.Value
+		}
+	}
+	for i := range entries {
+		entries[i].App = app
+	}
+	return entries
+}
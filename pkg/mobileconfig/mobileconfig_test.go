@@ -0,0 +1,94 @@
+package mobileconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Entry
+	}{
+		{
+			name: "google-services.json",
+			data: `{
+  "project_info": {"project_number": "123", "project_id": "demo"},
+  "client": [
+    {
+      "client_info": {"android_client_info": {"package_name": "com.demo.app"}},
+      "oauth_client": [
+        {"client_type": 3},
+        {"client_type": 1, "android_info": {"package_name": "com.demo.other", "certificate_hash": "aa"}},
+        {"client_type": 1, "android_info": {"package_name": "com.demo.app", "certificate_hash": "bb"}}
+      ],
+      "api_key": [{"current_key": "key-1"}, {"current_key": ""}]
+    },
+    {
+      "client_info": {"android_client_info": {"package_name": "com.demo.lite"}},
+      "api_key": [{"current_key": "key-2"}]
+    }
+  ]
+}`,
+			want: []Entry{
+				{Name: "project_id", Value: "demo", App: App{ProjectID: "demo"}},
+				{Name: "project_number", Value: "123", App: App{ProjectID: "demo"}},
+				{Name: "api_key", Value: "key-1", App: App{ProjectID: "demo", AndroidPackage: "com.demo.app", AndroidCert: "bb"}},
+				{Name: "api_key", Value: "key-2", App: App{ProjectID: "demo", AndroidPackage: "com.demo.lite"}},
+			},
+		},
+		{
+			name: "property list",
+			data: "<plist><dict>\n<key>API_KEY</key>\n<string> key-3 </string>\n<key>IS_ADS_ENABLED</key><false/>\n<string>unnamed</string>\n<key>BUNDLE_ID</key><string>com.demo.App</string>\n</dict></plist>",
+			want: []Entry{
+				{Name: "API_KEY", Value: "key-3", App: App{IOSBundle: "com.demo.App"}},
+				{Name: "BUNDLE_ID", Value: "com.demo.App", App: App{IOSBundle: "com.demo.App"}},
+			},
+		},
+		{
+			name: "string resources",
+			data: "<resources>\n<string name=\"google_api_key\" translatable=\"false\">key-4</string>\n<string name=\"project_id\">demo</string>\n<integer name=\"count\">1</integer>\n</resources>",
+			want: []Entry{
+				{Name: "google_api_key", Value: "key-4", App: App{ProjectID: "demo"}},
+				{Name: "project_id", Value: "demo", App: App{ProjectID: "demo"}},
+			},
+		},
+		{
+			// A chunk that ends in the middle of an element.
+			name: "truncated",
+			data: "<string name=\"a_key\">key-5</string>\n<string name=\"b_key\">key-",
+			want: []Entry{{Name: "a_key", Value: "key-5"}},
+		},
+		{
+			name: "not a config",
+			data: `{"name": "app"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse([]byte(tt.data)))
+		})
+	}
+}
+
+func TestVariable(t *testing.T) {
+	data := []byte("<resources>\n<string name=\"maps_key\">AIzaExample</string>\n</resources>")
+	assert.Equal(t, "maps_key", Variable(data, []byte("AIzaExample")))
+	assert.Empty(t, Variable(data, []byte("missing")))
+	assert.Empty(t, Variable(data, nil))
+}
+
+func TestIsConfigFile(t *testing.T) {
+	for _, file := range []string{
+		"app/google-services.json", "app/src/debug/google-services.json", "GoogleService-Info.plist",
+		"ios/GoogleService-Info-Prod.plist", "app/src/main/res/values/strings.xml", "res/values-night/keys.xml",
+	} {
+		assert.True(t, IsConfigFile(file), file)
+	}
+	for _, file := range []string{"", "services.json", "Info.plist", "AndroidManifest.xml", "res/layout/main.xml"} {
+		assert.False(t, IsConfigFile(file), file)
+	}
+}
@@ -0,0 +1,124 @@
+// Package ocr recognizes the text of images, such as screenshots of consoles
+// and config files pasted in wikis and tickets, so the keys visible in them
+// can be scanned. The text is recognized outside of TruffleHog, by tesseract,
+// by another executable that is sent an image on its stdin and writes its
+// text to its stdout, or by an HTTP endpoint that is POSTed an image and
+// answers with its text.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// maxTextSize caps the text read for an image.
+const maxTextSize = 1 << 20 // 1 MB
+
+// Recognizer recognizes the text of PNG and JPEG images.
+type Recognizer interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// New returns the recognizer of spec, which is the URL of an HTTP endpoint,
+// or the name or path of an executable that is run for each image.
+func New(spec string) (Recognizer, error) {
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		return &endpointRecognizer{client: common.SaneHttpClient(), url: spec}, nil
+	}
+	path, err := exec.LookPath(spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not find OCR executable %s: %w", spec, err)
+	}
+	r := &commandRecognizer{path: path}
+	// tesseract reads and writes files, stdin and stdout among them.
+	if strings.TrimSuffix(filepath.Base(path), ".exe") == "tesseract" {
+		r.args = []string{"stdin", "stdout"}
+	}
+	return r, nil
+}
+
+// IsImage returns true for the MIME types of the images text is recognized in.
+func IsImage(mimeType string) bool {
+	return mimeType == "image/png" || mimeType == "image/jpeg"
+}
+
+// IsImageFile returns true for the extensions of the images text is
+// recognized in.
+func IsImageFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	default:
+		return false
+	}
+}
+
+// endpointRecognizer POSTs images to an HTTP endpoint.
+type endpointRecognizer struct {
+	client *http.Client
+	url    string
+}
+
+func (r *endpointRecognizer) Recognize(ctx context.Context, image []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(image))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", http.DetectContentType(image))
+	res, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OCR endpoint returned %s", res.Status)
+	}
+	text, err := io.ReadAll(io.LimitReader(res.Body, maxTextSize))
+	if err != nil {
+		return "", fmt.Errorf("could not read OCR response: %w", err)
+	}
+	return string(text), nil
+}
+
+// commandRecognizer runs an executable for each image.
+type commandRecognizer struct {
+	path string
+	args []string
+}
+
+func (r *commandRecognizer) Recognize(ctx context.Context, image []byte) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.path, r.args...)
+	cmd.Stdin = bytes.NewReader(image)
+	cmd.Stdout = &limitedBuffer{Buffer: &stdout, limit: maxTextSize}
+	cmd.Stderr = &limitedBuffer{Buffer: &stderr, limit: 4096}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", filepath.Base(r.path), err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", filepath.Base(r.path), err)
+	}
+	return stdout.String(), nil
+}
+
+// limitedBuffer drops what is written to it past its limit.
+type limitedBuffer struct {
+	*bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
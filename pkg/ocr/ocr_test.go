@@ -0,0 +1,76 @@
+package ocr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestEndpointRecognizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, png, body)
+		assert.Equal(t, "image/png", r.Header.Get("Content-Type"))
+		_, _ = w.Write([]byte("export API_TOKEN=example\n"))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+	text, err := r.Recognize(ctx, png)
+	require.NoError(t, err)
+	assert.Equal(t, "export API_TOKEN=example\n", text)
+
+	r, err = New(srv.URL + "/down")
+	require.NoError(t, err)
+	_, err = r.Recognize(ctx, png)
+	assert.ErrorContains(t, err, "503")
+}
+
+func TestCommandRecognizer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test recognizer is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ocr.sh")
+	script := "#!/bin/sh\nif [ \"$(head -c 4 | tail -c 3)\" != PNG ]; then echo 'not an image' >&2; exit 1; fi\necho 'key: example'\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+
+	ctx := context.Background()
+	r, err := New(path)
+	require.NoError(t, err)
+	text, err := r.Recognize(ctx, png)
+	require.NoError(t, err)
+	assert.Equal(t, "key: example\n", text)
+
+	_, err = r.Recognize(ctx, []byte("GIF89a"))
+	assert.ErrorContains(t, err, "not an image")
+
+	_, err = New(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestIsImageFile(t *testing.T) {
+	for _, file := range []string{"screenshot.png", "docs/Console.PNG", "photo.jpg", "photo.jpeg"} {
+		assert.True(t, IsImageFile(file), file)
+	}
+	for _, file := range []string{"", "logo.svg", "anim.gif", "png"} {
+		assert.False(t, IsImageFile(file), file)
+	}
+}
@@ -0,0 +1,431 @@
+// Package operator runs a Kubernetes controller for SecretScan resources.
+// Every scan of a SecretScan's source runs as a Job executing "trufflehog
+// scan", with the source's connection injected through its environment. When
+// the Job finishes, the findings in its output are summarized in the
+// SecretScan's status and sent to a webhook.
+package operator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	containerName = "trufflehog"
+	connectionEnv = "TRUFFLEHOG_CONNECTION"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "trufflehog-operator"
+	scanLabel      = Group + "/secretscan"
+
+	// jobTTL is how long finished jobs are kept, giving the controller time
+	// to summarize them.
+	jobTTL = 24 * time.Hour
+	// maxLabelValue is the length limit of label values, which job names are
+	// bound by too since pods are labelled with them.
+	maxLabelValue = 63
+)
+
+// Config configures a Controller.
+type Config struct {
+	// Namespace to watch SecretScans in. Empty watches all namespaces.
+	Namespace string
+	// Image of scan jobs, unless a SecretScan sets its own.
+	Image string
+	// WebhookURL receives the summary of every finished scan, unless a
+	// SecretScan sets its own.
+	WebhookURL string
+	// ResyncInterval is how often every SecretScan is reconciled, which
+	// starts scans that are due and summarizes finished ones.
+	ResyncInterval time.Duration
+}
+
+// Controller reconciles SecretScans with the jobs scanning their sources.
+type Controller struct {
+	cfg     Config
+	kube    kubernetes.Interface
+	dynamic dynamic.Interface
+	logs    func(ctx context.Context, namespace, pod string) (io.ReadCloser, error)
+	sink    *http.Client
+	now     func() time.Time
+}
+
+// New creates a Controller for the cluster it runs in.
+func New(cfg Config) (*Controller, error) {
+	kube, dyn, err := inClusterClients()
+	if err != nil {
+		return nil, err
+	}
+	return newController(kube, dyn, cfg), nil
+}
+
+func newController(kube kubernetes.Interface, dyn dynamic.Interface, cfg Config) *Controller {
+	if cfg.ResyncInterval < time.Second {
+		cfg.ResyncInterval = time.Second
+	}
+	return &Controller{
+		cfg:     cfg,
+		kube:    kube,
+		dynamic: dyn,
+		logs:    podLogs(kube),
+		sink:    common.RetryableHTTPClient(common.WithTimeout(30 * time.Second)),
+		now:     time.Now,
+	}
+}
+
+// Run reconciles SecretScans whenever they or their jobs change, and all of
+// them every resync interval, until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[string]())
+	defer queue.ShutDown()
+
+	// The resyncs of the informer reconcile every SecretScan, which starts
+	// the scans that are due.
+	scanInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamic, c.cfg.ResyncInterval, c.cfg.Namespace, nil)
+	scanInformer := scanInformers.ForResource(secretScans).Informer()
+	_, _ = scanInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { enqueue(queue, obj) },
+		UpdateFunc: func(_, obj any) { enqueue(queue, obj) },
+	})
+	jobInformers := informers.NewSharedInformerFactoryWithOptions(c.kube, 0,
+		informers.WithNamespace(c.cfg.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) { opts.LabelSelector = managedByLabel + "=" + managedBy }),
+	)
+	jobInformer := jobInformers.Batch().V1().Jobs().Informer()
+	_, _ = jobInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj any) { enqueueOwner(queue, obj) },
+		DeleteFunc: func(obj any) { enqueueOwner(queue, obj) },
+	})
+
+	scanInformers.Start(ctx.Done())
+	jobInformers.Start(ctx.Done())
+	defer scanInformers.Shutdown()
+	defer jobInformers.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), scanInformer.HasSynced, jobInformer.HasSynced) {
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+	for {
+		key, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		if c.reconcileKey(ctx, scanInformer.GetIndexer(), key) {
+			queue.Forget(key)
+		} else {
+			queue.AddRateLimited(key)
+		}
+		queue.Done(key)
+	}
+}
+
+func enqueue(queue workqueue.TypedInterface[string], obj any) {
+	if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+		queue.Add(key)
+	}
+}
+
+// enqueueOwner enqueues the SecretScan of a scan job.
+func enqueueOwner(queue workqueue.TypedInterface[string], obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	j, ok := obj.(*batchv1.Job)
+	if !ok {
+		return
+	}
+	if owner := metav1.GetControllerOf(j); owner != nil && owner.Kind == "SecretScan" {
+		queue.Add(j.Namespace + "/" + owner.Name)
+	}
+}
+
+// reconcileKey reconciles the cached SecretScan with the given key, and
+// reports whether it's done with it, rather than having to retry.
+func (c *Controller) reconcileKey(ctx context.Context, indexer cache.Indexer, key string) bool {
+	obj, exists, err := indexer.GetByKey(key)
+	if err != nil || !exists {
+		// Deleted SecretScans are cleaned up with their jobs, which
+		// they own.
+		return true
+	}
+	scan, err := toSecretScan(obj)
+	if err != nil {
+		ctx.Logger().Error(err, "invalid SecretScan", "secretscan", key)
+		return true
+	}
+	ctx = context.WithValues(ctx, "secretscan", key)
+	err = c.reconcile(ctx, scan)
+	switch {
+	case err == nil:
+	case apierrors.IsConflict(err):
+		// The SecretScan changed since it was cached; the change triggers
+		// another reconcile.
+		ctx.Logger().V(2).Info("SecretScan changed while reconciling", "error", err)
+	default:
+		ctx.Logger().Error(err, "error reconciling SecretScan")
+		return false
+	}
+	return true
+}
+
+// reconcile summarizes the active job of a SecretScan once it finishes, or
+// starts one if a scan is due.
+func (c *Controller) reconcile(ctx context.Context, scan *SecretScan) error {
+	if scan.Status.ActiveJob != "" {
+		return c.checkJob(ctx, scan)
+	}
+
+	scheduled, due, err := dueTime(scan, c.now())
+	if err != nil {
+		message := "invalid schedule: " + err.Error()
+		if scan.Status.Phase == PhaseFailed && scan.Status.Message == message {
+			return nil
+		}
+		scan.Status.Phase, scan.Status.Message = PhaseFailed, message
+		return c.updateStatus(ctx, scan)
+	}
+	if !due {
+		if scan.Status.Phase != "" {
+			return nil
+		}
+		scan.Status.Phase = PhasePending
+		return c.updateStatus(ctx, scan)
+	}
+	return c.startJob(ctx, scan, scheduled)
+}
+
+// dueTime returns the time of the scan of a SecretScan that is due, if any.
+// Without a schedule, a SecretScan is scanned once after it's created.
+func dueTime(scan *SecretScan, now time.Time) (time.Time, bool, error) {
+	if scan.Spec.Suspend {
+		return time.Time{}, false, nil
+	}
+	last := now
+	if !scan.CreationTimestamp.IsZero() {
+		last = scan.CreationTimestamp.Time
+	}
+	if scan.Spec.Schedule == "" {
+		return last, scan.Status.LastScheduleTime == nil, nil
+	}
+
+	schedule, err := cron.ParseStandard(scan.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if scan.Status.LastScheduleTime != nil {
+		last = scan.Status.LastScheduleTime.Time
+	}
+	// Scans missed while the controller wasn't running are coalesced into
+	// the latest one.
+	var scheduled time.Time
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		scheduled = next
+	}
+	return scheduled, !scheduled.IsZero(), nil
+}
+
+// startJob creates the job of the scan scheduled at the given time. Its name
+// is derived from that time, so a job already created for it is reused.
+func (c *Controller) startJob(ctx context.Context, scan *SecretScan, scheduled time.Time) error {
+	j := c.newJob(scan, scheduled)
+	_, err := c.kube.BatchV1().Jobs(scan.Namespace).Create(ctx, j, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		scan.Status.Phase, scan.Status.Message = PhaseFailed, "could not create scan job: "+err.Error()
+		return errors.Join(err, c.updateStatus(ctx, scan))
+	}
+	ctx.Logger().Info("started scan job", "job", j.Name)
+
+	scan.Status.Phase = PhaseRunning
+	scan.Status.ActiveJob = j.Name
+	scan.Status.LastScheduleTime = &metav1.Time{Time: scheduled}
+	scan.Status.Message = ""
+	return c.updateStatus(ctx, scan)
+}
+
+func (c *Controller) newJob(scan *SecretScan, scheduled time.Time) *batchv1.Job {
+	image := scan.Spec.Image
+	if image == "" {
+		image = c.cfg.Image
+	}
+	connection := corev1.EnvVar{Name: connectionEnv, Value: string(scan.Spec.Connection)}
+	if ref := scan.Spec.ConnectionSecretRef; ref != nil {
+		connection = corev1.EnvVar{Name: connectionEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+			Key:                  ref.Key,
+		}}}
+	}
+	args := append([]string{"scan", "--source-type=" + scan.Spec.SourceType, "--json", "--no-update"}, scan.Spec.Args...)
+	labels := map[string]string{managedByLabel: managedBy, scanLabel: truncateName(scan.Name, maxLabelValue)}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName(scan.Name, scheduled),
+			Namespace: scan.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         Group + "/" + Version,
+				Kind:               "SecretScan",
+				Name:               scan.Name,
+				UID:                scan.UID,
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}},
+		},
+		Spec: batchv1.JobSpec{
+			// A retried scan would print its findings twice.
+			BackoffLimit:            ptr.To[int32](0),
+			TTLSecondsAfterFinished: ptr.To(int32(jobTTL.Seconds())),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: scan.Spec.ServiceAccountName,
+					Containers: []corev1.Container{{
+						Name:  containerName,
+						Image: image,
+						// Bypass the image's entrypoint, which splits its
+						// last argument.
+						Command: []string{"/usr/bin/trufflehog"},
+						Args:    args,
+						Env:     []corev1.EnvVar{connection},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func jobName(scanName string, scheduled time.Time) string {
+	suffix := fmt.Sprintf("-%d", scheduled.Unix())
+	return truncateName(scanName, maxLabelValue-len(suffix)) + suffix
+}
+
+func truncateName(name string, n int) string {
+	if len(name) <= n {
+		return name
+	}
+	return strings.TrimRight(name[:n], "-.")
+}
+
+// jobFinished reports whether the job is complete or failed, and whether it
+// succeeded.
+func jobFinished(j *batchv1.Job) (done, succeeded bool, message string) {
+	for _, cond := range j.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return true, true, cond.Message
+		case batchv1.JobFailed:
+			return true, false, cond.Message
+		}
+	}
+	return false, false, ""
+}
+
+// checkJob records the result of the active job of a SecretScan once it has
+// finished, and sends its summary to the webhook.
+func (c *Controller) checkJob(ctx context.Context, scan *SecretScan) error {
+	name := scan.Status.ActiveJob
+	// The job is read from the API rather than the informer's cache, which
+	// may not have seen a job that was just created yet.
+	j, err := c.kube.BatchV1().Jobs(scan.Namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		scan.Status.Phase = PhaseFailed
+		scan.Status.Message = fmt.Sprintf("scan job %s was deleted before it finished", name)
+	case err != nil:
+		return fmt.Errorf("could not get scan job %s: %w", name, err)
+	default:
+		done, succeeded, message := jobFinished(j)
+		if !done {
+			return nil
+		}
+		summary, err := c.summarize(ctx, j)
+		if err != nil {
+			return fmt.Errorf("could not summarize scan job %s: %w", name, err)
+		}
+		scan.Status.Summary = summary
+		scan.Status.Phase, scan.Status.Message = PhaseSucceeded, ""
+		if !succeeded {
+			scan.Status.Phase = PhaseFailed
+			scan.Status.Message = fmt.Sprintf("scan job %s failed: %s", name, message)
+		}
+	}
+
+	now := metav1.NewTime(c.now())
+	scan.Status.ActiveJob = ""
+	scan.Status.LastJob = name
+	scan.Status.LastCompletionTime = &now
+	if err := c.updateStatus(ctx, scan); err != nil {
+		return err
+	}
+	ctx.Logger().Info("scan job finished", "job", name, "phase", scan.Status.Phase)
+	c.notify(ctx, scan)
+	return nil
+}
+
+// summarize counts the findings in the output of a job's pods.
+func (c *Controller) summarize(ctx context.Context, j *batchv1.Job) (*Summary, error) {
+	pods, err := c.kube.CoreV1().Pods(j.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + j.Name})
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{Detectors: make(map[string]int)}
+	for _, p := range pods.Items {
+		logs, err := c.logs(ctx, j.Namespace, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		err = summary.add(logs)
+		logs.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// updateStatus replaces the status of a SecretScan, and updates it to the
+// stored version.
+func (c *Controller) updateStatus(ctx context.Context, scan *SecretScan) error {
+	obj, err := fromSecretScan(scan)
+	if err != nil {
+		return err
+	}
+	stored, err := c.dynamic.Resource(secretScans).Namespace(scan.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update status: %w", err)
+	}
+	updated, err := toSecretScan(stored)
+	if err != nil {
+		return err
+	}
+	*scan = *updated
+	return nil
+}
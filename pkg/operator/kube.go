@@ -0,0 +1,66 @@
+package operator
+
+import (
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// inClusterClients returns the clients of the built-in resources and of
+// SecretScans, authenticating with the service account of the pod they run
+// in.
+func inClusterClients() (kubernetes.Interface, dynamic.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("not running in a Kubernetes cluster: %w", err)
+	}
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kube, dyn, nil
+}
+
+// podLogs streams the logs of a pod's container. The caller must close them.
+func podLogs(kube kubernetes.Interface) func(ctx context.Context, namespace, pod string) (io.ReadCloser, error) {
+	return func(ctx context.Context, namespace, pod string) (io.ReadCloser, error) {
+		return kube.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: containerName}).Stream(ctx)
+	}
+}
+
+// SecretScans have no generated client, so they're read and written as
+// unstructured objects.
+
+func toSecretScan(obj any) (*SecretScan, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected SecretScan object %T", obj)
+	}
+	scan := new(SecretScan)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), scan); err != nil {
+		return nil, fmt.Errorf("invalid SecretScan: %w", err)
+	}
+	return scan, nil
+}
+
+func fromSecretScan(scan *SecretScan) (*unstructured.Unstructured, error) {
+	scan.TypeMeta = metav1.TypeMeta{APIVersion: Group + "/" + Version, Kind: "SecretScan"}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(scan)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
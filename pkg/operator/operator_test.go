@@ -0,0 +1,256 @@
+package operator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// newFakeController returns a controller of fake clusters holding scans,
+// whose pods all log the output in logs.
+func newFakeController(t *testing.T, cfg Config, logs *string, scans ...*SecretScan) (*Controller, *kubefake.Clientset, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	objs := make([]runtime.Object, 0, len(scans))
+	for _, scan := range scans {
+		obj, err := fromSecretScan(scan)
+		require.NoError(t, err)
+		objs = append(objs, obj)
+	}
+	kube := kubefake.NewClientset()
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{secretScans: "SecretScanList"}, objs...)
+	c := newController(kube, dyn, cfg)
+	c.logs = func(context.Context, string, string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(*logs)), nil
+	}
+	return c, kube, dyn
+}
+
+func getScan(t *testing.T, dyn *dynamicfake.FakeDynamicClient, name string) *SecretScan {
+	t.Helper()
+	obj, err := dyn.Resource(secretScans).Namespace("default").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	scan, err := toSecretScan(obj)
+	require.NoError(t, err)
+	return scan
+}
+
+func getJob(t *testing.T, kube *kubefake.Clientset, name string) *batchv1.Job {
+	t.Helper()
+	j, err := kube.BatchV1().Jobs("default").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return j
+}
+
+func completeJob(t *testing.T, kube *kubefake.Clientset, j *batchv1.Job) {
+	t.Helper()
+	j.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	_, err := kube.BatchV1().Jobs("default").UpdateStatus(context.Background(), j, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func countStatusUpdates(dyn *dynamicfake.FakeDynamicClient) int {
+	n := 0
+	for _, action := range dyn.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestController_Reconcile(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu            sync.Mutex
+		notifications []Notification
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		mu.Lock()
+		notifications = append(notifications, n)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var logs string
+	ctrl, kube, dyn := newFakeController(t, Config{Image: "trufflehog:test", WebhookURL: webhook.URL}, &logs, &SecretScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default", UID: "uid", CreationTimestamp: metav1.NewTime(created)},
+		Spec: SecretScanSpec{
+			SourceType: "git",
+			Connection: json.RawMessage(`{"uri":"https://example.com/repo.git"}`),
+			Args:       []string{"--results=verified"},
+		},
+	})
+
+	// The scan starts as soon as it's created.
+	require.NoError(t, ctrl.reconcile(ctx, getScan(t, dyn, "repo")))
+	scan := getScan(t, dyn, "repo")
+	assert.Equal(t, PhaseRunning, scan.Status.Phase)
+	jobName := "repo-" + strconv.FormatInt(created.Unix(), 10)
+	require.Equal(t, jobName, scan.Status.ActiveJob)
+
+	j := getJob(t, kube, jobName)
+	require.Len(t, j.Spec.Template.Spec.Containers, 1)
+	scanContainer := j.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "trufflehog:test", scanContainer.Image)
+	assert.Equal(t, []string{"scan", "--source-type=git", "--json", "--no-update", "--results=verified"}, scanContainer.Args)
+	assert.Equal(t, []corev1.EnvVar{{Name: connectionEnv, Value: `{"uri":"https://example.com/repo.git"}`}}, scanContainer.Env)
+	assert.Equal(t, "uid", string(j.OwnerReferences[0].UID))
+	assert.Equal(t, corev1.RestartPolicyNever, j.Spec.Template.Spec.RestartPolicy)
+
+	// Nothing changes while the job runs.
+	updates := countStatusUpdates(dyn)
+	require.NoError(t, ctrl.reconcile(ctx, scan))
+	assert.Equal(t, updates, countStatusUpdates(dyn))
+
+	_, err := kube.CoreV1().Pods("default").Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "scan-pod", Labels: map[string]string{"job-name": jobName}}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	completeJob(t, kube, j)
+	logs = `{"level":"info","msg":"running source"}
+{"DetectorName":"AWS","Verified":true,"Raw":"AKIA"}
+not json
+{"DetectorName":"Github","Verified":false,"Raw":"ghp_"}
+{"DetectorName":"AWS","Verified":false,"Raw":"AKIB"}
+`
+
+	require.NoError(t, ctrl.reconcile(ctx, scan))
+	scan = getScan(t, dyn, "repo")
+	assert.Equal(t, PhaseSucceeded, scan.Status.Phase)
+	assert.Empty(t, scan.Status.ActiveJob)
+	assert.Equal(t, jobName, scan.Status.LastJob)
+	want := &Summary{Findings: 3, Verified: 1, Unverified: 2, Detectors: map[string]int{"AWS": 2, "Github": 1}}
+	assert.Equal(t, want, scan.Status.Summary)
+
+	mu.Lock()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "default/repo", notifications[0].SecretScan)
+	assert.Equal(t, jobName, notifications[0].Job)
+	assert.Equal(t, want, notifications[0].Summary)
+	mu.Unlock()
+
+	// Without a schedule, the source is only scanned once.
+	require.NoError(t, ctrl.reconcile(ctx, scan))
+	jobs, err := kube.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 1)
+}
+
+func TestController_ReconcileDeletedJob(t *testing.T) {
+	ctx := context.Background()
+	var logs string
+	ctrl, _, dyn := newFakeController(t, Config{}, &logs, &SecretScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default"},
+		Status:     SecretScanStatus{Phase: PhaseRunning, ActiveJob: "repo-1"},
+	})
+	require.NoError(t, ctrl.reconcile(ctx, getScan(t, dyn, "repo")))
+	scan := getScan(t, dyn, "repo")
+	assert.Equal(t, PhaseFailed, scan.Status.Phase)
+	assert.Contains(t, scan.Status.Message, "deleted")
+	assert.Empty(t, scan.Status.ActiveJob)
+}
+
+func TestController_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var logs string
+	// Only the informers, rather than resyncs, can trigger the reconciles
+	// within the test.
+	ctrl, kube, dyn := newFakeController(t, Config{ResyncInterval: time.Hour}, &logs, &SecretScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now())},
+		Spec:       SecretScanSpec{SourceType: "git"},
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctrl.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var activeJob string
+	require.Eventually(t, func() bool {
+		activeJob = getScan(t, dyn, "repo").Status.ActiveJob
+		return activeJob != ""
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The change of the job triggers the reconcile that summarizes it.
+	completeJob(t, kube, getJob(t, kube, activeJob))
+	assert.Eventually(t, func() bool {
+		return getScan(t, dyn, "repo").Status.Phase == PhaseSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDueTime(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	at := func(hour, minute int) *metav1.Time {
+		return &metav1.Time{Time: time.Date(2024, 5, 1, hour, minute, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name     string
+		spec     SecretScanSpec
+		last     *metav1.Time
+		now      *metav1.Time
+		want     *metav1.Time
+		wantFail bool
+	}{
+		{name: "once", now: at(10, 31), want: at(10, 30)},
+		{name: "once already run", last: at(10, 30), now: at(12, 0)},
+		{name: "suspended", spec: SecretScanSpec{Suspend: true}, now: at(10, 31)},
+		{name: "not yet scheduled", spec: SecretScanSpec{Schedule: "0 * * * *"}, now: at(10, 59)},
+		{name: "first schedule", spec: SecretScanSpec{Schedule: "0 * * * *"}, now: at(11, 0), want: at(11, 0)},
+		{name: "missed schedules", spec: SecretScanSpec{Schedule: "0 * * * *"}, last: at(11, 0), now: at(13, 30), want: at(13, 0)},
+		{name: "invalid schedule", spec: SecretScanSpec{Schedule: "nope"}, now: at(11, 0), wantFail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := &SecretScan{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec:       tt.spec,
+				Status:     SecretScanStatus{LastScheduleTime: tt.last},
+			}
+			got, due, err := dueTime(scan, tt.now.Time)
+			if tt.wantFail {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want != nil, due)
+			if tt.want != nil {
+				assert.Equal(t, tt.want.Time, got)
+			}
+		})
+	}
+}
+
+func TestJobName(t *testing.T) {
+	scheduled := time.Unix(1714557600, 0)
+	assert.Equal(t, "repo-1714557600", jobName("repo", scheduled))
+
+	// Names are truncated to fit labels, without ending in a separator.
+	long := jobName("a-very-long-secretscan-name-that-goes-on-and-on-and-on-and-on", scheduled)
+	assert.Equal(t, "a-very-long-secretscan-name-that-goes-on-and-on-and-1714557600", long)
+	assert.LessOrEqual(t, len(long), maxLabelValue)
+}
@@ -0,0 +1,103 @@
+package operator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// maxOutputLine bounds the size of a line of scan output, which holds a
+// finding and its metadata.
+const maxOutputLine = 16 << 20
+
+// add counts the findings in JSON scan output. Other lines, such as logs, are
+// skipped.
+func (s *Summary) add(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxOutputLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var finding struct {
+			DetectorName string
+			Verified     bool
+		}
+		if json.Unmarshal(line, &finding) != nil || finding.DetectorName == "" {
+			continue
+		}
+		s.Findings++
+		if finding.Verified {
+			s.Verified++
+		} else {
+			s.Unverified++
+		}
+		s.Detectors[finding.DetectorName]++
+	}
+	return scanner.Err()
+}
+
+// Notification is the payload sent to the webhook when a scan finishes.
+type Notification struct {
+	// SecretScan is the namespace and name of the SecretScan.
+	SecretScan     string    `json:"secretScan"`
+	Job            string    `json:"job"`
+	Phase          string    `json:"phase"`
+	Message        string    `json:"message,omitempty"`
+	CompletionTime time.Time `json:"completionTime"`
+	Summary        *Summary  `json:"summary,omitempty"`
+}
+
+// notify sends the result of a SecretScan's last job to its webhook. Failures
+// are only logged, since the result is also kept in the SecretScan's status.
+func (c *Controller) notify(ctx context.Context, scan *SecretScan) {
+	webhookURL := scan.Spec.WebhookURL
+	if webhookURL == "" {
+		webhookURL = c.cfg.WebhookURL
+	}
+	if webhookURL == "" {
+		return
+	}
+	notification := Notification{
+		SecretScan: scan.Namespace + "/" + scan.Name,
+		Job:        scan.Status.LastJob,
+		Phase:      scan.Status.Phase,
+		Message:    scan.Status.Message,
+		Summary:    scan.Status.Summary,
+	}
+	if scan.Status.LastCompletionTime != nil {
+		notification.CompletionTime = scan.Status.LastCompletionTime.Time
+	}
+	if err := c.post(ctx, webhookURL, notification); err != nil {
+		ctx.Logger().Error(err, "could not send scan summary to webhook")
+	}
+}
+
+func (c *Controller) post(ctx context.Context, webhookURL string, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.sink.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
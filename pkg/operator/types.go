@@ -0,0 +1,90 @@
+package operator
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Group is the API group of the SecretScan resource.
+	Group = "trufflehog.trufflesecurity.com"
+	// Version is the API version of the SecretScan resource.
+	Version = "v1alpha1"
+
+	secretScanResource = "secretscans"
+)
+
+var secretScans = schema.GroupVersionResource{Group: Group, Version: Version, Resource: secretScanResource}
+
+// Phases of a SecretScan.
+const (
+	PhasePending   = "Pending"
+	PhaseRunning   = "Running"
+	PhaseSucceeded = "Succeeded"
+	PhaseFailed    = "Failed"
+)
+
+// SecretScan describes a source to scan, once or on a schedule.
+type SecretScan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretScanSpec   `json:"spec"`
+	Status SecretScanStatus `json:"status,omitempty"`
+}
+
+// SecretScanSpec is the desired state of a SecretScan.
+type SecretScanSpec struct {
+	// SourceType is the type of the source, such as "git" or "s3".
+	SourceType string `json:"sourceType"`
+	// Connection is the connection of the source, as the JSON form of its
+	// message in sources.proto.
+	Connection json.RawMessage `json:"connection,omitempty"`
+	// ConnectionSecretRef selects a secret key holding the connection
+	// instead, for connections with credentials.
+	ConnectionSecretRef *SecretKeySelector `json:"connectionSecretRef,omitempty"`
+	// Schedule is a cron expression the source is scanned on. Without one,
+	// the source is scanned once.
+	Schedule string `json:"schedule,omitempty"`
+	// Suspend stops new scans from starting.
+	Suspend bool `json:"suspend,omitempty"`
+	// Image overrides the operator's scan image.
+	Image string `json:"image,omitempty"`
+	// Args are extra arguments of the scan, such as "--results=verified".
+	Args []string `json:"args,omitempty"`
+	// ServiceAccountName is the service account scan pods run as.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// WebhookURL overrides the operator's webhook summaries are sent to.
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+// SecretKeySelector selects a key of a secret in the SecretScan's namespace.
+type SecretKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// SecretScanStatus is the observed state of a SecretScan.
+type SecretScanStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// ActiveJob is the name of the running scan job, if any.
+	ActiveJob string `json:"activeJob,omitempty"`
+	// LastJob is the name of the last finished scan job.
+	LastJob            string       `json:"lastJob,omitempty"`
+	LastScheduleTime   *metav1.Time `json:"lastScheduleTime,omitempty"`
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+	// Summary summarizes the findings of the last finished scan.
+	Summary *Summary `json:"summary,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Summary counts the findings of a scan.
+type Summary struct {
+	Findings   int `json:"findings"`
+	Verified   int `json:"verified"`
+	Unverified int `json:"unverified"`
+	// Detectors counts the findings of every detector.
+	Detectors map[string]int `json:"detectors,omitempty"`
+}
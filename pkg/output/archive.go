@@ -0,0 +1,228 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/google/uuid"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	archiveFindingsObject = "findings.jsonl"
+	archiveManifestObject = "manifest.json"
+	archiveKeyTimeFormat  = "20060102T150405Z"
+)
+
+// objectStore is the subset of an object storage service used to archive
+// scan results.
+type objectStore interface {
+	Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+}
+
+// ArchivePrinter is a printer that archives the complete output of a run to
+// an object store for retention. Results are spooled to a local file as JSON
+// lines and uploaded once the scan has finished, together with a manifest
+// summarizing the run, under "<prefix>/<timestamp>-<run id>/".
+type ArchivePrinter struct {
+	store     objectStore
+	prefix    string
+	runID     string
+	startedAt time.Time
+
+	mu         sync.Mutex
+	spool      *os.File
+	enc        *json.Encoder
+	digest     hash.Hash
+	findings   int
+	verified   int
+	byDetector map[string]int
+}
+
+// NewArchivePrinter creates an ArchivePrinter that uploads to dest, an
+// s3://bucket/prefix or gs://bucket/prefix URL. If kmsKey is set, objects are
+// encrypted with it: an SSE-KMS key ID or ARN for S3, or a Cloud KMS key name
+// for GCS.
+func NewArchivePrinter(ctx context.Context, dest, kmsKey string) (*ArchivePrinter, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid archive destination %q: expected s3://bucket/prefix or gs://bucket/prefix", dest)
+	}
+
+	var store objectStore
+	switch u.Scheme {
+	case "s3":
+		store, err = newS3Store(ctx, u.Host, kmsKey)
+	case "gs":
+		store, err = newGCSStore(ctx, u.Host, kmsKey)
+	default:
+		return nil, fmt.Errorf("unsupported archive destination scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newArchivePrinter(store, strings.Trim(u.Path, "/"))
+}
+
+func newArchivePrinter(store objectStore, prefix string) (*ArchivePrinter, error) {
+	spool, err := os.CreateTemp("", "trufflehog-archive-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("could not create archive spool file: %w", err)
+	}
+	digest := sha256.New()
+	return &ArchivePrinter{
+		store:      store,
+		prefix:     prefix,
+		runID:      uuid.NewString(),
+		startedAt:  time.Now().UTC(),
+		spool:      spool,
+		enc:        json.NewEncoder(io.MultiWriter(spool, digest)),
+		digest:     digest,
+		byDetector: make(map[string]int),
+	}, nil
+}
+
+func (p *ArchivePrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enc.Encode(newJSONResult(r)); err != nil {
+		return fmt.Errorf("could not spool result: %w", err)
+	}
+	p.findings++
+	if r.Verified {
+		p.verified++
+	}
+	p.byDetector[r.DetectorType.String()]++
+	return nil
+}
+
+// archiveManifest summarizes an archived run.
+type archiveManifest struct {
+	RunID            string         `json:"run_id"`
+	Version          string         `json:"version"`
+	StartedAt        time.Time      `json:"started_at"`
+	FinishedAt       time.Time      `json:"finished_at"`
+	Findings         int            `json:"findings"`
+	VerifiedFindings int            `json:"verified_findings"`
+	ByDetector       map[string]int `json:"by_detector"`
+	FindingsObject   string         `json:"findings_object"`
+	FindingsSHA256   string         `json:"findings_sha256"`
+}
+
+// Flush uploads the spooled results and the run manifest.
+func (p *ArchivePrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer os.Remove(p.spool.Name())
+	defer p.spool.Close()
+
+	finishedAt := time.Now().UTC()
+	dir := path.Join(p.prefix, fmt.Sprintf("%s-%s", p.startedAt.Format(archiveKeyTimeFormat), p.runID))
+	findingsKey := path.Join(dir, archiveFindingsObject)
+
+	if _, err := p.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("could not read archive spool file: %w", err)
+	}
+	if err := p.store.Put(ctx, findingsKey, p.spool, "application/x-ndjson"); err != nil {
+		return fmt.Errorf("could not archive findings: %w", err)
+	}
+
+	manifest, err := json.MarshalIndent(archiveManifest{
+		RunID:            p.runID,
+		Version:          version.BuildVersion,
+		StartedAt:        p.startedAt,
+		FinishedAt:       finishedAt,
+		Findings:         p.findings,
+		VerifiedFindings: p.verified,
+		ByDetector:       p.byDetector,
+		FindingsObject:   findingsKey,
+		FindingsSHA256:   hex.EncodeToString(p.digest.Sum(nil)),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal archive manifest: %w", err)
+	}
+	if err := p.store.Put(ctx, path.Join(dir, archiveManifestObject), strings.NewReader(string(manifest)), "application/json"); err != nil {
+		return fmt.Errorf("could not archive manifest: %w", err)
+	}
+	return nil
+}
+
+type s3Store struct {
+	uploader *s3manager.Uploader
+	bucket   string
+	kmsKey   string
+}
+
+func newS3Store(ctx context.Context, bucket, kmsKey string) (*s3Store, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", err)
+	}
+	region, err := s3manager.GetBucketRegion(ctx, sess, bucket, "us-east-1")
+	if err != nil {
+		return nil, fmt.Errorf("could not get region of bucket %s: %w", bucket, err)
+	}
+	return &s3Store{
+		uploader: s3manager.NewUploader(sess.Copy(&aws.Config{Region: aws.String(region)})),
+		bucket:   bucket,
+		kmsKey:   kmsKey,
+	}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if s.kmsKey != "" {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSKeyId = aws.String(s.kmsKey)
+	}
+	_, err := s.uploader.UploadWithContext(ctx, input)
+	return err
+}
+
+type gcsStore struct {
+	bucket *storage.BucketHandle
+	kmsKey string
+}
+
+func newGCSStore(ctx context.Context, bucket, kmsKey string) (*gcsStore, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not create GCS client: %w", err)
+	}
+	return &gcsStore{bucket: client.Bucket(bucket), kmsKey: kmsKey}, nil
+}
+
+func (s *gcsStore) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	w.KMSKeyName = s.kmsKey
+	if _, err := io.Copy(w, body); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+type memoryStore map[string]string
+
+func (s memoryStore) Put(_ context.Context, key string, body io.ReadSeeker, _ string) error {
+	data, err := io.ReadAll(body)
+	s[key] = string(data)
+	return err
+}
+
+func TestArchivePrinter(t *testing.T) {
+	ctx := context.Background()
+	store := make(memoryStore)
+	p, err := newArchivePrinter(store, "scans/nightly")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "other.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Flush(ctx))
+	require.Len(t, store, 2)
+
+	var manifestKey string
+	for key := range store {
+		assert.True(t, strings.HasPrefix(key, "scans/nightly/"), key)
+		assert.Contains(t, key, p.runID)
+		if strings.HasSuffix(key, archiveManifestObject) {
+			manifestKey = key
+		}
+	}
+	require.NotEmpty(t, manifestKey)
+
+	var manifest archiveManifest
+	require.NoError(t, json.Unmarshal([]byte(store[manifestKey]), &manifest))
+	assert.Equal(t, 2, manifest.Findings)
+	assert.Equal(t, 1, manifest.VerifiedFindings)
+	assert.Equal(t, map[string]int{"AWS": 2}, manifest.ByDetector)
+	assert.Len(t, manifest.FindingsSHA256, 64)
+
+	lines := strings.Split(strings.TrimSpace(store[manifest.FindingsObject]), "\n")
+	assert.Len(t, lines, 2)
+}
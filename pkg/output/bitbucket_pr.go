@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const bitbucketCloudEndpoint = "https://api.bitbucket.org/2.0"
+
+// BitbucketPRPrinter is a printer that reports results on a Bitbucket Cloud
+// pull request. Once the scan has finished, it posts an inline comment on
+// every changed line that introduces a secret and resolves the comments of
+// earlier runs whose secret is no longer present.
+type BitbucketPRPrinter struct {
+	client   *http.Client
+	endpoint string
+	auth     func(*http.Request)
+	pr       PullRequestRef
+	prFindings
+}
+
+// NewBitbucketPRPrinter creates a BitbucketPRPrinter for pr. A token of the
+// form "username:app-password" is sent with basic authentication; any other
+// token is sent as a bearer token. The endpoint may be empty to use
+// Bitbucket Cloud.
+func NewBitbucketPRPrinter(pr PullRequestRef, token, endpoint string) *BitbucketPRPrinter {
+	if endpoint == "" {
+		endpoint = bitbucketCloudEndpoint
+	}
+	return &BitbucketPRPrinter{
+		client:   common.RetryableHTTPClient(),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		auth:     tokenAuth(token),
+		pr:       pr,
+	}
+}
+
+func (p *BitbucketPRPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush posts and resolves comments for every result printed so far.
+func (p *BitbucketPRPrinter) Flush(ctx context.Context) error {
+	diff, err := p.do(ctx, http.MethodGet, p.prURL("diff"), nil)
+	if err != nil {
+		return fmt.Errorf("could not get diff of pull request %s: %w", p.pr, err)
+	}
+	posted, err := p.postedComments(ctx)
+	if err != nil {
+		return err
+	}
+
+	inDiff, outside := partitionByDiff(p.take(), addedLinesByFile(string(diff)))
+	toPost, stale := reconcileComments(inDiff, posted)
+
+	var errs []error
+	for _, f := range toPost {
+		comment := bitbucketComment{Inline: &bitbucketInline{Path: f.Path, To: f.Line}}
+		comment.Content.Raw = f.commentBody()
+		if err := p.postComment(ctx, comment); err != nil {
+			errs = append(errs, fmt.Errorf("could not comment on %s:%d of pull request %s: %w", f.Path, f.Line, p.pr, err))
+		}
+	}
+	for _, c := range stale {
+		id, _ := strconv.Atoi(c.ID)
+		reply := bitbucketComment{Parent: &bitbucketParent{ID: id}}
+		reply.Content.Raw = resolvedCommentBody
+		if err := p.postComment(ctx, reply); err != nil {
+			errs = append(errs, fmt.Errorf("could not reply to comment %s on pull request %s: %w", c.ID, p.pr, err))
+			continue
+		}
+		if _, err := p.do(ctx, http.MethodPost, p.prURL("comments", c.ID, "resolve"), nil); err != nil {
+			errs = append(errs, fmt.Errorf("could not resolve comment %s on pull request %s: %w", c.ID, p.pr, err))
+		}
+	}
+	if len(toPost) > 0 || len(outside) > 0 {
+		var summary bitbucketComment
+		summary.Content.Raw = reviewSummary(inDiff, outside)
+		if err := p.postComment(ctx, summary); err != nil {
+			errs = append(errs, fmt.Errorf("could not comment on pull request %s: %w", p.pr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postedComments returns the unresolved comments posted by earlier runs.
+func (p *BitbucketPRPrinter) postedComments(ctx context.Context) ([]postedComment, error) {
+	var posted []postedComment
+	next := p.prURL("comments") + "?pagelen=100"
+	for next != "" {
+		body, err := p.do(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not list comments of pull request %s: %w", p.pr, err)
+		}
+		var page struct {
+			Values []bitbucketComment `json:"values"`
+			Next   string             `json:"next"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("could not decode comments of pull request %s: %w", p.pr, err)
+		}
+		for _, c := range page.Values {
+			if c.Deleted || c.Resolution != nil || c.Parent != nil {
+				continue
+			}
+			if marker, ok := parseCommentMarker(c.Content.Raw); ok {
+				posted = append(posted, postedComment{ID: strconv.Itoa(c.ID), Marker: marker})
+			}
+		}
+		next = page.Next
+	}
+	return posted, nil
+}
+
+func (p *BitbucketPRPrinter) postComment(ctx context.Context, comment bitbucketComment) error {
+	body, err := json.Marshal(comment)
+	if err != nil {
+		return err
+	}
+	_, err = p.do(ctx, http.MethodPost, p.prURL("comments"), body)
+	return err
+}
+
+// prURL returns the API URL of the pull request, or of a resource under it.
+func (p *BitbucketPRPrinter) prURL(elem ...string) string {
+	parts := []string{p.endpoint, "repositories", p.pr.Owner(), p.pr.Name(), "pullrequests", strconv.Itoa(p.pr.Number)}
+	for _, e := range elem {
+		parts = append(parts, url.PathEscape(e))
+	}
+	return strings.Join(parts, "/")
+}
+
+func (p *BitbucketPRPrinter) do(ctx context.Context, method, target string, body []byte) ([]byte, error) {
+	return doRequest(ctx, p.client, p.auth, method, target, body)
+}
+
+type bitbucketComment struct {
+	ID      int `json:"id,omitempty"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline     *bitbucketInline `json:"inline,omitempty"`
+	Parent     *bitbucketParent `json:"parent,omitempty"`
+	Deleted    bool             `json:"deleted,omitempty"`
+	Resolution *struct{}        `json:"resolution,omitempty"`
+}
+
+type bitbucketInline struct {
+	Path string `json:"path"`
+	To   int    `json:"to"`
+}
+
+type bitbucketParent struct {
+	ID int `json:"id"`
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestBitbucketPRPrinter(t *testing.T) {
+	stale := prFinding{Path: "config.yaml", Line: 9, Fingerprint: "cc"}
+	var posted []bitbucketComment
+	var resolved []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repositories/ws/repo/pullrequests/3/diff", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "diff --git a/config.yaml b/config.yaml\n--- /dev/null\n+++ b/config.yaml\n@@ -0,0 +1 @@\n+key: AKIAEXAMPLE\n")
+	})
+	mux.HandleFunc("GET /repositories/ws/repo/pullrequests/3/comments", func(w http.ResponseWriter, _ *http.Request) {
+		old := bitbucketComment{ID: 41}
+		old.Content.Raw = stale.commentBody()
+		_ = json.NewEncoder(w).Encode(map[string]any{"values": []bitbucketComment{old}})
+	})
+	mux.HandleFunc("POST /repositories/ws/repo/pullrequests/3/comments", func(_ http.ResponseWriter, r *http.Request) {
+		var c bitbucketComment
+		_ = json.NewDecoder(r.Body).Decode(&c)
+		posted = append(posted, c)
+	})
+	mux.HandleFunc("POST /repositories/ws/repo/pullrequests/3/comments/{id}/resolve", func(_ http.ResponseWriter, r *http.Request) {
+		resolved = append(resolved, r.PathValue("id"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewBitbucketPRPrinter(PullRequestRef{Repo: "ws/repo", Number: 3}, "token", srv.URL)
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Flush(ctx))
+
+	require.Len(t, posted, 3, "inline comment, resolution reply and summary")
+	require.NotNil(t, posted[0].Inline)
+	assert.Equal(t, bitbucketInline{Path: "config.yaml", To: 1}, *posted[0].Inline)
+	require.NotNil(t, posted[1].Parent)
+	assert.Equal(t, 41, posted[1].Parent.ID)
+	assert.Equal(t, []string{"41"}, resolved)
+}
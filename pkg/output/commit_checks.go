@@ -0,0 +1,236 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// CommitRef identifies a commit of a repository on a code host.
+type CommitRef struct {
+	// Repo is the full path of the repository, e.g. "owner/repo" or
+	// "group/subgroup/project".
+	Repo string
+	SHA  string
+}
+
+func (r CommitRef) String() string {
+	return r.Repo + "@" + r.SHA
+}
+
+// GitHubCheckRunPrinter is a printer that reports results on a commit pushed
+// to GitHub. Once the scan has finished, it creates a check run on the commit
+// that fails when anything was found, with an annotation on the line of every
+// finding. Creating check runs requires a GitHub App installation token.
+type GitHubCheckRunPrinter struct {
+	client *github.Client
+	commit CommitRef
+	prFindings
+}
+
+// NewGitHubCheckRunPrinter creates a GitHubCheckRunPrinter for commit. The
+// endpoint is the GitHub API URL, and may be empty to use github.com.
+func NewGitHubCheckRunPrinter(commit CommitRef, token, endpoint string) (*GitHubCheckRunPrinter, error) {
+	client, err := newGitHubClient(common.RetryableHTTPClient(), token, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubCheckRunPrinter{client: client, commit: commit}, nil
+}
+
+func (p *GitHubCheckRunPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush creates the check run for every result printed so far.
+func (p *GitHubCheckRunPrinter) Flush(ctx context.Context) error {
+	ref := PullRequestRef{Repo: p.commit.Repo}
+	if err := createCheckRun(ctx, p.client, ref.Owner(), ref.Name(), p.commit.SHA, p.take()); err != nil {
+		return fmt.Errorf("could not create check run on %s: %w", p.commit, err)
+	}
+	return nil
+}
+
+// GitLabCommitStatusPrinter is a printer that reports results on a commit
+// pushed to GitLab. Once the scan has finished, it sets a commit status that
+// fails when anything was found, and comments the findings on the commit.
+type GitLabCommitStatusPrinter struct {
+	client *gitlab.Client
+	commit CommitRef
+	prFindings
+}
+
+// NewGitLabCommitStatusPrinter creates a GitLabCommitStatusPrinter for
+// commit. The endpoint is the GitLab URL, and may be empty to use gitlab.com.
+func NewGitLabCommitStatusPrinter(commit CommitRef, token, endpoint string) (*GitLabCommitStatusPrinter, error) {
+	var opts []gitlab.ClientOptionFunc
+	if endpoint != "" {
+		opts = append(opts, gitlab.WithBaseURL(endpoint))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create GitLab client: %w", err)
+	}
+	return &GitLabCommitStatusPrinter{client: client, commit: commit}, nil
+}
+
+func (p *GitLabCommitStatusPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush sets the commit status and comments every result printed so far.
+func (p *GitLabCommitStatusPrinter) Flush(ctx context.Context) error {
+	findings := p.take()
+	state, description := gitlab.Success, "No secrets found"
+	if len(findings) > 0 {
+		state, description = gitlab.Failed, fmt.Sprintf("%d potential secret(s) found", len(findings))
+		_, _, err := p.client.Commits.PostCommitComment(p.commit.Repo, p.commit.SHA, &gitlab.PostCommitCommentOptions{
+			Note: gitlab.Ptr(commitSummary(findings)),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("could not comment on commit %s: %w", p.commit, err)
+		}
+	}
+	_, _, err := p.client.Commits.SetCommitStatus(p.commit.Repo, p.commit.SHA, &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        gitlab.Ptr(githubCheckRunName),
+		Description: gitlab.Ptr(description),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not set status of commit %s: %w", p.commit, err)
+	}
+	return nil
+}
+
+// commitSummary renders the comment listing the findings of a push.
+func commitSummary(findings []prFinding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**TruffleHog found %d potential secret(s) in this push.**\n\n", len(findings))
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- `%s:%d`: %s %s secret `%s`\n", f.Path, f.Line, f.Status, f.Detector, f.Preview)
+	}
+	return b.String()
+}
+
+// BitbucketCommitStatusPrinter is a printer that reports results on a commit
+// pushed to Bitbucket Cloud. Once the scan has finished, it sets a build
+// status that fails when anything was found, and comments the findings on the
+// commit.
+type BitbucketCommitStatusPrinter struct {
+	client   *http.Client
+	endpoint string
+	auth     func(*http.Request)
+	commit   CommitRef
+	prFindings
+}
+
+// NewBitbucketCommitStatusPrinter creates a BitbucketCommitStatusPrinter for
+// commit. Tokens are sent as by NewBitbucketPRPrinter. The endpoint may be
+// empty to use Bitbucket Cloud.
+func NewBitbucketCommitStatusPrinter(commit CommitRef, token, endpoint string) *BitbucketCommitStatusPrinter {
+	if endpoint == "" {
+		endpoint = bitbucketCloudEndpoint
+	}
+	return &BitbucketCommitStatusPrinter{
+		client:   common.RetryableHTTPClient(),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		auth:     tokenAuth(token),
+		commit:   commit,
+	}
+}
+
+func (p *BitbucketCommitStatusPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush sets the build status and comments every result printed so far.
+func (p *BitbucketCommitStatusPrinter) Flush(ctx context.Context) error {
+	findings := p.take()
+	ref := PullRequestRef{Repo: p.commit.Repo}
+	commitURL := strings.Join([]string{p.endpoint, "repositories", ref.Owner(), ref.Name(), "commit", url.PathEscape(p.commit.SHA)}, "/")
+	status := map[string]string{
+		"key":         "trufflehog",
+		"name":        githubCheckRunName,
+		"state":       "SUCCESSFUL",
+		"description": "No secrets found",
+		// Build statuses must link somewhere; link the commit itself.
+		"url": "https://bitbucket.org/" + p.commit.Repo + "/commits/" + url.PathEscape(p.commit.SHA),
+	}
+	if len(findings) > 0 {
+		status["state"], status["description"] = "FAILED", fmt.Sprintf("%d potential secret(s) found", len(findings))
+		comment, err := json.Marshal(map[string]any{"content": map[string]string{"raw": commitSummary(findings)}})
+		if err != nil {
+			return err
+		}
+		if _, err := doRequest(ctx, p.client, p.auth, http.MethodPost, commitURL+"/comments", comment); err != nil {
+			return fmt.Errorf("could not comment on commit %s: %w", p.commit, err)
+		}
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, p.client, p.auth, http.MethodPost, commitURL+"/statuses/build", body); err != nil {
+		return fmt.Errorf("could not set status of commit %s: %w", p.commit, err)
+	}
+	return nil
+}
+
+// AzureDevOpsCommitStatusPrinter is a printer that reports results on a
+// commit pushed to Azure Repos. Once the scan has finished, it sets a commit
+// status that fails when anything was found, which pull requests of the
+// commit show too.
+type AzureDevOpsCommitStatusPrinter struct {
+	client   *http.Client
+	endpoint string
+	auth     func(*http.Request)
+	commit   CommitRef
+	prFindings
+}
+
+// NewAzureDevOpsCommitStatusPrinter creates an AzureDevOpsCommitStatusPrinter
+// for commit that authenticates with a personal access token. The endpoint is
+// the API URL of the repository, as in the service hook events of its pushes.
+func NewAzureDevOpsCommitStatusPrinter(commit CommitRef, token, endpoint string) *AzureDevOpsCommitStatusPrinter {
+	return &AzureDevOpsCommitStatusPrinter{
+		client:   common.RetryableHTTPClient(),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		auth:     tokenAuth(":" + token),
+		commit:   commit,
+	}
+}
+
+func (p *AzureDevOpsCommitStatusPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush sets the commit status for every result printed so far.
+func (p *AzureDevOpsCommitStatusPrinter) Flush(ctx context.Context) error {
+	findings := p.take()
+	state, description := "succeeded", "No secrets found"
+	if len(findings) > 0 {
+		state, description = "failed", fmt.Sprintf("%d potential secret(s) found", len(findings))
+	}
+	body, err := json.Marshal(map[string]any{
+		"state":       state,
+		"description": description,
+		"context":     map[string]string{"name": "trufflehog", "genre": "secret-scanning"},
+	})
+	if err != nil {
+		return err
+	}
+	target := p.endpoint + "/commits/" + url.PathEscape(p.commit.SHA) + "/statuses?api-version=7.1"
+	if _, err := doRequest(ctx, p.client, p.auth, http.MethodPost, target, body); err != nil {
+		return fmt.Errorf("could not set status of commit %s: %w", p.commit, err)
+	}
+	return nil
+}
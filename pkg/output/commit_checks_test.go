@@ -0,0 +1,115 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestGitHubCheckRunPrinter(t *testing.T) {
+	var checkRun github.CreateCheckRunOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/org/repo/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&checkRun)
+		_ = json.NewEncoder(w).Encode(github.CheckRun{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := newGitHubClient(srv.Client(), "token", srv.URL)
+	require.NoError(t, err)
+	p := &GitHubCheckRunPrinter{client: client, commit: CommitRef{Repo: "org/repo", SHA: "abc123"}}
+
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "abc123", checkRun.HeadSHA)
+	assert.Equal(t, "failure", checkRun.GetConclusion())
+	require.Len(t, checkRun.Output.Annotations, 1)
+	assert.Equal(t, "config.yaml", checkRun.Output.Annotations[0].GetPath())
+
+	// A push without findings passes.
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "success", checkRun.GetConclusion())
+}
+
+func TestGitLabCommitStatusPrinter(t *testing.T) {
+	var status, comment map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v4/projects/group%2Fproject/statuses/abc123", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&status)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /api/v4/projects/group%2Fproject/repository/commits/abc123/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p, err := NewGitLabCommitStatusPrinter(CommitRef{Repo: "group/project", SHA: "abc123"}, "token", srv.URL)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", false)))
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "failed", status["state"])
+	assert.Equal(t, "1 potential secret(s) found", status["description"])
+	assert.Contains(t, comment["note"], "config.yaml:1")
+	assert.NotContains(t, comment["note"], "AKIAEXAMPLESECRET")
+}
+
+func TestBitbucketCommitStatusPrinter(t *testing.T) {
+	var status, comment map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repositories/ws/repo/commit/abc123/statuses/build", func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&status)
+	})
+	mux.HandleFunc("POST /repositories/ws/repo/commit/abc123/comments", func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewBitbucketCommitStatusPrinter(CommitRef{Repo: "ws/repo", SHA: "abc123"}, "token", srv.URL)
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", false)))
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "FAILED", status["state"])
+	assert.Equal(t, "https://bitbucket.org/ws/repo/commits/abc123", status["url"])
+	require.NotNil(t, comment)
+	assert.Contains(t, comment["content"].(map[string]any)["raw"], "config.yaml:1")
+
+	comment = nil
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "SUCCESSFUL", status["state"])
+	assert.Nil(t, comment)
+}
+
+func TestAzureDevOpsCommitStatusPrinter(t *testing.T) {
+	var status map[string]any
+	var user, password string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /org/_apis/git/repositories/repo-id/commits/abc123/statuses", func(_ http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		_ = json.NewDecoder(r.Body).Decode(&status)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := NewAzureDevOpsCommitStatusPrinter(CommitRef{Repo: "project/repo", SHA: "abc123"}, "pat", srv.URL+"/org/_apis/git/repositories/repo-id")
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, "failed", status["state"])
+	assert.Equal(t, "1 potential secret(s) found", status["description"])
+	assert.Equal(t, "", user)
+	assert.Equal(t, "pat", password)
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+var csvHeader = []string{
+	"detector", "decoder", "verified", "verification_error", "raw", "redacted",
+	"source", "repository", "commit", "file", "line", "link", "email", "timestamp",
+	"fingerprint", "occurrences",
+}
+
+// CSVPrinter is a printer that prints results as CSV rows, one per result,
+// preceded by a header row.
+type CSVPrinter struct {
+	mu          sync.Mutex
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVPrinter creates a CSVPrinter that writes to out. If out is nil,
+// os.Stdout is used.
+func NewCSVPrinter(out io.Writer) *CSVPrinter {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &CSVPrinter{w: csv.NewWriter(out)}
+}
+
+func (p *CSVPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	var verificationErr string
+	if err := r.VerificationError(); err != nil {
+		verificationErr = err.Error()
+	}
+	var line string
+	if loc.Line > 0 {
+		line = strconv.FormatInt(loc.Line, 10)
+	}
+
+	record := []string{
+		r.DetectorType.String(),
+		r.DecoderType.String(),
+		strconv.FormatBool(r.Verified),
+		verificationErr,
+		string(r.Raw),
+		r.Redacted,
+		r.SourceName,
+		loc.Repository,
+		loc.Commit,
+		loc.File,
+		line,
+		loc.Link,
+		loc.Email,
+		loc.Timestamp,
+		r.Fingerprint,
+		strconv.Itoa(max(len(r.Occurrences), 1)),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.wroteHeader {
+		if err := p.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("could not write CSV header: %w", err)
+		}
+		p.wroteHeader = true
+	}
+	if err := p.w.Write(record); err != nil {
+		return fmt.Errorf("could not write CSV record: %w", err)
+	}
+	// Flush each row so results stream like the other line-based printers.
+	p.w.Flush()
+	return p.w.Error()
+}
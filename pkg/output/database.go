@@ -0,0 +1,293 @@
+package output
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/envelope"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+//go:embed database_schema.sql
+var databaseSchema string
+
+const (
+	insertRunQuery = `INSERT INTO scan_runs (id, started_at, version) VALUES ($1, $2, $3)`
+
+	// A finding keeps the commit it was introduced in unless it is found in
+	// an older one, such as when a later scan goes further back in history.
+	upsertFindingQuery = `INSERT INTO findings (
+	fingerprint, detector, decoder, verified, secret, source_name, repository, file, line, commit_hash, link,
+	first_seen_run, first_seen_at, last_seen_run, last_seen_at, introduced_commit, introduced_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $12, $13, $10, $14)
+ON CONFLICT (fingerprint) DO UPDATE SET
+	verified = excluded.verified,
+	secret = excluded.secret,
+	line = excluded.line,
+	commit_hash = excluded.commit_hash,
+	link = excluded.link,
+	last_seen_run = excluded.last_seen_run,
+	last_seen_at = excluded.last_seen_at,
+	introduced_commit = CASE WHEN ` + introducedEarlier + ` THEN excluded.introduced_commit ELSE findings.introduced_commit END,
+	introduced_at = CASE WHEN ` + introducedEarlier + ` THEN excluded.introduced_at ELSE findings.introduced_at END`
+
+	introducedEarlier = `(findings.introduced_commit = '' OR excluded.introduced_at < findings.introduced_at OR
+	(findings.introduced_at IS NULL AND excluded.introduced_at IS NOT NULL))`
+
+	insertFindingRunQuery = `INSERT INTO finding_runs (run_id, fingerprint) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	upsertRepoStatsQuery = `INSERT INTO repo_stats (run_id, repository, findings, verified_findings) VALUES ($1, $2, $3, $4)
+ON CONFLICT (run_id, repository) DO UPDATE SET
+	findings = excluded.findings,
+	verified_findings = excluded.verified_findings`
+
+	finishRunQuery = `UPDATE scan_runs SET finished_at = $1, findings = $2, verified_findings = $3 WHERE id = $4`
+)
+
+// DatabasePrinter is a printer that records results in a PostgreSQL or SQLite
+// database using the schema in database_schema.sql. Each finding is upserted
+// by fingerprint as it is printed, and the run and per-repository statistics
+// are written once the scan has finished.
+type DatabasePrinter struct {
+	db     *sql.DB
+	rebind func(string) string
+	runID  string
+
+	hashSecrets bool
+	retention   RetentionPolicy
+
+	mu    sync.Mutex
+	stats map[string]*repoStats
+}
+
+type repoStats struct {
+	findings, verified int64
+}
+
+// DatabaseOption configures a DatabasePrinter.
+type DatabaseOption func(*DatabasePrinter)
+
+// WithHashedSecrets stores the hashes of secrets instead of the secrets,
+// masked or not.
+func WithHashedSecrets() DatabaseOption {
+	return func(p *DatabasePrinter) { p.hashSecrets = true }
+}
+
+// WithRetention purges what policy no longer retains once a run finishes.
+func WithRetention(policy RetentionPolicy) DatabaseOption {
+	return func(p *DatabasePrinter) { p.retention = policy }
+}
+
+// NewDatabasePrinter connects to the database at dsn, creates the schema if
+// needed and records the start of a new scan run. URLs with a postgres:// or
+// postgresql:// scheme connect to PostgreSQL; a sqlite:// URL or a plain file
+// path opens an SQLite database.
+func NewDatabasePrinter(ctx context.Context, dsn string, opts ...DatabaseOption) (*DatabasePrinter, error) {
+	db, rebind, err := openDatabase(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &DatabasePrinter{db: db, rebind: rebind, runID: uuid.NewString(), stats: make(map[string]*repoStats)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if _, err := db.ExecContext(ctx, rebind(insertRunQuery), p.runID, time.Now().UTC(), version.BuildVersion); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not record scan run: %w", err)
+	}
+	return p, nil
+}
+
+// openDatabase opens the database at dsn and creates or updates its schema.
+func openDatabase(ctx context.Context, dsn string) (*sql.DB, func(string) string, error) {
+	driver, source := "sqlite", strings.TrimPrefix(dsn, "sqlite://")
+	rebind := sqliteRebind
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		driver, source = "postgres", dsn
+		rebind = func(query string) string { return query }
+	}
+
+	db, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open %s database: %w", driver, err)
+	}
+	if driver == "sqlite" {
+		// SQLite only supports a single writer.
+		db.SetMaxOpenConns(1)
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return db, rebind, nil
+}
+
+// addedColumns are the columns added to tables after they were first
+// created, which databases created before them lack.
+var addedColumns = []struct{ table, column, definition string }{
+	{"findings", "introduced_commit", "TEXT NOT NULL DEFAULT ''"},
+	{"findings", "introduced_at", "TIMESTAMP"},
+}
+
+func migrate(ctx context.Context, db *sql.DB) error {
+	for _, stmt := range strings.Split(databaseSchema, ";\n") {
+		if strings.TrimSpace(stripSQLComments(stmt)) == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("could not create database schema: %w", err)
+		}
+	}
+	// SQLite has no ADD COLUMN IF NOT EXISTS, so columns are only added when
+	// selecting them fails.
+	for _, c := range addedColumns {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("SELECT %s FROM %s LIMIT 0", c.column, c.table)); err == nil {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("could not update database schema: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *DatabasePrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(r)
+	}
+	secret := r.Redacted
+	if raw := strings.TrimSpace(string(r.Raw)); secret == "" || envelope.IsSealed(raw) {
+		secret = raw
+	}
+	if p.hashSecrets {
+		secret = hashSecret(strings.TrimSpace(string(r.Raw)))
+	}
+	repo := loc.Repository
+	if repo == "" {
+		repo = r.SourceName
+	}
+
+	_, err = p.db.ExecContext(ctx, p.rebind(upsertFindingQuery),
+		fingerprint,
+		r.DetectorType.String(),
+		r.DecoderType.String(),
+		r.Verified,
+		secret,
+		r.SourceName,
+		repo,
+		loc.File,
+		loc.Line,
+		loc.Commit,
+		reportLink(loc),
+		p.runID,
+		time.Now().UTC(),
+		commitTime(loc.Timestamp),
+	)
+	if err != nil {
+		return fmt.Errorf("could not record finding: %w", err)
+	}
+	if _, err := p.db.ExecContext(ctx, p.rebind(insertFindingRunQuery), p.runID, fingerprint); err != nil {
+		return fmt.Errorf("could not record finding: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats, ok := p.stats[repo]
+	if !ok {
+		stats = new(repoStats)
+		p.stats[repo] = stats
+	}
+	stats.findings++
+	if r.Verified {
+		stats.verified++
+	}
+	return nil
+}
+
+// Flush records the per-repository statistics and the end of the scan run,
+// purges what the retention policy no longer retains, then closes the
+// database.
+func (p *DatabasePrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.db.Close()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not record scan run: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var total, verified int64
+	for repo, stats := range p.stats {
+		total += stats.findings
+		verified += stats.verified
+		if _, err := tx.ExecContext(ctx, p.rebind(upsertRepoStatsQuery), p.runID, repo, stats.findings, stats.verified); err != nil {
+			return fmt.Errorf("could not record repository stats: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, p.rebind(finishRunQuery), time.Now().UTC(), total, verified, p.runID); err != nil {
+		return fmt.Errorf("could not record scan run: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not record scan run: %w", err)
+	}
+	if p.retention != (RetentionPolicy{}) {
+		if _, err := purge(ctx, p.db, p.rebind, p.retention, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitTimeLayouts are the formats sources write commit timestamps in.
+var commitTimeLayouts = []string{"2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 -0700 MST", time.RFC3339}
+
+// commitTime parses the timestamp of a finding's commit, returning nil if it
+// has none, so that it is stored as NULL.
+func commitTime(timestamp string) *time.Time {
+	for _, layout := range commitTimeLayouts {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+var postgresPlaceholderPat = regexp.MustCompile(`\$(\d+)`)
+
+// sqliteRebind rewrites PostgreSQL-style placeholders into SQLite's numbered
+// "?NNN" form, which likewise allows a parameter to be referenced twice.
+func sqliteRebind(query string) string {
+	return postgresPlaceholderPat.ReplaceAllString(query, "?$1")
+}
+
+func stripSQLComments(stmt string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
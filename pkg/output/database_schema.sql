@@ -0,0 +1,59 @@
+-- Schema used by the database output sink (--database-url). It is applied
+-- automatically on startup and is compatible with both PostgreSQL and SQLite.
+
+-- scan_runs has one row per TruffleHog invocation.
+CREATE TABLE IF NOT EXISTS scan_runs (
+    id                TEXT PRIMARY KEY,
+    started_at        TIMESTAMP NOT NULL,
+    finished_at       TIMESTAMP,
+    version           TEXT NOT NULL,
+    findings          BIGINT NOT NULL DEFAULT 0,
+    verified_findings BIGINT NOT NULL DEFAULT 0
+);
+
+-- findings has one row per unique finding, keyed by its fingerprint (see
+-- output.Fingerprint). Rows are upserted, so re-running a scan updates the
+-- existing row rather than creating a duplicate. The secret column holds the
+-- masked secret unless the scan was run with --show-secrets, or its hash or
+-- encrypted form with --database-hash-secrets or --secrets-encryption-key
+-- (see pkg/envelope). The introduced
+-- columns hold the earliest commit the finding has been seen in, and its date.
+CREATE TABLE IF NOT EXISTS findings (
+    fingerprint    TEXT PRIMARY KEY,
+    detector       TEXT NOT NULL,
+    decoder        TEXT NOT NULL,
+    verified       BOOLEAN NOT NULL,
+    secret         TEXT NOT NULL,
+    source_name    TEXT NOT NULL,
+    repository     TEXT NOT NULL,
+    file           TEXT NOT NULL,
+    line           BIGINT NOT NULL,
+    commit_hash    TEXT NOT NULL,
+    link           TEXT NOT NULL,
+    first_seen_run TEXT NOT NULL REFERENCES scan_runs (id),
+    first_seen_at  TIMESTAMP NOT NULL,
+    last_seen_run  TEXT NOT NULL REFERENCES scan_runs (id),
+    last_seen_at   TIMESTAMP NOT NULL,
+    introduced_commit TEXT NOT NULL DEFAULT '',
+    introduced_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS findings_repository_idx ON findings (repository);
+CREATE INDEX IF NOT EXISTS findings_last_seen_run_idx ON findings (last_seen_run);
+
+-- finding_runs has the fingerprints of the findings of each run, which is what
+-- `trufflehog report drift` compares.
+CREATE TABLE IF NOT EXISTS finding_runs (
+    run_id      TEXT NOT NULL REFERENCES scan_runs (id),
+    fingerprint TEXT NOT NULL REFERENCES findings (fingerprint),
+    PRIMARY KEY (run_id, fingerprint)
+);
+
+-- repo_stats has the number of findings per repository for each run.
+CREATE TABLE IF NOT EXISTS repo_stats (
+    run_id            TEXT NOT NULL REFERENCES scan_runs (id),
+    repository        TEXT NOT NULL,
+    findings          BIGINT NOT NULL,
+    verified_findings BIGINT NOT NULL,
+    PRIMARY KEY (run_id, repository)
+);
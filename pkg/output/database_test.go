@@ -0,0 +1,142 @@
+package output
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestSQLiteRebind(t *testing.T) {
+	assert.Equal(t, "VALUES (?1, ?2, ?12, ?12)", sqliteRebind("VALUES ($1, $2, $12, $12)"))
+}
+
+func TestDatabasePrinter_SQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+
+	// Two runs over the same findings should upsert rather than duplicate.
+	for i := 0; i < 2; i++ {
+		p, err := NewDatabasePrinter(ctx, "sqlite://"+path)
+		require.NoError(t, err)
+		require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)))
+		require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLE", true)))
+		require.NoError(t, p.Print(ctx, gitResult("c2", "other.yaml", "AKIAEXAMPLE", false)))
+		require.NoError(t, p.Flush(ctx))
+	}
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var findings, runs int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM findings`).Scan(&findings))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM scan_runs WHERE finished_at IS NOT NULL`).Scan(&runs))
+	assert.Equal(t, 2, findings)
+	assert.Equal(t, 2, runs)
+
+	var verified bool
+	var commit string
+	require.NoError(t, db.QueryRow(`SELECT verified, commit_hash FROM findings WHERE file = 'config.yaml'`).Scan(&verified, &commit))
+	assert.True(t, verified)
+	assert.Equal(t, "c2", commit)
+
+	var repoFindings, repoVerified int
+	require.NoError(t, db.QueryRow(`SELECT findings, verified_findings FROM repo_stats LIMIT 1`).Scan(&repoFindings, &repoVerified))
+	assert.Equal(t, 3, repoFindings)
+	assert.Equal(t, 1, repoVerified)
+}
+
+func TestDatabasePrinter_Introduced(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+
+	// The first scan only reaches the newer commit, and the second goes
+	// further back in history.
+	newer := gitResult("c2", "config.yaml", "AKIAEXAMPLE", false)
+	newer.SourceMetadata.GetGit().Timestamp = "2024-03-02 10:00:00 +0000"
+	older := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	older.SourceMetadata.GetGit().Timestamp = "2024-03-01 12:00:00 +0200"
+	for _, results := range [][]*detectors.ResultWithMetadata{{newer}, {older, newer}} {
+		p, err := NewDatabasePrinter(ctx, path)
+		require.NoError(t, err)
+		for _, r := range results {
+			require.NoError(t, p.Print(ctx, r))
+		}
+		require.NoError(t, p.Flush(ctx))
+	}
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var commit, lastCommit string
+	var introducedAt time.Time
+	require.NoError(t, db.QueryRow(`SELECT introduced_commit, introduced_at, commit_hash FROM findings`).Scan(&commit, &introducedAt, &lastCommit))
+	assert.Equal(t, "c1", commit)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), introducedAt.UTC())
+	assert.Equal(t, "c2", lastCommit)
+}
+
+func TestDatabasePrinter_AddsColumns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE findings (fingerprint TEXT PRIMARY KEY, detector TEXT NOT NULL, decoder TEXT NOT NULL,
+	verified BOOLEAN NOT NULL, secret TEXT NOT NULL, source_name TEXT NOT NULL, repository TEXT NOT NULL, file TEXT NOT NULL,
+	line BIGINT NOT NULL, commit_hash TEXT NOT NULL, link TEXT NOT NULL, first_seen_run TEXT NOT NULL, first_seen_at TIMESTAMP NOT NULL,
+	last_seen_run TEXT NOT NULL, last_seen_at TIMESTAMP NOT NULL)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	p, err := NewDatabasePrinter(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Flush(ctx))
+}
+
+func TestDatabaseDrift(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+
+	_, err := DatabaseDrift(ctx, path, "", "")
+	assert.ErrorContains(t, err, "not enough finished scan runs")
+
+	var runs []string
+	for _, files := range [][]string{{"config.yaml", "old.yaml"}, {"config.yaml", "new.yaml"}} {
+		p, err := NewDatabasePrinter(ctx, path)
+		require.NoError(t, err)
+		for _, file := range files {
+			require.NoError(t, p.Print(ctx, gitResult("c1", file, "AKIAEXAMPLE", false)))
+		}
+		require.NoError(t, p.Flush(ctx))
+		runs = append(runs, p.runID)
+		// Runs are ordered by when they started.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	report, err := DatabaseDrift(ctx, path, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, runs[0], report.From.ID)
+	assert.Equal(t, runs[1], report.To.ID)
+	require.Len(t, report.Introduced, 1)
+	assert.Equal(t, "new.yaml", report.Introduced[0].File)
+	assert.Equal(t, "c1", report.Introduced[0].IntroducedCommit)
+	require.Len(t, report.Remediated, 1)
+	assert.Equal(t, "old.yaml", report.Remediated[0].File)
+
+	// Swapping the runs swaps what was introduced and remediated.
+	report, err = DatabaseDrift(ctx, path, runs[1], runs[0])
+	require.NoError(t, err)
+	assert.Equal(t, "old.yaml", report.Introduced[0].File)
+	assert.Equal(t, "new.yaml", report.Remediated[0].File)
+
+	_, err = DatabaseDrift(ctx, path, "missing", "")
+	assert.ErrorContains(t, err, "scan run missing not found")
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// DiffFinding is a finding of one of the two results files compared by
+// DiffResults.
+type DiffFinding struct {
+	Fingerprint string
+	Detector    string
+	Verified    bool
+	Secret      string
+	Repository  string `json:",omitempty"`
+	File        string `json:",omitempty"`
+	Line        int64  `json:",omitempty"`
+	Link        string `json:",omitempty"`
+}
+
+// ResultsDiff compares the findings of two results files: the ones that are
+// new in the later file, the ones it no longer has, and the ones both have.
+type ResultsDiff struct {
+	New        []DiffFinding
+	Resolved   []DiffFinding
+	Persisting []DiffFinding
+}
+
+// NewVerified returns the number of new findings that are verified.
+func (d *ResultsDiff) NewVerified() int {
+	n := 0
+	for _, f := range d.New {
+		if f.Verified {
+			n++
+		}
+	}
+	return n
+}
+
+// diffResult is the part of a JSON result DiffResults compares. The source
+// metadata is read generically, as its oneof can't be unmarshalled.
+type diffResult struct {
+	SourceMetadata struct {
+		Data       map[string]map[string]any
+		Provenance []*source_metadatapb.Container `json:"provenance"`
+	}
+	SourceName   string
+	DetectorType detectorspb.DetectorType
+	Verified     bool
+	Raw          string
+	RawV2        string
+	Redacted     string
+	Fingerprint  string
+}
+
+// DiffResults compares the findings of two results files, old and latest, by
+// fingerprint. Both hold JSON results, one per line, as JSONPrinter prints
+// them. The results of a finding found more than once in a file, such as in
+// several commits, are compared once, as verified if any of them is.
+// Results without a fingerprint, printed by versions that didn't compute
+// them, are fingerprinted from their detector, secret, and location.
+func DiffResults(old, latest io.Reader) (*ResultsDiff, error) {
+	oldOrder, oldFindings, err := readDiffFindings(old)
+	if err != nil {
+		return nil, fmt.Errorf("could not read old results: %w", err)
+	}
+	newOrder, newFindings, err := readDiffFindings(latest)
+	if err != nil {
+		return nil, fmt.Errorf("could not read new results: %w", err)
+	}
+
+	diff := new(ResultsDiff)
+	for _, fp := range newOrder {
+		if _, ok := oldFindings[fp]; ok {
+			diff.Persisting = append(diff.Persisting, newFindings[fp])
+		} else {
+			diff.New = append(diff.New, newFindings[fp])
+		}
+	}
+	for _, fp := range oldOrder {
+		if _, ok := newFindings[fp]; !ok {
+			diff.Resolved = append(diff.Resolved, oldFindings[fp])
+		}
+	}
+	return diff, nil
+}
+
+// readDiffFindings reads the findings of a results file, and the order of
+// their fingerprints in it.
+func readDiffFindings(r io.Reader) ([]string, map[string]DiffFinding, error) {
+	var order []string
+	findings := make(map[string]DiffFinding)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var result diffResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid result: %w", n, err)
+		}
+
+		f := result.finding()
+		existing, ok := findings[f.Fingerprint]
+		switch {
+		case !ok:
+			order = append(order, f.Fingerprint)
+			findings[f.Fingerprint] = f
+		case f.Verified && !existing.Verified:
+			findings[f.Fingerprint] = f
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return order, findings, nil
+}
+
+func (r *diffResult) finding() DiffFinding {
+	loc := locationOf(r.SourceMetadata.Data, r.SourceMetadata.Provenance)
+	repo := loc.Repository
+	if repo == "" {
+		repo = r.SourceName
+	}
+	f := DiffFinding{
+		Fingerprint: r.Fingerprint,
+		Detector:    r.DetectorType.String(),
+		Verified:    r.Verified,
+		Secret:      r.Redacted,
+		Repository:  repo,
+		File:        loc.File,
+		Line:        loc.Line,
+		Link:        loc.Link,
+	}
+	if f.Secret == "" {
+		f.Secret = strings.TrimSpace(r.Raw)
+	}
+	if f.Fingerprint == "" {
+		secret := r.RawV2
+		if secret == "" {
+			secret = r.Raw
+		}
+		secretHash := sha256.Sum256([]byte(strings.TrimSpace(secret)))
+		f.Fingerprint = fingerprint(r.DetectorType.String(), "", hex.EncodeToString(secretHash[:]), repo, loc.File)
+	}
+	return f
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func resultsFile(t *testing.T, results ...*detectors.ResultWithMetadata) string {
+	t.Helper()
+	var b strings.Builder
+	for _, r := range results {
+		r.Fingerprint = Fingerprint(r)
+		out, err := MarshalResult(r)
+		require.NoError(t, err)
+		b.Write(out)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestDiffResults(t *testing.T) {
+	old := resultsFile(t,
+		gitResult("aaa", "config.yaml", "AKIAPERSIST", false),
+		gitResult("aaa", "deploy.sh", "AKIARESOLVED", true),
+	)
+	latest := resultsFile(t,
+		gitResult("bbb", "config.yaml", "AKIAPERSIST", false),
+		gitResult("bbb", "app.env", "AKIANEW", false),
+		gitResult("ccc", "app.env", "AKIANEW", true),
+		gitResult("bbb", "main.go", "AKIANEWUNVERIFIED", false),
+	)
+
+	diff, err := DiffResults(strings.NewReader(old), strings.NewReader("\n"+latest))
+	require.NoError(t, err)
+
+	require.Len(t, diff.New, 2)
+	assert.Equal(t, "app.env", diff.New[0].File)
+	assert.True(t, diff.New[0].Verified, "a finding is verified if any of its results is")
+	assert.Equal(t, "main.go", diff.New[1].File)
+	assert.Equal(t, 1, diff.NewVerified())
+
+	require.Len(t, diff.Resolved, 1)
+	assert.Equal(t, "deploy.sh", diff.Resolved[0].File)
+	assert.Equal(t, "AKIARESOLVED", diff.Resolved[0].Secret)
+	assert.Equal(t, "https://github.com/org/repo.git", diff.Resolved[0].Repository)
+
+	require.Len(t, diff.Persisting, 1)
+	assert.Equal(t, "config.yaml", diff.Persisting[0].File)
+}
+
+func TestDiffResults_NoFingerprint(t *testing.T) {
+	r := gitResult("aaa", "config.yaml", "AKIAEXAMPLE", false)
+	out, err := MarshalResult(r)
+	require.NoError(t, err)
+
+	diff, err := DiffResults(strings.NewReader(resultsFile(t, r)), strings.NewReader(string(out)))
+	require.NoError(t, err)
+	assert.Empty(t, diff.New)
+	assert.Empty(t, diff.Resolved)
+	assert.Len(t, diff.Persisting, 1)
+}
+
+func TestDiffResults_Invalid(t *testing.T) {
+	_, err := DiffResults(strings.NewReader(""), strings.NewReader("{}\nnot json\n"))
+	assert.ErrorContains(t, err, "could not read new results: line 2")
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// DriftRun is a scan run recorded by the DatabasePrinter.
+type DriftRun struct {
+	ID        string
+	StartedAt time.Time
+}
+
+// DriftFinding is a finding that is in one of the two runs of a drift report
+// but not the other.
+type DriftFinding struct {
+	Fingerprint      string
+	Detector         string
+	Verified         bool
+	Secret           string
+	Repository       string
+	File             string     `json:",omitempty"`
+	Line             int64      `json:",omitempty"`
+	Link             string     `json:",omitempty"`
+	IntroducedCommit string     `json:",omitempty"`
+	IntroducedAt     *time.Time `json:",omitempty"`
+	FirstSeenAt      time.Time
+}
+
+// DriftReport compares the findings of two scan runs: the secrets that were
+// introduced since the earlier run, and the ones that were remediated.
+type DriftReport struct {
+	From       DriftRun
+	To         DriftRun
+	Introduced []DriftFinding
+	Remediated []DriftFinding
+}
+
+const (
+	selectRunQuery       = `SELECT id, started_at FROM scan_runs WHERE id = $1`
+	selectLatestRunQuery = `SELECT id, started_at FROM scan_runs WHERE finished_at IS NOT NULL
+ORDER BY started_at DESC LIMIT 1`
+	selectRunBeforeQuery = `SELECT id, started_at FROM scan_runs WHERE finished_at IS NOT NULL AND started_at < $1
+ORDER BY started_at DESC LIMIT 1`
+
+	selectDriftQuery = `SELECT f.fingerprint, f.detector, f.verified, f.secret, f.repository, f.file, f.line, f.link,
+	f.introduced_commit, f.introduced_at, f.first_seen_at
+FROM finding_runs r JOIN findings f ON f.fingerprint = r.fingerprint
+WHERE r.run_id = $1 AND NOT EXISTS (
+	SELECT 1 FROM finding_runs o WHERE o.run_id = $2 AND o.fingerprint = r.fingerprint
+)
+ORDER BY f.repository, f.file, f.line, f.fingerprint`
+)
+
+// DatabaseDrift reports the drift between two of the scan runs recorded in
+// the database at dsn. An empty to is the latest finished run, and an empty
+// from is the finished run before to.
+func DatabaseDrift(ctx context.Context, dsn, from, to string) (*DriftReport, error) {
+	db, rebind, err := openDatabase(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	report := new(DriftReport)
+	if report.To, err = findRun(ctx, db, rebind, to, time.Time{}); err != nil {
+		return nil, err
+	}
+	if report.From, err = findRun(ctx, db, rebind, from, report.To.StartedAt); err != nil {
+		return nil, err
+	}
+	if report.Introduced, err = driftFindings(ctx, db, rebind, report.To.ID, report.From.ID); err != nil {
+		return nil, err
+	}
+	if report.Remediated, err = driftFindings(ctx, db, rebind, report.From.ID, report.To.ID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// findRun finds the run with the given ID or, if the ID is empty, the last
+// finished run that started before the given time, if it isn't zero.
+func findRun(ctx context.Context, db *sql.DB, rebind func(string) string, id string, before time.Time) (DriftRun, error) {
+	var row *sql.Row
+	switch {
+	case id != "":
+		row = db.QueryRowContext(ctx, rebind(selectRunQuery), id)
+	case before.IsZero():
+		row = db.QueryRowContext(ctx, selectLatestRunQuery)
+	default:
+		row = db.QueryRowContext(ctx, rebind(selectRunBeforeQuery), before)
+	}
+	var run DriftRun
+	err := row.Scan(&run.ID, &run.StartedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && id != "":
+		return run, fmt.Errorf("scan run %s not found", id)
+	case errors.Is(err, sql.ErrNoRows):
+		return run, errors.New("not enough finished scan runs to compare")
+	case err != nil:
+		return run, fmt.Errorf("could not read scan runs: %w", err)
+	}
+	run.StartedAt = run.StartedAt.UTC()
+	return run, nil
+}
+
+// driftFindings returns the findings of run that other doesn't have.
+func driftFindings(ctx context.Context, db *sql.DB, rebind func(string) string, run, other string) ([]DriftFinding, error) {
+	rows, err := db.QueryContext(ctx, rebind(selectDriftQuery), run, other)
+	if err != nil {
+		return nil, fmt.Errorf("could not read findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []DriftFinding
+	for rows.Next() {
+		var f DriftFinding
+		var introducedAt sql.NullTime
+		if err := rows.Scan(&f.Fingerprint, &f.Detector, &f.Verified, &f.Secret, &f.Repository, &f.File, &f.Line, &f.Link,
+			&f.IntroducedCommit, &introducedAt, &f.FirstSeenAt); err != nil {
+			return nil, fmt.Errorf("could not read findings: %w", err)
+		}
+		if introducedAt.Valid {
+			t := introducedAt.Time.UTC()
+			f.IntroducedAt = &t
+		}
+		f.FirstSeenAt = f.FirstSeenAt.UTC()
+		findings = append(findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read findings: %w", err)
+	}
+	return findings, nil
+}
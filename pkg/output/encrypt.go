@@ -0,0 +1,64 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/envelope"
+)
+
+// EncryptingPrinter wraps a printer that persists results and encrypts their
+// raw secret values instead of masking them, so that they can be recovered
+// with the key but aren't stored in plaintext. Fingerprints are computed
+// before encrypting, so they stay the same as without encryption.
+type EncryptingPrinter struct {
+	printer resultPrinter
+	sealer  *envelope.Sealer
+}
+
+// NewEncryptingPrinter creates an EncryptingPrinter that encrypts secrets
+// with sealer before handing results to printer.
+func NewEncryptingPrinter(printer resultPrinter, sealer *envelope.Sealer) *EncryptingPrinter {
+	return &EncryptingPrinter{printer: printer, sealer: sealer}
+}
+
+func (p *EncryptingPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	sealed := *r
+	if sealed.Fingerprint == "" {
+		sealed.Fingerprint = Fingerprint(r)
+	}
+	var err error
+	if sealed.Raw, err = p.seal(r.Raw); err != nil {
+		return err
+	}
+	if sealed.RawV2, err = p.seal(r.RawV2); err != nil {
+		return err
+	}
+	sealed.Data = nil
+	if r.Context != nil {
+		sealed.Context = redactContext(r.Context, r.Raw, r.RawV2)
+	}
+	return p.printer.Print(ctx, &sealed)
+}
+
+// Flush flushes the wrapped printer if it buffers its output.
+func (p *EncryptingPrinter) Flush(ctx context.Context) error {
+	if f, ok := p.printer.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func (p *EncryptingPrinter) seal(raw []byte) ([]byte, error) {
+	secret := strings.TrimSpace(string(raw))
+	if secret == "" {
+		return raw, nil
+	}
+	sealed, err := p.sealer.Seal([]byte(secret))
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt secret: %w", err)
+	}
+	return []byte(sealed), nil
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	aCtx "context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/envelope"
+)
+
+// plainWrapper wraps data keys as they are.
+type plainWrapper struct{}
+
+func (plainWrapper) Scheme() string { return "plain" }
+
+func (plainWrapper) Wrap(_ aCtx.Context, dataKey []byte) ([]byte, error) { return dataKey, nil }
+
+func (plainWrapper) Unwrap(_ aCtx.Context, wrapped []byte) ([]byte, error) { return wrapped, nil }
+
+func newTestSealer(t *testing.T) *envelope.Sealer {
+	t.Helper()
+	sealer, err := envelope.NewSealer(context.Background(), plainWrapper{})
+	require.NoError(t, err)
+	return sealer
+}
+
+func TestEncryptingPrinter(t *testing.T) {
+	rec := new(recordingPrinter)
+	r := gitResult("abc123", "config.yaml", "AKIAEXAMPLEKEY", true)
+	r.RawV2 = []byte("AKIAEXAMPLEKEYwJalrXUtnFEMIK7MDENG")
+	r.Data = []byte("chunk")
+	r.Context = &detectors.LineContext{Line: "aws_access_key_id = AKIAEXAMPLEKEY"}
+	require.NoError(t, NewEncryptingPrinter(rec, newTestSealer(t)).Print(context.Background(), r))
+
+	require.Len(t, rec.results, 1)
+	got := rec.results[0]
+	assert.True(t, envelope.IsSealed(string(got.Raw)))
+	assert.True(t, envelope.IsSealed(string(got.RawV2)))
+	assert.NotContains(t, string(got.Raw), "AKIAEXAMPLEKEY")
+	assert.Equal(t, Fingerprint(r), got.Fingerprint)
+	assert.Nil(t, got.Data)
+	assert.Equal(t, "aws_access_key_id = "+MaskSecret("AKIAEXAMPLEKEY"), got.Context.Line)
+	assert.Equal(t, "AKIAEXAMPLEKEY", string(r.Raw), "the original result should not be modified")
+}
+
+func TestEncryptingPrinter_Database(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+	db, err := NewDatabasePrinter(ctx, path)
+	require.NoError(t, err)
+	p := NewEncryptingPrinter(db, newTestSealer(t))
+	r := gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)
+	// Sealed secrets are stored even if the detector has a redacted form.
+	r.Redacted = "AKIA****"
+	require.NoError(t, p.Print(ctx, r))
+	require.NoError(t, p.Flush(ctx))
+
+	conn, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	var fingerprint, secret string
+	require.NoError(t, conn.QueryRow(`SELECT fingerprint, secret FROM findings`).Scan(&fingerprint, &secret))
+	assert.Equal(t, Fingerprint(r), fingerprint)
+	assert.True(t, strings.HasPrefix(secret, envelope.Prefix+"plain:"))
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// Fingerprint computes a stable identifier for a result from its detector,
+// a hash of the normalized secret, and the repository and path it was found
+// in. The commit and line are deliberately excluded so the same secret in the
+// same file produces the same fingerprint across commits and scans.
+func Fingerprint(r *detectors.ResultWithMetadata) string {
+	secret := r.RawV2
+	if len(secret) == 0 {
+		secret = r.Raw
+	}
+	secretHash := sha256.Sum256([]byte(strings.TrimSpace(string(secret))))
+
+	// Location extraction only fails for metadata that can't be marshalled, in
+	// which case the fingerprint falls back to the source name alone.
+	loc, _ := extractLocation(r.SourceMetadata)
+	repo := loc.Repository
+	if repo == "" {
+		repo = r.SourceName
+	}
+
+	return fingerprint(r.DetectorType.String(), r.DetectorName, hex.EncodeToString(secretHash[:]), repo, loc.File)
+}
+
+// fingerprint hashes the parts of a fingerprint.
+func fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DedupingPrinter wraps a printer and collapses results that share a
+// fingerprint into a single result whose Occurrences list every location it
+// was found at. Results are printed once the scan has finished, in the order
+// they were first seen.
+type DedupingPrinter struct {
+	mu       sync.Mutex
+	printer  resultPrinter
+	order    []string
+	findings map[string]*detectors.ResultWithMetadata
+}
+
+// NewDedupingPrinter creates a DedupingPrinter that prints deduplicated
+// results to printer.
+func NewDedupingPrinter(printer resultPrinter) *DedupingPrinter {
+	return &DedupingPrinter{printer: printer, findings: make(map[string]*detectors.ResultWithMetadata)}
+}
+
+func (p *DedupingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(r)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, ok := p.findings[fingerprint]
+	if !ok {
+		finding := *r
+		finding.Fingerprint = fingerprint
+		finding.Data = nil
+		finding.Occurrences = []*source_metadatapb.MetaData{r.SourceMetadata}
+		p.findings[fingerprint] = &finding
+		p.order = append(p.order, fingerprint)
+		return nil
+	}
+
+	existing.Occurrences = append(existing.Occurrences, r.SourceMetadata)
+	// Prefer a verified result as the representative of the finding.
+	if r.Verified && !existing.Verified {
+		occurrences := existing.Occurrences
+		*existing = *r
+		existing.Fingerprint = fingerprint
+		existing.Data = nil
+		existing.Occurrences = occurrences
+	}
+	return nil
+}
+
+// Flush prints every deduplicated finding and flushes the wrapped printer.
+func (p *DedupingPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, fingerprint := range p.order {
+		if err := p.printer.Print(ctx, p.findings[fingerprint]); err != nil {
+			ctx.Logger().Error(err, "error printing deduplicated result")
+		}
+	}
+	p.order = nil
+	p.findings = make(map[string]*detectors.ResultWithMetadata)
+
+	if f, ok := p.printer.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
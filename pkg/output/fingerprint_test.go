@@ -0,0 +1,90 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+type recordingPrinter struct {
+	results []*detectors.ResultWithMetadata
+}
+
+func (p *recordingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.results = append(p.results, r)
+	return nil
+}
+
+func gitResult(commit, file, raw string, verified bool) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{
+				Repository: "https://github.com/org/repo.git",
+				Commit:     commit,
+				File:       file,
+				Line:       1,
+			}},
+		},
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Raw:          []byte(raw),
+			Verified:     verified,
+		},
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint(gitResult("aaa", "config.yaml", "AKIAEXAMPLE", false))
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, Fingerprint(gitResult("bbb", "config.yaml", "AKIAEXAMPLE", true)), "commit should not change the fingerprint")
+	assert.Equal(t, base, Fingerprint(gitResult("aaa", "config.yaml", " AKIAEXAMPLE\n", false)), "surrounding whitespace should be ignored")
+	assert.NotEqual(t, base, Fingerprint(gitResult("aaa", "other.yaml", "AKIAEXAMPLE", false)))
+	assert.NotEqual(t, base, Fingerprint(gitResult("aaa", "config.yaml", "AKIAOTHER", false)))
+}
+
+func TestFingerprintNestedArchive(t *testing.T) {
+	nested := func(entry string) *detectors.ResultWithMetadata {
+		r := gitResult("aaa", "backup.tar.gz", "AKIAEXAMPLE", false)
+		r.SourceMetadata.Provenance = []*source_metadatapb.Container{
+			{Format: "gz"},
+			{Format: "tar", Path: "app/creds.zip"},
+			{Format: "zip", Path: entry},
+		}
+		return r
+	}
+
+	loc, err := extractLocation(nested(".env").SourceMetadata)
+	require.NoError(t, err)
+	assert.Equal(t, "backup.tar.gz!app/creds.zip!.env", loc.File)
+	assert.NotEqual(t, Fingerprint(nested(".env")), Fingerprint(nested("config/.env")))
+	assert.NotEqual(t, Fingerprint(gitResult("aaa", "backup.tar.gz", "AKIAEXAMPLE", false)), Fingerprint(nested(".env")))
+}
+
+func TestDedupingPrinter(t *testing.T) {
+	ctx := context.Background()
+	rec := new(recordingPrinter)
+	p := NewDedupingPrinter(rec)
+
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "other.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Print(ctx, gitResult("c3", "config.yaml", "AKIAEXAMPLE", true)))
+	assert.Empty(t, rec.results, "results should be held until flush")
+
+	require.NoError(t, p.Flush(ctx))
+	require.Len(t, rec.results, 2)
+
+	first := rec.results[0]
+	assert.True(t, first.Verified, "the verified occurrence should represent the finding")
+	require.Len(t, first.Occurrences, 2)
+	assert.Equal(t, "c1", first.Occurrences[0].GetGit().Commit)
+	assert.Equal(t, "c3", first.Occurrences[1].GetGit().Commit)
+	assert.NotEmpty(t, first.Fingerprint)
+
+	assert.Len(t, rec.results[1].Occurrences, 1)
+}
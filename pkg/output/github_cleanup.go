@@ -0,0 +1,395 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/google/go-github/v66/github"
+	"gopkg.in/yaml.v3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// DefaultCleanupReplacement is the default template of the text secrets are
+// replaced with by cleanup pull requests.
+const DefaultCleanupReplacement = "{{with .Canary}}{{.}}{{else}}TRUFFLEHOG_REMOVED_{{upper .Detector}}_{{upper .Part}}{{end}}"
+
+// cleanupBranchPrefix is the prefix of the branches of cleanup pull requests.
+const cleanupBranchPrefix = "trufflehog/remove-secrets-"
+
+// cleanupReplacement is the data replacement templates are executed with.
+type cleanupReplacement struct {
+	Detector string
+	// Part is "secret", or "id" for the identifier of a multi-part secret,
+	// such as the access key ID of AWS credentials.
+	Part string
+	// Canary is the canary token planted for this part of the detector's
+	// secrets, if there is one.
+	Canary string
+	File   string
+}
+
+// cleanupPart is a part of a secret found in a file.
+type cleanupPart struct {
+	detector detectorspb.DetectorType
+	part     string
+	value    string
+	preview  string
+}
+
+// GitHubCleanupPrinter is a printer that opens a pull request on every private
+// GitHub repository with verified results, replacing their secrets with a
+// placeholder or a canary token in the repository's default branch. Secrets
+// that are no longer in the default branch are skipped.
+//
+// Public repositories are skipped as well: their secrets have already been
+// exposed, and pointing at them in a pull request only draws attention. The
+// pull request doesn't rewrite history nor rotate anything, which its
+// description says.
+type GitHubCleanupPrinter struct {
+	client      *github.Client
+	host        string
+	replacement *template.Template
+	canaries    Canaries
+
+	mu    sync.Mutex
+	repos map[string]map[string][]cleanupPart
+}
+
+// NewGitHubCleanupPrinter creates a GitHubCleanupPrinter. Secrets are replaced
+// with the text rendered by the replacement template, or
+// DefaultCleanupReplacement if it is empty, which can plant canaries. The
+// endpoint is the GitHub API URL, and may be empty to use github.com.
+func NewGitHubCleanupPrinter(token, endpoint, replacement string, canaries Canaries) (*GitHubCleanupPrinter, error) {
+	client, err := newGitHubClient(common.RetryableHTTPClient(), token, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return newGitHubCleanupPrinter(client, replacement, canaries)
+}
+
+func newGitHubCleanupPrinter(client *github.Client, replacement string, canaries Canaries) (*GitHubCleanupPrinter, error) {
+	if replacement == "" {
+		replacement = DefaultCleanupReplacement
+	}
+	tmpl, err := template.New("replacement").Funcs(templateFuncs).Option("missingkey=error").Parse(replacement)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replacement template: %w", err)
+	}
+	// Repositories hosted by GitHub Enterprise Server are found on the host
+	// of its API.
+	host := "github.com"
+	if base := client.BaseURL; base != nil && base.Host != "api.github.com" {
+		host = base.Hostname()
+	}
+	return &GitHubCleanupPrinter{
+		client:      client,
+		host:        host,
+		replacement: tmpl,
+		canaries:    canaries,
+		repos:       make(map[string]map[string][]cleanupPart),
+	}, nil
+}
+
+// Canaries are the canary tokens planted in place of the secrets of
+// detectors, by detector and then by part of the secret, "id" or "secret".
+type Canaries map[detectorspb.DetectorType]map[string]string
+
+// LoadCanaries reads a YAML file of canary tokens to plant in place of the
+// secrets of detectors, keyed by detector name and then by part:
+//
+//	aws:
+//	  id: AKIA...
+//	  secret: ...
+//	github:
+//	  secret: ghp_...
+func LoadCanaries(path string) (Canaries, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file map[string]map[string]string
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid canaries file %s: %w", path, err)
+	}
+	canaries := make(Canaries, len(file))
+	for name, parts := range file {
+		detector, ok := detectorTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid canaries file %s: unknown detector %q", path, name)
+		}
+		for part := range parts {
+			if part != "id" && part != "secret" {
+				return nil, fmt.Errorf("invalid canaries file %s: %s has part %q, expected id or secret", path, name, part)
+			}
+		}
+		canaries[detector] = parts
+	}
+	return canaries, nil
+}
+
+func detectorTypeByName(name string) (detectorspb.DetectorType, bool) {
+	for typeName, value := range detectorspb.DetectorType_value {
+		if strings.EqualFold(typeName, name) {
+			return detectorspb.DetectorType(value), true
+		}
+	}
+	return 0, false
+}
+
+func (p *GitHubCleanupPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	if !r.Verified {
+		return nil
+	}
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	repo, ok := p.repository(loc.Repository)
+	if !ok || loc.File == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	files := p.repos[repo]
+	if files == nil {
+		files = make(map[string][]cleanupPart)
+		p.repos[repo] = files
+	}
+	for _, part := range secretParts(r) {
+		if !containsPart(files[loc.File], part) {
+			files[loc.File] = append(files[loc.File], part)
+		}
+	}
+	return nil
+}
+
+// repository returns the "owner/repo" name of a repository URL of the GitHub
+// host of p.
+func (p *GitHubCleanupPrinter) repository(repoURL string) (string, bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || !strings.EqualFold(u.Hostname(), p.host) {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(name, "/") != 1 {
+		return "", false
+	}
+	return name, true
+}
+
+// secretParts splits the secret of r into the parts found in files.
+// Multi-part secrets often store the ID in Raw and append the secret to form
+// RawV2, and the two don't have to be next to each other in a file.
+func secretParts(r *detectors.ResultWithMetadata) []cleanupPart {
+	raw, rawV2 := strings.TrimSpace(string(r.Raw)), strings.TrimSpace(string(r.RawV2))
+	preview := SecretPreview(r)
+	if rest, ok := strings.CutPrefix(rawV2, raw); ok && raw != "" && strings.TrimSpace(rest) != "" {
+		return []cleanupPart{
+			{detector: r.DetectorType, part: "id", value: raw, preview: preview},
+			{detector: r.DetectorType, part: "secret", value: strings.TrimSpace(rest), preview: preview},
+		}
+	}
+	return []cleanupPart{{detector: r.DetectorType, part: "secret", value: raw, preview: preview}}
+}
+
+func containsPart(parts []cleanupPart, part cleanupPart) bool {
+	for _, p := range parts {
+		if p.value == part.value {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush opens the cleanup pull requests of every result printed so far.
+func (p *GitHubCleanupPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	repos := make([]string, 0, len(p.repos))
+	for repo := range p.repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var errs []error
+	for _, repo := range repos {
+		if err := p.cleanup(ctx, repo, p.repos[repo]); err != nil {
+			errs = append(errs, fmt.Errorf("could not open cleanup pull request on %s: %w", repo, err))
+		}
+	}
+	p.repos = make(map[string]map[string][]cleanupPart)
+	return errors.Join(errs...)
+}
+
+// cleanupFile is a file of a repository whose secrets were replaced.
+type cleanupFile struct {
+	path    string
+	sha     string
+	content string
+	parts   []cleanupPart
+}
+
+func (p *GitHubCleanupPrinter) cleanup(ctx context.Context, fullName string, files map[string][]cleanupPart) error {
+	owner, name, _ := strings.Cut(fullName, "/")
+	logger := ctx.Logger().WithValues("repository", fullName)
+
+	repo, _, err := p.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	if !repo.GetPrivate() {
+		logger.Info("not opening a cleanup pull request on a public repository")
+		return nil
+	}
+	base := repo.GetDefaultBranch()
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// The branch is named after the secrets it removes, so later scans
+	// finding the same secrets don't open the pull request again.
+	hash := sha256.New()
+	for _, path := range paths {
+		for _, part := range files[path] {
+			fmt.Fprintf(hash, "%s\x00%s\x00", path, part.value)
+		}
+	}
+	branch := cleanupBranchPrefix + hex.EncodeToString(hash.Sum(nil))[:12]
+	open, _, err := p.client.PullRequests.List(ctx, owner, name, &github.PullRequestListOptions{Head: owner + ":" + branch, State: "open"})
+	if err != nil {
+		return err
+	}
+	if len(open) > 0 {
+		logger.V(2).Info("cleanup pull request is already open", "url", open[0].GetHTMLURL())
+		return nil
+	}
+
+	var changed []cleanupFile
+	for _, path := range paths {
+		file, err := p.replaceSecrets(ctx, owner, name, base, path, files[path])
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			changed = append(changed, *file)
+		}
+	}
+	if len(changed) == 0 {
+		logger.V(2).Info("secrets are no longer in the default branch", "branch", base)
+		return nil
+	}
+
+	head, _, err := p.client.Git.GetRef(ctx, owner, name, "refs/heads/"+base)
+	if err != nil {
+		return err
+	}
+	ref := &github.Reference{Ref: github.String("refs/heads/" + branch), Object: &github.GitObject{SHA: head.GetObject().SHA}}
+	if _, _, err := p.client.Git.CreateRef(ctx, owner, name, ref); err != nil {
+		// The branch is left over from a pull request that was closed
+		// without being merged, so it is reset to the default branch.
+		if _, _, err := p.client.Git.UpdateRef(ctx, owner, name, ref, true); err != nil {
+			return err
+		}
+	}
+	for _, file := range changed {
+		_, _, err := p.client.Repositories.UpdateFile(ctx, owner, name, file.path, &github.RepositoryContentFileOptions{
+			Message: github.String("Remove secrets from " + file.path),
+			Content: []byte(file.content),
+			SHA:     github.String(file.sha),
+			Branch:  github.String(branch),
+		})
+		if err != nil {
+			return fmt.Errorf("could not update %s: %w", file.path, err)
+		}
+	}
+
+	pr, _, err := p.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.String("Remove leaked secrets"),
+		Head:  github.String(branch),
+		Base:  github.String(base),
+		Body:  github.String(cleanupBody(changed)),
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("opened cleanup pull request", "url", pr.GetHTMLURL())
+	return nil
+}
+
+// replaceSecrets returns path of the branch base with the parts replaced, or
+// nil if none of them are in it anymore.
+func (p *GitHubCleanupPrinter) replaceSecrets(ctx context.Context, owner, name, base, path string, parts []cleanupPart) (*cleanupFile, error) {
+	content, _, resp, err := p.client.Repositories.GetContents(ctx, owner, name, path, &github.RepositoryContentGetOptions{Ref: base})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get %s: %w", path, err)
+	}
+	if content == nil {
+		return nil, nil
+	}
+	text, err := content.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+
+	file := &cleanupFile{path: path, sha: content.GetSHA(), content: text}
+	for _, part := range parts {
+		if !strings.Contains(file.content, part.value) {
+			continue
+		}
+		var replacement strings.Builder
+		err := p.replacement.Execute(&replacement, cleanupReplacement{
+			Detector: part.detector.String(),
+			Part:     part.part,
+			Canary:   p.canaries[part.detector][part.part],
+			File:     path,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not render replacement: %w", err)
+		}
+		file.content = strings.ReplaceAll(file.content, part.value, replacement.String())
+		file.parts = append(file.parts, part)
+	}
+	if len(file.parts) == 0 {
+		return nil, nil
+	}
+	return file, nil
+}
+
+// cleanupBody renders the description of a cleanup pull request.
+func cleanupBody(files []cleanupFile) string {
+	var b strings.Builder
+	b.WriteString("TruffleHog found verified secrets in this repository and replaced them in the default branch:\n\n")
+	for _, file := range files {
+		seen := make(map[string]bool)
+		for _, part := range file.parts {
+			if seen[part.preview] {
+				continue
+			}
+			seen[part.preview] = true
+			fmt.Fprintf(&b, "- `%s`: %s secret `%s`\n", file.path, part.detector, strings.ReplaceAll(part.preview, "`", ""))
+		}
+	}
+	b.WriteString("\nMerging this pull request does not make the secrets safe to keep: they are still in the history of the repository. Rotate them, and update whatever read them from these files.\n")
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+func TestGitHubCleanupPrinter(t *testing.T) {
+	var (
+		ref     struct{ Ref, SHA string }
+		updated = make(map[string]github.RepositoryContentFileOptions)
+		created github.NewPullRequest
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/org/repo", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(github.Repository{Private: github.Bool(true), DefaultBranch: github.String("main")})
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/public", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(github.Repository{Private: github.Bool(false), DefaultBranch: github.String("main")})
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/repo/pulls", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]github.PullRequest{})
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/repo/contents/config.yaml", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("ref"))
+		_ = json.NewEncoder(w).Encode(github.RepositoryContent{
+			Type:     github.String("file"),
+			SHA:      github.String("blob1"),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte("key: AKIAEXAMPLESECRET\n"))),
+		})
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/repo/contents/removed.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/repo/git/ref/heads/main", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String("base123")}})
+	})
+	mux.HandleFunc("POST /api/v3/repos/org/repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&ref)
+		_ = json.NewEncoder(w).Encode(github.Reference{Ref: github.String(ref.Ref)})
+	})
+	mux.HandleFunc("PUT /api/v3/repos/org/repo/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		var opts github.RepositoryContentFileOptions
+		_ = json.NewDecoder(r.Body).Decode(&opts)
+		updated[r.PathValue("path")] = opts
+		_ = json.NewEncoder(w).Encode(github.RepositoryContentResponse{})
+	})
+	mux.HandleFunc("POST /api/v3/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		_ = json.NewEncoder(w).Encode(github.PullRequest{HTMLURL: github.String("https://github.com/org/repo/pull/1")})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := newGitHubClient(srv.Client(), "token", srv.URL)
+	require.NoError(t, err)
+	canaries := Canaries{detectorspb.DetectorType_AWS: {"secret": "CANARYSECRET"}}
+	p, err := newGitHubCleanupPrinter(client, "", canaries)
+	require.NoError(t, err)
+	p.host = "github.com"
+
+	ctx := context.Background()
+	aws := gitResult("abc123", "config.yaml", "AKIAEXAMPLE", true)
+	aws.RawV2 = []byte("AKIAEXAMPLESECRET")
+	require.NoError(t, p.Print(ctx, aws))
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "removed.yaml", "AKIAEXAMPLE", true)))
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "unverified.yaml", "AKIAEXAMPLE", false)))
+	public := gitResult("abc123", "config.yaml", "AKIAEXAMPLE", true)
+	public.SourceMetadata.GetGit().Repository = "https://github.com/org/public.git"
+	require.NoError(t, p.Print(ctx, public))
+	require.NoError(t, p.Flush(ctx))
+
+	assert.Regexp(t, `^refs/heads/trufflehog/remove-secrets-[0-9a-f]{12}$`, ref.Ref)
+	assert.Equal(t, "base123", ref.SHA)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "key: TRUFFLEHOG_REMOVED_AWS_IDCANARYSECRET\n", string(updated["config.yaml"].Content))
+	assert.Equal(t, "blob1", *updated["config.yaml"].SHA)
+	assert.Equal(t, "main", created.GetBase())
+	assert.Contains(t, created.GetBody(), "`config.yaml`: AWS secret")
+	assert.NotContains(t, created.GetBody(), "AKIAEXAMPLESECRET")
+}
+
+func TestNewGitHubCleanupPrinter(t *testing.T) {
+	_, err := NewGitHubCleanupPrinter("token", "", "{{.Nope", nil)
+	assert.Error(t, err)
+
+	p, err := NewGitHubCleanupPrinter("token", "https://github.example.com/api/v3", "", nil)
+	require.NoError(t, err)
+	repo, ok := p.repository("https://github.example.com/org/repo.git")
+	assert.True(t, ok)
+	assert.Equal(t, "org/repo", repo)
+	_, ok = p.repository("https://github.com/org/repo.git")
+	assert.False(t, ok)
+}
+
+func TestLoadCanaries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "canaries.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("aws:\n  id: AKIACANARY\n  secret: canary\ngithub:\n  secret: ghp_canary\n"), 0o644))
+	canaries, err := LoadCanaries(path)
+	require.NoError(t, err)
+	assert.Equal(t, Canaries{
+		detectorspb.DetectorType_AWS:    {"id": "AKIACANARY", "secret": "canary"},
+		detectorspb.DetectorType_Github: {"secret": "ghp_canary"},
+	}, canaries)
+
+	require.NoError(t, os.WriteFile(path, []byte("nosuchdetector:\n  secret: x\n"), 0o644))
+	_, err = LoadCanaries(path)
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("aws:\n  token: x\n"), 0o644))
+	_, err = LoadCanaries(path)
+	assert.Error(t, err)
+}
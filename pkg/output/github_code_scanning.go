@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// GitHubCodeScanningPrinter is a printer that uploads the verified results to
+// GitHub code scanning once the scan has finished, so they are tracked as
+// alerts of the repository. GitHub's secret scanning API doesn't accept alerts
+// found by other tools, so the results are uploaded as a SARIF analysis.
+//
+// The analysis is uploaded even if nothing was found, which closes the alerts
+// of secrets that have since been removed. Uploading requires a token with the
+// security_events scope, or an Actions token with security-events: write.
+type GitHubCodeScanningPrinter struct {
+	client      *github.Client
+	owner, repo string
+	commit, ref string
+
+	buf   bytes.Buffer
+	sarif *SARIFPrinter
+}
+
+// NewGitHubCodeScanningPrinter creates a GitHubCodeScanningPrinter for the
+// repository "owner/repo". The results are attributed to commit, which must be
+// the full SHA of a commit of ref, such as "refs/heads/main". The endpoint is
+// the GitHub API URL, and may be empty to use github.com.
+func NewGitHubCodeScanningPrinter(repository, commit, ref, token, endpoint string) (*GitHubCodeScanningPrinter, error) {
+	owner, repo, ok := strings.Cut(strings.Trim(repository, "/"), "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid repository %q: expected owner/repo", repository)
+	}
+	if commit == "" || ref == "" {
+		return nil, fmt.Errorf("the commit and ref of the analysis are required")
+	}
+	client, err := newGitHubClient(common.RetryableHTTPClient(), token, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return newGitHubCodeScanningPrinter(client, owner, repo, commit, ref), nil
+}
+
+func newGitHubCodeScanningPrinter(client *github.Client, owner, repo, commit, ref string) *GitHubCodeScanningPrinter {
+	p := &GitHubCodeScanningPrinter{client: client, owner: owner, repo: repo, commit: commit, ref: ref}
+	p.sarif = NewSARIFPrinter(&p.buf)
+	return p
+}
+
+func (p *GitHubCodeScanningPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	if !r.Verified {
+		return nil
+	}
+	return p.sarif.Print(ctx, r)
+}
+
+// Flush uploads the analysis of every verified result printed so far.
+func (p *GitHubCodeScanningPrinter) Flush(ctx context.Context) error {
+	p.buf.Reset()
+	if err := p.sarif.Flush(ctx); err != nil {
+		return err
+	}
+
+	// The API takes the SARIF document gzipped and base64 encoded.
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(p.buf.Bytes()); err != nil {
+		return fmt.Errorf("could not compress SARIF analysis: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("could not compress SARIF analysis: %w", err)
+	}
+
+	id, _, err := p.client.CodeScanning.UploadSarif(ctx, p.owner, p.repo, &github.SarifAnalysis{
+		CommitSHA: github.String(p.commit),
+		Ref:       github.String(p.ref),
+		Sarif:     github.String(base64.StdEncoding.EncodeToString(compressed.Bytes())),
+		ToolName:  github.String(toolName),
+	})
+	if err != nil {
+		return fmt.Errorf("could not upload code scanning analysis to %s/%s: %w", p.owner, p.repo, err)
+	}
+	ctx.Logger().V(1).Info("uploaded code scanning analysis", "repository", p.owner+"/"+p.repo, "id", id.GetID(), "url", id.GetURL())
+	return nil
+}
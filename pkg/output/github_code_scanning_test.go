@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestGitHubCodeScanningPrinter(t *testing.T) {
+	var analyses []github.SarifAnalysis
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/org/repo/code-scanning/sarifs", func(w http.ResponseWriter, r *http.Request) {
+		var analysis github.SarifAnalysis
+		_ = json.NewDecoder(r.Body).Decode(&analysis)
+		analyses = append(analyses, analysis)
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(github.SarifID{ID: github.String("47177e22")})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := newGitHubClient(srv.Client(), "token", srv.URL)
+	require.NoError(t, err)
+	p := newGitHubCodeScanningPrinter(client, "org", "repo", "abc123", "refs/heads/main")
+
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "other.yaml", "AKIAEXAMPLEOTHER", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	require.Len(t, analyses, 1)
+	assert.Equal(t, "abc123", analyses[0].GetCommitSHA())
+	assert.Equal(t, "refs/heads/main", analyses[0].GetRef())
+	assert.Equal(t, "TruffleHog", analyses[0].GetToolName())
+
+	compressed, err := base64.StdEncoding.DecodeString(analyses[0].GetSarif())
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	var doc sarifLog
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Runs, 1)
+	require.Len(t, doc.Runs[0].Results, 1, "only verified results should be uploaded")
+	assert.Equal(t, "config.yaml", doc.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.NotContains(t, string(data), "AKIAEXAMPLESECRET")
+}
+
+func TestNewGitHubCodeScanningPrinter(t *testing.T) {
+	_, err := NewGitHubCodeScanningPrinter("org/repo", "abc123", "refs/heads/main", "token", "")
+	assert.NoError(t, err)
+
+	_, err = NewGitHubCodeScanningPrinter("org", "abc123", "refs/heads/main", "token", "")
+	assert.Error(t, err)
+	_, err = NewGitHubCodeScanningPrinter("org/repo", "", "refs/heads/main", "token", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+const (
+	githubCloudEndpoint = "https://api.github.com"
+	githubCheckRunName  = "TruffleHog"
+
+	// githubMaxAnnotations is the number of annotations GitHub accepts in a
+	// single check run request.
+	githubMaxAnnotations = 50
+)
+
+// GitHubPRPrinter is a printer that reports results on a GitHub pull request.
+// Once the scan has finished, it posts a review with an inline comment on
+// every changed line that introduces a secret and creates a check run on the
+// head commit that fails when anything was found.
+//
+// Creating check runs requires a GitHub App installation token; with any
+// other token the review is still posted, and the check run error is
+// reported.
+type GitHubPRPrinter struct {
+	client *github.Client
+	pr     PullRequestRef
+	prFindings
+}
+
+// NewGitHubPRPrinter creates a GitHubPRPrinter for pr. The endpoint is the
+// GitHub API URL, and may be empty to use github.com.
+func NewGitHubPRPrinter(pr PullRequestRef, token, endpoint string) (*GitHubPRPrinter, error) {
+	client, err := newGitHubClient(common.RetryableHTTPClient(), token, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubPRPrinter{client: client, pr: pr}, nil
+}
+
+func newGitHubClient(httpClient *http.Client, token, endpoint string) (*github.Client, error) {
+	client := github.NewClient(httpClient).WithAuthToken(token)
+	if endpoint == "" || strings.EqualFold(strings.TrimSuffix(endpoint, "/"), githubCloudEndpoint) {
+		return client, nil
+	}
+	return client.WithEnterpriseURLs(endpoint, endpoint)
+}
+
+func (p *GitHubPRPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush posts the review and check run for every result printed so far.
+func (p *GitHubPRPrinter) Flush(ctx context.Context) error {
+	owner, repo := p.pr.Owner(), p.pr.Name()
+
+	pr, _, err := p.client.PullRequests.Get(ctx, owner, repo, p.pr.Number)
+	if err != nil {
+		return fmt.Errorf("could not get pull request %s: %w", p.pr, err)
+	}
+	headSHA := pr.GetHead().GetSHA()
+
+	added, err := p.addedLines(ctx)
+	if err != nil {
+		return err
+	}
+	findings := p.take()
+	inDiff, outside := partitionByDiff(findings, added)
+
+	var errs []error
+	if len(findings) > 0 {
+		if err := p.postReview(ctx, headSHA, inDiff, outside); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := createCheckRun(ctx, p.client, p.pr.Owner(), p.pr.Name(), headSHA, findings); err != nil {
+		errs = append(errs, fmt.Errorf("could not create check run on %s: %w", p.pr, err))
+	}
+	return errors.Join(errs...)
+}
+
+// addedLines returns the lines added by the pull request, keyed by file.
+func (p *GitHubPRPrinter) addedLines(ctx context.Context) (map[string]map[int]bool, error) {
+	added := make(map[string]map[int]bool)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		files, resp, err := p.client.PullRequests.ListFiles(ctx, p.pr.Owner(), p.pr.Name(), p.pr.Number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("could not list files of pull request %s: %w", p.pr, err)
+		}
+		for _, f := range files {
+			added[f.GetFilename()] = addedLines(f.GetPatch())
+		}
+		if resp.NextPage == 0 {
+			return added, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (p *GitHubPRPrinter) postReview(ctx context.Context, headSHA string, inDiff, outside []prFinding) error {
+	comments := make([]*github.DraftReviewComment, 0, len(inDiff))
+	for _, f := range inDiff {
+		comments = append(comments, &github.DraftReviewComment{
+			Path: github.String(f.Path),
+			Line: github.Int(f.Line),
+			Side: github.String("RIGHT"),
+			Body: github.String(f.commentBody()),
+		})
+	}
+
+	_, _, err := p.client.PullRequests.CreateReview(ctx, p.pr.Owner(), p.pr.Name(), p.pr.Number, &github.PullRequestReviewRequest{
+		CommitID: github.String(headSHA),
+		Body:     github.String(reviewSummary(inDiff, outside)),
+		Event:    github.String("COMMENT"),
+		Comments: comments,
+	})
+	if err != nil {
+		return fmt.Errorf("could not post review on pull request %s: %w", p.pr, err)
+	}
+	return nil
+}
+
+// createCheckRun creates a check run on the commit headSHA of owner/repo that
+// fails when anything was found, annotating the lines of the findings.
+func createCheckRun(ctx context.Context, client *github.Client, owner, repo, headSHA string, findings []prFinding) error {
+	conclusion := "success"
+	title := "No secrets found"
+	if len(findings) > 0 {
+		conclusion = "failure"
+		title = fmt.Sprintf("%d potential secret(s) found", len(findings))
+	}
+
+	annotations := make([]*github.CheckRunAnnotation, 0, min(len(findings), githubMaxAnnotations))
+	for _, f := range findings[:min(len(findings), githubMaxAnnotations)] {
+		level := "warning"
+		if f.Verified {
+			level = "failure"
+		}
+		annotations = append(annotations, &github.CheckRunAnnotation{
+			Path:            github.String(f.Path),
+			StartLine:       github.Int(f.Line),
+			EndLine:         github.Int(f.Line),
+			AnnotationLevel: github.String(level),
+			Title:           github.String(f.Detector),
+			Message:         github.String(fmt.Sprintf("Found %s %s secret: %s", f.Status, f.Detector, f.Preview)),
+		})
+	}
+	summary := title
+	if len(findings) > githubMaxAnnotations {
+		summary += fmt.Sprintf(". Only the first %d are annotated.", githubMaxAnnotations)
+	}
+
+	_, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:       githubCheckRunName,
+		HeadSHA:    headSHA,
+		Status:     github.String("completed"),
+		Conclusion: github.String(conclusion),
+		Output: &github.CheckRunOutput{
+			Title:       github.String(title),
+			Summary:     github.String(summary),
+			Annotations: annotations,
+		},
+	})
+	return err
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestGitHubPRPrinter(t *testing.T) {
+	var review github.PullRequestReviewRequest
+	var checkRun github.CreateCheckRunOptions
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/org/repo/pulls/7", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(github.PullRequest{Head: &github.PullRequestBranch{SHA: github.String("abc123")}})
+	})
+	mux.HandleFunc("GET /api/v3/repos/org/repo/pulls/7/files", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]github.CommitFile{{
+			Filename: github.String("config.yaml"),
+			Patch:    github.String("@@ -0,0 +1,1 @@\n+key: AKIAEXAMPLE"),
+		}})
+	})
+	mux.HandleFunc("POST /api/v3/repos/org/repo/pulls/7/reviews", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		_ = json.NewEncoder(w).Encode(github.PullRequestReview{})
+	})
+	mux.HandleFunc("POST /api/v3/repos/org/repo/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&checkRun)
+		_ = json.NewEncoder(w).Encode(github.CheckRun{})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := newGitHubClient(srv.Client(), "token", srv.URL)
+	require.NoError(t, err)
+	p := &GitHubPRPrinter{client: client, pr: PullRequestRef{Repo: "org/repo", Number: 7}}
+
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Print(ctx, gitResult("abc123", "other.yaml", "AKIAEXAMPLESECRET", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	require.Len(t, review.Comments, 1)
+	assert.Equal(t, "config.yaml", review.Comments[0].GetPath())
+	assert.Equal(t, 1, review.Comments[0].GetLine())
+	assert.NotContains(t, review.Comments[0].GetBody(), "AKIAEXAMPLESECRET")
+	assert.Contains(t, review.GetBody(), "other.yaml:1")
+
+	assert.Equal(t, "abc123", checkRun.HeadSHA)
+	assert.Equal(t, "failure", checkRun.GetConclusion())
+	assert.Len(t, checkRun.Output.Annotations, 2)
+}
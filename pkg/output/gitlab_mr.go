@@ -0,0 +1,154 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// GitLabMRPrinter is a printer that reports results on a GitLab merge
+// request. Once the scan has finished, it opens a discussion on every changed
+// line that introduces a secret and resolves the discussions of earlier runs
+// whose secret is no longer present.
+type GitLabMRPrinter struct {
+	client *gitlab.Client
+	mr     PullRequestRef
+	prFindings
+}
+
+// NewGitLabMRPrinter creates a GitLabMRPrinter for mr. The endpoint is the
+// GitLab URL, and may be empty to use gitlab.com.
+func NewGitLabMRPrinter(mr PullRequestRef, token, endpoint string) (*GitLabMRPrinter, error) {
+	var opts []gitlab.ClientOptionFunc
+	if endpoint != "" {
+		opts = append(opts, gitlab.WithBaseURL(endpoint))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create GitLab client: %w", err)
+	}
+	return &GitLabMRPrinter{client: client, mr: mr}, nil
+}
+
+func (p *GitLabMRPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	return p.add(r)
+}
+
+// Flush opens and resolves discussions for every result printed so far.
+func (p *GitLabMRPrinter) Flush(ctx context.Context) error {
+	pid, iid := p.mr.Repo, p.mr.Number
+
+	mr, _, err := p.client.MergeRequests.GetMergeRequest(pid, iid, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not get merge request %s: %w", p.mr, err)
+	}
+	added, err := p.addedLines(ctx)
+	if err != nil {
+		return err
+	}
+	posted, err := p.postedDiscussions(ctx)
+	if err != nil {
+		return err
+	}
+
+	inDiff, outside := partitionByDiff(p.take(), added)
+	toPost, stale := reconcileComments(inDiff, posted)
+
+	var errs []error
+	for _, f := range toPost {
+		_, _, err := p.client.Discussions.CreateMergeRequestDiscussion(pid, iid, &gitlab.CreateMergeRequestDiscussionOptions{
+			Body: gitlab.Ptr(f.commentBody()),
+			Position: &gitlab.PositionOptions{
+				BaseSHA:      gitlab.Ptr(mr.DiffRefs.BaseSha),
+				HeadSHA:      gitlab.Ptr(mr.DiffRefs.HeadSha),
+				StartSHA:     gitlab.Ptr(mr.DiffRefs.StartSha),
+				PositionType: gitlab.Ptr("text"),
+				NewPath:      gitlab.Ptr(f.Path),
+				NewLine:      gitlab.Ptr(f.Line),
+			},
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not comment on %s:%d of merge request %s: %w", f.Path, f.Line, p.mr, err))
+		}
+	}
+	for _, c := range stale {
+		if err := p.resolve(ctx, c.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(toPost) > 0 || len(outside) > 0 {
+		_, _, err := p.client.Notes.CreateMergeRequestNote(pid, iid, &gitlab.CreateMergeRequestNoteOptions{
+			Body: gitlab.Ptr(reviewSummary(inDiff, outside)),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not comment on merge request %s: %w", p.mr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// addedLines returns the lines added by the merge request, keyed by file.
+func (p *GitLabMRPrinter) addedLines(ctx context.Context) (map[string]map[int]bool, error) {
+	added := make(map[string]map[int]bool)
+	opts := &gitlab.ListMergeRequestDiffsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		diffs, resp, err := p.client.MergeRequests.ListMergeRequestDiffs(p.mr.Repo, p.mr.Number, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("could not list changes of merge request %s: %w", p.mr, err)
+		}
+		for _, d := range diffs {
+			if !d.DeletedFile {
+				added[d.NewPath] = addedLines(d.Diff)
+			}
+		}
+		if resp.NextPage == 0 {
+			return added, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// postedDiscussions returns the unresolved discussions opened by earlier runs.
+func (p *GitLabMRPrinter) postedDiscussions(ctx context.Context) ([]postedComment, error) {
+	var posted []postedComment
+	opts := &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100}
+	for {
+		discussions, resp, err := p.client.Discussions.ListMergeRequestDiscussions(p.mr.Repo, p.mr.Number, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("could not list discussions of merge request %s: %w", p.mr, err)
+		}
+		for _, d := range discussions {
+			if len(d.Notes) == 0 || d.Notes[0].Resolved {
+				continue
+			}
+			if marker, ok := parseCommentMarker(d.Notes[0].Body); ok {
+				posted = append(posted, postedComment{ID: d.ID, Marker: marker})
+			}
+		}
+		if resp.NextPage == 0 {
+			return posted, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (p *GitLabMRPrinter) resolve(ctx context.Context, discussionID string) error {
+	pid, iid := p.mr.Repo, p.mr.Number
+	_, _, err := p.client.Discussions.AddMergeRequestDiscussionNote(pid, iid, discussionID, &gitlab.AddMergeRequestDiscussionNoteOptions{
+		Body: gitlab.Ptr(resolvedCommentBody),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not reply to discussion %s on merge request %s: %w", discussionID, p.mr, err)
+	}
+	_, _, err = p.client.Discussions.ResolveMergeRequestDiscussion(pid, iid, discussionID, &gitlab.ResolveMergeRequestDiscussionOptions{
+		Resolved: gitlab.Ptr(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not resolve discussion %s on merge request %s: %w", discussionID, p.mr, err)
+	}
+	return nil
+}
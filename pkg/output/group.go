@@ -0,0 +1,160 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Ways results can be grouped in reports and deliveries.
+const (
+	GroupByRepository = "repository"
+	GroupByOwner      = "owner"
+)
+
+// Groups of results that have no repository or owner.
+const (
+	unknownRepository = "unknown"
+	unownedGroup      = "unowned"
+)
+
+// ResultGroups returns the groups r belongs to when results are grouped by.
+// A result belongs to one repository, but to every owner it is attributed to.
+func ResultGroups(r *detectors.ResultWithMetadata, by string) []string {
+	switch by {
+	case GroupByRepository:
+		loc, _ := extractLocation(r.SourceMetadata)
+		repo := loc.Repository
+		if repo == "" {
+			repo = r.SourceName
+		}
+		if repo == "" {
+			repo = unknownRepository
+		}
+		return []string{repo}
+	case GroupByOwner:
+		if len(r.Owners) == 0 {
+			return []string{unownedGroup}
+		}
+		return r.Owners
+	default:
+		return nil
+	}
+}
+
+// GroupedPrinter is a printer that hands results to a printer of their group,
+// such as to write a report per repository. The printer of a group is created
+// with the first of its results.
+type GroupedPrinter struct {
+	by         string
+	newPrinter func(group string) (resultPrinter, error)
+
+	mu       sync.Mutex
+	printers map[string]resultPrinter
+}
+
+// NewGroupedPrinter creates a GroupedPrinter that groups results by, creating
+// the printer of each group with newPrinter.
+func NewGroupedPrinter[P resultPrinter](by string, newPrinter func(group string) (P, error)) *GroupedPrinter {
+	return &GroupedPrinter{
+		by: by,
+		newPrinter: func(group string) (resultPrinter, error) {
+			return newPrinter(group)
+		},
+		printers: make(map[string]resultPrinter),
+	}
+}
+
+func (p *GroupedPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	var errs []error
+	for _, group := range ResultGroups(r, p.by) {
+		printer, err := p.printer(group)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := printer.Print(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *GroupedPrinter) printer(group string) (resultPrinter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if printer, ok := p.printers[group]; ok {
+		return printer, nil
+	}
+	printer, err := p.newPrinter(group)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the printer of group %s: %w", group, err)
+	}
+	p.printers[group] = printer
+	return printer, nil
+}
+
+// Flush flushes the printer of every group that buffers its output, in the
+// order of their names.
+func (p *GroupedPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	groups := make([]string, 0, len(p.printers))
+	for group := range p.printers {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var errs []error
+	for _, group := range groups {
+		if f, ok := p.printers[group].(interface{ Flush(context.Context) error }); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GroupFileName returns the name of the file of group next to path, with the
+// group between its name and extension, such as report.github.com-org-repo.html
+// for the repository https://github.com/org/repo.git of report.html.
+func GroupFileName(path, group string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + groupSlug(group) + ext
+}
+
+// groupSlug turns a group name into a file name, without the scheme and
+// suffix of repository URLs.
+func groupSlug(group string) string {
+	_, rest, ok := strings.Cut(group, "://")
+	if ok {
+		group = rest
+	}
+	group = strings.TrimSuffix(group, ".git")
+
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(group) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c == '.' && b.Len() > 0:
+			b.WriteRune(c)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimRight(b.String(), "-.")
+	if slug == "" {
+		return "group"
+	}
+	return slug
+}
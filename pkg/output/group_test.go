@@ -0,0 +1,54 @@
+package output
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestResultGroups(t *testing.T) {
+	r := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	assert.Equal(t, []string{"https://github.com/org/repo.git"}, ResultGroups(r, GroupByRepository))
+	assert.Equal(t, []string{"unowned"}, ResultGroups(r, GroupByOwner))
+	r.Owners = []string{"payments", "platform"}
+	assert.Equal(t, []string{"payments", "platform"}, ResultGroups(r, GroupByOwner))
+	assert.Nil(t, ResultGroups(r, ""))
+
+	assert.Equal(t, []string{"filesystem"}, ResultGroups(&detectors.ResultWithMetadata{SourceName: "filesystem"}, GroupByRepository))
+	assert.Equal(t, []string{"unknown"}, ResultGroups(&detectors.ResultWithMetadata{}, GroupByRepository))
+}
+
+func TestGroupFileName(t *testing.T) {
+	assert.Equal(t, "out/report.github.com-org-repo.html", GroupFileName("out/report.html", "https://github.com/org/repo.git"))
+	assert.Equal(t, "report.org-payments.html", GroupFileName("report.html", "@org/payments"))
+	assert.Equal(t, "report.dev-example.com", GroupFileName("report", "dev@example.com"))
+	assert.Equal(t, "report.group.html", GroupFileName("report.html", "../"))
+}
+
+func TestGroupedPrinter(t *testing.T) {
+	ctx := context.Background()
+	rcv := new(webhookReceiver)
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	p := NewGroupedPrinter(GroupByOwner, func(group string) (*WebhookPrinter, error) {
+		return NewWebhookPrinter([]string{srv.URL},
+			WithWebhookBatchSize(10),
+			WithWebhookGroup(group),
+			WithWebhookClient(testWebhookClient()),
+		), nil
+	})
+	owned := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	owned.Owners = []string{"payments", "platform"}
+	require.NoError(t, p.Print(ctx, owned))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLE", false)))
+	assert.Empty(t, rcv.bodies)
+
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, []string{"payments", "platform", "unowned"}, rcv.groups)
+}
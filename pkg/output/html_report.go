@@ -0,0 +1,247 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/credfile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/giturl"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reachability"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+//go:embed html_report.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// Severities used to group results in reports. A verified secret is a live
+// credential, while a verification error means its status could not be
+// determined.
+const (
+	severityHigh   = "high"
+	severityMedium = "medium"
+	severityLow    = "low"
+)
+
+// longExposureDays is how long a secret has to be exposed for to be a
+// severity higher.
+const longExposureDays = 90
+
+// resultSeverity classifies a result by its verification status, rating
+// secrets that can't be verified as those whose verification failed. Results
+// found in classified files, such as tests and lockfiles, are less likely to
+// be real secrets, so they are a severity lower, while secrets assigned to
+// variables named like secrets, such as DB_PASSWORD, are more likely to be,
+// so they are a severity higher. Secrets in public repositories and buckets,
+// or exposed for longer than longExposureDays, are more likely to have been
+// found by someone else, so they are a severity higher.
+func resultSeverity(r *detectors.ResultWithMetadata) string {
+	level := 0
+	switch {
+	case r.Verified:
+		level = 2
+	case r.VerificationError() != nil, r.Unverifiable:
+		level = 1
+	}
+	if len(r.Categories) > 0 {
+		level--
+	}
+	if r.Variable != "" && credfile.SensitiveName(r.Variable) {
+		level++
+	}
+	exposed := r.Reachability == reachability.Public
+	if e := r.Exposure; e != nil {
+		exposed = exposed || (e.Public != nil && *e.Public) || e.Days > longExposureDays
+	}
+	if exposed {
+		level++
+	}
+	switch {
+	case level >= 2:
+		return severityHigh
+	case level == 1:
+		return severityMedium
+	default:
+		return severityLow
+	}
+}
+
+// HTMLReportPrinter is a printer that collects results and renders them as a
+// standalone HTML report once the scan has finished. Secrets are never
+// written to the report; only redacted previews are included.
+type HTMLReportPrinter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	findings []htmlFinding
+}
+
+// NewHTMLReportPrinter creates an HTMLReportPrinter that writes to out.
+func NewHTMLReportPrinter(out io.Writer) *HTMLReportPrinter {
+	return &HTMLReportPrinter{out: out}
+}
+
+func (p *HTMLReportPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	finding := htmlFinding{
+		Detector: r.DetectorType.String(),
+		Decoder:  r.DecoderType.String(),
+		Severity: resultSeverity(r),
+		Repo:     loc.Repository,
+		Commit:   loc.Commit,
+		File:     loc.File,
+		Line:     loc.Line,
+		Preview:  SecretPreview(r),
+	}
+	if finding.Repo == "" {
+		finding.Repo = r.SourceName
+	}
+	finding.Link = reportLink(loc)
+
+	p.mu.Lock()
+	p.findings = append(p.findings, finding)
+	p.mu.Unlock()
+	return nil
+}
+
+// Flush renders the report containing every result printed so far.
+func (p *HTMLReportPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data := htmlReportData{
+		Version:     version.BuildVersion,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Total:       len(p.findings),
+		Findings:    p.findings,
+	}
+
+	detectorCounts := make(map[string]*htmlCount)
+	repoCounts := make(map[string]*htmlCount)
+	for _, f := range p.findings {
+		data.Totals.add(f.Severity)
+		if _, ok := detectorCounts[f.Detector]; !ok {
+			detectorCounts[f.Detector] = &htmlCount{Name: f.Detector}
+		}
+		detectorCounts[f.Detector].add(f.Severity)
+		if _, ok := repoCounts[f.Repo]; !ok {
+			repoCounts[f.Repo] = &htmlCount{Name: f.Repo}
+		}
+		repoCounts[f.Repo].add(f.Severity)
+	}
+	data.ByDetector = sortedCounts(detectorCounts)
+	data.ByRepo = sortedCounts(repoCounts)
+
+	sort.SliceStable(data.Findings, func(i, j int) bool {
+		a, b := data.Findings[i], data.Findings[j]
+		if a.Severity != b.Severity {
+			return severityRank(a.Severity) < severityRank(b.Severity)
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Detector < b.Detector
+	})
+
+	if err := htmlReport.Execute(p.out, data); err != nil {
+		return fmt.Errorf("could not render HTML report: %w", err)
+	}
+	return nil
+}
+
+// SecretPreview returns a display-safe preview of the secret in a result.
+func SecretPreview(r *detectors.ResultWithMetadata) string {
+	if r.Redacted != "" {
+		return r.Redacted
+	}
+	return MaskSecret(strings.TrimSpace(string(r.Raw)))
+}
+
+// reportLink returns the link to a result's location, generating one from the
+// repository and commit when the source didn't provide it.
+func reportLink(loc resultLocation) string {
+	if loc.Link != "" {
+		return loc.Link
+	}
+	if loc.Commit == "" || !strings.HasPrefix(loc.Repository, "https://") || !strings.HasSuffix(loc.Repository, ".git") {
+		return ""
+	}
+	return giturl.GenerateLink(loc.Repository, loc.Commit, loc.File, loc.Line)
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case severityHigh:
+		return 0
+	case severityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func sortedCounts(m map[string]*htmlCount) []htmlCount {
+	counts := make([]htmlCount, 0, len(m))
+	for _, c := range m {
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Total != counts[j].Total {
+			return counts[i].Total > counts[j].Total
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+type htmlReportData struct {
+	Version     string
+	GeneratedAt string
+	Total       int
+	Totals      htmlCount
+	ByDetector  []htmlCount
+	ByRepo      []htmlCount
+	Findings    []htmlFinding
+}
+
+type htmlCount struct {
+	Name              string
+	High, Medium, Low int
+	Total             int
+}
+
+func (c *htmlCount) add(severity string) {
+	c.Total++
+	switch severity {
+	case severityHigh:
+		c.High++
+	case severityMedium:
+		c.Medium++
+	default:
+		c.Low++
+	}
+}
+
+type htmlFinding struct {
+	Detector string
+	Decoder  string
+	Severity string
+	Repo     string
+	Commit   string
+	File     string
+	Line     int64
+	Link     string
+	Preview  string
+}
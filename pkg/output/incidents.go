@@ -0,0 +1,207 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// Incident is every finding of the same leaked secret, wherever it was found.
+type Incident struct {
+	// ID identifies the incident by its detector and secret, so it is the same
+	// across scans.
+	ID       string
+	Detector string
+	// Secret is the raw secret of the first finding, masked unless the
+	// printer shows secrets.
+	Secret string
+	// Secrets is how many different secrets the incident has, which is more
+	// than one only when secrets are clustered by prefix.
+	Secrets   int
+	Verified  bool
+	Locations []IncidentLocation
+}
+
+// IncidentLocation is one finding of an incident.
+type IncidentLocation struct {
+	SourceName  string
+	SourceType  string
+	Repository  string `json:",omitempty"`
+	Commit      string `json:",omitempty"`
+	File        string `json:",omitempty"`
+	Line        int64  `json:",omitempty"`
+	Link        string `json:",omitempty"`
+	Verified    bool
+	Fingerprint string
+}
+
+// IncidentPrinter is a printer that clusters findings of the same secret
+// across files, repositories, and sources into incidents, and prints them
+// once the scan has finished, in the order they were first seen. It masks
+// secrets itself, so it needs the raw results.
+type IncidentPrinter struct {
+	mu           sync.Mutex
+	out          io.Writer
+	json         bool
+	showSecrets  bool
+	prefixLength int
+	order        []string
+	incidents    map[string]*Incident
+	secrets      map[string]map[string]struct{}
+}
+
+// IncidentOption configures an IncidentPrinter.
+type IncidentOption func(*IncidentPrinter)
+
+// WithIncidentJSON prints every incident as a line of JSON instead of text.
+func WithIncidentJSON() IncidentOption {
+	return func(p *IncidentPrinter) { p.json = true }
+}
+
+// WithIncidentSecrets prints the secrets of incidents instead of masking them.
+func WithIncidentSecrets() IncidentOption {
+	return func(p *IncidentPrinter) { p.showSecrets = true }
+}
+
+// WithIncidentPrefixLength clusters secrets of the same detector that share
+// their first n characters into one incident, such as the secrets a key was
+// rotated to under the same ID. Secrets up to n characters long are only
+// clustered with themselves.
+func WithIncidentPrefixLength(n int) IncidentOption {
+	return func(p *IncidentPrinter) { p.prefixLength = n }
+}
+
+// NewIncidentPrinter creates an IncidentPrinter that prints incidents to out.
+func NewIncidentPrinter(out io.Writer, opts ...IncidentOption) *IncidentPrinter {
+	p := &IncidentPrinter{
+		out:       out,
+		incidents: make(map[string]*Incident),
+		secrets:   make(map[string]map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// incidentID derives the ID of the incident of r from its detector and its
+// secret, or the prefix of its secret.
+func (p *IncidentPrinter) incidentID(r *detectors.ResultWithMetadata) (id, secret string) {
+	secret = strings.TrimSpace(string(r.RawV2))
+	if secret == "" {
+		secret = strings.TrimSpace(string(r.Raw))
+	}
+	key := secret
+	if p.prefixLength > 0 && len(key) > p.prefixLength {
+		key = key[:p.prefixLength]
+	}
+	h := sha256.New()
+	for _, part := range []string{r.DetectorType.String(), r.DetectorName, key} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], secret
+}
+
+func (p *IncidentPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(r)
+	}
+	id, secret := p.incidentID(r)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	incident, ok := p.incidents[id]
+	if !ok {
+		incident = &Incident{ID: id, Detector: r.DetectorType.String(), Secret: strings.TrimSpace(string(r.Raw))}
+		if r.DetectorName != "" {
+			incident.Detector = r.DetectorName
+		}
+		if !p.showSecrets {
+			incident.Secret = string(redactBytes(r.Raw))
+		}
+		p.incidents[id] = incident
+		p.secrets[id] = make(map[string]struct{})
+		p.order = append(p.order, id)
+	}
+	if _, ok := p.secrets[id][secret]; !ok {
+		p.secrets[id][secret] = struct{}{}
+		incident.Secrets++
+	}
+	incident.Verified = incident.Verified || r.Verified
+	incident.Locations = append(incident.Locations, IncidentLocation{
+		SourceName:  r.SourceName,
+		SourceType:  r.SourceType.String(),
+		Repository:  loc.Repository,
+		Commit:      loc.Commit,
+		File:        loc.File,
+		Line:        loc.Line,
+		Link:        reportLink(loc),
+		Verified:    r.Verified,
+		Fingerprint: fingerprint,
+	})
+	return nil
+}
+
+// Flush prints every incident.
+func (p *IncidentPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range p.order {
+		var err error
+		if p.json {
+			err = json.NewEncoder(p.out).Encode(p.incidents[id])
+		} else {
+			err = printIncident(p.out, p.incidents[id])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.order = nil
+	p.incidents = make(map[string]*Incident)
+	p.secrets = make(map[string]map[string]struct{})
+	return nil
+}
+
+func printIncident(out io.Writer, incident *Incident) error {
+	var b strings.Builder
+	status := "unverified"
+	if incident.Verified {
+		status = "verified"
+	}
+	fmt.Fprintf(&b, "Incident %s: %s %s secret %s\n", incident.ID, status, incident.Detector, incident.Secret)
+	if incident.Secrets > 1 {
+		fmt.Fprintf(&b, "Secrets: %d\n", incident.Secrets)
+	}
+	fmt.Fprintf(&b, "Locations: %d\n", len(incident.Locations))
+	for _, l := range incident.Locations {
+		loc := resultLocation{Repository: l.Repository, Commit: l.Commit, File: l.File, Line: l.Line, Link: l.Link}
+		where := loc.String()
+		if where == "" {
+			where = l.SourceName
+		}
+		mark := " "
+		if l.Verified {
+			mark = "✅"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, where)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
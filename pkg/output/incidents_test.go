@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestIncidentPrinter(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	p := NewIncidentPrinter(&out, WithIncidentJSON())
+
+	other := gitResult("c2", "deploy.sh", "AKIAEXAMPLEKEY", true)
+	other.SourceMetadata.GetGit().Repository = "https://github.com/org/other.git"
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLEKEY", false)))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAOTHERKEY", false)))
+	require.NoError(t, p.Print(ctx, other))
+	require.NoError(t, p.Flush(ctx))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var incident Incident
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &incident))
+	assert.Len(t, incident.ID, 16)
+	assert.Equal(t, "AWS", incident.Detector)
+	assert.Equal(t, MaskSecret("AKIAEXAMPLEKEY"), incident.Secret)
+	assert.Equal(t, 1, incident.Secrets)
+	assert.True(t, incident.Verified, "an incident is verified if any of its findings is")
+	require.Len(t, incident.Locations, 2)
+	assert.Equal(t, "https://github.com/org/repo.git", incident.Locations[0].Repository)
+	assert.False(t, incident.Locations[0].Verified)
+	assert.Equal(t, "https://github.com/org/other.git", incident.Locations[1].Repository)
+	assert.Equal(t, "deploy.sh", incident.Locations[1].File)
+	assert.Equal(t, "https://github.com/org/other/blob/c2/deploy.sh#L1", incident.Locations[1].Link)
+	assert.NotEmpty(t, incident.Locations[1].Fingerprint)
+	assert.NotContains(t, out.String(), "AKIAEXAMPLEKEY")
+
+	// The IDs of incidents are stable.
+	out.Reset()
+	require.NoError(t, p.Print(ctx, other))
+	require.NoError(t, p.Flush(ctx))
+	var again Incident
+	require.NoError(t, json.Unmarshal(out.Bytes(), &again))
+	assert.Equal(t, incident.ID, again.ID)
+	assert.Len(t, again.Locations, 1)
+}
+
+func TestIncidentPrinter_Prefix(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	p := NewIncidentPrinter(&out, WithIncidentPrefixLength(8), WithIncidentSecrets())
+
+	rotated := gitResult("c2", "config.yaml", "AKIAEXAMPLEKEY", false)
+	rotated.RawV2 = []byte("AKIAEXAMPLEKEYsecret2")
+	first := gitResult("c1", "config.yaml", "AKIAEXAMPLEKEY", true)
+	first.RawV2 = []byte("AKIAEXAMPLEKEYsecret1")
+	require.NoError(t, p.Print(ctx, first))
+	require.NoError(t, p.Print(ctx, rotated))
+	require.NoError(t, p.Print(ctx, gitResult("c3", "other.yaml", "AKIADIFFERENT", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	text := out.String()
+	assert.Equal(t, 2, strings.Count(text, "Incident "))
+	assert.Contains(t, text, ": verified AWS secret AKIAEXAMPLEKEY\nSecrets: 2\nLocations: 2\n")
+	assert.Contains(t, text, "  ✅ https://github.com/org/repo.git commit c1 config.yaml:1\n")
+	assert.Contains(t, text, ": unverified AWS secret AKIADIFFERENT\nLocations: 1\n")
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Labels TruffleHog adds to Jira issues. Fingerprint and repository labels
+// let later runs find the issues they created.
+const (
+	jiraLabel            = "trufflehog"
+	jiraNotFoundLabel    = "trufflehog-not-found"
+	jiraFingerprintLabel = "trufflehog-fp-"
+	jiraRepoLabel        = "trufflehog-repo-"
+	jiraDetectorLabel    = "secret-"
+)
+
+type jiraTracker struct {
+	client    *http.Client
+	auth      func(*http.Request)
+	endpoint  string
+	project   string
+	issueType string
+}
+
+// NewJiraPrinter creates a TicketPrinter that files issues of issueType in
+// a Jira project. A token of the form "email:api-token" authenticates with
+// Jira Cloud; any other token is sent as a personal access token.
+func NewJiraPrinter(endpoint, project, issueType, token, summaryTemplate string) (*TicketPrinter, error) {
+	return newTicketPrinter(&jiraTracker{
+		client:    common.RetryableHTTPClient(),
+		auth:      tokenAuth(token),
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		project:   project,
+		issueType: issueType,
+	}, summaryTemplate)
+}
+
+func (j *jiraTracker) openTickets(ctx context.Context, repo string) ([]ticket, error) {
+	jql := fmt.Sprintf(`project = %q AND labels = %q AND labels = %q AND statusCategory != Done`,
+		j.project, jiraLabel, jiraRepoLabel+repoKey(repo))
+
+	var tickets []ticket
+	for startAt := 0; ; {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {"labels"},
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {"100"},
+		}
+		body, err := doRequest(ctx, j.client, j.auth, http.MethodGet, j.endpoint+"/rest/api/2/search?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not search Jira issues: %w", err)
+		}
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key    string `json:"key"`
+				Fields struct {
+					Labels []string `json:"labels"`
+				} `json:"fields"`
+			} `json:"issues"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("could not decode Jira issues: %w", err)
+		}
+		for _, issue := range page.Issues {
+			t := ticket{ID: issue.Key}
+			for _, label := range issue.Fields.Labels {
+				if fp, ok := strings.CutPrefix(label, jiraFingerprintLabel); ok {
+					t.Fingerprint = fp
+				}
+				t.NotFound = t.NotFound || label == jiraNotFoundLabel
+			}
+			if t.Fingerprint != "" {
+				tickets = append(tickets, t)
+			}
+		}
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			return tickets, nil
+		}
+	}
+}
+
+func (j *jiraTracker) createTicket(ctx context.Context, f ticketFinding, summary string) error {
+	body, err := json.Marshal(map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.project},
+			"issuetype":   map[string]string{"name": j.issueType},
+			"summary":     summary,
+			"description": f.description(),
+			"labels": []string{
+				jiraLabel,
+				jiraFingerprintLabel + f.Fingerprint,
+				jiraRepoLabel + repoKey(f.Repository),
+				jiraDetectorLabel + strings.ToLower(f.Detector),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, j.client, j.auth, http.MethodPost, j.endpoint+"/rest/api/2/issue", body); err != nil {
+		return fmt.Errorf("could not create Jira issue: %w", err)
+	}
+	return nil
+}
+
+func (j *jiraTracker) markNotFound(ctx context.Context, t ticket, comment string) error {
+	issueURL := j.endpoint + "/rest/api/2/issue/" + url.PathEscape(t.ID)
+
+	body, err := json.Marshal(map[string]string{"body": comment})
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, j.client, j.auth, http.MethodPost, issueURL+"/comment", body); err != nil {
+		return fmt.Errorf("could not comment on Jira issue %s: %w", t.ID, err)
+	}
+
+	body, err = json.Marshal(map[string]any{
+		"update": map[string]any{"labels": []map[string]string{{"add": jiraNotFoundLabel}}},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, j.client, j.auth, http.MethodPut, issueURL, body); err != nil {
+		return fmt.Errorf("could not label Jira issue %s: %w", t.ID, err)
+	}
+	return nil
+}
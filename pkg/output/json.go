@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reachability"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -17,6 +19,76 @@ import (
 type JSONPrinter struct{ mu sync.Mutex }
 
 func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	out, err := MarshalResult(r)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	p.mu.Lock()
+	fmt.Println(string(out))
+	p.mu.Unlock()
+	return nil
+}
+
+// MarshalResult encodes a result the way JSONPrinter prints it.
+func MarshalResult(r *detectors.ResultWithMetadata) ([]byte, error) {
+	return json.Marshal(newJSONResult(r))
+}
+
+// jsonResult is the JSON representation of a result shared by every printer
+// and sink that emits JSON.
+type jsonResult struct {
+	// SourceMetadata contains source-specific contextual information.
+	SourceMetadata *source_metadatapb.MetaData
+	// SourceID is the ID of the source that the API uses to map secrets to specific sources.
+	SourceID sources.SourceID
+	// SourceType is the type of Source.
+	SourceType sourcespb.SourceType
+	// SourceName is the name of the Source.
+	SourceName string
+	// DetectorType is the type of Detector.
+	DetectorType detectorspb.DetectorType
+	// DetectorName is the string name of the DetectorType.
+	DetectorName string
+	// DecoderName is the string name of the DecoderType.
+	DecoderName       string
+	Verified          bool
+	VerificationError string `json:",omitempty"`
+	// Unverifiable is set on secrets no API can verify, such as webhook signing secrets.
+	Unverifiable bool `json:",omitempty"`
+	// Raw contains the raw secret data.
+	Raw string
+	// RawV2 contains the raw secret identifier that is a combination of both the ID and the secret.
+	// This is used for secrets that are multi part and could have the same ID. Ex: AWS credentials
+	RawV2 string
+	// Redacted contains the redacted version of the raw secret identification data for display purposes.
+	// A secret ID should be used if available.
+	Redacted       string
+	ExtraData      map[string]string
+	StructuredData *detectorspb.StructuredData
+	// Fingerprint is a stable identifier of the finding across scans.
+	Fingerprint string `json:",omitempty"`
+	// Occurrences lists every location of the finding when results are deduplicated.
+	Occurrences []*source_metadatapb.MetaData `json:",omitempty"`
+	// Context holds the lines around the secret when --context-lines is set.
+	Context *detectors.LineContext `json:",omitempty"`
+	// Categories are the categories of the file the secret was found in, such as test or lockfile.
+	Categories []classify.Category `json:",omitempty"`
+	// Variable is the name the secret is assigned to in a dotenv file or credential dotfile.
+	Variable string `json:",omitempty"`
+	// Address is the address of the template block the secret was found in.
+	Address string `json:",omitempty"`
+	// Exposure is how long the secret has been exposed in its git repository when --exposure is set.
+	Exposure *detectors.Exposure `json:",omitempty"`
+	// Reachability is who can read the repository or bucket the secret was found in: public, internal, or private.
+	Reachability reachability.Scope `json:",omitempty"`
+	// Owners are the teams or people the finding is attributed to when --owners is set.
+	Owners []string `json:",omitempty"`
+	// Blame is the commit that introduced the secret when --blame is set.
+	Blame *detectors.Blame `json:",omitempty"`
+}
+
+func newJSONResult(r *detectors.ResultWithMetadata) *jsonResult {
 	verificationErr := func(err error) string {
 		if err != nil {
 			return err.Error()
@@ -24,34 +96,7 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		return ""
 	}(r.VerificationError())
 
-	v := &struct {
-		// SourceMetadata contains source-specific contextual information.
-		SourceMetadata *source_metadatapb.MetaData
-		// SourceID is the ID of the source that the API uses to map secrets to specific sources.
-		SourceID sources.SourceID
-		// SourceType is the type of Source.
-		SourceType sourcespb.SourceType
-		// SourceName is the name of the Source.
-		SourceName string
-		// DetectorType is the type of Detector.
-		DetectorType detectorspb.DetectorType
-		// DetectorName is the string name of the DetectorType.
-		DetectorName string
-		// DecoderName is the string name of the DecoderType.
-		DecoderName       string
-		Verified          bool
-		VerificationError string `json:",omitempty"`
-		// Raw contains the raw secret data.
-		Raw string
-		// RawV2 contains the raw secret identifier that is a combination of both the ID and the secret.
-		// This is used for secrets that are multi part and could have the same ID. Ex: AWS credentials
-		RawV2 string
-		// Redacted contains the redacted version of the raw secret identification data for display purposes.
-		// A secret ID should be used if available.
-		Redacted       string
-		ExtraData      map[string]string
-		StructuredData *detectorspb.StructuredData
-	}{
+	return &jsonResult{
 		SourceMetadata:    r.SourceMetadata,
 		SourceID:          r.SourceID,
 		SourceType:        r.SourceType,
@@ -61,19 +106,21 @@ func (p *JSONPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		DecoderName:       r.DecoderType.String(),
 		Verified:          r.Verified,
 		VerificationError: verificationErr,
+		Unverifiable:      r.Unverifiable,
 		Raw:               string(r.Raw),
 		RawV2:             string(r.RawV2),
 		Redacted:          r.Redacted,
 		ExtraData:         r.ExtraData,
 		StructuredData:    r.StructuredData,
+		Fingerprint:       r.Fingerprint,
+		Occurrences:       r.Occurrences,
+		Context:           r.Context,
+		Categories:        r.Categories,
+		Variable:          r.Variable,
+		Address:           r.Address,
+		Exposure:          r.Exposure,
+		Reachability:      r.Reachability,
+		Owners:            r.Owners,
+		Blame:             r.Blame,
 	}
-	out, err := json.Marshal(v)
-	if err != nil {
-		return fmt.Errorf("could not marshal result: %w", err)
-	}
-
-	p.mu.Lock()
-	fmt.Println(string(out))
-	p.mu.Unlock()
-	return nil
 }
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// JUnitPrinter is a printer that collects results and writes them as a JUnit
+// XML report once the scan has finished. Each detector becomes a test suite
+// and each result a test case; verified results are reported as failures so
+// CI systems that render JUnit highlight them.
+type JUnitPrinter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	suites map[string][]junitTestCase
+}
+
+// NewJUnitPrinter creates a JUnitPrinter that writes to out. If out is nil,
+// os.Stdout is used.
+func NewJUnitPrinter(out io.Writer) *JUnitPrinter {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &JUnitPrinter{out: out, suites: make(map[string][]junitTestCase)}
+}
+
+func (p *JUnitPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	detector := r.DetectorType.String()
+	name := loc.File
+	if name == "" {
+		name = r.SourceName
+	}
+	if loc.Line > 0 {
+		name = fmt.Sprintf("%s:%d", name, loc.Line)
+	}
+
+	tc := junitTestCase{Name: name, ClassName: detector}
+	switch {
+	case r.Verified:
+		tc.Failure = &junitMessage{
+			Message: fmt.Sprintf("verified %s secret found", detector),
+			Type:    "verified",
+			Text:    junitDetails(r, loc),
+		}
+	case r.VerificationError() != nil:
+		tc.SystemOut = fmt.Sprintf("verification error: %s\n%s", r.VerificationError(), junitDetails(r, loc))
+	default:
+		tc.SystemOut = junitDetails(r, loc)
+	}
+
+	p.mu.Lock()
+	p.suites[detector] = append(p.suites[detector], tc)
+	p.mu.Unlock()
+	return nil
+}
+
+// Flush writes the JUnit report containing every result printed so far.
+func (p *JUnitPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := make([]string, 0, len(p.suites))
+	for name := range p.suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := junitTestSuites{Name: toolName}
+	for _, name := range names {
+		cases := p.suites[name]
+		suite := junitTestSuite{Name: name, Tests: len(cases), TestCases: cases}
+		for _, tc := range cases {
+			if tc.Failure != nil {
+				suite.Failures++
+			}
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(p.out, xml.Header); err != nil {
+		return fmt.Errorf("could not write JUnit output: %w", err)
+	}
+	enc := xml.NewEncoder(p.out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("could not write JUnit output: %w", err)
+	}
+	_, err := io.WriteString(p.out, "\n")
+	return err
+}
+
+// junitDetails renders the non-sensitive context of a result for the body of
+// a test case.
+func junitDetails(r *detectors.ResultWithMetadata, loc resultLocation) string {
+	details := fmt.Sprintf("Detector: %s\nDecoder: %s\nSource: %s\n", r.DetectorType, r.DecoderType, r.SourceName)
+	if loc.Repository != "" {
+		details += fmt.Sprintf("Repository: %s\n", loc.Repository)
+	}
+	if loc.Commit != "" {
+		details += fmt.Sprintf("Commit: %s\n", loc.Commit)
+	}
+	if loc.File != "" {
+		details += fmt.Sprintf("File: %s\n", loc.File)
+	}
+	if loc.Link != "" {
+		details += fmt.Sprintf("Link: %s\n", loc.Link)
+	}
+	if r.Redacted != "" {
+		details += fmt.Sprintf("Redacted: %s\n", r.Redacted)
+	}
+	return details
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestJUnitPrinter(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	p := NewJUnitPrinter(&buf)
+
+	newResult := func(detector detectorspb.DetectorType, file string, verified bool) *detectors.ResultWithMetadata {
+		return &detectors.ResultWithMetadata{
+			SourceName: "test",
+			SourceMetadata: &source_metadatapb.MetaData{
+				Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{
+					File: file,
+					Line: 3,
+				}},
+			},
+			Result: detectors.Result{DetectorType: detector, Verified: verified},
+		}
+	}
+	require.NoError(t, p.Print(ctx, newResult(detectorspb.DetectorType_AWS, "a.txt", true)))
+	require.NoError(t, p.Print(ctx, newResult(detectorspb.DetectorType_AWS, "b.txt", false)))
+	require.NoError(t, p.Print(ctx, newResult(detectorspb.DetectorType_Slack, "c.txt", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	require.Len(t, report.Suites, 2)
+
+	aws := report.Suites[0]
+	assert.Equal(t, "AWS", aws.Name)
+	assert.Equal(t, 2, aws.Tests)
+	assert.Equal(t, 1, aws.Failures)
+	assert.Equal(t, "a.txt:3", aws.TestCases[0].Name)
+	assert.NotNil(t, aws.TestCases[0].Failure)
+	assert.Nil(t, aws.TestCases[1].Failure)
+
+	slack := report.Suites[1]
+	assert.Equal(t, "Slack", slack.Name)
+	assert.Equal(t, 0, slack.Failures)
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// resultLocation is a source-agnostic view of where a result was found.
+// Not every source populates every field.
+type resultLocation struct {
+	File       string
+	Line       int64
+	Commit     string
+	Repository string
+	Link       string
+	Email      string
+	Timestamp  string
+}
+
+// extractLocation flattens the source-specific metadata of a result into a
+// resultLocation. Sources use a handful of common field names for the same
+// concepts, so the metadata is inspected generically rather than per source.
+// The file of a result extracted from archives includes the entries it was
+// extracted from.
+func extractLocation(md *source_metadatapb.MetaData) (resultLocation, error) {
+	var loc resultLocation
+	if md == nil || md.Data == nil {
+		return loc, nil
+	}
+
+	meta, err := structToMap(md.Data)
+	if err != nil {
+		return loc, err
+	}
+	return locationOf(meta, md.GetProvenance()), nil
+}
+
+// locationOf flattens the metadata of a result, as marshalled to JSON, into
+// a resultLocation.
+func locationOf(meta map[string]map[string]any, provenance []*source_metadatapb.Container) resultLocation {
+	var loc resultLocation
+	for _, data := range meta {
+		for k, v := range data {
+			switch k {
+			case "line":
+				if line, ok := v.(float64); ok {
+					loc.Line = int64(line)
+				}
+			case "file", "filename":
+				if file, ok := v.(string); ok && loc.File == "" {
+					loc.File = file
+				}
+			case "commit":
+				loc.Commit, _ = v.(string)
+			case "repository":
+				loc.Repository, _ = v.(string)
+			case "link":
+				loc.Link, _ = v.(string)
+			case "email":
+				loc.Email, _ = v.(string)
+			case "timestamp":
+				loc.Timestamp, _ = v.(string)
+			}
+		}
+	}
+	loc.File = nestedPath(loc.File, provenance)
+	return loc
+}
+
+// nestedPath appends the paths of the archive entries content was extracted
+// from to the file it was found in, separated by "!", such as
+// backup.tar.gz!app/creds.zip!.env.
+func nestedPath(file string, provenance []*source_metadatapb.Container) string {
+	for _, c := range provenance {
+		if c.GetPath() == "" {
+			continue
+		}
+		if file == "" {
+			file = c.GetPath()
+		} else {
+			file += "!" + c.GetPath()
+		}
+	}
+	return file
+}
+
+// String renders the location in a compact, human-readable form.
+func (l resultLocation) String() string {
+	var parts []string
+	if l.Repository != "" {
+		parts = append(parts, l.Repository)
+	}
+	if l.Commit != "" {
+		parts = append(parts, "commit "+l.Commit)
+	}
+	if l.File != "" {
+		file := l.File
+		if l.Line > 0 {
+			file = fmt.Sprintf("%s:%d", file, l.Line)
+		}
+		parts = append(parts, file)
+	}
+	if len(parts) == 0 && l.Link != "" {
+		parts = append(parts, l.Link)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Location describes where a result was found in a compact, human-readable
+// form, and returns a link to it when one is known.
+func Location(r *detectors.ResultWithMetadata) (where, link string) {
+	loc, _ := extractLocation(r.SourceMetadata)
+	return loc.String(), reportLink(loc)
+}
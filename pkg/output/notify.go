@@ -0,0 +1,411 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// DefaultNotificationRate is the default number of notifications sent to a
+// single channel per minute. Slack and Teams incoming webhooks both throttle
+// at around one message per second.
+const DefaultNotificationRate = 60
+
+// NotificationRoute sends findings of at least MinSeverity to a chat webhook.
+// Routes with an Owner only receive the findings attributed to it.
+type NotificationRoute struct {
+	URL         string
+	MinSeverity string
+	Owner       string
+}
+
+// ParseNotificationRoute parses a route of the form "[severity=]url", where
+// severity is one of high, medium or low. Routes without a severity receive
+// every finding.
+func ParseNotificationRoute(s string) (NotificationRoute, error) {
+	route := NotificationRoute{URL: s, MinSeverity: severityLow}
+	if severity, url, ok := strings.Cut(s, "="); ok {
+		switch severity {
+		case severityHigh, severityMedium, severityLow:
+			route = NotificationRoute{URL: url, MinSeverity: severity}
+		}
+	}
+	if !strings.HasPrefix(route.URL, "https://") && !strings.HasPrefix(route.URL, "http://") {
+		return NotificationRoute{}, fmt.Errorf("invalid notification webhook %q: expected [high|medium|low=]URL", s)
+	}
+	return route, nil
+}
+
+// notification is the platform-agnostic content of a finding notification.
+type notification struct {
+	Detector string
+	Severity string
+	Status   string
+	Location string
+	Link     string
+	Preview  string
+	Owners   string
+}
+
+// notificationFormatter renders a notification as a platform-specific
+// webhook payload.
+type notificationFormatter func(notification) any
+
+// notificationDigest summarizes the results of a repository or owner when
+// notifications are grouped.
+type notificationDigest struct {
+	Group     string
+	Totals    htmlCount
+	Detectors map[string]int
+	owners    map[string]struct{}
+}
+
+// topDetectors returns the detectors with the most results, most first.
+func (d *notificationDigest) topDetectors(n int) []string {
+	detectors := make([]string, 0, len(d.Detectors))
+	for detector := range d.Detectors {
+		detectors = append(detectors, detector)
+	}
+	sort.Slice(detectors, func(i, j int) bool {
+		a, b := detectors[i], detectors[j]
+		if d.Detectors[a] != d.Detectors[b] {
+			return d.Detectors[a] > d.Detectors[b]
+		}
+		return a < b
+	})
+	if len(detectors) > n {
+		detectors = detectors[:n]
+	}
+	for i, detector := range detectors {
+		detectors[i] = fmt.Sprintf("%s (%d)", detector, d.Detectors[detector])
+	}
+	return detectors
+}
+
+// severity is the highest severity of the digest's results.
+func (d *notificationDigest) severity() string {
+	switch {
+	case d.Totals.High > 0:
+		return severityHigh
+	case d.Totals.Medium > 0:
+		return severityMedium
+	default:
+		return severityLow
+	}
+}
+
+// digestFormatter renders a digest as a platform-specific webhook payload.
+type digestFormatter func(*notificationDigest) any
+
+type notificationChannel struct {
+	route   NotificationRoute
+	limiter *rate.Limiter
+}
+
+// NotificationPrinter is a printer that posts a short, human-readable message
+// for every result to chat webhooks. Secrets are always masked, regardless of
+// whether the result was redacted upstream.
+type NotificationPrinter struct {
+	format       notificationFormatter
+	formatDigest digestFormatter
+	channels     []notificationChannel
+	client       *http.Client
+	groupBy      string
+
+	mu      sync.Mutex
+	digests map[string]*notificationDigest
+}
+
+// NotificationOption configures a NotificationPrinter.
+type NotificationOption func(*notificationOptions)
+
+type notificationOptions struct {
+	perMinute int
+	client    *http.Client
+	groupBy   string
+}
+
+// WithNotificationRate limits how many messages are sent to each channel per
+// minute. Messages over the limit wait rather than being dropped.
+func WithNotificationRate(perMinute int) NotificationOption {
+	return func(o *notificationOptions) { o.perMinute = perMinute }
+}
+
+// WithNotificationDigests sends a digest of the results of every group, when
+// results are grouped by, once the scan has finished instead of a message per
+// result. Routes with an owner only receive the digests of the groups with
+// results attributed to it.
+func WithNotificationDigests(by string) NotificationOption {
+	return func(o *notificationOptions) { o.groupBy = by }
+}
+
+// WithNotificationClient sets the HTTP client used to deliver messages.
+func WithNotificationClient(client *http.Client) NotificationOption {
+	return func(o *notificationOptions) { o.client = client }
+}
+
+// NewSlackPrinter creates a NotificationPrinter that posts to Slack incoming
+// webhooks.
+func NewSlackPrinter(routes []NotificationRoute, opts ...NotificationOption) *NotificationPrinter {
+	return newNotificationPrinter(slackMessage, slackDigest, routes, opts...)
+}
+
+// NewTeamsPrinter creates a NotificationPrinter that posts to Microsoft Teams
+// incoming webhooks.
+func NewTeamsPrinter(routes []NotificationRoute, opts ...NotificationOption) *NotificationPrinter {
+	return newNotificationPrinter(teamsMessage, teamsDigest, routes, opts...)
+}
+
+func newNotificationPrinter(format notificationFormatter, formatDigest digestFormatter, routes []NotificationRoute, opts ...NotificationOption) *NotificationPrinter {
+	o := notificationOptions{perMinute: DefaultNotificationRate}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.client == nil {
+		o.client = common.RetryableHTTPClient()
+	}
+
+	limit := rate.Inf
+	if o.perMinute > 0 {
+		limit = rate.Every(time.Minute / time.Duration(o.perMinute))
+	}
+	p := &NotificationPrinter{
+		format:       format,
+		formatDigest: formatDigest,
+		client:       o.client,
+		groupBy:      o.groupBy,
+		digests:      make(map[string]*notificationDigest),
+	}
+	for _, route := range routes {
+		p.channels = append(p.channels, notificationChannel{route: route, limiter: rate.NewLimiter(limit, 1)})
+	}
+	return p
+}
+
+func (p *NotificationPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	if p.groupBy != "" {
+		p.addToDigests(r)
+		return nil
+	}
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	severity := resultSeverity(r)
+	n := notification{
+		Detector: r.DetectorType.String(),
+		Severity: severity,
+		Status:   "unverified",
+		Location: loc.String(),
+		Link:     reportLink(loc),
+		Preview:  SecretPreview(r),
+		Owners:   strings.Join(r.Owners, ", "),
+	}
+	switch {
+	case r.Verified:
+		n.Status = "verified"
+	case r.VerificationError() != nil:
+		n.Status = "unknown"
+	}
+	if n.Location == "" {
+		n.Location = r.SourceName
+	}
+
+	var body []byte
+	var errs []error
+	for _, ch := range p.channels {
+		if severityRank(severity) > severityRank(ch.route.MinSeverity) {
+			continue
+		}
+		if ch.route.Owner != "" && !slices.Contains(r.Owners, ch.route.Owner) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(p.format(n)); err != nil {
+				return fmt.Errorf("could not marshal notification: %w", err)
+			}
+		}
+		if err := ch.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := postJSON(ctx, p.client, ch.route.URL, body, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *NotificationPrinter) addToDigests(r *detectors.ResultWithMetadata) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, group := range ResultGroups(r, p.groupBy) {
+		d, ok := p.digests[group]
+		if !ok {
+			d = &notificationDigest{Group: group, Detectors: make(map[string]int), owners: make(map[string]struct{})}
+			p.digests[group] = d
+		}
+		d.Totals.add(resultSeverity(r))
+		d.Detectors[r.DetectorType.String()]++
+		for _, owner := range r.Owners {
+			d.owners[owner] = struct{}{}
+		}
+	}
+}
+
+// Flush posts the digest of every group, in the order of their names, when
+// notifications are grouped.
+func (p *NotificationPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	digests := p.digests
+	p.digests = make(map[string]*notificationDigest)
+	p.mu.Unlock()
+
+	groups := make([]string, 0, len(digests))
+	for group := range digests {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var errs []error
+	for _, group := range groups {
+		d := digests[group]
+		body, err := json.Marshal(p.formatDigest(d))
+		if err != nil {
+			return fmt.Errorf("could not marshal notification: %w", err)
+		}
+		for _, ch := range p.channels {
+			if severityRank(d.severity()) > severityRank(ch.route.MinSeverity) {
+				continue
+			}
+			if _, ok := d.owners[ch.route.Owner]; ch.route.Owner != "" && !ok {
+				continue
+			}
+			if err := ch.limiter.Wait(ctx); err != nil {
+				return err
+			}
+			if err := postJSON(ctx, p.client, ch.route.URL, body, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n notification) title() string {
+	return fmt.Sprintf("Found %s %s secret", n.Status, n.Detector)
+}
+
+func slackMessage(n notification) any {
+	text := fmt.Sprintf("*%s*\n*Location:* %s\n*Secret:* `%s`", n.title(), n.Location, n.Preview)
+	if n.Owners != "" {
+		text += fmt.Sprintf("\n*Owners:* %s", n.Owners)
+	}
+	if n.Link != "" {
+		text += fmt.Sprintf("\n<%s|View on source>", n.Link)
+	}
+	return map[string]any{
+		"text": n.title(),
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+		},
+	}
+}
+
+func (d *notificationDigest) title() string {
+	if d.Totals.Total == 1 {
+		return "1 secret found in " + d.Group
+	}
+	return fmt.Sprintf("%d secrets found in %s", d.Totals.Total, d.Group)
+}
+
+func slackDigest(d *notificationDigest) any {
+	text := fmt.Sprintf("*%s*\n*High:* %d  *Medium:* %d  *Low:* %d\n*Detectors:* %s",
+		d.title(), d.Totals.High, d.Totals.Medium, d.Totals.Low, strings.Join(d.topDetectors(5), ", "))
+	return map[string]any{
+		"text": d.title(),
+		"blocks": []any{
+			map[string]any{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+		},
+	}
+}
+
+func teamsDigest(d *notificationDigest) any {
+	return map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    d.title(),
+		"title":      d.title(),
+		"themeColor": notificationColors[d.severity()],
+		"sections": []any{
+			map[string]any{
+				"facts": []map[string]string{
+					{"name": "High", "value": strconv.Itoa(d.Totals.High)},
+					{"name": "Medium", "value": strconv.Itoa(d.Totals.Medium)},
+					{"name": "Low", "value": strconv.Itoa(d.Totals.Low)},
+					{"name": "Detectors", "value": strings.Join(d.topDetectors(5), ", ")},
+				},
+			},
+		},
+	}
+}
+
+// notificationColors are the colors of Teams cards by severity.
+var notificationColors = map[string]string{
+	severityHigh:   "D32F2F",
+	severityMedium: "F57C00",
+	severityLow:    "FBC02D",
+}
+
+func teamsMessage(n notification) any {
+	facts := []map[string]string{
+		{"name": "Detector", "value": n.Detector},
+		{"name": "Status", "value": n.Status},
+		{"name": "Location", "value": n.Location},
+		{"name": "Secret", "value": n.Preview},
+	}
+	if n.Owners != "" {
+		facts = append(facts, map[string]string{"name": "Owners", "value": n.Owners})
+	}
+	card := map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    n.title(),
+		"title":      n.title(),
+		"themeColor": notificationColors[n.Severity],
+		"sections": []any{
+			map[string]any{
+				"facts": facts,
+			},
+		},
+	}
+	if n.Link != "" {
+		card["potentialAction"] = []any{
+			map[string]any{
+				"@type":   "OpenUri",
+				"name":    "View on source",
+				"targets": []map[string]string{{"os": "default", "uri": n.Link}},
+			},
+		}
+	}
+	return card
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestParseNotificationRoute(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    NotificationRoute
+		wantErr bool
+	}{
+		{in: "https://hooks.slack.com/x", want: NotificationRoute{URL: "https://hooks.slack.com/x", MinSeverity: "low"}},
+		{in: "high=https://hooks.slack.com/x", want: NotificationRoute{URL: "https://hooks.slack.com/x", MinSeverity: "high"}},
+		{in: "https://example.com/hook?a=b", want: NotificationRoute{URL: "https://example.com/hook?a=b", MinSeverity: "low"}},
+		{in: "critical=https://hooks.slack.com/x", wantErr: true},
+		{in: "hooks.slack.com/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseNotificationRoute(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNotificationPrinter_Routing(t *testing.T) {
+	ctx := context.Background()
+	all, verified := new(webhookReceiver), new(webhookReceiver)
+	allSrv, verifiedSrv := httptest.NewServer(all), httptest.NewServer(verified)
+	defer allSrv.Close()
+	defer verifiedSrv.Close()
+
+	p := NewSlackPrinter(
+		[]NotificationRoute{
+			{URL: allSrv.URL, MinSeverity: severityLow},
+			{URL: verifiedSrv.URL, MinSeverity: severityHigh},
+		},
+		WithNotificationRate(0),
+		WithNotificationClient(testWebhookClient()),
+	)
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", false)))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLESECRET", true)))
+
+	assert.Len(t, all.bodies, 2)
+	require.Len(t, verified.bodies, 1)
+
+	var msg struct{ Text string }
+	require.NoError(t, json.Unmarshal(verified.bodies[0], &msg))
+	assert.Equal(t, "Found verified AWS secret", msg.Text)
+	assert.NotContains(t, string(verified.bodies[0]), "AKIAEXAMPLESECRET", "secrets must be masked")
+}
+
+func TestNotificationPrinter_OwnerRouting(t *testing.T) {
+	ctx := context.Background()
+	all, payments := new(webhookReceiver), new(webhookReceiver)
+	allSrv, paymentsSrv := httptest.NewServer(all), httptest.NewServer(payments)
+	defer allSrv.Close()
+	defer paymentsSrv.Close()
+
+	p := NewSlackPrinter(
+		[]NotificationRoute{
+			{URL: allSrv.URL, MinSeverity: severityLow},
+			{URL: paymentsSrv.URL, MinSeverity: severityLow, Owner: "payments"},
+		},
+		WithNotificationRate(0),
+		WithNotificationClient(testWebhookClient()),
+	)
+	owned := gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", false)
+	owned.Owners = []string{"platform", "payments"}
+	require.NoError(t, p.Print(ctx, owned))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLESECRET", false)))
+
+	assert.Len(t, all.bodies, 2)
+	require.Len(t, payments.bodies, 1)
+	assert.Contains(t, string(payments.bodies[0]), "*Owners:* platform, payments")
+}
+
+func TestNotificationPrinter_Digests(t *testing.T) {
+	ctx := context.Background()
+	all, payments := new(webhookReceiver), new(webhookReceiver)
+	allSrv, paymentsSrv := httptest.NewServer(all), httptest.NewServer(payments)
+	defer allSrv.Close()
+	defer paymentsSrv.Close()
+
+	p := NewSlackPrinter(
+		[]NotificationRoute{
+			{URL: allSrv.URL, MinSeverity: severityLow},
+			{URL: paymentsSrv.URL, MinSeverity: severityLow, Owner: "payments"},
+		},
+		WithNotificationRate(0),
+		WithNotificationClient(testWebhookClient()),
+		WithNotificationDigests(GroupByRepository),
+	)
+	owned := gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", true)
+	owned.Owners = []string{"payments"}
+	require.NoError(t, p.Print(ctx, owned))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLESECRET", false)))
+	other := gitResult("c3", "config.yaml", "AKIAEXAMPLESECRET", false)
+	other.SourceMetadata.GetGit().Repository = "https://github.com/org/other.git"
+	require.NoError(t, p.Print(ctx, other))
+	assert.Empty(t, all.bodies, "digests are only sent when flushed")
+
+	require.NoError(t, p.Flush(ctx))
+	require.Len(t, all.bodies, 2)
+	var msg struct{ Text string }
+	require.NoError(t, json.Unmarshal(all.bodies[0], &msg))
+	assert.Equal(t, "1 secret found in https://github.com/org/other.git", msg.Text)
+	require.NoError(t, json.Unmarshal(all.bodies[1], &msg))
+	assert.Equal(t, "2 secrets found in https://github.com/org/repo.git", msg.Text)
+	assert.Contains(t, string(all.bodies[1]), "*High:* 1  *Medium:* 0  *Low:* 1")
+	assert.Contains(t, string(all.bodies[1]), "AWS (2)")
+	require.Len(t, payments.bodies, 1, "owner routes only receive the digests of their groups")
+	assert.Equal(t, all.bodies[1], payments.bodies[0])
+}
+
+func TestTeamsMessage(t *testing.T) {
+	msg := teamsMessage(notification{
+		Detector: "AWS",
+		Severity: severityHigh,
+		Status:   "verified",
+		Location: "config.yaml:1",
+		Link:     "https://github.com/org/repo/blob/c1/config.yaml#L1",
+		Preview:  "AKIA****CRET",
+	})
+	out, err := json.Marshal(msg)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(out), `"themeColor":"D32F2F"`))
+	assert.Contains(t, string(out), "OpenUri")
+}
@@ -50,7 +50,11 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 		boldGreenPrinter.Print("✅ Found verified result 🐷🔑\n")
 	} else {
 		printer = whitePrinter
-		boldWhitePrinter.Print("Found unverified result 🐷🔑❓\n")
+		if r.Result.Unverifiable {
+			boldWhitePrinter.Print("Found unverifiable result 🐷🔑❓\n")
+		} else {
+			boldWhitePrinter.Print("Found unverified result 🐷🔑❓\n")
+		}
 		if out.VerificationError != nil {
 			yellowPrinter.Printf("Verification issue: %s\n", out.VerificationError)
 		}
@@ -95,10 +99,97 @@ func (p *PlainPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata)
 	for _, k := range aggregateDataKeys {
 		printer.Printf("%s: %v\n", cases.Title(language.AmericanEnglish).String(k), aggregateData[k])
 	}
+	if provenance := out.MetaData.GetProvenance(); len(provenance) > 0 {
+		printer.Printf("Extracted from: %s\n", provenanceText(provenance))
+	}
+
+	if len(r.Categories) > 0 {
+		printer.Printf("Categories: %s\n", strings.Join(categoryNames(r.Categories), ", "))
+	}
+
+	if r.Address != "" {
+		printer.Printf("Address: %s\n", r.Address)
+	}
+
+	if r.Variable != "" {
+		printer.Printf("Variable: %s\n", r.Variable)
+	}
+
+	if e := r.Exposure; e != nil {
+		printer.Printf("Exposed: %s\n", exposureText(e))
+	}
+
+	if r.Reachability != "" {
+		printer.Printf("Reachability: %s\n", r.Reachability)
+	}
+
+	if len(r.Owners) > 0 {
+		printer.Printf("Owners: %s\n", strings.Join(r.Owners, ", "))
+	}
+
+	if b := r.Blame; b != nil {
+		printer.Printf("Introduced: %s by %s <%s> on %s\n", b.Commit, b.Author, b.Email, b.Date.Format("2006-01-02"))
+	}
+
+	if len(r.Occurrences) > 1 {
+		printer.Printf("Occurrences: %d\n", len(r.Occurrences))
+		for _, md := range r.Occurrences {
+			loc, err := extractLocation(md)
+			if err != nil {
+				continue
+			}
+			printer.Printf("  - %s\n", loc)
+		}
+	}
+	if c := r.Context; c != nil {
+		// The line of the secret is marked, so the context can't be
+		// mistaken for the secret.
+		printer.Println("Context:")
+		for _, line := range c.Before {
+			printer.Printf("    %s\n", line)
+		}
+		printer.Printf("  > %s\n", c.Line)
+		for _, line := range c.After {
+			printer.Printf("    %s\n", line)
+		}
+	}
 	fmt.Println("")
 	return nil
 }
 
+// exposureText describes how long a secret has been exposed, and to whom.
+func exposureText(e *detectors.Exposure) string {
+	const day = "2006-01-02"
+	text := fmt.Sprintf("%d days, since %s", e.Days, e.IntroducedAt.Format(day))
+	if e.RemovedAt != nil {
+		text = fmt.Sprintf("%d days, from %s until it was removed on %s", e.Days, e.IntroducedAt.Format(day), e.RemovedAt.Format(day))
+	}
+	if e.Public != nil && *e.Public {
+		text += ", in a public repository"
+	} else if e.Public != nil {
+		text += ", in a private repository"
+	}
+	return text
+}
+
+// provenanceText describes the containers a result was extracted from,
+// outermost first, such as "gz > tar app/creds.zip > zip .env", or
+// "eml creds.zip (part 2) > zip .env".
+func provenanceText(provenance []*source_metadatapb.Container) string {
+	levels := make([]string, 0, len(provenance))
+	for _, c := range provenance {
+		level := c.GetFormat()
+		if c.GetPath() != "" {
+			level += " " + c.GetPath()
+		}
+		if c.GetPart() != "" {
+			level += " (part " + c.GetPart() + ")"
+		}
+		levels = append(levels, level)
+	}
+	return strings.Join(levels, " > ")
+}
+
 func structToMap(obj any) (m map[string]map[string]any, err error) {
 	data, err := json.Marshal(obj)
 	if err != nil {
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+)
+
+// PolicyViolation is a finding, or the summary of the scan, failing a
+// policy rule.
+type PolicyViolation struct {
+	Rule string
+	// Detector and Location are empty for summary rules.
+	Detector string
+	Location string
+}
+
+// PolicyPrinter is a printer that evaluates a policy over every result and,
+// once the scan has finished, over its summary. It doesn't print individual
+// results.
+type PolicyPrinter struct {
+	mu         sync.Mutex
+	policy     *policy.Policy
+	findings   verificationCounts
+	violations []PolicyViolation
+	// failing is the number of results that failed a finding rule.
+	failing int
+}
+
+// NewPolicyPrinter creates a PolicyPrinter that evaluates p.
+func NewPolicyPrinter(p *policy.Policy) *PolicyPrinter {
+	return &PolicyPrinter{policy: p}
+}
+
+func (p *PolicyPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	failed, evalErr := p.policy.EvalFinding(policy.Finding{
+		Detector:     r.DetectorType.String(),
+		Decoder:      r.DecoderType.String(),
+		Verified:     r.Verified,
+		Severity:     resultSeverity(r),
+		Source:       r.SourceName,
+		SourceType:   r.SourceType.String(),
+		File:         loc.File,
+		Line:         loc.Line,
+		Repository:   loc.Repository,
+		Commit:       loc.Commit,
+		Email:        loc.Email,
+		Link:         loc.Link,
+		Extra:        r.ExtraData,
+		Categories:   categoryNames(r.Categories),
+		Reachability: string(r.Reachability),
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.findings.add(r)
+	if len(failed) > 0 {
+		p.failing++
+	}
+	for _, rule := range failed {
+		p.violations = append(p.violations, PolicyViolation{
+			Rule:     rule,
+			Detector: r.DetectorType.String(),
+			Location: loc.String(),
+		})
+	}
+	return evalErr
+}
+
+// Evaluate evaluates the summary rules of the policy with the results seen
+// so far and stats, and returns every violation of the scan. The scan passes
+// the policy when there are none.
+func (p *PolicyPrinter) Evaluate(stats ScanStats) ([]PolicyViolation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed, err := p.policy.EvalSummary(policy.Summary{
+		Findings:        int64(p.findings.Total),
+		Verified:        int64(p.findings.Verified),
+		Unknown:         int64(p.findings.Unknown),
+		Unverified:      int64(p.findings.Unverified),
+		Violations:      int64(p.failing),
+		Chunks:          int64(stats.ChunksScanned),
+		Bytes:           int64(stats.BytesScanned),
+		Errors:          int64(stats.Errors),
+		DurationSeconds: int64(stats.Duration.Seconds()),
+		Interrupted:     stats.Interrupted,
+	})
+	violations := append([]PolicyViolation(nil), p.violations...)
+	for _, rule := range failed {
+		violations = append(violations, PolicyViolation{Rule: rule})
+	}
+	return violations, err
+}
+
+func categoryNames(categories []classify.Category) []string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = string(c)
+	}
+	return names
+}
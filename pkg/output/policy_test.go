@@ -0,0 +1,120 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/classify"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/reachability"
+)
+
+func TestPolicyPrinter(t *testing.T) {
+	ctx := context.Background()
+	pol, err := policy.Parse([]byte(`
+findings:
+  - name: verified outside tests
+    fail: finding.verified && !finding.file.startsWith("test/")
+summary:
+  - name: slow scan with findings
+    fail: summary.findings > 0 && summary.duration_seconds >= 60
+  - name: unverified
+    fail: summary.unverified >= summary.violations
+`))
+	require.NoError(t, err)
+	p := NewPolicyPrinter(pol)
+
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "test/config.yaml", "AKIAEXAMPLE", true)))
+
+	violations, err := p.Evaluate(ScanStats{Duration: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyViolation{{
+		Rule:     "verified outside tests",
+		Detector: "AWS",
+		Location: "https://github.com/org/repo.git commit c1 config.yaml:1",
+	}}, violations)
+
+	require.NoError(t, p.Print(ctx, gitResult("c2", "other.yaml", "AKIAOTHER", false)))
+	violations, err = p.Evaluate(ScanStats{Duration: 2 * time.Minute})
+	require.NoError(t, err)
+	require.Len(t, violations, 3)
+	assert.Equal(t, PolicyViolation{Rule: "slow scan with findings"}, violations[1])
+	assert.Equal(t, PolicyViolation{Rule: "unverified"}, violations[2])
+}
+
+func TestPolicyPrinter_Categories(t *testing.T) {
+	pol, err := policy.Parse([]byte(`
+findings:
+  - name: high severity outside tests
+    fail: finding.severity == "high" && !("test" in finding.categories)
+`))
+	require.NoError(t, err)
+	p := NewPolicyPrinter(pol)
+
+	classified := gitResult("c1", "test/config.yaml", "AKIAEXAMPLE", true)
+	classified.Categories = []classify.Category{classify.Test}
+	assert.Equal(t, severityMedium, resultSeverity(classified))
+	require.NoError(t, p.Print(context.Background(), classified))
+
+	violations, err := p.Evaluate(ScanStats{})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestResultSeverity_Exposure(t *testing.T) {
+	public := true
+	unverified := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	unverified.Exposure = &detectors.Exposure{Days: 3, Public: &public}
+	assert.Equal(t, severityMedium, resultSeverity(unverified))
+
+	old := gitResult("c1", "test/config.yaml", "AKIAEXAMPLE", true)
+	old.Categories = []classify.Category{classify.Test}
+	old.Exposure = &detectors.Exposure{Days: longExposureDays + 1}
+	assert.Equal(t, severityHigh, resultSeverity(old))
+
+	recent := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	recent.Exposure = &detectors.Exposure{Days: 3}
+	assert.Equal(t, severityLow, resultSeverity(recent))
+}
+
+func TestResultSeverity_Reachability(t *testing.T) {
+	public := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	public.Reachability = reachability.Public
+	assert.Equal(t, severityMedium, resultSeverity(public))
+
+	internal := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	internal.Reachability = reachability.Internal
+	assert.Equal(t, severityLow, resultSeverity(internal))
+
+	// Public repositories aren't rated higher twice.
+	exposed := true
+	both := gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)
+	both.Reachability = reachability.Public
+	both.Exposure = &detectors.Exposure{Days: longExposureDays + 1, Public: &exposed}
+	assert.Equal(t, severityMedium, resultSeverity(both))
+}
+
+func TestResultSeverity_Variable(t *testing.T) {
+	named := gitResult("c1", ".env", "hunter22", false)
+	named.Variable = "DB_PASSWORD"
+	assert.Equal(t, severityMedium, resultSeverity(named))
+
+	setting := gitResult("c1", ".env", "hunter22", false)
+	setting.Variable = "DB_USER"
+	assert.Equal(t, severityLow, resultSeverity(setting))
+}
+
+func TestResultSeverity_Unverifiable(t *testing.T) {
+	signing := gitResult("c1", "config.yaml", "whsec_example", false)
+	signing.Unverifiable = true
+	assert.Equal(t, severityMedium, resultSeverity(signing))
+
+	signing.Categories = []classify.Category{classify.Test}
+	assert.Equal(t, severityLow, resultSeverity(signing))
+}
@@ -0,0 +1,250 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// PullRequestRef identifies a pull or merge request on a code host.
+type PullRequestRef struct {
+	// Repo is the full path of the repository, e.g. "owner/repo" or
+	// "group/subgroup/project".
+	Repo   string
+	Number int
+}
+
+// ParsePullRequestRef parses a reference of the form "owner/repo#123".
+func ParsePullRequestRef(s string) (PullRequestRef, error) {
+	repo, number, ok := strings.Cut(s, "#")
+	n, err := strconv.Atoi(number)
+	if !ok || err != nil || n <= 0 || !strings.Contains(repo, "/") {
+		return PullRequestRef{}, fmt.Errorf("invalid pull request %q: expected owner/repo#number", s)
+	}
+	return PullRequestRef{Repo: strings.Trim(repo, "/"), Number: n}, nil
+}
+
+// Owner returns the namespace the repository belongs to.
+func (r PullRequestRef) Owner() string {
+	if i := strings.LastIndex(r.Repo, "/"); i >= 0 {
+		return r.Repo[:i]
+	}
+	return ""
+}
+
+// Name returns the name of the repository without its namespace.
+func (r PullRequestRef) Name() string {
+	return r.Repo[strings.LastIndex(r.Repo, "/")+1:]
+}
+
+func (r PullRequestRef) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+// prFinding is a result placed on a line of a file in a pull request.
+type prFinding struct {
+	Path        string
+	Line        int
+	Detector    string
+	Verified    bool
+	Status      string
+	Preview     string
+	Fingerprint string
+}
+
+// commentMarker tags comments posted by TruffleHog with the finding they
+// belong to, so later runs can recognize their own comments.
+func (f prFinding) commentMarker() string {
+	return fmt.Sprintf("<!-- trufflehog:%s:%d -->", f.Fingerprint, f.Line)
+}
+
+func (f prFinding) commentBody() string {
+	return fmt.Sprintf("%s\n**TruffleHog found a %s %s secret** on this line: `%s`\n\n"+
+		"Remove the secret from the change and rotate it if it was ever valid.",
+		f.commentMarker(), f.Status, f.Detector, f.Preview)
+}
+
+var commentMarkerPat = regexp.MustCompile(`<!-- trufflehog:[0-9a-f]+:\d+ -->`)
+
+// postedComment is a comment TruffleHog posted on an earlier run.
+type postedComment struct {
+	ID     string
+	Marker string
+}
+
+// parseCommentMarker returns the marker of a comment previously posted by
+// TruffleHog, if body contains one.
+func parseCommentMarker(body string) (string, bool) {
+	m := commentMarkerPat.FindString(body)
+	return m, m != ""
+}
+
+// reconcileComments compares the findings of this run against the comments
+// posted by earlier runs. It returns the findings that don't have a comment
+// yet and the comments whose finding is no longer present, which can be
+// resolved.
+func reconcileComments(findings []prFinding, posted []postedComment) (toPost []prFinding, stale []postedComment) {
+	current := make(map[string]struct{}, len(findings))
+	for _, f := range findings {
+		current[f.commentMarker()] = struct{}{}
+	}
+	existing := make(map[string]struct{}, len(posted))
+	for _, c := range posted {
+		existing[c.Marker] = struct{}{}
+		if _, ok := current[c.Marker]; !ok {
+			stale = append(stale, c)
+		}
+	}
+	for _, f := range findings {
+		if _, ok := existing[f.commentMarker()]; !ok {
+			toPost = append(toPost, f)
+		}
+	}
+	return toPost, stale
+}
+
+// resolvedCommentBody is the reply added to a comment whose finding is no
+// longer present.
+const resolvedCommentBody = "TruffleHog no longer finds this secret in the latest changes."
+
+// prFindings collects the results of a scan that can be attributed to a line
+// of a file, dropping repeated reports of the same secret on the same line.
+type prFindings struct {
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	findings []prFinding
+}
+
+func (c *prFindings) add(r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	if loc.File == "" || loc.Line <= 0 {
+		return nil
+	}
+
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(r)
+	}
+	f := prFinding{
+		Path:        loc.File,
+		Line:        int(loc.Line),
+		Detector:    r.DetectorType.String(),
+		Verified:    r.Verified,
+		Status:      "unverified",
+		Preview:     SecretPreview(r),
+		Fingerprint: fingerprint,
+	}
+	if r.Verified {
+		f.Status = "verified"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	key := f.commentMarker()
+	if _, ok := c.seen[key]; ok {
+		return nil
+	}
+	c.seen[key] = struct{}{}
+	c.findings = append(c.findings, f)
+	return nil
+}
+
+// take returns the collected findings sorted by location and resets the
+// collector.
+func (c *prFindings) take() []prFinding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	findings := c.findings
+	c.findings, c.seen = nil, nil
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// partitionByDiff splits findings into those on lines added by the change,
+// which can carry inline comments, and the rest.
+func partitionByDiff(findings []prFinding, added map[string]map[int]bool) (inDiff, outside []prFinding) {
+	for _, f := range findings {
+		if added[f.Path][f.Line] {
+			inDiff = append(inDiff, f)
+		} else {
+			outside = append(outside, f)
+		}
+	}
+	return inDiff, outside
+}
+
+var hunkHeaderPat = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// addedLines returns the new-file line numbers of the lines added by a
+// unified diff patch.
+func addedLines(patch string) map[int]bool {
+	lines := make(map[int]bool)
+	line := 0
+	for _, l := range strings.Split(patch, "\n") {
+		if m := hunkHeaderPat.FindStringSubmatch(l); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if line == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			lines[line] = true
+			line++
+		case strings.HasPrefix(l, "-"), strings.HasPrefix(l, `\`):
+		default:
+			line++
+		}
+	}
+	return lines
+}
+
+// addedLinesByFile returns the lines added by a multi-file unified diff,
+// keyed by the new path of each file.
+func addedLinesByFile(diff string) map[string]map[int]bool {
+	added := make(map[string]map[int]bool)
+	for _, section := range strings.Split(diff, "\ndiff --git ") {
+		header, _, _ := strings.Cut(section, "\n@@")
+		var path string
+		for _, l := range strings.Split(header, "\n") {
+			if p, ok := strings.CutPrefix(l, "+++ b/"); ok {
+				path = p
+			}
+		}
+		if path != "" {
+			added[path] = addedLines(section)
+		}
+	}
+	return added
+}
+
+// reviewSummary renders the body of the top-level review comment.
+func reviewSummary(inDiff, outside []prFinding) string {
+	var b strings.Builder
+	total := len(inDiff) + len(outside)
+	fmt.Fprintf(&b, "**TruffleHog found %d potential secret(s) in this change.**\n", total)
+	if len(outside) > 0 {
+		b.WriteString("\nThe following findings are not on lines changed by this pull request:\n\n")
+		for _, f := range outside {
+			fmt.Fprintf(&b, "- `%s:%d`: %s %s secret `%s`\n", f.Path, f.Line, f.Status, f.Detector, f.Preview)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequestRef(t *testing.T) {
+	ref, err := ParsePullRequestRef("group/sub/project#42")
+	require.NoError(t, err)
+	assert.Equal(t, "group/sub", ref.Owner())
+	assert.Equal(t, "project", ref.Name())
+	assert.Equal(t, 42, ref.Number)
+
+	for _, bad := range []string{"repo#1", "owner/repo", "owner/repo#x", "owner/repo#0"} {
+		_, err := ParsePullRequestRef(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	patch := "@@ -1,3 +1,4 @@\n context\n-removed\n+added one\n+added two\n context\n@@ -10 +11,2 @@\n+late\n\\ No newline at end of file"
+	assert.Equal(t, map[int]bool{2: true, 3: true, 11: true}, addedLines(patch))
+}
+
+func TestAddedLinesByFile(t *testing.T) {
+	diff := "diff --git a/a.txt b/a.txt\nindex 1..2 100644\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1,2 @@\n keep\n+new\n" +
+		"diff --git a/gone.txt b/gone.txt\ndeleted file mode 100644\n--- a/gone.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n" +
+		"diff --git a/b.txt b/b.txt\nnew file mode 100644\n--- /dev/null\n+++ b/b.txt\n@@ -0,0 +1 @@\n+first\n"
+	assert.Equal(t, map[string]map[int]bool{
+		"a.txt": {2: true},
+		"b.txt": {1: true},
+	}, addedLinesByFile(diff))
+}
+
+func TestReconcileComments(t *testing.T) {
+	kept := prFinding{Path: "a.txt", Line: 1, Fingerprint: "aa"}
+	added := prFinding{Path: "a.txt", Line: 2, Fingerprint: "bb"}
+	removed := prFinding{Path: "b.txt", Line: 3, Fingerprint: "cc"}
+
+	body := "intro\n" + removed.commentBody()
+	marker, ok := parseCommentMarker(body)
+	require.True(t, ok)
+
+	toPost, stale := reconcileComments(
+		[]prFinding{kept, added},
+		[]postedComment{{ID: "1", Marker: kept.commentMarker()}, {ID: "2", Marker: marker}},
+	)
+	assert.Equal(t, []prFinding{added}, toPost)
+	assert.Equal(t, []postedComment{{ID: "2", Marker: removed.commentMarker()}}, stale)
+
+	_, ok = parseCommentMarker("an unrelated comment")
+	assert.False(t, ok)
+}
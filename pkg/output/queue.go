@@ -0,0 +1,209 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/segmentio/kafka-go"
+	"golang.org/x/oauth2/google"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// messagePublisher publishes messages to a message broker.
+type messagePublisher interface {
+	// Publish sends body as a single message. The key groups messages about
+	// the same finding where the broker supports it.
+	Publish(ctx context.Context, key string, body []byte) error
+	// Close delivers any buffered messages and releases the connection.
+	Close() error
+}
+
+// QueuePrinter is a printer that publishes every result as a JSON message to
+// a message broker: a Kafka topic, an AWS SQS queue, or a GCP Pub/Sub topic.
+// Messages are keyed by the result's fingerprint.
+type QueuePrinter struct {
+	dest      string
+	publisher messagePublisher
+}
+
+// NewQueuePrinter creates a QueuePrinter that publishes to dest, which is one
+// of:
+//
+//	kafka://broker1:9092,broker2:9092/topic
+//	sqs://sqs.us-east-1.amazonaws.com/123456789012/queue
+//	pubsub://project/topic
+func NewQueuePrinter(ctx context.Context, dest string) (*QueuePrinter, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("invalid message queue destination %q", dest)
+	}
+
+	var publisher messagePublisher
+	switch u.Scheme {
+	case "kafka":
+		publisher = newKafkaPublisher(strings.Split(u.Host, ","), strings.Trim(u.Path, "/"))
+	case "sqs":
+		publisher, err = newSQSPublisher("https://" + u.Host + u.Path)
+	case "pubsub":
+		publisher, err = newPubSubPublisher(ctx, u.Host, strings.Trim(u.Path, "/"))
+	default:
+		return nil, fmt.Errorf("unsupported message queue scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &QueuePrinter{dest: dest, publisher: publisher}, nil
+}
+
+func (p *QueuePrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	body, err := json.Marshal(newJSONResult(r))
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	key := r.Fingerprint
+	if key == "" {
+		key = Fingerprint(r)
+	}
+	if err := p.publisher.Publish(ctx, key, body); err != nil {
+		return fmt.Errorf("could not publish result to %s: %w", p.dest, err)
+	}
+	return nil
+}
+
+// Flush delivers any buffered messages and closes the connection.
+func (p *QueuePrinter) Flush(_ context.Context) error {
+	if err := p.publisher.Close(); err != nil {
+		return fmt.Errorf("could not publish results to %s: %w", p.dest, err)
+	}
+	return nil
+}
+
+// kafkaPublisher writes messages asynchronously, so failed deliveries are
+// only reported when the publisher is closed.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+
+	mu   sync.Mutex
+	errs []error
+}
+
+func newKafkaPublisher(brokers []string, topic string) *kafkaPublisher {
+	p := new(kafkaPublisher)
+	p.writer = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Async:        true,
+		Completion: func(_ []kafka.Message, err error) {
+			if err != nil {
+				p.mu.Lock()
+				p.errs = append(p.errs, err)
+				p.mu.Unlock()
+			}
+		},
+	}
+	return p
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, key string, body []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: body})
+}
+
+func (p *kafkaPublisher) Close() error {
+	err := p.writer.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(append(p.errs, err)...)
+}
+
+type sqsPublisher struct {
+	client   *sqs.SQS
+	queueURL string
+	fifo     bool
+}
+
+func newSQSPublisher(queueURL string) (*sqsPublisher, error) {
+	cfg := aws.NewConfig()
+	// Queue URLs name their region: https://sqs.<region>.amazonaws.com/...
+	if host := strings.Split(strings.TrimPrefix(queueURL, "https://"), "."); len(host) > 2 && host[0] == "sqs" {
+		cfg = cfg.WithRegion(host[1])
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", err)
+	}
+	return &sqsPublisher{
+		client:   sqs.New(sess),
+		queueURL: queueURL,
+		fifo:     strings.HasSuffix(queueURL, ".fifo"),
+	}, nil
+}
+
+func (p *sqsPublisher) Publish(ctx context.Context, key string, body []byte) error {
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(p.queueURL),
+		MessageBody: aws.String(string(body)),
+	}
+	if p.fifo {
+		// Group by finding so its occurrences stay ordered, and deduplicate
+		// on the full message so distinct occurrences aren't dropped.
+		sum := sha256.Sum256(body)
+		input.MessageGroupId = aws.String(key)
+		input.MessageDeduplicationId = aws.String(hex.EncodeToString(sum[:]))
+	}
+	_, err := p.client.SendMessageWithContext(ctx, input)
+	return err
+}
+
+func (p *sqsPublisher) Close() error { return nil }
+
+const pubSubScope = "https://www.googleapis.com/auth/pubsub"
+
+type pubSubPublisher struct {
+	client     *http.Client
+	publishURL string
+}
+
+func newPubSubPublisher(ctx context.Context, project, topic string) (*pubSubPublisher, error) {
+	client, err := google.DefaultClient(ctx, pubSubScope)
+	if err != nil {
+		return nil, fmt.Errorf("could not find Google Cloud credentials: %w", err)
+	}
+	return &pubSubPublisher{
+		client: client,
+		publishURL: fmt.Sprintf("https://pubsub.googleapis.com/v1/projects/%s/topics/%s:publish",
+			url.PathEscape(project), url.PathEscape(topic)),
+	}, nil
+}
+
+func (p *pubSubPublisher) Publish(ctx context.Context, key string, body []byte) error {
+	req, err := json.Marshal(map[string]any{
+		"messages": []map[string]any{{
+			"data":       base64.StdEncoding.EncodeToString(body),
+			"attributes": map[string]string{"fingerprint": key},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, p.client, p.publishURL, req, nil)
+}
+
+func (p *pubSubPublisher) Close() error { return nil }
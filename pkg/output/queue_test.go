@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+type recordingPublisher struct {
+	keys   []string
+	bodies [][]byte
+	closed bool
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, key string, body []byte) error {
+	p.keys = append(p.keys, key)
+	p.bodies = append(p.bodies, body)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestQueuePrinter(t *testing.T) {
+	ctx := context.Background()
+	pub := new(recordingPublisher)
+	p := &QueuePrinter{dest: "test://", publisher: pub}
+
+	r := gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)
+	require.NoError(t, p.Print(ctx, r))
+	require.NoError(t, p.Flush(ctx))
+
+	assert.Equal(t, []string{Fingerprint(r)}, pub.keys)
+	assert.True(t, pub.closed)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(pub.bodies[0], &got))
+	assert.Equal(t, "AWS", got["DetectorName"])
+}
+
+func TestNewQueuePrinter_InvalidDestination(t *testing.T) {
+	for _, dest := range []string{"kafka://broker:9092", "amqp://host/queue", "not a url"} {
+		_, err := NewQueuePrinter(context.Background(), dest)
+		assert.Error(t, err, dest)
+	}
+}
+
+func TestPubSubPublisher(t *testing.T) {
+	rcv := new(webhookReceiver)
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	p := &pubSubPublisher{client: srv.Client(), publishURL: srv.URL}
+	require.NoError(t, p.Publish(context.Background(), "fp", []byte(`{"a":1}`)))
+
+	var req struct {
+		Messages []struct {
+			Data       string
+			Attributes map[string]string
+		}
+	}
+	require.Len(t, rcv.bodies, 1)
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &req))
+	require.Len(t, req.Messages, 1)
+	data, err := base64.StdEncoding.DecodeString(req.Messages[0].Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1}`, string(data))
+	assert.Equal(t, "fp", req.Messages[0].Attributes["fingerprint"])
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// maskVisibleChars is the number of characters kept at each end of a masked
+// secret.
+const maskVisibleChars = 4
+
+// MaskSecret returns a display-safe form of a secret: its first and last few
+// characters and a short hash that can be used to correlate the same secret
+// across findings without revealing it. Short secrets are masked entirely.
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	hash := "sha256:" + hex.EncodeToString(sum[:])[:12]
+
+	if len(secret) <= maskVisibleChars*3 {
+		return "**** (" + hash + ")"
+	}
+	return secret[:maskVisibleChars] + "****" + secret[len(secret)-maskVisibleChars:] + " (" + hash + ")"
+}
+
+// resultPrinter mirrors engine.Printer, which can't be referenced here
+// without an import cycle.
+type resultPrinter interface {
+	Print(ctx context.Context, r *detectors.ResultWithMetadata) error
+}
+
+// RedactingPrinter wraps a printer and masks the raw secret values of every
+// result before printing it.
+type RedactingPrinter struct {
+	printer resultPrinter
+}
+
+// NewRedactingPrinter creates a RedactingPrinter that masks secrets before
+// handing results to printer.
+func NewRedactingPrinter(printer resultPrinter) *RedactingPrinter {
+	return &RedactingPrinter{printer: printer}
+}
+
+func (p *RedactingPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	redacted := *r
+	redacted.Raw = redactBytes(r.Raw)
+	redacted.RawV2 = redactBytes(r.RawV2)
+	// The chunk data surrounds the secret and isn't needed by any printer.
+	redacted.Data = nil
+	if r.Context != nil {
+		redacted.Context = redactContext(r.Context, r.Raw, r.RawV2)
+	}
+	return p.printer.Print(ctx, &redacted)
+}
+
+// Flush flushes the wrapped printer if it buffers its output.
+func (p *RedactingPrinter) Flush(ctx context.Context) error {
+	if f, ok := p.printer.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func redactBytes(raw []byte) []byte {
+	secret := strings.TrimSpace(string(raw))
+	if secret == "" {
+		return raw
+	}
+	return []byte(MaskSecret(secret))
+}
+
+// redactContext masks the parts of the secret of a result in the lines of its
+// context. The lines around it can hold the other parts of multi-part
+// secrets.
+func redactContext(c *detectors.LineContext, raw, rawV2 []byte) *detectors.LineContext {
+	id, full := strings.TrimSpace(string(raw)), strings.TrimSpace(string(rawV2))
+	parts := []string{id}
+	if rest, ok := strings.CutPrefix(full, id); ok && id != "" {
+		parts = append(parts, strings.TrimSpace(rest))
+	} else {
+		parts = append(parts, full)
+	}
+	mask := func(line string) string {
+		for _, part := range parts {
+			if part != "" {
+				line = strings.ReplaceAll(line, part, MaskSecret(part))
+			}
+		}
+		return line
+	}
+	redacted := &detectors.LineContext{Line: mask(c.Line)}
+	for _, line := range c.Before {
+		redacted.Before = append(redacted.Before, mask(line))
+	}
+	for _, line := range c.After {
+		redacted.After = append(redacted.After, mask(line))
+	}
+	return redacted
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+func TestRedactingPrinterContext(t *testing.T) {
+	rec := new(recordingPrinter)
+	r := gitResult("abc123", "config.yaml", "AKIAEXAMPLEKEY", true)
+	r.RawV2 = []byte("AKIAEXAMPLEKEYwJalrXUtnFEMIK7MDENG")
+	r.Data = []byte("chunk")
+	r.Context = &detectors.LineContext{
+		Before: []string{"[default]"},
+		Line:   "aws_access_key_id = AKIAEXAMPLEKEY",
+		After:  []string{"aws_secret_access_key = wJalrXUtnFEMIK7MDENG"},
+	}
+	require.NoError(t, NewRedactingPrinter(rec).Print(context.Background(), r))
+
+	require.Len(t, rec.results, 1)
+	got := rec.results[0]
+	assert.Nil(t, got.Data)
+	assert.Equal(t, []string{"[default]"}, got.Context.Before)
+	assert.Equal(t, "aws_access_key_id = "+MaskSecret("AKIAEXAMPLEKEY"), got.Context.Line)
+	assert.Equal(t, []string{"aws_secret_access_key = " + MaskSecret("wJalrXUtnFEMIK7MDENG")}, got.Context.After)
+	assert.Equal(t, "aws_access_key_id = AKIAEXAMPLEKEY", r.Context.Line, "the original result should not be modified")
+}
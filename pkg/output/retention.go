@@ -0,0 +1,157 @@
+package output
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// RetentionPolicy is how long the DatabasePrinter keeps what it records, so
+// that secret material isn't stored for longer than it's needed.
+type RetentionPolicy struct {
+	// Remediated deletes findings that no run has found for this long,
+	// which are then considered remediated. 0 keeps them.
+	Remediated time.Duration
+	// Runs deletes scan runs that started this long ago, with the findings
+	// and statistics recorded for them, but not the findings themselves.
+	// Runs a finding was first or last seen in are kept. 0 keeps them.
+	Runs time.Duration
+	// HashSecrets replaces the secrets already stored with their hashes.
+	HashSecrets bool
+}
+
+// PurgeResult counts what a purge deleted or hashed.
+type PurgeResult struct {
+	Findings      int64 `json:"findings"`
+	Runs          int64 `json:"runs"`
+	HashedSecrets int64 `json:"hashed_secrets"`
+}
+
+// hashedSecretPrefix marks secrets stored as hashes.
+const hashedSecretPrefix = "sha256:"
+
+// hashSecret returns the hash stored in place of a secret in hash-only mode.
+// It still lets a leaked secret be matched against the findings, without
+// the database holding the secret.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hashedSecretPrefix + hex.EncodeToString(sum[:])
+}
+
+const (
+	purgeFindingRunsQuery = `DELETE FROM finding_runs WHERE fingerprint IN (SELECT fingerprint FROM findings WHERE last_seen_at < $1)`
+	purgeFindingsQuery    = `DELETE FROM findings WHERE last_seen_at < $1`
+
+	purgeRunFindingsQuery = `DELETE FROM finding_runs WHERE run_id IN (SELECT id FROM scan_runs WHERE started_at < $1)`
+	purgeRunStatsQuery    = `DELETE FROM repo_stats WHERE run_id IN (SELECT id FROM scan_runs WHERE started_at < $1)`
+	purgeRunsQuery        = `DELETE FROM scan_runs WHERE started_at < $1
+AND NOT EXISTS (SELECT 1 FROM findings WHERE first_seen_run = scan_runs.id OR last_seen_run = scan_runs.id)`
+
+	selectUnhashedSecretsQuery = `SELECT fingerprint, secret FROM findings WHERE secret NOT LIKE '` + hashedSecretPrefix + `%'`
+	updateSecretQuery          = `UPDATE findings SET secret = $1 WHERE fingerprint = $2`
+)
+
+// PurgeDatabase applies policy to the database at dsn.
+func PurgeDatabase(ctx context.Context, dsn string, policy RetentionPolicy) (PurgeResult, error) {
+	db, rebind, err := openDatabase(ctx, dsn)
+	if err != nil {
+		return PurgeResult{}, err
+	}
+	defer db.Close()
+	return purge(ctx, db, rebind, policy, time.Now().UTC())
+}
+
+func purge(ctx context.Context, db *sql.DB, rebind func(string) string, policy RetentionPolicy, now time.Time) (PurgeResult, error) {
+	var result PurgeResult
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("could not purge database: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if policy.Remediated > 0 {
+		cutoff := now.Add(-policy.Remediated)
+		if _, err := tx.ExecContext(ctx, rebind(purgeFindingRunsQuery), cutoff); err != nil {
+			return result, fmt.Errorf("could not purge remediated findings: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, rebind(purgeFindingsQuery), cutoff)
+		if err != nil {
+			return result, fmt.Errorf("could not purge remediated findings: %w", err)
+		}
+		result.Findings, _ = res.RowsAffected()
+	}
+	if policy.Runs > 0 {
+		cutoff := now.Add(-policy.Runs)
+		for _, query := range []string{purgeRunFindingsQuery, purgeRunStatsQuery} {
+			if _, err := tx.ExecContext(ctx, rebind(query), cutoff); err != nil {
+				return result, fmt.Errorf("could not purge scan runs: %w", err)
+			}
+		}
+		res, err := tx.ExecContext(ctx, rebind(purgeRunsQuery), cutoff)
+		if err != nil {
+			return result, fmt.Errorf("could not purge scan runs: %w", err)
+		}
+		result.Runs, _ = res.RowsAffected()
+	}
+	if policy.HashSecrets {
+		if result.HashedSecrets, err = hashStoredSecrets(ctx, tx, rebind); err != nil {
+			return result, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("could not purge database: %w", err)
+	}
+	return result, nil
+}
+
+// hashStoredSecrets replaces the stored secrets that aren't hashes yet with
+// their hashes.
+func hashStoredSecrets(ctx context.Context, tx *sql.Tx, rebind func(string) string) (int64, error) {
+	rows, err := tx.QueryContext(ctx, selectUnhashedSecretsQuery)
+	if err != nil {
+		return 0, fmt.Errorf("could not hash secrets: %w", err)
+	}
+	secrets := make(map[string]string)
+	for rows.Next() {
+		var fingerprint, secret string
+		if err := rows.Scan(&fingerprint, &secret); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("could not hash secrets: %w", err)
+		}
+		secrets[fingerprint] = secret
+	}
+	// SQLite has a single connection, so the rows are read before updating.
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("could not hash secrets: %w", err)
+	}
+	for fingerprint, secret := range secrets {
+		if _, err := tx.ExecContext(ctx, rebind(updateSecretQuery), hashSecret(secret), fingerprint); err != nil {
+			return 0, fmt.Errorf("could not hash secrets: %w", err)
+		}
+	}
+	return int64(len(secrets)), nil
+}
+
+// ParseRetention parses a retention period, either as a number of days such
+// as "90d" or as a Go duration such as "36h".
+func ParseRetention(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid retention %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid retention %q", s)
+	}
+	return d, nil
+}
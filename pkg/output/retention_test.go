@@ -0,0 +1,98 @@
+package output
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestDatabasePrinter_HashedSecrets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+	p, err := NewDatabasePrinter(ctx, path, WithHashedSecrets())
+	require.NoError(t, err)
+	result := gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)
+	result.Redacted = "AKIA****"
+	require.NoError(t, p.Print(ctx, result))
+	require.NoError(t, p.Flush(ctx))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var secret string
+	require.NoError(t, db.QueryRow(`SELECT secret FROM findings`).Scan(&secret))
+	assert.Equal(t, hashSecret("AKIAEXAMPLE"), secret)
+}
+
+func TestPurgeDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "findings.db")
+
+	p, err := NewDatabasePrinter(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, p.Print(ctx, gitResult("c1", "old.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Flush(ctx))
+	p, err = NewDatabasePrinter(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, p.Print(ctx, gitResult("c2", "config.yaml", "AKIAEXAMPLE", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	// Backdate the first run and the finding only it found.
+	longAgo := time.Now().UTC().Add(-100 * 24 * time.Hour)
+	var firstRun string
+	require.NoError(t, db.QueryRow(`SELECT id FROM scan_runs ORDER BY started_at LIMIT 1`).Scan(&firstRun))
+	_, err = db.Exec(`UPDATE scan_runs SET started_at = ? WHERE id = ?`, longAgo, firstRun)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE findings SET last_seen_at = ? WHERE file = 'old.yaml'`, longAgo)
+	require.NoError(t, err)
+
+	result, err := PurgeDatabase(ctx, path, RetentionPolicy{Remediated: 90 * 24 * time.Hour, HashSecrets: true})
+	require.NoError(t, err)
+	assert.Equal(t, PurgeResult{Findings: 1, HashedSecrets: 1}, result)
+	var files []string
+	rows, err := db.Query(`SELECT file FROM findings`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var file string
+		require.NoError(t, rows.Scan(&file))
+		files = append(files, file)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"config.yaml"}, files)
+
+	// The first run is kept while the remaining finding was first seen in it.
+	result, err = PurgeDatabase(ctx, path, RetentionPolicy{Runs: 90 * 24 * time.Hour, HashSecrets: true})
+	require.NoError(t, err)
+	assert.Equal(t, PurgeResult{}, result)
+	var runFindings int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM finding_runs WHERE run_id = ?`, firstRun).Scan(&runFindings))
+	assert.Zero(t, runFindings)
+
+	_, err = db.Exec(`UPDATE findings SET first_seen_run = last_seen_run`)
+	require.NoError(t, err)
+	result, err = PurgeDatabase(ctx, path, RetentionPolicy{Runs: 90 * 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, PurgeResult{Runs: 1}, result)
+}
+
+func TestParseRetention(t *testing.T) {
+	for s, want := range map[string]time.Duration{"90d": 90 * 24 * time.Hour, "36h": 36 * time.Hour, "0": 0} {
+		got, err := ParseRetention(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	for _, s := range []string{"", "d", "-1d", "soon"} {
+		_, err := ParseRetention(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,221 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "TruffleHog"
+	toolInfoURI  = "https://github.com/trufflesecurity/trufflehog"
+)
+
+// SARIFPrinter is a printer that collects results and writes them as a single
+// SARIF 2.1.0 document once the scan has finished. Each detector is reported
+// as a rule, so the output can be uploaded directly to GitHub code scanning.
+type SARIFPrinter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	rules   map[string]sarifRule
+	results []sarifResult
+}
+
+// NewSARIFPrinter creates a SARIFPrinter that writes to out. If out is nil,
+// os.Stdout is used.
+func NewSARIFPrinter(out io.Writer) *SARIFPrinter {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &SARIFPrinter{out: out, rules: make(map[string]sarifRule)}
+}
+
+func (p *SARIFPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	ruleID := r.DetectorType.String()
+	status := "unverified"
+	level := "warning"
+	if r.Verified {
+		status = "verified"
+		level = "error"
+	}
+
+	res := sarifResult{
+		RuleID: ruleID,
+		Level:  level,
+		Message: sarifMessage{
+			Text: fmt.Sprintf("Found %s %s secret", status, ruleID),
+		},
+		Properties: map[string]any{
+			"verified": r.Verified,
+			"decoder":  r.DecoderType.String(),
+			"source":   r.SourceName,
+		},
+	}
+	if r.Redacted != "" {
+		res.Properties["redacted"] = r.Redacted
+	}
+	if r.Fingerprint != "" {
+		res.PartialFingerprints = map[string]string{"trufflehog/v1": r.Fingerprint}
+	}
+	if len(r.Occurrences) > 1 {
+		res.Properties["occurrences"] = len(r.Occurrences)
+	}
+	if verErr := r.VerificationError(); verErr != nil {
+		res.Properties["verificationError"] = verErr.Error()
+	}
+
+	var location sarifLocation
+	if loc.File != "" {
+		location.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: loc.File},
+		}
+		// SARIF lines are 1-based; consumers reject a region with a line of 0.
+		if loc.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: loc.Line}
+		}
+	}
+	if loc.Commit != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{Name: loc.Commit, Kind: "commit"}}
+	}
+	if location.PhysicalLocation != nil || len(location.LogicalLocations) > 0 {
+		res.Locations = []sarifLocation{location}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.rules[ruleID]; !ok {
+		p.rules[ruleID] = sarifRule{
+			ID:   ruleID,
+			Name: ruleID,
+			ShortDescription: sarifMessage{
+				Text: fmt.Sprintf("%s secret", ruleID),
+			},
+			HelpURI: toolInfoURI,
+		}
+	}
+	p.results = append(p.results, res)
+	return nil
+}
+
+// Flush writes the SARIF document containing every result printed so far.
+func (p *SARIFPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rules := make([]sarifRule, 0, len(p.rules))
+	for _, rule := range p.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+	}
+	results := make([]sarifResult, len(p.results))
+	for i, res := range p.results {
+		res.RuleIndex = ruleIndex[res.RuleID]
+		results[i] = res
+	}
+
+	doc := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				Version:        version.BuildVersion,
+				InformationURI: toolInfoURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(p.out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("could not write SARIF output: %w", err)
+	}
+	return nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name,omitempty"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int64 `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestSARIFPrinter(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	p := NewSARIFPrinter(&buf)
+
+	gitResult := &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{
+				Commit: "abc123",
+				File:   "config/secrets.yaml",
+				Line:   42,
+			}},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_AWS, Verified: true},
+	}
+	fsResult := &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{
+				File: "main.go",
+			}},
+		},
+		Result: detectors.Result{DetectorType: detectorspb.DetectorType_Github},
+	}
+	require.NoError(t, p.Print(ctx, gitResult))
+	require.NoError(t, p.Print(ctx, fsResult))
+	require.NoError(t, p.Flush(ctx))
+
+	var doc sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, sarifVersion, doc.Version)
+	require.Len(t, doc.Runs, 1)
+
+	run := doc.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "AWS", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "Github", run.Tool.Driver.Rules[1].ID)
+
+	require.Len(t, run.Results, 2)
+	aws := run.Results[0]
+	assert.Equal(t, "error", aws.Level)
+	assert.Equal(t, 0, aws.RuleIndex)
+	require.Len(t, aws.Locations, 1)
+	assert.Equal(t, "config/secrets.yaml", aws.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, int64(42), aws.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "abc123", aws.Locations[0].LogicalLocations[0].Name)
+
+	gh := run.Results[1]
+	assert.Equal(t, "warning", gh.Level)
+	assert.Equal(t, 1, gh.RuleIndex)
+	assert.Nil(t, gh.Locations[0].PhysicalLocation.Region)
+	assert.Empty(t, gh.Locations[0].LogicalLocations)
+}
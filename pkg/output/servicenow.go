@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	serviceNowCorrelationPrefix = "trufflehog:"
+	serviceNowDisplay           = "TruffleHog"
+	serviceNowNotFoundDisplay   = "TruffleHog: not found"
+)
+
+type serviceNowTracker struct {
+	client   *http.Client
+	auth     func(*http.Request)
+	tableURL string
+}
+
+// NewServiceNowPrinter creates a TicketPrinter that opens incidents in a
+// ServiceNow instance. Incidents are matched to findings by their correlation
+// ID, so the detector is recorded in the incident's category.
+func NewServiceNowPrinter(endpoint, token, summaryTemplate string) (*TicketPrinter, error) {
+	return newTicketPrinter(&serviceNowTracker{
+		client:   common.RetryableHTTPClient(),
+		auth:     tokenAuth(token),
+		tableURL: strings.TrimSuffix(endpoint, "/") + "/api/now/table/incident",
+	}, summaryTemplate)
+}
+
+func serviceNowCorrelationID(repo, fingerprint string) string {
+	return serviceNowCorrelationPrefix + repoKey(repo) + ":" + fingerprint
+}
+
+func (s *serviceNowTracker) openTickets(ctx context.Context, repo string) ([]ticket, error) {
+	prefix := serviceNowCorrelationID(repo, "")
+	query := url.Values{
+		"sysparm_query":  {"active=true^correlation_idSTARTSWITH" + prefix},
+		"sysparm_fields": {"sys_id,correlation_id,correlation_display"},
+	}
+	body, err := doRequest(ctx, s.client, s.auth, http.MethodGet, s.tableURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not list ServiceNow incidents: %w", err)
+	}
+	var resp struct {
+		Result []struct {
+			SysID              string `json:"sys_id"`
+			CorrelationID      string `json:"correlation_id"`
+			CorrelationDisplay string `json:"correlation_display"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("could not decode ServiceNow incidents: %w", err)
+	}
+
+	tickets := make([]ticket, 0, len(resp.Result))
+	for _, incident := range resp.Result {
+		tickets = append(tickets, ticket{
+			ID:          incident.SysID,
+			Fingerprint: strings.TrimPrefix(incident.CorrelationID, prefix),
+			NotFound:    incident.CorrelationDisplay == serviceNowNotFoundDisplay,
+		})
+	}
+	return tickets, nil
+}
+
+func (s *serviceNowTracker) createTicket(ctx context.Context, f ticketFinding, summary string) error {
+	body, err := json.Marshal(map[string]string{
+		"short_description":   summary,
+		"description":         f.description(),
+		"category":            "security",
+		"subcategory":         strings.ToLower(f.Detector),
+		"correlation_id":      serviceNowCorrelationID(f.Repository, f.Fingerprint),
+		"correlation_display": serviceNowDisplay,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, s.client, s.auth, http.MethodPost, s.tableURL, body); err != nil {
+		return fmt.Errorf("could not create ServiceNow incident: %w", err)
+	}
+	return nil
+}
+
+func (s *serviceNowTracker) markNotFound(ctx context.Context, t ticket, comment string) error {
+	body, err := json.Marshal(map[string]string{
+		"comments":            comment,
+		"correlation_display": serviceNowNotFoundDisplay,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := doRequest(ctx, s.client, s.auth, http.MethodPatch, s.tableURL+"/"+url.PathEscape(t.ID), body); err != nil {
+		return fmt.Errorf("could not update ServiceNow incident %s: %w", t.ID, err)
+	}
+	return nil
+}
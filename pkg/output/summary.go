@@ -0,0 +1,185 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+// ScanStats are the scan-wide numbers reported by the engine and sources
+// that are included in a scan summary.
+type ScanStats struct {
+	ChunksScanned uint64
+	BytesScanned  uint64
+	Duration      time.Duration
+	Errors        uint64
+	ExitCode      int
+	// Interrupted is set when the scan was stopped before finishing.
+	Interrupted bool
+}
+
+// verificationCounts counts results by their verification state.
+type verificationCounts struct {
+	Total      int `json:"total"`
+	Verified   int `json:"verified"`
+	Unknown    int `json:"unknown"`
+	Unverified int `json:"unverified"`
+}
+
+func (c *verificationCounts) add(r *detectors.ResultWithMetadata) {
+	c.Total++
+	switch resultSeverity(r) {
+	case severityHigh:
+		c.Verified++
+	case severityMedium:
+		c.Unknown++
+	default:
+		c.Unverified++
+	}
+}
+
+// scanSummary is the machine-readable summary of a finished scan.
+type scanSummary struct {
+	Version         string  `json:"version"`
+	ChunksScanned   uint64  `json:"chunks_scanned"`
+	BytesScanned    uint64  `json:"bytes_scanned"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Errors          uint64  `json:"errors"`
+	ExitCode        int     `json:"exit_code"`
+	Interrupted     bool    `json:"interrupted"`
+	findingsSummary
+	// Groups summarizes the findings of every repository or owner when the
+	// summary is grouped.
+	Groups map[string]*findingsSummary `json:"groups,omitempty"`
+}
+
+// findingsSummary counts the findings of a scan, or of a group of them.
+type findingsSummary struct {
+	Findings                 verificationCounts             `json:"findings"`
+	ByDetector               map[string]*verificationCounts `json:"by_detector"`
+	RepositoriesWithFindings []string                       `json:"repositories_with_findings"`
+}
+
+// findingsTally tallies results by detector, verification state, and
+// repository.
+type findingsTally struct {
+	findings   verificationCounts
+	byDetector map[string]*verificationCounts
+	repos      map[string]struct{}
+}
+
+func newFindingsTally() *findingsTally {
+	return &findingsTally{
+		byDetector: make(map[string]*verificationCounts),
+		repos:      make(map[string]struct{}),
+	}
+}
+
+func (t *findingsTally) add(r *detectors.ResultWithMetadata, repo string) {
+	t.findings.add(r)
+	detector := r.DetectorType.String()
+	if t.byDetector[detector] == nil {
+		t.byDetector[detector] = new(verificationCounts)
+	}
+	t.byDetector[detector].add(r)
+	if repo != "" {
+		t.repos[repo] = struct{}{}
+	}
+}
+
+func (t *findingsTally) summary() findingsSummary {
+	repos := make([]string, 0, len(t.repos))
+	for repo := range t.repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return findingsSummary{Findings: t.findings, ByDetector: t.byDetector, RepositoriesWithFindings: repos}
+}
+
+// SummaryPrinter is a printer that tallies results by detector, verification
+// state, and repository so a summary of the scan can be written once it has
+// finished. It doesn't print individual results.
+type SummaryPrinter struct {
+	groupBy string
+
+	mu     sync.Mutex
+	total  *findingsTally
+	groups map[string]*findingsTally
+}
+
+// SummaryOption configures a SummaryPrinter.
+type SummaryOption func(*SummaryPrinter)
+
+// WithSummaryGroups also tallies the results of every group when results are
+// grouped by, such as by GroupByRepository.
+func WithSummaryGroups(by string) SummaryOption {
+	return func(p *SummaryPrinter) { p.groupBy = by }
+}
+
+// NewSummaryPrinter creates an empty SummaryPrinter.
+func NewSummaryPrinter(opts ...SummaryOption) *SummaryPrinter {
+	p := &SummaryPrinter{total: newFindingsTally(), groups: make(map[string]*findingsTally)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *SummaryPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	repo := loc.Repository
+	if repo == "" {
+		repo = r.SourceName
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total.add(r, repo)
+	for _, group := range ResultGroups(r, p.groupBy) {
+		if p.groups[group] == nil {
+			p.groups[group] = newFindingsTally()
+		}
+		p.groups[group].add(r, repo)
+	}
+	return nil
+}
+
+// WriteSummary writes the summary of the scan as indented JSON to w.
+func (p *SummaryPrinter) WriteSummary(w io.Writer, stats ScanStats) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	summary := scanSummary{
+		Version:         version.BuildVersion,
+		ChunksScanned:   stats.ChunksScanned,
+		BytesScanned:    stats.BytesScanned,
+		DurationSeconds: stats.Duration.Seconds(),
+		Errors:          stats.Errors,
+		ExitCode:        stats.ExitCode,
+		Interrupted:     stats.Interrupted,
+		findingsSummary: p.total.summary(),
+	}
+	if p.groupBy != "" {
+		summary.Groups = make(map[string]*findingsSummary, len(p.groups))
+		for group, tally := range p.groups {
+			s := tally.summary()
+			summary.Groups[group] = &s
+		}
+	}
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal scan summary: %w", err)
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestSummaryPrinter(t *testing.T) {
+	ctx := context.Background()
+	p := NewSummaryPrinter()
+
+	unknown := gitResult("c1", "b.yaml", "AKIAUNKNOWN", false)
+	unknown.SetVerificationError(errors.New("timeout"))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "a.yaml", "AKIAEXAMPLE", true)))
+	require.NoError(t, p.Print(ctx, unknown))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "c.yaml", "AKIAOTHER", false)))
+
+	var out bytes.Buffer
+	require.NoError(t, p.WriteSummary(&out, ScanStats{ChunksScanned: 10, BytesScanned: 2048, Duration: 1500 * time.Millisecond, Errors: 2, ExitCode: 183, Interrupted: true}))
+
+	var got scanSummary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, uint64(10), got.ChunksScanned)
+	assert.Equal(t, uint64(2048), got.BytesScanned)
+	assert.Equal(t, 1.5, got.DurationSeconds)
+	assert.Equal(t, uint64(2), got.Errors)
+	assert.Equal(t, 183, got.ExitCode)
+	assert.True(t, got.Interrupted)
+	want := verificationCounts{Total: 3, Verified: 1, Unknown: 1, Unverified: 1}
+	assert.Equal(t, want, got.Findings)
+	assert.Equal(t, map[string]*verificationCounts{"AWS": &want}, got.ByDetector)
+	assert.Equal(t, []string{"https://github.com/org/repo.git"}, got.RepositoriesWithFindings)
+}
+
+func TestSummaryPrinter_Groups(t *testing.T) {
+	ctx := context.Background()
+	p := NewSummaryPrinter(WithSummaryGroups(GroupByOwner))
+
+	owned := gitResult("c1", "a.yaml", "AKIAEXAMPLE", true)
+	owned.Owners = []string{"payments", "platform"}
+	require.NoError(t, p.Print(ctx, owned))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "b.yaml", "AKIAOTHER", false)))
+
+	var out bytes.Buffer
+	require.NoError(t, p.WriteSummary(&out, ScanStats{}))
+	var got scanSummary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, 2, got.Findings.Total)
+	require.Len(t, got.Groups, 3)
+	assert.Equal(t, verificationCounts{Total: 1, Verified: 1}, got.Groups["payments"].Findings)
+	assert.Equal(t, verificationCounts{Total: 1, Verified: 1}, got.Groups["platform"].Findings)
+	assert.Equal(t, verificationCounts{Total: 1, Unverified: 1}, got.Groups["unowned"].Findings)
+	assert.Equal(t, []string{"https://github.com/org/repo.git"}, got.Groups["unowned"].RepositoriesWithFindings)
+
+	// Summaries that aren't grouped have no groups.
+	out.Reset()
+	require.NoError(t, NewSummaryPrinter().WriteSummary(&out, ScanStats{}))
+	assert.NotContains(t, out.String(), "groups")
+}
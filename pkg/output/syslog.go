@@ -0,0 +1,254 @@
+package output
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+// SIEM record formats supported by SyslogPrinter.
+const (
+	SyslogFormatCEF  = "cef"
+	SyslogFormatLEEF = "leef"
+)
+
+const (
+	siemVendor  = "Truffle Security"
+	siemProduct = "TruffleHog"
+
+	// syslogFacility is the "security/authorization" facility.
+	syslogFacility = 4
+	syslogTimeout  = 10 * time.Second
+)
+
+// SyslogPrinter is a printer that sends every result as a CEF or LEEF record
+// over syslog (RFC 5424) to a SIEM. UDP sends one record per datagram, while
+// TCP and TLS separate records with newlines.
+type SyslogPrinter struct {
+	network  string
+	addr     string
+	format   string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogPrinter creates a SyslogPrinter that sends records in format to
+// dest, a udp://, tcp:// or tls:// host:port URL.
+func NewSyslogPrinter(dest, format string) (*SyslogPrinter, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid syslog destination %q: expected udp://, tcp://, or tls://host:port", dest)
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("unsupported syslog transport %q", u.Scheme)
+	}
+	switch format {
+	case SyslogFormatCEF, SyslogFormatLEEF:
+	default:
+		return nil, fmt.Errorf("unsupported SIEM format %q", format)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	p := &SyslogPrinter{network: u.Scheme, addr: u.Host, format: format, hostname: hostname}
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *SyslogPrinter) connect() error {
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+	var err error
+	if p.network == "tls" {
+		p.conn, err = tls.DialWithDialer(dialer, "tcp", p.addr, nil)
+	} else {
+		p.conn, err = dialer.Dial(p.network, p.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("could not connect to syslog server %s: %w", p.addr, err)
+	}
+	return nil
+}
+
+func (p *SyslogPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	var record string
+	if p.format == SyslogFormatLEEF {
+		record = leefRecord(r, loc)
+	} else {
+		record = cefRecord(r, loc)
+	}
+	msg := p.syslogMessage(r, record)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.write(msg); err != nil {
+		// Stream connections may have been closed by the server; reconnect
+		// once before giving up on the record.
+		if p.network == "udp" {
+			return fmt.Errorf("could not send syslog record: %w", err)
+		}
+		p.conn.Close()
+		if err := p.connect(); err != nil {
+			return err
+		}
+		if _, err := p.write(msg); err != nil {
+			return fmt.Errorf("could not send syslog record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *SyslogPrinter) write(msg string) (int, error) {
+	_ = p.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+	if p.network != "udp" {
+		msg += "\n"
+	}
+	return p.conn.Write([]byte(msg))
+}
+
+// Flush closes the connection to the syslog server.
+func (p *SyslogPrinter) Flush(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conn.Close()
+}
+
+// syslogMessage wraps a record in an RFC 5424 header.
+func (p *SyslogPrinter) syslogMessage(r *detectors.ResultWithMetadata, record string) string {
+	// Map the finding to a syslog severity: 2 (critical) for live
+	// credentials, 4 (warning) otherwise.
+	severity := 4
+	if r.Verified {
+		severity = 2
+	}
+	return fmt.Sprintf("<%d>1 %s %s trufflehog %d finding - %s",
+		syslogFacility*8+severity, time.Now().UTC().Format(time.RFC3339), p.hostname, os.Getpid(), record)
+}
+
+// siemSeverity maps a result to the 0-10 severity scale used by CEF and LEEF.
+func siemSeverity(r *detectors.ResultWithMetadata) int {
+	switch resultSeverity(r) {
+	case severityHigh:
+		return 10
+	case severityMedium:
+		return 6
+	default:
+		return 3
+	}
+}
+
+func siemOutcome(r *detectors.ResultWithMetadata) string {
+	switch resultSeverity(r) {
+	case severityHigh:
+		return "verified"
+	case severityMedium:
+		return "unknown"
+	default:
+		return "unverified"
+	}
+}
+
+func siemLine(loc resultLocation) string {
+	if loc.Line <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(loc.Line, 10)
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+// cefRecord renders a result in ArcSight Common Event Format.
+func cefRecord(r *detectors.ResultWithMetadata, loc resultLocation) string {
+	detector := r.DetectorType.String()
+	header := []string{
+		"CEF:0",
+		siemVendor,
+		siemProduct,
+		version.BuildVersion,
+		strconv.Itoa(int(r.DetectorType)),
+		fmt.Sprintf("%s %s secret", siemOutcome(r), detector),
+		strconv.Itoa(siemSeverity(r)),
+	}
+	for i := range header[1:] {
+		header[i+1] = cefHeaderEscaper.Replace(header[i+1])
+	}
+
+	ext := []string{
+		"cat", "secret",
+		"outcome", siemOutcome(r),
+		"cs1Label", "detector", "cs1", detector,
+		"cs2Label", "repository", "cs2", loc.Repository,
+		"cs3Label", "commit", "cs3", loc.Commit,
+		"cs4Label", "fingerprint", "cs4", r.Fingerprint,
+		"fname", loc.File,
+		"cn1Label", "line", "cn1", siemLine(loc),
+		"suser", loc.Email,
+		"request", reportLink(loc),
+		"msg", SecretPreview(r),
+		"sourceServiceName", r.SourceName,
+	}
+	var pairs []string
+	for i := 0; i < len(ext); i += 2 {
+		if ext[i+1] == "" {
+			continue
+		}
+		pairs = append(pairs, ext[i]+"="+cefExtensionEscaper.Replace(ext[i+1]))
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(pairs, " ")
+}
+
+// leefRecord renders a result in IBM QRadar Log Event Extended Format 1.0.
+func leefRecord(r *detectors.ResultWithMetadata, loc resultLocation) string {
+	detector := r.DetectorType.String()
+	header := strings.Join([]string{"LEEF:1.0", siemVendor, siemProduct, version.BuildVersion, detector}, "|")
+
+	attrs := []string{
+		"cat", "secret",
+		"sev", strconv.Itoa(siemSeverity(r)),
+		"outcome", siemOutcome(r),
+		"detector", detector,
+		"repository", loc.Repository,
+		"commit", loc.Commit,
+		"fingerprint", r.Fingerprint,
+		"resource", loc.File,
+		"line", siemLine(loc),
+		"usrName", loc.Email,
+		"url", reportLink(loc),
+		"secret", SecretPreview(r),
+		"source", r.SourceName,
+	}
+	var pairs []string
+	for i := 0; i < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		pairs = append(pairs, attrs[i]+"="+leefValueEscaper.Replace(attrs[i+1]))
+	}
+	return header + "|" + strings.Join(pairs, "\t")
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestCEFRecord(t *testing.T) {
+	r := gitResult("c1", "a|b=c.yaml", "AKIAEXAMPLESECRET", true)
+	r.Fingerprint = "fp"
+	loc, err := extractLocation(r.SourceMetadata)
+	require.NoError(t, err)
+
+	record := cefRecord(r, loc)
+	assert.True(t, strings.HasPrefix(record, "CEF:0|Truffle Security|TruffleHog|"), record)
+	assert.Contains(t, record, "|verified AWS secret|10|")
+	assert.Contains(t, record, `fname=a|b\=c.yaml`)
+	assert.Contains(t, record, "cs3=c1")
+	assert.Contains(t, record, "cn1=1")
+	assert.NotContains(t, record, "AKIAEXAMPLESECRET")
+}
+
+func TestLEEFRecord(t *testing.T) {
+	r := gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", false)
+	loc, err := extractLocation(r.SourceMetadata)
+	require.NoError(t, err)
+
+	record := leefRecord(r, loc)
+	header, attrs, ok := strings.Cut(record, "|AWS|")
+	require.True(t, ok, record)
+	assert.True(t, strings.HasPrefix(header, "LEEF:1.0|Truffle Security|TruffleHog|"))
+	assert.Contains(t, strings.Split(attrs, "\t"), "sev=3")
+	assert.Contains(t, strings.Split(attrs, "\t"), "resource=config.yaml")
+}
+
+func TestSyslogPrinter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		lines <- line
+	}()
+
+	p, err := NewSyslogPrinter("tcp://"+ln.Addr().String(), SyslogFormatCEF)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLESECRET", true)))
+	require.NoError(t, p.Flush(ctx))
+
+	line := <-lines
+	assert.True(t, strings.HasPrefix(line, "<34>1 "), line)
+	assert.Contains(t, line, " trufflehog ")
+	assert.Contains(t, line, "CEF:0|")
+}
+
+func TestNewSyslogPrinter_Invalid(t *testing.T) {
+	_, err := NewSyslogPrinter("http://localhost:514", SyslogFormatCEF)
+	assert.Error(t, err)
+	_, err = NewSyslogPrinter("udp://localhost:514", "json")
+	assert.Error(t, err)
+}
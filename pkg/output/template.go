@@ -0,0 +1,92 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// templateResult is the data a user-supplied output template is executed
+// with: the JSON representation of a result plus its source-agnostic
+// location.
+type templateResult struct {
+	*jsonResult
+	File       string
+	Line       int64
+	Commit     string
+	Repository string
+	Link       string
+	Email      string
+	Timestamp  string
+	// Severity is high, medium, or low.
+	Severity string
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+	"quote":   func(s string) string { return fmt.Sprintf("%q", s) },
+}
+
+// TemplatePrinter is a printer that renders every result with a
+// user-supplied Go text/template.
+type TemplatePrinter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	tmpl *template.Template
+}
+
+// NewTemplatePrinter creates a TemplatePrinter that renders results with the
+// template text to out. If out is nil, os.Stdout is used.
+func NewTemplatePrinter(out io.Writer, text string) (*TemplatePrinter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	return &TemplatePrinter{out: out, tmpl: tmpl}, nil
+}
+
+func (p *TemplatePrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	data := templateResult{
+		jsonResult: newJSONResult(r),
+		File:       loc.File,
+		Line:       loc.Line,
+		Commit:     loc.Commit,
+		Repository: loc.Repository,
+		Link:       reportLink(loc),
+		Email:      loc.Email,
+		Timestamp:  loc.Timestamp,
+		Severity:   resultSeverity(r),
+	}
+
+	// Render into a buffer first so a failing template doesn't leave a
+	// partial record in the output.
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("could not render output template: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = io.WriteString(p.out, b.String())
+	return err
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestTemplatePrinter(t *testing.T) {
+	var out bytes.Buffer
+	p, err := NewTemplatePrinter(&out, "{{.DetectorName | lower}} {{.Severity}} {{.File}}:{{.Line}} {{json .Raw}}\n")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Print(context.Background(), gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)))
+	assert.Equal(t, "aws high config.yaml:1 \"AKIAEXAMPLE\"\n", out.String())
+}
+
+func TestTemplatePrinter_Errors(t *testing.T) {
+	_, err := NewTemplatePrinter(nil, "{{.DetectorName")
+	assert.Error(t, err)
+
+	var out bytes.Buffer
+	p, err := NewTemplatePrinter(&out, "{{.File}} {{.NoSuchField}}")
+	require.NoError(t, err)
+	assert.Error(t, p.Print(context.Background(), gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)))
+	assert.Empty(t, out.String(), "a failed render should not write a partial record")
+}
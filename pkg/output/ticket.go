@@ -0,0 +1,199 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// DefaultTicketSummary is the default template for ticket summaries.
+const DefaultTicketSummary = "Verified {{.Detector}} secret found in {{.Repository}}"
+
+// ticketClosedComment is added to a ticket once its secret is no longer
+// found in the repository it was reported for.
+const ticketClosedComment = "TruffleHog no longer finds this secret in %s. It can be closed once the credential has been rotated."
+
+// ticketFinding is the data available to ticket summary templates.
+type ticketFinding struct {
+	Detector    string
+	Repository  string
+	File        string
+	Line        int64
+	Commit      string
+	Link        string
+	Secret      string
+	Fingerprint string
+}
+
+// description renders the body of a ticket for the finding.
+func (f ticketFinding) description() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TruffleHog found a verified %s secret.\n\n", f.Detector)
+	for _, field := range [][2]string{
+		{"Repository", f.Repository},
+		{"File", f.File},
+		{"Commit", f.Commit},
+		{"Link", f.Link},
+		{"Secret", f.Secret},
+		{"Fingerprint", f.Fingerprint},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field[0], field[1])
+		}
+	}
+	if f.Line > 0 {
+		fmt.Fprintf(&b, "Line: %d\n", f.Line)
+	}
+	b.WriteString("\nRotate the credential and remove it from the repository history.")
+	return b.String()
+}
+
+// repoKey is a short, label-safe identifier for a repository.
+func repoKey(repo string) string {
+	sum := sha256.Sum256([]byte(repo))
+	return hex.EncodeToString(sum[:6])
+}
+
+// ticket is an open ticket TruffleHog created on an earlier run.
+type ticket struct {
+	ID          string
+	Fingerprint string
+	// NotFound is set once the ticket has been commented on because its
+	// secret is no longer found, so the comment is only added once.
+	NotFound bool
+}
+
+// ticketTracker is an issue tracker TicketPrinter files tickets in.
+type ticketTracker interface {
+	// openTickets returns the open tickets created for findings in repo.
+	openTickets(ctx context.Context, repo string) ([]ticket, error)
+	createTicket(ctx context.Context, f ticketFinding, summary string) error
+	// markNotFound comments on t that its secret is no longer found.
+	markNotFound(ctx context.Context, t ticket, comment string) error
+}
+
+// TicketPrinter is a printer that opens a ticket in an issue tracker for
+// every unique verified finding that doesn't already have an open ticket.
+// Open tickets for a scanned repository whose secret is no longer found get a
+// comment saying so.
+type TicketPrinter struct {
+	tracker ticketTracker
+	summary *template.Template
+
+	mu       sync.Mutex
+	repos    map[string]struct{}
+	findings map[string]ticketFinding
+}
+
+func newTicketPrinter(tracker ticketTracker, summaryTemplate string) (*TicketPrinter, error) {
+	if summaryTemplate == "" {
+		summaryTemplate = DefaultTicketSummary
+	}
+	summary, err := template.New("summary").Option("missingkey=error").Parse(summaryTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket summary template: %w", err)
+	}
+	return &TicketPrinter{
+		tracker:  tracker,
+		summary:  summary,
+		repos:    make(map[string]struct{}),
+		findings: make(map[string]ticketFinding),
+	}, nil
+}
+
+func (p *TicketPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	loc, err := extractLocation(r.SourceMetadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	repo := loc.Repository
+	if repo == "" {
+		repo = r.SourceName
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.repos[repo] = struct{}{}
+	if !r.Verified {
+		return nil
+	}
+
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(r)
+	}
+	if _, ok := p.findings[fingerprint]; ok {
+		return nil
+	}
+	p.findings[fingerprint] = ticketFinding{
+		Detector:    r.DetectorType.String(),
+		Repository:  repo,
+		File:        loc.File,
+		Line:        loc.Line,
+		Commit:      loc.Commit,
+		Link:        reportLink(loc),
+		Secret:      SecretPreview(r),
+		Fingerprint: fingerprint,
+	}
+	return nil
+}
+
+// Flush reconciles the findings of the scan with the open tickets of every
+// scanned repository.
+func (p *TicketPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	byRepo := make(map[string][]ticketFinding)
+	for _, f := range p.findings {
+		byRepo[f.Repository] = append(byRepo[f.Repository], f)
+	}
+	repos := make([]string, 0, len(p.repos))
+	for repo := range p.repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var errs []error
+	for _, repo := range repos {
+		open, err := p.tracker.openTickets(ctx, repo)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ticketed := make(map[string]struct{}, len(open))
+		for _, t := range open {
+			ticketed[t.Fingerprint] = struct{}{}
+			if _, ok := p.findings[t.Fingerprint]; !ok && !t.NotFound {
+				if err := p.tracker.markNotFound(ctx, t, fmt.Sprintf(ticketClosedComment, repo)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+
+		findings := byRepo[repo]
+		sort.Slice(findings, func(i, j int) bool { return findings[i].Fingerprint < findings[j].Fingerprint })
+		for _, f := range findings {
+			if _, ok := ticketed[f.Fingerprint]; ok {
+				continue
+			}
+			var summary strings.Builder
+			if err := p.summary.Execute(&summary, f); err != nil {
+				errs = append(errs, fmt.Errorf("could not render ticket summary: %w", err))
+				continue
+			}
+			if err := p.tracker.createTicket(ctx, f, summary.String()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+type fakeTracker struct {
+	open     map[string][]ticket
+	created  []string
+	notFound []string
+}
+
+func (f *fakeTracker) openTickets(_ context.Context, repo string) ([]ticket, error) {
+	return f.open[repo], nil
+}
+
+func (f *fakeTracker) createTicket(_ context.Context, finding ticketFinding, summary string) error {
+	f.created = append(f.created, summary)
+	return nil
+}
+
+func (f *fakeTracker) markNotFound(_ context.Context, t ticket, _ string) error {
+	f.notFound = append(f.notFound, t.ID)
+	return nil
+}
+
+func TestTicketPrinter(t *testing.T) {
+	ctx := context.Background()
+	const repo = "https://github.com/org/repo.git"
+	ticketed := gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)
+	tracker := &fakeTracker{open: map[string][]ticket{repo: {
+		{ID: "T-1", Fingerprint: Fingerprint(ticketed)},
+		{ID: "T-2", Fingerprint: "gone"},
+		{ID: "T-3", Fingerprint: "gone-and-commented", NotFound: true},
+	}}}
+	p, err := newTicketPrinter(tracker, "{{.Detector}} in {{.File}}")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Print(ctx, ticketed))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "new.yaml", "AKIAOTHER", true)))
+	require.NoError(t, p.Print(ctx, gitResult("c2", "new.yaml", "AKIAOTHER", true)))
+	require.NoError(t, p.Print(ctx, gitResult("c1", "unverified.yaml", "AKIAUNVERIFIED", false)))
+	require.NoError(t, p.Flush(ctx))
+
+	assert.Equal(t, []string{"AWS in new.yaml"}, tracker.created)
+	assert.Equal(t, []string{"T-2"}, tracker.notFound)
+}
+
+func TestNewTicketPrinter_InvalidTemplate(t *testing.T) {
+	_, err := newTicketPrinter(new(fakeTracker), "{{.Detector")
+	assert.Error(t, err)
+}
+
+func TestJiraTracker(t *testing.T) {
+	var requests []string
+	var created map[string]map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "search")
+		assert.Contains(t, r.URL.Query().Get("jql"), `project = "SEC"`)
+		_, _ = io.WriteString(w, `{"total":2,"issues":[
+			{"key":"SEC-1","fields":{"labels":["trufflehog","trufflehog-fp-abc"]}},
+			{"key":"SEC-2","fields":{"labels":["trufflehog","trufflehog-fp-def","trufflehog-not-found"]}}]}`)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "create")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/SEC-1/comment", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "comment")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PUT /rest/api/2/issue/SEC-1", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "label")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	j := &jiraTracker{client: srv.Client(), auth: tokenAuth("token"), endpoint: srv.URL, project: "SEC", issueType: "Bug"}
+
+	open, err := j.openTickets(ctx, "repo")
+	require.NoError(t, err)
+	assert.Equal(t, []ticket{{ID: "SEC-1", Fingerprint: "abc"}, {ID: "SEC-2", Fingerprint: "def", NotFound: true}}, open)
+
+	require.NoError(t, j.createTicket(ctx, ticketFinding{Detector: "AWS", Repository: "repo", Fingerprint: "xyz"}, "summary"))
+	assert.Equal(t, "summary", created["fields"]["summary"])
+	assert.Contains(t, created["fields"]["labels"], "secret-aws")
+	assert.Contains(t, created["fields"]["labels"], "trufflehog-fp-xyz")
+
+	require.NoError(t, j.markNotFound(ctx, open[0], "gone"))
+	assert.Equal(t, []string{"search", "create", "comment", "label"}, requests)
+}
@@ -0,0 +1,184 @@
+package output
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+)
+
+// WebhookSignatureHeader is the header carrying the HMAC-SHA256 signature of
+// a request when a signing secret is configured. Its value has the form
+// "sha256=<hex digest>", computed by SignWebhookPayload over the
+// WebhookTimestampHeader and the body.
+const WebhookSignatureHeader = "X-TruffleHog-Signature"
+
+// WebhookTimestampHeader is the header carrying the Unix time in seconds a
+// request was first sent at. It's covered by the signature, so receivers can
+// reject replayed requests whose timestamp is older than
+// WebhookTimestampTolerance.
+const WebhookTimestampHeader = "X-TruffleHog-Timestamp"
+
+// WebhookDeliveryHeader is the header carrying a unique ID of each request.
+// Retries of a request keep its ID, so receivers can drop deliveries they've
+// already processed.
+const WebhookDeliveryHeader = "X-TruffleHog-Delivery"
+
+// WebhookTimestampTolerance is how old the timestamp of a request receivers
+// should accept. It's longer than the retries of a request take, as retries
+// keep the timestamp of the first attempt.
+const WebhookTimestampTolerance = 5 * time.Minute
+
+// WebhookGroupHeader is the header naming the repository or owner the results
+// of a request belong to when deliveries are grouped.
+const WebhookGroupHeader = "X-TruffleHog-Group"
+
+const (
+	webhookMaxRetries   = 5
+	webhookRetryWaitMin = 500 * time.Millisecond
+	webhookRetryWaitMax = 30 * time.Second
+	webhookTimeout      = 30 * time.Second
+)
+
+// WebhookPrinter is a printer that POSTs results as JSON to one or more
+// webhook URLs. With a batch size of one, each result is sent as a JSON object
+// as soon as it is printed; larger batch sizes send JSON arrays of results and
+// the final partial batch is sent on Flush. Failed deliveries are retried with
+// exponential backoff.
+type WebhookPrinter struct {
+	urls      []string
+	secret    []byte
+	batchSize int
+	group     string
+	client    *http.Client
+
+	mu    sync.Mutex
+	batch []*jsonResult
+}
+
+// WebhookOption configures a WebhookPrinter.
+type WebhookOption func(*WebhookPrinter)
+
+// WithWebhookSecret signs every request body with secret using HMAC-SHA256.
+func WithWebhookSecret(secret string) WebhookOption {
+	return func(p *WebhookPrinter) { p.secret = []byte(secret) }
+}
+
+// WithWebhookBatchSize sets how many results are sent per request.
+func WithWebhookBatchSize(size int) WebhookOption {
+	return func(p *WebhookPrinter) { p.batchSize = size }
+}
+
+// WithWebhookGroup names the group of every result sent, in the
+// WebhookGroupHeader.
+func WithWebhookGroup(group string) WebhookOption {
+	return func(p *WebhookPrinter) { p.group = group }
+}
+
+// WithWebhookClient sets the HTTP client used to deliver requests.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(p *WebhookPrinter) { p.client = client }
+}
+
+// NewWebhookPrinter creates a WebhookPrinter that delivers results to urls.
+func NewWebhookPrinter(urls []string, opts ...WebhookOption) *WebhookPrinter {
+	p := &WebhookPrinter{urls: urls, batchSize: 1}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.client == nil {
+		p.client = common.RetryableHTTPClient(
+			common.WithMaxRetries(webhookMaxRetries),
+			common.WithRetryWaitMin(webhookRetryWaitMin),
+			common.WithRetryWaitMax(webhookRetryWaitMax),
+			common.WithTimeout(webhookTimeout),
+		)
+	}
+	return p
+}
+
+func (p *WebhookPrinter) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	result := newJSONResult(r)
+	if p.batchSize <= 1 {
+		return p.send(ctx, result)
+	}
+
+	p.mu.Lock()
+	p.batch = append(p.batch, result)
+	if len(p.batch) < p.batchSize {
+		p.mu.Unlock()
+		return nil
+	}
+	batch := p.batch
+	p.batch = nil
+	p.mu.Unlock()
+
+	return p.send(ctx, batch)
+}
+
+// Flush sends any results still waiting for a full batch.
+func (p *WebhookPrinter) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	batch := p.batch
+	p.batch = nil
+	p.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return p.send(ctx, batch)
+}
+
+// send marshals payload and delivers it to every configured URL. A failure to
+// deliver to one URL does not prevent delivery to the others.
+func (p *WebhookPrinter) send(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+
+	var errs []error
+	for _, url := range p.urls {
+		if err := p.post(ctx, url, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *WebhookPrinter) post(ctx context.Context, url string, body []byte) error {
+	header := make(http.Header)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	header.Set(WebhookTimestampHeader, timestamp)
+	header.Set(WebhookDeliveryHeader, uuid.NewString())
+	if len(p.secret) > 0 {
+		header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(p.secret, timestamp, body))
+	}
+	if p.group != "" {
+		header.Set(WebhookGroupHeader, p.group)
+	}
+	return postJSON(ctx, p.client, url, body, header)
+}
+
+// SignWebhookPayload returns the hex-encoded HMAC-SHA256 of timestamp, a dot
+// and body keyed with secret, as sent in the WebhookSignatureHeader.
+// Receivers can use it to verify that a request originated from TruffleHog
+// and that its WebhookTimestampHeader wasn't changed.
+func SignWebhookPayload(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+type webhookReceiver struct {
+	mu         sync.Mutex
+	bodies     [][]byte
+	signatures []string
+	timestamps []string
+	deliveries []string
+	groups     []string
+	failures   int
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.deliveries = append(rcv.deliveries, r.Header.Get(WebhookDeliveryHeader))
+	if rcv.failures > 0 {
+		rcv.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	rcv.bodies = append(rcv.bodies, body)
+	rcv.signatures = append(rcv.signatures, r.Header.Get(WebhookSignatureHeader))
+	rcv.timestamps = append(rcv.timestamps, r.Header.Get(WebhookTimestampHeader))
+	rcv.groups = append(rcv.groups, r.Header.Get(WebhookGroupHeader))
+}
+
+func testWebhookClient() *http.Client {
+	return common.RetryableHTTPClient(
+		common.WithRetryWaitMin(time.Millisecond),
+		common.WithRetryWaitMax(time.Millisecond),
+	)
+}
+
+func TestWebhookPrinter_SignsAndRetries(t *testing.T) {
+	ctx := context.Background()
+	rcv := &webhookReceiver{failures: 2}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	p := NewWebhookPrinter([]string{srv.URL},
+		WithWebhookSecret("s3cret"),
+		WithWebhookClient(testWebhookClient()),
+	)
+	require.NoError(t, p.Print(ctx, gitResult("c1", "config.yaml", "AKIAEXAMPLE", true)))
+
+	require.Len(t, rcv.bodies, 1)
+	assert.Equal(t, "sha256="+SignWebhookPayload([]byte("s3cret"), rcv.timestamps[0], rcv.bodies[0]), rcv.signatures[0])
+	sent, err := strconv.ParseInt(rcv.timestamps[0], 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(sent, 0), WebhookTimestampTolerance)
+	// The signature covers the timestamp, so it can't be moved forward to
+	// replay the request.
+	assert.NotEqual(t, rcv.signatures[0], "sha256="+SignWebhookPayload([]byte("s3cret"), strconv.FormatInt(sent+60, 10), rcv.bodies[0]))
+	// Retries are the same delivery.
+	require.Len(t, rcv.deliveries, 3)
+	assert.NotEmpty(t, rcv.deliveries[0])
+	assert.Equal(t, rcv.deliveries[0], rcv.deliveries[1])
+	assert.Equal(t, rcv.deliveries[0], rcv.deliveries[2])
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &got))
+	assert.Equal(t, "AWS", got["DetectorName"])
+	assert.Equal(t, true, got["Verified"])
+}
+
+func TestWebhookPrinter_Batches(t *testing.T) {
+	ctx := context.Background()
+	rcv := new(webhookReceiver)
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	p := NewWebhookPrinter([]string{srv.URL},
+		WithWebhookBatchSize(2),
+		WithWebhookClient(testWebhookClient()),
+	)
+	for _, commit := range []string{"c1", "c2", "c3"} {
+		require.NoError(t, p.Print(ctx, gitResult(commit, "config.yaml", "AKIAEXAMPLE", false)))
+	}
+	require.Len(t, rcv.bodies, 1, "only full batches should be sent before flush")
+	require.NoError(t, p.Flush(ctx))
+	require.Len(t, rcv.bodies, 2)
+
+	var first, last []map[string]any
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &first))
+	require.NoError(t, json.Unmarshal(rcv.bodies[1], &last))
+	assert.Len(t, first, 2)
+	assert.Len(t, last, 1)
+	assert.Empty(t, rcv.signatures[0], "unsigned when no secret is configured")
+	assert.NotEqual(t, rcv.deliveries[0], rcv.deliveries[1])
+}
+
+func TestWebhookPrinter_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookPrinter([]string{srv.URL}, WithWebhookClient(testWebhookClient()))
+	assert.Error(t, p.Print(context.Background(), gitResult("c1", "config.yaml", "AKIAEXAMPLE", false)))
+}
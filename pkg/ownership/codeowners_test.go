@@ -0,0 +1,41 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	c := ParseCodeOwners([]byte(`# Default owners.
+*       @org/everyone
+
+[Backend]
+/services/           @org/backend # Inline comments are ignored.
+*.tf                 @org/infra
+docs/**/secrets.md   docs@example.com
+/services/legacy/
+
+/config/*.yaml       @org/config
+`))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "README.md", want: []string{"@org/everyone"}},
+		{path: "services/api/main.go", want: []string{"@org/backend"}},
+		{path: "/services/api/main.go", want: []string{"@org/backend"}},
+		{path: "infra/prod/main.tf", want: []string{"@org/infra"}},
+		{path: "docs/secrets.md", want: []string{"docs@example.com"}},
+		{path: "docs/a/b/secrets.md", want: []string{"docs@example.com"}},
+		{path: "services/legacy/main.go", want: nil},
+		{path: "config/app.yaml", want: []string{"@org/config"}},
+		{path: "config/nested/app.yaml", want: []string{"@org/everyone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Owners(tt.path))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package ownership attributes findings to the teams or people that own them,
+// from CODEOWNERS files, the author of the commit that introduced the secret,
+// and a team mapping, so findings can be routed to their owners.
+package ownership
+
+import (
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/blame"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// Resolver finds the owners of findings. The CODEOWNERS files of git findings
+// are read from the HEAD of their repository, which is cloned again without
+// credentials, and those of files on disk from the work tree they are in.
+type Resolver struct {
+	teams *Teams
+	repos repoCache
+
+	mu         sync.Mutex
+	codeOwners map[string]*cachedCodeOwners
+}
+
+// repoCache is the git.RepoCache repositories are read from.
+type repoCache interface {
+	Path(ctx context.Context, repoURL string) (string, error)
+	Close()
+}
+
+type cachedCodeOwners struct {
+	once  sync.Once
+	rules *CodeOwners
+}
+
+// NewResolver creates a Resolver that maps owners to the teams of teams,
+// which may be nil.
+func NewResolver(teams *Teams) *Resolver {
+	return &Resolver{teams: teams, repos: git.NewRepoCache(), codeOwners: make(map[string]*cachedCodeOwners)}
+}
+
+// location is the part of the metadata of a finding ownership needs.
+type location struct {
+	repository string
+	file       string
+	email      string
+	line       int64
+	onDisk     bool
+}
+
+func locate(md *source_metadatapb.MetaData) (location, bool) {
+	switch m := md.GetData().(type) {
+	case *source_metadatapb.MetaData_Git:
+		return location{repository: m.Git.Repository, file: m.Git.File, email: m.Git.Email}, true
+	case *source_metadatapb.MetaData_Github:
+		return location{repository: m.Github.Repository, file: m.Github.File, email: m.Github.Email}, true
+	case *source_metadatapb.MetaData_Gitlab:
+		return location{repository: m.Gitlab.Repository, file: m.Gitlab.File, email: m.Gitlab.Email}, true
+	case *source_metadatapb.MetaData_Filesystem:
+		return location{file: m.Filesystem.File, line: m.Filesystem.Line, onDisk: true}, true
+	default:
+		return location{}, false
+	}
+}
+
+// Owners returns the owners of r: the teams that match it, if there are any,
+// or else the CODEOWNERS owners of its file, or else the author of the commit
+// that introduced the secret.
+func (res *Resolver) Owners(ctx context.Context, r *detectors.ResultWithMetadata) []string {
+	loc, ok := locate(r.SourceMetadata)
+	if !ok || loc.file == "" {
+		return nil
+	}
+
+	var codeOwners []string
+	var author string
+	path := loc.file
+	if loc.onDisk {
+		if root := blame.WorkTree(loc.file); root != "" {
+			if rel, err := filepath.Rel(root, loc.file); err == nil {
+				path = filepath.ToSlash(rel)
+				codeOwners = res.cached(root, func() *CodeOwners { return readCodeOwners(root) }).Owners(path)
+				if b, err := blame.Line(ctx, root, path, loc.line); err != nil {
+					ctx.Logger().V(3).Info("could not blame file", "file", path, "error", err)
+				} else if b != nil {
+					author = b.Email
+				}
+			}
+		}
+	} else {
+		author = emailAddress(loc.email)
+		if loc.repository == "" {
+			// Local repositories without a remote can't be read again.
+		} else if repo, err := res.repos.Path(ctx, loc.repository); err != nil {
+			ctx.Logger().V(2).Info("could not clone repository to read its CODEOWNERS", "repository", loc.repository, "error", err)
+		} else {
+			codeOwners = res.cached(repo, func() *CodeOwners { return showCodeOwners(ctx, repo) }).Owners(path)
+		}
+	}
+
+	owners := codeOwners
+	if author != "" {
+		owners = append(owners[:len(owners):len(owners)], author)
+	}
+	if res.teams != nil {
+		if teams := res.teams.Match(loc.repository, path, owners); len(teams) > 0 {
+			return teams
+		}
+	}
+	if len(codeOwners) > 0 {
+		return codeOwners
+	}
+	if author != "" {
+		return []string{author}
+	}
+	return nil
+}
+
+// cached returns the CODEOWNERS of the repository at dir, reading them with
+// read the first time.
+func (res *Resolver) cached(dir string, read func() *CodeOwners) *CodeOwners {
+	res.mu.Lock()
+	c, ok := res.codeOwners[dir]
+	if !ok {
+		c = new(cachedCodeOwners)
+		res.codeOwners[dir] = c
+	}
+	res.mu.Unlock()
+	c.once.Do(func() { c.rules = read() })
+	return c.rules
+}
+
+// Close removes the repositories that were cloned.
+func (res *Resolver) Close() {
+	res.repos.Close()
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	res.codeOwners = make(map[string]*cachedCodeOwners)
+}
+
+func readCodeOwners(root string) *CodeOwners {
+	for _, p := range codeOwnersPaths {
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p))); err == nil {
+			return ParseCodeOwners(data)
+		}
+	}
+	return new(CodeOwners)
+}
+
+func showCodeOwners(ctx context.Context, repo string) *CodeOwners {
+	for _, p := range codeOwnersPaths {
+		cmd := exec.CommandContext(ctx, "git", "show", "HEAD:"+p)
+		cmd.Dir = repo
+		if data, err := cmd.Output(); err == nil {
+			return ParseCodeOwners(data)
+		}
+	}
+	return new(CodeOwners)
+}
+
+// emailAddress returns the address of an email such as "Name <name@example.com>".
+func emailAddress(email string) string {
+	if addr, err := mail.ParseAddress(email); err == nil {
+		return addr.Address
+	}
+	return strings.TrimSpace(email)
+}
+
+// resultsDispatcher mirrors engine.ResultsDispatcher, which can't be
+// referenced here without an import cycle.
+type resultsDispatcher interface {
+	Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error
+}
+
+// Dispatcher attributes results to their owners before dispatching them.
+type Dispatcher struct {
+	resolver *Resolver
+	next     resultsDispatcher
+}
+
+// NewDispatcher creates a Dispatcher that attributes results with resolver
+// and hands them to next.
+func NewDispatcher(resolver *Resolver, next resultsDispatcher) *Dispatcher {
+	return &Dispatcher{resolver: resolver, next: next}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, result detectors.ResultWithMetadata) error {
+	result.Owners = d.resolver.Owners(ctx, &result)
+	return d.next.Dispatch(ctx, result)
+}
+
+// Flush flushes the next dispatcher if it buffers its output, then removes
+// the repositories the resolver cloned.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	defer d.resolver.Close()
+	if f, ok := d.next.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
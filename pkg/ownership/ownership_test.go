@@ -0,0 +1,110 @@
+package ownership
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// commitFile commits file with content in the repository at dir as author.
+func commitFile(t *testing.T, dir, file, content, author string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "update " + file}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=author", "GIT_AUTHOR_EMAIL="+author,
+			"GIT_COMMITTER_NAME=committer", "GIT_COMMITTER_EMAIL=committer@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func filesystemResult(file string, line int64) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{SourceMetadata: &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: file, Line: line}},
+	}}
+}
+
+func gitResult(repo, file, email string) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{SourceMetadata: &source_metadatapb.MetaData{
+		Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{Repository: repo, File: file, Email: email}},
+	}}
+}
+
+// localRepos is a repository cache of local repositories.
+type localRepos map[string]string
+
+func (r localRepos) Path(_ context.Context, repoURL string) (string, error) {
+	if path, ok := r[repoURL]; ok {
+		return path, nil
+	}
+	return "", assert.AnError
+}
+func (localRepos) Close() {}
+
+func TestResolver_Owners(t *testing.T) {
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	commitFile(t, dir, ".github/CODEOWNERS", "/services/ @org/backend\n", "admin@example.com")
+	commitFile(t, dir, "services/api.env", "KEY=AKIAEXAMPLE\n", "backend@example.com")
+	commitFile(t, dir, "scripts/deploy.env", "USER=deploy\nKEY=AKIAEXAMPLE\n", "first@example.com")
+	commitFile(t, dir, "scripts/deploy.env", "USER=deploy\nKEY=AKIAOTHER\n", "second@example.com")
+
+	ctx := context.Background()
+	res := NewResolver(nil)
+	res.repos = localRepos{"https://github.com/org/repo.git": dir}
+	defer res.Close()
+
+	assert.Equal(t, []string{"@org/backend"}, res.Owners(ctx, filesystemResult(filepath.Join(dir, "services", "api.env"), 0)))
+	// Files without owners are attributed to the author of the line's commit.
+	assert.Equal(t, []string{"second@example.com"}, res.Owners(ctx, filesystemResult(filepath.Join(dir, "scripts", "deploy.env"), 1)))
+	assert.Equal(t, []string{"first@example.com"}, res.Owners(ctx, filesystemResult(filepath.Join(dir, "scripts", "deploy.env"), 0)))
+	assert.Nil(t, res.Owners(ctx, filesystemResult(filepath.Join(t.TempDir(), "a.env"), 0)))
+
+	repo := "https://github.com/org/repo.git"
+	assert.Equal(t, []string{"@org/backend"}, res.Owners(ctx, gitResult(repo, "services/api.env", "Backend <backend@example.com>")))
+	assert.Equal(t, []string{"dev@example.com"}, res.Owners(ctx, gitResult(repo, "scripts/deploy.env", "Dev <dev@example.com>")))
+	// The CODEOWNERS of repositories that can't be cloned are unknown.
+	assert.Equal(t, []string{"dev@example.com"}, res.Owners(ctx, gitResult("https://github.com/org/gone.git", "services/api.env", "dev@example.com")))
+}
+
+func TestResolver_Teams(t *testing.T) {
+	teams, err := ParseTeams([]byte(`
+teams:
+  - name: backend
+    owners: ["@org/backend"]
+  - name: contractors
+    owners: ["*@contractor.example.com"]
+`))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	commitFile(t, dir, "CODEOWNERS", "services/ @org/backend\n", "admin@example.com")
+
+	ctx := context.Background()
+	res := NewResolver(teams)
+	res.repos = localRepos{"https://github.com/org/repo.git": dir}
+	defer res.Close()
+
+	repo := "https://github.com/org/repo.git"
+	assert.Equal(t, []string{"backend"}, res.Owners(ctx, gitResult(repo, "services/api.env", "dev@example.com")))
+	assert.Equal(t, []string{"contractors"}, res.Owners(ctx, gitResult(repo, "app.env", "dev@contractor.example.com")))
+	// Owners that aren't in any team are kept as they are.
+	assert.Equal(t, []string{"dev@example.com"}, res.Owners(ctx, gitResult(repo, "app.env", "dev@example.com")))
+}
@@ -0,0 +1,126 @@
+package ownership
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Teams maps findings to the teams that own them. It is loaded from a YAML
+// file such as:
+//
+//	teams:
+//	  - name: payments
+//	    # CODEOWNERS owners and commit authors that belong to the team.
+//	    owners: ["@org/payments", "*@payments.example.com"]
+//	    repositories: ["https://github.com/org/payments*"]
+//	    paths: ["services/payments/"]
+//	    slack_webhook: https://hooks.slack.com/services/...
+//	    teams_webhook: high=https://example.webhook.office.com/...
+type Teams struct {
+	Teams []Team `yaml:"teams"`
+}
+
+// Team owns the findings that match any of its owners, repositories, or
+// paths.
+type Team struct {
+	Name string `yaml:"name"`
+	// Owners are CODEOWNERS owners and commit author emails, in which "*"
+	// matches anything.
+	Owners []string `yaml:"owners"`
+	// Repositories are the URLs of repositories, in which "*" matches
+	// anything.
+	Repositories []string `yaml:"repositories"`
+	// Paths are paths in repositories, or of files on disk, with the syntax
+	// of CODEOWNERS patterns.
+	Paths []string `yaml:"paths"`
+	// SlackWebhook and TeamsWebhook receive the notifications of the team's
+	// findings, with the syntax of --slack-webhook and --teams-webhook.
+	SlackWebhook string `yaml:"slack_webhook"`
+	TeamsWebhook string `yaml:"teams_webhook"`
+
+	owners, repositories, paths []*regexp.Regexp
+}
+
+// LoadTeams loads the teams of the file at path.
+func LoadTeams(path string) (*Teams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTeams(data)
+}
+
+// ParseTeams parses the YAML of a team mapping.
+func ParseTeams(data []byte) (*Teams, error) {
+	var t Teams
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid team mapping: %w", err)
+	}
+	for i := range t.Teams {
+		team := &t.Teams[i]
+		if team.Name == "" {
+			return nil, fmt.Errorf("team %d has no name", i+1)
+		}
+		for _, owner := range team.Owners {
+			team.owners = append(team.owners, compileWildcard(owner))
+		}
+		for _, repo := range team.Repositories {
+			team.repositories = append(team.repositories, compileWildcard(repo))
+		}
+		for _, path := range team.Paths {
+			pattern, err := compilePathPattern(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q of team %s: %w", path, team.Name, err)
+			}
+			team.paths = append(team.paths, pattern)
+		}
+	}
+	return &t, nil
+}
+
+// Match returns the names of the teams that own the file at path in the
+// repository at repo, or that any of owners belong to.
+func (t *Teams) Match(repo, path string, owners []string) []string {
+	var names []string
+	for _, team := range t.Teams {
+		if team.matches(repo, path, owners) {
+			names = append(names, team.Name)
+		}
+	}
+	return names
+}
+
+func (team *Team) matches(repo, path string, owners []string) bool {
+	for _, pattern := range team.repositories {
+		if repo != "" && pattern.MatchString(repo) {
+			return true
+		}
+	}
+	for _, pattern := range team.paths {
+		if path != "" && pattern.MatchString(strings.TrimPrefix(path, "/")) {
+			return true
+		}
+	}
+	for _, pattern := range team.owners {
+		for _, owner := range owners {
+			if pattern.MatchString(owner) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compileWildcard compiles a case-insensitive pattern in which "*" matches
+// anything.
+func compileWildcard(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+}
@@ -0,0 +1,37 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeams_Match(t *testing.T) {
+	teams, err := ParseTeams([]byte(`
+teams:
+  - name: payments
+    owners: ["@org/payments", "*@payments.example.com"]
+    slack_webhook: https://hooks.slack.com/services/payments
+  - name: platform
+    repositories: ["https://github.com/org/platform-*"]
+    paths: ["deploy/"]
+`))
+	require.NoError(t, err)
+	require.Len(t, teams.Teams, 2)
+	assert.Equal(t, "https://hooks.slack.com/services/payments", teams.Teams[0].SlackWebhook)
+
+	assert.Equal(t, []string{"payments"}, teams.Match("", "app.go", []string{"@org/payments"}))
+	assert.Equal(t, []string{"payments"}, teams.Match("", "app.go", []string{"Dev@Payments.example.com"}))
+	assert.Equal(t, []string{"platform"}, teams.Match("https://github.com/org/platform-api.git", "app.go", nil))
+	assert.Equal(t, []string{"payments", "platform"}, teams.Match("", "deploy/prod.yaml", []string{"@org/payments"}))
+	assert.Empty(t, teams.Match("https://github.com/org/other.git", "app.go", []string{"@org/other"}))
+}
+
+func TestParseTeams_Invalid(t *testing.T) {
+	_, err := ParseTeams([]byte("teams:\n  - owners: [\"@org/payments\"]\n"))
+	assert.ErrorContains(t, err, "team 1 has no name")
+
+	_, err = ParseTeams([]byte("teams: {"))
+	assert.Error(t, err)
+}
@@ -1304,6 +1304,8 @@ func (m *Gitlab) validate(all bool) error {
 
 	// no validation rules for ProjectOwner
 
+	// no validation rules for Visibility
+
 	if len(errors) > 0 {
 		return GitlabMultiError(errors)
 	}
@@ -1969,6 +1971,8 @@ func (m *S3) validate(all bool) error {
 
 	// no validation rules for Timestamp
 
+	// no validation rules for Visibility
+
 	if len(errors) > 0 {
 		return S3MultiError(errors)
 	}
@@ -4015,6 +4019,114 @@ var _ interface {
 	ErrorName() string
 } = SentryValidationError{}
 
+// Validate checks the field values on Plugin with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Plugin) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Plugin with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PluginMultiError, or nil if none found.
+func (m *Plugin) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Plugin) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Plugin
+
+	// no validation rules for File
+
+	// no validation rules for Link
+
+	// no validation rules for Line
+
+	// no validation rules for Timestamp
+
+	if len(errors) > 0 {
+		return PluginMultiError(errors)
+	}
+
+	return nil
+}
+
+// PluginMultiError is an error wrapping multiple validation errors returned by
+// Plugin.ValidateAll() if the designated constraints aren't met.
+type PluginMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PluginMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PluginMultiError) AllErrors() []error { return m }
+
+// PluginValidationError is the validation error returned by Plugin.Validate if
+// the designated constraints aren't met.
+type PluginValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PluginValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PluginValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PluginValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PluginValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PluginValidationError) ErrorName() string { return "PluginValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PluginValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPlugin.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PluginValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PluginValidationError{}
+
 // Validate checks the field values on MetaData with the rules defined in the
 // proto definition for this message. If any rules are violated, the first
 // error encountered is returned, or nil if there are no violations.
@@ -4037,6 +4149,40 @@ func (m *MetaData) validate(all bool) error {
 
 	var errors []error
 
+	for idx, item := range m.GetProvenance() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  fmt.Sprintf("Provenance[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  fmt.Sprintf("Provenance[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  fmt.Sprintf("Provenance[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
 	switch v := m.Data.(type) {
 	case *MetaData_Azure:
 		if v == nil {
@@ -5391,6 +5537,47 @@ func (m *MetaData) validate(all bool) error {
 			}
 		}
 
+	case *MetaData_Plugin:
+		if v == nil {
+			err := MetaDataValidationError{
+				field:  "Data",
+				reason: "oneof value cannot be a typed-nil",
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+		if all {
+			switch v := interface{}(m.GetPlugin()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Plugin",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, MetaDataValidationError{
+						field:  "Plugin",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetPlugin()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return MetaDataValidationError{
+					field:  "Plugin",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
 	default:
 		_ = v // ensures v is used
 	}
@@ -5471,3 +5658,107 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = MetaDataValidationError{}
+
+// Validate checks the field values on Container with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Container) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Container with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ContainerMultiError, or nil if none found.
+func (m *Container) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Container) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Format
+
+	// no validation rules for Path
+
+	// no validation rules for Part
+
+	if len(errors) > 0 {
+		return ContainerMultiError(errors)
+	}
+
+	return nil
+}
+
+// ContainerMultiError is an error wrapping multiple validation errors returned by
+// Container.ValidateAll() if the designated constraints aren't met.
+type ContainerMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ContainerMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ContainerMultiError) AllErrors() []error { return m }
+
+// ContainerValidationError is the validation error returned by Container.Validate if
+// the designated constraints aren't met.
+type ContainerValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ContainerValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ContainerValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ContainerValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ContainerValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ContainerValidationError) ErrorName() string { return "ContainerValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ContainerValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sContainer.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ContainerValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ContainerValidationError{}
@@ -1687,6 +1687,35 @@ func (m *Filesystem) validate(all bool) error {
 
 	// no validation rules for ExcludePathsFile
 
+	if all {
+		switch v := interface{}(m.GetFilter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, FilesystemValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFilter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return FilesystemValidationError{
+				field:  "Filter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	if len(errors) > 0 {
 		return FilesystemMultiError(errors)
 	}
@@ -2071,6 +2100,37 @@ func (m *Git) validate(all bool) error {
 
 	// no validation rules for SkipArchives
 
+	if all {
+		switch v := interface{}(m.GetFilter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFilter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitValidationError{
+				field:  "Filter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Remote
+
 	switch v := m.Credential.(type) {
 	case *Git_BasicAuth:
 		if v == nil {
@@ -2313,6 +2373,93 @@ func (m *GitLab) validate(all bool) error {
 
 	// no validation rules for SkipArchives
 
+	if all {
+		switch v := interface{}(m.GetFilter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFilter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "Filter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetExclude()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Exclude",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Exclude",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetExclude()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "Exclude",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetSelect()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Select",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitLabValidationError{
+					field:  "Select",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSelect()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitLabValidationError{
+				field:  "Select",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch v := m.Credential.(type) {
 	case *GitLab_Token:
 		if v == nil {
@@ -2542,6 +2689,93 @@ func (m *GitHub) validate(all bool) error {
 
 	// no validation rules for IncludeWikis
 
+	if all {
+		switch v := interface{}(m.GetFilter()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Filter",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetFilter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitHubValidationError{
+				field:  "Filter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetExclude()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Exclude",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Exclude",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetExclude()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitHubValidationError{
+				field:  "Exclude",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetSelect()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Select",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GitHubValidationError{
+					field:  "Select",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSelect()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GitHubValidationError{
+				field:  "Select",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	switch v := m.Credential.(type) {
 	case *GitHub_GithubApp:
 		if v == nil {
@@ -6385,3 +6619,467 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = SentryValidationError{}
+
+// Validate checks the field values on Plugin with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Plugin) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Plugin with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PluginMultiError, or nil if none found.
+func (m *Plugin) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Plugin) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Path
+
+	if all {
+		switch v := interface{}(m.GetSettings()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, PluginValidationError{
+					field:  "Settings",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, PluginValidationError{
+					field:  "Settings",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetSettings()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return PluginValidationError{
+				field:  "Settings",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return PluginMultiError(errors)
+	}
+
+	return nil
+}
+
+// PluginMultiError is an error wrapping multiple validation errors returned by
+// Plugin.ValidateAll() if the designated constraints aren't met.
+type PluginMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PluginMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PluginMultiError) AllErrors() []error { return m }
+
+// PluginValidationError is the validation error returned by Plugin.Validate if
+// the designated constraints aren't met.
+type PluginValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PluginValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PluginValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PluginValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PluginValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PluginValidationError) ErrorName() string { return "PluginValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PluginValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPlugin.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PluginValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PluginValidationError{}
+
+// Validate checks the field values on Filter with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Filter) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Filter with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in FilterMultiError, or nil if none found.
+func (m *Filter) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Filter) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for MaxSize
+
+	if len(errors) > 0 {
+		return FilterMultiError(errors)
+	}
+
+	return nil
+}
+
+// FilterMultiError is an error wrapping multiple validation errors returned by
+// Filter.ValidateAll() if the designated constraints aren't met.
+type FilterMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FilterMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FilterMultiError) AllErrors() []error { return m }
+
+// FilterValidationError is the validation error returned by Filter.Validate if
+// the designated constraints aren't met.
+type FilterValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FilterValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FilterValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FilterValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FilterValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FilterValidationError) ErrorName() string { return "FilterValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FilterValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFilter.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FilterValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FilterValidationError{}
+
+// Validate checks the field values on RepoExclusions with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *RepoExclusions) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on RepoExclusions with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in RepoExclusionsMultiError, or
+// nil if none found.
+func (m *RepoExclusions) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *RepoExclusions) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Archived
+
+	// no validation rules for Forks
+
+	if len(errors) > 0 {
+		return RepoExclusionsMultiError(errors)
+	}
+
+	return nil
+}
+
+// RepoExclusionsMultiError is an error wrapping multiple validation errors
+// returned by RepoExclusions.ValidateAll() if the designated constraints
+// aren't met.
+type RepoExclusionsMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RepoExclusionsMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RepoExclusionsMultiError) AllErrors() []error { return m }
+
+// RepoExclusionsValidationError is the validation error returned by
+// RepoExclusions.Validate if the designated constraints aren't met.
+type RepoExclusionsValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RepoExclusionsValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RepoExclusionsValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RepoExclusionsValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RepoExclusionsValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RepoExclusionsValidationError) ErrorName() string { return "RepoExclusionsValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RepoExclusionsValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRepoExclusions.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RepoExclusionsValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RepoExclusionsValidationError{}
+
+// Validate checks the field values on RepoSelection with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *RepoSelection) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on RepoSelection with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in RepoSelectionMultiError, or
+// nil if none found.
+func (m *RepoSelection) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *RepoSelection) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetPushedWithin()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, RepoSelectionValidationError{
+					field:  "PushedWithin",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, RepoSelectionValidationError{
+					field:  "PushedWithin",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetPushedWithin()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return RepoSelectionValidationError{
+				field:  "PushedWithin",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for MaxSizeKb
+
+	if len(errors) > 0 {
+		return RepoSelectionMultiError(errors)
+	}
+
+	return nil
+}
+
+// RepoSelectionMultiError is an error wrapping multiple validation errors
+// returned by RepoSelection.ValidateAll() if the designated constraints
+// aren't met.
+type RepoSelectionMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RepoSelectionMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RepoSelectionMultiError) AllErrors() []error { return m }
+
+// RepoSelectionValidationError is the validation error returned by
+// RepoSelection.Validate if the designated constraints aren't met.
+type RepoSelectionValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RepoSelectionValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RepoSelectionValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RepoSelectionValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RepoSelectionValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RepoSelectionValidationError) ErrorName() string { return "RepoSelectionValidationError" }
+
+// Error satisfies the builtin error interface
+func (e RepoSelectionValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRepoSelection.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RepoSelectionValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RepoSelectionValidationError{}
@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/custom_detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detector_pluginpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Detector is a detector run by a detector plugin. Like custom regex
+// detectors, its results have the CustomRegex type and are told apart by the
+// detector's name.
+type Detector struct {
+	process     *process
+	client      detector_pluginpb.DetectorPluginClient
+	name        string
+	description string
+	keywords    []string
+}
+
+var (
+	_ detectors.Detector                   = (*Detector)(nil)
+	_ detectors.CustomFalsePositiveChecker = (*Detector)(nil)
+)
+
+// LoadDetector starts the detector plugin executable at path. The plugin keeps
+// running until the Detector is closed.
+func LoadDetector(ctx context.Context, path string) (*Detector, error) {
+	p, err := start(ctx, kindDetector, path)
+	if err != nil {
+		return nil, err
+	}
+	client := detector_pluginpb.NewDetectorPluginClient(p.conn)
+	desc, err := client.Describe(ctx, &detector_pluginpb.DescribeRequest{})
+	if err == nil {
+		err = validateDescription(desc)
+	}
+	if err != nil {
+		_ = p.stop()
+		return nil, fmt.Errorf("could not describe detector plugin %s: %w", path, err)
+	}
+	return &Detector{
+		process:     p,
+		client:      client,
+		name:        desc.GetName(),
+		description: desc.GetDescription(),
+		keywords:    desc.GetKeywords(),
+	}, nil
+}
+
+func validateDescription(desc *detector_pluginpb.DescribeResponse) error {
+	if desc.GetName() == "" {
+		return errors.New("no name")
+	}
+	return custom_detectors.ValidateKeywords(desc.GetKeywords())
+}
+
+// FromData scans data with the plugin.
+func (d *Detector) FromData(ctx stdcontext.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	resp, err := d.client.FromData(ctx, &detector_pluginpb.FromDataRequest{Verify: verify, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("detector plugin %s: %w", d.name, err)
+	}
+	results := make([]detectors.Result, 0, len(resp.GetResults()))
+	for _, r := range resp.GetResults() {
+		// As for custom regex detectors, the name is part of the extra data
+		// so outputs tell the results of different plugins apart.
+		extraData := make(map[string]string, len(r.GetExtraData())+1)
+		for k, v := range r.GetExtraData() {
+			extraData[k] = v
+		}
+		extraData["name"] = d.name
+		result := detectors.Result{
+			DetectorType: detectorspb.DetectorType_CustomRegex,
+			DetectorName: d.name,
+			Verified:     r.GetVerified(),
+			Raw:          r.GetRaw(),
+			RawV2:        r.GetRawV2(),
+			Redacted:     r.GetRedacted(),
+			ExtraData:    extraData,
+		}
+		if msg := r.GetVerificationError(); msg != "" {
+			result.SetVerificationError(errors.New(msg))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Name returns the name the plugin gave its detector.
+func (d *Detector) Name() string { return d.name }
+
+func (d *Detector) Keywords() []string { return d.keywords }
+
+func (d *Detector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_CustomRegex }
+
+func (d *Detector) Description() string { return d.description }
+
+// IsFalsePositive keeps every result, since plugins filter their own false
+// positives.
+func (d *Detector) IsFalsePositive(detectors.Result) (bool, string) { return false, "" }
+
+// Close stops the plugin.
+func (d *Detector) Close() error { return d.process.stop() }
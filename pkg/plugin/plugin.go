@@ -0,0 +1,184 @@
+// Package plugin runs detectors and sources shipped as separate executables, so
+// internal token formats and systems can be scanned without forking trufflehog.
+//
+// Plugins are started by trufflehog with the TRUFFLEHOG_PLUGIN environment
+// variable set to the kind of plugin expected. A plugin serves gRPC on a unix
+// socket, or a loopback TCP address on Windows, and announces it by writing a
+// handshake line to stdout:
+//
+//	PROTOCOL-VERSION|NETWORK|ADDRESS
+//
+// such as "1|unix|/tmp/plugin123/plugin.sock". Anything written to stderr is
+// logged. Plugins must exit once their stdin is closed, which happens when
+// trufflehog no longer needs them or exits itself.
+//
+// Detector plugins serve the DetectorPlugin service of detector_plugin.proto.
+// Plugins written in Go can be served with ServeDetector, and a new one can be
+// generated with the "trufflehog plugin new" command. Source plugins serve the
+// SourcePlugin service of source_plugin.proto, and can be served with
+// ServeSource.
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+const (
+	// ProtocolVersion is the version of the plugin protocol, announced in the
+	// handshake line. It is increased on incompatible changes.
+	ProtocolVersion = 1
+
+	// kindEnv tells a plugin which kind of plugin trufflehog expects it to
+	// be. Its absence means the plugin wasn't started by trufflehog.
+	kindEnv = "TRUFFLEHOG_PLUGIN"
+
+	kindDetector = "detector"
+	kindSource   = "source"
+)
+
+var (
+	// handshakeTimeout is how long a plugin has to write its handshake line.
+	handshakeTimeout = 30 * time.Second
+	// stopTimeout is how long a plugin has to exit once its stdin is closed
+	// before it is killed.
+	stopTimeout = 5 * time.Second
+)
+
+// process is a running plugin.
+type process struct {
+	cmd   *exec.Cmd
+	stdin io.Closer
+	conn  *grpc.ClientConn
+	done  chan struct{}
+}
+
+// start runs the plugin executable at path and connects to it once it has
+// completed the handshake.
+func start(ctx context.Context, kind, path string) (*process, error) {
+	name := filepath.Base(path)
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), kindEnv+"="+kind)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// exec copies the output into the pipes, so the plugin's output is read
+	// in full before Wait returns.
+	stdout, stdoutWriter := io.Pipe()
+	stderr, stderrWriter := io.Pipe()
+	cmd.Stdout, cmd.Stderr = stdoutWriter, stderrWriter
+	cmd.WaitDelay = stopTimeout
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start plugin %s: %w", name, err)
+	}
+
+	logger := ctx.Logger().WithValues("plugin", name)
+	p := &process{cmd: cmd, stdin: stdin, done: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		_ = stdoutWriter.Close()
+		_ = stderrWriter.Close()
+		close(p.done)
+	}()
+	go logOutput(logger, stderr)
+
+	handshake := make(chan string, 1)
+	go func() {
+		lines := bufio.NewScanner(stdout)
+		if lines.Scan() {
+			handshake <- lines.Text()
+		}
+		close(handshake)
+		// Later output isn't part of the protocol, but is kept for
+		// debugging.
+		for lines.Scan() {
+			logger.Info(lines.Text())
+		}
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+
+	var line string
+	select {
+	case line = <-handshake:
+	case <-time.After(handshakeTimeout):
+		p.stop()
+		return nil, fmt.Errorf("plugin %s did not complete the handshake within %s", name, handshakeTimeout)
+	case <-ctx.Done():
+		p.stop()
+		return nil, ctx.Err()
+	}
+	if line == "" {
+		p.stop()
+		return nil, fmt.Errorf("plugin %s exited before completing the handshake", name)
+	}
+	target, err := parseHandshake(line)
+	if err != nil {
+		p.stop()
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if p.conn, err = grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+		p.stop()
+		return nil, fmt.Errorf("could not connect to plugin %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// parseHandshake returns the gRPC target announced by a handshake line.
+func parseHandshake(line string) (string, error) {
+	parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid handshake %q", line)
+	}
+	if version, err := strconv.Atoi(parts[0]); err != nil || version != ProtocolVersion {
+		return "", fmt.Errorf("unsupported protocol version %q, expected %d", parts[0], ProtocolVersion)
+	}
+	switch network, addr := parts[1], parts[2]; network {
+	case "unix":
+		return "unix://" + addr, nil
+	case "tcp":
+		return addr, nil
+	default:
+		return "", fmt.Errorf("unsupported network %q", network)
+	}
+}
+
+// stop closes the connection to the plugin, and asks it to exit by closing
+// its stdin. Plugins that don't exit in time are killed.
+func (p *process) stop() error {
+	var errs []error
+	if p.conn != nil {
+		errs = append(errs, p.conn.Close())
+	}
+	_ = p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		errs = append(errs, p.cmd.Process.Kill())
+		<-p.done
+	}
+	return errors.Join(errs...)
+}
+
+func logOutput(logger logr.Logger, r io.Reader) {
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		logger.Info(lines.Text())
+	}
+	// Keep draining overly long lines so the plugin doesn't block.
+	_, _ = io.Copy(io.Discard, r)
+}
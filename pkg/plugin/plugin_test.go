@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// TestMain serves testDetector or testSource when the test binary is started
+// as a plugin.
+func TestMain(m *testing.M) {
+	var err error
+	switch os.Getenv(kindEnv) {
+	case "":
+		os.Exit(m.Run())
+	case kindSource:
+		err = ServeSource("test-source", testSource{})
+	default:
+		err = ServeDetector("test-token", testDetector{})
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+var testTokenPattern = regexp.MustCompile(`test_[a-z0-9]{8}`)
+
+type testDetector struct{}
+
+func (testDetector) FromData(_ stdcontext.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	var results []detectors.Result
+	for _, match := range testTokenPattern.FindAll(data, -1) {
+		result := detectors.Result{
+			DetectorType: detectorspb.DetectorType_CustomRegex,
+			Raw:          match,
+			ExtraData:    map[string]string{"length": fmt.Sprint(len(match))},
+		}
+		if verify {
+			switch string(match) {
+			case "test_verified":
+				result.Verified = true
+			case "test_timedout":
+				result.SetVerificationError(errors.New("request timed out"))
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (testDetector) Keywords() []string { return []string{"test_"} }
+
+func (testDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_CustomRegex }
+
+func (testDetector) Description() string { return "Test tokens." }
+
+func TestLoadDetector(t *testing.T) {
+	ctx := context.Background()
+	d, err := LoadDetector(ctx, os.Args[0])
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, d.Close()) }()
+
+	assert.Equal(t, "test-token", d.Name())
+	assert.Equal(t, []string{"test_"}, d.Keywords())
+	assert.Equal(t, "Test tokens.", d.Description())
+
+	results, err := d.FromData(ctx, true, []byte("a=test_verified b=test_timedout c=test_unproven"))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.Equal(t, detectorspb.DetectorType_CustomRegex, r.DetectorType)
+		assert.Equal(t, "test-token", r.DetectorName)
+		assert.Equal(t, map[string]string{"name": "test-token", "length": "13"}, r.ExtraData)
+	}
+	assert.Equal(t, "test_verified", string(results[0].Raw))
+	assert.True(t, results[0].Verified)
+	assert.EqualError(t, results[1].VerificationError(), "request timed out")
+	assert.False(t, results[2].Verified)
+	assert.NoError(t, results[2].VerificationError())
+
+	results, err = d.FromData(ctx, false, []byte("nothing to see"))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestLoadDetector_NotAPlugin(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "plugin.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho hello\n"), 0o755))
+
+	_, err := LoadDetector(context.Background(), script)
+	assert.ErrorContains(t, err, `invalid handshake "hello"`)
+}
+
+func TestServe_NotStartedByTrufflehog(t *testing.T) {
+	t.Setenv(kindEnv, "")
+	assert.Error(t, ServeDetector("test-token", testDetector{}))
+	t.Setenv(kindEnv, "source")
+	assert.EqualError(t, ServeDetector("test-token", testDetector{}), "trufflehog expected a source plugin, but this is a detector plugin")
+}
+
+func TestParseHandshake(t *testing.T) {
+	tests := map[string]struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		"unix":           {line: "1|unix|/tmp/plugin/plugin.sock\n", want: "unix:///tmp/plugin/plugin.sock"},
+		"tcp":            {line: "1|tcp|127.0.0.1:4242", want: "127.0.0.1:4242"},
+		"future version": {line: "2|unix|/tmp/plugin.sock", wantErr: true},
+		"unknown net":    {line: "1|udp|127.0.0.1:4242", wantErr: true},
+		"malformed":      {line: "listening on 4242", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseHandshake(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "acme-token")
+	require.NoError(t, Scaffold(dir, "acme-token", "example.com/acme-token"))
+
+	for _, name := range []string{"main.go", "detector.go", "detector_test.go"} {
+		_, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, 0)
+		assert.NoError(t, err, name)
+	}
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(goMod), "module example.com/acme-token\n")
+	detector, err := os.ReadFile(filepath.Join(dir, "detector.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(detector), `"acme_token_"`)
+
+	// Existing projects are left alone.
+	assert.Error(t, Scaffold(dir, "acme-token", "example.com/acme-token"))
+	assert.Error(t, Scaffold(t.TempDir(), "Acme Token", "example.com/acme"))
+}
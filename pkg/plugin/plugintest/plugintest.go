@@ -0,0 +1,127 @@
+// Package plugintest checks detector plugins against the plugin protocol and
+// the expectations trufflehog has of detectors, from the plugins' own tests.
+package plugintest
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plugin"
+)
+
+// Cases are the inputs a detector plugin is tested with.
+type Cases struct {
+	// Secrets are inputs the detector must find secrets in.
+	Secrets []string
+	// NonSecrets are inputs the detector must not find secrets in, such as
+	// secrets of the right format that are known to be invalid.
+	NonSecrets []string
+}
+
+// Build builds the Go detector plugin in dir, and returns the path of its
+// executable.
+func Build(t testing.TB, dir string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin")
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", path, ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "could not build plugin: %s", out)
+	return path
+}
+
+// TestDetector runs the detector plugin executable at path, and checks that
+// it behaves as trufflehog expects with the given cases. Results are never
+// verified, so the tests don't depend on the services the plugin verifies
+// secrets with.
+func TestDetector(t *testing.T, path string, cases Cases) {
+	t.Helper()
+	require.NotEmpty(t, cases.Secrets, "at least one input with a secret is needed to test the plugin")
+
+	ctx := context.Background()
+	d, err := plugin.LoadDetector(ctx, path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, d.Close(), "plugin did not stop")
+	})
+
+	t.Run("Describe", func(t *testing.T) {
+		assert.NotEmpty(t, d.Description(), "detectors should describe what they detect")
+		for _, keyword := range d.Keywords() {
+			assert.Equal(t, strings.TrimSpace(keyword), keyword, "keywords are matched as they are, so surrounding whitespace is likely a mistake")
+		}
+	})
+
+	t.Run("Secrets", func(t *testing.T) {
+		for _, input := range cases.Secrets {
+			assert.True(t, hasKeyword(d, input), "%q has none of the keywords, so trufflehog would never scan it with the detector", input)
+			results, err := d.FromData(ctx, false, []byte(input))
+			require.NoError(t, err)
+			assert.NotEmpty(t, results, "no secrets found in %q", input)
+			for _, r := range results {
+				assert.NotEmpty(t, r.Raw, "results must have the raw secret, which is used to deduplicate them")
+				assert.False(t, r.Verified, "results must not be verified unless verification is requested")
+			}
+		}
+	})
+
+	t.Run("NonSecrets", func(t *testing.T) {
+		for _, input := range cases.NonSecrets {
+			results, err := d.FromData(ctx, false, []byte(input))
+			require.NoError(t, err)
+			assert.Empty(t, results, "secrets found in %q", input)
+		}
+	})
+
+	t.Run("EmptyData", func(t *testing.T) {
+		results, err := d.FromData(ctx, false, nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	// trufflehog scans many chunks with a detector at once.
+	t.Run("Concurrent", func(t *testing.T) {
+		want := make([]int, len(cases.Secrets))
+		for i, input := range cases.Secrets {
+			results, err := d.FromData(ctx, false, []byte(input))
+			require.NoError(t, err)
+			want[i] = len(results)
+		}
+		var wg sync.WaitGroup
+		for range 8 {
+			for i, input := range cases.Secrets {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results, err := d.FromData(ctx, false, []byte(input))
+					if assert.NoError(t, err) {
+						assert.Len(t, results, want[i], "different results for %q when scanned concurrently", input)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+	})
+}
+
+func hasKeyword(d detectors.Detector, input string) bool {
+	input = strings.ToLower(input)
+	for _, keyword := range d.Keywords() {
+		if strings.Contains(input, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
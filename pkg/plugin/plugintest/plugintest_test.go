@@ -0,0 +1,51 @@
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/plugin"
+)
+
+// TestMain serves exampleDetector when the test binary is started as a
+// plugin.
+func TestMain(m *testing.M) {
+	if os.Getenv("TRUFFLEHOG_PLUGIN") != "" {
+		if err := plugin.ServeDetector("example", exampleDetector{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var examplePattern = regexp.MustCompile(`example_[a-z0-9]{8}\b`)
+
+type exampleDetector struct{}
+
+func (exampleDetector) FromData(_ context.Context, _ bool, data []byte) ([]detectors.Result, error) {
+	var results []detectors.Result
+	for _, match := range examplePattern.FindAll(data, -1) {
+		results = append(results, detectors.Result{DetectorType: detectorspb.DetectorType_CustomRegex, Raw: match})
+	}
+	return results, nil
+}
+
+func (exampleDetector) Keywords() []string { return []string{"example_"} }
+
+func (exampleDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_CustomRegex }
+
+func (exampleDetector) Description() string { return "Example tokens." }
+
+func TestTestDetector(t *testing.T) {
+	TestDetector(t, os.Args[0], Cases{
+		Secrets:    []string{"token: example_abcd1234", "EXAMPLE_TOKEN=example_00000000 example_11111111"},
+		NonSecrets: []string{"example_short", "example_abcd12345"},
+	})
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed scaffold/*.tmpl
+var scaffoldFiles embed.FS
+
+var pluginNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Scaffold writes a Go project for a new detector plugin named name to dir.
+// module is the module path of the project. Existing files are never
+// overwritten.
+func Scaffold(dir, name, module string) error {
+	if !pluginNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q: use lowercase letters, digits, and dashes", name)
+	}
+	data := struct {
+		Name, Module, Keyword, Example string
+	}{
+		Name:    name,
+		Module:  module,
+		Keyword: strings.ReplaceAll(name, "-", "_") + "_",
+		Example: "a1B2c3D4e5F6g7H8i9J0k1L2m3N4o5P6",
+	}
+
+	templates, err := template.ParseFS(scaffoldFiles, "scaffold/*.tmpl")
+	if err != nil {
+		return err
+	}
+	// Check every file first, so nothing is written if any file exists.
+	for _, tmpl := range templates.Templates() {
+		path := scaffoldPath(dir, tmpl)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, tmpl := range templates.Templates() {
+		path := scaffoldPath(dir, tmpl)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		err = tmpl.Execute(f, data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("could not write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func scaffoldPath(dir string, tmpl *template.Template) string {
+	return filepath.Join(dir, strings.TrimSuffix(tmpl.Name(), ".tmpl"))
+}
@@ -0,0 +1,216 @@
+package plugin
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detector_pluginpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_pluginpb"
+)
+
+// ServeDetector serves d as a detector plugin named name. It is meant to be
+// called from the main function of the plugin, and returns once trufflehog is
+// done with the plugin.
+func ServeDetector(name string, d detectors.Detector) error {
+	return serve(kindDetector, func(s *grpc.Server) {
+		detector_pluginpb.RegisterDetectorPluginServer(s, &detectorServer{name: name, detector: d})
+	})
+}
+
+// Chunker reads the data of a source plugin.
+type Chunker interface {
+	Description() string
+	// Chunks reads the source configured by settings, the JSON object of
+	// the settings in its connection, and reports its data to reporter. It
+	// returns once everything has been read, or on a fatal error.
+	Chunks(ctx stdcontext.Context, settings []byte, reporter SourceReporter) error
+}
+
+// SourceReporter receives the data of a source plugin. Its methods may be
+// called concurrently, and only fail once trufflehog stops the scan.
+type SourceReporter interface {
+	Chunk(chunk SourceChunk) error
+	// Progress reports that done of total items have been read. total is 0
+	// if it isn't known.
+	Progress(done, total int64, message string) error
+	// Error reports an error that doesn't stop the scan, such as an item
+	// that could not be read.
+	Error(err error) error
+}
+
+// SourceChunk is data read by a source plugin, along with where it was found.
+type SourceChunk struct {
+	Data []byte
+	File string
+	Link string
+	// Line is the line Data starts at within File, if any.
+	Line      int64
+	Timestamp string
+}
+
+// ServeSource serves c as a source plugin named name. Like ServeDetector, it
+// returns once trufflehog is done with the plugin.
+func ServeSource(name string, c Chunker) error {
+	return serve(kindSource, func(s *grpc.Server) {
+		source_pluginpb.RegisterSourcePluginServer(s, &sourceServer{name: name, chunker: c})
+	})
+}
+
+// serve serves the plugin of the given kind until its stdin is closed.
+func serve(kind string, register func(*grpc.Server)) error {
+	if got := os.Getenv(kindEnv); got != kind {
+		if got == "" {
+			return errors.New("this is a trufflehog plugin, and is meant to be run by trufflehog")
+		}
+		return fmt.Errorf("trufflehog expected a %s plugin, but this is a %s plugin", got, kind)
+	}
+
+	lis, network, cleanup, err := listen()
+	if err != nil {
+		return fmt.Errorf("could not listen: %w", err)
+	}
+	defer cleanup()
+
+	s := grpc.NewServer()
+	register(s)
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(lis) }()
+
+	if _, err := fmt.Fprintf(os.Stdout, "%d|%s|%s\n", ProtocolVersion, network, lis.Addr()); err != nil {
+		s.Stop()
+		return err
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		s.GracefulStop()
+		return nil
+	case err := <-served:
+		return err
+	}
+}
+
+// listen listens on a unix socket in a private directory, or on a loopback
+// TCP address where unix sockets aren't available.
+func listen() (net.Listener, string, func(), error) {
+	if runtime.GOOS == "windows" {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		return lis, "tcp", func() {}, err
+	}
+	dir, err := os.MkdirTemp("", "trufflehog-plugin")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	lis, err := net.Listen("unix", filepath.Join(dir, "plugin.sock"))
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return lis, "unix", cleanup, nil
+}
+
+// detectorServer serves a detector over the DetectorPlugin service.
+type detectorServer struct {
+	detector_pluginpb.UnimplementedDetectorPluginServer
+	name     string
+	detector detectors.Detector
+}
+
+func (s *detectorServer) Describe(stdcontext.Context, *detector_pluginpb.DescribeRequest) (*detector_pluginpb.DescribeResponse, error) {
+	return &detector_pluginpb.DescribeResponse{
+		Name:        s.name,
+		Description: s.detector.Description(),
+		Keywords:    s.detector.Keywords(),
+	}, nil
+}
+
+func (s *detectorServer) FromData(ctx stdcontext.Context, req *detector_pluginpb.FromDataRequest) (*detector_pluginpb.FromDataResponse, error) {
+	results, err := s.detector.FromData(ctx, req.GetVerify(), req.GetData())
+	if err != nil {
+		return nil, err
+	}
+	resp := &detector_pluginpb.FromDataResponse{Results: make([]*detector_pluginpb.Result, 0, len(results))}
+	for _, r := range results {
+		result := &detector_pluginpb.Result{
+			Verified:  r.Verified,
+			Raw:       r.Raw,
+			RawV2:     r.RawV2,
+			Redacted:  r.Redacted,
+			ExtraData: r.ExtraData,
+		}
+		if err := r.VerificationError(); err != nil {
+			result.VerificationError = err.Error()
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// sourceServer serves a source over the SourcePlugin service.
+type sourceServer struct {
+	source_pluginpb.UnimplementedSourcePluginServer
+	name    string
+	chunker Chunker
+}
+
+func (s *sourceServer) Describe(stdcontext.Context, *source_pluginpb.DescribeRequest) (*source_pluginpb.DescribeResponse, error) {
+	return &source_pluginpb.DescribeResponse{
+		Name:        s.name,
+		Description: s.chunker.Description(),
+	}, nil
+}
+
+func (s *sourceServer) Chunks(req *source_pluginpb.ChunksRequest, stream grpc.ServerStreamingServer[source_pluginpb.ChunksResponse]) error {
+	return s.chunker.Chunks(stream.Context(), req.GetSettings(), &streamReporter{stream: stream})
+}
+
+// streamReporter sends the data of a source over its Chunks stream, which
+// can't be sent to concurrently.
+type streamReporter struct {
+	mu     sync.Mutex
+	stream grpc.ServerStreamingServer[source_pluginpb.ChunksResponse]
+}
+
+func (r *streamReporter) send(resp *source_pluginpb.ChunksResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stream.Send(resp)
+}
+
+func (r *streamReporter) Chunk(chunk SourceChunk) error {
+	return r.send(&source_pluginpb.ChunksResponse{Response: &source_pluginpb.ChunksResponse_Chunk{
+		Chunk: &source_pluginpb.Chunk{
+			Data:      chunk.Data,
+			File:      chunk.File,
+			Link:      chunk.Link,
+			Line:      chunk.Line,
+			Timestamp: chunk.Timestamp,
+		},
+	}})
+}
+
+func (r *streamReporter) Progress(done, total int64, message string) error {
+	return r.send(&source_pluginpb.ChunksResponse{Response: &source_pluginpb.ChunksResponse_Progress{
+		Progress: &source_pluginpb.Progress{Done: done, Total: total, Message: message},
+	}})
+}
+
+func (r *streamReporter) Error(err error) error {
+	return r.send(&source_pluginpb.ChunksResponse{Response: &source_pluginpb.ChunksResponse_Error{Error: err.Error()}})
+}
@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_pluginpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// SourceType is the type of sources read by source plugins.
+const SourceType = sourcespb.SourceType_SOURCE_TYPE_PLUGIN
+
+// Source is a source read by a source plugin. The plugin is started when the
+// source is scanned, and stopped once it has sent all its chunks.
+type Source struct {
+	name     string
+	sourceID sources.SourceID
+	jobID    sources.JobID
+	verify   bool
+	path     string
+	settings []byte
+	sources.Progress
+}
+
+var _ sources.Source = (*Source)(nil)
+
+// Type returns the type of source.
+func (s *Source) Type() sourcespb.SourceType { return SourceType }
+
+func (s *Source) SourceID() sources.SourceID { return s.sourceID }
+
+func (s *Source) JobID() sources.JobID { return s.jobID }
+
+// Init configures the source with a sourcespb.Plugin connection.
+func (s *Source) Init(_ context.Context, name string, jobID sources.JobID, sourceID sources.SourceID, verify bool, connection *anypb.Any, _ int) error {
+	s.name = name
+	s.jobID = jobID
+	s.sourceID = sourceID
+	s.verify = verify
+
+	var conn sourcespb.Plugin
+	if err := anypb.UnmarshalTo(connection, &conn, proto.UnmarshalOptions{}); err != nil {
+		return fmt.Errorf("error unmarshalling connection: %w", err)
+	}
+	if conn.GetPath() == "" {
+		return errors.New("plugin sources require the path of the plugin")
+	}
+	if _, err := os.Stat(conn.GetPath()); err != nil {
+		return fmt.Errorf("invalid source plugin: %w", err)
+	}
+	s.path = conn.GetPath()
+
+	s.settings = []byte("{}")
+	if conn.GetSettings() != nil {
+		settings, err := protojson.Marshal(conn.GetSettings())
+		if err != nil {
+			return fmt.Errorf("invalid settings of source plugin %s: %w", s.path, err)
+		}
+		s.settings = settings
+	}
+	return nil
+}
+
+// HealthCheck starts the plugin and checks that it describes itself. Plugins
+// have no way to check their settings short of streaming chunks.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	p, err := start(ctx, kindSource, s.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = p.stop() }()
+
+	desc, err := source_pluginpb.NewSourcePluginClient(p.conn).Describe(ctx, &source_pluginpb.DescribeRequest{})
+	if err == nil && desc.GetName() == "" {
+		err = errors.New("no name")
+	}
+	if err != nil {
+		return fmt.Errorf("could not describe source plugin %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Chunks runs the plugin and sends the chunks it streams to chunksChan.
+// Errors the plugin reports without ending the stream are logged.
+func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
+	p, err := start(ctx, kindSource, s.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = p.stop() }()
+
+	client := source_pluginpb.NewSourcePluginClient(p.conn)
+	desc, err := client.Describe(ctx, &source_pluginpb.DescribeRequest{})
+	if err == nil && desc.GetName() == "" {
+		err = errors.New("no name")
+	}
+	if err != nil {
+		return fmt.Errorf("could not describe source plugin %s: %w", s.path, err)
+	}
+	pluginName := desc.GetName()
+	ctx = context.WithValues(ctx, "plugin", pluginName)
+
+	stream, err := client.Chunks(ctx, &source_pluginpb.ChunksRequest{Settings: s.settings})
+	if err != nil {
+		return fmt.Errorf("source plugin %s: %w", pluginName, err)
+	}
+	var done, total int64
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("source plugin %s: %w", pluginName, err)
+		}
+
+		switch r := resp.GetResponse().(type) {
+		case *source_pluginpb.ChunksResponse_Chunk:
+			chunk := &sources.Chunk{
+				SourceType: s.Type(),
+				SourceName: s.name,
+				SourceID:   s.sourceID,
+				JobID:      s.jobID,
+				Data:       r.Chunk.GetData(),
+				SourceMetadata: &source_metadatapb.MetaData{
+					Data: &source_metadatapb.MetaData_Plugin{
+						Plugin: &source_metadatapb.Plugin{
+							Plugin:    pluginName,
+							File:      r.Chunk.GetFile(),
+							Link:      r.Chunk.GetLink(),
+							Line:      r.Chunk.GetLine(),
+							Timestamp: r.Chunk.GetTimestamp(),
+						},
+					},
+				},
+				Verify: s.verify,
+			}
+			select {
+			case chunksChan <- chunk:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case *source_pluginpb.ChunksResponse_Progress:
+			done, total = r.Progress.GetDone(), r.Progress.GetTotal()
+			if total > 0 {
+				s.SetProgressComplete(int(done), int(total), r.Progress.GetMessage(), "")
+			} else {
+				s.SetProgressOngoing(r.Progress.GetMessage(), "")
+			}
+		case *source_pluginpb.ChunksResponse_Error:
+			ctx.Logger().Error(errors.New(r.Error), "error reading source plugin data")
+		}
+	}
+	total = max(done, total)
+	s.SetProgressComplete(int(total), int(total), fmt.Sprintf("Completed %s scan", pluginName), "")
+	return nil
+}
@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// testSource reads the pages in its settings, and fails if asked to.
+type testSource struct{}
+
+func (testSource) Description() string { return "Test pages." }
+
+func (testSource) Chunks(_ stdcontext.Context, settings []byte, reporter SourceReporter) error {
+	var conf struct {
+		Pages []string `json:"pages"`
+		Fail  bool     `json:"fail"`
+	}
+	if err := json.Unmarshal(settings, &conf); err != nil {
+		return err
+	}
+	if conf.Fail {
+		return errors.New("wiki is down")
+	}
+	for i, page := range conf.Pages {
+		if page == "" {
+			if err := reporter.Error(fmt.Errorf("page %d is empty", i)); err != nil {
+				return err
+			}
+			continue
+		}
+		chunk := SourceChunk{
+			Data: []byte(page),
+			File: fmt.Sprintf("pages/%d", i),
+			Link: fmt.Sprintf("https://wiki.example.com/pages/%d", i),
+			Line: 1,
+		}
+		if err := reporter.Chunk(chunk); err != nil {
+			return err
+		}
+		if err := reporter.Progress(int64(i+1), int64(len(conf.Pages)), chunk.File); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func initTestSource(t *testing.T, settings map[string]any) *Source {
+	t.Helper()
+	conn := &sourcespb.Plugin{Path: os.Args[0]}
+	if settings != nil {
+		var err error
+		conn.Settings, err = structpb.NewStruct(settings)
+		require.NoError(t, err)
+	}
+	var connection anypb.Any
+	require.NoError(t, anypb.MarshalFrom(&connection, conn, proto.MarshalOptions{}))
+
+	s := &Source{}
+	require.NoError(t, s.Init(context.Background(), "test", 1, 2, true, &connection, 1))
+	return s
+}
+
+func collectChunks(s *Source) ([]*sources.Chunk, error) {
+	chunksChan := make(chan *sources.Chunk)
+	var err error
+	go func() {
+		defer close(chunksChan)
+		err = s.Chunks(context.Background(), chunksChan)
+	}()
+	var chunks []*sources.Chunk
+	for chunk := range chunksChan {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, err
+}
+
+func TestSource_Chunks(t *testing.T) {
+	s := initTestSource(t, map[string]any{"pages": []any{"token: test_abcd1234", "", "nothing"}})
+
+	chunks, err := collectChunks(s)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "token: test_abcd1234", string(chunks[0].Data))
+	assert.Equal(t, "nothing", string(chunks[1].Data))
+	for _, chunk := range chunks {
+		assert.Equal(t, SourceType, chunk.SourceType)
+		assert.Equal(t, "test", chunk.SourceName)
+		assert.Equal(t, sources.JobID(1), chunk.JobID)
+		assert.Equal(t, sources.SourceID(2), chunk.SourceID)
+		assert.True(t, chunk.Verify)
+	}
+	assert.True(t, proto.Equal(&source_metadatapb.Plugin{
+		Plugin: "test-source",
+		File:   "pages/0",
+		Link:   "https://wiki.example.com/pages/0",
+		Line:   1,
+	}, chunks[0].SourceMetadata.GetPlugin()))
+
+	progress := s.GetProgress()
+	assert.Equal(t, int64(100), progress.PercentComplete)
+	assert.Equal(t, "Completed test-source scan", progress.Message)
+}
+
+func TestSource_Chunks_NoSettings(t *testing.T) {
+	chunks, err := collectChunks(initTestSource(t, nil))
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestSource_Chunks_Error(t *testing.T) {
+	_, err := collectChunks(initTestSource(t, map[string]any{"fail": true}))
+	assert.ErrorContains(t, err, "wiki is down")
+}
+
+func TestSource_HealthCheck(t *testing.T) {
+	assert.NoError(t, initTestSource(t, nil).HealthCheck(context.Background()))
+}
+
+func TestSource_Init(t *testing.T) {
+	for name, conn := range map[string]*sourcespb.Plugin{
+		"no path":      {},
+		"missing path": {Path: "/nonexistent/plugin"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var connection anypb.Any
+			require.NoError(t, anypb.MarshalFrom(&connection, conn, proto.MarshalOptions{}))
+			assert.Error(t, (&Source{}).Init(context.Background(), "test", 1, 2, true, &connection, 1))
+		})
+	}
+}
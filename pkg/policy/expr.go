@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/interpreter"
+)
+
+// Rules are CEL expressions (https://cel.dev), with the string extensions of
+// cel-go, over a finding or summary variable whose fields are named by the
+// cel tags of Finding and Summary.
+
+// maxCost limits what a rule may cost to evaluate, in cel-go's cost units,
+// so a rule with a runaway comprehension fails instead of stalling the scan.
+const maxCost = 1 << 20
+
+var (
+	findingEnv = newEnv("finding", Finding{})
+	summaryEnv = newEnv("summary", Summary{})
+)
+
+// newEnv declares the variable name as the native type of v.
+func newEnv(name string, v any) *cel.Env {
+	t := reflect.TypeOf(v)
+	env, err := cel.NewEnv(
+		ext.NativeTypes(t, ext.ParseStructTags(true)),
+		ext.Strings(),
+		cel.Variable(name, cel.ObjectType("policy."+t.Name())),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("policy: could not declare %s: %v", name, err))
+	}
+	return env
+}
+
+// compile checks src against env and returns its program, which must
+// evaluate to a bool.
+func compile(env *cel.Env, src string) (cel.Program, error) {
+	ast, iss := env.Compile(src)
+	if err := iss.Err(); err != nil {
+		return nil, err
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression is a %s, not a bool", t)
+	}
+	// Constant patterns of matches are compiled here, so invalid ones are
+	// reported when the policy is loaded.
+	return env.Program(ast, cel.CostLimit(maxCost), cel.OptimizeRegex(interpreter.MatchesRegexOptimization))
+}
+
+func evalBool(p cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := p.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression is a %s, not a bool", out.Type())
+	}
+	return b, nil
+}
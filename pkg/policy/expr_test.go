@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFinding = map[string]any{"finding": Finding{
+	File:       "src/test/config.yaml",
+	Line:       3,
+	Categories: []string{"a", "b"},
+	Extra:      map[string]string{"account": "123"},
+}}
+
+func TestEval(t *testing.T) {
+	tests := map[string]bool{
+		`1 + 2 * 3 == 7`:                                      true,
+		`-finding.line % 2 == -1`:                             true,
+		`"a" + 'b' == "ab"`:                                   true,
+		`finding.line > 2 && finding.line <= 3`:               true,
+		`finding.line == 3 || finding.extra["missing"] == ""`: true,
+		`!(finding.line != 3)`:                                true,
+		`"b" in finding.categories`:                           true,
+		`"c" in finding.categories`:                           false,
+		`"account" in finding.extra`:                          true,
+		`finding.categories[1] == "b"`:                        true,
+		`size(finding.categories) == 2`:                       true,
+		`finding.file.size() == 20`:                           true,
+		`finding.file.contains("/test/")`:                     true,
+		`finding.file.startsWith("src/")`:                     true,
+		`finding.file.endsWith(".json")`:                      false,
+		`finding.file.matches(r'\.ya?ml$')`:                   true,
+		`finding.file.matches("^" + "src")`:                   true,
+		`"ABC".lowerAscii() == "abc"`:                         true,
+		`(finding.line > 2 ? "many" : "few") == "many"`:       true,
+		`finding.categories.exists(c, c == "a")`:              true,
+		`false && finding.extra["missing"] == ""`:             false,
+		`finding.detector == "" && !finding.verified`:         true,
+	}
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			p, err := compile(findingEnv, src)
+			require.NoError(t, err)
+			got, err := evalBool(p, testFinding)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	for _, src := range []string{
+		`finding.line / 0 == 1`,
+		`finding.categories[5] == "a"`,
+		`finding.extra["missing"] == ""`,
+		`finding.file.matches(finding.extra["account"] + "(")`,
+	} {
+		t.Run(src, func(t *testing.T) {
+			p, err := compile(findingEnv, src)
+			require.NoError(t, err)
+			_, err = evalBool(p, testFinding)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEval_CostLimit(t *testing.T) {
+	list := make([]string, 100)
+	for i := range list {
+		list[i] = fmt.Sprint(i)
+	}
+	l := "[" + strings.Join(list, ", ") + "]"
+	// 100^4 iterations are far above the limit.
+	src := fmt.Sprintf("%s.all(a, %s.all(b, %s.all(c, %s.all(d, a + b + c + d >= 0))))", l, l, l, l)
+	p, err := compile(findingEnv, src)
+	require.NoError(t, err)
+	_, err = evalBool(p, testFinding)
+	assert.ErrorContains(t, err, "cost limit exceeded")
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := map[string]string{
+		`summary.verified`:             `undeclared reference to 'summary'`,
+		`finding.fiel == ""`:           `undefined field 'fiel'`,
+		`finding.file.foo()`:           `undeclared reference to 'foo'`,
+		`finding.line + "a" == ""`:     `no matching overload`,
+		`!finding.line`:                `no matching overload`,
+		`finding.file`:                 `not a bool`,
+		`finding.line >`:               `Syntax error`,
+		`(finding.verified`:            `Syntax error`,
+		`"unterminated`:                `Syntax error`,
+		`finding.file.matches("(")`:    `error parsing regexp`,
+		`finding.file.startsWith("a",`: `Syntax error`,
+	}
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			_, err := compile(findingEnv, src)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), want)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package policy decides whether a scan passes or fails from rules written
+// as expressions over each finding and over the summary of the scan. A
+// policy file lists the rules in YAML:
+//
+//	findings:
+//	  - name: verified cloud credentials outside tests
+//	    fail: >-
+//	      finding.verified && finding.detector in ["AWS", "GCP", "Azure"] &&
+//	      !finding.file.contains("/test/")
+//	summary:
+//	  - name: too many unverified findings
+//	    fail: summary.unverified > 50
+//
+// A scan fails when any finding makes a finding rule true, or when a summary
+// rule is true once the scan has finished.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+// Finding is the data finding rules are evaluated with, as the finding
+// variable.
+type Finding struct {
+	Detector string `cel:"detector"`
+	Decoder  string `cel:"decoder"`
+	Verified bool   `cel:"verified"`
+	// Severity is high, medium, or low.
+	Severity   string            `cel:"severity"`
+	Source     string            `cel:"source"`
+	SourceType string            `cel:"source_type"`
+	File       string            `cel:"file"`
+	Line       int64             `cel:"line"`
+	Repository string            `cel:"repository"`
+	Commit     string            `cel:"commit"`
+	Email      string            `cel:"email"`
+	Link       string            `cel:"link"`
+	Extra      map[string]string `cel:"extra"`
+	// Categories are the categories of the file the finding is in, such as
+	// test or lockfile.
+	Categories []string `cel:"categories"`
+	// Reachability is public, internal, or private, if the source of the
+	// finding tells who can read its location.
+	Reachability string `cel:"reachability"`
+}
+
+// Summary is the data summary rules are evaluated with, as the summary
+// variable.
+type Summary struct {
+	Findings   int64 `cel:"findings"`
+	Verified   int64 `cel:"verified"`
+	Unknown    int64 `cel:"unknown"`
+	Unverified int64 `cel:"unverified"`
+	// Violations is the number of findings that failed a finding rule.
+	Violations      int64 `cel:"violations"`
+	Chunks          int64 `cel:"chunks"`
+	Bytes           int64 `cel:"bytes"`
+	Errors          int64 `cel:"errors"`
+	DurationSeconds int64 `cel:"duration_seconds"`
+	Interrupted     bool  `cel:"interrupted"`
+}
+
+type rule struct {
+	name    string
+	program cel.Program
+}
+
+// Policy is a compiled set of finding and summary rules. The zero value and
+// nil have no rules and never fail.
+type Policy struct {
+	findings []rule
+	summary  []rule
+}
+
+type ruleFile struct {
+	Name string `yaml:"name"`
+	Fail string `yaml:"fail"`
+}
+
+type policyFile struct {
+	Findings []ruleFile `yaml:"findings"`
+	Summary  []ruleFile `yaml:"summary"`
+}
+
+// Load reads and compiles the policy file at path.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse compiles a policy from its YAML representation.
+func Parse(data []byte) (*Policy, error) {
+	var file policyFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse policy: %w", err)
+	}
+	findings, err := compileRules("findings", file.Findings, findingEnv)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := compileRules("summary", file.Summary, summaryEnv)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{findings: findings, summary: summary}, nil
+}
+
+func compileRules(section string, files []ruleFile, env *cel.Env) ([]rule, error) {
+	rules := make([]rule, 0, len(files))
+	for i, f := range files {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", section, i)
+		}
+		if f.Fail == "" {
+			return nil, fmt.Errorf("policy rule %q: missing fail expression", name)
+		}
+		program, err := compile(env, f.Fail)
+		if err != nil {
+			return nil, fmt.Errorf("policy rule %q: %w", name, err)
+		}
+		rules = append(rules, rule{name: name, program: program})
+	}
+	return rules, nil
+}
+
+// EvalFinding returns the names of the finding rules f fails. Rules that
+// can't be evaluated for f are reported in the error and don't fail it.
+func (p *Policy) EvalFinding(f Finding) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return eval(p.findings, map[string]any{"finding": f})
+}
+
+// EvalSummary returns the names of the summary rules s fails.
+func (p *Policy) EvalSummary(s Summary) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return eval(p.summary, map[string]any{"summary": s})
+}
+
+func eval(rules []rule, vars map[string]any) ([]string, error) {
+	var (
+		failed []string
+		errs   []error
+	)
+	for _, r := range rules {
+		ok, err := evalBool(r.program, vars)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy rule %q: %w", r.name, err))
+			continue
+		}
+		if ok {
+			failed = append(failed, r.name)
+		}
+	}
+	if len(errs) > 0 {
+		return failed, errors.Join(errs...)
+	}
+	return failed, nil
+}
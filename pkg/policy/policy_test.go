@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+findings:
+  - name: verified cloud credentials outside tests
+    fail: >-
+      finding.verified && finding.detector in ["AWS", "GCP", "Azure"] &&
+      !finding.file.contains("/test/")
+  - fail: finding.extra["rotation_guide"] == "none"
+summary:
+  - name: too many unverified findings
+    fail: summary.unverified > 2
+`
+
+func TestPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o644))
+	p, err := Load(path)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		finding Finding
+		want    []string
+		wantErr bool
+	}{
+		"verified aws": {
+			finding: Finding{Detector: "AWS", Verified: true, File: "src/config.yaml", Extra: map[string]string{"rotation_guide": "x"}},
+			want:    []string{"verified cloud credentials outside tests"},
+		},
+		"verified aws in tests": {
+			finding: Finding{Detector: "AWS", Verified: true, File: "src/test/config.yaml", Extra: map[string]string{"rotation_guide": "x"}},
+		},
+		"unverified aws": {
+			finding: Finding{Detector: "AWS", File: "src/config.yaml", Extra: map[string]string{"rotation_guide": "x"}},
+		},
+		"both rules": {
+			finding: Finding{Detector: "GCP", Verified: true, Extra: map[string]string{"rotation_guide": "none"}},
+			want:    []string{"verified cloud credentials outside tests", "findings[1]"},
+		},
+		"missing extra data": {
+			finding: Finding{Detector: "Azure", Verified: true},
+			want:    []string{"verified cloud credentials outside tests"},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := p.EvalFinding(tt.finding)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, `policy rule "findings[1]"`)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := p.EvalSummary(Summary{Unverified: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"too many unverified findings"}, got)
+	got, err = p.EvalSummary(Summary{Unverified: 2})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestPolicy_Categories(t *testing.T) {
+	p, err := Parse([]byte("findings:\n  - name: outside tests\n    fail: '!(\"test\" in finding.categories)'\n"))
+	require.NoError(t, err)
+
+	got, err := p.EvalFinding(Finding{Categories: []string{"test", "generated"}})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = p.EvalFinding(Finding{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outside tests"}, got)
+}
+
+func TestPolicy_Reachability(t *testing.T) {
+	p, err := Parse([]byte("findings:\n  - name: public secrets\n    fail: 'finding.reachability == \"public\"'\n"))
+	require.NoError(t, err)
+
+	got, err := p.EvalFinding(Finding{Reachability: "public"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public secrets"}, got)
+	got, err = p.EvalFinding(Finding{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":   "findings:\n  - name: a\n    fial: finding.verified\n",
+		"missing fail":    "findings:\n  - name: a\n",
+		"wrong variable":  "findings:\n  - fail: summary.verified > 0\n",
+		"bad expression":  "summary:\n  - fail: summary.verified >\n",
+		"not a rule list": "findings: true\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	got, err := p.EvalFinding(Finding{Verified: true})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = p.EvalSummary(Summary{Verified: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	p, err = Parse(nil)
+	require.NoError(t, err)
+	got, err = p.EvalFinding(Finding{Verified: true})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,32 @@
+// Package qrcode decodes the QR codes in images, such as screenshots of the
+// TOTP provisioning codes of two-factor authentication setups and of the
+// Wi-Fi configs printed for guests, whose payloads are credentials.
+package qrcode
+
+import (
+	"image"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/multi/qrcode"
+)
+
+// Decode returns the payloads of the QR codes in img.
+func Decode(img image.Image) []string {
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil
+	}
+	hints := map[gozxing.DecodeHintType]any{gozxing.DecodeHintType_TRY_HARDER: true}
+	// An image without codes is an error.
+	results, _ := qrcode.NewQRCodeMultiReader().DecodeMultiple(bitmap, hints)
+
+	var payloads []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if payload := result.GetText(); !seen[payload] {
+			seen[payload] = true
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
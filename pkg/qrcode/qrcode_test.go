@@ -0,0 +1,86 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// render draws the code of payload with modules of scale pixels, turned by
+// angle around its center.
+func render(t *testing.T, payload string, scale int, angle float64) *image.Gray {
+	t.Helper()
+	// With a width of 1, the writer returns one pixel per module.
+	m, err := qrcode.NewQRCodeWriter().Encode(payload, gozxing.BarcodeFormat_QR_CODE, 1, 1, nil)
+	require.NoError(t, err)
+	dim := m.GetWidth()
+	size := dim * scale
+	if angle != 0 {
+		size = int(float64(size) * math.Sqrt2)
+	}
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	center := float64(size) / 2
+	sin, cos := math.Sincos(-angle)
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			dx, dy := float64(px)+0.5-center, float64(py)+0.5-center
+			x := (dx*cos-dy*sin)/float64(scale) + float64(dim)/2
+			y := (dx*sin+dy*cos)/float64(scale) + float64(dim)/2
+			c := color.Gray{Y: 0xFF}
+			if x >= 0 && y >= 0 && x < float64(dim) && y < float64(dim) && m.Get(int(x), int(y)) {
+				c.Y = 0
+			}
+			img.SetGray(px, py, c)
+		}
+	}
+	return img
+}
+
+func TestDecode(t *testing.T) {
+	payload := "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example"
+	tests := []struct {
+		name  string
+		scale int
+		angle float64
+	}{
+		{name: "upright", scale: 4},
+		{name: "small modules", scale: 2},
+		{name: "quarter turn", scale: 3, angle: math.Pi / 2},
+		{name: "tilted", scale: 6, angle: 0.3},
+		{name: "diagonal", scale: 6, angle: math.Pi / 4},
+		{name: "upside down", scale: 5, angle: math.Pi},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []string{payload}, Decode(render(t, payload, tt.scale, tt.angle)))
+		})
+	}
+}
+
+func TestDecodeTwoCodes(t *testing.T) {
+	left, right := render(t, "WIFI:T:WPA;S:guest;P:hunter22;;", 4, 0), render(t, "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP", 4, 0)
+	img := image.NewGray(image.Rect(0, 0, left.Bounds().Dx()+right.Bounds().Dx(), max(left.Bounds().Dy(), right.Bounds().Dy())))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	draw.Draw(img, left.Bounds(), left, image.Point{}, draw.Src)
+	draw.Draw(img, right.Bounds().Add(image.Pt(left.Bounds().Dx(), 0)), right, image.Point{}, draw.Src)
+	assert.ElementsMatch(t, []string{"WIFI:T:WPA;S:guest;P:hunter22;;", "otpauth://totp/Example?secret=JBSWY3DPEHPK3PXP"}, Decode(img))
+}
+
+func TestDecodeNoCodes(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x * 4)})
+		}
+	}
+	assert.Empty(t, Decode(img))
+}
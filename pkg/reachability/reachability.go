@@ -0,0 +1,71 @@
+// Package reachability classifies the locations secrets are found at by who
+// can read them: anyone, everyone in an organization, or only those granted
+// access. Secrets anyone can read are the likeliest to have been found by
+// someone else already.
+package reachability
+
+import (
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+// Scope is who can read the location of a secret.
+type Scope string
+
+const (
+	// Public locations can be read by anyone, such as public repositories
+	// and buckets.
+	Public Scope = "public"
+	// Internal locations can be read by every member of an organization,
+	// such as GitHub and GitLab internal repositories.
+	Internal Scope = "internal"
+	// Private locations can only be read by those granted access.
+	Private Scope = "private"
+)
+
+// gcsPublicEntities are the ACL entities granting access to anyone, signed in
+// to Google or not.
+var gcsPublicEntities = map[string]struct{}{
+	"allUsers":              {},
+	"allAuthenticatedUsers": {},
+}
+
+// Of returns the scope of the location described by md, or "" if its source
+// doesn't tell. Only GitHub and GitLab repositories, and S3 and GCS
+// objects, are classified.
+func Of(md *source_metadatapb.MetaData) Scope {
+	switch m := md.GetData().(type) {
+	case *source_metadatapb.MetaData_Github:
+		return fromVisibility(m.Github.GetVisibility())
+	case *source_metadatapb.MetaData_Gitlab:
+		return fromVisibility(m.Gitlab.GetVisibility())
+	case *source_metadatapb.MetaData_S3:
+		return fromVisibility(m.S3.GetVisibility())
+	case *source_metadatapb.MetaData_Gcs:
+		// Buckets with uniform access control have no object ACLs.
+		acls := m.Gcs.GetAcls()
+		if len(acls) == 0 {
+			return ""
+		}
+		for _, entity := range acls {
+			if _, ok := gcsPublicEntities[entity]; ok {
+				return Public
+			}
+		}
+		return Private
+	default:
+		return ""
+	}
+}
+
+func fromVisibility(v source_metadatapb.Visibility) Scope {
+	switch v {
+	case source_metadatapb.Visibility_public:
+		return Public
+	case source_metadatapb.Visibility_internal:
+		return Internal
+	case source_metadatapb.Visibility_private:
+		return Private
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,63 @@
+package reachability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		md   *source_metadatapb.MetaData
+		want Scope
+	}{
+		{
+			name: "public github repository",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Github{Github: &source_metadatapb.Github{Visibility: source_metadatapb.Visibility_public}}},
+			want: Public,
+		},
+		{
+			name: "internal gitlab project",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Gitlab{Gitlab: &source_metadatapb.Gitlab{Visibility: source_metadatapb.Visibility_internal}}},
+			want: Internal,
+		},
+		{
+			name: "gitlab repository that wasn't enumerated",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Gitlab{Gitlab: &source_metadatapb.Gitlab{Visibility: source_metadatapb.Visibility_unknown}}},
+			want: "",
+		},
+		{
+			name: "private s3 bucket",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_S3{S3: &source_metadatapb.S3{Visibility: source_metadatapb.Visibility_private}}},
+			want: Private,
+		},
+		{
+			name: "gcs object readable by all users",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Gcs{Gcs: &source_metadatapb.GCS{Acls: []string{"project-owners-123", "allUsers"}}}},
+			want: Public,
+		},
+		{
+			name: "gcs object readable by its project",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Gcs{Gcs: &source_metadatapb.GCS{Acls: []string{"project-owners-123"}}}},
+			want: Private,
+		},
+		{
+			name: "gcs bucket with uniform access",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Gcs{Gcs: &source_metadatapb.GCS{}}},
+			want: "",
+		},
+		{
+			name: "filesystem",
+			md:   &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Filesystem{Filesystem: &source_metadatapb.Filesystem{File: "config.yaml"}}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Of(tt.md))
+		})
+	}
+}
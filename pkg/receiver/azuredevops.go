@@ -0,0 +1,124 @@
+package receiver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// azureDevOps receives Azure DevOps service hooks. Service hooks aren't
+// signed; they're authenticated with the basic authentication password
+// configured on the subscription.
+func (r *Receiver) azureDevOps(w http.ResponseWriter, req *http.Request) {
+	if r.conf.AzureDevOpsSecret == "" {
+		http.Error(w, "Azure DevOps service hooks aren't enabled", http.StatusNotFound)
+		return
+	}
+	_, password, _ := req.BasicAuth()
+	if subtle.ConstantTimeCompare([]byte(password), []byte(r.conf.AzureDevOpsSecret)) != 1 {
+		http.Error(w, "invalid password", http.StatusUnauthorized)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 25<<20))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	var e azureDevOpsEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	var scans []scanRequest
+	switch e.EventType {
+	case "git.push":
+		scans = azureDevOpsPush(e)
+	case "git.pullrequest.created", "git.pullrequest.updated":
+		scans = azureDevOpsPullRequest(e)
+	}
+	r.accept(w, scans)
+}
+
+type azureDevOpsEvent struct {
+	EventType string `json:"eventType"`
+	Resource  struct {
+		Repository azureDevOpsRepository `json:"repository"`
+
+		// Set on pushes.
+		Commits    []json.RawMessage `json:"commits"`
+		RefUpdates []struct {
+			Name        string `json:"name"`
+			OldObjectID string `json:"oldObjectId"`
+			NewObjectID string `json:"newObjectId"`
+		} `json:"refUpdates"`
+
+		// Set on pull requests.
+		PullRequestID         int    `json:"pullRequestId"`
+		Status                string `json:"status"`
+		TargetRefName         string `json:"targetRefName"`
+		LastMergeSourceCommit struct {
+			CommitID string `json:"commitId"`
+		} `json:"lastMergeSourceCommit"`
+	} `json:"resource"`
+}
+
+type azureDevOpsRepository struct {
+	Name string `json:"name"`
+	// URL is the API URL of the repository.
+	URL       string `json:"url"`
+	RemoteURL string `json:"remoteUrl"`
+	Project   struct {
+		Name string `json:"name"`
+	} `json:"project"`
+}
+
+// scan returns the scan of the repository, without its range of commits.
+func (r azureDevOpsRepository) scan() scanRequest {
+	// Remote URLs name the organization as user, which would be used to
+	// clone instead of the configured one.
+	cloneURL := r.RemoteURL
+	if u, err := url.Parse(r.RemoteURL); err == nil {
+		u.User = nil
+		cloneURL = u.String()
+	}
+	return scanRequest{
+		host:     hostAzureDevOps,
+		repo:     r.Project.Name + "/" + r.Name,
+		cloneURL: cloneURL,
+		apiURL:   r.URL,
+	}
+}
+
+// azureDevOpsPush returns a scan for every branch a push updated or created.
+func azureDevOpsPush(e azureDevOpsEvent) []scanRequest {
+	var scans []scanRequest
+	for _, update := range e.Resource.RefUpdates {
+		if !strings.HasPrefix(update.Name, "refs/heads/") || update.NewObjectID == zeroCommit {
+			continue
+		}
+		scan := e.Resource.Repository.scan()
+		scan.base, scan.head = update.OldObjectID, update.NewObjectID
+		if scan.base == zeroCommit {
+			if len(e.Resource.Commits) == 0 {
+				continue
+			}
+			scan.base, scan.depth = "", int64(len(e.Resource.Commits))
+		}
+		scans = append(scans, scan)
+	}
+	return scans
+}
+
+func azureDevOpsPullRequest(e azureDevOpsEvent) []scanRequest {
+	if e.Resource.Status != "active" {
+		return nil
+	}
+	scan := e.Resource.Repository.scan()
+	scan.base = strings.TrimPrefix(e.Resource.TargetRefName, "refs/heads/")
+	scan.head = e.Resource.LastMergeSourceCommit.CommitID
+	scan.number = e.Resource.PullRequestID
+	return []scanRequest{scan}
+}
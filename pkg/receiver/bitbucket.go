@@ -0,0 +1,149 @@
+package receiver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// bitbucket receives Bitbucket Cloud webhooks, which are signed like GitHub's
+// with the HMAC-SHA256 of the payload.
+func (r *Receiver) bitbucket(w http.ResponseWriter, req *http.Request) {
+	if r.conf.BitbucketSecret == "" {
+		http.Error(w, "Bitbucket webhooks aren't enabled", http.StatusNotFound)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 25<<20))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	signature, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature"), "sha256=")
+	got, err := hex.DecodeString(signature)
+	mac := hmac.New(sha256.New, []byte(r.conf.BitbucketSecret))
+	mac.Write(payload)
+	if !ok || err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var scans []scanRequest
+	switch req.Header.Get("X-Event-Key") {
+	case "repo:push":
+		var e bitbucketPushEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		scans = bitbucketPush(e)
+	case "pullrequest:created", "pullrequest:updated":
+		var e bitbucketPullRequestEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		scans = bitbucketPullRequest(e)
+	}
+	r.accept(w, scans)
+}
+
+type bitbucketRepository struct {
+	FullName string `json:"full_name"`
+	Links    struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// cloneURL returns the HTTPS clone URL of the repository, which webhooks
+// only give the web page of.
+func (r bitbucketRepository) cloneURL() string {
+	if r.Links.HTML.Href == "" {
+		return ""
+	}
+	return strings.TrimSuffix(r.Links.HTML.Href, "/") + ".git"
+}
+
+type bitbucketRef struct {
+	Type   string `json:"type"`
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+type bitbucketPushEvent struct {
+	Repository bitbucketRepository `json:"repository"`
+	Push       struct {
+		Changes []struct {
+			Old       *bitbucketRef     `json:"old"`
+			New       *bitbucketRef     `json:"new"`
+			Commits   []json.RawMessage `json:"commits"`
+			Truncated bool              `json:"truncated"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+// bitbucketPush returns a scan for every branch a push updated or created.
+func bitbucketPush(e bitbucketPushEvent) []scanRequest {
+	var scans []scanRequest
+	for _, change := range e.Push.Changes {
+		// Deleted branches and tags have nothing new to scan.
+		if change.New == nil || change.New.Type != "branch" {
+			continue
+		}
+		scan := scanRequest{
+			host:     hostBitbucket,
+			repo:     e.Repository.FullName,
+			cloneURL: e.Repository.cloneURL(),
+			head:     change.New.Target.Hash,
+		}
+		// A new branch is scanned as far back as the commits it introduced,
+		// or entirely if there were more of them than the webhook lists.
+		switch {
+		case change.Old != nil:
+			scan.base = change.Old.Target.Hash
+		case !change.Truncated:
+			scan.depth = int64(len(change.Commits))
+		}
+		scans = append(scans, scan)
+	}
+	return scans
+}
+
+type bitbucketPullRequestEvent struct {
+	Repository  bitbucketRepository `json:"repository"`
+	PullRequest struct {
+		ID     int `json:"id"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+			Repository bitbucketRepository `json:"repository"`
+		} `json:"source"`
+		Destination struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+		} `json:"destination"`
+	} `json:"pullrequest"`
+}
+
+func bitbucketPullRequest(e bitbucketPullRequestEvent) []scanRequest {
+	pr := e.PullRequest
+	// Webhooks only give abbreviated commit hashes, so the branches are
+	// scanned instead.
+	return []scanRequest{{
+		host:     hostBitbucket,
+		repo:     e.Repository.FullName,
+		cloneURL: pr.Source.Repository.cloneURL(),
+		base:     pr.Destination.Branch.Name,
+		head:     pr.Source.Branch.Name,
+		baseURL:  e.Repository.cloneURL(),
+		baseRef:  pr.Destination.Branch.Name,
+		number:   pr.ID,
+	}}
+}
@@ -0,0 +1,454 @@
+// Package receiver serves GitHub, GitLab, Bitbucket Cloud, and Azure DevOps
+// webhooks, scanning the commits of every push and pull or merge request as
+// soon as they are delivered, and reporting the findings back on the code
+// host: as check runs and reviews on GitHub, as commit statuses and merge
+// request discussions on GitLab, as build statuses and pull request comments
+// on Bitbucket, and as commit statuses on Azure DevOps.
+package receiver
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+)
+
+// zeroCommit is the commit pushes report before creating or after deleting a
+// branch.
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// Config configures the code hosts webhooks are received from. Webhooks of a
+// host are only accepted if its secret is set. Its token is used to clone
+// repositories and report findings; without it, repositories are cloned
+// anonymously and findings only go to the configured outputs.
+type Config struct {
+	GitHubSecret   string
+	GitHubToken    string
+	GitHubEndpoint string
+
+	GitLabSecret   string
+	GitLabToken    string
+	GitLabEndpoint string
+
+	BitbucketSecret   string
+	BitbucketToken    string
+	BitbucketEndpoint string
+
+	// AzureDevOpsSecret is the basic authentication password of service
+	// hooks, and AzureDevOpsToken a personal access token.
+	AzureDevOpsSecret string
+	AzureDevOpsToken  string
+
+	// MaxScans is the number of scans run at once. Further scans wait.
+	MaxScans int
+}
+
+// Receiver scans the changes delivered by webhooks.
+type Receiver struct {
+	ctx  context.Context
+	cfg  engine.Config
+	conf Config
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	// launch starts a scan. It is replaced in tests.
+	launch func(scanRequest)
+}
+
+// scanRequest is the range of commits of a webhook to scan.
+type scanRequest struct {
+	host string
+	// repo is the full path of the repository findings are reported on.
+	repo     string
+	cloneURL string
+	base     string
+	head     string
+	// baseURL is the clone URL of the repository the pull or merge request
+	// targets. If it isn't cloneURL, as for requests from forks, its branch
+	// baseRef is fetched into the clone, as base isn't in it.
+	baseURL string
+	baseRef string
+	// depth limits the scan to this many commits when there's no base.
+	depth int64
+	// number is the number of the pull or merge request, or 0 for a push.
+	number int
+	// apiURL is the API URL of the repository, for hosts whose webhooks
+	// identify repositories by it.
+	apiURL string
+}
+
+const (
+	hostGitHub      = "github"
+	hostGitLab      = "gitlab"
+	hostBitbucket   = "bitbucket"
+	hostAzureDevOps = "azure-devops"
+)
+
+// New creates a Receiver that scans with the engine configuration cfg,
+// sending findings to cfg.Dispatcher as well as the code host. Scans are
+// stopped when ctx is cancelled.
+func New(ctx context.Context, cfg engine.Config, conf Config) *Receiver {
+	if conf.MaxScans < 1 {
+		conf.MaxScans = 1
+	}
+	r := &Receiver{ctx: ctx, cfg: cfg, conf: conf, sem: make(chan struct{}, conf.MaxScans)}
+	r.launch = r.start
+	return r
+}
+
+// Handler returns the HTTP handler receiving webhooks:
+//
+//	POST /github        push and pull_request events
+//	POST /gitlab        Push Hook and Merge Request Hook events
+//	POST /bitbucket     repo:push, pullrequest:created, and pullrequest:updated events
+//	POST /azure-devops  git.push, git.pullrequest.created, and git.pullrequest.updated events
+//
+// Webhooks are answered as soon as they're validated, before their changes
+// are scanned. Other events are acknowledged and ignored.
+func (r *Receiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /github", r.github)
+	mux.HandleFunc("POST /gitlab", r.gitlab)
+	mux.HandleFunc("POST /bitbucket", r.bitbucket)
+	mux.HandleFunc("POST /azure-devops", r.azureDevOps)
+	return mux
+}
+
+func (r *Receiver) github(w http.ResponseWriter, req *http.Request) {
+	if r.conf.GitHubSecret == "" {
+		http.Error(w, "GitHub webhooks aren't enabled", http.StatusNotFound)
+		return
+	}
+	payload, err := github.ValidatePayload(req, []byte(r.conf.GitHubSecret))
+	if err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	event, err := github.ParseWebHook(github.WebHookType(req), payload)
+	if err != nil {
+		// Events of types go-github doesn't know aren't scanned either.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var scans []scanRequest
+	switch e := event.(type) {
+	case *github.PushEvent:
+		scans = githubPush(e)
+	case *github.PullRequestEvent:
+		scans = githubPullRequest(e)
+	}
+	r.accept(w, scans)
+}
+
+func githubPush(e *github.PushEvent) []scanRequest {
+	if e.GetDeleted() || e.GetAfter() == zeroCommit {
+		return nil
+	}
+	scan := scanRequest{
+		host:     hostGitHub,
+		repo:     e.GetRepo().GetFullName(),
+		cloneURL: e.GetRepo().GetCloneURL(),
+		base:     e.GetBefore(),
+		head:     e.GetAfter(),
+	}
+	if scan.base == zeroCommit {
+		// A new branch is scanned as far back as the commits it introduced.
+		if len(e.Commits) == 0 {
+			return nil
+		}
+		scan.base, scan.depth = "", int64(len(e.Commits))
+	}
+	return []scanRequest{scan}
+}
+
+func githubPullRequest(e *github.PullRequestEvent) []scanRequest {
+	switch e.GetAction() {
+	case "opened", "reopened", "synchronize":
+	default:
+		return nil
+	}
+	pr := e.GetPullRequest()
+	return []scanRequest{{
+		host:     hostGitHub,
+		repo:     e.GetRepo().GetFullName(),
+		cloneURL: pr.GetHead().GetRepo().GetCloneURL(),
+		base:     pr.GetBase().GetSHA(),
+		head:     pr.GetHead().GetSHA(),
+		baseURL:  e.GetRepo().GetCloneURL(),
+		baseRef:  pr.GetBase().GetRef(),
+		number:   e.GetNumber(),
+	}}
+}
+
+func (r *Receiver) gitlab(w http.ResponseWriter, req *http.Request) {
+	if r.conf.GitLabSecret == "" {
+		http.Error(w, "GitLab webhooks aren't enabled", http.StatusNotFound)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Header.Get("X-Gitlab-Token")), []byte(r.conf.GitLabSecret)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, 25<<20))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	event, err := gitlab.ParseWebhook(gitlab.HookEventType(req), payload)
+	if err != nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var scans []scanRequest
+	switch e := event.(type) {
+	case *gitlab.PushEvent:
+		scans = gitlabPush(e)
+	case *gitlab.MergeEvent:
+		scans = gitlabMerge(e)
+	}
+	r.accept(w, scans)
+}
+
+func gitlabPush(e *gitlab.PushEvent) []scanRequest {
+	if e.After == zeroCommit {
+		return nil
+	}
+	scan := scanRequest{
+		host:     hostGitLab,
+		repo:     e.Project.PathWithNamespace,
+		cloneURL: e.Project.GitHTTPURL,
+		base:     e.Before,
+		head:     e.After,
+	}
+	if scan.base == zeroCommit {
+		if e.TotalCommitsCount == 0 {
+			return nil
+		}
+		scan.base, scan.depth = "", int64(e.TotalCommitsCount)
+	}
+	return []scanRequest{scan}
+}
+
+func gitlabMerge(e *gitlab.MergeEvent) []scanRequest {
+	attrs := e.ObjectAttributes
+	switch attrs.Action {
+	case "open", "reopen":
+	case "update":
+		// Updates that didn't push commits, like edits of the title, have
+		// no old revision.
+		if attrs.OldRev == "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if attrs.Source == nil {
+		return nil
+	}
+	// The target branch is resolved to the commit the source branch forked
+	// from.
+	return []scanRequest{{
+		host:     hostGitLab,
+		repo:     e.Project.PathWithNamespace,
+		cloneURL: attrs.Source.GitHTTPURL,
+		base:     attrs.TargetBranch,
+		head:     attrs.LastCommit.ID,
+		baseURL:  e.Project.GitHTTPURL,
+		baseRef:  attrs.TargetBranch,
+		number:   attrs.IID,
+	}}
+}
+
+// accept answers a webhook, starting the scans of its changes if it has any.
+func (r *Receiver) accept(w http.ResponseWriter, scans []scanRequest) {
+	status := http.StatusNoContent
+	for _, scan := range scans {
+		if scan.cloneURL == "" || scan.head == "" {
+			continue
+		}
+		r.launch(scan)
+		status = http.StatusAccepted
+	}
+	w.WriteHeader(status)
+}
+
+func (r *Receiver) start(scan scanRequest) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx := context.WithValues(r.ctx, "repository", scan.repo, "head", scan.head)
+		select {
+		case r.sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		defer func() { <-r.sem }()
+		if err := r.scan(ctx, scan); err != nil {
+			ctx.Logger().Error(err, "webhook scan failed")
+			return
+		}
+		ctx.Logger().V(1).Info("webhook scan finished")
+	}()
+}
+
+// scan scans the commits of scan and reports its findings.
+func (r *Receiver) scan(ctx context.Context, scan scanRequest) error {
+	connection := &sourcespb.Git{
+		Repositories: []string{scan.cloneURL},
+		Base:         scan.base,
+		Head:         scan.head,
+		MaxDepth:     scan.depth,
+		Credential:   &sourcespb.Git_Unauthenticated{},
+	}
+	user, token := r.credentials(scan.host)
+	if token != "" {
+		connection.Credential = &sourcespb.Git_BasicAuth{BasicAuth: &credentialspb.BasicAuth{Username: user, Password: token}}
+	}
+	if scan.baseURL != "" && scan.baseURL != scan.cloneURL {
+		dir, base, err := r.cloneFork(ctx, scan)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		connection.Repositories, connection.Directories, connection.Base = nil, []string{dir}, base
+	}
+
+	cfg := r.cfg
+	cfg.SourceManager = sources.NewManager(
+		sources.WithConcurrentSources(1),
+		sources.WithConcurrentUnits(cfg.Concurrency),
+		sources.WithSourceUnits(),
+	)
+	var dispatchers engine.MultiDispatcher
+	if cfg.Dispatcher != nil {
+		dispatchers = append(dispatchers, cfg.Dispatcher)
+	}
+	reporter, err := r.reporter(scan)
+	if err != nil {
+		return err
+	}
+	if reporter != nil {
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(reporter))
+	}
+	cfg.Dispatcher = dispatchers
+	eng, err := engine.NewEngine(ctx, &cfg)
+	if err != nil {
+		return err
+	}
+	eng.Start(ctx)
+
+	_, err = eng.ScanConnection(ctx, "trufflehog - "+scan.host+" webhook", connection)
+	// The engine must be finished even if the source couldn't be started.
+	// Finishing reports the findings.
+	if finishErr := eng.Finish(ctx); err == nil {
+		err = finishErr
+	}
+	return err
+}
+
+// cloneFork clones the repository of scan, and fetches its base branch from
+// the repository the request targets. It returns the path of the clone and
+// the commit base resolves to, since a branch name would resolve to the
+// fork's own branch.
+func (r *Receiver) cloneFork(ctx context.Context, scan scanRequest) (string, string, error) {
+	user, token := r.credentials(scan.host)
+	var (
+		dir string
+		err error
+	)
+	if token != "" {
+		dir, _, err = git.CloneRepoUsingToken(ctx, token, scan.cloneURL, user)
+	} else {
+		dir, _, err = git.CloneRepoUsingUnauthenticated(ctx, scan.cloneURL)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	baseURL := scan.baseURL
+	if u, err := url.Parse(baseURL); err == nil && token != "" {
+		u.User = url.UserPassword(user, token)
+		baseURL = u.String()
+	}
+	// The error of git isn't returned, as it may contain the token.
+	fetch := exec.CommandContext(ctx, "git", "-C", dir, "fetch", "--quiet", "--no-tags", baseURL, "refs/heads/"+scan.baseRef)
+	if err := fetch.Run(); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", "", fmt.Errorf("could not fetch base branch %s of %s: %w", scan.baseRef, scan.repo, err)
+	}
+	if plumbing.IsHash(scan.base) {
+		return dir, scan.base, nil
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "FETCH_HEAD").Output()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", "", fmt.Errorf("could not resolve base branch %s of %s: %w", scan.baseRef, scan.repo, err)
+	}
+	return dir, strings.TrimSpace(string(out)), nil
+}
+
+// credentials returns the user and token repositories of host are cloned
+// with.
+func (r *Receiver) credentials(host string) (user, token string) {
+	switch host {
+	case hostGitHub:
+		return "x-access-token", r.conf.GitHubToken
+	case hostGitLab:
+		return "oauth2", r.conf.GitLabToken
+	case hostBitbucket:
+		if user, password, ok := strings.Cut(r.conf.BitbucketToken, ":"); ok {
+			return user, password
+		}
+		return "x-token-auth", r.conf.BitbucketToken
+	default:
+		// Any user goes with a personal access token.
+		return "pat", r.conf.AzureDevOpsToken
+	}
+}
+
+// reporter returns the printer reporting the findings of scan on its code
+// host, or nil if there's no token to do so with.
+func (r *Receiver) reporter(scan scanRequest) (engine.Printer, error) {
+	switch {
+	case scan.host == hostGitHub && r.conf.GitHubToken != "":
+		if scan.number > 0 {
+			return output.NewGitHubPRPrinter(output.PullRequestRef{Repo: scan.repo, Number: scan.number}, r.conf.GitHubToken, r.conf.GitHubEndpoint)
+		}
+		return output.NewGitHubCheckRunPrinter(output.CommitRef{Repo: scan.repo, SHA: scan.head}, r.conf.GitHubToken, r.conf.GitHubEndpoint)
+	case scan.host == hostGitLab && r.conf.GitLabToken != "":
+		if scan.number > 0 {
+			return output.NewGitLabMRPrinter(output.PullRequestRef{Repo: scan.repo, Number: scan.number}, r.conf.GitLabToken, r.conf.GitLabEndpoint)
+		}
+		return output.NewGitLabCommitStatusPrinter(output.CommitRef{Repo: scan.repo, SHA: scan.head}, r.conf.GitLabToken, r.conf.GitLabEndpoint)
+	case scan.host == hostBitbucket && r.conf.BitbucketToken != "":
+		if scan.number > 0 {
+			return output.NewBitbucketPRPrinter(output.PullRequestRef{Repo: scan.repo, Number: scan.number}, r.conf.BitbucketToken, r.conf.BitbucketEndpoint), nil
+		}
+		return output.NewBitbucketCommitStatusPrinter(output.CommitRef{Repo: scan.repo, SHA: scan.head}, r.conf.BitbucketToken, r.conf.BitbucketEndpoint), nil
+	case scan.host == hostAzureDevOps && r.conf.AzureDevOpsToken != "" && scan.apiURL != "":
+		// Pull requests show the status of their last commit.
+		return output.NewAzureDevOpsCommitStatusPrinter(output.CommitRef{Repo: scan.repo, SHA: scan.head}, r.conf.AzureDevOpsToken, scan.apiURL), nil
+	default:
+		return nil, nil
+	}
+}
+
+// Wait blocks until every scan has finished.
+func (r *Receiver) Wait() { r.wg.Wait() }
@@ -0,0 +1,347 @@
+package receiver
+
+import (
+	aCtx "context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const fakeDetectorKeyword = "fakedetectorkeyword"
+
+type fakeDetector struct{}
+
+func (fakeDetector) FromData(_ aCtx.Context, _ bool, _ []byte) ([]detectors.Result, error) {
+	return []detectors.Result{{DetectorType: detectorspb.DetectorType(-1), Raw: []byte("fake secret")}}, nil
+}
+func (fakeDetector) Keywords() []string             { return []string{fakeDetectorKeyword} }
+func (fakeDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType(-1) }
+func (fakeDetector) Description() string            { return "" }
+
+// recordingPrinter records the file of every result it prints.
+type recordingPrinter struct {
+	mu    sync.Mutex
+	files []string
+}
+
+func (p *recordingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files = append(p.files, r.SourceMetadata.GetGit().GetFile())
+	return nil
+}
+
+func newReceiver(t *testing.T, printer engine.Printer) *Receiver {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := engine.Config{
+		Concurrency: 1,
+		Decoders:    decoders.DefaultDecoders(),
+		Detectors:   []detectors.Detector{fakeDetector{}},
+		Dispatcher:  engine.NewPrinterDispatcher(printer),
+	}
+	r := New(ctx, cfg, Config{
+		GitHubSecret:      "hook secret",
+		GitLabSecret:      "gitlab secret",
+		BitbucketSecret:   "hook secret",
+		AzureDevOpsSecret: "azure secret",
+	})
+	t.Cleanup(func() {
+		cancel()
+		r.Wait()
+	})
+	return r
+}
+
+func deliverGitHub(t *testing.T, h http.Handler, event, secret string, payload any) int {
+	return deliverSigned(t, h, "/github", "X-GitHub-Event", "X-Hub-Signature-256", event, secret, payload)
+}
+
+func deliverBitbucket(t *testing.T, h http.Handler, event, secret string, payload any) int {
+	return deliverSigned(t, h, "/bitbucket", "X-Event-Key", "X-Hub-Signature", event, secret, payload)
+}
+
+// deliverSigned delivers a webhook signed with the HMAC-SHA256 of its payload.
+func deliverSigned(t *testing.T, h http.Handler, path, eventHeader, signatureHeader, event, secret string, payload any) int {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(eventHeader, event)
+	req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func deliverAzureDevOps(t *testing.T, h http.Handler, password string, payload any) int {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/azure-devops", strings.NewReader(string(body)))
+	req.SetBasicAuth("trufflehog", password)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func deliverGitLab(t *testing.T, h http.Handler, event, token string, payload any) int {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/gitlab", strings.NewReader(string(body)))
+	req.Header.Set("X-Gitlab-Event", event)
+	req.Header.Set("X-Gitlab-Token", token)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+// gitRepo creates a repository with a commit of a clean file followed by one
+// of a file with a secret, and returns its path and both commits.
+func gitRepo(t *testing.T) (dir, first, second string) {
+	t.Helper()
+	dir = t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.txt"), []byte(fakeDetectorKeyword), 0o644))
+	git("add", "old.txt")
+	git("commit", "-q", "-m", "old")
+	first = git("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte(fakeDetectorKeyword), 0o644))
+	git("add", "new.txt")
+	git("commit", "-q", "-m", "new")
+	second = git("rev-parse", "HEAD")
+	return dir, first, second
+}
+
+func TestReceiver_GitHubPush(t *testing.T) {
+	dir, first, second := gitRepo(t)
+	printer := new(recordingPrinter)
+	r := newReceiver(t, printer)
+	h := r.Handler()
+
+	push := map[string]any{
+		"before": first,
+		"after":  second,
+		"repository": map[string]any{
+			"full_name": "org/repo",
+			"clone_url": "file://" + dir,
+		},
+	}
+	assert.Equal(t, http.StatusUnauthorized, deliverGitHub(t, h, "push", "wrong secret", push))
+	assert.Equal(t, http.StatusAccepted, deliverGitHub(t, h, "push", "hook secret", push))
+	r.Wait()
+	// Only the pushed commit is scanned.
+	assert.Equal(t, []string{"new.txt"}, printer.files)
+
+	assert.Equal(t, http.StatusNoContent, deliverGitHub(t, h, "ping", "hook secret", map[string]any{"zen": "hi"}))
+	push["deleted"] = true
+	assert.Equal(t, http.StatusNoContent, deliverGitHub(t, h, "push", "hook secret", push))
+}
+
+func TestReceiver_GitLabMergeRequestFromFork(t *testing.T) {
+	target, first, _ := gitRepo(t)
+	// The fork only has the branch of the merge request, which forked from
+	// the first commit of the target.
+	fork := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = fork
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("fetch", "-q", target, first)
+	git("checkout", "-q", "-b", "feature", first)
+	require.NoError(t, os.WriteFile(filepath.Join(fork, "fork.txt"), []byte(fakeDetectorKeyword), 0o644))
+	git("add", "fork.txt")
+	git("commit", "-q", "-m", "fork")
+	head := git("rev-parse", "HEAD")
+
+	printer := new(recordingPrinter)
+	r := newReceiver(t, printer)
+	mr := map[string]any{
+		"object_kind": "merge_request",
+		"project":     map[string]any{"path_with_namespace": "group/project", "git_http_url": "file://" + target},
+		"object_attributes": map[string]any{
+			"action":        "open",
+			"iid":           3,
+			"target_branch": strings.TrimPrefix(gitHead(t, target), "refs/heads/"),
+			"last_commit":   map[string]any{"id": head},
+			"source":        map[string]any{"git_http_url": "file://" + fork},
+		},
+	}
+	assert.Equal(t, http.StatusAccepted, deliverGitLab(t, r.Handler(), "Merge Request Hook", "gitlab secret", mr))
+	r.Wait()
+	// Only the commit of the fork is scanned, and not the commits the
+	// target branch got since.
+	assert.Equal(t, []string{"fork.txt"}, printer.files)
+}
+
+// gitHead returns the branch checked out in the repository in dir.
+func gitHead(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "symbolic-ref", "HEAD").Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func TestReceiver_Parse(t *testing.T) {
+	var launched []scanRequest
+	r := newReceiver(t, new(recordingPrinter))
+	r.launch = func(scan scanRequest) { launched = append(launched, scan) }
+	h := r.Handler()
+
+	pr := map[string]any{
+		"action": "synchronize",
+		"number": 7,
+		"pull_request": map[string]any{
+			"base": map[string]any{"sha": "base", "ref": "main"},
+			"head": map[string]any{"sha": "head", "repo": map[string]any{"clone_url": "https://github.com/fork/repo.git"}},
+		},
+		"repository": map[string]any{"full_name": "org/repo", "clone_url": "https://github.com/org/repo.git"},
+	}
+	assert.Equal(t, http.StatusAccepted, deliverGitHub(t, h, "pull_request", "hook secret", pr))
+	pr["action"] = "closed"
+	assert.Equal(t, http.StatusNoContent, deliverGitHub(t, h, "pull_request", "hook secret", pr))
+
+	branch := map[string]any{
+		"before":              zeroCommit,
+		"after":               "head",
+		"total_commits_count": 3,
+		"project":             map[string]any{"path_with_namespace": "group/project", "git_http_url": "https://gitlab.com/group/project.git"},
+	}
+	assert.Equal(t, http.StatusUnauthorized, deliverGitLab(t, h, "Push Hook", "wrong", branch))
+	assert.Equal(t, http.StatusAccepted, deliverGitLab(t, h, "Push Hook", "gitlab secret", branch))
+
+	mr := map[string]any{
+		"object_kind": "merge_request",
+		"project":     map[string]any{"path_with_namespace": "group/project", "git_http_url": "https://gitlab.com/group/project.git"},
+		"object_attributes": map[string]any{
+			"action":        "update",
+			"iid":           3,
+			"target_branch": "main",
+			"oldrev":        "old",
+			"last_commit":   map[string]any{"id": "head"},
+			"source":        map[string]any{"git_http_url": "https://gitlab.com/fork/project.git"},
+		},
+	}
+	assert.Equal(t, http.StatusAccepted, deliverGitLab(t, h, "Merge Request Hook", "gitlab secret", mr))
+	mr["object_attributes"].(map[string]any)["oldrev"] = ""
+	assert.Equal(t, http.StatusNoContent, deliverGitLab(t, h, "Merge Request Hook", "gitlab secret", mr))
+
+	assert.Equal(t, []scanRequest{
+		{host: hostGitHub, repo: "org/repo", cloneURL: "https://github.com/fork/repo.git", base: "base", head: "head", baseURL: "https://github.com/org/repo.git", baseRef: "main", number: 7},
+		{host: hostGitLab, repo: "group/project", cloneURL: "https://gitlab.com/group/project.git", head: "head", depth: 3},
+		{host: hostGitLab, repo: "group/project", cloneURL: "https://gitlab.com/fork/project.git", base: "main", head: "head", baseURL: "https://gitlab.com/group/project.git", baseRef: "main", number: 3},
+	}, launched)
+}
+
+func TestReceiver_ParseBitbucketAndAzureDevOps(t *testing.T) {
+	var launched []scanRequest
+	r := newReceiver(t, new(recordingPrinter))
+	r.launch = func(scan scanRequest) { launched = append(launched, scan) }
+	h := r.Handler()
+
+	repository := map[string]any{
+		"full_name": "ws/repo",
+		"links":     map[string]any{"html": map[string]any{"href": "https://bitbucket.org/ws/repo"}},
+	}
+	push := map[string]any{
+		"repository": repository,
+		"push": map[string]any{"changes": []any{
+			map[string]any{
+				"old": map[string]any{"type": "branch", "target": map[string]any{"hash": "old"}},
+				"new": map[string]any{"type": "branch", "target": map[string]any{"hash": "new"}},
+			},
+			map[string]any{
+				"new":     map[string]any{"type": "branch", "target": map[string]any{"hash": "branch"}},
+				"commits": []any{map[string]any{}, map[string]any{}},
+			},
+			map[string]any{"new": map[string]any{"type": "tag", "target": map[string]any{"hash": "tag"}}},
+		}},
+	}
+	assert.Equal(t, http.StatusUnauthorized, deliverBitbucket(t, h, "repo:push", "wrong secret", push))
+	assert.Equal(t, http.StatusAccepted, deliverBitbucket(t, h, "repo:push", "hook secret", push))
+	pr := map[string]any{
+		"repository": repository,
+		"pullrequest": map[string]any{
+			"id":          5,
+			"source":      map[string]any{"branch": map[string]any{"name": "feature"}, "repository": repository},
+			"destination": map[string]any{"branch": map[string]any{"name": "main"}},
+		},
+	}
+	assert.Equal(t, http.StatusAccepted, deliverBitbucket(t, h, "pullrequest:updated", "hook secret", pr))
+	assert.Equal(t, http.StatusNoContent, deliverBitbucket(t, h, "repo:fork", "hook secret", pr))
+
+	azureRepo := map[string]any{
+		"name":      "repo",
+		"url":       "https://dev.azure.com/org/_apis/git/repositories/id",
+		"remoteUrl": "https://org@dev.azure.com/org/project/_git/repo",
+		"project":   map[string]any{"name": "project"},
+	}
+	azurePush := map[string]any{
+		"eventType": "git.push",
+		"resource": map[string]any{
+			"repository": azureRepo,
+			"refUpdates": []any{
+				map[string]any{"name": "refs/heads/main", "oldObjectId": "old", "newObjectId": "new"},
+				map[string]any{"name": "refs/heads/gone", "oldObjectId": "old", "newObjectId": zeroCommit},
+			},
+		},
+	}
+	assert.Equal(t, http.StatusUnauthorized, deliverAzureDevOps(t, h, "wrong", azurePush))
+	assert.Equal(t, http.StatusAccepted, deliverAzureDevOps(t, h, "azure secret", azurePush))
+	azurePR := map[string]any{
+		"eventType": "git.pullrequest.created",
+		"resource": map[string]any{
+			"repository":            azureRepo,
+			"pullRequestId":         9,
+			"status":                "active",
+			"targetRefName":         "refs/heads/main",
+			"lastMergeSourceCommit": map[string]any{"commitId": "head"},
+		},
+	}
+	assert.Equal(t, http.StatusAccepted, deliverAzureDevOps(t, h, "azure secret", azurePR))
+	azurePR["resource"].(map[string]any)["status"] = "completed"
+	assert.Equal(t, http.StatusNoContent, deliverAzureDevOps(t, h, "azure secret", azurePR))
+
+	azureClone := "https://dev.azure.com/org/project/_git/repo"
+	azureAPI := "https://dev.azure.com/org/_apis/git/repositories/id"
+	assert.Equal(t, []scanRequest{
+		{host: hostBitbucket, repo: "ws/repo", cloneURL: "https://bitbucket.org/ws/repo.git", base: "old", head: "new"},
+		{host: hostBitbucket, repo: "ws/repo", cloneURL: "https://bitbucket.org/ws/repo.git", head: "branch", depth: 2},
+		{host: hostBitbucket, repo: "ws/repo", cloneURL: "https://bitbucket.org/ws/repo.git", base: "main", head: "feature", baseURL: "https://bitbucket.org/ws/repo.git", baseRef: "main", number: 5},
+		{host: hostAzureDevOps, repo: "project/repo", cloneURL: azureClone, base: "old", head: "new", apiURL: azureAPI},
+		{host: hostAzureDevOps, repo: "project/repo", cloneURL: azureClone, base: "main", head: "head", number: 9, apiURL: azureAPI},
+	}, launched)
+}
@@ -0,0 +1,146 @@
+package remediation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+)
+
+// awsPlaybook deactivates leaked access keys of IAM users with the default
+// AWS credentials, which need iam:UpdateAccessKey in the account of the key.
+// The key is deactivated rather than deleted, so it can be reactivated if it
+// turns out to still be needed.
+type awsPlaybook struct {
+	client iamiface.IAMAPI
+}
+
+func newAWSPlaybook() (Playbook, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, err
+	}
+	return &awsPlaybook{client: iam.New(sess)}, nil
+}
+
+// user returns the access key ID of r and the name of the IAM user it belongs
+// to, from the ARN of the caller identity the detector verified it with.
+func (p *awsPlaybook) user(r *detectors.ResultWithMetadata) (keyID, userName string, err error) {
+	parsed, err := arn.Parse(r.ExtraData["arn"])
+	if err != nil {
+		return "", "", fmt.Errorf("the access key has no caller identity: %w", err)
+	}
+	path, ok := strings.CutPrefix(parsed.Resource, "user/")
+	if parsed.Service != "iam" || !ok {
+		return "", "", fmt.Errorf("%s isn't an IAM user, only keys of IAM users can be deactivated", parsed)
+	}
+	return string(r.Raw), path[strings.LastIndex(path, "/")+1:], nil
+}
+
+func (p *awsPlaybook) Describe(r *detectors.ResultWithMetadata) (string, error) {
+	keyID, userName, err := p.user(r)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deactivate access key %s of IAM user %s", keyID, userName), nil
+}
+
+func (p *awsPlaybook) Remediate(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	keyID, userName, err := p.user(r)
+	if err != nil {
+		return err
+	}
+	_, err = p.client.UpdateAccessKeyWithContext(ctx, &iam.UpdateAccessKeyInput{
+		AccessKeyId: aws.String(keyID),
+		UserName:    aws.String(userName),
+		Status:      aws.String(iam.StatusTypeInactive),
+	})
+	return err
+}
+
+// gitHubPlaybook revokes leaked GitHub tokens through the credential
+// revocation API, which doesn't need any credentials of its own. The owners
+// of the tokens are notified by GitHub.
+type gitHubPlaybook struct {
+	client   *http.Client
+	endpoint string
+}
+
+func newGitHubPlaybook() (Playbook, error) {
+	return &gitHubPlaybook{client: common.RetryableHTTPClient(), endpoint: "https://api.github.com"}, nil
+}
+
+func (p *gitHubPlaybook) Describe(r *detectors.ResultWithMetadata) (string, error) {
+	return "revoke GitHub token " + output.MaskSecret(string(r.Raw)), nil
+}
+
+func (p *gitHubPlaybook) Remediate(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	body, err := json.Marshal(map[string][]string{"credentials": {string(r.Raw)}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/credentials/revoke", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// CommandPlaybook runs an executable for every secret to remediate, with the
+// finding as JSON, the way --json prints it, on its standard input. The
+// secret is considered remediated if it exits with status 0.
+type CommandPlaybook struct {
+	path string
+}
+
+// NewCommandPlaybook creates a CommandPlaybook that runs the executable at
+// path.
+func NewCommandPlaybook(path string) *CommandPlaybook {
+	return &CommandPlaybook{path: path}
+}
+
+func (p *CommandPlaybook) Describe(*detectors.ResultWithMetadata) (string, error) {
+	return "run " + p.path, nil
+}
+
+func (p *CommandPlaybook) Remediate(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	finding, err := output.MarshalResult(r)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(finding)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", p.path, err, msg)
+		}
+		return fmt.Errorf("%s: %w", p.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package remediation runs playbooks that remediate verified secrets as soon
+// as they are found, such as deactivating a leaked AWS access key.
+package remediation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Playbook remediates the secrets found by a detector.
+type Playbook interface {
+	// Describe returns what Remediate does for the secret of r. Dry runs
+	// log it instead of remediating the secret.
+	Describe(r *detectors.ResultWithMetadata) (string, error)
+	Remediate(ctx context.Context, r *detectors.ResultWithMetadata) error
+}
+
+// builtin create the playbooks of the detectors that have one.
+var builtin = map[detectorspb.DetectorType]func() (Playbook, error){
+	detectorspb.DetectorType_AWS:    newAWSPlaybook,
+	detectorspb.DetectorType_Github: newGitHubPlaybook,
+}
+
+// Playbooks returns the playbooks of the detectors named by enabled, which
+// are opted in to remediation. commands give the executables run for
+// detectors as DETECTOR=PATH, which take precedence over the built-in
+// playbooks. Every enabled detector needs one or the other.
+func Playbooks(enabled, commands []string) (map[detectorspb.DetectorType]Playbook, error) {
+	paths := make(map[detectorspb.DetectorType]string)
+	for _, command := range commands {
+		name, path, ok := strings.Cut(command, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid remediation command %q: expected DETECTOR=PATH", command)
+		}
+		id, err := config.ParseDetector(name)
+		if err != nil {
+			return nil, err
+		}
+		paths[id.ID] = path
+	}
+
+	playbooks := make(map[detectorspb.DetectorType]Playbook)
+	for _, name := range enabled {
+		id, err := config.ParseDetector(name)
+		if err != nil {
+			return nil, err
+		}
+		if path, ok := paths[id.ID]; ok {
+			playbooks[id.ID] = NewCommandPlaybook(path)
+			continue
+		}
+		newPlaybook, ok := builtin[id.ID]
+		if !ok {
+			return nil, fmt.Errorf("%s has no built-in remediation playbook, give one with a remediation command", id.ID)
+		}
+		playbook, err := newPlaybook()
+		if err != nil {
+			return nil, fmt.Errorf("could not set up the remediation playbook of %s: %w", id.ID, err)
+		}
+		playbooks[id.ID] = playbook
+	}
+	for id := range paths {
+		if _, ok := playbooks[id]; !ok {
+			return nil, fmt.Errorf("%s has a remediation command but isn't enabled for remediation", id)
+		}
+	}
+	return playbooks, nil
+}
+
+// Printer is a printer that runs the playbook of the detector of every
+// verified result. Each secret is remediated once, however many times it is
+// found, and unverified results are never remediated since they may not be
+// secrets at all.
+type Printer struct {
+	playbooks map[detectorspb.DetectorType]Playbook
+	dryRun    bool
+
+	mu         sync.Mutex
+	seen       map[string]struct{}
+	remediated int
+	failed     int
+}
+
+// NewPrinter creates a Printer that runs playbooks, or only logs what they
+// would do if dryRun is set.
+func NewPrinter(playbooks map[detectorspb.DetectorType]Playbook, dryRun bool) *Printer {
+	return &Printer{playbooks: playbooks, dryRun: dryRun, seen: make(map[string]struct{})}
+}
+
+func (p *Printer) Print(ctx context.Context, r *detectors.ResultWithMetadata) error {
+	playbook, ok := p.playbooks[r.DetectorType]
+	if !ok || !r.Verified {
+		return nil
+	}
+	secret := string(r.RawV2)
+	if secret == "" {
+		secret = string(r.Raw)
+	}
+	key := r.DetectorType.String() + "\x00" + secret
+	p.mu.Lock()
+	_, seen := p.seen[key]
+	p.seen[key] = struct{}{}
+	p.mu.Unlock()
+	if seen {
+		return nil
+	}
+
+	logger := ctx.Logger().WithValues("detector", r.DetectorType.String())
+	action, err := playbook.Describe(r)
+	if err == nil {
+		if p.dryRun {
+			logger.Info("dry run, not remediating secret", "action", action)
+			return nil
+		}
+		err = playbook.Remediate(ctx, r)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.failed++
+		return fmt.Errorf("could not remediate %s secret: %w", r.DetectorType, err)
+	}
+	p.remediated++
+	logger.Info("remediated secret", "action", action)
+	return nil
+}
+
+// Flush logs how many secrets were remediated.
+func (p *Printer) Flush(ctx context.Context) error {
+	if p.dryRun {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ctx.Logger().Info("finished remediating secrets", "remediated", p.remediated, "failed", p.failed)
+	return nil
+}
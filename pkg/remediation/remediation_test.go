@@ -0,0 +1,149 @@
+package remediation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+type countingPlaybook struct{ described, remediated int }
+
+func (p *countingPlaybook) Describe(*detectors.ResultWithMetadata) (string, error) {
+	p.described++
+	return "count", nil
+}
+
+func (p *countingPlaybook) Remediate(context.Context, *detectors.ResultWithMetadata) error {
+	p.remediated++
+	return nil
+}
+
+func result(detector detectorspb.DetectorType, raw string, verified bool) *detectors.ResultWithMetadata {
+	return &detectors.ResultWithMetadata{Result: detectors.Result{DetectorType: detector, Raw: []byte(raw), Verified: verified}}
+}
+
+func TestPrinter(t *testing.T) {
+	ctx := context.Background()
+	playbook := new(countingPlaybook)
+	p := NewPrinter(map[detectorspb.DetectorType]Playbook{detectorspb.DetectorType_Github: playbook}, false)
+
+	require.NoError(t, p.Print(ctx, result(detectorspb.DetectorType_Github, "ghp_one", true)))
+	require.NoError(t, p.Print(ctx, result(detectorspb.DetectorType_Github, "ghp_one", true)))
+	require.NoError(t, p.Print(ctx, result(detectorspb.DetectorType_Github, "ghp_two", false)))
+	require.NoError(t, p.Print(ctx, result(detectorspb.DetectorType_AWS, "AKIAONE", true)))
+	require.NoError(t, p.Flush(ctx))
+	assert.Equal(t, 1, playbook.remediated)
+
+	dryRun := new(countingPlaybook)
+	p = NewPrinter(map[detectorspb.DetectorType]Playbook{detectorspb.DetectorType_Github: dryRun}, true)
+	require.NoError(t, p.Print(ctx, result(detectorspb.DetectorType_Github, "ghp_one", true)))
+	assert.Equal(t, 1, dryRun.described)
+	assert.Zero(t, dryRun.remediated)
+}
+
+func TestPlaybooks(t *testing.T) {
+	playbooks, err := Playbooks([]string{"github", "slack"}, []string{"slack=/usr/local/bin/revoke-slack"})
+	require.NoError(t, err)
+	assert.IsType(t, &gitHubPlaybook{}, playbooks[detectorspb.DetectorType_Github])
+	assert.Equal(t, NewCommandPlaybook("/usr/local/bin/revoke-slack"), playbooks[detectorspb.DetectorType_Slack])
+
+	for name, args := range map[string][2][]string{
+		"no playbook":      {{"slack"}, nil},
+		"not enabled":      {{"github"}, {"slack=/usr/local/bin/revoke-slack"}},
+		"invalid command":  {{"slack"}, {"slack"}},
+		"unknown detector": {{"nosuchdetector"}, nil},
+	} {
+		_, err := Playbooks(args[0], args[1])
+		assert.Error(t, err, name)
+	}
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	input *iam.UpdateAccessKeyInput
+}
+
+func (f *fakeIAM) UpdateAccessKeyWithContext(_ aws.Context, in *iam.UpdateAccessKeyInput, _ ...request.Option) (*iam.UpdateAccessKeyOutput, error) {
+	f.input = in
+	return &iam.UpdateAccessKeyOutput{}, nil
+}
+
+func TestAWSPlaybook(t *testing.T) {
+	ctx := context.Background()
+	client := new(fakeIAM)
+	p := &awsPlaybook{client: client}
+
+	r := result(detectorspb.DetectorType_AWS, "AKIAEXAMPLE", true)
+	r.ExtraData = map[string]string{"arn": "arn:aws:iam::123456789012:user/ci/deployer"}
+	action, err := p.Describe(r)
+	require.NoError(t, err)
+	assert.Equal(t, "deactivate access key AKIAEXAMPLE of IAM user deployer", action)
+	require.NoError(t, p.Remediate(ctx, r))
+	assert.Equal(t, &iam.UpdateAccessKeyInput{
+		AccessKeyId: aws.String("AKIAEXAMPLE"),
+		UserName:    aws.String("deployer"),
+		Status:      aws.String(iam.StatusTypeInactive),
+	}, client.input)
+
+	r.ExtraData["arn"] = "arn:aws:sts::123456789012:assumed-role/ci/session"
+	assert.Error(t, p.Remediate(ctx, r))
+	r.ExtraData = nil
+	_, err = p.Describe(r)
+	assert.Error(t, err)
+}
+
+func TestGitHubPlaybook(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST /credentials/revoke", r.Method+" "+r.URL.Path)
+		var body struct{ Credentials []string }
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		revoked = append(revoked, body.Credentials...)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := &gitHubPlaybook{client: server.Client(), endpoint: server.URL}
+	r := result(detectorspb.DetectorType_Github, "ghp_1234567890abcdef", true)
+	action, err := p.Describe(r)
+	require.NoError(t, err)
+	assert.NotContains(t, action, "ghp_1234567890abcdef")
+	require.NoError(t, p.Remediate(context.Background(), r))
+	assert.Equal(t, []string{"ghp_1234567890abcdef"}, revoked)
+}
+
+func TestCommandPlaybook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "finding.json")
+	script := filepath.Join(dir, "revoke")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat > "+out+"\n"), 0o755))
+
+	ctx := context.Background()
+	require.NoError(t, NewCommandPlaybook(script).Remediate(ctx, result(detectorspb.DetectorType_Slack, "xoxb-secret", true)))
+	finding, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(finding), `"Raw":"xoxb-secret"`)
+
+	failing := filepath.Join(dir, "fail")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho token already revoked >&2\nexit 1\n"), 0o755))
+	err = NewCommandPlaybook(failing).Remediate(ctx, result(detectorspb.DetectorType_Slack, "xoxb-secret", true))
+	assert.ErrorContains(t, err, "token already revoked")
+}
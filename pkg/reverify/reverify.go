@@ -0,0 +1,289 @@
+// Package reverify runs the verification of the findings of earlier JSON
+// output again, without scanning their sources, to tell which of their
+// secrets are still live and which have been revoked since.
+package reverify
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	regexp "github.com/wasilibs/go-re2"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/envelope"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// Status is what verifying a finding again tells about its secret.
+type Status string
+
+const (
+	// Live secrets are verified again.
+	Live Status = "live"
+	// Revoked secrets were verified, and aren't anymore.
+	Revoked Status = "revoked"
+	// Unverified secrets weren't verified before, and still aren't.
+	Unverified Status = "unverified"
+	// Unknown secrets couldn't be verified because verification failed.
+	Unknown Status = "unknown"
+	// Skipped findings can't be verified again, such as those whose secret
+	// was masked in the output.
+	Skipped Status = "skipped"
+)
+
+// Finding is the part of a finding of JSON output reverification needs.
+type Finding struct {
+	DetectorType detectorspb.DetectorType
+	DetectorName string
+	Verified     bool
+	Unverifiable bool
+	Raw          string
+	RawV2        string
+	Redacted     string
+	Fingerprint  string
+	// SourceMetadata is passed through to outcomes as it is.
+	SourceMetadata json.RawMessage
+	Context        *detectors.LineContext
+}
+
+// Outcome is the result of verifying a finding again.
+type Outcome struct {
+	// Line is the line of the finding in the input.
+	Line           int
+	DetectorName   string
+	Redacted       string
+	Fingerprint    string          `json:",omitempty"`
+	SourceMetadata json.RawMessage `json:",omitempty"`
+	// WasVerified is whether the finding was verified when it was found.
+	WasVerified bool
+	Status      Status
+	// Reason tells why the finding was skipped or its verification failed.
+	Reason string `json:",omitempty"`
+}
+
+// Summary counts the outcomes of each status.
+type Summary map[Status]int
+
+// maskedPat matches the secrets output.MaskSecret masked.
+var maskedPat = regexp.MustCompile(`\(sha256:[0-9a-f]{12}\)$`)
+
+// Verifier verifies findings again with the detectors of their type.
+type Verifier struct {
+	detectors map[detectorspb.DetectorType][]detectors.Detector
+	opener    *envelope.Opener
+}
+
+// New creates a Verifier that verifies findings with dets, and decrypts the
+// secrets sealed with --secrets-encryption-key with opener, which may be
+// nil.
+func New(dets []detectors.Detector, opener *envelope.Opener) *Verifier {
+	v := &Verifier{detectors: make(map[detectorspb.DetectorType][]detectors.Detector), opener: opener}
+	for _, d := range dets {
+		v.detectors[d.Type()] = append(v.detectors[d.Type()], d)
+	}
+	return v
+}
+
+// Run verifies the findings of r, one JSON finding per line, with up to
+// concurrency at a time, and returns their outcomes in the order of r.
+func (v *Verifier) Run(ctx context.Context, r io.Reader, concurrency int) ([]Outcome, error) {
+	findings, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]Outcome, len(findings))
+	var g errgroup.Group
+	g.SetLimit(max(concurrency, 1))
+	for i := range findings {
+		g.Go(func() error {
+			outcomes[i] = v.Reverify(ctx, findings[i].Finding)
+			outcomes[i].Line = findings[i].Line
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+// LineFinding is a finding and the line of the input it was read from.
+type LineFinding struct {
+	Line int
+	Finding
+}
+
+// Read reads the findings of r, one JSON finding per line as the json format
+// prints them. Blank lines are skipped.
+func Read(r io.Reader) ([]LineFinding, error) {
+	var findings []LineFinding
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var f Finding
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			return nil, fmt.Errorf("line %d: invalid finding: %w", n, err)
+		}
+		findings = append(findings, LineFinding{Line: n, Finding: f})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// Reverify verifies f again. Its secret is found again by the detectors of
+// its type in data made of its raw values, which are then verified, so
+// findings whose secret was masked in the output can't be verified again.
+func (v *Verifier) Reverify(ctx context.Context, f Finding) Outcome {
+	outcome := Outcome{
+		DetectorName:   f.DetectorName,
+		Redacted:       f.Redacted,
+		Fingerprint:    f.Fingerprint,
+		SourceMetadata: f.SourceMetadata,
+		WasVerified:    f.Verified,
+	}
+	if outcome.DetectorName == "" {
+		outcome.DetectorName = f.DetectorType.String()
+	}
+	skip := func(reason string) Outcome {
+		outcome.Status, outcome.Reason = Skipped, reason
+		return outcome
+	}
+
+	if f.Unverifiable {
+		return skip("secrets of this detector can't be verified")
+	}
+	raw, err := v.open(ctx, f.Raw)
+	if err != nil {
+		return skip(err.Error())
+	}
+	rawV2, err := v.open(ctx, f.RawV2)
+	if err != nil {
+		return skip(err.Error())
+	}
+	if raw == "" || maskedPat.MatchString(raw) || maskedPat.MatchString(rawV2) {
+		return skip("secret is masked, scan with --show-secrets or --secrets-encryption-key to reverify it")
+	}
+	dets := v.detectors[f.DetectorType]
+	if len(dets) == 0 {
+		return skip("detector isn't selected")
+	}
+
+	for _, d := range dets {
+		results, err := d.FromData(ctx, true, rebuild(d, raw, rawV2, f.Context))
+		if err != nil {
+			ctx.Logger().V(2).Info("could not reverify finding", "detector", outcome.DetectorName, "error", err)
+		}
+		result, ok := match(results, raw, rawV2)
+		if !ok {
+			continue
+		}
+		switch {
+		case result.Verified:
+			outcome.Status = Live
+		case result.VerificationError() != nil:
+			outcome.Status, outcome.Reason = Unknown, result.VerificationError().Error()
+		case f.Verified:
+			outcome.Status = Revoked
+		default:
+			outcome.Status = Unverified
+		}
+		return outcome
+	}
+	return skip("detector doesn't find the secret in its raw values")
+}
+
+func (v *Verifier) open(ctx context.Context, value string) (string, error) {
+	if !envelope.IsSealed(value) {
+		return value, nil
+	}
+	if v.opener == nil {
+		return "", errors.New("secret is encrypted")
+	}
+	secret, err := v.opener.Open(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// rebuild makes the data d finds the secret of a finding in again: the line
+// it was found on, if it was captured unmasked, and its raw values and their
+// parts, each after a keyword of d, as detectors look for secrets near their
+// keywords.
+func rebuild(d detectors.Detector, raw, rawV2 string, lineContext *detectors.LineContext) []byte {
+	var b strings.Builder
+	if lineContext != nil && strings.Contains(lineContext.Line, raw) {
+		b.WriteString(lineContext.Line)
+		b.WriteString("\n")
+	}
+
+	values := []string{raw}
+	if rawV2 != "" && rawV2 != raw {
+		values = append(values, rawV2)
+		if rest, ok := strings.CutPrefix(rawV2, raw); ok {
+			values = append(values, strings.TrimLeft(rest, ":;|/ "))
+		}
+		values = append(values, strings.FieldsFunc(rawV2, func(r rune) bool {
+			return r == ':' || r == ';' || r == '|'
+		})...)
+	}
+	keyword := ""
+	if keywords := d.Keywords(); len(keywords) > 0 {
+		keyword = keywords[0] + " "
+	}
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok || value == "" {
+			continue
+		}
+		seen[value] = struct{}{}
+		b.WriteString(keyword)
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// match returns the result of the secret of a finding, preferring the one
+// with the same RawV2.
+func match(results []detectors.Result, raw, rawV2 string) (detectors.Result, bool) {
+	var (
+		found detectors.Result
+		ok    bool
+	)
+	for _, r := range results {
+		if string(r.Raw) != raw {
+			continue
+		}
+		if rawV2 == "" || string(r.RawV2) == rawV2 {
+			return r, true
+		}
+		if !ok {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// Summarize counts the outcomes of each status.
+func Summarize(outcomes []Outcome) Summary {
+	summary := make(Summary)
+	for _, o := range outcomes {
+		summary[o.Status]++
+	}
+	return summary
+}
@@ -0,0 +1,110 @@
+package reverify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	regexp "github.com/wasilibs/go-re2"
+
+	logContext "github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+// fakeDetector finds fake_ tokens after its keyword, and verifies those of
+// live. Verifying the token fake_timeout1 fails.
+type fakeDetector struct {
+	live map[string]bool
+}
+
+var fakePat = regexp.MustCompile(detectors.PrefixRegex([]string{"fake"}) + `\b(fake_[a-z0-9]{8})\b`)
+
+func (d fakeDetector) Keywords() []string { return []string{"fake"} }
+
+func (d fakeDetector) FromData(_ context.Context, verify bool, data []byte) ([]detectors.Result, error) {
+	var results []detectors.Result
+	for _, match := range fakePat.FindAllStringSubmatch(string(data), -1) {
+		r := detectors.Result{DetectorType: detectorspb.DetectorType_CustomRegex, Raw: []byte(match[1])}
+		if verify {
+			if match[1] == "fake_timeout1" {
+				r.SetVerificationError(errors.New("timeout"))
+			}
+			r.Verified = d.live[match[1]]
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (d fakeDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType_CustomRegex }
+
+func (d fakeDetector) Description() string { return "" }
+
+func TestVerifier_Run(t *testing.T) {
+	input := strings.Join([]string{
+		`{"DetectorType":904,"DetectorName":"CustomRegex","Verified":true,"Raw":"fake_stillup1","Redacted":"fake_s..."}`,
+		`{"DetectorType":904,"DetectorName":"CustomRegex","Verified":true,"Raw":"fake_rotated1","Fingerprint":"abc"}`,
+		``,
+		`{"DetectorType":904,"Verified":false,"Raw":"fake_nevervfd"}`,
+		`{"DetectorType":904,"Verified":true,"Raw":"fake_timeout1"}`,
+		`{"DetectorType":904,"Verified":true,"Raw":"fake****vfd1 (sha256:0123456789ab)"}`,
+		`{"DetectorType":904,"Verified":true,"Raw":"enc:v1:age:abc:def"}`,
+		`{"DetectorType":2,"DetectorName":"AWS","Verified":true,"Raw":"AKIAEXAMPLE"}`,
+		`{"DetectorType":904,"Verified":false,"Unverifiable":true,"Raw":"fake_signing1"}`,
+		`{"DetectorType":904,"Verified":true,"Raw":"not-a-fake"}`,
+	}, "\n")
+	v := New([]detectors.Detector{fakeDetector{live: map[string]bool{"fake_stillup1": true, "fake_nevervfd": false}}}, nil)
+
+	outcomes, err := v.Run(logContext.Background(), strings.NewReader(input), 4)
+	require.NoError(t, err)
+
+	type got struct {
+		line   int
+		status Status
+	}
+	var statuses []got
+	for _, o := range outcomes {
+		statuses = append(statuses, got{o.Line, o.Status})
+	}
+	assert.Equal(t, []got{
+		{1, Live},
+		{2, Revoked},
+		{4, Unverified},
+		{5, Unknown},
+		{6, Skipped},
+		{7, Skipped},
+		{8, Skipped},
+		{9, Skipped},
+		{10, Skipped},
+	}, statuses)
+
+	assert.Equal(t, "fake_s...", outcomes[0].Redacted)
+	assert.Equal(t, "abc", outcomes[1].Fingerprint)
+	assert.Equal(t, "CustomRegex", outcomes[2].DetectorName)
+	assert.Equal(t, "timeout", outcomes[3].Reason)
+	assert.Contains(t, outcomes[4].Reason, "masked")
+	assert.Equal(t, "secret is encrypted", outcomes[5].Reason)
+	assert.Equal(t, "detector isn't selected", outcomes[6].Reason)
+
+	assert.Equal(t, Summary{Live: 1, Revoked: 1, Unverified: 1, Unknown: 1, Skipped: 5}, Summarize(outcomes))
+}
+
+func TestVerifier_RunInvalid(t *testing.T) {
+	v := New(nil, nil)
+	_, err := v.Run(logContext.Background(), strings.NewReader("{\"Raw\":\"a\"}\nFound verified result\n"), 1)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestRebuild(t *testing.T) {
+	d := fakeDetector{}
+	data := rebuild(d, "id123", "id123:s3cr3t", &detectors.LineContext{Line: "client = id123"})
+	assert.Equal(t, "client = id123\nfake id123\nfake id123:s3cr3t\nfake s3cr3t\n", string(data))
+
+	// Masked lines aren't used.
+	data = rebuild(d, "id123", "", &detectors.LineContext{Line: "client = id1****"})
+	assert.Equal(t, "fake id123\n", string(data))
+}
@@ -0,0 +1,304 @@
+// Package runmanifest records what a scan ran with, so that it can be run
+// again the same way after the flags' defaults or the set of detectors of a
+// newer version have changed.
+package runmanifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+// Manifest describes a run.
+type Manifest struct {
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Args are the command line arguments of the run, without the flags of
+	// Redacted.
+	Args []string `json:"args"`
+	// Redacted lists the flags that held credentials, which have to be given
+	// again to rerun the scan, and the URLs whose passwords were masked.
+	Redacted []string `json:"redacted,omitempty"`
+	// Files are the contents of the files named by Args, such as the config
+	// file and the path filters.
+	Files     []File     `json:"files,omitempty"`
+	Detectors []Detector `json:"detectors"`
+}
+
+// File is a file named by a flag of a run.
+type File struct {
+	Flag    string `json:"flag"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Detector is a detector that ran.
+type Detector struct {
+	Name    string `json:"name"`
+	Version int    `json:"version,omitempty"`
+}
+
+// Options tell how the command line of a run is recorded and replayed.
+type Options struct {
+	// Lookup returns the long name of a flag given by its long or short name,
+	// without dashes, and whether it takes a value. The positional arguments
+	// before the flag, which name its command, are given too, since short
+	// names are per command. It returns false for unknown flags.
+	Lookup func(positional []string, name string) (long string, takesValue bool, ok bool)
+	// Secrets are the flags whose values are left out.
+	Secrets []string
+	// Files are the flags whose files are recorded.
+	Files []string
+	// Omit are the flags that aren't recorded at all, such as those of the
+	// manifests themselves.
+	Omit []string
+}
+
+// New records a run with the command line args and the detectors dets.
+func New(args []string, dets []detectors.Detector, opts Options) (*Manifest, error) {
+	m := &Manifest{
+		Version:   version.BuildVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Args:      []string{},
+		Detectors: []Detector{},
+	}
+	for _, a := range splitArgs(args, opts.Lookup) {
+		switch {
+		case slices.Contains(opts.Omit, a.flag):
+			continue
+		case slices.Contains(opts.Secrets, a.flag):
+			if !slices.Contains(m.Redacted, a.flag) {
+				m.Redacted = append(m.Redacted, a.flag)
+			}
+			continue
+		case a.hasValue && slices.Contains(opts.Files, a.flag):
+			content, err := os.ReadFile(a.value)
+			if err != nil {
+				return nil, fmt.Errorf("could not record --%s: %w", a.flag, err)
+			}
+			m.Files = append(m.Files, File{Flag: a.flag, Path: a.value, Content: string(content)})
+		}
+		for _, token := range a.tokens {
+			prefix, value := "", token
+			if flag, v, ok := strings.Cut(token, "="); ok && strings.HasPrefix(flag, "-") {
+				prefix, value = flag+"=", v
+			}
+			if redacted := redactURL(value); redacted != value {
+				m.Redacted = append(m.Redacted, redacted)
+				token = prefix + redacted
+			}
+			m.Args = append(m.Args, token)
+		}
+	}
+	seen := make(map[config.DetectorID]struct{})
+	for _, d := range dets {
+		id := config.GetDetectorID(d)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		m.Detectors = append(m.Detectors, Detector{Name: id.ID.String(), Version: id.Version})
+	}
+	slices.SortFunc(m.Detectors, func(a, b Detector) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return a.Version - b.Version
+	})
+	return m, nil
+}
+
+// redactURL masks the password of s if it is a URL.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	return u.Redacted()
+}
+
+// Read reads the manifest at path.
+func Read(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid run manifest %s: %w", path, err)
+	}
+	if len(m.Args) == 0 {
+		return nil, fmt.Errorf("invalid run manifest %s: no arguments", path)
+	}
+	return &m, nil
+}
+
+// Write writes m to path.
+func (m *Manifest) Write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// ReplayArgs returns the command line that reruns the scan of m. The recorded
+// files are written to dir and the arguments refer to them instead. The flags
+// of extra override the recorded ones and are appended, so they can supply
+// the redacted credentials.
+func (m *Manifest) ReplayArgs(extra []string, dir string, opts Options) ([]string, error) {
+	overridden := make(map[string]bool)
+	for _, a := range splitArgs(extra, opts.Lookup) {
+		if a.flag == "" {
+			return nil, fmt.Errorf("argument %q can't be given with a run manifest, only flags can", a.tokens[0])
+		}
+		overridden[a.flag] = true
+	}
+
+	var args []string
+	files := m.Files
+	for _, a := range splitArgs(m.Args, opts.Lookup) {
+		if a.flag != "" && overridden[a.flag] {
+			continue
+		}
+		if a.hasValue && slices.Contains(opts.Files, a.flag) {
+			i := slices.IndexFunc(files, func(f File) bool { return f.Flag == a.flag && f.Path == a.value })
+			if i < 0 {
+				return nil, fmt.Errorf("run manifest has no contents for --%s=%s", a.flag, a.value)
+			}
+			path := filepath.Join(dir, fmt.Sprintf("%d-%s", len(args), filepath.Base(a.value)))
+			if err := os.WriteFile(path, []byte(files[i].Content), 0o600); err != nil {
+				return nil, err
+			}
+			args = append(args, "--"+a.flag+"="+path)
+			continue
+		}
+		args = append(args, a.tokens...)
+	}
+	return append(args, extra...), nil
+}
+
+// Missing returns the redacted flags of m that args don't give, and the
+// URLs whose passwords were masked.
+func (m *Manifest) Missing(args []string, opts Options) []string {
+	given := make(map[string]bool)
+	for _, a := range splitArgs(args, opts.Lookup) {
+		given[a.flag] = true
+	}
+	var missing []string
+	for _, redacted := range m.Redacted {
+		if !given[redacted] {
+			missing = append(missing, redacted)
+		}
+	}
+	return missing
+}
+
+// IncludeDetectors returns the detectors of m as a list for
+// --include-detectors. The names this version doesn't know are returned
+// separately, since they can't be selected.
+func (m *Manifest) IncludeDetectors() (include string, unknown []string) {
+	var names []string
+	for _, d := range m.Detectors {
+		name := d.Name
+		if d.Version > 0 {
+			name = fmt.Sprintf("%s.v%d", d.Name, d.Version)
+		}
+		if _, err := config.ParseDetector(name); err != nil {
+			unknown = append(unknown, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ","), unknown
+}
+
+// FlagValue returns the value of the flag name in args, which is how the
+// manifest to replay is found before the command line is parsed.
+func FlagValue(args []string, name string, opts Options) (string, error) {
+	for _, a := range splitArgs(args, opts.Lookup) {
+		if a.flag != name {
+			continue
+		}
+		if !a.hasValue {
+			return "", errors.New("expected a value for --" + name)
+		}
+		return a.value, nil
+	}
+	return "", nil
+}
+
+// arg is a positional argument, or a flag with its value.
+type arg struct {
+	// flag is the long name of the flag, or empty for positional arguments.
+	flag     string
+	value    string
+	hasValue bool
+	tokens   []string
+}
+
+func splitArgs(args []string, lookup func([]string, string) (string, bool, bool)) []arg {
+	var (
+		out        []arg
+		positional []string
+	)
+	for i := 0; i < len(args); i++ {
+		token := args[i]
+		if token == "--" {
+			for _, rest := range args[i:] {
+				out = append(out, arg{tokens: []string{rest}})
+			}
+			break
+		}
+		var (
+			name, long, value         string
+			inline, takesValue, known bool
+		)
+		switch {
+		case strings.HasPrefix(token, "--") && len(token) > 2:
+			name, value, inline = strings.Cut(token[2:], "=")
+			long, takesValue, known = lookup(positional, name)
+			if negated, ok := strings.CutPrefix(name, "no-"); !known && ok {
+				long, _, known = lookup(positional, negated)
+			}
+		case strings.HasPrefix(token, "-") && len(token) > 1:
+			// Short flags can be followed by their value, as in -ipaths.txt.
+			name = token[1:2]
+			long, takesValue, known = lookup(positional, name)
+			if takesValue && len(token) > 2 {
+				value, inline = token[2:], true
+			}
+		default:
+			out = append(out, arg{tokens: []string{token}})
+			positional = append(positional, token)
+			continue
+		}
+		if !known {
+			long = name
+		}
+		a := arg{flag: long, tokens: []string{token}}
+		switch {
+		case inline:
+			a.value, a.hasValue = value, true
+		case takesValue && i+1 < len(args):
+			i++
+			a.value, a.hasValue = args[i], true
+			a.tokens = append(a.tokens, args[i])
+		}
+		out = append(out, a)
+	}
+	return out
+}
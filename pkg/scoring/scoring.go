@@ -0,0 +1,173 @@
+// Package scoring lets an external model, such as a false positive classifier,
+// score the candidate secrets detectors find before they are verified. The
+// model runs outside of TruffleHog, either behind an HTTP endpoint or in a
+// local executable that loads it, such as an ONNX model run by a Python
+// script, and both are sent the same JSON:
+//
+//	{"candidates": [{"detector": "AWS", "secret": "AKIA...", "match": "aws_access_key_id = AKIA..."}]}
+//
+// and answer with a score between 0 and 1 for each candidate, in order:
+//
+//	{"scores": [0.93]}
+package scoring
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Candidate is a secret a detector found, before it is verified.
+type Candidate struct {
+	Detector string `json:"detector"`
+	// Secret is the raw secret, and SecretV2 the combination of its ID and
+	// secret for multi-part credentials.
+	Secret   string `json:"secret"`
+	SecretV2 string `json:"secret_v2,omitempty"`
+	// Match is the data the detector found the secret in.
+	Match string `json:"match"`
+	File  string `json:"file,omitempty"`
+}
+
+// Scorer scores how likely candidates are to be real secrets, from 0 to 1.
+type Scorer interface {
+	Score(ctx context.Context, candidates []Candidate) ([]float64, error)
+}
+
+type request struct {
+	Candidates []Candidate `json:"candidates"`
+}
+
+type response struct {
+	Scores []float64 `json:"scores"`
+}
+
+// New returns the scorer of spec, which is the URL of an HTTP endpoint, or
+// the path to an executable that is started once and scores candidates sent
+// on its stdin, one JSON request per line, answering each with one line.
+func New(ctx context.Context, spec string) (Scorer, error) {
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		return &endpointScorer{client: common.SaneHttpClient(), url: spec}, nil
+	}
+	return startCommand(ctx, spec)
+}
+
+// endpointScorer POSTs candidates to an HTTP endpoint.
+type endpointScorer struct {
+	client *http.Client
+	url    string
+}
+
+func (s *endpointScorer) Score(ctx context.Context, candidates []Candidate) ([]float64, error) {
+	body, err := json.Marshal(request{Candidates: candidates})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scoring endpoint returned %s", res.Status)
+	}
+	var resp response
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid scoring response: %w", err)
+	}
+	return checkScores(resp.Scores, len(candidates))
+}
+
+// CommandScorer scores candidates with a long-running executable.
+type CommandScorer struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+}
+
+func startCommand(ctx context.Context, path string) (*CommandScorer, error) {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start scorer %s: %w", path, err)
+	}
+
+	logger := ctx.Logger().WithValues("scorer", filepath.Base(path))
+	go func() {
+		lines := bufio.NewScanner(stderr)
+		for lines.Scan() {
+			logger.Info(lines.Text())
+		}
+	}()
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, 1<<20)
+	return &CommandScorer{cmd: cmd, stdin: stdin, stdout: scanner}, nil
+}
+
+func (s *CommandScorer) Score(_ context.Context, candidates []Candidate) ([]float64, error) {
+	line, err := json.Marshal(request{Candidates: candidates})
+	if err != nil {
+		return nil, err
+	}
+
+	// The scorer answers requests in order, so they are sent one at a time.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.stdin.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("could not write to scorer: %w", err)
+	}
+	if !s.stdout.Scan() {
+		if err := s.stdout.Err(); err != nil {
+			return nil, fmt.Errorf("could not read from scorer: %w", err)
+		}
+		return nil, errors.New("scorer exited")
+	}
+	var resp response
+	if err := json.Unmarshal(s.stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid scoring response: %w", err)
+	}
+	return checkScores(resp.Scores, len(candidates))
+}
+
+// Close stops the executable by closing its stdin, and waits for it to exit.
+func (s *CommandScorer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.stdin.Close()
+	return s.cmd.Wait()
+}
+
+func checkScores(scores []float64, n int) ([]float64, error) {
+	if len(scores) != n {
+		return nil, fmt.Errorf("scorer returned %d scores for %d candidates", len(scores), n)
+	}
+	return scores, nil
+}
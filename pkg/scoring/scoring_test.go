@@ -0,0 +1,88 @@
+package scoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+var candidates = []Candidate{
+	{Detector: "AWS", Secret: "AKIAEXAMPLE", Match: "key = AKIAEXAMPLE"},
+	{Detector: "Github", Secret: "ghp_example", Match: "token: ghp_example", File: "config.yaml"},
+}
+
+func TestEndpointScorer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, candidates, req.Candidates)
+		scores := make([]float64, len(req.Candidates))
+		for i, c := range req.Candidates {
+			if c.File != "" {
+				scores[i] = 0.9
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response{Scores: scores})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s, err := New(ctx, srv.URL)
+	require.NoError(t, err)
+	scores, err := s.Score(ctx, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.9}, scores)
+}
+
+func TestEndpointScorer_Errors(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response{Scores: []float64{1}})
+	}))
+	defer srv.Close()
+
+	s, err := New(ctx, srv.URL+"/down")
+	require.NoError(t, err)
+	_, err = s.Score(ctx, candidates)
+	assert.ErrorContains(t, err, "503")
+
+	s, err = New(ctx, srv.URL)
+	require.NoError(t, err)
+	_, err = s.Score(ctx, candidates)
+	assert.ErrorContains(t, err, "1 scores for 2 candidates")
+}
+
+func TestCommandScorer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test scorer is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "scorer.sh")
+	script := "#!/bin/sh\necho starting >&2\nwhile read -r line; do echo '{\"scores\": [0.25, 0.75]}'; done\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+
+	ctx := context.Background()
+	s, err := New(ctx, path)
+	require.NoError(t, err)
+	for range 2 {
+		scores, err := s.Score(ctx, candidates)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.25, 0.75}, scores)
+	}
+	assert.NoError(t, s.(*CommandScorer).Close())
+
+	_, err = New(ctx, filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
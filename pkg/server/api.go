@@ -0,0 +1,372 @@
+package server
+
+import (
+	aCtx "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+)
+
+// Handler returns the HTTP handler of the job API, and the role each
+// endpoint requires:
+//
+//	POST   /v1/jobs                submit  submit a JobRequest
+//	GET    /v1/jobs                read    list jobs
+//	GET    /v1/jobs/{id}           read    get a job's status and progress
+//	DELETE /v1/jobs/{id}           submit  cancel a job
+//	GET    /v1/jobs/{id}/findings  read    stream a job's findings as JSON lines
+//
+//	POST   /v1/sources             submit  store a JobRequest for schedules to scan
+//	GET    /v1/sources             read    list stored sources
+//	GET    /v1/sources/{id}        read    get a stored source
+//	DELETE /v1/sources/{id}        submit  delete a stored source
+//
+//	POST   /v1/schedules           submit  create a ScheduleRequest
+//	GET    /v1/schedules           read    list schedules
+//	GET    /v1/schedules/{id}      read    get a schedule and its run history
+//	DELETE /v1/schedules/{id}      submit  delete a schedule
+//	POST   /v1/schedules/{id}/run  submit  run a schedule now
+//
+//	GET    /v1/tenants             admin   list tenants and their overrides
+//	PUT    /v1/tenants/{name}      admin   replace a tenant's TenantOverrides
+//
+// Findings are streamed until the job finishes, unless follow=false is given,
+// in which case only the findings found so far are returned. Requests
+// authenticated as a tenant only see the tenant's jobs, stored sources, and
+// schedules, and create them for it. Every change, every read of findings,
+// and every denied request is recorded in the audit log.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/jobs", s.require(RoleSubmit, s.submitJob))
+	mux.HandleFunc("GET /v1/jobs", s.require(RoleRead, s.listJobs))
+	mux.HandleFunc("GET /v1/jobs/{id}", s.require(RoleRead, s.withJob(s.getJob)))
+	mux.HandleFunc("DELETE /v1/jobs/{id}", s.require(RoleSubmit, s.withJob(s.cancelJob)))
+	mux.HandleFunc("GET /v1/jobs/{id}/findings", s.require(RoleRead, s.withJob(s.streamFindings)))
+	mux.HandleFunc("POST /v1/sources", s.require(RoleSubmit, s.addSource))
+	mux.HandleFunc("GET /v1/sources", s.require(RoleRead, s.listSources))
+	mux.HandleFunc("GET /v1/sources/{id}", s.require(RoleRead, s.getSource))
+	mux.HandleFunc("DELETE /v1/sources/{id}", s.require(RoleSubmit, s.deleteSource))
+	mux.HandleFunc("POST /v1/schedules", s.require(RoleSubmit, s.addSchedule))
+	mux.HandleFunc("GET /v1/schedules", s.require(RoleRead, s.listSchedules))
+	mux.HandleFunc("GET /v1/schedules/{id}", s.require(RoleRead, s.withSchedule(s.getSchedule)))
+	mux.HandleFunc("DELETE /v1/schedules/{id}", s.require(RoleSubmit, s.deleteSchedule))
+	mux.HandleFunc("POST /v1/schedules/{id}/run", s.require(RoleSubmit, s.withSchedule(s.runSchedule)))
+	mux.HandleFunc("GET /v1/tenants", s.require(RoleAdmin, s.listTenants))
+	mux.HandleFunc("PUT /v1/tenants/{name}", s.require(RoleAdmin, s.setTenantOverrides))
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		c, err := s.authorize(r.Context(), token)
+		if err != nil {
+			s.writeAudit(AuditEvent{Time: time.Now().UTC(), Caller: "unauthenticated", Action: "denied", Error: err.Error()})
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(aCtx.WithValue(r.Context(), callerKey{}, c)))
+	})
+}
+
+func (s *Server) withJob(handle func(http.ResponseWriter, *http.Request, *Job)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := s.Job(r.PathValue("id"))
+		// Jobs of other tenants are reported as not found, like their
+		// stored sources and schedules, so tenants can't probe each
+		// other's IDs.
+		if !ok || !callerOf(r.Context()).sees(job.Tenant) {
+			writeError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		handle(w, r, job)
+	}
+}
+
+func (s *Server) withSchedule(handle func(http.ResponseWriter, *http.Request, *Schedule)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sc, ok := s.Schedule(r.PathValue("id"))
+		if !ok || !callerOf(r.Context()).sees(sc.Tenant) {
+			writeError(w, http.StatusNotFound, "schedule not found")
+			return
+		}
+		handle(w, r, sc)
+	}
+}
+
+// decodeRequest decodes a request body into v, writing an error response if
+// it's invalid.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// scopeRequest runs the jobs of requests authenticated as a tenant for it.
+func scopeRequest(r *http.Request, req *JobRequest) {
+	if c := callerOf(r.Context()); c.tenant != "" {
+		req.Tenant = c.tenant
+	}
+}
+
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	scopeRequest(r, &req)
+	job, err := s.Submit(req)
+	if err != nil {
+		s.audit(r.Context(), AuditEvent{Action: "job.submit", SourceType: req.SourceType, SourceName: req.Name, Error: err.Error()})
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.audit(r.Context(), jobEvent("job.submit", job))
+	writeJSON(w, http.StatusCreated, job.Status())
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	c := callerOf(r.Context())
+	jobs := s.Jobs()
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		if c.sees(job.Tenant) {
+			statuses = append(statuses, job.Status())
+		}
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, _ *http.Request, job *Job) {
+	writeJSON(w, http.StatusOK, job.Status())
+}
+
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request, job *Job) {
+	s.audit(r.Context(), jobEvent("job.cancel", job))
+	job.Cancel()
+	writeJSON(w, http.StatusAccepted, job.Status())
+}
+
+func (s *Server) streamFindings(w http.ResponseWriter, r *http.Request, job *Job) {
+	s.audit(r.Context(), jobEvent("job.read_findings", job))
+	follow := r.URL.Query().Get("follow") != "false"
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	offset := 0
+	for {
+		// Check whether the job is done before reading its findings, so
+		// none found in between are missed.
+		var done bool
+		select {
+		case <-job.Done():
+			done = true
+		default:
+		}
+		findings, changed := job.Findings(offset)
+		for _, finding := range findings {
+			if _, err := w.Write(finding); err != nil {
+				return
+			}
+			if _, err := w.Write([]byte("\n")); err != nil {
+				return
+			}
+		}
+		offset += len(findings)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if done || !follow {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+func (s *Server) addSource(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	scopeRequest(r, &req)
+	source, err := s.AddSource(req)
+	if err != nil {
+		s.audit(r.Context(), AuditEvent{Action: "source.add", SourceType: req.SourceType, SourceName: req.Name, Error: err.Error()})
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.audit(r.Context(), sourceEvent("source.add", source))
+	writeJSON(w, http.StatusCreated, source)
+}
+
+func (s *Server) listSources(w http.ResponseWriter, r *http.Request) {
+	c := callerOf(r.Context())
+	sources := make([]*StoredSource, 0)
+	for _, source := range s.Sources() {
+		if c.sees(source.Tenant) {
+			sources = append(sources, source)
+		}
+	}
+	writeJSON(w, http.StatusOK, sources)
+}
+
+// visibleSource returns the stored source with the given ID if the caller
+// of r may see it.
+func (s *Server) visibleSource(r *http.Request, id string) (*StoredSource, bool) {
+	source, ok := s.Source(id)
+	if !ok || !callerOf(r.Context()).sees(source.Tenant) {
+		return nil, false
+	}
+	return source, true
+}
+
+func (s *Server) getSource(w http.ResponseWriter, r *http.Request) {
+	source, ok := s.visibleSource(r, r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errSourceNotFound.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, source)
+}
+
+func (s *Server) deleteSource(w http.ResponseWriter, r *http.Request) {
+	source, ok := s.visibleSource(r, r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errSourceNotFound.Error())
+		return
+	}
+	err := s.DeleteSource(source.ID)
+	event := sourceEvent("source.delete", source)
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.audit(r.Context(), event)
+	switch {
+	case errors.Is(err, errSourceNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errSourceInUse):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) addSchedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if _, ok := s.visibleSource(r, req.SourceID); !ok {
+		writeError(w, http.StatusBadRequest, errSourceNotFound.Error())
+		return
+	}
+	sc, err := s.AddSchedule(req)
+	if err != nil {
+		s.audit(r.Context(), AuditEvent{Action: "schedule.add", SourceID: req.SourceID, Error: err.Error()})
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.audit(r.Context(), AuditEvent{Action: "schedule.add", ScheduleID: sc.ID, SourceID: sc.SourceID})
+	writeJSON(w, http.StatusCreated, sc.Status())
+}
+
+func (s *Server) listSchedules(w http.ResponseWriter, r *http.Request) {
+	c := callerOf(r.Context())
+	schedules := s.Schedules()
+	statuses := make([]ScheduleStatus, 0, len(schedules))
+	for _, sc := range schedules {
+		if c.sees(sc.Tenant) {
+			statuses = append(statuses, sc.Status())
+		}
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) getSchedule(w http.ResponseWriter, _ *http.Request, sc *Schedule) {
+	writeJSON(w, http.StatusOK, sc.Status())
+}
+
+func (s *Server) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.Schedule(r.PathValue("id"))
+	if !ok || !callerOf(r.Context()).sees(sc.Tenant) || !s.DeleteSchedule(sc.ID) {
+		writeError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+	s.audit(r.Context(), AuditEvent{Action: "schedule.delete", ScheduleID: sc.ID, SourceID: sc.SourceID})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) runSchedule(w http.ResponseWriter, r *http.Request, sc *Schedule) {
+	s.audit(r.Context(), AuditEvent{Action: "schedule.run", ScheduleID: sc.ID, SourceID: sc.SourceID})
+	s.RunSchedule(sc)
+	writeJSON(w, http.StatusAccepted, sc.Status())
+}
+
+func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
+	c := callerOf(r.Context())
+	tenants := make([]TenantStatus, 0)
+	for _, t := range s.Tenants() {
+		if c.sees(t.Name) {
+			tenants = append(tenants, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, tenants)
+}
+
+func (s *Server) setTenantOverrides(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !callerOf(r.Context()).sees(name) {
+		writeError(w, http.StatusNotFound, errTenantNotFound.Error())
+		return
+	}
+	var overrides TenantOverrides
+	if !decodeRequest(w, r, &overrides) {
+		return
+	}
+	tenant, err := s.SetTenantOverrides(name, overrides)
+	event := AuditEvent{Action: "tenant.update_overrides"}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	// The event names the tenant changed, not the caller's.
+	c := callerOf(r.Context())
+	event.Time, event.Caller, event.Tenant = time.Now().UTC(), c.name, name
+	s.writeAudit(event)
+	switch {
+	case errors.Is(err, errTenantNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, tenant)
+	}
+}
+
+func jobEvent(action string, job *Job) AuditEvent {
+	return AuditEvent{Action: action, JobID: job.ID, SourceType: engine.SourceTypeName(job.SourceType), SourceName: job.Name}
+}
+
+func sourceEvent(action string, source *StoredSource) AuditEvent {
+	return AuditEvent{Action: action, SourceID: source.ID, SourceType: source.SourceType, SourceName: source.Name}
+}
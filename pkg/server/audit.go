@@ -0,0 +1,70 @@
+package server
+
+import (
+	aCtx "context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// AuditEvent records who did what to which job, stored source, schedule, or
+// tenant through the API.
+type AuditEvent struct {
+	Time time.Time `json:"time"`
+	// Caller identifies who made the request: "token" for the server's
+	// token, "tenant:NAME", "key:NAME", "oidc:SUBJECT", "anonymous" when the server doesn't authenticate
+	// requests, or "unauthenticated" for requests with an invalid token.
+	Caller string `json:"caller"`
+	// Tenant is the tenant the caller is limited to, if any.
+	Tenant     string `json:"tenant,omitempty"`
+	Action     string `json:"action"`
+	JobID      string `json:"job_id,omitempty"`
+	SourceID   string `json:"source_id,omitempty"`
+	ScheduleID string `json:"schedule_id,omitempty"`
+	// SourceType and SourceName describe the source scanned or stored.
+	SourceType string `json:"source_type,omitempty"`
+	SourceName string `json:"source_name,omitempty"`
+	// Error is why the request was denied or failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SetAuditLog writes the audit log to w as JSON lines instead of the
+// server's logger.
+func (s *Server) SetAuditLog(w io.Writer) {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	s.auditLog = w
+}
+
+// audit records an event of the caller of ctx.
+func (s *Server) audit(ctx aCtx.Context, event AuditEvent) {
+	c := callerOf(ctx)
+	event.Time = time.Now().UTC()
+	event.Caller, event.Tenant = c.name, c.tenant
+	s.writeAudit(event)
+}
+
+func (s *Server) writeAudit(event AuditEvent) {
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	if s.auditLog == nil {
+		s.ctx.Logger().WithName("audit").Info(event.Action,
+			"caller", event.Caller,
+			"tenant", event.Tenant,
+			"job_id", event.JobID,
+			"source_id", event.SourceID,
+			"schedule_id", event.ScheduleID,
+			"source_type", event.SourceType,
+			"source_name", event.SourceName,
+			"error", event.Error,
+		)
+		return
+	}
+	data, err := json.Marshal(event)
+	if err == nil {
+		_, err = s.auditLog.Write(append(data, '\n'))
+	}
+	if err != nil {
+		s.ctx.Logger().Error(err, "could not write audit log", "action", event.Action, "caller", event.Caller)
+	}
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	aCtx "context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Role is a permission granted to API clients.
+type Role string
+
+const (
+	// RoleSubmit submits and cancels jobs, and manages stored sources and
+	// schedules.
+	RoleSubmit Role = "submit"
+	// RoleRead reads jobs, their findings, stored sources, and schedules.
+	RoleRead Role = "read"
+	// RoleAdmin manages the detector and policy overrides of tenants, and
+	// has every other role.
+	RoleAdmin Role = "admin"
+)
+
+func parseRole(role string) (Role, error) {
+	switch r := Role(role); r {
+	case RoleSubmit, RoleRead, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q, must be one of submit, read, or admin", role)
+	}
+}
+
+// APIKey is a key API clients authenticate with, granting them roles.
+type APIKey struct {
+	// Name identifies the client in the audit log.
+	Name  string   `yaml:"name"`
+	Key   string   `yaml:"key"`
+	Roles []string `yaml:"roles"`
+	// Tenant limits the client to a tenant's jobs, stored sources, and
+	// schedules. Clients of keys without one see every tenant.
+	Tenant string `yaml:"tenant"`
+}
+
+type apiKeysFile struct {
+	Keys []APIKey `yaml:"keys"`
+}
+
+// LoadAPIKeys reads the API keys of the YAML file at path, which lists them
+// under a top-level keys key.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read API keys: %w", err)
+	}
+	var file apiKeysFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse API keys: %w", err)
+	}
+	return file.Keys, nil
+}
+
+// SetAPIKeys configures the API keys clients may authenticate with besides
+// the server's and tenants' tokens. Once there are keys, requests must
+// authenticate.
+func (s *Server) SetAPIKeys(keys []APIKey) error {
+	callers := make(map[string]caller, len(keys))
+	for i, k := range keys {
+		switch {
+		case k.Name == "":
+			return fmt.Errorf("API key %d has no name", i+1)
+		case k.Key == "":
+			return fmt.Errorf("API key %s has no key", k.Name)
+		}
+		if _, ok := callers[k.Key]; ok {
+			return fmt.Errorf("API key %s is listed twice", k.Name)
+		}
+		roles, err := parseRoles(k.Roles)
+		if err != nil {
+			return fmt.Errorf("API key %s: %w", k.Name, err)
+		}
+		callers[k.Key] = caller{name: "key:" + k.Name, tenant: k.Tenant, roles: roles}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKeys = callers
+	return nil
+}
+
+func parseRoles(names []string) (map[Role]bool, error) {
+	roles := make(map[Role]bool, len(names))
+	for _, name := range names {
+		role, err := parseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		roles[role] = true
+	}
+	return roles, nil
+}
+
+// caller is who a request is authenticated as.
+type caller struct {
+	// name identifies the caller in the audit log.
+	name string
+	// tenant is the tenant the caller is limited to, if any.
+	tenant string
+	roles  map[Role]bool
+}
+
+// allRoles are the roles of the server's token, and of every request when
+// the server doesn't authenticate them.
+var allRoles = map[Role]bool{RoleSubmit: true, RoleRead: true, RoleAdmin: true}
+
+// sees reports whether the caller may see what belongs to tenant.
+func (c caller) sees(tenant string) bool { return c.tenant == "" || c.tenant == tenant }
+
+// has reports whether the caller has role.
+func (c caller) has(role Role) bool { return c.roles[role] || c.roles[RoleAdmin] }
+
+type callerKey struct{}
+
+// callerOf returns the caller of a request authenticated by the server.
+func callerOf(ctx aCtx.Context) caller {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c
+}
+
+// authorize returns who a request presenting token is, if anyone. Without a
+// server token, tenants, API keys, or OIDC, every request may do anything.
+func (s *Server) authorize(ctx aCtx.Context, token string) (caller, error) {
+	s.mu.Lock()
+	tenants, keys, oidc := s.tenants, s.apiKeys, s.oidc
+	s.mu.Unlock()
+	if token == "" {
+		if s.token == "" && len(tenants) == 0 && len(keys) == 0 && oidc == nil {
+			return caller{name: "anonymous", roles: allRoles}, nil
+		}
+		return caller{}, errors.New("missing token")
+	}
+
+	// Every token is compared, so how long it takes doesn't reveal which
+	// one a guess was close to.
+	var match caller
+	var found bool
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+		match, found = caller{name: "token", roles: allRoles}, true
+	}
+	for _, t := range tenants {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) == 1 {
+			// Tenants can't change their own overrides.
+			match, found = caller{name: "tenant:" + t.Name, tenant: t.Name, roles: map[Role]bool{RoleSubmit: true, RoleRead: true}}, true
+		}
+	}
+	for key, c := range keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			match, found = c, true
+		}
+	}
+	if found {
+		return match, nil
+	}
+	if oidc != nil && strings.Count(token, ".") == 2 {
+		return oidc.verify(ctx, token)
+	}
+	return caller{}, errors.New("invalid token")
+}
+
+// require only lets callers with role through to handle.
+func (s *Server) require(role Role, handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := callerOf(r.Context())
+		if !c.has(role) {
+			s.audit(r.Context(), AuditEvent{Action: "denied", Error: fmt.Sprintf("%s %s requires the %s role", r.Method, r.URL.Path, role)})
+			writeError(w, http.StatusForbidden, fmt.Sprintf("the %s role is required", role))
+			return
+		}
+		handle(w, r)
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_APIKeys(t *testing.T) {
+	dir := t.TempDir()
+	s := newServer(t, "", nil)
+	require.NoError(t, s.SetTenants([]Tenant{{Name: "payments", Token: "payments-token"}}))
+	require.NoError(t, s.SetAPIKeys([]APIKey{
+		{Name: "ci", Key: "ci-key", Roles: []string{"submit", "read"}},
+		{Name: "dashboard", Key: "dashboard-key", Roles: []string{"read"}},
+		{Name: "security", Key: "admin-key", Roles: []string{"admin"}},
+		{Name: "payments-admin", Key: "payments-admin-key", Roles: []string{"admin"}, Tenant: "payments"},
+	}))
+	var audit bytes.Buffer
+	s.SetAuditLog(&audit)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+
+	body := `{"source_type": "filesystem", "name": "repo", "connection": {"paths": ["` + dir + `"]}}`
+	var job JobStatus
+	require.Equal(t, http.StatusCreated, request(t, ts, http.MethodPost, "/v1/jobs", "ci-key", body, &job))
+	assert.Equal(t, http.StatusForbidden, request(t, ts, http.MethodPost, "/v1/jobs", "dashboard-key", body, nil))
+	assert.Equal(t, http.StatusOK, request(t, ts, http.MethodGet, "/v1/jobs/"+job.ID, "dashboard-key", "", nil))
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", "", "", nil))
+
+	// Only admins manage the overrides of tenants.
+	assert.Equal(t, http.StatusForbidden, request(t, ts, http.MethodGet, "/v1/tenants", "ci-key", "", nil))
+	assert.Equal(t, http.StatusForbidden, request(t, ts, http.MethodGet, "/v1/tenants", "payments-token", "", nil))
+	overrides := `{"include_detectors": "aws", "policy": "findings:\n  - name: no secrets\n    fail: 'true'\n"}`
+	var tenant TenantStatus
+	require.Equal(t, http.StatusOK, request(t, ts, http.MethodPut, "/v1/tenants/payments", "payments-admin-key", overrides, &tenant))
+	assert.Equal(t, "aws", tenant.IncludeDetectors)
+	assert.Equal(t, http.StatusNotFound, request(t, ts, http.MethodPut, "/v1/tenants/search", "admin-key", overrides, nil))
+	assert.Equal(t, http.StatusBadRequest, request(t, ts, http.MethodPut, "/v1/tenants/payments", "admin-key", `{"policy": "unknown: true"}`, nil))
+	var tenants []TenantStatus
+	require.Equal(t, http.StatusOK, request(t, ts, http.MethodGet, "/v1/tenants", "admin-key", "", &tenants))
+	require.Len(t, tenants, 1)
+	assert.Equal(t, "aws", tenants[0].IncludeDetectors)
+	assert.NotEmpty(t, tenants[0].Policy)
+
+	var actions []string
+	var submitted AuditEvent
+	for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		var event AuditEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		actions = append(actions, event.Caller+" "+event.Action)
+		if event.Action == "job.submit" {
+			submitted = event
+		}
+	}
+	assert.Equal(t, []string{
+		"key:ci job.submit",
+		"key:dashboard denied",
+		"unauthenticated denied",
+		"key:ci denied",
+		"tenant:payments denied",
+		"key:payments-admin tenant.update_overrides",
+		"key:security tenant.update_overrides",
+		"key:security tenant.update_overrides",
+	}, actions)
+	assert.Equal(t, job.ID, submitted.JobID)
+	assert.Equal(t, "filesystem", submitted.SourceType)
+	assert.Equal(t, "repo", submitted.SourceName)
+}
+
+func TestServer_TenantOverridesPersist(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	tenants := []Tenant{{Name: "payments", Token: "payments-token"}}
+	s := newServer(t, "", nil)
+	require.NoError(t, s.SetTenants(tenants))
+	require.NoError(t, s.LoadSchedules(statePath))
+	_, err := s.SetTenantOverrides("payments", TenantOverrides{ExcludeDetectors: "aws"})
+	require.NoError(t, err)
+
+	restored := newServer(t, "", nil)
+	require.NoError(t, restored.SetTenants(tenants))
+	require.NoError(t, restored.LoadSchedules(statePath))
+	assert.Equal(t, []TenantStatus{{Name: "payments", TenantOverrides: TenantOverrides{ExcludeDetectors: "aws"}}}, restored.Tenants())
+}
+
+func TestServer_SetAPIKeys(t *testing.T) {
+	s := newServer(t, "", nil)
+	for name, keys := range map[string][]APIKey{
+		"no name":      {{Key: "a"}},
+		"no key":       {{Name: "a"}},
+		"shared key":   {{Name: "a", Key: "a"}, {Name: "b", Key: "a"}},
+		"unknown role": {{Name: "a", Key: "a", Roles: []string{"owner"}}},
+	} {
+		assert.Error(t, s.SetAPIKeys(keys), name)
+	}
+}
+
+func TestServer_OIDC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	provider := httptest.NewServer(mux)
+	t.Cleanup(provider.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": provider.URL, "jwks_uri": provider.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "key-1",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	s := newServer(t, "", nil)
+	require.Error(t, s.SetOIDC(context.Background(), OIDCConfig{Issuer: provider.URL}))
+	require.NoError(t, s.SetOIDC(context.Background(), OIDCConfig{Issuer: provider.URL, Audience: "trufflehog", TenantClaim: "team"}))
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func(audience string, expires time.Time, roles any) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   provider.URL,
+			"aud":   audience,
+			"sub":   "user-1",
+			"email": "dev@example.com",
+			"exp":   expires.Unix(),
+			"roles": roles,
+			"team":  "payments",
+		}
+	}
+	hour := time.Now().Add(time.Hour)
+
+	var jobs []JobStatus
+	assert.Equal(t, http.StatusOK, request(t, ts, http.MethodGet, "/v1/jobs", sign(claims("trufflehog", hour, []string{"read", "other-app"})), "", &jobs))
+	assert.Equal(t, http.StatusOK, request(t, ts, http.MethodGet, "/v1/jobs", sign(claims("trufflehog", hour, "read submit")), "", &jobs))
+	assert.Equal(t, http.StatusForbidden, request(t, ts, http.MethodGet, "/v1/jobs", sign(claims("trufflehog", hour, nil)), "", nil))
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", sign(claims("other", hour, "read")), "", nil))
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", sign(claims("trufflehog", time.Now().Add(-time.Hour), "read")), "", nil))
+	noExpiry := claims("trufflehog", hour, "read")
+	delete(noExpiry, "exp")
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", sign(noExpiry), "", nil))
+	noTenant := claims("trufflehog", hour, "read")
+	delete(noTenant, "team")
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", sign(noTenant), "", nil))
+	noTenant["team"] = ""
+	assert.Equal(t, http.StatusUnauthorized, request(t, ts, http.MethodGet, "/v1/jobs", sign(noTenant), "", nil))
+
+	// Jobs are submitted for the tenant of the token.
+	var job JobStatus
+	body := `{"source_type": "filesystem", "connection": {"paths": ["` + t.TempDir() + `"]}}`
+	require.Equal(t, http.StatusCreated, request(t, ts, http.MethodPost, "/v1/jobs", sign(claims("trufflehog", hour, "submit")), body, &job))
+	assert.Equal(t, "payments", job.Tenant)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	aCtx "context"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/serverpb"
+)
+
+// progressInterval is how often the progress of a job is streamed while it
+// changes.
+var progressInterval = time.Second
+
+// GRPCServer returns a gRPC server of the Jobs service, which streams the
+// findings and progress of jobs as they are scanned. It authenticates
+// clients like the HTTP API, and requires the read role.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(append(opts, grpc.StreamInterceptor(s.authenticateStream))...)
+	serverpb.RegisterJobsServer(srv, jobsServer{s: s})
+	return srv
+}
+
+func (s *Server) authenticateStream(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	c, err := s.authorize(stream.Context(), token)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !c.has(RoleRead) {
+		return status.Error(codes.PermissionDenied, "the read role is required")
+	}
+	return handler(srv, callerStream{ServerStream: stream, ctx: aCtx.WithValue(stream.Context(), callerKey{}, c)})
+}
+
+// callerStream is a stream whose context carries its caller.
+type callerStream struct {
+	grpc.ServerStream
+	ctx aCtx.Context
+}
+
+func (cs callerStream) Context() aCtx.Context { return cs.ctx }
+
+type jobsServer struct {
+	serverpb.UnimplementedJobsServer
+	s *Server
+}
+
+func (js jobsServer) StreamJob(req *serverpb.StreamJobRequest, stream grpc.ServerStreamingServer[serverpb.JobEvent]) error {
+	job, ok := js.s.Job(req.GetJobId())
+	if !ok || !callerOf(stream.Context()).sees(job.Tenant) {
+		return status.Error(codes.NotFound, "job not found")
+	}
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	// Offsets past the findings a job can ever have would wrap around
+	// converted to an int.
+	offset := math.MaxInt
+	if req.GetOffset() < uint64(math.MaxInt) {
+		offset = int(req.GetOffset())
+	}
+	var progress *serverpb.JobProgress
+	sendProgress := func() error {
+		next := jobProgress(job.Status())
+		if proto.Equal(next, progress) {
+			return nil
+		}
+		progress = next
+		return stream.Send(&serverpb.JobEvent{Event: &serverpb.JobEvent_Progress{Progress: next}})
+	}
+	for {
+		// As for the HTTP stream, whether the job is done is checked before
+		// reading its findings so none found in between are missed.
+		var done bool
+		select {
+		case <-job.Done():
+			done = true
+		default:
+		}
+		findings, changed := job.findingsFrom(offset)
+		for _, f := range findings {
+			if err := stream.Send(&serverpb.JobEvent{Event: &serverpb.JobEvent_Finding{Finding: f.pb}}); err != nil {
+				return err
+			}
+		}
+		offset += len(findings)
+		if done {
+			if err := sendProgress(); err != nil {
+				return err
+			}
+			st := job.Status()
+			return stream.Send(&serverpb.JobEvent{Event: &serverpb.JobEvent_Finished{
+				Finished: &serverpb.JobFinished{State: string(st.State), Error: st.Error},
+			}})
+		}
+		select {
+		case <-changed:
+		case <-ticker.C:
+			if err := sendProgress(); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// jobProgress converts the status of a job into its progress event.
+func jobProgress(st JobStatus) *serverpb.JobProgress {
+	progress := &serverpb.JobProgress{
+		Findings:         uint64(st.Findings),
+		VerifiedFindings: uint64(st.VerifiedFindings),
+	}
+	if p := st.Progress; p != nil {
+		progress.TotalUnits = p.TotalUnits
+		progress.FinishedUnits = p.FinishedUnits
+		progress.TotalChunks = p.TotalChunks
+		progress.SourcePercent = p.SourcePercent
+		progress.SourceMessage = p.SourceMessage
+		progress.Errors = uint64(len(p.Errors))
+		progress.SourceStage = string(p.SourceStage)
+		progress.SourceBytesProcessed = p.SourceBytesProcessed
+		for _, r := range p.SourceRepos {
+			progress.Repos = append(progress.Repos, &serverpb.RepoProgress{
+				Name:            r.Name,
+				Stage:           string(r.Stage),
+				BytesProcessed:  r.BytesProcessed,
+				ChunksProcessed: r.ChunksProcessed,
+				Errors:          r.Errors,
+				Done:            r.Done,
+			})
+		}
+	}
+	return progress
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	aCtx "context"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/serverpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+func newJobsClient(t *testing.T, s *Server) serverpb.JobsClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := s.GRPCServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return serverpb.NewJobsClient(conn)
+}
+
+func TestServer_StreamJob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(fakeDetectorKeyword), 0o644))
+	}
+	s := newServer(t, "token", nil)
+	client := newJobsClient(t, s)
+	job, err := s.Submit(JobRequest{
+		SourceType: "filesystem",
+		Connection: []byte(`{"paths": ["` + dir + `"]}`),
+	})
+	require.NoError(t, err)
+
+	// Errors of server streams are returned by their first Recv.
+	ctx := aCtx.Background()
+	stream, err := client.StreamJob(ctx, &serverpb.StreamJobRequest{JobId: job.ID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token")
+	stream, err = client.StreamJob(ctx, &serverpb.StreamJobRequest{JobId: job.ID})
+	require.NoError(t, err)
+	var (
+		findings int
+		progress *serverpb.JobProgress
+		finished *serverpb.JobFinished
+	)
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Nil(t, finished, "nothing is streamed after the job finished")
+		switch e := event.GetEvent().(type) {
+		case *serverpb.JobEvent_Finding:
+			findings++
+			assert.Equal(t, "fake secret", string(e.Finding.GetRaw()))
+		case *serverpb.JobEvent_Progress:
+			progress = e.Progress
+		case *serverpb.JobEvent_Finished:
+			finished = e.Finished
+		}
+	}
+	assert.Equal(t, 2, findings)
+	require.NotNil(t, progress)
+	assert.Equal(t, uint64(2), progress.GetFindings())
+	assert.NotZero(t, progress.GetTotalChunks())
+	require.NotNil(t, finished)
+	assert.Equal(t, string(JobFinished), finished.GetState())
+
+	// Streams can resume from an offset.
+	stream, err = client.StreamJob(ctx, &serverpb.StreamJobRequest{JobId: job.ID, Offset: 1})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetFinding())
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetProgress())
+
+	// Offsets past the findings only stream the end of the job.
+	stream, err = client.StreamJob(ctx, &serverpb.StreamJobRequest{JobId: job.ID, Offset: math.MaxUint64})
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetProgress())
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, event.GetFinished())
+
+	stream, err = client.StreamJob(ctx, &serverpb.StreamJobRequest{JobId: "missing"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestJobProgress(t *testing.T) {
+	st := JobStatus{Findings: 3, Progress: &JobProgress{JobProgressMetrics: sources.JobProgressMetrics{
+		TotalChunks:          4,
+		SourceStage:          sources.StageScanning,
+		SourceBytesProcessed: 40,
+		SourceRepos: []sources.RepoProgressSnapshot{
+			{Name: "https://example.com/a.git", BytesProcessed: 30, ChunksProcessed: 3, Done: true},
+			{Name: "https://example.com/b.git", Stage: sources.StageScanning, BytesProcessed: 10, ChunksProcessed: 1, Errors: 1},
+		},
+	}}}
+	progress := jobProgress(st)
+	assert.Equal(t, "scanning", progress.GetSourceStage())
+	assert.Equal(t, uint64(40), progress.GetSourceBytesProcessed())
+	require.Len(t, progress.GetRepos(), 2)
+	assert.True(t, progress.GetRepos()[0].GetDone())
+	assert.Equal(t, "scanning", progress.GetRepos()[1].GetStage())
+	assert.Equal(t, uint64(1), progress.GetRepos()[1].GetErrors())
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/distributed"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/distributedpb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// JobState is the lifecycle state of a job.
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobFinished  JobState = "finished"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Job is a scan of a single source submitted to the server. It is a printer
+// that keeps every finding of the scan so clients can stream them.
+type Job struct {
+	ID         string
+	Name       string
+	SourceType sourcespb.SourceType
+	Priority   JobPriority
+	Tenant     string
+	CreatedAt  time.Time
+
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	state    JobState
+	err      error
+	ref      *sources.JobProgressRef
+	findings []jobFinding
+	verified int
+	// violations are the violations of its tenant's policy, once the job
+	// has finished.
+	violations []PolicyViolation
+	// changed is closed and replaced whenever findings are added or the job
+	// finishes, waking up clients streaming its findings.
+	changed chan struct{}
+}
+
+func newJob(id, name string, kind sourcespb.SourceType, cancel context.CancelCauseFunc) *Job {
+	return &Job{
+		ID:         id,
+		Name:       name,
+		SourceType: kind,
+		CreatedAt:  time.Now(),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		state:      JobQueued,
+		changed:    make(chan struct{}),
+	}
+}
+
+// jobFinding is a finding of a job, encoded for the HTTP and gRPC APIs.
+type jobFinding struct {
+	json json.RawMessage
+	pb   *distributedpb.Finding
+}
+
+// Print records a finding of the job.
+func (j *Job) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	out, err := output.MarshalResult(r)
+	if err != nil {
+		return fmt.Errorf("could not marshal result: %w", err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.findings = append(j.findings, jobFinding{json: out, pb: distributed.ToFinding(r)})
+	if r.Verified {
+		j.verified++
+	}
+	j.notify()
+	return nil
+}
+
+// notify wakes up everyone waiting on changed. j.mu must be held.
+func (j *Job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// Cancel stops the job. It doesn't wait for the job to finish.
+func (j *Job) Cancel() { j.cancel(errCancelled) }
+
+// Done is closed once the job has finished.
+func (j *Job) Done() <-chan struct{} { return j.done }
+
+// Findings returns the findings recorded from offset onwards, and a channel
+// that is closed once there are more of them or the job has finished.
+func (j *Job) Findings(offset int) ([]json.RawMessage, <-chan struct{}) {
+	findings, changed := j.findingsFrom(offset)
+	out := make([]json.RawMessage, len(findings))
+	for i, f := range findings {
+		out[i] = f.json
+	}
+	return out, changed
+}
+
+func (j *Job) findingsFrom(offset int) ([]jobFinding, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	offset = min(max(offset, 0), len(j.findings))
+	return j.findings[offset:len(j.findings):len(j.findings)], j.changed
+}
+
+func (j *Job) setRunning() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.state = JobRunning
+}
+
+func (j *Job) track(ref sources.JobProgressRef) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ref = &ref
+}
+
+func (j *Job) setViolations(violations []output.PolicyViolation) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.violations = make([]PolicyViolation, 0, len(violations))
+	for _, v := range violations {
+		j.violations = append(j.violations, PolicyViolation(v))
+	}
+}
+
+func (j *Job) finish(err, cause error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch {
+	case cause == errCancelled:
+		j.state = JobCancelled
+	case err != nil:
+		j.state = JobFailed
+		j.err = err
+	default:
+		j.state = JobFinished
+	}
+	j.notify()
+	close(j.done)
+}
+
+// JobStatus is the JSON representation of a job returned by the API.
+type JobStatus struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	SourceType       string       `json:"source_type"`
+	Priority         JobPriority  `json:"priority"`
+	Tenant           string       `json:"tenant,omitempty"`
+	State            JobState     `json:"state"`
+	Error            string       `json:"error,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	Progress         *JobProgress `json:"progress,omitempty"`
+	Findings         int          `json:"findings"`
+	VerifiedFindings int          `json:"verified_findings"`
+	// PolicyViolations is set once a job of a tenant with a policy has
+	// finished. The job passes the policy when it's empty.
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+}
+
+// PolicyViolation is a finding, or the summary of a job, failing a rule of
+// its tenant's policy.
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Detector string `json:"detector,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// JobProgress is the progress of the job's source. Errors are reported as
+// their messages since they don't encode as JSON.
+type JobProgress struct {
+	sources.JobProgressMetrics
+	Errors []string `json:"errors"`
+}
+
+// Status returns a snapshot of the job.
+func (j *Job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := JobStatus{
+		ID:               j.ID,
+		Name:             j.Name,
+		SourceType:       engine.SourceTypeName(j.SourceType),
+		Priority:         j.Priority,
+		Tenant:           j.Tenant,
+		State:            j.state,
+		CreatedAt:        j.CreatedAt,
+		Findings:         len(j.findings),
+		VerifiedFindings: j.verified,
+		PolicyViolations: j.violations,
+	}
+	if j.err != nil {
+		status.Error = j.err.Error()
+	}
+	if j.ref != nil {
+		metrics := j.ref.Snapshot()
+		progress := &JobProgress{JobProgressMetrics: metrics, Errors: make([]string, 0, len(metrics.Errors))}
+		for _, err := range metrics.Errors {
+			progress.Errors = append(progress.Errors, err.Error())
+		}
+		status.Progress = progress
+	}
+	return status
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	aCtx "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+// OIDCConfig configures the identity provider whose ID or access tokens API
+// clients may authenticate with.
+type OIDCConfig struct {
+	// Issuer is the URL of the provider, which serves its discovery document
+	// under /.well-known/openid-configuration.
+	Issuer string
+	// Audience is the audience tokens must be issued for.
+	Audience string
+	// RolesClaim is the claim listing the roles of the client, either as a
+	// list or separated by spaces. Defaults to "roles".
+	RolesClaim string
+	// TenantClaim is the claim naming the tenant the client is limited to.
+	// Once it's set, tokens without it are rejected, rather than seeing
+	// every tenant.
+	TenantClaim string
+}
+
+// minKeyRefresh limits how often the provider's keys are fetched again when
+// a token is signed with an unknown key.
+var minKeyRefresh = time.Minute
+
+type oidcVerifier struct {
+	cfg     OIDCConfig
+	client  *http.Client
+	jwksURL string
+
+	mu      sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+}
+
+// SetOIDC lets API clients authenticate with tokens of the OIDC provider of
+// cfg, whose discovery document is fetched right away.
+func (s *Server) SetOIDC(ctx aCtx.Context, cfg OIDCConfig) error {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return errors.New("OIDC needs an issuer and an audience")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	v := &oidcVerifier{cfg: cfg, client: common.RetryableHTTPClient()}
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.get(ctx, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return fmt.Errorf("could not discover OIDC provider: %w", err)
+	}
+	if discovery.Issuer != cfg.Issuer || discovery.JWKSURI == "" {
+		return fmt.Errorf("OIDC provider %s has an invalid discovery document", cfg.Issuer)
+	}
+	v.jwksURL = discovery.JWKSURI
+	if err := v.refresh(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.oidc = v
+	return nil
+}
+
+func (v *oidcVerifier) get(ctx aCtx.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// refresh fetches the signing keys of the provider.
+func (v *oidcVerifier) refresh(ctx aCtx.Context) error {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := v.get(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("could not fetch OIDC keys: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, errN := decodeBigInt(k.N)
+			e, errE := decodeBigInt(k.E)
+			if errN != nil || errE != nil || !e.IsInt64() {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := decodeBigInt(k.X)
+			y, errY := decodeBigInt(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys, v.fetched = keys, time.Now()
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// key returns the signing key with the given ID, fetching the provider's
+// keys again if it doesn't know it, in case they were rotated.
+func (v *oidcVerifier) key(ctx aCtx.Context, kid string) (any, error) {
+	v.mu.Lock()
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetched) >= minKeyRefresh
+	v.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if stale {
+		if err := v.refresh(ctx); err != nil {
+			return nil, err
+		}
+		v.mu.Lock()
+		key, ok = v.keys[kid]
+		v.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (v *oidcVerifier) verify(ctx aCtx.Context, raw string) (caller, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return caller{}, fmt.Errorf("invalid token: %w", err)
+	}
+	switch {
+	case !claims.VerifyIssuer(v.cfg.Issuer, true):
+		return caller{}, errors.New("invalid token issuer")
+	case !claims.VerifyAudience(v.cfg.Audience, true):
+		return caller{}, errors.New("invalid token audience")
+	case !claims.VerifyExpiresAt(time.Now().Unix(), true):
+		return caller{}, errors.New("token has no expiry")
+	}
+
+	var names []string
+	switch roles := claims[v.cfg.RolesClaim].(type) {
+	case string:
+		names = strings.Fields(roles)
+	case []any:
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	roles := make(map[Role]bool, len(names))
+	for _, name := range names {
+		// Providers list roles of other applications too.
+		if role, err := parseRole(name); err == nil {
+			roles[role] = true
+		}
+	}
+	c := caller{roles: roles}
+	if v.cfg.TenantClaim != "" {
+		c.tenant, _ = claims[v.cfg.TenantClaim].(string)
+		// Callers without a tenant see every tenant.
+		if c.tenant == "" {
+			return caller{}, fmt.Errorf("token has no %s claim", v.cfg.TenantClaim)
+		}
+	}
+	subject, _ := claims["sub"].(string)
+	if email, ok := claims["email"].(string); ok && email != "" {
+		subject = email
+	}
+	c.name = "oidc:" + subject
+	return c, nil
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// JobPriority is how urgently a job should run. Queued jobs of a higher
+// priority start before those of a lower one, such as pull request gates
+// before nightly full scans.
+type JobPriority string
+
+const (
+	PriorityHigh   JobPriority = "high"
+	PriorityNormal JobPriority = "normal"
+	PriorityLow    JobPriority = "low"
+)
+
+// rank orders priorities, higher first. It returns false for unknown ones.
+func (p JobPriority) rank() (int, bool) {
+	switch p {
+	case PriorityHigh:
+		return 2, true
+	case PriorityNormal, "":
+		return 1, true
+	case PriorityLow:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func parsePriority(p JobPriority) (JobPriority, error) {
+	if _, ok := p.rank(); !ok {
+		return "", fmt.Errorf("unknown priority %q, must be one of high, normal, or low", p)
+	}
+	if p == "" {
+		return PriorityNormal, nil
+	}
+	return p, nil
+}
+
+// jobQueue decides which queued jobs run. At most maxJobs jobs run at once,
+// and at most the quota of their tenant for each tenant. When a slot frees
+// up, the queued job of the highest priority starts; among those, the job of
+// the tenant with the fewest running jobs, then of the source with the fewest
+// running jobs, so no tenant or source starves the others, and then the job
+// submitted first.
+type jobQueue struct {
+	mu      sync.Mutex
+	maxJobs int
+	// tenantMax is the quota of tenants without one in quotas. 0 means no
+	// quota.
+	tenantMax int
+	quotas    map[string]int
+
+	running       int
+	tenantRunning map[string]int
+	sourceRunning map[string]int
+	waiting       []*queuedJob
+}
+
+type queuedJob struct {
+	job   *Job
+	rank  int
+	ready chan struct{}
+}
+
+func newJobQueue(maxJobs int) *jobQueue {
+	return &jobQueue{
+		maxJobs:       maxJobs,
+		tenantRunning: make(map[string]int),
+		sourceRunning: make(map[string]int),
+	}
+}
+
+// setQuotas sets how many jobs of a tenant may run at once.
+func (q *jobQueue) setQuotas(tenantMax int, quotas map[string]int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tenantMax = tenantMax
+	q.quotas = quotas
+	q.dispatch()
+}
+
+// acquire blocks until job may run or ctx is cancelled. Every successful call
+// must be followed by a call to release.
+func (q *jobQueue) acquire(ctx context.Context, job *Job) error {
+	rank, _ := job.Priority.rank()
+	entry := &queuedJob{job: job, rank: rank, ready: make(chan struct{})}
+	q.mu.Lock()
+	q.waiting = append(q.waiting, entry)
+	q.dispatch()
+	q.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-entry.ready:
+		// The job was started just as it was cancelled.
+		q.releaseLocked(job)
+	default:
+		for i, w := range q.waiting {
+			if w == entry {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// release frees the slot of a job that finished running.
+func (q *jobQueue) release(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked(job)
+}
+
+func (q *jobQueue) releaseLocked(job *Job) {
+	q.running--
+	if q.tenantRunning[job.Tenant]--; q.tenantRunning[job.Tenant] == 0 {
+		delete(q.tenantRunning, job.Tenant)
+	}
+	if q.sourceRunning[job.Name]--; q.sourceRunning[job.Name] == 0 {
+		delete(q.sourceRunning, job.Name)
+	}
+	q.dispatch()
+}
+
+// dispatch starts queued jobs while there are free slots. q.mu must be held.
+func (q *jobQueue) dispatch() {
+	for q.running < q.maxJobs {
+		next := -1
+		for i, w := range q.waiting {
+			if q.atQuota(w.job.Tenant) {
+				continue
+			}
+			if next < 0 || q.before(w, q.waiting[next]) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return
+		}
+		w := q.waiting[next]
+		q.waiting = append(q.waiting[:next], q.waiting[next+1:]...)
+		q.running++
+		q.tenantRunning[w.job.Tenant]++
+		q.sourceRunning[w.job.Name]++
+		close(w.ready)
+	}
+}
+
+// before reports whether a should start before b. Jobs that are tied start
+// in the order they were queued in.
+func (q *jobQueue) before(a, b *queuedJob) bool {
+	if a.rank != b.rank {
+		return a.rank > b.rank
+	}
+	if ta, tb := q.tenantRunning[a.job.Tenant], q.tenantRunning[b.job.Tenant]; ta != tb {
+		return ta < tb
+	}
+	return q.sourceRunning[a.job.Name] < q.sourceRunning[b.job.Name]
+}
+
+// atQuota reports whether tenant is running as many jobs as it may. q.mu
+// must be held.
+func (q *jobQueue) atQuota(tenant string) bool {
+	quota, ok := q.quotas[tenant]
+	if !ok {
+		quota = q.tenantMax
+	}
+	return quota > 0 && q.tenantRunning[tenant] >= quota
+}
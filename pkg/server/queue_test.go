@@ -0,0 +1,117 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func queueJob(name, tenant string, priority JobPriority) *Job {
+	job := newJob(name, name, 0, func(error) {})
+	job.Tenant = tenant
+	job.Priority = priority
+	return job
+}
+
+// startOrder queues jobs one after the other behind a running job, then
+// releases every job once it starts and returns the names of the jobs in the
+// order they started.
+func startOrder(t *testing.T, q *jobQueue, jobs ...*Job) []string {
+	t.Helper()
+	ctx := context.Background()
+	blocker := queueJob("blocker", "", PriorityHigh)
+	require.NoError(t, q.acquire(ctx, blocker))
+
+	started := make(chan *Job, len(jobs))
+	for i, job := range jobs {
+		go func() {
+			if err := q.acquire(ctx, job); err == nil {
+				started <- job
+			}
+		}()
+		require.Eventually(t, func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return len(q.waiting) == i+1
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	q.release(blocker)
+	var order []string
+	for range jobs {
+		job := <-started
+		order = append(order, job.Name)
+		q.release(job)
+	}
+	return order
+}
+
+func TestJobQueue_Priority(t *testing.T) {
+	q := newJobQueue(1)
+	order := startOrder(t, q,
+		queueJob("nightly", "", PriorityLow),
+		queueJob("push", "", PriorityNormal),
+		queueJob("pr", "", PriorityHigh),
+		queueJob("default", "", ""),
+	)
+	assert.Equal(t, []string{"pr", "push", "default", "nightly"}, order)
+}
+
+func TestJobQueue_TenantQuotas(t *testing.T) {
+	ctx := context.Background()
+	q := newJobQueue(3)
+	q.setQuotas(1, map[string]int{"big": 2})
+
+	a1, a2 := queueJob("a1", "a", PriorityNormal), queueJob("a2", "a", PriorityNormal)
+	require.NoError(t, q.acquire(ctx, a1))
+	acquired := make(chan struct{})
+	go func() {
+		if q.acquire(ctx, a2) == nil {
+			close(acquired)
+		}
+	}()
+	// Tenant a is at its quota, but other tenants can still run jobs.
+	require.NoError(t, q.acquire(ctx, queueJob("big1", "big", PriorityNormal)))
+	require.NoError(t, q.acquire(ctx, queueJob("big2", "big", PriorityNormal)))
+	select {
+	case <-acquired:
+		t.Fatal("a job over its tenant's quota should be queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.release(a1)
+	<-acquired
+
+	// A cancelled job gives up its place in the queue.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, q.acquire(cancelCtx, queueJob("a3", "a", PriorityNormal)))
+	q.mu.Lock()
+	assert.Empty(t, q.waiting)
+	q.mu.Unlock()
+}
+
+func TestJobQueue_Fairness(t *testing.T) {
+	q := newJobQueue(2)
+	ctx := context.Background()
+	// Tenant a already has a job running, so tenant b goes first even though
+	// a queued before it.
+	running := queueJob("a-running", "a", PriorityNormal)
+	require.NoError(t, q.acquire(ctx, running))
+	order := startOrder(t, q,
+		queueJob("a", "a", PriorityNormal),
+		queueJob("b", "b", PriorityNormal),
+	)
+	assert.Equal(t, []string{"b", "a"}, order)
+	q.release(running)
+}
+
+func TestServer_InvalidPriority(t *testing.T) {
+	s := newServer(t, "", nil)
+	_, err := s.Submit(JobRequest{SourceType: "filesystem", Connection: []byte(`{"paths": ["."]}`), Priority: "urgent"})
+	assert.ErrorContains(t, err, "unknown priority")
+}
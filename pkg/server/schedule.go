@@ -0,0 +1,599 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+)
+
+// RunSkipped is the state of a scheduled run that didn't start because the
+// previous run of the schedule was still in progress.
+const RunSkipped JobState = "skipped"
+
+// maxScheduleHistory is the number of runs remembered per schedule.
+const maxScheduleHistory = 50
+
+var (
+	errSourceNotFound = errors.New("source not found")
+	errSourceInUse    = errors.New("source is used by a schedule")
+)
+
+// StoredSource is a source configuration saved on the server so schedules can
+// scan it.
+type StoredSource struct {
+	ID string `json:"id"`
+	JobRequest
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ScheduleRequest is the payload creating a schedule.
+type ScheduleRequest struct {
+	// SourceID is the ID of the stored source to scan.
+	SourceID string `json:"source_id"`
+	// Cron is a five-field cron expression, or a macro such as @daily,
+	// evaluated in the server's time zone.
+	Cron string `json:"cron"`
+}
+
+// ScheduleRun is a run of a schedule.
+type ScheduleRun struct {
+	JobID      string     `json:"job_id,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	State      JobState   `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	Findings   int        `json:"findings"`
+	// NewFindings is the number of findings the previous successful run
+	// didn't have. Only these are sent to the server's outputs.
+	NewFindings int `json:"new_findings"`
+}
+
+// Schedule periodically scans a stored source.
+type Schedule struct {
+	ID       string
+	SourceID string
+	// Tenant is the tenant of the stored source.
+	Tenant    string
+	Cron      string
+	CreatedAt time.Time
+
+	cron cron.Schedule
+	stop chan struct{}
+
+	mu      sync.Mutex
+	running bool
+	history []*ScheduleRun
+	// known holds the fingerprints of the findings of the last successful
+	// run.
+	known map[string]struct{}
+}
+
+// ScheduleStatus is the JSON representation of a schedule returned by the
+// API.
+type ScheduleStatus struct {
+	ID        string        `json:"id"`
+	SourceID  string        `json:"source_id"`
+	Tenant    string        `json:"tenant,omitempty"`
+	Cron      string        `json:"cron"`
+	CreatedAt time.Time     `json:"created_at"`
+	NextRun   *time.Time    `json:"next_run,omitempty"`
+	Running   bool          `json:"running"`
+	History   []ScheduleRun `json:"history"`
+}
+
+// Status returns a snapshot of the schedule.
+func (sc *Schedule) Status() ScheduleStatus {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	status := ScheduleStatus{
+		ID:        sc.ID,
+		SourceID:  sc.SourceID,
+		Tenant:    sc.Tenant,
+		Cron:      sc.Cron,
+		CreatedAt: sc.CreatedAt,
+		Running:   sc.running,
+		History:   sc.historyCopy(),
+	}
+	if next := sc.cron.Next(time.Now()); !next.IsZero() {
+		status.NextRun = &next
+	}
+	return status
+}
+
+// record appends a run to the history, forgetting the oldest runs. sc.mu must
+// be held.
+func (sc *Schedule) record(run *ScheduleRun) *ScheduleRun {
+	if len(sc.history) >= maxScheduleHistory {
+		sc.history = sc.history[len(sc.history)-maxScheduleHistory+1:]
+	}
+	sc.history = append(sc.history, run)
+	return run
+}
+
+// historyCopy returns a copy of the history. sc.mu must be held.
+func (sc *Schedule) historyCopy() []ScheduleRun {
+	history := make([]ScheduleRun, 0, len(sc.history))
+	for _, run := range sc.history {
+		history = append(history, *run)
+	}
+	return history
+}
+
+// AddSource validates and stores a source configuration.
+func (s *Server) AddSource(req JobRequest) (*StoredSource, error) {
+	if _, _, _, err := req.parse(); err != nil {
+		return nil, err
+	}
+	source := &StoredSource{ID: uuid.NewString(), JobRequest: req, CreatedAt: time.Now()}
+	s.mu.Lock()
+	s.sources[source.ID] = source
+	s.mu.Unlock()
+	s.persist()
+	return source, nil
+}
+
+// Source returns the stored source with the given ID.
+func (s *Server) Source(id string) (*StoredSource, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.sources[id]
+	return source, ok
+}
+
+// Sources returns every stored source.
+func (s *Server) Sources() []*StoredSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sources := make([]*StoredSource, 0, len(s.sources))
+	for _, source := range s.sources {
+		sources = append(sources, source)
+	}
+	sortByCreation(sources, func(source *StoredSource) time.Time { return source.CreatedAt })
+	return sources
+}
+
+// DeleteSource deletes a stored source that no schedule uses.
+func (s *Server) DeleteSource(id string) error {
+	s.mu.Lock()
+	if _, ok := s.sources[id]; !ok {
+		s.mu.Unlock()
+		return errSourceNotFound
+	}
+	for _, sc := range s.schedules {
+		if sc.SourceID == id {
+			s.mu.Unlock()
+			return errSourceInUse
+		}
+	}
+	delete(s.sources, id)
+	s.mu.Unlock()
+	s.persist()
+	return nil
+}
+
+// AddSchedule creates and starts a schedule.
+func (s *Server) AddSchedule(req ScheduleRequest) (*Schedule, error) {
+	schedule, err := cron.ParseStandard(req.Cron)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := s.Source(req.SourceID)
+	if !ok {
+		return nil, errSourceNotFound
+	}
+	sc := &Schedule{
+		ID:        uuid.NewString(),
+		SourceID:  req.SourceID,
+		Tenant:    source.Tenant,
+		Cron:      req.Cron,
+		CreatedAt: time.Now(),
+		cron:      schedule,
+		stop:      make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.schedules[sc.ID] = sc
+	s.mu.Unlock()
+	s.startSchedule(sc)
+	s.persist()
+	return sc, nil
+}
+
+// Schedule returns the schedule with the given ID.
+func (s *Server) Schedule(id string) (*Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[id]
+	return sc, ok
+}
+
+// Schedules returns every schedule.
+func (s *Server) Schedules() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		schedules = append(schedules, sc)
+	}
+	sortByCreation(schedules, func(sc *Schedule) time.Time { return sc.CreatedAt })
+	return schedules
+}
+
+// DeleteSchedule stops and deletes a schedule. A run in progress isn't
+// cancelled.
+func (s *Server) DeleteSchedule(id string) bool {
+	s.mu.Lock()
+	sc, ok := s.schedules[id]
+	delete(s.schedules, id)
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	close(sc.stop)
+	s.persist()
+	return true
+}
+
+func (s *Server) startSchedule(sc *Schedule) {
+	go func() {
+		for {
+			next := sc.cron.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-sc.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.RunSchedule(sc)
+			}
+		}
+	}()
+}
+
+// RunSchedule starts a run of the schedule, unless the previous one is still
+// in progress. Findings that the previous successful run didn't have are sent
+// to the server's outputs once the run finishes.
+func (s *Server) RunSchedule(sc *Schedule) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	ctx := context.WithValues(s.ctx, "schedule_id", sc.ID)
+	sc.mu.Lock()
+	if sc.running {
+		now := time.Now()
+		sc.record(&ScheduleRun{StartedAt: now, FinishedAt: &now, State: RunSkipped})
+		sc.mu.Unlock()
+		ctx.Logger().V(2).Info("skipping scheduled run, the previous one is still running")
+		return
+	}
+	sc.running = true
+	run := sc.record(&ScheduleRun{StartedAt: time.Now(), State: JobQueued})
+	diff := &newFindings{known: sc.known, seen: make(map[string]struct{})}
+	sc.mu.Unlock()
+
+	// finish records the outcome of the run. The run may have been pushed out
+	// of the history by skipped runs by then, in which case only the
+	// schedule is updated.
+	finish := func(update func()) {
+		sc.mu.Lock()
+		now := time.Now()
+		run.FinishedAt = &now
+		update()
+		sc.running = false
+		sc.mu.Unlock()
+		s.persist()
+	}
+
+	job, err := s.startScheduledJob(sc, diff)
+	if err != nil {
+		ctx.Logger().Error(err, "could not start scheduled run")
+		finish(func() {
+			run.State = JobFailed
+			run.Error = err.Error()
+		})
+		return
+	}
+	sc.mu.Lock()
+	run.JobID = job.ID
+	sc.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-job.Done()
+		status := job.Status()
+		if status.State == JobFinished {
+			diff.notify(ctx, s.cfg.Dispatcher)
+		}
+		finish(func() {
+			run.State = status.State
+			run.Error = status.Error
+			run.Findings = status.Findings
+			if status.State == JobFinished {
+				run.NewFindings = len(diff.results)
+				sc.known = diff.seen
+			}
+		})
+	}()
+}
+
+func (s *Server) startScheduledJob(sc *Schedule, diff *newFindings) (*Job, error) {
+	source, ok := s.Source(sc.SourceID)
+	if !ok {
+		return nil, errSourceNotFound
+	}
+	return s.submit(source.JobRequest, diff)
+}
+
+// newFindings is a printer that collects the findings of a scheduled run
+// that the previous run didn't have.
+type newFindings struct {
+	known map[string]struct{}
+
+	mu      sync.Mutex
+	seen    map[string]struct{}
+	results []detectors.ResultWithMetadata
+}
+
+func (p *newFindings) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = output.Fingerprint(r)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.seen[fingerprint]; ok {
+		return nil
+	}
+	p.seen[fingerprint] = struct{}{}
+	if _, ok := p.known[fingerprint]; !ok {
+		result := *r
+		result.Data = nil
+		p.results = append(p.results, result)
+	}
+	return nil
+}
+
+// notify sends the new findings to dispatcher.
+func (p *newFindings) notify(ctx context.Context, dispatcher engine.ResultsDispatcher) {
+	if dispatcher == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range p.results {
+		if err := dispatcher.Dispatch(ctx, r); err != nil {
+			ctx.Logger().Error(err, "could not send new finding")
+		}
+	}
+	if f, ok := dispatcher.(engine.Flusher); ok {
+		if err := f.Flush(ctx); err != nil {
+			ctx.Logger().Error(err, "could not flush new findings")
+		}
+	}
+}
+
+// serverState is the file format that stored sources, schedules, and the
+// tenant overrides set through the API are persisted in.
+type serverState struct {
+	Sources   []sourceRecord   `json:"sources"`
+	Schedules []scheduleRecord `json:"schedules"`
+	// TenantOverrides take precedence over those of the tenants file.
+	TenantOverrides map[string]TenantOverrides `json:"tenant_overrides,omitempty"`
+}
+
+type sourceRecord struct {
+	StoredSource
+	// EncryptedConnection replaces the connection of the source when the
+	// state is encrypted.
+	EncryptedConnection []byte `json:"encrypted_connection,omitempty"`
+}
+
+type scheduleRecord struct {
+	ID            string        `json:"id"`
+	SourceID      string        `json:"source_id"`
+	Tenant        string        `json:"tenant,omitempty"`
+	Cron          string        `json:"cron"`
+	CreatedAt     time.Time     `json:"created_at"`
+	History       []ScheduleRun `json:"history"`
+	KnownFindings []string      `json:"known_findings"`
+}
+
+// LoadSchedules loads the stored sources and schedules saved in path, if it
+// exists, starts the schedules, and saves every later change to them there.
+func (s *Server) LoadSchedules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var state serverState
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("could not parse %s: %w", path, err)
+		}
+	}
+
+	var schedules []*Schedule
+	for _, record := range state.Schedules {
+		schedule, err := cron.ParseStandard(record.Cron)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", record.ID, err)
+		}
+		sc := &Schedule{
+			ID:        record.ID,
+			SourceID:  record.SourceID,
+			Tenant:    record.Tenant,
+			Cron:      record.Cron,
+			CreatedAt: record.CreatedAt,
+			cron:      schedule,
+			stop:      make(chan struct{}),
+			known:     make(map[string]struct{}, len(record.KnownFindings)),
+		}
+		for _, fingerprint := range record.KnownFindings {
+			sc.known[fingerprint] = struct{}{}
+		}
+		for _, run := range record.History {
+			// Runs interrupted by a restart never finished.
+			if run.FinishedAt == nil {
+				run.State = JobCancelled
+			}
+			sc.history = append(sc.history, &run)
+		}
+		schedules = append(schedules, sc)
+	}
+	sources := make([]*StoredSource, 0, len(state.Sources))
+	for _, record := range state.Sources {
+		source := record.StoredSource
+		if record.EncryptedConnection != nil {
+			if source.Connection, err = s.open(&source, record.EncryptedConnection); err != nil {
+				return err
+			}
+		}
+		sources = append(sources, &source)
+	}
+
+	s.mu.Lock()
+	overridden := make(map[string]*Tenant, len(state.TenantOverrides))
+	for name, overrides := range state.TenantOverrides {
+		t, ok := s.tenants[name]
+		if !ok {
+			continue
+		}
+		updated := *t
+		if err := updated.override(overrides); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("tenant %s: %w", name, err)
+		}
+		overridden[name] = &updated
+	}
+	for name, t := range overridden {
+		s.tenants[name] = t
+	}
+	for name, overrides := range state.TenantOverrides {
+		s.overrides[name] = overrides
+	}
+	s.statePath = path
+	for _, source := range sources {
+		s.sources[source.ID] = source
+	}
+	for _, sc := range schedules {
+		s.schedules[sc.ID] = sc
+	}
+	s.mu.Unlock()
+	for _, sc := range schedules {
+		s.startSchedule(sc)
+	}
+	return nil
+}
+
+// persist saves the stored sources and schedules, logging any error. Changes
+// are kept in memory even if they can't be saved.
+func (s *Server) persist() {
+	if err := s.save(); err != nil {
+		s.ctx.Logger().Error(err, "could not save schedules")
+	}
+}
+
+// save persists the stored sources and schedules if LoadSchedules was called.
+func (s *Server) save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	path := s.statePath
+	sources := make([]*StoredSource, 0, len(s.sources))
+	for _, source := range s.sources {
+		sources = append(sources, source)
+	}
+	schedules := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		schedules = append(schedules, sc)
+	}
+	var overrides map[string]TenantOverrides
+	if len(s.overrides) > 0 {
+		overrides = make(map[string]TenantOverrides, len(s.overrides))
+		for name, o := range s.overrides {
+			overrides[name] = o
+		}
+	}
+	s.mu.Unlock()
+	if path == "" {
+		return nil
+	}
+
+	sortByCreation(sources, func(source *StoredSource) time.Time { return source.CreatedAt })
+	state := serverState{Sources: make([]sourceRecord, 0, len(sources)), TenantOverrides: overrides}
+	for _, source := range sources {
+		record := sourceRecord{StoredSource: *source}
+		sealed, err := s.seal(source)
+		if err != nil {
+			return fmt.Errorf("could not encrypt source %s: %w", source.ID, err)
+		}
+		if sealed != nil {
+			record.Connection, record.EncryptedConnection = nil, sealed
+		}
+		state.Sources = append(state.Sources, record)
+	}
+	sortByCreation(schedules, func(sc *Schedule) time.Time { return sc.CreatedAt })
+	for _, sc := range schedules {
+		sc.mu.Lock()
+		record := scheduleRecord{
+			ID:            sc.ID,
+			SourceID:      sc.SourceID,
+			Tenant:        sc.Tenant,
+			Cron:          sc.Cron,
+			CreatedAt:     sc.CreatedAt,
+			History:       sc.historyCopy(),
+			KnownFindings: make([]string, 0, len(sc.known)),
+		}
+		for fingerprint := range sc.known {
+			record.KnownFindings = append(record.KnownFindings, fingerprint)
+		}
+		sc.mu.Unlock()
+		sort.Strings(record.KnownFindings)
+		state.Schedules = append(state.Schedules, record)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a truncated
+	// file behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// sortByCreation sorts items by the time they were created, oldest first.
+func sortByCreation[T any](items []T, createdAt func(T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool { return createdAt(items[i]).Before(createdAt(items[j])) })
+}
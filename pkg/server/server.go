@@ -0,0 +1,246 @@
+// Package server runs scans submitted to an HTTP job API. Every job scans a
+// single source with its own engine, configured like the server's, and keeps
+// its findings in memory so clients can stream them while it runs.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+var (
+	errCancelled      = errors.New("job cancelled")
+	errTenantNotFound = errors.New("tenant not found")
+)
+
+// JobRequest is the payload submitting a job.
+type JobRequest struct {
+	// Name of the source. Defaults to "trufflehog - <source type>".
+	Name string `json:"name"`
+	// SourceType is the type of the source, such as "git" or "s3".
+	SourceType string `json:"source_type"`
+	// Connection configures the source. It's the JSON encoding of the
+	// source's connection message in sources.proto, such as
+	// {"uri": "https://github.com/trufflesecurity/test_keys"} for git.
+	Connection json.RawMessage `json:"connection"`
+	// Priority is one of high, normal, or low. Defaults to normal.
+	Priority JobPriority `json:"priority,omitempty"`
+	// Tenant is who the job is run for, which limits how many of its jobs
+	// run at once if the server has tenant quotas. Requests authenticated as
+	// a tenant always run their jobs for it.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Server runs scan jobs.
+type Server struct {
+	ctx   context.Context
+	cfg   engine.Config
+	token string
+	queue *jobQueue
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	// order lists job IDs by submission time.
+	order     []string
+	sources   map[string]*StoredSource
+	schedules map[string]*Schedule
+	tenants   map[string]*Tenant
+	// overrides are the tenant overrides set through the API, which are
+	// saved with the state and outlive the tenants file.
+	overrides map[string]TenantOverrides
+	apiKeys   map[string]caller
+	oidc      *oidcVerifier
+	// statePath is where sources and schedules are saved, if anywhere.
+	statePath string
+	stateKey  []byte
+	saveMu    sync.Mutex
+	wg        sync.WaitGroup
+
+	auditMu  sync.Mutex
+	auditLog io.Writer
+}
+
+// New creates a Server that scans with the engine configuration cfg, running
+// at most maxJobs jobs at once. Further jobs are queued by priority. Every job gets its own SourceManager, and
+// cfg.Dispatcher only receives the new findings of scheduled runs. Jobs and
+// schedules are stopped when ctx is cancelled. If token is set, requests must
+// provide it as a bearer token.
+func New(ctx context.Context, cfg engine.Config, token string, maxJobs int) *Server {
+	if maxJobs < 1 {
+		maxJobs = 1
+	}
+	return &Server{
+		ctx:       ctx,
+		cfg:       cfg,
+		token:     token,
+		queue:     newJobQueue(maxJobs),
+		jobs:      make(map[string]*Job),
+		sources:   make(map[string]*StoredSource),
+		schedules: make(map[string]*Schedule),
+		overrides: make(map[string]TenantOverrides),
+	}
+}
+
+// SetTenantQuotas limits how many jobs of a tenant run at once: quotas maps
+// tenants to their limit, and other tenants, including jobs without one, get
+// tenantMax. A limit of 0 means no limit.
+func (s *Server) SetTenantQuotas(tenantMax int, quotas map[string]int) {
+	s.queue.setQuotas(tenantMax, quotas)
+}
+
+// parse validates the request and decodes its connection.
+func (req JobRequest) parse() (name string, kind sourcespb.SourceType, connection proto.Message, err error) {
+	kind, ok := engine.ParseSourceType(req.SourceType)
+	if !ok {
+		return "", 0, nil, fmt.Errorf("unknown source type %q", req.SourceType)
+	}
+	// Plugin sources run executables on the server, so they can only be
+	// scanned from the command line.
+	if kind == sourcespb.SourceType_SOURCE_TYPE_PLUGIN {
+		return "", 0, nil, errors.New("plugin sources can't be scanned by the server")
+	}
+	if _, err := parsePriority(req.Priority); err != nil {
+		return "", 0, nil, err
+	}
+	connection, err = engine.ParseConnection(kind, req.Connection)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	name = req.Name
+	if name == "" {
+		name = "trufflehog - " + engine.SourceTypeName(kind)
+	}
+	return name, kind, connection, nil
+}
+
+// Submit validates req and queues a job for it.
+func (s *Server) Submit(req JobRequest) (*Job, error) {
+	return s.submit(req)
+}
+
+// submit queues a job for req whose findings are also printed to printers.
+func (s *Server) submit(req JobRequest, printers ...engine.Printer) (*Job, error) {
+	name, kind, connection, err := req.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	ctx, cancel := context.WithCancelCause(context.WithValues(s.ctx, "job_id", id))
+	job := newJob(id, name, kind, cancel)
+	job.Priority, _ = parsePriority(req.Priority)
+	job.Tenant = req.Tenant
+
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.order = append(s.order, id)
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel(nil)
+		err := s.run(ctx, job, connection, printers)
+		if err != nil {
+			ctx.Logger().Error(err, "job failed")
+		}
+		job.finish(err, context.Cause(ctx))
+	}()
+	return job, nil
+}
+
+func (s *Server) run(ctx context.Context, job *Job, connection proto.Message, printers []engine.Printer) error {
+	if err := s.queue.acquire(ctx, job); err != nil {
+		return err
+	}
+	defer s.queue.release(job)
+	job.setRunning()
+
+	cfg := s.cfg
+	cfg.SourceManager = sources.NewManager(
+		sources.WithConcurrentSources(1),
+		sources.WithConcurrentUnits(cfg.Concurrency),
+		sources.WithSourceUnits(),
+	)
+	dispatchers := engine.MultiDispatcher{engine.NewPrinterDispatcher(job)}
+	for _, printer := range printers {
+		dispatchers = append(dispatchers, engine.NewPrinterDispatcher(printer))
+	}
+	var policyPrinter *output.PolicyPrinter
+	if tenant, ok := s.tenant(job.Tenant); ok {
+		if tenant.IncludeDetectors != "" {
+			cfg.IncludeDetectors = tenant.IncludeDetectors
+		}
+		if tenant.ExcludeDetectors != "" {
+			cfg.ExcludeDetectors = strings.TrimPrefix(cfg.ExcludeDetectors+","+tenant.ExcludeDetectors, ",")
+		}
+		if tenant.policy != nil {
+			policyPrinter = output.NewPolicyPrinter(tenant.policy)
+			dispatchers = append(dispatchers, engine.NewPrinterDispatcher(policyPrinter))
+		}
+	}
+	cfg.Dispatcher = dispatchers
+	eng, err := engine.NewEngine(ctx, &cfg)
+	if err != nil {
+		return fmt.Errorf("error initializing engine: %w", err)
+	}
+	eng.Start(ctx)
+
+	ref, err := eng.ScanConnection(ctx, job.Name, connection)
+	if err == nil {
+		job.track(ref)
+	}
+	// The engine must be finished even if the source couldn't be started.
+	if finishErr := eng.Finish(ctx); err == nil {
+		err = finishErr
+	}
+	if policyPrinter != nil && err == nil {
+		metrics := ref.Snapshot()
+		violations, evalErr := policyPrinter.Evaluate(output.ScanStats{
+			ChunksScanned: metrics.TotalChunks,
+			BytesScanned:  eng.GetMetrics().BytesScanned,
+			Duration:      metrics.ElapsedTime(),
+			Errors:        uint64(len(metrics.Errors)),
+		})
+		if evalErr != nil {
+			ctx.Logger().Error(evalErr, "could not evaluate every policy rule")
+		}
+		job.setViolations(violations)
+	}
+	return err
+}
+
+// Job returns the job with the given ID.
+func (s *Server) Job(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// Jobs returns every job in the order they were submitted.
+func (s *Server) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*Job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id])
+	}
+	return jobs
+}
+
+// Wait blocks until every job has finished.
+func (s *Server) Wait() { s.wg.Wait() }
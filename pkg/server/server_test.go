@@ -0,0 +1,284 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	aCtx "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const fakeDetectorKeyword = "fakedetectorkeyword"
+
+type fakeDetector struct{}
+
+func (fakeDetector) FromData(_ aCtx.Context, _ bool, _ []byte) ([]detectors.Result, error) {
+	return []detectors.Result{{DetectorType: detectorspb.DetectorType(-1), Raw: []byte("fake secret")}}, nil
+}
+func (fakeDetector) Keywords() []string             { return []string{fakeDetectorKeyword} }
+func (fakeDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType(-1) }
+func (fakeDetector) Description() string            { return "" }
+
+// recordingPrinter records the raw secret of every result it prints.
+type recordingPrinter struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (p *recordingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results = append(p.results, string(r.Raw))
+	return nil
+}
+
+func (p *recordingPrinter) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.results)
+}
+
+func newServer(t *testing.T, token string, printer engine.Printer) *Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := engine.Config{
+		Concurrency: 1,
+		Decoders:    decoders.DefaultDecoders(),
+		Detectors:   []detectors.Detector{fakeDetector{}},
+	}
+	if printer != nil {
+		cfg.Dispatcher = engine.NewPrinterDispatcher(printer)
+	}
+	s := New(ctx, cfg, token, 1)
+	t.Cleanup(func() {
+		cancel()
+		s.Wait()
+	})
+	return s
+}
+
+func newTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(newServer(t, token, nil).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func submit(t *testing.T, ts *httptest.Server, body string) (*http.Response, JobStatus) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/v1/jobs", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var status JobStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	return resp, status
+}
+
+func TestServer_ScanJob(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "creds.txt"), []byte(fakeDetectorKeyword), 0o644))
+	ts := newTestServer(t, "")
+
+	payload, err := json.Marshal(map[string]any{
+		"source_type": "filesystem",
+		"connection":  map[string]any{"paths": []string{dir}},
+	})
+	require.NoError(t, err)
+	resp, job := submit(t, ts, string(payload))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "filesystem", job.SourceType)
+	assert.Equal(t, "trufflehog - filesystem", job.Name)
+
+	// Findings are streamed until the job finishes.
+	resp, err = http.Get(ts.URL + "/v1/jobs/" + job.ID + "/findings")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var findings []map[string]any
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var finding map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &finding))
+		findings = append(findings, finding)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, findings, 1)
+	assert.Equal(t, "fake secret", findings[0]["Raw"])
+
+	resp, err = http.Get(ts.URL + "/v1/jobs/" + job.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var status JobStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, JobFinished, status.State)
+	assert.Equal(t, 1, status.Findings)
+	require.NotNil(t, status.Progress)
+	assert.NotZero(t, status.Progress.TotalChunks)
+}
+
+func TestServer_InvalidJob(t *testing.T) {
+	ts := newTestServer(t, "")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "unknown source type", body: `{"source_type": "nope"}`},
+		{name: "unsupported source type", body: `{"source_type": "syslog"}`},
+		{name: "plugin source", body: `{"source_type": "plugin", "connection": {"path": "/bin/sh"}}`},
+		{name: "invalid connection", body: `{"source_type": "git", "connection": {"nope": true}}`},
+		{name: "invalid json", body: `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/v1/jobs", "application/json", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+
+	resp, err := http.Get(ts.URL + "/v1/jobs/missing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_Token(t *testing.T) {
+	ts := newTestServer(t, "secret")
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "secret": http.StatusOK} {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/jobs", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode, token)
+	}
+}
+
+func TestJob_Findings(t *testing.T) {
+	job := newJob("id", "name", 0, func(error) {})
+	findings, changed := job.Findings(0)
+	assert.Empty(t, findings)
+
+	require.NoError(t, job.Print(context.Background(), &detectors.ResultWithMetadata{Result: detectors.Result{Raw: []byte("a"), Verified: true}}))
+	select {
+	case <-changed:
+	default:
+		t.Fatal("adding a finding should notify streams")
+	}
+	findings, _ = job.Findings(0)
+	require.Len(t, findings, 1)
+	assert.True(t, bytes.Contains(findings[0], []byte(`"Raw":"a"`)))
+	findings, _ = job.Findings(5)
+	assert.Empty(t, findings)
+
+	_, changed = job.Findings(1)
+	job.finish(nil, nil)
+	<-changed
+	<-job.Done()
+	status := job.Status()
+	assert.Equal(t, JobFinished, status.State)
+	assert.Equal(t, 1, status.VerifiedFindings)
+}
+
+func TestServer_Schedule(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte(fakeDetectorKeyword), 0o644))
+	statePath := filepath.Join(t.TempDir(), "schedules.json")
+	printer := new(recordingPrinter)
+	s := newServer(t, "", printer)
+	require.NoError(t, s.LoadSchedules(statePath))
+
+	connection, err := json.Marshal(map[string]any{"paths": []string{dir}})
+	require.NoError(t, err)
+	source, err := s.AddSource(JobRequest{SourceType: "filesystem", Connection: connection})
+	require.NoError(t, err)
+	_, err = s.AddSchedule(ScheduleRequest{SourceID: source.ID, Cron: "not cron"})
+	assert.Error(t, err)
+	_, err = s.AddSchedule(ScheduleRequest{SourceID: "missing", Cron: "@daily"})
+	assert.ErrorIs(t, err, errSourceNotFound)
+	sc, err := s.AddSchedule(ScheduleRequest{SourceID: source.ID, Cron: "@daily"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.DeleteSource(source.ID), errSourceInUse)
+
+	runAndWait := func() ScheduleRun {
+		t.Helper()
+		s.RunSchedule(sc)
+		var last ScheduleRun
+		require.Eventually(t, func() bool {
+			history := sc.Status().History
+			last = history[len(history)-1]
+			return last.FinishedAt != nil
+		}, 10*time.Second, 10*time.Millisecond)
+		return last
+	}
+
+	// Every finding of the first run is new.
+	run := runAndWait()
+	assert.Equal(t, JobFinished, run.State)
+	assert.Equal(t, 1, run.Findings)
+	assert.Equal(t, 1, run.NewFindings)
+	assert.Equal(t, 1, printer.count())
+
+	// Findings of the previous run aren't sent again.
+	run = runAndWait()
+	assert.Equal(t, 1, run.Findings)
+	assert.Equal(t, 0, run.NewFindings)
+	assert.Equal(t, 1, printer.count())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte(fakeDetectorKeyword), 0o644))
+	run = runAndWait()
+	assert.Equal(t, 2, run.Findings)
+	assert.Equal(t, 1, run.NewFindings)
+	assert.Equal(t, 2, printer.count())
+
+	// A run isn't started while the previous one is in progress.
+	s.RunSchedule(sc)
+	s.RunSchedule(sc)
+	require.Eventually(t, func() bool { return !sc.Status().Running }, 10*time.Second, 10*time.Millisecond)
+	history := sc.Status().History
+	require.Len(t, history, 5)
+	assert.Equal(t, RunSkipped, history[4].State)
+	assert.Equal(t, JobFinished, history[3].State)
+	assert.Equal(t, 0, history[3].NewFindings)
+
+	// Sources, schedules, and the findings of their last run are restored.
+	restored := newServer(t, "", printer)
+	require.NoError(t, restored.LoadSchedules(statePath))
+	_, ok := restored.Source(source.ID)
+	assert.True(t, ok)
+	restoredSchedule, ok := restored.Schedule(sc.ID)
+	require.True(t, ok)
+	restoredHistory := restoredSchedule.Status().History
+	require.Len(t, restoredHistory, len(history))
+	for i, run := range history {
+		assert.Equal(t, run.JobID, restoredHistory[i].JobID)
+		assert.Equal(t, run.State, restoredHistory[i].State)
+		assert.Equal(t, run.NewFindings, restoredHistory[i].NewFindings)
+	}
+	assert.Len(t, restoredSchedule.known, 2)
+
+	assert.True(t, s.DeleteSchedule(sc.ID))
+	assert.False(t, s.DeleteSchedule(sc.ID))
+	assert.NoError(t, s.DeleteSource(source.ID))
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/crypto/hkdf"
+	"gopkg.in/yaml.v3"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/config"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/policy"
+)
+
+// Tenant is a business unit sharing the server. Requests authenticated with
+// its token only see and create its own jobs, stored sources, and schedules,
+// and its jobs are scanned with its overrides of the server's detectors and
+// evaluated against its policy.
+type Tenant struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+	// IncludeDetectors replaces the detectors the server includes, and
+	// ExcludeDetectors are excluded in addition to those the server
+	// excludes. Both are lists as in --include-detectors.
+	IncludeDetectors string `yaml:"include_detectors"`
+	ExcludeDetectors string `yaml:"exclude_detectors"`
+	// Policy is the path of a policy file the tenant's jobs are evaluated
+	// against.
+	Policy string `yaml:"policy"`
+
+	policy *policy.Policy
+	// policyRules is the YAML of policy.
+	policyRules string
+}
+
+// TenantOverrides are the detector and policy overrides of a tenant, as
+// listed and replaced by admins through the API.
+type TenantOverrides struct {
+	IncludeDetectors string `json:"include_detectors"`
+	ExcludeDetectors string `json:"exclude_detectors"`
+	// Policy is the YAML of the tenant's policy, if it has one.
+	Policy string `json:"policy,omitempty"`
+}
+
+// TenantStatus is the JSON representation of a tenant returned by the API.
+// Its token is never returned.
+type TenantStatus struct {
+	Name string `json:"name"`
+	TenantOverrides
+}
+
+type tenantsFile struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// LoadTenants reads the tenants of the YAML file at path, which lists them
+// under a top-level tenants key.
+func LoadTenants(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tenants: %w", err)
+	}
+	var file tenantsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse tenants: %w", err)
+	}
+	return file.Tenants, nil
+}
+
+// SetTenants configures the tenants of the server. Once there are tenants,
+// requests must authenticate, either with a tenant's token or with the
+// server's token to see every tenant.
+func (s *Server) SetTenants(tenants []Tenant) error {
+	byName := make(map[string]*Tenant, len(tenants))
+	tokens := make(map[string]string, len(tenants))
+	for i := range tenants {
+		t := tenants[i]
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("tenant %d has no name", i+1)
+		case t.Token == "":
+			return fmt.Errorf("tenant %s has no token", t.Name)
+		case byName[t.Name] != nil:
+			return fmt.Errorf("tenant %s is listed twice", t.Name)
+		case tokens[t.Token] != "" || t.Token == s.token:
+			return fmt.Errorf("tenant %s shares its token", t.Name)
+		}
+		overrides := TenantOverrides{IncludeDetectors: t.IncludeDetectors, ExcludeDetectors: t.ExcludeDetectors}
+		if t.Policy != "" {
+			data, err := os.ReadFile(t.Policy)
+			if err != nil {
+				return fmt.Errorf("tenant %s: could not read policy: %w", t.Name, err)
+			}
+			overrides.Policy = string(data)
+		}
+		if err := t.override(overrides); err != nil {
+			return fmt.Errorf("tenant %s: %w", t.Name, err)
+		}
+		byName[t.Name] = &t
+		tokens[t.Token] = t.Name
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants = byName
+	return nil
+}
+
+// tenant returns the configured tenant with the given name.
+func (s *Server) tenant(name string) (*Tenant, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tenants[name]
+	return t, ok
+}
+
+// Tenants returns every tenant.
+func (s *Server) Tenants() []TenantStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenants := make([]TenantStatus, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		tenants = append(tenants, t.status())
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Name < tenants[j].Name })
+	return tenants
+}
+
+// SetTenantOverrides replaces the overrides of a tenant. Jobs that already
+// started keep the overrides they started with.
+func (s *Server) SetTenantOverrides(name string, overrides TenantOverrides) (TenantStatus, error) {
+	s.mu.Lock()
+	t, ok := s.tenants[name]
+	if !ok {
+		s.mu.Unlock()
+		return TenantStatus{}, errTenantNotFound
+	}
+	// Tenants are replaced rather than changed, since running jobs may use
+	// the old one.
+	updated := *t
+	if err := updated.override(overrides); err != nil {
+		s.mu.Unlock()
+		return TenantStatus{}, err
+	}
+	s.tenants[name] = &updated
+	s.overrides[name] = overrides
+	s.mu.Unlock()
+	s.persist()
+	return updated.status(), nil
+}
+
+// override validates and applies overrides to the tenant.
+func (t *Tenant) override(overrides TenantOverrides) error {
+	for _, list := range []string{overrides.IncludeDetectors, overrides.ExcludeDetectors} {
+		if _, err := config.ParseDetectors(list); err != nil {
+			return fmt.Errorf("invalid detectors %q: %w", list, err)
+		}
+	}
+	var pol *policy.Policy
+	if overrides.Policy != "" {
+		var err error
+		if pol, err = policy.Parse([]byte(overrides.Policy)); err != nil {
+			return err
+		}
+	}
+	t.IncludeDetectors, t.ExcludeDetectors = overrides.IncludeDetectors, overrides.ExcludeDetectors
+	t.policy, t.policyRules = pol, overrides.Policy
+	return nil
+}
+
+func (t *Tenant) status() TenantStatus {
+	return TenantStatus{Name: t.Name, TenantOverrides: TenantOverrides{
+		IncludeDetectors: t.IncludeDetectors,
+		ExcludeDetectors: t.ExcludeDetectors,
+		Policy:           t.policyRules,
+	}}
+}
+
+// SetStateKey encrypts the connections of stored sources, which include
+// their credentials, in the state file. Every tenant's sources are encrypted
+// with a key of its own derived from key. It must be called before
+// LoadSchedules.
+func (s *Server) SetStateKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateKey = []byte(key)
+}
+
+// sealer returns the cipher of the stored sources of tenant, or nil if the
+// state isn't encrypted.
+func (s *Server) sealer(tenant string) (cipher.AEAD, error) {
+	s.mu.Lock()
+	key := s.stateKey
+	s.mu.Unlock()
+	if len(key) == 0 {
+		return nil, nil
+	}
+	derived := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte("trufflehog stored source "+tenant)), derived); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the connection of a stored source, binding it to the
+// source's ID so it can't be swapped with another's.
+func (s *Server) seal(source *StoredSource) ([]byte, error) {
+	aead, err := s.sealer(source.Tenant)
+	if err != nil || aead == nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(source.Connection)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, source.Connection, []byte(source.ID)), nil
+}
+
+// open decrypts the connection of a stored source sealed by seal.
+func (s *Server) open(source *StoredSource, sealed []byte) ([]byte, error) {
+	aead, err := s.sealer(source.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	if aead == nil {
+		return nil, fmt.Errorf("source %s is encrypted, but no state key is set", source.ID)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("source %s: encrypted connection is truncated", source.ID)
+	}
+	connection, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(source.ID))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt source %s, is the state key right? %w", source.ID, err)
+	}
+	return connection, nil
+}
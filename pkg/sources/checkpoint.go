@@ -0,0 +1,144 @@
+package sources
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/checkpoint"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// WithCheckpoints saves the progress of every source the manager runs to
+// store under runID at the given interval. Sources with progress already
+// saved under runID resume from it: completed sources are skipped, completed
+// units aren't chunked again, and the source's EncodedResumeInfo is restored.
+func WithCheckpoints(store checkpoint.Store, runID string, interval time.Duration) func(*SourceManager) {
+	return func(mgr *SourceManager) {
+		mgr.checkpoints = &checkpointer{store: store, runID: runID, interval: interval}
+	}
+}
+
+type checkpointer struct {
+	store    checkpoint.Store
+	runID    string
+	interval time.Duration
+}
+
+// sourceCheckpoint tracks the progress of a single running source. A nil
+// *sourceCheckpoint is valid and tracks nothing.
+type sourceCheckpoint struct {
+	*checkpointer
+	name     string
+	progress *Progress
+	stop     chan struct{}
+	stopped  chan struct{}
+
+	mu        sync.Mutex
+	completed map[string]struct{}
+	done      bool
+}
+
+// resume loads the saved progress of the named source and restores its
+// resume info.
+func (c *checkpointer) resume(ctx context.Context, name string, progress *Progress) (*sourceCheckpoint, error) {
+	state, err := c.store.Load(ctx, c.runID, name)
+	if err != nil {
+		return nil, err
+	}
+	sc := &sourceCheckpoint{
+		checkpointer: c,
+		name:         name,
+		progress:     progress,
+		completed:    make(map[string]struct{}),
+	}
+	if state == nil {
+		return sc, nil
+	}
+	sc.done = state.Done
+	for _, id := range state.CompletedUnits {
+		sc.completed[id] = struct{}{}
+	}
+	if state.ResumeInfo != "" && progress != nil {
+		progress.mut.Lock()
+		progress.EncodedResumeInfo = state.ResumeInfo
+		progress.mut.Unlock()
+	}
+	return sc, nil
+}
+
+func unitKey(unit SourceUnit) string {
+	id, kind := unit.SourceUnitID()
+	return string(kind) + ":" + id
+}
+
+func (sc *sourceCheckpoint) unitCompleted(unit SourceUnit) bool {
+	if sc == nil {
+		return false
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	_, ok := sc.completed[unitKey(unit)]
+	return ok
+}
+
+func (sc *sourceCheckpoint) completeUnit(unit SourceUnit) {
+	if sc == nil {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.completed[unitKey(unit)] = struct{}{}
+}
+
+// start periodically saves the source's progress until finish is called.
+func (sc *sourceCheckpoint) start(ctx context.Context) {
+	sc.stop, sc.stopped = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(sc.stopped)
+		ticker := time.NewTicker(sc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sc.save(ctx)
+			case <-sc.stop:
+				return
+			}
+		}
+	}()
+}
+
+// finish stops the periodic saves and saves the final progress of the
+// source, which is done if it finished without error.
+func (sc *sourceCheckpoint) finish(ctx context.Context, err error) {
+	close(sc.stop)
+	<-sc.stopped
+	sc.mu.Lock()
+	sc.done = err == nil && ctx.Err() == nil
+	sc.mu.Unlock()
+	// Save even if ctx was cancelled, since that is how an interrupted scan
+	// ends.
+	sc.save(context.WithoutCancel(ctx))
+}
+
+func (sc *sourceCheckpoint) save(ctx context.Context) {
+	var state checkpoint.State
+	if sc.progress != nil {
+		sc.progress.mut.Lock()
+		state.ResumeInfo = sc.progress.EncodedResumeInfo
+		sc.progress.mut.Unlock()
+	}
+
+	sc.mu.Lock()
+	state.Done = sc.done
+	state.CompletedUnits = make([]string, 0, len(sc.completed))
+	for id := range sc.completed {
+		state.CompletedUnits = append(state.CompletedUnits, id)
+	}
+	sc.mu.Unlock()
+	state.UpdatedAt = time.Now().UTC()
+
+	if err := sc.store.Save(ctx, sc.runID, sc.name, state); err != nil {
+		ctx.Logger().Error(err, "could not save checkpoint")
+	}
+}
@@ -0,0 +1,45 @@
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/checkpoint"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestSourceManagerCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	store, err := checkpoint.Open(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(ctx, "run", "dummy", checkpoint.State{
+		CompletedUnits: []string{"test:countChunk(0)"},
+	}))
+
+	run := func() []byte {
+		mgr := NewManager(WithSourceUnits(), WithBufferedOutput(8), WithCheckpoints(store, "run", time.Hour))
+		source, err := buildDummy(&counterChunker{count: 3})
+		require.NoError(t, err)
+		ref, err := mgr.Run(ctx, "dummy", source)
+		require.NoError(t, err)
+		<-ref.Done()
+		require.NoError(t, mgr.Wait())
+		var data []byte
+		for chunk := range mgr.Chunks() {
+			data = append(data, chunk.Data...)
+		}
+		return data
+	}
+
+	data := run()
+	assert.ElementsMatch(t, []byte{1, 2}, data, "the completed unit should not be chunked again")
+	state, err := store.Load(ctx, "run", "dummy")
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+	assert.ElementsMatch(t, []string{"test:countChunk(0)", "test:countChunk(1)", "test:countChunk(2)"}, state.CompletedUnits)
+
+	assert.Empty(t, run(), "a completed source should be skipped")
+}
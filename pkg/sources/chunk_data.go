@@ -0,0 +1,46 @@
+package sources
+
+import "sync"
+
+// pooledDataSize is the largest chunk data AllocData takes from the pool.
+// Larger chunks are rare enough that allocating them isn't worth pooling.
+const pooledDataSize = 4 << 10 // 4KB
+
+var chunkDataPool = sync.Pool{
+	New: func() any {
+		data := make([]byte, pooledDataSize)
+		return &data
+	},
+}
+
+// AllocData sets the Data of c to n bytes, taken from a pool if they're
+// small, and returns it. Sources that emit many small chunks use it to avoid
+// allocating the data of each one.
+//
+// The data is returned to the pool once the chunk is released with
+// SourceManager.ReleaseChunk, so consumers of the manager's chunks must copy
+// any data they keep after releasing its chunk. Chunks reported with ChunkOk
+// belong to the manager, even if it returns an error; sources return the data
+// of the ones they don't report with FreeData.
+func (c *Chunk) AllocData(n int) []byte {
+	if n > pooledDataSize {
+		c.Data, c.pooled = make([]byte, n), false
+		return c.Data
+	}
+	c.Data, c.pooled = (*chunkDataPool.Get().(*[]byte))[:n], true
+	return c.Data
+}
+
+// FreeData returns the data of c to the pool if it was taken from it, and
+// clears it. Data replaced since, such as by a decoder, only goes to the pool
+// if it's the size of the pooled data.
+func (c *Chunk) FreeData() {
+	if !c.pooled {
+		return
+	}
+	if cap(c.Data) == pooledDataSize {
+		data := c.Data[:pooledDataSize]
+		chunkDataPool.Put(&data)
+	}
+	c.Data, c.pooled = nil, false
+}
@@ -17,6 +17,38 @@ const (
 	TotalChunkSize = ChunkSize + PeekSize
 )
 
+// chunkSizesKey is the context key of the sizes set by WithChunkSizes.
+type chunkSizesKey struct{}
+
+type chunkSizes struct{ chunk, peek int }
+
+// WithChunkSizes returns a copy of ctx in which data is split into chunks of
+// chunkSize bytes, each sharing peekSize bytes with the next, when no sizes
+// are given. A size of 0 keeps ChunkSize or PeekSize. The sizes are scoped to
+// the context so engines with different sizes can run in the same process.
+func WithChunkSizes(ctx context.Context, chunkSize, peekSize int) context.Context {
+	return context.WithValue(ctx, chunkSizesKey{}, chunkSizes{chunk: max(chunkSize, 0), peek: max(peekSize, 0)})
+}
+
+// DefaultChunkSize returns the chunk size used in ctx when none is given.
+func DefaultChunkSize(ctx context.Context) int {
+	if sizes, ok := ctx.Value(chunkSizesKey{}).(chunkSizes); ok && sizes.chunk > 0 {
+		return sizes.chunk
+	}
+	return ChunkSize
+}
+
+// DefaultPeekSize returns the overlap between consecutive chunks used in ctx
+// when none is given. Sources that split data themselves should carry at
+// least this many bytes of each chunk into the next one, so secrets spanning
+// the boundary are still found.
+func DefaultPeekSize(ctx context.Context) int {
+	if sizes, ok := ctx.Value(chunkSizesKey{}).(chunkSizes); ok && sizes.peek > 0 {
+		return sizes.peek
+	}
+	return PeekSize
+}
+
 type chunkReaderConfig struct {
 	chunkSize int
 	totalSize int
@@ -70,30 +102,33 @@ func NewChunkReader(opts ...ConfigOption) ChunkReader {
 }
 
 func applyOptions(opts []ConfigOption) *chunkReaderConfig {
-	// Set defaults.
-	config := &chunkReaderConfig{
-		chunkSize: ChunkSize, // default
-		peekSize:  PeekSize,  // default
-	}
-
+	config := new(chunkReaderConfig)
 	for _, opt := range opts {
 		opt(config)
 	}
-
-	config.totalSize = config.chunkSize + config.peekSize
-
 	return config
 }
 
+// createReaderFn returns a ChunkReader that reads with config, whose sizes
+// that aren't set are the defaults of the context of each read.
 func createReaderFn(config *chunkReaderConfig) ChunkReader {
 	return func(ctx context.Context, reader io.Reader) <-chan ChunkResult {
-		return readInChunks(ctx, reader, config)
+		c := *config
+		if c.chunkSize <= 0 {
+			c.chunkSize = DefaultChunkSize(ctx)
+		}
+		if c.peekSize <= 0 {
+			c.peekSize = DefaultPeekSize(ctx)
+		}
+		c.totalSize = c.chunkSize + c.peekSize
+		return readInChunks(ctx, reader, &c)
 	}
 }
 
 func readInChunks(ctx context.Context, reader io.Reader, config *chunkReaderConfig) <-chan ChunkResult {
 	const channelSize = 64
-	chunkReader := bufio.NewReaderSize(reader, config.chunkSize)
+	// The buffer must hold a whole peek, which may be larger than a chunk.
+	chunkReader := bufio.NewReaderSize(reader, max(config.chunkSize, config.peekSize))
 	chunkResultChan := make(chan ChunkResult, channelSize)
 
 	go func() {
@@ -95,6 +95,14 @@ func TestNewChunkedReader(t *testing.T) {
 			wantChunks: []string{strings.Repeat("a", 2048), strings.Repeat("a", 2048), strings.Repeat("a", 2048), strings.Repeat("a", 1024)},
 			wantErr:    false,
 		},
+		{
+			name:       "Peek larger than chunkSize",
+			input:      strings.Repeat("a", 4096),
+			chunkSize:  1024,
+			peekSize:   2048,
+			wantChunks: []string{strings.Repeat("a", 3072), strings.Repeat("a", 3072), strings.Repeat("a", 2048), strings.Repeat("a", 1024)},
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,6 +129,26 @@ func TestNewChunkedReader(t *testing.T) {
 	}
 }
 
+func TestNewChunkReader_Defaults(t *testing.T) {
+	ctx := WithChunkSizes(context.Background(), 1024, 256)
+	assert.Equal(t, 1024, DefaultChunkSize(ctx))
+	assert.Equal(t, 256, DefaultPeekSize(ctx))
+
+	var chunks []string
+	for data := range NewChunkReader()(ctx, strings.NewReader(strings.Repeat("a", 2048))) {
+		assert.NoError(t, data.Error())
+		chunks = append(chunks, string(data.Bytes()))
+	}
+	assert.Equal(t, []string{strings.Repeat("a", 1280), strings.Repeat("a", 1024)}, chunks)
+
+	// Other contexts keep the defaults.
+	assert.Equal(t, ChunkSize, DefaultChunkSize(context.Background()))
+	assert.Equal(t, PeekSize, DefaultPeekSize(context.Background()))
+	ctx = WithChunkSizes(ctx, 0, 0)
+	assert.Equal(t, ChunkSize, DefaultChunkSize(ctx))
+	assert.Equal(t, PeekSize, DefaultPeekSize(ctx))
+}
+
 func BenchmarkChunkReader(b *testing.B) {
 	var bigChunk = make([]byte, 1<<24) // 16MB
 
@@ -78,6 +78,15 @@ func (s *Source) Init(_ context.Context, name string, jobId sources.JobID, sourc
 	return nil
 }
 
+// HealthCheck lists the projects the token can read, which fails if the token
+// is invalid.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if _, err := s.projects(ctx); err != nil {
+		return fmt.Errorf("error getting projects: %w", err)
+	}
+	return nil
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
 	projects, err := s.projects(ctx)
@@ -0,0 +1,114 @@
+package sources
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// UnitDispatcher scans the units of a source outside of the local process,
+// such as on remote workers. When set with WithUnitDispatcher, the manager
+// enumerates units locally but doesn't chunk them.
+type UnitDispatcher interface {
+	// DispatchUnits hands out every unit received on units to be scanned and
+	// returns once all of them have been. done is called as each unit
+	// finishes, with the error scanning it, if any.
+	DispatchUnits(ctx context.Context, job JobProgressRef, kind sourcespb.SourceType, units <-chan SourceUnit, done func(SourceUnit, error)) error
+}
+
+// UnitLeaser provides the units of a source that were enumerated outside of
+// the local process. When set with WithUnitLeaser, the manager chunks the
+// leased units instead of enumerating the source itself.
+type UnitLeaser interface {
+	// LeaseUnits reports units of the source leased to this process to
+	// reporter, decoding them with unmarshaller, until no units are left.
+	LeaseUnits(ctx context.Context, sourceName string, kind sourcespb.SourceType, unmarshaller SourceUnitUnmarshaller, reporter UnitReporter) error
+	// ReportChunk records a chunk produced by a leased unit.
+	ReportChunk(unit SourceUnit, chunk *Chunk)
+	// ReleaseUnit returns a leased unit once all of its chunks have been
+	// exported, along with the error chunking it, if any.
+	ReleaseUnit(ctx context.Context, unit SourceUnit, err error)
+}
+
+// WithUnitDispatcher scans the units of every source with the provided
+// UnitDispatcher instead of chunking them locally.
+func WithUnitDispatcher(d UnitDispatcher) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.unitDispatcher = d }
+}
+
+// WithUnitLeaser scans the units leased from the provided UnitLeaser instead
+// of enumerating sources locally.
+func WithUnitLeaser(l UnitLeaser) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.unitLeaser = l }
+}
+
+// dispatchUnits enumerates the units of source and hands them to the
+// manager's UnitDispatcher to be scanned.
+func (s *SourceManager) dispatchUnits(ctx context.Context, source Source, enumerator SourceUnitEnumerator, report *JobProgress, cp *sourceCheckpoint) error {
+	unitReporter := &mgrUnitReporter{
+		unitCh: make(chan SourceUnit, 1),
+		report: report,
+	}
+	fatalErr := make(chan error, 1)
+	go func() {
+		report.StartEnumerating(time.Now())
+		defer func() { report.EndEnumerating(time.Now()) }()
+		defer close(unitReporter.unitCh)
+		ctx.Logger().V(2).Info("enumerating source")
+		if err := enumerator.Enumerate(ctx, unitReporter); err != nil {
+			report.ReportError(Fatal{err})
+			fatalErr <- Fatal{err}
+		}
+	}()
+
+	units := make(chan SourceUnit)
+	go func() {
+		defer close(units)
+		for unit := range unitReporter.unitCh {
+			if cp.unitCompleted(unit) {
+				id, _ := unit.SourceUnitID()
+				ctx.Logger().V(3).Info("skipping unit completed by the resumed run", "unit", id)
+				continue
+			}
+			if err := common.CancellableWrite(ctx, units, unit); err != nil {
+				return
+			}
+		}
+	}()
+
+	err := s.unitDispatcher.DispatchUnits(ctx, report.Ref(), source.Type(), units, func(unit SourceUnit, err error) {
+		if err != nil {
+			report.ReportError(ChunkError{Unit: unit, Err: err})
+			return
+		}
+		cp.completeUnit(unit)
+	})
+	if err != nil {
+		report.ReportError(Fatal{err})
+		return Fatal{err}
+	}
+	select {
+	case err := <-fatalErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// leaseUnits reports the units leased from the manager's UnitLeaser in place
+// of enumerating source.
+func (s *SourceManager) leaseUnits(ctx context.Context, source SourceUnitEnumChunker, report *JobProgress, reporter UnitReporter) error {
+	unmarshaller, ok := source.(SourceUnitUnmarshaller)
+	if !ok {
+		return fmt.Errorf("source can't decode units enumerated elsewhere")
+	}
+	var kind sourcespb.SourceType
+	if src, ok := source.(Source); ok {
+		kind = src.Type()
+	}
+	ctx.Logger().V(2).Info("leasing source units")
+	return s.unitLeaser.LeaseUnits(ctx, report.SourceName, kind, unmarshaller, reporter)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
@@ -164,6 +166,44 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 	return nil
 }
 
+// Validate checks that the configured images exist, reading only the
+// manifests of remote images.
+func (s *Source) Validate(ctx context.Context) []error {
+	remoteOpts, err := s.remoteOpts()
+	if err != nil {
+		return []error{err}
+	}
+	remoteOpts = append(remoteOpts, remote.WithContext(ctx))
+
+	var errs []error
+	for _, image := range s.conn.GetImages() {
+		if path, ok := strings.CutPrefix(image, "file://"); ok {
+			if _, err := os.Stat(path); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		var ref name.Reference
+		if _, _, hasDigest := baseAndTagFromImage(image); hasDigest {
+			ref, err = name.NewDigest(image)
+		} else {
+			ref, err = name.NewTag(image)
+		}
+		if err == nil {
+			_, err = remote.Head(ref, remoteOpts...)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", image, err))
+		}
+	}
+	return errs
+}
+
+// HealthCheck checks that the images can still be pulled.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return errors.Join(s.Validate(ctx)...)
+}
+
 // processImage processes an individual image and prepares it for further processing.
 func (s *Source) processImage(ctx context.Context, image string) (imageInfo, error) {
 	var (
@@ -341,7 +381,9 @@ type chunkProcessingInfo struct {
 	layer  layerInfo
 }
 
-// processChunk processes an individual chunk of a layer.
+// processChunk processes an individual file of a layer. Files are handled
+// like those of other sources, so the contents of archives in the layer are
+// scanned too, and their chunks record the archives they came from.
 func (s *Source) processChunk(ctx context.Context, info chunkProcessingInfo, chunksChan chan *sources.Chunk) error {
 	const filesizeLimitBytes int64 = 50 * 1024 * 1024 // 50MB
 	if info.size > filesizeLimitBytes {
@@ -349,39 +391,30 @@ func (s *Source) processChunk(ctx context.Context, info chunkProcessingInfo, chu
 		return nil
 	}
 
-	chunkReader := sources.NewChunkReader()
-	chunkResChan := chunkReader(ctx, info.reader)
-
-	for data := range chunkResChan {
-		if err := data.Error(); err != nil {
-			ctx.Logger().Error(err, "error reading chunk.")
-			continue
-		}
-
-		chunk := &sources.Chunk{
-			SourceType: s.Type(),
-			SourceName: s.name,
-			SourceID:   s.SourceID(),
-			SourceMetadata: &source_metadatapb.MetaData{
-				Data: &source_metadatapb.MetaData_Docker{
-					Docker: &source_metadatapb.Docker{
-						File:  "/" + info.name,
-						Image: info.layer.base,
-						Tag:   info.layer.tag,
-						Layer: info.layer.digest.String(),
-					},
+	chunkSkel := &sources.Chunk{
+		SourceType: s.Type(),
+		SourceName: s.name,
+		SourceID:   s.SourceID(),
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Docker{
+				Docker: &source_metadatapb.Docker{
+					File:  "/" + info.name,
+					Image: info.layer.base,
+					Tag:   info.layer.tag,
+					Layer: info.layer.digest.String(),
 				},
 			},
-			Verify: s.verify,
-		}
-		chunk.Data = data.Bytes()
-
-		if err := common.CancellableWrite(ctx, chunksChan, chunk); err != nil {
-			return err
-		}
+		},
+		Verify: s.verify,
 	}
 
-	return nil
+	err := handlers.HandleFile(ctx, info.reader, chunkSkel, sources.ChanReporter{Ch: chunksChan})
+	if err != nil && ctx.Err() == nil {
+		// A file that can't be handled shouldn't stop the rest of the layer from being scanned.
+		ctx.Logger().Error(err, "error handling file", "file", info.name)
+		return nil
+	}
+	return err
 }
 
 func (s *Source) remoteOpts() ([]remote.Option, error) {
@@ -1,11 +1,21 @@
 package docker
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -134,6 +144,26 @@ func TestDockerImageScanWithDigest(t *testing.T) {
 	assert.Equal(t, 1, historyCounter)
 }
 
+func TestValidate(t *testing.T) {
+	dockerConn := &sourcespb.Docker{
+		Credential: &sourcespb.Docker_Unauthenticated{
+			Unauthenticated: &credentialspb.Unauthenticated{},
+		},
+		Images: []string{"file:///no/such/image.tar", "not a valid:image"},
+	}
+
+	conn := &anypb.Any{}
+	err := conn.MarshalFrom(dockerConn)
+	assert.NoError(t, err)
+
+	s := &Source{}
+	err = s.Init(context.TODO(), "test source", 0, 0, false, conn, 1)
+	assert.NoError(t, err)
+
+	errs := s.Validate(context.TODO())
+	assert.Len(t, errs, 2)
+}
+
 func TestBaseAndTagFromImage(t *testing.T) {
 	tests := []struct {
 		image      string
@@ -166,3 +196,59 @@ func isHistoryChunk(t *testing.T, chunk *sources.Chunk) bool {
 	return metadata != nil &&
 		strings.HasPrefix(metadata.File, "image-metadata:history:")
 }
+
+func TestDockerImageScanNestedArchive(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	f, err := zw.Create("config/.env")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("AWS_SECRET_ACCESS_KEY=example"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var layerTar bytes.Buffer
+	tw := tar.NewWriter(&layerTar)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "app/creds.zip", Mode: 0644, Size: int64(zipped.Len())}))
+	_, err = tw.Write(zipped.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(layerTar.Bytes())), nil
+	})
+	require.NoError(t, err)
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "image.tar")
+	tag, err := name.NewTag("example/nested:latest")
+	require.NoError(t, err)
+	require.NoError(t, tarball.WriteToFile(path, tag, img))
+
+	conn, err := anypb.New(&sourcespb.Docker{
+		Credential: &sourcespb.Docker_Unauthenticated{Unauthenticated: &credentialspb.Unauthenticated{}},
+		Images:     []string{"file://" + path},
+	})
+	require.NoError(t, err)
+	s := &Source{}
+	require.NoError(t, s.Init(context.TODO(), "test source", 0, 0, false, conn, 1))
+
+	chunksChan := make(chan *sources.Chunk, 8)
+	require.NoError(t, s.Chunks(context.TODO(), chunksChan))
+	close(chunksChan)
+
+	var found []*sources.Chunk
+	for chunk := range chunksChan {
+		if strings.Contains(string(chunk.Data), "AWS_SECRET_ACCESS_KEY") {
+			found = append(found, chunk)
+		}
+	}
+	require.Len(t, found, 1)
+	md := found[0].SourceMetadata
+	assert.Equal(t, "/app/creds.zip", md.GetDocker().GetFile())
+	digest, err := layer.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), md.GetDocker().GetLayer())
+	require.Len(t, md.GetProvenance(), 1)
+	assert.Equal(t, "zip", md.GetProvenance()[0].GetFormat())
+	assert.Equal(t, "config/.env", md.GetProvenance()[0].GetPath())
+}
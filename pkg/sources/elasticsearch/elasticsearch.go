@@ -120,6 +120,19 @@ func (s *Source) JobID() sources.JobID {
 	return s.jobId
 }
 
+// HealthCheck pings the cluster, which fails if it can't be reached or
+// rejects the credentials.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	ok, err := s.client.Ping().Do(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("elasticsearch cluster did not respond to ping")
+	}
+	return nil
+}
+
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(
 	ctx context.Context,
@@ -6,6 +6,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/go-errors/errors"
 	"github.com/go-logr/logr"
@@ -16,6 +18,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -33,6 +36,13 @@ type Source struct {
 	paths       []string
 	log         logr.Logger
 	filter      *common.Filter
+	// watch keeps the source scanning changed files after the paths are
+	// scanned.
+	watch bool
+
+	ignoreFilesMu sync.Mutex
+	// ignoreFiles caches the .trufflehogignore file of each scanned directory.
+	ignoreFiles map[string]*ignorefile.File
 	sources.Progress
 	sources.CommonSourceUnitUnmarshaller
 }
@@ -72,17 +82,24 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	}
 	s.paths = append(conn.Paths, conn.Directories...)
 
-	filter, err := common.FilterFromFiles(conn.IncludePathsFile, conn.ExcludePathsFile)
+	filter, err := common.FilterFromFiles(conn.IncludePathsFile, conn.ExcludePathsFile, sources.FilterOptions(conn.GetFilter())...)
 	if err != nil {
 		return fmt.Errorf("unable to create filter: %w", err)
 	}
 	s.filter = filter
+	s.watch = conn.GetWatch()
 
 	return nil
 }
 
 // Chunks emits chunks of bytes over a channel.
 func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ ...sources.ChunkingTarget) error {
+	watcher, err := s.startWatching(ctx)
+	if err != nil {
+		return err
+	}
+	defer watcher.close()
+
 	for i, path := range s.paths {
 		logger := ctx.Logger().WithValues("path", path)
 		if common.IsDone(ctx) {
@@ -105,7 +122,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 		if fileInfo.IsDir() {
 			err = s.scanDir(ctx, cleanPath, chunksChan)
 		} else {
-			err = s.scanFile(ctx, cleanPath, chunksChan)
+			err = s.scanFile(ctx, cleanPath, chunksChan, nil)
 		}
 
 		if err != nil && !errors.Is(err, io.EOF) {
@@ -115,13 +132,14 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 		}
 	}
 
-	return nil
+	return watcher.run(ctx, s.changedFileScanner(ctx, chunksChan))
 }
 func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sources.Chunk) error {
 	workerPool := new(errgroup.Group)
 	workerPool.SetLimit(s.concurrency)
 	defer func() { _ = workerPool.Wait() }()
 
+	ignore := s.ignoreFile(ctx, path)
 	return fs.WalkDir(os.DirFS(path), ".", func(relativePath string, d fs.DirEntry, err error) error {
 		if err != nil {
 			ctx.Logger().Error(err, "error walking directory")
@@ -129,6 +147,13 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 		}
 		fullPath := filepath.Join(path, relativePath)
 
+		if ignore.Ignores(relativePath, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
 		// Skip over non-regular files. We do this check here to suppress noisy
 		// logs for trying to scan directories and other non-regular files in
 		// our traversal.
@@ -140,7 +165,7 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 		}
 
 		workerPool.Go(func() error {
-			if err = s.scanFile(ctx, fullPath, chunksChan); err != nil {
+			if err = s.scanFile(ctx, fullPath, chunksChan, ignore); err != nil {
 				ctx.Logger().Error(err, "error scanning file", "path", fullPath, "error", err)
 			}
 			return nil
@@ -152,7 +177,7 @@ func (s *Source) scanDir(ctx context.Context, path string, chunksChan chan *sour
 
 var skipSymlinkErr = errors.New("skipping symlink")
 
-func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sources.Chunk) error {
+func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sources.Chunk, ignore *ignorefile.File) error {
 	logger := ctx.Logger().WithValues("path", path)
 	fileStat, err := os.Lstat(path)
 	if err != nil {
@@ -161,6 +186,10 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 	if fileStat.Mode()&os.ModeSymlink != 0 {
 		return skipSymlinkErr
 	}
+	if !s.filter.PassSize(fileStat.Size()) {
+		logger.V(3).Info("skipping file larger than the max size", "size", fileStat.Size())
+		return nil
+	}
 
 	inputFile, err := os.Open(path)
 	if err != nil {
@@ -168,6 +197,12 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 	}
 	defer inputFile.Close()
 
+	content, pass := s.filter.PassReader(inputFile)
+	if !pass {
+		logger.V(3).Info("skipping file with excluded content type")
+		return nil
+	}
+
 	logger.V(3).Info("scanning file")
 
 	chunkSkel := &sources.Chunk{
@@ -182,16 +217,23 @@ func (s *Source) scanFile(ctx context.Context, path string, chunksChan chan *sou
 				},
 			},
 		},
-		Verify: s.verify,
+		Verify:     s.verify,
+		IgnoreFile: ignore,
 	}
 
-	return handlers.HandleFile(ctx, inputFile, chunkSkel, sources.ChanReporter{Ch: chunksChan})
+	return handlers.HandleFile(ctx, content, chunkSkel, sources.ChanReporter{Ch: chunksChan})
 }
 
 // Enumerate implements SourceUnitEnumerator interface. This implementation simply
 // passes the configured paths as the source unit, whether it be a single
 // filepath or a directory.
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
+	watcher, err := s.startWatching(ctx)
+	if err != nil {
+		return err
+	}
+	defer watcher.close()
+
 	for _, path := range s.paths {
 		fileInfo, err := os.Lstat(filepath.Clean(path))
 		if err != nil {
@@ -207,10 +249,17 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 			}
 			continue
 		}
+		ignore := s.ignoreFile(ctx, filepath.Clean(path))
 		err = fs.WalkDir(os.DirFS(path), ".", func(relativePath string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return reporter.UnitErr(ctx, err)
 			}
+			if ignore.Ignores(relativePath, d.IsDir()) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
 			if d.IsDir() {
 				return nil
 			}
@@ -227,7 +276,11 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 			}
 		}
 	}
-	return nil
+
+	// Changed files are reported as units of their own.
+	return watcher.run(ctx, func(path string, _ *ignorefile.File) error {
+		return reporter.UnitOk(ctx, sources.CommonSourceUnit{ID: path})
+	})
 }
 
 // ChunkUnit implements SourceUnitChunker interface.
@@ -251,7 +304,7 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 		} else {
 			// TODO: Finer grain error tracking of individual
 			// chunks (in the case of archives).
-			scanErr = s.scanFile(ctx, cleanPath, ch)
+			scanErr = s.scanFile(ctx, cleanPath, ch, s.enclosingIgnoreFile(ctx, cleanPath))
 		}
 	}()
 
@@ -272,3 +325,38 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 	}
 	return nil
 }
+
+// ignoreFile returns the .trufflehogignore file of the scanned directory dir,
+// or nil if it has none.
+func (s *Source) ignoreFile(ctx context.Context, dir string) *ignorefile.File {
+	s.ignoreFilesMu.Lock()
+	defer s.ignoreFilesMu.Unlock()
+	if ignore, ok := s.ignoreFiles[dir]; ok {
+		return ignore
+	}
+	ignore, err := ignorefile.Load(dir)
+	if err != nil {
+		// A broken ignore file shouldn't stop the scan, only its suppressions.
+		ctx.Logger().Error(err, "could not load "+ignorefile.Name, "path", dir)
+	}
+	if s.ignoreFiles == nil {
+		s.ignoreFiles = make(map[string]*ignorefile.File)
+	}
+	s.ignoreFiles[dir] = ignore
+	return ignore
+}
+
+// enclosingIgnoreFile returns the .trufflehogignore file of the scanned
+// directory that contains the file at path, which Enumerate reports as a
+// unit of its own.
+func (s *Source) enclosingIgnoreFile(ctx context.Context, path string) *ignorefile.File {
+	for _, root := range s.paths {
+		root = filepath.Clean(root)
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return s.ignoreFile(ctx, root)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
@@ -110,7 +111,7 @@ func TestScanFile(t *testing.T) {
 	ctx := context.WithLogger(context.Background(), logr.Discard())
 	go func() {
 		defer close(chunksChan)
-		err = source.scanFile(ctx, tmpfile.Name(), chunksChan)
+		err = source.scanFile(ctx, tmpfile.Name(), chunksChan, nil)
 		assert.Nil(t, err)
 	}()
 
@@ -233,6 +234,128 @@ func TestChunkUnit(t *testing.T) {
 	assert.Contains(t, dataFound, "baz")
 }
 
+func TestChunkUnitFilter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tmpdir, cleanup, err := createTempDir("", "small", strings.Repeat("large ", 100), "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	assert.NoError(t, err)
+	defer cleanup()
+
+	conn, err := anypb.New(&sourcespb.Filesystem{
+		Filter: &sourcespb.Filter{
+			MaxSize:          100,
+			ExcludeMimeTypes: []string{"image/*"},
+		},
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test chunk unit filter", 0, 0, true, conn, 1)
+	assert.NoError(t, err)
+
+	reporter := sourcestest.TestReporter{}
+	err = s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: tmpdir}, &reporter)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(reporter.Chunks))
+	assert.Equal(t, "small", string(reporter.Chunks[0].Data))
+}
+
+func TestChunkUnitIgnoreFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tmpdir := t.TempDir()
+	files := map[string]string{
+		ignorefile.Name:   "vendor/\n*.log\ndetector:AWS\n",
+		"main.txt":        "main",
+		"debug.log":       "debug",
+		"vendor/lib.txt":  "vendored",
+		"nested/note.txt": "note",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpdir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	conn, err := anypb.New(&sourcespb.Filesystem{})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test chunk unit ignore file", 0, 0, true, conn, 1)
+	assert.NoError(t, err)
+
+	reporter := sourcestest.TestReporter{}
+	err = s.ChunkUnit(ctx, sources.CommonSourceUnit{ID: tmpdir}, &reporter)
+	assert.NoError(t, err)
+
+	var scanned []string
+	for _, chunk := range reporter.Chunks {
+		scanned = append(scanned, string(chunk.Data))
+		assert.True(t, chunk.IgnoreFile.SuppressesDetector("AWS"))
+	}
+	assert.ElementsMatch(t, []string{files[ignorefile.Name], "main", "note"}, scanned)
+}
+
+func TestChunksWatch(t *testing.T) {
+	watchDebounce = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ignorefile.Name), []byte("vendor/\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("existing"), 0644))
+
+	conn, err := anypb.New(&sourcespb.Filesystem{Paths: []string{dir}, Watch: true})
+	assert.NoError(t, err)
+	s := Source{}
+	assert.NoError(t, s.Init(ctx, "test watch", 0, 0, true, conn, 1))
+
+	chunksCh := make(chan *sources.Chunk, 1)
+	done := make(chan error)
+	go func() { done <- s.Chunks(ctx, chunksCh) }()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case chunk := <-chunksCh:
+			return string(chunk.Data)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a chunk")
+			return ""
+		}
+	}
+	// The initial scan.
+	assert.ElementsMatch(t, []string{"vendor/\n", "existing"}, []string{next(), next()})
+
+	// New and modified files, including those in new directories, are
+	// scanned. Ignored ones aren't.
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "lib.txt"), []byte("vendored"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644))
+	assert.Equal(t, "new", next())
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("modified"), 0644))
+	assert.Equal(t, "modified", next())
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested", "deeper"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "deeper", "file.txt"), []byte("nested"), 0644))
+	assert.Equal(t, "nested", next())
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Chunks didn't return after the context was canceled")
+	}
+	select {
+	case chunk := <-chunksCh:
+		t.Fatalf("unexpected chunk %q", chunk.Data)
+	default:
+	}
+}
+
 func TestEnumerateReporterErr(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -0,0 +1,25 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// Validate checks that the configured paths exist.
+func (s *Source) Validate(ctx context.Context) []error {
+	var errs []error
+	for _, path := range s.paths {
+		if _, err := os.Lstat(filepath.Clean(path)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// HealthCheck checks that the paths still exist.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return errors.Join(s.Validate(ctx)...)
+}
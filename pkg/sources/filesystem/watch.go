@@ -0,0 +1,237 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// watchDebounce is how long a file must go without changes before it is
+// scanned, so a file that is being written is scanned once it is complete.
+var watchDebounce = 500 * time.Millisecond
+
+// watchRoot is one of the configured paths of a watching source.
+type watchRoot struct {
+	path string
+	dir  bool
+}
+
+// pathWatcher reports the files under the paths of a source that are
+// created or modified.
+type pathWatcher struct {
+	source  *Source
+	watcher *fsnotify.Watcher
+	roots   []watchRoot
+	// pending holds the time of the last event of each changed file that
+	// hasn't been scanned yet.
+	pending map[string]time.Time
+}
+
+// startWatching starts watching the paths of the source if it is configured
+// to, and returns nil otherwise. Watching starts before the paths are first
+// scanned, so no change is missed in between.
+func (s *Source) startWatching(ctx context.Context) (*pathWatcher, error) {
+	if !s.watch {
+		return nil, nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not watch paths: %w", err)
+	}
+	w := &pathWatcher{source: s, watcher: watcher, pending: make(map[string]time.Time)}
+	for _, path := range s.paths {
+		path = filepath.Clean(path)
+		info, err := os.Lstat(path)
+		if err != nil {
+			ctx.Logger().Error(err, "unable to watch path", "path", path)
+			continue
+		}
+		root := watchRoot{path: path, dir: info.IsDir()}
+		w.roots = append(w.roots, root)
+		if !root.dir {
+			// Editors often replace files instead of writing them, which
+			// only the directory sees.
+			if err := watcher.Add(filepath.Dir(path)); err != nil {
+				ctx.Logger().Error(err, "unable to watch path", "path", path)
+			}
+			continue
+		}
+		w.addTree(ctx, root, path, false)
+	}
+	return w, nil
+}
+
+// addTree watches dir and the directories below it. When markFiles is set,
+// the files found are scanned too, since they may have been written before
+// the directory was watched.
+func (w *pathWatcher) addTree(ctx context.Context, root watchRoot, dir string, markFiles bool) {
+	ignore := w.source.ignoreFile(ctx, root.path)
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			ctx.Logger().Error(err, "error walking directory")
+			return nil
+		}
+		rel, _ := filepath.Rel(root.path, path)
+		if ignore.Ignores(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case d.IsDir():
+			if err := w.watcher.Add(path); err != nil {
+				ctx.Logger().Error(err, "unable to watch directory", "path", path)
+			}
+		case markFiles && d.Type().IsRegular():
+			w.mark(path)
+		}
+		return nil
+	})
+}
+
+// close stops watching. It is safe to call on a nil pathWatcher.
+func (w *pathWatcher) close() {
+	if w != nil {
+		_ = w.watcher.Close()
+	}
+}
+
+// root returns the configured path that path is, or is below.
+func (w *pathWatcher) root(path string) (watchRoot, bool) {
+	for _, root := range w.roots {
+		if !root.dir {
+			if path == root.path {
+				return root, true
+			}
+			continue
+		}
+		rel, err := filepath.Rel(root.path, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return root, true
+		}
+	}
+	return watchRoot{}, false
+}
+
+func (w *pathWatcher) mark(path string) {
+	if w.source.filter != nil && !w.source.filter.Pass(path) {
+		return
+	}
+	w.pending[path] = time.Now()
+}
+
+func (w *pathWatcher) handle(ctx context.Context, event fsnotify.Event) {
+	path := filepath.Clean(event.Name)
+	root, ok := w.root(path)
+	if !ok {
+		return
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		delete(w.pending, path)
+		return
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+
+	if !root.dir {
+		if info.Mode().IsRegular() {
+			w.mark(path)
+		}
+		return
+	}
+	if path == filepath.Join(root.path, ignorefile.Name) {
+		w.source.forgetIgnoreFile(root.path)
+	}
+	rel, _ := filepath.Rel(root.path, path)
+	if w.source.ignoreFile(ctx, root.path).Ignores(filepath.ToSlash(rel), info.IsDir()) {
+		return
+	}
+	switch {
+	case info.IsDir():
+		if event.Has(fsnotify.Create) {
+			w.addTree(ctx, root, path, true)
+		}
+	case info.Mode().IsRegular():
+		w.mark(path)
+	}
+}
+
+// run passes every file that is created or modified to scan, along with the
+// ignore file of the directory it was found under, until ctx is done or scan
+// returns an error. It returns right away for a nil pathWatcher.
+func (w *pathWatcher) run(ctx context.Context, scan func(path string, ignore *ignorefile.File) error) error {
+	if w == nil {
+		return nil
+	}
+	ctx.Logger().Info("watching for changes", "paths", len(w.roots))
+
+	ticker := time.NewTicker(watchDebounce / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			w.handle(ctx, event)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			ctx.Logger().Error(err, "error watching paths")
+		case now := <-ticker.C:
+			for path, changed := range w.pending {
+				if now.Sub(changed) < watchDebounce {
+					continue
+				}
+				delete(w.pending, path)
+				var ignore *ignorefile.File
+				if root, ok := w.root(path); ok && root.dir {
+					ignore = w.source.ignoreFile(ctx, root.path)
+				}
+				ctx.Logger().V(2).Info("scanning changed file", "path", path)
+				if err := scan(path, ignore); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// forgetIgnoreFile drops the cached .trufflehogignore file of dir, so it is
+// loaded again after it changes.
+func (s *Source) forgetIgnoreFile(dir string) {
+	s.ignoreFilesMu.Lock()
+	defer s.ignoreFilesMu.Unlock()
+	delete(s.ignoreFiles, dir)
+}
+
+// changedFileScanner returns the scan function of pathWatcher.run that sends
+// the chunks of changed files to chunksChan. Errors scanning a file are only
+// logged, so a file that can't be read doesn't stop the watch.
+func (s *Source) changedFileScanner(ctx context.Context, chunksChan chan *sources.Chunk) func(string, *ignorefile.File) error {
+	return func(path string, ignore *ignorefile.File) error {
+		if err := s.scanFile(ctx, path, chunksChan, ignore); err != nil && !errors.Is(err, skipSymlinkErr) {
+			ctx.Logger().Error(err, "error scanning file", "path", path)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package sources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+// FilterOptions converts the filter rules of a source connection to options
+// for common.FilterFromFiles. A nil filter has no options.
+func FilterOptions(filter *sourcespb.Filter) []common.FilterOption {
+	if filter == nil {
+		return nil
+	}
+	return []common.FilterOption{
+		common.WithIncludePaths(filter.GetIncludePaths()...),
+		common.WithExcludePaths(filter.GetExcludePaths()...),
+		common.WithMaxSize(filter.GetMaxSize()),
+		common.WithIncludeMIMETypes(filter.GetIncludeMimeTypes()...),
+		common.WithExcludeMIMETypes(filter.GetExcludeMimeTypes()...),
+	}
+}
+
+// RepoInfo describes a repository found while listing those of an
+// organization, group or user, for RepoExclusions and RepoSelection to match.
+type RepoInfo struct {
+	Archived bool
+	Fork     bool
+	Topics   []string
+	// Language is the primary language of the repository.
+	Language string
+	PushedAt time.Time
+	// SizeKB is the size of the repository in kilobytes, or 0 if it isn't
+	// known.
+	SizeKB int64
+}
+
+// RepoExclusionReason returns why exclusions skip repo, or "" if it's
+// scanned. Topics are compared ignoring case.
+func RepoExclusionReason(exclusions *sourcespb.RepoExclusions, repo RepoInfo) string {
+	switch {
+	case exclusions.GetArchived() && repo.Archived:
+		return "archived"
+	case exclusions.GetForks() && repo.Fork:
+		return "fork"
+	}
+	for _, topic := range repo.Topics {
+		if containsFold(exclusions.GetTopics(), topic) {
+			return "topic " + topic
+		}
+	}
+	return ""
+}
+
+// RepoSelectionReason returns why repo doesn't match selection, or "" if it's
+// scanned. Topics and languages are compared ignoring case.
+func RepoSelectionReason(selection *sourcespb.RepoSelection, repo RepoInfo) string {
+	if topics := selection.GetTopics(); len(topics) > 0 && !slices.ContainsFunc(repo.Topics, func(topic string) bool {
+		return containsFold(topics, topic)
+	}) {
+		return "no selected topic"
+	}
+	if languages := selection.GetLanguages(); len(languages) > 0 && !containsFold(languages, repo.Language) {
+		if repo.Language == "" {
+			return "no language"
+		}
+		return "language " + repo.Language
+	}
+	if within := selection.GetPushedWithin(); within != nil && time.Since(repo.PushedAt) > within.AsDuration() {
+		if repo.PushedAt.IsZero() {
+			return "never pushed"
+		}
+		return "pushed " + repo.PushedAt.Format(time.DateOnly)
+	}
+	if limit := selection.GetMaxSizeKb(); limit > 0 && repo.SizeKB > limit {
+		return fmt.Sprintf("size %d KB", repo.SizeKB)
+	}
+	return ""
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+)
+
+func TestRepoExclusionReason(t *testing.T) {
+	exclusions := &sourcespb.RepoExclusions{Archived: true, Forks: true, Topics: []string{"demo"}}
+
+	tests := []struct {
+		name       string
+		exclusions *sourcespb.RepoExclusions
+		repo       RepoInfo
+		want       string
+	}{
+		{name: "scanned", exclusions: exclusions, repo: RepoInfo{Topics: []string{"prod"}}},
+		{name: "archived", exclusions: exclusions, repo: RepoInfo{Archived: true}, want: "archived"},
+		{name: "fork", exclusions: exclusions, repo: RepoInfo{Fork: true}, want: "fork"},
+		{name: "topic", exclusions: exclusions, repo: RepoInfo{Topics: []string{"prod", "Demo"}}, want: "topic Demo"},
+		{name: "no exclusions", repo: RepoInfo{Archived: true, Fork: true, Topics: []string{"demo"}}},
+		{name: "forks kept", exclusions: &sourcespb.RepoExclusions{Archived: true}, repo: RepoInfo{Fork: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RepoExclusionReason(tt.exclusions, tt.repo))
+		})
+	}
+}
+
+func TestRepoSelectionReason(t *testing.T) {
+	selection := &sourcespb.RepoSelection{
+		Topics:       []string{"service"},
+		Languages:    []string{"go"},
+		PushedWithin: durationpb.New(365 * 24 * time.Hour),
+		MaxSizeKb:    1024,
+	}
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	selected := RepoInfo{Topics: []string{"Service"}, Language: "Go", PushedAt: recent, SizeKB: 512}
+
+	tests := []struct {
+		name      string
+		selection *sourcespb.RepoSelection
+		repo      RepoInfo
+		want      string
+	}{
+		{name: "selected", selection: selection, repo: selected},
+		{name: "no selection", repo: RepoInfo{}},
+		{name: "topic", selection: selection, repo: RepoInfo{Topics: []string{"demo"}, Language: "Go", PushedAt: recent}, want: "no selected topic"},
+		{name: "language", selection: selection, repo: RepoInfo{Topics: []string{"service"}, Language: "Python", PushedAt: recent}, want: "language Python"},
+		{name: "no language", selection: selection, repo: RepoInfo{Topics: []string{"service"}, PushedAt: recent}, want: "no language"},
+		{name: "pushed", selection: selection, repo: RepoInfo{Topics: []string{"service"}, Language: "Go", PushedAt: old}, want: "pushed 2020-05-01"},
+		{name: "never pushed", selection: selection, repo: RepoInfo{Topics: []string{"service"}, Language: "Go"}, want: "never pushed"},
+		{name: "size", selection: selection, repo: RepoInfo{Topics: []string{"service"}, Language: "Go", PushedAt: recent, SizeKB: 2048}, want: "size 2048 KB"},
+		{name: "unknown size", selection: selection, repo: RepoInfo{Topics: []string{"service"}, Language: "Go", PushedAt: recent}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RepoSelectionReason(tt.selection, tt.repo))
+		})
+	}
+}
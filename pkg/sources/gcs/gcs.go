@@ -21,6 +21,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/cache/simple"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -56,6 +57,7 @@ func (s *Source) JobID() sources.JobID {
 type objectManager interface {
 	ListObjects(context.Context) (chan io.Reader, error)
 	Attributes(ctx context.Context) (*attributes, error)
+	Ping(ctx context.Context) error
 }
 
 // Source represents a GCS source.
@@ -139,6 +141,12 @@ func (s *Source) Init(aCtx context.Context, name string, id sources.JobID, sourc
 	return nil
 }
 
+// HealthCheck checks that the buckets can still be read with the source's
+// credentials.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return s.gcsManager.Ping(ctx)
+}
+
 func configureGCSManager(aCtx context.Context, conn *sourcespb.GCS, concurrency int) (*gcsManager, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("GCS connection is nil, cannot configure GCS manager")
@@ -274,6 +282,11 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 			ctx.Logger().V(5).Info("skipping object, object already processed", "name", o.name)
 			continue
 		}
+		objectURL := "gs://" + o.bucket + "/" + o.name
+		if incremental.FromContext(ctx).Unchanged(objectURL, o.md5) {
+			ctx.Logger().V(5).Info("skipping object, object unchanged since last scan", "name", o.name)
+			continue
+		}
 
 		wg.Add(1)
 		go func(obj object) {
@@ -283,6 +296,7 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, _ .
 				ctx.Logger().V(1).Info("error setting start progress progress", "name", o.name, "error", err)
 				return
 			}
+			incremental.FromContext(ctx).SetObject(objectURL, o.md5)
 			s.setProgress(ctx, o.md5, o.name, persistableCache)
 		}(o)
 	}
@@ -19,8 +19,8 @@ import (
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
-	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 )
 
@@ -386,6 +386,25 @@ func (g *gcsManager) Attributes(ctx context.Context) (*attributes, error) {
 	return g.enumerate(ctx, buckets)
 }
 
+// Ping checks that GCS can be read with the manager's credentials, by
+// listing the first bucket of the project, or without credentials the first
+// object of each bucket to include.
+func (g *gcsManager) Ping(ctx context.Context) error {
+	if g.withoutAuth {
+		for name := range g.includeBuckets {
+			if _, err := g.client.Bucket(name).Objects(ctx, nil).Next(); err != nil && !errors.Is(err, iterator.Done) {
+				return fmt.Errorf("failed to list objects of bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	if _, err := g.client.Buckets(ctx, g.projectID).Next(); err != nil && !errors.Is(err, iterator.Done) {
+		return fmt.Errorf("failed to list buckets: %w", err)
+	}
+	return nil
+}
+
 func (g *gcsManager) enumerate(ctx context.Context, bkts []bucket) (*attributes, error) {
 	logger := ctx.Logger().WithValues("phase", "enumeration")
 
@@ -664,7 +683,7 @@ func objectACLs(acl []storage.ACLRule) []string {
 }
 
 func isObjectTypeValid(ctx context.Context, name string) bool {
-	isValid := !common.SkipFile(name)
+	isValid := !handlers.SkipFile(ctx, name)
 	if !isValid {
 		ctx.Logger().V(2).Info("object type is invalid", "object-name", name)
 		return false
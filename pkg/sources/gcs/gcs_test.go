@@ -222,6 +222,13 @@ func (m *mockObjectManager) Attributes(_ context.Context) (*attributes, error) {
 	}, nil
 }
 
+func (m *mockObjectManager) Ping(_ context.Context) error {
+	if m.wantErr {
+		return fmt.Errorf("some error")
+	}
+	return nil
+}
+
 type mockReader struct {
 	offset int
 	data   []byte
@@ -355,6 +362,12 @@ func TestSourceInit_Enumerate(t *testing.T) {
 	assert.Equal(t, uint64(5), source.stats.bucketObjects[testBucket])
 }
 
+func TestSource_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, (&Source{gcsManager: &mockObjectManager{}}).HealthCheck(ctx))
+	assert.Error(t, (&Source{gcsManager: &mockObjectManager{wantErr: true}}).HealthCheck(ctx))
+}
+
 func TestSourceChunks_ListObjects_Error(t *testing.T) {
 	ctx := context.Background()
 	source := &Source{gcsManager: &mockObjectManager{wantErr: true}}
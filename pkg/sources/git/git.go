@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -19,7 +20,10 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-logr/logr"
+	"github.com/gobwas/glob"
 	"github.com/google/go-github/v66/github"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/protobuf/proto"
@@ -31,14 +35,39 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/feature"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/gitparse"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tracing"
 )
 
 const SourceType = sourcespb.SourceType_SOURCE_TYPE_GIT
 
+// ErrRepoTimeout is returned by ScanRepo when scanning a repository took
+// longer than the RepoTimeout of its ScanOptions.
+var ErrRepoTimeout = errors.New("repository scan timed out")
+
+// repoTimeoutKey is the context key of the timeout set by WithRepoTimeout.
+type repoTimeoutKey struct{}
+
+// WithRepoTimeout returns a copy of ctx in which ScanRepo spends at most
+// timeout on a repository whose ScanOptions don't set a RepoTimeout. The
+// timeout is scoped to the context so engines with different timeouts can
+// run in the same process.
+func WithRepoTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, repoTimeoutKey{}, timeout)
+}
+
+// DefaultRepoTimeout returns the timeout of repositories used in ctx when
+// their ScanOptions don't set one. Zero means no timeout.
+func DefaultRepoTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(repoTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
 type Source struct {
 	name     string
 	sourceID sources.SourceID
@@ -46,16 +75,24 @@ type Source struct {
 	verify   bool
 
 	useCustomContentWriter bool
+	logger                 logr.Logger
 	git                    *Git
 	scanOptions            *ScanOptions
 
 	sources.Progress
 	conn *sourcespb.Git
+	// ignoreRepos are the globs of the repositories and directories that
+	// aren't scanned.
+	ignoreRepos []glob.Glob
 }
 
 // WithCustomContentWriter sets the useCustomContentWriter flag on the source.
 func (s *Source) WithCustomContentWriter() { s.useCustomContentWriter = true }
 
+// WithLogger sets the logger the source logs to instead of the one of the
+// context it's run with.
+func (s *Source) WithLogger(logger logr.Logger) { s.logger = logger }
+
 type Git struct {
 	sourceType         sourcespb.SourceType
 	sourceName         string
@@ -67,6 +104,7 @@ type Git struct {
 	concurrency        *semaphore.Weighted
 	skipBinaries       bool
 	skipArchives       bool
+	logger             logr.Logger
 
 	parser *gitparse.Parser
 }
@@ -92,6 +130,10 @@ type Config struct {
 	// When set to true, the parser will use a custom contentWriter provided through the WithContentWriter option.
 	// When false, the parser will use the default buffer (in-memory) contentWriter.
 	UseCustomContentWriter bool
+
+	// Logger is logged to instead of the logger of the context scans are run
+	// with, if it's set.
+	Logger logr.Logger
 }
 
 // NewGit creates a new Git instance with the provided configuration. The Git instance is used to interact with
@@ -114,6 +156,7 @@ func NewGit(config *Config) *Git {
 		concurrency:        semaphore.NewWeighted(int64(config.Concurrency)),
 		skipBinaries:       config.SkipBinaries,
 		skipArchives:       config.SkipArchives,
+		logger:             config.Logger,
 		parser:             parser,
 	}
 }
@@ -159,6 +202,15 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 		return fmt.Errorf("error unmarshalling connection: %w", err)
 	}
 
+	s.ignoreRepos = nil
+	for _, pattern := range conn.GetIgnoreRepos() {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid ignore_repos glob %q: %w", pattern, err)
+		}
+		s.ignoreRepos = append(s.ignoreRepos, g)
+	}
+
 	if uri := conn.GetUri(); uri != "" {
 		repoPath, _, err := prepareRepoSinceCommit(aCtx, uri, conn.GetBase())
 		if err != nil || repoPath == "" {
@@ -167,7 +219,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 		conn.Directories = append(conn.Directories, repoPath)
 	}
 
-	filter, err := common.FilterFromFiles(conn.IncludePathsFile, conn.ExcludePathsFile)
+	filter, err := common.FilterFromFiles(conn.IncludePathsFile, conn.ExcludePathsFile, sources.FilterOptions(conn.GetFilter())...)
 	if err != nil {
 		return fmt.Errorf("error creating filter: %w", err)
 	}
@@ -189,6 +241,9 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 	if isBare := conn.GetBare(); isBare {
 		opts = append(opts, ScanOptionBare(isBare))
 	}
+	if remote := conn.GetRemote(); remote != "" {
+		opts = append(opts, ScanOptionRemoteName(remote))
+	}
 	s.withScanOptions(NewScanOptions(opts...))
 
 	s.conn = &conn
@@ -225,6 +280,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobId sources.JobID, so
 			}
 		},
 		UseCustomContentWriter: s.useCustomContentWriter,
+		Logger:                 s.logger,
 	}
 	s.git = NewGit(cfg)
 	return nil
@@ -257,7 +313,7 @@ func (s *Source) scanRepos(ctx context.Context, reporter sources.ChunkReporter)
 	totalRepos := len(s.conn.Repositories) + len(s.conn.Directories)
 	for i, repoURI := range s.conn.Repositories {
 		s.SetProgressComplete(i, totalRepos, fmt.Sprintf("Repo: %s", repoURI), "")
-		if len(repoURI) == 0 {
+		if len(repoURI) == 0 || s.ignored(ctx, repoURI) {
 			continue
 		}
 		if err := s.scanRepo(ctx, repoURI, reporter); err != nil {
@@ -268,8 +324,21 @@ func (s *Source) scanRepos(ctx context.Context, reporter sources.ChunkReporter)
 	return nil
 }
 
+// ignored reports whether the repository or directory matches an ignore_repos
+// glob of the connection.
+func (s *Source) ignored(ctx context.Context, repo string) bool {
+	for _, g := range s.ignoreRepos {
+		if g.Match(repo) {
+			ctx.Logger().V(2).Info("skipping repository", "repo", repo, "reason", "ignored in config")
+			return true
+		}
+	}
+	return false
+}
+
 // scanRepo scans a single provided repository.
 func (s *Source) scanRepo(ctx context.Context, repoURI string, reporter sources.ChunkReporter) error {
+	ctx = repoContext(withLogger(ctx, s.logger), repoURI)
 	var cloneFunc func() (string, *git.Repository, error)
 	switch cred := s.conn.GetCredential().(type) {
 	case *sourcespb.Git_BasicAuth:
@@ -290,12 +359,18 @@ func (s *Source) scanRepo(ctx context.Context, repoURI string, reporter sources.
 		return errors.New("invalid connection type for git source")
 	}
 
+	progress := s.ProgressRepo(repoURI)
+	defer progress.Finish()
+	reporter = progress.Reporter(reporter)
+
 	err := func() error {
+		progress.SetStage(sources.StageCloning)
 		path, repo, err := cloneFunc()
 		defer os.RemoveAll(path)
 		if err != nil {
 			return err
 		}
+		progress.SetStage(sources.StageScanning)
 		return s.git.ScanRepo(ctx, repo, path, s.scanOptions, reporter)
 	}()
 	if err != nil {
@@ -304,13 +379,33 @@ func (s *Source) scanRepo(ctx context.Context, repoURI string, reporter sources.
 	return nil
 }
 
+// withLogger returns ctx logging to logger, if it's set.
+func withLogger(ctx context.Context, logger logr.Logger) context.Context {
+	if logger.GetSink() == nil {
+		return ctx
+	}
+	return context.WithLogger(ctx, logger)
+}
+
+// repoContext returns ctx with the repository it scans added to its log
+// values, without the password of its URL, unless ctx already has one.
+func repoContext(ctx context.Context, repo string) context.Context {
+	if ctx.Value("repo") != nil {
+		return ctx
+	}
+	if safeURL, _, err := stripPassword(repo); err == nil && safeURL != "" {
+		repo = safeURL
+	}
+	return context.WithValue(ctx, "repo", repo)
+}
+
 // scanDirs scans the configured directories in s.conn.Directories.
 func (s *Source) scanDirs(ctx context.Context, reporter sources.ChunkReporter) error {
 	totalRepos := len(s.conn.Repositories) + len(s.conn.Directories)
 	for i, gitDir := range s.conn.Directories {
 		s.SetProgressComplete(len(s.conn.Repositories)+i, totalRepos, fmt.Sprintf("Repo: %s", gitDir), "")
 
-		if len(gitDir) == 0 {
+		if len(gitDir) == 0 || s.ignored(ctx, gitDir) {
 			continue
 		}
 		if err := s.scanDir(ctx, gitDir, reporter); err != nil {
@@ -323,24 +418,27 @@ func (s *Source) scanDirs(ctx context.Context, reporter sources.ChunkReporter) e
 
 // scanDir scans a single provided directory.
 func (s *Source) scanDir(ctx context.Context, gitDir string, reporter sources.ChunkReporter) error {
+	ctx = withLogger(ctx, s.logger)
 	if !s.scanOptions.Bare && strings.HasSuffix(gitDir, "git") {
 		// TODO: Figure out why we skip directories ending in "git".
 		return nil
 	}
+	progress := s.ProgressRepo(gitDir)
+	defer progress.Finish()
+	reporter = progress.Reporter(reporter)
+	progress.SetStage(sources.StageScanning)
+
+	// Repositories cloned by Init are removed even if they can't be scanned.
+	if strings.HasPrefix(gitDir, filepath.Join(os.TempDir(), "trufflehog")) {
+		defer os.RemoveAll(gitDir)
+	}
+
 	// try paths instead of url
 	repo, err := RepoFromPath(gitDir, s.scanOptions.Bare)
 	if err != nil {
 		return reporter.ChunkErr(ctx, err)
 	}
-
-	err = func() error {
-		if strings.HasPrefix(gitDir, filepath.Join(os.TempDir(), "trufflehog")) {
-			defer os.RemoveAll(gitDir)
-		}
-
-		return s.git.ScanRepo(ctx, repo, gitDir, s.scanOptions, reporter)
-	}()
-	if err != nil {
+	if err := s.git.ScanRepo(ctx, repo, gitDir, s.scanOptions, reporter); err != nil {
 		return reporter.ChunkErr(ctx, err)
 	}
 	return nil
@@ -388,7 +486,11 @@ type cloneParams struct {
 // infrastructure, ensuring that any encountered errors trigger a cleanup of resources.
 // The core cloning logic is delegated to a nested function, which returns errors to the
 // outer function for centralized error handling and cleanup.
-func CloneRepo(ctx context.Context, userInfo *url.Userinfo, gitURL string, args ...string) (string, *git.Repository, error) {
+func CloneRepo(ctx context.Context, userInfo *url.Userinfo, gitURL string, args ...string) (_ string, _ *git.Repository, err error) {
+	repoURL, _, _ := stripPassword(gitURL)
+	ctx, span := tracing.StartSpan(ctx, "git.clone", attribute.String("repository", repoURL))
+	defer func() { tracing.End(span, err) }()
+
 	clonePath, err := cleantemp.MkdirTemp()
 	if err != nil {
 		return "", nil, err
@@ -428,7 +530,7 @@ func executeClone(ctx context.Context, params cloneParams) (*git.Repository, err
 			"remote.origin.fetch=+refs/*:refs/remotes/origin/*")
 	}
 	gitArgs = append(gitArgs, params.args...)
-	cloneCmd := exec.Command("git", gitArgs...)
+	cloneCmd := exec.CommandContext(ctx, "git", gitArgs...)
 
 	safeURL, secretForRedaction, err := stripPassword(params.gitURL)
 	if err != nil {
@@ -436,10 +538,12 @@ func executeClone(ctx context.Context, params cloneParams) (*git.Repository, err
 	}
 	logger := ctx.Logger().WithValues(
 		"subcommand", "git clone",
-		"repo", safeURL,
 		"path", params.clonePath,
 		"args", params.args,
 	)
+	if ctx.Value("repo") == nil {
+		logger = logger.WithValues("repo", safeURL)
+	}
 
 	// Execute command and wait for the stdout / stderr.
 	outputBytes, err := cloneCmd.CombinedOutput()
@@ -474,9 +578,36 @@ func executeClone(ctx context.Context, params cloneParams) (*git.Repository, err
 
 // PingRepoUsingToken executes git ls-remote on a repo and returns any error that occurs. It can be used to validate
 // that a repo actually exists and is reachable.
-//
-// Pinging using other authentication methods is only unimplemented because there's been no pressing need for it yet.
 func PingRepoUsingToken(ctx context.Context, token, gitUrl, user string) error {
+	return pingRepo(ctx, url.UserPassword(user, token), gitUrl)
+}
+
+// PingURI checks that the repository of a URI as accepted by PrepareRepo can
+// be read, without cloning it.
+func PingURI(ctx context.Context, uriString string) error {
+	uri, err := GitURLParse(uriString)
+	if err != nil {
+		return fmt.Errorf("unable to parse Git URI: %s", err)
+	}
+	switch uri.Scheme {
+	case "file":
+		_, err := RepoFromPath(uri.Host+uri.Path, false)
+		return err
+	case "http", "https":
+		return pingRepo(ctx, nil, uriString)
+	case "ssh":
+		if isCodeCommitURL(uriString) {
+			return pingRepo(ctx, nil, uriString)
+		}
+		return pingRepo(ctx, url.User("git"), uriString)
+	default:
+		return fmt.Errorf("unsupported Git URI: %s", uriString)
+	}
+}
+
+// pingRepo lists a ref of the remote at gitUrl, using userInfo unless the URL
+// has its own.
+func pingRepo(ctx context.Context, userInfo *url.Userinfo, gitUrl string) error {
 	if err := CmdCheck(); err != nil {
 		return err
 	}
@@ -485,7 +616,7 @@ func PingRepoUsingToken(ctx context.Context, token, gitUrl, user string) error {
 		return err
 	}
 	if lsUrl.User == nil {
-		lsUrl.User = url.UserPassword(user, token)
+		lsUrl.User = userInfo
 	}
 
 	// We don't actually care about any refs on the remote, we just care whether can can list them at all. So we query
@@ -493,9 +624,16 @@ func PingRepoUsingToken(ctx context.Context, token, gitUrl, user string) error {
 	// with 0 even if it doesn't find any matching refs.)
 	fakeRef := "TRUFFLEHOG_CHECK_GIT_REMOTE_URL_REACHABILITY"
 	gitArgs := []string{"ls-remote", lsUrl.String(), "--quiet", fakeRef}
-	cmd := exec.Command("git", gitArgs...)
-	_, err = cmd.CombinedOutput()
-	return err
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
+	// Fail rather than prompt for missing credentials.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
 }
 
 // CloneRepoUsingToken clones a repo using a provided token.
@@ -531,29 +669,50 @@ const gitDirName = ".git"
 // getGitDir returns the likely path of the ".git" directory.
 // If the repository is bare, it will be at the top-level; otherwise, it
 // exists in the ".git" directory at the root of the working tree.
+// Linked worktrees and submodules have a ".git" file there instead, which
+// points to their git directory.
 //
 // See: https://git-scm.com/docs/gitrepository-layout#_description
 func getGitDir(path string, options *ScanOptions) string {
 	if options.Bare {
 		return path
 	} else {
-		return filepath.Join(path, gitDirName)
+		return resolveGitDir(filepath.Join(path, gitDirName))
 	}
 }
 
-func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
-	// Get the remote URL for reporting (may be empty)
-	remoteURL := getSafeRemoteURL(repo, "origin")
-	var repoCtx context.Context
+// resolveGitDir follows dotGit if it's a "gitdir: PATH" file rather than a
+// directory. Relative paths in the file are relative to the directory it is in.
+func resolveGitDir(dotGit string) string {
+	info, err := os.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return dotGit
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(dotGit), dir)
+	}
+	return filepath.Clean(dir)
+}
 
+func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
+	ctx = withLogger(ctx, s.logger)
+	if scanOptions.ignoreFile != nil {
+		reporter = ignoreFileReporter{reporter, scanOptions.ignoreFile}
+	}
+	// Get the remote URL for reporting, or the local path without one.
+	remoteURL := repositoryID(repo, path, scanOptions)
+	repoCtx := ctx
 	if ctx.Value("repo") == nil {
-		if remoteURL != "" {
-			repoCtx = context.WithValue(ctx, "repo", remoteURL)
-		} else {
-			repoCtx = context.WithValue(ctx, "repo", path)
-		}
-	} else {
-		repoCtx = ctx
+		repoCtx = context.WithValue(ctx, "repo", remoteURL)
 	}
 
 	logger := repoCtx.Logger()
@@ -568,6 +727,9 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 		logValues = append(logValues, "max_depth", scanOptions.MaxDepth)
 	}
 
+	// Stopping early, such as at the max depth, kills git and stops the parser.
+	repoCtx, cancel := context.WithCancel(repoCtx)
+	defer cancel()
 	diffChan, err := s.parser.RepoPath(repoCtx, path, scanOptions.HeadHash, scanOptions.BaseHash == "", scanOptions.ExcludeGlobs, scanOptions.Bare)
 	if err != nil {
 		return err
@@ -585,6 +747,9 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 	)
 
 	for diff := range diffChan {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if scanOptions.MaxDepth > 0 && depth >= scanOptions.MaxDepth {
 			logger.V(1).Info("reached max depth", "depth", depth)
 			break
@@ -636,7 +801,7 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			continue
 		}
 
-		if !scanOptions.Filter.Pass(fileName) {
+		if !scanOptions.Filter.Pass(fileName) || scanOptions.ignoreFile.Ignores(fileName, false) {
 			continue
 		}
 
@@ -653,7 +818,7 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			}
 
 			commitHash := plumbing.NewHash(fullHash)
-			if err := s.handleBinary(ctx, gitDir, reporter, chunkSkel, commitHash, fileName); err != nil {
+			if err := s.handleBinary(ctx, gitDir, reporter, chunkSkel, commitHash, fileName, scanOptions.Filter); err != nil {
 				logger.Error(
 					err,
 					"error handling binary file",
@@ -665,8 +830,13 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			continue
 		}
 
-		if diff.Len() > sources.ChunkSize+sources.PeekSize {
-			s.gitChunk(ctx, diff, fileName, email, fullHash, when, remoteURL, reporter)
+		if !scanOptions.Filter.PassSize(int64(diff.Len())) {
+			logger.V(5).Info("skipping diff larger than the max size", "filename", fileName, "size", diff.Len())
+			continue
+		}
+
+		if diff.Len() > sources.DefaultChunkSize(ctx)+sources.DefaultPeekSize(ctx) {
+			s.gitChunk(ctx, diff, fileName, email, fullHash, when, remoteURL, reporter, scanOptions.Filter)
 			continue
 		}
 
@@ -685,8 +855,18 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 			}
 			defer reader.Close()
 
-			data := make([]byte, d.Len())
+			chunk := sources.Chunk{
+				SourceName:     s.sourceName,
+				SourceID:       s.sourceID,
+				JobID:          s.jobID,
+				SourceType:     s.sourceType,
+				SourceMetadata: metadata,
+				Verify:         s.verify,
+			}
+			// Most diffs are small, so their data is pooled.
+			data := chunk.AllocData(d.Len())
 			if _, err := io.ReadFull(reader, data); err != nil {
+				chunk.FreeData()
 				ctx.Logger().Error(
 					err, "error reading diff content for commit",
 					"filename", fileName,
@@ -695,14 +875,9 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 				)
 				return nil
 			}
-			chunk := sources.Chunk{
-				SourceName:     s.sourceName,
-				SourceID:       s.sourceID,
-				JobID:          s.jobID,
-				SourceType:     s.sourceType,
-				SourceMetadata: metadata,
-				Data:           data,
-				Verify:         s.verify,
+			if !scanOptions.Filter.PassContent(data) {
+				chunk.FreeData()
+				return nil
 			}
 			return reporter.ChunkOk(ctx, chunk)
 		}
@@ -713,7 +888,7 @@ func (s *Git) ScanCommits(ctx context.Context, repo *git.Repository, path string
 	return nil
 }
 
-func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email, hash, when, urlMetadata string, reporter sources.ChunkReporter) {
+func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email, hash, when, urlMetadata string, reporter sources.ChunkReporter, filter *common.Filter) {
 	reader, err := diff.ReadCloser()
 	if err != nil {
 		ctx.Logger().Error(err, "error creating reader for chunk", "filename", fileName, "commit", hash, "file", diff.PathB)
@@ -721,14 +896,20 @@ func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email
 	}
 	defer reader.Close()
 
-	originalChunk := bufio.NewScanner(reader)
+	content, pass := filter.PassReader(reader)
+	if !pass {
+		return
+	}
+
+	chunkSize, peekSize := sources.DefaultChunkSize(ctx), sources.DefaultPeekSize(ctx)
+	originalChunk := bufio.NewScanner(content)
 	newChunkBuffer := bytes.Buffer{}
 	lastOffset := 0
 	for offset := 0; originalChunk.Scan(); offset++ {
 		line := make([]byte, len(originalChunk.Bytes())+1)
 		copy(line, originalChunk.Bytes())
 		line[len(line)-1] = byte('\n')
-		if len(line) > sources.ChunkSize || len(line)+newChunkBuffer.Len() > sources.ChunkSize {
+		if len(line) > chunkSize || len(line)+newChunkBuffer.Len() > chunkSize {
 			// Add oversize chunk info
 			if newChunkBuffer.Len() > 0 {
 				// Send the existing fragment.
@@ -747,10 +928,17 @@ func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email
 					return
 				}
 
+				overlap := overlapLines(newChunkBuffer.Bytes(), peekSize)
 				newChunkBuffer.Reset()
 				lastOffset = offset
+				if len(line) <= chunkSize {
+					// Start the next fragment with the last lines of this one, so
+					// secrets split between them are still found.
+					newChunkBuffer.Write(overlap)
+					lastOffset -= bytes.Count(overlap, []byte("\n"))
+				}
 			}
-			if len(line) > sources.ChunkSize {
+			if len(line) > chunkSize {
 				// Send the oversize line.
 				metadata := s.sourceMetadataFunc(fileName, email, hash, when, urlMetadata, int64(diff.LineStart+offset))
 				chunk := sources.Chunk{
@@ -793,11 +981,32 @@ func (s *Git) gitChunk(ctx context.Context, diff *gitparse.Diff, fileName, email
 	}
 }
 
+// overlapLines returns a copy of the whole lines at the end of data that fit
+// in size bytes.
+func overlapLines(data []byte, size int) []byte {
+	if len(data) > size {
+		tail := data[len(data)-size:]
+		// Drop the partial line the tail starts in, unless it ends there.
+		if data[len(data)-size-1] != '\n' {
+			i := bytes.IndexByte(tail, '\n')
+			tail = tail[i+1:]
+		}
+		data = tail
+	}
+	return append([]byte(nil), data...)
+}
+
 // ScanStaged chunks staged changes.
 func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string, scanOptions *ScanOptions, reporter sources.ChunkReporter) error {
-	// Get the URL metadata for reporting (may be empty).
-	urlMetadata := getSafeRemoteURL(repo, "origin")
+	ctx = withLogger(ctx, s.logger)
+	if scanOptions.ignoreFile != nil {
+		reporter = ignoreFileReporter{reporter, scanOptions.ignoreFile}
+	}
+	// Get the URL metadata for reporting, or the local path without a remote.
+	urlMetadata := repositoryID(repo, path, scanOptions)
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	diffChan, err := s.parser.Staged(ctx, path)
 	if err != nil {
 		return err
@@ -828,6 +1037,9 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 		lastCommitHash string
 	)
 	for diff := range diffChan {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		fullHash := diff.Commit.Hash
 		logger := ctx.Logger().WithValues("filename", diff.PathB, "commit", fullHash, "file", diff.PathB)
 		logger.V(2).Info("scanning staged changes from git")
@@ -852,7 +1064,7 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 			reachedBase = true
 		}
 
-		if !scanOptions.Filter.Pass(diff.PathB) {
+		if !scanOptions.Filter.Pass(diff.PathB) || scanOptions.ignoreFile.Ignores(diff.PathB, false) {
 			continue
 		}
 
@@ -876,12 +1088,17 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 				SourceMetadata: metadata,
 				Verify:         s.verify,
 			}
-			if err := s.handleBinary(ctx, gitDir, reporter, chunkSkel, commitHash, fileName); err != nil {
+			if err := s.handleBinary(ctx, gitDir, reporter, chunkSkel, commitHash, fileName, scanOptions.Filter); err != nil {
 				logger.Error(err, "error handling binary file", "filename", fileName)
 			}
 			continue
 		}
 
+		if !scanOptions.Filter.PassSize(int64(diff.Len())) {
+			logger.V(5).Info("skipping diff larger than the max size", "size", diff.Len())
+			continue
+		}
+
 		chunkData := func(d *gitparse.Diff) error {
 			metadata := s.sourceMetadataFunc(fileName, email, "Staged", when, urlMetadata, int64(diff.LineStart))
 
@@ -907,6 +1124,9 @@ func (s *Git) ScanStaged(ctx context.Context, repo *git.Repository, path string,
 				)
 				return nil
 			}
+			if !scanOptions.Filter.PassContent(data) {
+				return nil
+			}
 			chunk := sources.Chunk{
 				SourceName:     s.sourceName,
 				SourceID:       s.sourceID,
@@ -929,14 +1149,45 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	if scanOptions == nil {
 		scanOptions = NewScanOptions()
 	}
+	ctx = withLogger(ctx, s.logger)
+	repoTimeout := scanOptions.RepoTimeout
+	if repoTimeout == 0 {
+		repoTimeout = DefaultRepoTimeout(ctx)
+	}
+	var timedOut error
+	if repoTimeout > 0 {
+		timedOut = fmt.Errorf("%w after %s", ErrRepoTimeout, repoTimeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, repoTimeout, timedOut)
+		defer cancel()
+	}
+	stateKey, scanOptions := incrementalScanOptions(ctx, repo, repoPath, scanOptions)
 	if err := normalizeConfig(scanOptions, repo); err != nil {
 		return err
 	}
+	ignore, err := loadIgnoreFile(repo, repoPath, scanOptions)
+	if err != nil {
+		// A broken ignore file shouldn't stop the scan, only its suppressions.
+		ctx.Logger().Error(err, "could not load "+ignorefile.Name)
+	}
+	if ignore != nil {
+		opts := *scanOptions
+		opts.ignoreFile = ignore
+		scanOptions = &opts
+	}
 	start := time.Now().Unix()
 
-	if err := s.ScanCommits(ctx, repo, repoPath, scanOptions, reporter); err != nil {
+	err = s.ScanCommits(ctx, repo, repoPath, scanOptions, reporter)
+	if timedOut != nil && context.Cause(ctx) == timedOut {
+		return timedOut
+	}
+	if err != nil {
 		return err
 	}
+	// Histories that weren't scanned to the end are scanned again next time.
+	if stateKey != "" && ctx.Err() == nil {
+		recordScannedHead(ctx, repo, stateKey, scanOptions)
+	}
 	if !scanOptions.Bare {
 		if err := s.ScanStaged(ctx, repo, repoPath, scanOptions, reporter); err != nil {
 			ctx.Logger().V(1).Info("error scanning unstaged changes", "error", err)
@@ -948,12 +1199,7 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	// To make this duration logging useful, we need to log the remote as well.
 	// Other sources may have included this info to the context, in which case we don't need to add it again.
 	if ctx.Value("repo") == nil {
-		remotes, _ := repo.Remotes()
-		repoURL := "Could not get remote for repo"
-		if len(remotes) != 0 {
-			repoURL = getSafeRemoteURL(repo, remotes[0].Config().Name)
-		}
-		logger = logger.WithValues("repo", repoURL)
+		logger = logger.WithValues("repo", repositoryID(repo, repoPath, scanOptions))
 	}
 
 	scanTime := time.Now().Unix() - start
@@ -966,6 +1212,100 @@ func (s *Git) ScanRepo(ctx context.Context, repo *git.Repository, repoPath strin
 	return nil
 }
 
+// incrementalScanOptions returns the key the repository's last scanned commit
+// is stored under and the options to scan it with. If incremental scanning is
+// enabled and the repository was scanned before, the returned options start
+// from the previously scanned commit. Scans limited by a base commit or depth
+// are never recorded, as they don't cover the full history.
+func incrementalScanOptions(ctx context.Context, repo *git.Repository, repoPath string, scanOptions *ScanOptions) (string, *ScanOptions) {
+	state := incremental.FromContext(ctx)
+	if state == nil || scanOptions.BaseHash != "" || scanOptions.MaxDepth > 0 {
+		return "", scanOptions
+	}
+	key := repositoryID(repo, repoPath, scanOptions)
+
+	last := state.LastCommit(key)
+	if last == "" {
+		return key, scanOptions
+	}
+	// History may have been rewritten since the last scan, in which case the
+	// whole repository is scanned again.
+	if _, err := repo.CommitObject(plumbing.NewHash(last)); err != nil {
+		ctx.Logger().V(2).Info("last scanned commit not found, scanning full history", "repo", key, "commit", last)
+		return key, scanOptions
+	}
+	ctx.Logger().V(2).Info("scanning commits since last scan", "repo", key, "commit", last)
+	opts := *scanOptions
+	opts.BaseHash = last
+	return key, &opts
+}
+
+// loadIgnoreFile reads the .trufflehogignore file at the root of the
+// repository, from the working tree if it is scanned, and from the head
+// commit otherwise. It returns nil if there is none.
+func loadIgnoreFile(repo *git.Repository, repoPath string, scanOptions *ScanOptions) (*ignorefile.File, error) {
+	if !scanOptions.Bare && scanOptions.HeadHash == "" {
+		ignore, err := ignorefile.Load(repoPath)
+		if ignore != nil || err != nil {
+			return ignore, err
+		}
+	}
+
+	rev := scanOptions.HeadHash
+	if rev == "" {
+		rev = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		// The repository has no commits.
+		return nil, nil
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(ignorefile.Name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ignorefile.Parse(r)
+}
+
+// ignoreFileReporter attaches the .trufflehogignore file of the repository to
+// the chunks it reports, so the engine can apply its suppressions.
+type ignoreFileReporter struct {
+	sources.ChunkReporter
+	ignoreFile *ignorefile.File
+}
+
+func (r ignoreFileReporter) ChunkOk(ctx context.Context, chunk sources.Chunk) error {
+	chunk.IgnoreFile = r.ignoreFile
+	return r.ChunkReporter.ChunkOk(ctx, chunk)
+}
+
+// recordScannedHead stores the commit a finished scan of the repository
+// started from, so the next incremental scan begins there.
+func recordScannedHead(ctx context.Context, repo *git.Repository, key string, scanOptions *ScanOptions) {
+	head := scanOptions.HeadHash
+	if head == "" {
+		ref, err := repo.Head()
+		if err != nil {
+			ctx.Logger().V(2).Info("could not resolve HEAD, not recording scanned commit", "repo", key, "error", err)
+			return
+		}
+		head = ref.Hash().String()
+	}
+	incremental.FromContext(ctx).SetLastCommit(key, head)
+}
+
 // normalizeConfig updates scanOptions with the resolved base and head commit hashes.
 // It's designed to handle scenarios where BaseHash and HeadHash in scanOptions might be branch names or
 // other non-hash references. This ensures that both the base and head commits are resolved to actual commit hashes.
@@ -1226,11 +1566,29 @@ func getSafeRemoteURL(repo *git.Repository, preferred string) string {
 	return safeURL
 }
 
-func (s *Git) handleBinary(ctx context.Context, gitDir string, reporter sources.ChunkReporter, chunkSkel *sources.Chunk, commitHash plumbing.Hash, path string) error {
+// repositoryID identifies the repository at path by the URL of the scan's
+// remote, or of its first remote if it has no such remote. Repositories
+// without remotes, such as ones just created with git init, are identified by
+// their absolute local path.
+func repositoryID(repo *git.Repository, path string, scanOptions *ScanOptions) string {
+	remote := scanOptions.RemoteName
+	if remote == "" {
+		remote = "origin"
+	}
+	if remoteURL := getSafeRemoteURL(repo, remote); remoteURL != "" {
+		return remoteURL
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func (s *Git) handleBinary(ctx context.Context, gitDir string, reporter sources.ChunkReporter, chunkSkel *sources.Chunk, commitHash plumbing.Hash, path string, filter *common.Filter) error {
 	fileCtx := context.WithValues(ctx, "commit", commitHash.String()[:7], "path", path)
 	fileCtx.Logger().V(5).Info("handling binary file")
 
-	if common.SkipFile(path) {
+	if handlers.SkipFile(fileCtx, path) {
 		fileCtx.Logger().V(5).Info("file contains ignored extension")
 		return nil
 	}
@@ -1240,7 +1598,23 @@ func (s *Git) handleBinary(ctx context.Context, gitDir string, reporter sources.
 		return nil
 	}
 
-	cmd := exec.Command("git", "-C", gitDir, "cat-file", "blob", commitHash.String()+":"+path)
+	object := commitHash.String() + ":" + path
+	if filter.MaxSize() > 0 {
+		out, err := exec.CommandContext(ctx, "git", "-C", gitDir, "cat-file", "-s", object).Output()
+		if err != nil {
+			return fmt.Errorf("error getting size with git cat-file: %w", err)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing size from git cat-file: %w", err)
+		}
+		if !filter.PassSize(size) {
+			fileCtx.Logger().V(5).Info("skipping binary file larger than the max size", "size", size)
+			return nil
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", gitDir, "cat-file", "blob", object)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
@@ -1255,12 +1629,20 @@ func (s *Git) handleBinary(ctx context.Context, gitDir string, reporter sources.
 
 	defer func() { _ = cmd.Wait() }()
 
-	return handlers.HandleFile(ctx, stdout, chunkSkel, reporter, handlers.WithSkipArchives(s.skipArchives))
+	content, pass := filter.PassReader(stdout)
+	if !pass {
+		fileCtx.Logger().V(5).Info("skipping binary file with excluded content type")
+		// Nothing reads the rest of the blob, so git would block on a full pipe.
+		_ = cmd.Process.Kill()
+		return nil
+	}
+
+	return handlers.HandleFile(ctx, content, chunkSkel, reporter, handlers.WithSkipArchives(s.skipArchives))
 }
 
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
 	for _, repo := range s.conn.GetDirectories() {
-		if repo == "" {
+		if repo == "" || s.ignored(ctx, repo) {
 			continue
 		}
 		unit := SourceUnit{ID: repo, Kind: UnitDir}
@@ -1269,7 +1651,7 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 		}
 	}
 	for _, repo := range s.conn.GetRepositories() {
-		if repo == "" {
+		if repo == "" || s.ignored(ctx, repo) {
 			continue
 		}
 		unit := SourceUnit{ID: repo, Kind: UnitRepo}
@@ -1280,6 +1662,47 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 	return nil
 }
 
+// Validate checks that the configured repositories can be read with the
+// configured credential, and that the configured directories are
+// repositories. Nothing is cloned.
+func (s *Source) Validate(ctx context.Context) []error {
+	var errs []error
+	for _, dir := range s.conn.GetDirectories() {
+		if dir == "" {
+			continue
+		}
+		if _, err := RepoFromPath(dir, s.conn.GetBare()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dir, err))
+		}
+	}
+	for _, repoURI := range s.conn.GetRepositories() {
+		if repoURI == "" {
+			continue
+		}
+		var userInfo *url.Userinfo
+		switch cred := s.conn.GetCredential().(type) {
+		case *sourcespb.Git_BasicAuth:
+			userInfo = url.UserPassword(cred.BasicAuth.Username, cred.BasicAuth.Password)
+		case *sourcespb.Git_Unauthenticated:
+		case *sourcespb.Git_SshAuth:
+			if !isCodeCommitURL(repoURI) {
+				userInfo = url.User("git")
+			}
+		default:
+			return append(errs, errors.New("invalid connection type for git source"))
+		}
+		if err := pingRepo(ctx, userInfo, repoURI); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repoURI, err))
+		}
+	}
+	return errs
+}
+
+// HealthCheck checks the repositories and directories like Validate.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return errors.Join(s.Validate(ctx)...)
+}
+
 func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporter sources.ChunkReporter) error {
 	unitID, kind := unit.SourceUnitID()
 
@@ -3,15 +3,25 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
@@ -566,6 +576,60 @@ func TestEnumerate(t *testing.T) {
 	}
 }
 
+func TestEnumerate_IgnoreRepos(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	conn, err := anypb.New(&sourcespb.Git{
+		Repositories: []string{"https://example.com/org/app.git", "https://example.com/org/demo-1.git"},
+		Directories:  []string{"/src/app/", "/src/archive/old/"},
+		IgnoreRepos:  []string{"*/demo-*", "/src/archive/**"},
+	})
+	assert.NoError(t, err)
+
+	s := Source{}
+	err = s.Init(ctx, "test enumerate", 0, 0, true, conn, 1)
+	assert.NoError(t, err)
+
+	reporter := sourcestest.TestReporter{}
+	err = s.Enumerate(ctx, &reporter)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []sources.SourceUnit{
+		SourceUnit{ID: "https://example.com/org/app.git", Kind: UnitRepo},
+		SourceUnit{ID: "/src/app/", Kind: UnitDir},
+	}, reporter.Units)
+
+	conn, err = anypb.New(&sourcespb.Git{IgnoreRepos: []string{"[demo"}})
+	assert.NoError(t, err)
+	assert.Error(t, (&Source{}).Init(ctx, "test enumerate", 0, 0, true, conn, 1))
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	out, err := exec.Command("git", "init", repoPath).CombinedOutput()
+	require.NoError(t, err, string(out))
+	notRepo := t.TempDir()
+
+	conn, err := anypb.New(&sourcespb.Git{
+		Credential:   &sourcespb.Git_Unauthenticated{},
+		Repositories: []string{"file://" + repoPath, "file://" + notRepo},
+		Directories:  []string{repoPath, notRepo},
+	})
+	require.NoError(t, err)
+	s := Source{}
+	require.NoError(t, s.Init(ctx, "test validate", 0, 0, true, conn, 1))
+
+	errs := s.Validate(ctx)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], notRepo)
+	assert.ErrorContains(t, errs[1], "file://"+notRepo)
+
+	assert.NoError(t, PingURI(ctx, "file://"+repoPath))
+	assert.Error(t, PingURI(ctx, "file://"+notRepo))
+	assert.Error(t, PingURI(ctx, "ftp://example.com/repo.git"))
+}
+
 func TestChunkUnit(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -597,3 +661,394 @@ func TestChunkUnit(t *testing.T) {
 	assert.Equal(t, 22, len(reporter.Chunks))
 	assert.Equal(t, 1, len(reporter.ChunkErrs))
 }
+
+func TestScanRepo_Incremental(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commitFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+		gitCmd("add", name)
+		gitCmd("commit", "-m", "add "+name)
+	}
+	gitCmd("init")
+	commitFile("first.txt", "first content")
+	commitFile("second.txt", "second content")
+
+	state, err := incremental.Open(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+	ctx = incremental.WithState(ctx, state)
+
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file, Commit: commit}}}
+		},
+	})
+	scannedFiles := func() []string {
+		t.Helper()
+		repo, err := git.PlainOpen(repoPath)
+		require.NoError(t, err)
+		reporter := sourcestest.TestReporter{}
+		require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(), &reporter))
+		var files []string
+		for _, chunk := range reporter.Chunks {
+			if file := chunk.SourceMetadata.GetGit().GetFile(); file != "" {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
+
+	assert.ElementsMatch(t, []string{"first.txt", "second.txt"}, scannedFiles())
+	commitFile("third.txt", "third content")
+	assert.Equal(t, []string{"third.txt"}, scannedFiles())
+	assert.Empty(t, scannedFiles())
+}
+
+func TestScanRepo_ChunkOverlap(t *testing.T) {
+	ctx := sources.WithChunkSizes(context.Background(), 100, 40)
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	var content strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&content, "line %02d\n", i)
+	}
+	gitCmd("init")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "lines.txt"), []byte(content.String()), 0644))
+	gitCmd("add", "lines.txt")
+	gitCmd("commit", "-m", "add lines")
+
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file, Line: line}}}
+		},
+	})
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	reporter := sourcestest.TestReporter{}
+	require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(), &reporter))
+
+	var chunks []sources.Chunk
+	for _, chunk := range reporter.Chunks {
+		if chunk.SourceMetadata.GetGit().GetFile() == "lines.txt" {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	// Fragments of 12 lines, each starting with the last 5 lines of the one
+	// before.
+	var lines []int64
+	for i, chunk := range chunks {
+		line := chunk.SourceMetadata.GetGit().GetLine()
+		lines = append(lines, line)
+		assert.True(t, strings.HasPrefix(string(chunk.Data), fmt.Sprintf("line %02d\n", line)), string(chunk.Data))
+		if i > 0 {
+			assert.True(t, bytes.HasSuffix(chunks[i-1].Data, chunk.Data[:40]))
+		}
+	}
+	assert.Equal(t, []int64{1, 8, 15, 22}, lines)
+	assert.True(t, strings.HasSuffix(string(chunks[len(chunks)-1].Data), "line 30\n"))
+}
+
+func TestScanRepo_Filter(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd("init")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "vendor"), 0755))
+	files := map[string]string{
+		"small.txt":      "small content\n",
+		"large.txt":      strings.Repeat("large content\n", 100),
+		"vendor/lib.txt": "vendored content\n",
+		"logo.png":       "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"small.bin":      "\x00\x01\x02 small binary",
+		"large.bin":      strings.Repeat("\x00\x01\x02 large binary", 100),
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "add files")
+
+	filter, err := common.FilterFromFiles("", "",
+		common.WithExcludePaths(`^vendor/`),
+		common.WithMaxSize(200),
+		common.WithExcludeMIMETypes("image/*"),
+	)
+	require.NoError(t, err)
+
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file}}}
+		},
+	})
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	reporter := sourcestest.TestReporter{}
+	require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(ScanOptionFilter(filter)), &reporter))
+
+	var scanned []string
+	for _, chunk := range reporter.Chunks {
+		if file := chunk.SourceMetadata.GetGit().GetFile(); file != "" {
+			scanned = append(scanned, file)
+		}
+	}
+	assert.ElementsMatch(t, []string{"small.txt", "small.bin"}, scanned)
+}
+
+func TestScanRepo_IgnoreFile(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd("init")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "vendor"), 0755))
+	files := map[string]string{
+		ignorefile.Name:  "vendor/\ndetector:AWS\n",
+		"main.txt":       "main content\n",
+		"vendor/lib.txt": "vendored content\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "add files")
+
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file}}}
+		},
+	})
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	reporter := sourcestest.TestReporter{}
+	require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(), &reporter))
+
+	var scanned []string
+	for _, chunk := range reporter.Chunks {
+		if file := chunk.SourceMetadata.GetGit().GetFile(); file != "" {
+			scanned = append(scanned, file)
+		}
+		assert.True(t, chunk.IgnoreFile.SuppressesDetector("AWS"))
+	}
+	assert.ElementsMatch(t, []string{ignorefile.Name, "main.txt"}, scanned)
+}
+
+func TestScanRepo_Worktree(t *testing.T) {
+	ctx := context.Background()
+	mainPath := t.TempDir()
+	worktreePath := filepath.Join(t.TempDir(), "feature")
+	gitCmd := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd(mainPath, "init")
+	require.NoError(t, os.WriteFile(filepath.Join(mainPath, "main.txt"), []byte("main content\n"), 0644))
+	gitCmd(mainPath, "add", ".")
+	gitCmd(mainPath, "commit", "-m", "add main")
+	gitCmd(mainPath, "worktree", "add", "-b", "feature", worktreePath)
+
+	files := map[string]string{
+		"feature.txt": "feature content\n",
+		"feature.bin": "\x00\x01\x02 feature binary",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(worktreePath, name), []byte(content), 0644))
+	}
+	gitCmd(worktreePath, "add", ".")
+	gitCmd(worktreePath, "commit", "-m", "add feature")
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, "staged.txt"), []byte("staged content\n"), 0644))
+	gitCmd(worktreePath, "add", "staged.txt")
+
+	repo, err := RepoFromPath(worktreePath, false)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/feature", head.Name().String())
+	require.NoError(t, PingURI(ctx, "file://"+worktreePath))
+
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file, Commit: commit}}}
+		},
+	})
+	reporter := sourcestest.TestReporter{}
+	require.NoError(t, g.ScanRepo(ctx, repo, worktreePath, NewScanOptions(), &reporter))
+
+	scanned := make(map[string]string)
+	for _, chunk := range reporter.Chunks {
+		if file := chunk.SourceMetadata.GetGit().GetFile(); file != "" {
+			scanned[file] = string(chunk.Data)
+		}
+	}
+	assert.Contains(t, scanned["main.txt"], "main content")
+	assert.Contains(t, scanned["feature.txt"], "feature content")
+	assert.Contains(t, scanned["feature.bin"], "feature binary")
+	assert.Contains(t, scanned["staged.txt"], "staged content")
+	assert.Empty(t, reporter.ChunkErrs)
+}
+
+func TestResolveGitDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "repo", ".git"), 0755))
+	assert.Equal(t, filepath.Join(dir, "repo", ".git"), resolveGitDir(filepath.Join(dir, "repo", ".git")))
+
+	// Submodules point to their git directory with a relative path.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "repo", "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repo", "sub", ".git"), []byte("gitdir: ../.git/modules/sub\n"), 0644))
+	assert.Equal(t, filepath.Join(dir, "repo", ".git", "modules", "sub"), resolveGitDir(filepath.Join(dir, "repo", "sub", ".git")))
+
+	assert.Equal(t, filepath.Join(dir, "missing", ".git"), resolveGitDir(filepath.Join(dir, "missing", ".git")))
+}
+
+func TestScanRepo_Remotes(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", path, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd("init")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "committed.txt"), []byte("committed content\n"), 0644))
+	gitCmd("add", ".")
+	gitCmd("commit", "-m", "add committed")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "staged.txt"), []byte("staged content\n"), 0644))
+	gitCmd("add", "staged.txt")
+
+	scan := func(options ...ScanOption) map[string]string {
+		t.Helper()
+		repo, err := RepoFromPath(path, false)
+		require.NoError(t, err)
+		g := NewGit(&Config{
+			Concurrency: 1,
+			SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+				return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file, Repository: repository}}}
+			},
+		})
+		reporter := sourcestest.TestReporter{}
+		require.NoError(t, g.ScanRepo(ctx, repo, path, NewScanOptions(options...), &reporter))
+		assert.Empty(t, reporter.ChunkErrs)
+		repositories := make(map[string]string)
+		for _, chunk := range reporter.Chunks {
+			if file := chunk.SourceMetadata.GetGit().GetFile(); file != "" {
+				repositories[file] = chunk.SourceMetadata.GetGit().GetRepository()
+			}
+		}
+		return repositories
+	}
+
+	// Freshly created repositories have no remotes.
+	assert.Equal(t, map[string]string{"committed.txt": path, "staged.txt": path}, scan())
+
+	// Without origin, the first remote identifies the repository.
+	gitCmd("remote", "add", "upstream", "https://example.com/upstream.git")
+	assert.Equal(t, "https://example.com/upstream.git", scan()["staged.txt"])
+
+	gitCmd("remote", "add", "mirror", "https://example.com/mirror.git")
+	assert.Equal(t, "https://example.com/mirror.git", scan(ScanOptionRemoteName("mirror"))["committed.txt"])
+}
+
+func TestScanRepo_Timeout(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd("init")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("content"), 0644))
+	gitCmd("add", "file.txt")
+	gitCmd("commit", "-m", "add file.txt")
+
+	state, err := incremental.Open(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+	ctx = incremental.WithState(ctx, state)
+
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file}}}
+		},
+	})
+	err = g.ScanRepo(ctx, repo, repoPath, NewScanOptions(ScanOptionRepoTimeout(time.Nanosecond)), &sourcestest.TestReporter{})
+	assert.ErrorIs(t, err, ErrRepoTimeout)
+	// The history wasn't scanned to the end, so it's scanned again next time.
+	abs, err := filepath.Abs(repoPath)
+	require.NoError(t, err)
+	assert.Empty(t, state.LastCommit(abs))
+
+	// The timeout of the context applies when the options don't set one.
+	err = g.ScanRepo(WithRepoTimeout(ctx, time.Nanosecond), repo, repoPath, NewScanOptions(), &sourcestest.TestReporter{})
+	assert.ErrorIs(t, err, ErrRepoTimeout)
+
+	require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(ScanOptionRepoTimeout(time.Minute)), &sourcestest.TestReporter{}))
+	assert.NotEmpty(t, state.LastCommit(abs))
+}
+
+func TestScanRepo_Logger(t *testing.T) {
+	ctx := context.WithLogger(context.Background(), logr.Discard())
+	repoPath := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	gitCmd("init")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("content"), 0644))
+	gitCmd("add", "file.txt")
+	gitCmd("commit", "-m", "add file.txt")
+
+	var logs []string
+	logger := funcr.New(func(prefix, args string) {
+		logs = append(logs, args)
+	}, funcr.Options{Verbosity: 5})
+	g := NewGit(&Config{
+		Concurrency: 1,
+		SourceMetadataFunc: func(file, email, commit, timestamp, repository string, line int64) *source_metadatapb.MetaData {
+			return &source_metadatapb.MetaData{Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{File: file}}}
+		},
+		Logger: logger,
+	})
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	require.NoError(t, g.ScanRepo(ctx, repo, repoPath, NewScanOptions(), &sourcestest.TestReporter{}))
+
+	// The scan logs to the configured logger, with the repository it scans,
+	// rather than to the one of the context.
+	require.NotEmpty(t, logs)
+	assert.Contains(t, strings.Join(logs, "\n"), fmt.Sprintf(`"repo"=%q`, repoPath))
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"os"
+	"sync"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// RepoCache prepares each repository it's asked for once, such as to read
+// the repositories of findings after they were scanned. Repositories are
+// cloned without credentials, like PrepareRepo does, unless they are local
+// directories, and the clones are removed by Close.
+type RepoCache struct {
+	mu    sync.Mutex
+	repos map[string]*cachedRepo
+}
+
+type cachedRepo struct {
+	once   sync.Once
+	path   string
+	remote bool
+	err    error
+}
+
+// NewRepoCache creates an empty RepoCache.
+func NewRepoCache() *RepoCache {
+	return &RepoCache{repos: make(map[string]*cachedRepo)}
+}
+
+// Path returns the local path of the repository at repoURL, which is a URL
+// PrepareRepo accepts or the path of a local repository, as the metadata of
+// findings in repositories cloned from disk has.
+func (c *RepoCache) Path(ctx context.Context, repoURL string) (string, error) {
+	c.mu.Lock()
+	repo, ok := c.repos[repoURL]
+	if !ok {
+		repo = new(cachedRepo)
+		c.repos[repoURL] = repo
+	}
+	c.mu.Unlock()
+
+	repo.once.Do(func() {
+		if info, err := os.Stat(repoURL); err == nil && info.IsDir() {
+			repo.path = repoURL
+			return
+		}
+		repo.path, repo.remote, repo.err = PrepareRepo(ctx, repoURL)
+	})
+	return repo.path, repo.err
+}
+
+// Close removes the repositories the cache cloned.
+func (c *RepoCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, repo := range c.repos {
+		if repo.remote && repo.path != "" {
+			_ = os.RemoveAll(repo.path)
+		}
+	}
+	c.repos = make(map[string]*cachedRepo)
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestRepoCache_Local(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cache := NewRepoCache()
+
+	path, err := cache.Path(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, path)
+	path, err = cache.Path(ctx, "file://"+dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, path)
+
+	_, err = cache.Path(ctx, "no bueno")
+	assert.Error(t, err)
+
+	// Local repositories aren't removed.
+	cache.Close()
+	_, err = os.Stat(dir)
+	assert.NoError(t, err)
+}
@@ -1,8 +1,11 @@
 package git
 
 import (
+	"time"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
 )
 
 type ScanOptions struct {
@@ -13,6 +16,14 @@ type ScanOptions struct {
 	Bare         bool
 	ExcludeGlobs []string
 	LogOptions   *git.LogOptions
+	RemoteName   string // The remote whose URL identifies the repository, origin by default.
+	// RepoTimeout is the maximum time spent scanning the repository. The rest
+	// of its history isn't scanned once it's exceeded. If it is zero,
+	// DefaultRepoTimeout of the context of the scan is used.
+	RepoTimeout time.Duration
+
+	// ignoreFile is the .trufflehogignore file of the repository being scanned.
+	ignoreFile *ignorefile.File
 }
 
 type ScanOption func(*ScanOptions)
@@ -59,6 +70,18 @@ func ScanOptionBare(bare bool) ScanOption {
 	}
 }
 
+func ScanOptionRemoteName(name string) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.RemoteName = name
+	}
+}
+
+func ScanOptionRepoTimeout(timeout time.Duration) ScanOption {
+	return func(scanOptions *ScanOptions) {
+		scanOptions.RepoTimeout = timeout
+	}
+}
+
 func NewScanOptions(options ...ScanOption) *ScanOptions {
 	scanOptions := &ScanOptions{
 		Filter:   common.FilterEmpty(),
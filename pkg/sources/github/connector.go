@@ -2,12 +2,15 @@ package github
 
 import (
 	"fmt"
+	"net/http"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-github/v66/github"
 
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 const cloudEndpoint = "https://api.github.com"
@@ -27,14 +30,25 @@ func newConnector(source *Source) (connector, error) {
 
 	switch cred := source.conn.GetCredential().(type) {
 	case *sourcespb.GitHub_GithubApp:
-		return newAppConnector(apiEndpoint, cred.GithubApp)
+		return newAppConnector(apiEndpoint, cred.GithubApp, source.rateLimiter)
 	case *sourcespb.GitHub_BasicAuth:
-		return newBasicAuthConnector(apiEndpoint, cred.BasicAuth)
+		return newBasicAuthConnector(apiEndpoint, cred.BasicAuth, source.rateLimiter)
 	case *sourcespb.GitHub_Token:
-		return newTokenConnector(apiEndpoint, cred.Token, source.handleRateLimit)
+		tokens := append([]string{cred.Token}, source.conn.GetTokens()...)
+		return newTokenConnector(apiEndpoint, tokens, source.handleRateLimit, source.rateLimiter)
 	case *sourcespb.GitHub_Unauthenticated:
-		return newUnauthenticatedConnector(apiEndpoint)
+		return newUnauthenticatedConnector(apiEndpoint, source.rateLimiter)
 	default:
 		return nil, fmt.Errorf("unknown connection type")
 	}
 }
+
+// newHTTPClient returns the retrying client of the connectors. Rate limited
+// responses aren't retried by the client but wait on limiter, which sits
+// above the timeout of each attempt so that long waits aren't cut short.
+func newHTTPClient(limiter *ratelimit.Limiter) *http.Client {
+	const httpTimeoutSeconds = 60
+	httpClient := common.RetryableHTTPClientTimeout(int64(httpTimeoutSeconds), common.WithCheckRetry(ratelimit.CheckRetry))
+	httpClient.Transport = limiter.Transport(httpClient.Transport)
+	return httpClient
+}
@@ -7,10 +7,10 @@ import (
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-github/v66/github"
-	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 type appConnector struct {
@@ -21,7 +21,7 @@ type appConnector struct {
 
 var _ connector = (*appConnector)(nil)
 
-func newAppConnector(apiEndpoint string, app *credentialspb.GitHubApp) (*appConnector, error) {
+func newAppConnector(apiEndpoint string, app *credentialspb.GitHubApp, limiter *ratelimit.Limiter) (*appConnector, error) {
 	installationID, err := strconv.ParseInt(app.InstallationId, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse app installation ID %q: %w", app.InstallationId, err)
@@ -32,8 +32,7 @@ func newAppConnector(apiEndpoint string, app *credentialspb.GitHubApp) (*appConn
 		return nil, fmt.Errorf("could not parse app ID %q: %w", appID, err)
 	}
 
-	const httpTimeoutSeconds = 60
-	httpClient := common.RetryableHTTPClientTimeout(int64(httpTimeoutSeconds))
+	httpClient := newHTTPClient(limiter)
 
 	installationTransport, err := ghinstallation.NewAppsTransport(
 		httpClient.Transport,
@@ -44,7 +43,7 @@ func newAppConnector(apiEndpoint string, app *credentialspb.GitHubApp) (*appConn
 	}
 	installationTransport.BaseURL = apiEndpoint
 
-	installationHttpClient := common.RetryableHTTPClientTimeout(60)
+	installationHttpClient := newHTTPClient(limiter)
 	installationHttpClient.Transport = installationTransport
 	installationClient, err := github.NewClient(installationHttpClient).WithEnterpriseURLs(apiEndpoint, apiEndpoint)
 	if err != nil {
@@ -5,10 +5,10 @@ import (
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-github/v66/github"
-	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 type basicAuthConnector struct {
@@ -19,12 +19,12 @@ type basicAuthConnector struct {
 
 var _ connector = (*basicAuthConnector)(nil)
 
-func newBasicAuthConnector(apiEndpoint string, cred *credentialspb.BasicAuth) (*basicAuthConnector, error) {
-	const httpTimeoutSeconds = 60
-	httpClient := common.RetryableHTTPClientTimeout(int64(httpTimeoutSeconds))
+func newBasicAuthConnector(apiEndpoint string, cred *credentialspb.BasicAuth, limiter *ratelimit.Limiter) (*basicAuthConnector, error) {
+	httpClient := newHTTPClient(limiter)
 	httpClient.Transport = &github.BasicAuthTransport{
-		Username: cred.Username,
-		Password: cred.Password,
+		Username:  cred.Username,
+		Password:  cred.Password,
+		Transport: httpClient.Transport,
 	}
 
 	apiClient, err := createGitHubClient(httpClient, apiEndpoint)
@@ -7,67 +7,84 @@ import (
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-github/v66/github"
-	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 	"golang.org/x/oauth2"
 )
 
 type tokenConnector struct {
-	apiClient          *github.Client
-	token              string
+	// apiClients has a client for each token, because go-github refuses
+	// requests while the last response of its client had no quota left.
+	apiClients         map[string]*github.Client
+	tokens             *ratelimit.Pool
 	isGitHubEnterprise bool
 	handleRateLimit    func(context.Context, error) bool
-	user               string
+	users              map[string]string
 	userMu             sync.Mutex
 }
 
 var _ connector = (*tokenConnector)(nil)
 
-func newTokenConnector(apiEndpoint string, token string, handleRateLimit func(context.Context, error) bool) (*tokenConnector, error) {
-	const httpTimeoutSeconds = 60
-	httpClient := common.RetryableHTTPClientTimeout(int64(httpTimeoutSeconds))
-	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	httpClient.Transport = &oauth2.Transport{
-		Base:   httpClient.Transport,
-		Source: oauth2.ReuseTokenSource(nil, tokenSource),
-	}
+func newTokenConnector(apiEndpoint string, tokens []string, handleRateLimit func(context.Context, error) bool, limiter *ratelimit.Limiter) (*tokenConnector, error) {
+	pool := ratelimit.NewPool(limiter, tokens...)
+	apiClients := make(map[string]*github.Client, pool.Len())
+	for _, token := range pool.Tokens() {
+		httpClient := newHTTPClient(limiter)
+		tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+		httpClient.Transport = &oauth2.Transport{
+			Base:   httpClient.Transport,
+			Source: oauth2.ReuseTokenSource(nil, tokenSource),
+		}
 
-	apiClient, err := createGitHubClient(httpClient, apiEndpoint)
-	if err != nil {
-		return nil, fmt.Errorf("could not create API client: %w", err)
+		apiClient, err := createGitHubClient(httpClient, apiEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("could not create API client: %w", err)
+		}
+		apiClients[token] = apiClient
 	}
 
 	return &tokenConnector{
-		apiClient:          apiClient,
-		token:              token,
+		apiClients:         apiClients,
+		tokens:             pool,
 		isGitHubEnterprise: !strings.EqualFold(apiEndpoint, cloudEndpoint),
 		handleRateLimit:    handleRateLimit,
+		users:              make(map[string]string, pool.Len()),
 	}, nil
 }
 
+// APIClient returns the client of the token with the most of its rate limit
+// left.
 func (c *tokenConnector) APIClient() *github.Client {
-	return c.apiClient
+	return c.apiClients[c.tokens.Next()]
 }
 
 func (c *tokenConnector) Clone(ctx context.Context, repoURL string) (string, *gogit.Repository, error) {
-	if err := c.setUserIfUnset(ctx); err != nil {
+	token := c.tokens.Next()
+	user, err := c.user(ctx, token)
+	if err != nil {
 		return "", nil, err
 	}
-	return git.CloneRepoUsingToken(ctx, c.token, repoURL, c.user)
+	return git.CloneRepoUsingToken(ctx, token, repoURL, user)
 }
 
 func (c *tokenConnector) IsGithubEnterprise() bool {
 	return c.isGitHubEnterprise
 }
 
-func (c *tokenConnector) getUser(ctx context.Context) (string, error) {
+// canRotate reports whether another token can be used now that requests
+// were rate limited.
+func (c *tokenConnector) canRotate() bool {
+	return c.tokens.Len() > 1 && c.tokens.Ready()
+}
+
+func (c *tokenConnector) getUser(ctx context.Context, token string) (string, error) {
 	var (
 		user *github.User
 		err  error
 	)
 	for {
-		user, _, err = c.apiClient.Users.Get(ctx, "")
+		user, _, err = c.apiClients[token].Users.Get(ctx, "")
 		if c.handleRateLimit(ctx, err) {
 			continue
 		}
@@ -79,19 +96,20 @@ func (c *tokenConnector) getUser(ctx context.Context) (string, error) {
 	return user.GetLogin(), nil
 }
 
-func (c *tokenConnector) setUserIfUnset(ctx context.Context) error {
+// user returns the login of the owner of token, which is looked up once.
+func (c *tokenConnector) user(ctx context.Context, token string) (string, error) {
 	c.userMu.Lock()
 	defer c.userMu.Unlock()
 
-	if c.user != "" {
-		return nil
+	if user, ok := c.users[token]; ok {
+		return user, nil
 	}
 
-	user, err := c.getUser(ctx)
+	user, err := c.getUser(ctx, token)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	c.user = user
-	return nil
+	c.users[token] = user
+	return user, nil
 }
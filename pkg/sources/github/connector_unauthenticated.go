@@ -6,9 +6,9 @@ import (
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/google/go-github/v66/github"
 
-	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 type unauthenticatedConnector struct {
@@ -17,9 +17,8 @@ type unauthenticatedConnector struct {
 
 var _ connector = (*unauthenticatedConnector)(nil)
 
-func newUnauthenticatedConnector(apiEndpoint string) (*unauthenticatedConnector, error) {
-	const httpTimeoutSeconds = 60
-	httpClient := common.RetryableHTTPClientTimeout(int64(httpTimeoutSeconds))
+func newUnauthenticatedConnector(apiEndpoint string, limiter *ratelimit.Limiter) (*unauthenticatedConnector, error) {
+	httpClient := newHTTPClient(limiter)
 	apiClient, err := createGitHubClient(httpClient, apiEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("could not create API client: %w", err)
@@ -31,6 +31,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 const (
@@ -65,6 +66,7 @@ type Source struct {
 	resumeInfoMutex sync.Mutex
 	resumeInfoSlice []string
 	connector       connector
+	rateLimiter     *ratelimit.Limiter
 
 	includePRComments    bool
 	includeIssueComments bool
@@ -224,6 +226,7 @@ func (s *Source) Init(aCtx context.Context, name string, jobID sources.JobID, so
 	}
 	s.conn = &conn
 
+	s.rateLimiter = ratelimit.New("GitHub", ratelimit.WithProgress(&s.Progress))
 	connector, err := newConnector(s)
 	if err != nil {
 		return fmt.Errorf("could not create connector: %w", err)
@@ -302,6 +305,20 @@ func (s *Source) Validate(ctx context.Context) []error {
 	return nil
 }
 
+// HealthCheck gets the API rate limits, which works with every kind of
+// credential but fails if the credential is invalid or GitHub can't be
+// reached. Exhausted core limits are reported too, since a scan would stall.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	limits, _, err := s.connector.APIClient().RateLimit.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if core := limits.GetCore(); core != nil && core.Remaining == 0 {
+		return fmt.Errorf("rate limit exhausted until %s", core.Reset.Time.Format(time.RFC3339))
+	}
+	return nil
+}
+
 func (s *Source) visibilityOf(ctx context.Context, repoURL string) source_metadatapb.Visibility {
 	// It isn't possible to get the visibility of a wiki.
 	// We must use the visibility of the corresponding repository.
@@ -405,6 +422,9 @@ func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) e
 		if err := s.enumerateWithToken(ctx, c.IsGithubEnterprise(), dedupeReporter); err != nil {
 			return err
 		}
+		if c.tokens.Len() > 1 {
+			ctx.Logger().V(2).Info("token pool status after enumeration", "tokens", c.tokens.Status())
+		}
 	case *unauthenticatedConnector:
 		s.enumerateUnauthenticated(ctx, dedupeReporter)
 	}
@@ -714,10 +734,14 @@ func (s *Source) scanRepo(ctx context.Context, repoURL string, reporter sources.
 
 func (s *Source) cloneAndScanRepo(ctx context.Context, repoURL string, repoInfo repoInfo, reporter sources.ChunkReporter) (time.Duration, error) {
 	var duration time.Duration
+	progress := s.ProgressRepo(repoURL)
+	defer progress.Finish()
 
 	ctx.Logger().V(2).Info("attempting to clone repo")
+	progress.SetStage(sources.StageCloning)
 	path, repo, err := s.cloneRepo(ctx, repoURL)
 	if err != nil {
+		progress.AddError()
 		return duration, err
 	}
 	defer os.RemoveAll(path)
@@ -726,7 +750,8 @@ func (s *Source) cloneAndScanRepo(ctx context.Context, repoURL string, repoInfo
 	s.setScanOptions(s.conn.Base, s.conn.Head)
 
 	start := time.Now()
-	if err = s.git.ScanRepo(ctx, repo, path, s.scanOptions, reporter); err != nil {
+	progress.SetStage(sources.StageScanning)
+	if err = s.git.ScanRepo(ctx, repo, path, s.scanOptions, progress.Reporter(reporter)); err != nil {
 		return duration, fmt.Errorf("error scanning repo %s: %w", repoURL, err)
 	}
 	duration = time.Since(start)
@@ -749,6 +774,18 @@ func (s *Source) handleRateLimit(ctx context.Context, errIn error) bool {
 		return false
 	}
 
+	// With several tokens, a rate limited request is retried with another
+	// one instead of waiting.
+	var (
+		rateLimit  *github.RateLimitError
+		abuseLimit *github.AbuseRateLimitError
+	)
+	if c, ok := s.connector.(*tokenConnector); ok && c.canRotate() && (errors.As(errIn, &rateLimit) || errors.As(errIn, &abuseLimit)) {
+		githubNumRateLimitEncountered.WithLabelValues(s.name).Inc()
+		ctx.Logger().V(2).Info("rate limited, rotating to another token")
+		return true
+	}
+
 	rateLimitMu.RLock()
 	resumeTime := rateLimitResumeTime
 	rateLimitMu.RUnlock()
@@ -761,9 +798,7 @@ func (s *Source) handleRateLimit(ctx context.Context, errIn error) bool {
 			now = time.Now()
 
 			// GitHub has both primary (RateLimit) and secondary (AbuseRateLimit) errors.
-			limitType  string
-			rateLimit  *github.RateLimitError
-			abuseLimit *github.AbuseRateLimitError
+			limitType string
 		)
 		if errors.As(errIn, &rateLimit) {
 			limitType = "primary"
@@ -797,7 +832,7 @@ func (s *Source) handleRateLimit(ctx context.Context, errIn error) bool {
 	}
 
 	githubNumRateLimitEncountered.WithLabelValues(s.name).Inc()
-	time.Sleep(retryAfter)
+	_ = s.rateLimiter.Wait(ctx, time.Now().Add(retryAfter))
 	githubSecondsSpentRateLimited.WithLabelValues(s.name).Add(retryAfter.Seconds())
 	return true
 }
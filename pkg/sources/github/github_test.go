@@ -9,10 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"gopkg.in/h2non/gock.v1"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/cache/simple"
@@ -28,6 +31,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 )
 
 func createPrivateKey() string {
@@ -139,6 +143,68 @@ func TestAddReposByOrg_IncludeRepos(t *testing.T) {
 	assert.True(t, gock.IsDone())
 }
 
+func TestAddReposByOrg_Exclude(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/orgs/super-secret-org/repos").
+		Reply(200).
+		JSON(`[
+			{"full_name": "super-secret-org/super-secret-repo", "clone_url": "https://github.com/super-secret-org/super-secret-repo.git", "size": 1},
+			{"full_name": "super-secret-org/old-repo", "clone_url": "https://github.com/super-secret-org/old-repo.git", "size": 1, "archived": true},
+			{"full_name": "super-secret-org/demo-repo", "clone_url": "https://github.com/super-secret-org/demo-repo.git", "size": 1, "topics": ["Demo"]}
+		]`)
+
+	s := initTestSource(&sourcespb.GitHub{
+		Credential: &sourcespb.GitHub_Token{
+			Token: "super secret token",
+		},
+		Organizations: []string{"super-secret-org"},
+		Exclude:       &sourcespb.RepoExclusions{Archived: true, Topics: []string{"demo"}},
+	})
+	err := s.getReposByOrg(context.Background(), "super-secret-org", noopReporter())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.filteredRepoCache.Count())
+	ok := s.filteredRepoCache.Exists("super-secret-org/super-secret-repo")
+	assert.True(t, ok)
+	assert.False(t, gock.HasUnmatchedRequest())
+	assert.True(t, gock.IsDone())
+}
+
+func TestAddReposByOrg_Select(t *testing.T) {
+	defer gock.Off()
+
+	pushed := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	gock.New("https://api.github.com").
+		Get("/orgs/super-secret-org/repos").
+		Reply(200).
+		JSON(`[
+			{"full_name": "super-secret-org/api", "clone_url": "https://github.com/super-secret-org/api.git", "size": 1, "language": "Go", "pushed_at": "` + pushed + `"},
+			{"full_name": "super-secret-org/web", "clone_url": "https://github.com/super-secret-org/web.git", "size": 1, "language": "TypeScript", "pushed_at": "` + pushed + `"},
+			{"full_name": "super-secret-org/legacy", "clone_url": "https://github.com/super-secret-org/legacy.git", "size": 1, "language": "Go", "pushed_at": "2019-01-01T00:00:00Z"},
+			{"full_name": "super-secret-org/monolith", "clone_url": "https://github.com/super-secret-org/monolith.git", "size": 4096, "language": "Go", "pushed_at": "` + pushed + `"}
+		]`)
+
+	s := initTestSource(&sourcespb.GitHub{
+		Credential: &sourcespb.GitHub_Token{
+			Token: "super secret token",
+		},
+		Organizations: []string{"super-secret-org"},
+		Select: &sourcespb.RepoSelection{
+			Languages:    []string{"go"},
+			PushedWithin: durationpb.New(365 * 24 * time.Hour),
+			MaxSizeKb:    1024,
+		},
+	})
+	err := s.getReposByOrg(context.Background(), "super-secret-org", noopReporter())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.filteredRepoCache.Count())
+	ok := s.filteredRepoCache.Exists("super-secret-org/api")
+	assert.True(t, ok)
+	assert.False(t, gock.HasUnmatchedRequest())
+	assert.True(t, gock.IsDone())
+}
+
 func TestAddReposByUser(t *testing.T) {
 	defer gock.Off()
 
@@ -404,6 +470,37 @@ func TestHandleRateLimit(t *testing.T) {
 	assert.True(t, s.handleRateLimit(ctx, err))
 }
 
+func TestTokenConnector_Rotation(t *testing.T) {
+	var used []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, token)
+		// Each token has a single request left.
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		fmt.Fprintf(w, `{"login": "user-%s"}`, token)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := &Source{}
+	limiter := ratelimit.New("GitHub")
+	c, err := newTokenConnector(srv.URL, []string{"a", "b"}, s.handleRateLimit, limiter)
+	assert.NoError(t, err)
+	s.connector = c
+
+	for _, want := range []string{"user-a", "user-b"} {
+		assert.True(t, c.canRotate())
+		user, _, err := c.APIClient().Users.Get(ctx, "")
+		assert.NoError(t, err)
+		assert.Equal(t, want, user.GetLogin())
+	}
+	assert.Equal(t, []string{"a", "b"}, used)
+	// Both quotas are spent, so a rate limit has to be waited on.
+	assert.False(t, c.canRotate())
+}
+
 func TestEnumerateUnauthenticated(t *testing.T) {
 	defer gock.Off()
 
@@ -201,6 +201,22 @@ func (s *Source) processRepos(ctx context.Context, target string, reporter sourc
 
 		ctx.Logger().V(2).Info("Listed repos", "page", opts.Page, "last_page", res.LastPage)
 		for _, r := range someRepos {
+			attrs := sources.RepoInfo{
+				Archived: r.GetArchived(),
+				Fork:     r.GetFork(),
+				Topics:   r.Topics,
+				Language: r.GetLanguage(),
+				PushedAt: r.GetPushedAt().Time,
+				SizeKB:   int64(r.GetSize()),
+			}
+			reason := sources.RepoExclusionReason(s.conn.GetExclude(), attrs)
+			if reason == "" {
+				reason = sources.RepoSelectionReason(s.conn.GetSelect(), attrs)
+			}
+			if reason != "" {
+				logger.V(3).Info("skipping repo", "name", r.GetFullName(), "reason", reason)
+				continue
+			}
 			if r.GetFork() {
 				if !s.conn.IncludeForks {
 					continue
@@ -243,9 +259,12 @@ func (s *Source) cacheRepoInfo(r *github.Repository) {
 		hasWiki:  r.GetHasWiki(),
 		size:     r.GetSize(),
 	}
-	if r.GetPrivate() {
+	switch {
+	case r.GetVisibility() == "internal":
+		info.visibility = source_metadatapb.Visibility_internal
+	case r.GetPrivate():
 		info.visibility = source_metadatapb.Visibility_private
-	} else {
+	default:
 		info.visibility = source_metadatapb.Visibility_public
 	}
 	s.repoInfoCache.put(r.GetCloneURL(), info)
@@ -129,6 +129,13 @@ func (s *Source) Init(aCtx context.Context, name string, jobID sources.JobID, so
 	return nil
 }
 
+// HealthCheck gets the API rate limits, which fails if the token is invalid
+// or GitHub can't be reached.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	_, _, err := s.apiClient.RateLimit.Get(ctx)
+	return err
+}
+
 func (s *Source) visibilityOf(ctx context.Context, repoURL string) source_metadatapb.Visibility {
 	// It isn't possible to get the visibility of a wiki.
 	// We must use the visibility of the corresponding repository.
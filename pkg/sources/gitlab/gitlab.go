@@ -1,7 +1,9 @@
 package gitlab
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"sort"
@@ -18,6 +20,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/ratelimit"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/gobwas/glob"
@@ -45,6 +48,8 @@ type Source struct {
 	url         string
 	repos       []string
 	ignoreRepos []string
+	exclude     *sourcespb.RepoExclusions
+	selection   *sourcespb.RepoSelection
 
 	useCustomContentWriter bool
 	git                    *git.Git
@@ -54,7 +59,15 @@ type Source struct {
 	resumeInfoMutex sync.Mutex
 	sources.Progress
 
-	jobPool *errgroup.Group
+	// visibility is the visibility of the enumerated projects, by the URL of
+	// their repository.
+	visibility   map[string]source_metadatapb.Visibility
+	visibilityMu sync.RWMutex
+
+	jobPool     *errgroup.Group
+	rateLimiter *ratelimit.Limiter
+	// tokens are the tokens rotated between when the source has several.
+	tokens *ratelimit.Pool
 	sources.CommonSourceUnitUnmarshaller
 }
 
@@ -89,6 +102,7 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 	s.verify = verify
 	s.jobPool = &errgroup.Group{}
 	s.jobPool.SetLimit(concurrency)
+	s.rateLimiter = ratelimit.New("GitLab", ratelimit.WithProgress(&s.Progress))
 
 	if err := git.CmdCheck(); err != nil {
 		return err
@@ -102,12 +116,15 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 
 	s.repos = conn.Repositories
 	s.ignoreRepos = conn.IgnoreRepos
+	s.exclude = conn.GetExclude()
+	s.selection = conn.GetSelect()
 	ctx.Logger().V(3).Info("setting ignore repos patterns", "patterns", s.ignoreRepos)
 
 	switch cred := conn.GetCredential().(type) {
 	case *sourcespb.GitLab_Token:
 		s.authMethod = "TOKEN"
 		s.token = cred.Token
+		s.tokens = ratelimit.NewPool(s.rateLimiter, append([]string{cred.Token}, conn.GetTokens()...)...)
 	case *sourcespb.GitLab_Oauth:
 		s.authMethod = "OAUTH"
 		s.token = cred.Oauth.RefreshToken
@@ -147,6 +164,7 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 						Link:       giturl.GenerateLink(repository, commit, file, line),
 						Timestamp:  sanitizer.UTF8(timestamp),
 						Line:       line,
+						Visibility: s.visibilityOf(repository),
 					},
 				},
 			}
@@ -212,6 +230,36 @@ func (s *Source) Chunks(ctx context.Context, chunksChan chan *sources.Chunk, tar
 	return s.scanRepos(ctx, chunksChan)
 }
 
+func (s *Source) setVisibility(repoURL string, visibility gitlab.VisibilityValue) {
+	v := source_metadatapb.Visibility_unknown
+	switch visibility {
+	case gitlab.PublicVisibility:
+		v = source_metadatapb.Visibility_public
+	case gitlab.InternalVisibility:
+		v = source_metadatapb.Visibility_internal
+	case gitlab.PrivateVisibility:
+		v = source_metadatapb.Visibility_private
+	}
+
+	s.visibilityMu.Lock()
+	defer s.visibilityMu.Unlock()
+	if s.visibility == nil {
+		s.visibility = make(map[string]source_metadatapb.Visibility)
+	}
+	s.visibility[repoURL] = v
+}
+
+// visibilityOf returns the visibility of the project of the repository at
+// repoURL, which is unknown unless the project was enumerated.
+func (s *Source) visibilityOf(repoURL string) source_metadatapb.Visibility {
+	s.visibilityMu.RLock()
+	defer s.visibilityMu.RUnlock()
+	if v, ok := s.visibility[repoURL]; ok {
+		return v
+	}
+	return source_metadatapb.Visibility_unknown
+}
+
 func (s *Source) scanTargets(ctx context.Context, client *gitlab.Client, targets []sources.ChunkingTarget, chunksChan chan *sources.Chunk) error {
 	ctx = context.WithValues(ctx, "scan_type", "targeted")
 	for _, tgt := range targets {
@@ -331,24 +379,56 @@ func (s *Source) Validate(ctx context.Context) []error {
 	}
 
 	if len(repos) == 0 {
-		errs = append(errs, fmt.Errorf("ignore patterns excluded all projects"))
+		errs = append(errs, fmt.Errorf("ignore patterns and exclusions excluded all projects"))
 	}
 
 	return errs
 }
 
+// HealthCheck checks the credentials and that the explicitly configured
+// repositories can be reached. Unlike Validate, it doesn't list every project.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	apiClient, err := s.newClient()
+	if err != nil {
+		return err
+	}
+	if _, _, err := apiClient.Users.CurrentUser(gitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("gitlab authentication failed using method %v: %w", s.authMethod, err)
+	}
+
+	repos, errs := normalizeRepos(s.repos)
+	user := s.user
+	if user == "" {
+		user = "placeholder"
+	}
+	for _, r := range repos {
+		if err := git.PingRepoUsingToken(ctx, s.token, r, user); err != nil {
+			errs = append(errs, fmt.Errorf("could not reach git repository %q: %w", r, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (s *Source) newClient() (*gitlab.Client, error) {
+	// Requests wait on the rate limits of GitLab below the retries of the
+	// client, which has no timeout of its own.
+	transport := s.rateLimiter.Transport(nil)
+	if s.tokens.Len() > 1 {
+		transport = &tokenTransport{tokens: s.tokens, base: transport}
+	}
+	httpClient := gitlab.WithHTTPClient(&http.Client{Transport: transport})
+
 	// Initialize a new api instance.
 	switch s.authMethod {
 	case "OAUTH":
-		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url), httpClient)
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab OAUTH client for %q: %w", s.url, err)
 		}
 		return apiClient, nil
 
 	case "BASIC_AUTH":
-		apiClient, err := gitlab.NewBasicAuthClient(s.user, s.password, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewBasicAuthClient(s.user, s.password, gitlab.WithBaseURL(s.url), httpClient)
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab BASICAUTH client for %q: %w", s.url, err)
 		}
@@ -361,7 +441,7 @@ func (s *Source) newClient() (*gitlab.Client, error) {
 		}
 		fallthrough
 	case "TOKEN":
-		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url))
+		apiClient, err := gitlab.NewOAuthClient(s.token, gitlab.WithBaseURL(s.url), httpClient)
 		if err != nil {
 			return nil, fmt.Errorf("could not create Gitlab TOKEN client for %q: %w", s.url, err)
 		}
@@ -372,6 +452,19 @@ func (s *Source) newClient() (*gitlab.Client, error) {
 	}
 }
 
+// tokenTransport sends each request with the token of the pool that has the
+// most of its rate limit left, replacing the one the client was created with.
+type tokenTransport struct {
+	tokens *ratelimit.Pool
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.tokens.Next())
+	return t.base.RoundTrip(req)
+}
+
 func (s *Source) basicAuthSuccessful(apiClient *gitlab.Client) bool {
 	user, resp, err := apiClient.Users.CurrentUser()
 	if err != nil {
@@ -386,6 +479,60 @@ func (s *Source) basicAuthSuccessful(apiClient *gitlab.Client) bool {
 	return false
 }
 
+// projectSkipReason returns why the exclusions or selection of the source
+// skip proj, or "" if it's scanned. Listed projects have neither their
+// primary language nor, for group projects, their size, so those are only
+// looked up for projects that match the rest of the selection.
+func (s *Source) projectSkipReason(ctx context.Context, apiClient *gitlab.Client, proj *gitlab.Project) string {
+	attrs := sources.RepoInfo{
+		Archived: proj.Archived,
+		Fork:     proj.ForkedFromProject != nil,
+		Topics:   proj.Topics,
+	}
+	if proj.LastActivityAt != nil {
+		attrs.PushedAt = *proj.LastActivityAt
+	}
+	if reason := sources.RepoExclusionReason(s.exclude, attrs); reason != "" {
+		return reason
+	}
+	listed := &sourcespb.RepoSelection{Topics: s.selection.GetTopics(), PushedWithin: s.selection.GetPushedWithin()}
+	if reason := sources.RepoSelectionReason(listed, attrs); reason != "" {
+		return reason
+	}
+
+	if len(s.selection.GetLanguages()) > 0 {
+		languages, _, err := apiClient.Projects.GetProjectLanguages(proj.ID, gitlab.WithContext(ctx))
+		if err != nil {
+			ctx.Logger().V(2).Info("could not get project languages", "error", err)
+		} else {
+			attrs.Language = primaryLanguage(*languages)
+		}
+	}
+	if s.selection.GetMaxSizeKb() > 0 && proj.Statistics == nil {
+		opts := &gitlab.GetProjectOptions{Statistics: gitlab.Ptr(true)}
+		if p, _, err := apiClient.Projects.GetProject(proj.ID, opts, gitlab.WithContext(ctx)); err != nil {
+			ctx.Logger().V(2).Info("could not get project statistics", "error", err)
+		} else {
+			proj.Statistics = p.Statistics
+		}
+	}
+	if proj.Statistics != nil {
+		attrs.SizeKB = proj.Statistics.RepositorySize / 1024
+	}
+	return sources.RepoSelectionReason(s.selection, attrs)
+}
+
+// primaryLanguage returns the language most of a project is written in.
+func primaryLanguage(languages gitlab.ProjectLanguages) string {
+	var primary string
+	for language, share := range languages {
+		if primary == "" || share > languages[primary] || (share == languages[primary] && language < primary) {
+			primary = language
+		}
+	}
+	return primary
+}
+
 // getAllProjectRepos enumerates all GitLab projects using the provided API
 // client. The reporter is used to report the valid repository found for
 // projects that are not ignored.
@@ -423,6 +570,10 @@ func (s *Source) getAllProjectRepos(
 				ctx.Logger().V(3).Info("skipping project", "reason", "ignored in config")
 				continue
 			}
+			if reason := s.projectSkipReason(ctx, apiClient, proj); reason != "" {
+				ctx.Logger().V(3).Info("skipping project", "reason", reason)
+				continue
+			}
 			// Record that we've seen this project.
 			uniqueProjects[proj.ID] = proj
 			// Report an error if we could not convert the project into a URL.
@@ -438,6 +589,7 @@ func (s *Source) getAllProjectRepos(
 				}
 				continue
 			}
+			s.setVisibility(proj.HTTPURLToRepo, proj.Visibility)
 			// Report the unit.
 			ctx.Logger().V(3).Info("accepting project")
 			unit := git.SourceUnit{Kind: git.UnitRepo, ID: proj.HTTPURLToRepo}
@@ -457,6 +609,9 @@ func (s *Source) getAllProjectRepos(
 	listOpts := gitlab.ListOptions{PerPage: paginationLimit}
 
 	projectQueryOptions := &gitlab.ListProjectsOptions{OrderBy: gitlab.Ptr(orderBy), ListOptions: listOpts}
+	if s.selection.GetMaxSizeKb() > 0 {
+		projectQueryOptions.Statistics = gitlab.Ptr(true)
+	}
 	for {
 		userProjects, res, err := apiClient.Projects.ListUserProjects(user.ID, projectQueryOptions)
 		if err != nil {
@@ -546,6 +701,9 @@ func (s *Source) getAllProjectRepos(
 
 	ctx.Logger().Info("Enumerated GitLab projects", "count", len(projectsWithNamespace))
 	ctx.Logger().V(2).Info("Enumerated GitLab projects", "projects", projectsWithNamespace)
+	if s.tokens.Len() > 1 {
+		ctx.Logger().V(2).Info("token pool status after enumeration", "tokens", s.tokens.Status())
+	}
 
 	return nil
 }
@@ -593,7 +751,7 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk)
 				if user == "" {
 					user = "placeholder"
 				}
-				path, repo, err = git.CloneRepoUsingToken(ctx, s.token, repoURL, user)
+				path, repo, err = git.CloneRepoUsingToken(ctx, s.cloneToken(), repoURL, user)
 			}
 			if err != nil {
 				scanErrs.Add(err)
@@ -622,6 +780,15 @@ func (s *Source) scanRepos(ctx context.Context, chunksChan chan *sources.Chunk)
 	return nil
 }
 
+// cloneToken returns the token to clone a repository with, rotating between
+// the tokens of the source when it has several.
+func (s *Source) cloneToken() string {
+	if s.tokens.Len() > 0 {
+		return s.tokens.Next()
+	}
+	return s.token
+}
+
 // setProgressCompleteWithRepo calls the s.SetProgressComplete after safely setting up the encoded resume info string.
 func (s *Source) setProgressCompleteWithRepo(index int, offset int, repoURL string) {
 	s.resumeInfoMutex.Lock()
@@ -785,7 +952,7 @@ func (s *Source) ChunkUnit(ctx context.Context, unit sources.SourceUnit, reporte
 		if user == "" {
 			user = "placeholder"
 		}
-		path, repo, err = git.CloneRepoUsingToken(ctx, s.token, repoURL, user)
+		path, repo, err = git.CloneRepoUsingToken(ctx, s.cloneToken(), repoURL, user)
 	}
 	if err != nil {
 		return err
@@ -1,13 +1,19 @@
 package gitlab
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources/git"
 )
 
@@ -187,3 +193,57 @@ func Test_normalizeGitlabEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func Test_primaryLanguage(t *testing.T) {
+	assert.Equal(t, "Go", primaryLanguage(gitlab.ProjectLanguages{"Shell": 12.5, "Go": 80, "Makefile": 7.5}))
+	assert.Equal(t, "C", primaryLanguage(gitlab.ProjectLanguages{"Go": 50, "C": 50}))
+	assert.Equal(t, "", primaryLanguage(nil))
+}
+
+func Test_projectSkipReason(t *testing.T) {
+	var lookups []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups = append(lookups, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v4/projects/1/languages":
+			fmt.Fprint(w, `{"Go": 90.5, "Shell": 9.5}`)
+		case "/api/v4/projects/1":
+			fmt.Fprint(w, `{"id": 1, "statistics": {"repository_size": 2097152}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	apiClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(srv.URL))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	s := &Source{
+		exclude:   &sourcespb.RepoExclusions{Archived: true},
+		selection: &sourcespb.RepoSelection{Topics: []string{"service"}, Languages: []string{"go"}, MaxSizeKb: 4096},
+	}
+	assert.Equal(t, "archived", s.projectSkipReason(ctx, apiClient, &gitlab.Project{ID: 1, Archived: true}))
+	assert.Equal(t, "no selected topic", s.projectSkipReason(ctx, apiClient, &gitlab.Project{ID: 1, Topics: []string{"demo"}}))
+	assert.Empty(t, lookups)
+
+	// The language and size of projects matching the rest of the selection
+	// are looked up.
+	assert.Equal(t, "", s.projectSkipReason(ctx, apiClient, &gitlab.Project{ID: 1, Topics: []string{"service"}}))
+	assert.Equal(t, []string{"/api/v4/projects/1/languages", "/api/v4/projects/1"}, lookups)
+
+	s.selection.MaxSizeKb = 1024
+	assert.Equal(t, "size 2048 KB", s.projectSkipReason(ctx, apiClient, &gitlab.Project{ID: 1, Topics: []string{"service"}}))
+}
+
+func Test_visibilityOf(t *testing.T) {
+	s := &Source{}
+	s.setVisibility("https://gitlab.com/group/public.git", gitlab.PublicVisibility)
+	s.setVisibility("https://gitlab.com/group/internal.git", gitlab.InternalVisibility)
+	s.setVisibility("https://gitlab.com/group/private.git", gitlab.PrivateVisibility)
+
+	assert.Equal(t, source_metadatapb.Visibility_public, s.visibilityOf("https://gitlab.com/group/public.git"))
+	assert.Equal(t, source_metadatapb.Visibility_internal, s.visibilityOf("https://gitlab.com/group/internal.git"))
+	assert.Equal(t, source_metadatapb.Visibility_private, s.visibilityOf("https://gitlab.com/group/private.git"))
+	// Repositories that were configured rather than enumerated aren't known.
+	assert.Equal(t, source_metadatapb.Visibility_unknown, s.visibilityOf("https://gitlab.com/group/configured.git"))
+}
@@ -205,6 +205,16 @@ func (c *HFClient) ListReposByAuthor(ctx context.Context, resourceType string, a
 	return repos, err
 }
 
+// WhoAmI retrieves the name of the account the API key belongs to
+func (c *HFClient) WhoAmI(ctx context.Context) (string, error) {
+	var account struct {
+		Name string `json:"name"`
+	}
+	url := fmt.Sprintf("%s/%s/whoami-v2", c.BaseURL, APIRoute)
+	err := c.get(ctx, url, &account)
+	return account.Name, err
+}
+
 // getResourceAPIPath returns the API path for the given resource type
 func getResourceAPIPath(resourceType string) string {
 	return apiPaths[resourceType]
@@ -269,6 +269,18 @@ func (s *Source) Init(aCtx context.Context, name string, jobID sources.JobID, so
 	return nil
 }
 
+// HealthCheck checks that the token is still valid. Without a token there's
+// nothing to check before cloning.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if s.huggingfaceToken == "" {
+		return nil
+	}
+	if _, err := s.apiClient.WhoAmI(ctx); err != nil {
+		return fmt.Errorf("error checking token: %w", err)
+	}
+	return nil
+}
+
 func (s *Source) validateIgnoreIncludeRepos() error {
 	if err := verifySlashSeparatedStrings(s.conn.IgnoreModels); err != nil {
 		return err
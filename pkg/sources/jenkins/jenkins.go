@@ -148,6 +148,27 @@ func (s *Source) NewRequest(method, url string, body io.Reader) (*http.Request,
 	return request, nil
 }
 
+// HealthCheck requests the top level of the Jenkins API, which fails if the
+// endpoint can't be reached or rejects the credentials.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	apiURL := *s.url
+	apiURL.Path = path.Join(apiURL.Path, "api/json")
+	apiURL.RawQuery = "tree=mode"
+	req, err := s.NewRequest(http.MethodGet, apiURL.String(), nil)
+	if err != nil {
+		return errors.WrapPrefix(err, "Failed to create new request to check jenkins", 0)
+	}
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.WrapPrefix(err, "Failed to reach jenkins", 0)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(fmt.Sprintf("Received non-200 status from jenkins api: %d", resp.StatusCode))
+	}
+	return nil
+}
+
 // GetJenkinsJobs traverses the tree to find all jobs.
 // Example response from http://localhost:8080/api/json?tree=jobs[name,url]{0,100}
 // on our Jenkins instance:
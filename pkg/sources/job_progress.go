@@ -146,6 +146,13 @@ type JobProgressMetrics struct {
 	SourceEncodedResumeInfo string `json:"source_encoded_resume_info,omitempty"`
 	SourceSectionsCompleted int32  `json:"source_sections_completed,omitempty"`
 	SourceSectionsRemaining int32  `json:"source_sections_remaining,omitempty"`
+	// What the source is doing, how much it processed, and the progress of
+	// each of its repositories, for sources that report them.
+	SourceStage           ProgressStage          `json:"source_stage,omitempty"`
+	SourceBytesProcessed  uint64                 `json:"source_bytes_processed,omitempty"`
+	SourceChunksProcessed uint64                 `json:"source_chunks_processed,omitempty"`
+	SourceErrors          uint64                 `json:"source_errors,omitempty"`
+	SourceRepos           []RepoProgressSnapshot `json:"source_repos,omitempty"`
 }
 
 // WithHooks adds hooks to be called when an event triggers.
@@ -272,13 +279,17 @@ func (jp *JobProgress) Snapshot() JobProgressMetrics {
 	copy(metrics.Errors, jp.metrics.Errors)
 
 	if jp.progress != nil {
-		jp.progress.mut.Lock()
-		defer jp.progress.mut.Unlock()
-		metrics.SourcePercent = jp.progress.PercentComplete
-		metrics.SourceMessage = jp.progress.Message
-		metrics.SourceEncodedResumeInfo = jp.progress.EncodedResumeInfo
-		metrics.SourceSectionsCompleted = jp.progress.SectionsCompleted
-		metrics.SourceSectionsRemaining = jp.progress.SectionsRemaining
+		progress := jp.progress.Snapshot()
+		metrics.SourcePercent = progress.PercentComplete
+		metrics.SourceMessage = progress.Message
+		metrics.SourceEncodedResumeInfo = progress.EncodedResumeInfo
+		metrics.SourceSectionsCompleted = progress.SectionsCompleted
+		metrics.SourceSectionsRemaining = progress.SectionsRemaining
+		metrics.SourceStage = progress.Stage
+		metrics.SourceBytesProcessed = progress.BytesProcessed
+		metrics.SourceChunksProcessed = progress.ChunksProcessed
+		metrics.SourceErrors = progress.Errors
+		metrics.SourceRepos = progress.Repos
 	}
 
 	return metrics
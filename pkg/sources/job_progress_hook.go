@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
@@ -239,3 +240,15 @@ func (NoopHook) ReportError(JobProgressRef, error)                       {}
 func (NoopHook) ReportUnit(JobProgressRef, SourceUnit)                   {}
 func (NoopHook) ReportChunk(JobProgressRef, SourceUnit, *Chunk)          {}
 func (NoopHook) Finish(JobProgressRef)                                   {}
+
+// ErrorCountHook is a JobProgressHook that counts the errors reported by
+// every job it observes.
+type ErrorCountHook struct {
+	NoopHook
+	count atomic.Uint64
+}
+
+func (h *ErrorCountHook) ReportError(JobProgressRef, error) { h.count.Add(1) }
+
+// Count returns the number of errors reported so far.
+func (h *ErrorCountHook) Count() uint64 { return h.count.Load() }
@@ -0,0 +1,92 @@
+package sources
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// memoryBudget limits the bytes of chunk data held between sources and the
+// consumer of their chunks. Acquiring blocks while the budget is spent, which
+// pauses the source until earlier chunks are released.
+type memoryBudget struct {
+	size int64
+	sem  *semaphore.Weighted
+
+	// inFlight holds the bytes acquired for each chunk, since consumers may
+	// replace the data of the chunks they scan.
+	mu       sync.Mutex
+	inFlight map[*Chunk]int64
+}
+
+func newMemoryBudget(size int64) *memoryBudget {
+	return &memoryBudget{
+		size:     size,
+		sem:      semaphore.NewWeighted(size),
+		inFlight: make(map[*Chunk]int64),
+	}
+}
+
+// acquire waits until the data of chunk fits in the budget. A chunk larger
+// than the whole budget waits for everything else to be released instead.
+func (b *memoryBudget) acquire(ctx context.Context, chunk *Chunk) error {
+	if b == nil {
+		return nil
+	}
+	if b.tryAcquire(chunk) {
+		return nil
+	}
+	n := b.cost(chunk)
+	start := time.Now()
+	if err := b.sem.Acquire(ctx, n); err != nil {
+		return err
+	}
+	sourcesPausedSeconds.Add(time.Since(start).Seconds())
+	b.track(chunk, n)
+	return nil
+}
+
+// tryAcquire acquires the data of chunk if it fits in the budget without
+// waiting, and reports whether it did.
+func (b *memoryBudget) tryAcquire(chunk *Chunk) bool {
+	if b == nil {
+		return true
+	}
+	n := b.cost(chunk)
+	if !b.sem.TryAcquire(n) {
+		return false
+	}
+	b.track(chunk, n)
+	return true
+}
+
+func (b *memoryBudget) cost(chunk *Chunk) int64 {
+	return min(int64(len(chunk.Data)), b.size)
+}
+
+func (b *memoryBudget) track(chunk *Chunk, n int64) {
+	b.mu.Lock()
+	b.inFlight[chunk] = n
+	b.mu.Unlock()
+	chunkBytesInFlight.Add(float64(n))
+}
+
+// release returns the bytes acquired for chunk to the budget. Chunks that
+// weren't acquired are ignored.
+func (b *memoryBudget) release(chunk *Chunk) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	n, ok := b.inFlight[chunk]
+	delete(b.inFlight, chunk)
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	b.sem.Release(n)
+	chunkBytesInFlight.Sub(float64(n))
+}
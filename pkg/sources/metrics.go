@@ -21,4 +21,18 @@ var (
 		Name:      "hooks_channel_size",
 		Help:      "Total number of metrics waiting in the finished channel.",
 	}, nil)
+
+	chunkBytesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "chunk_bytes_in_flight",
+		Help:      "Bytes of chunk data counted against the memory budget, waiting to be or being scanned.",
+	})
+
+	sourcesPausedSeconds = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "sources_paused_seconds_total",
+		Help:      "Time sources spent paused because the memory budget was spent.",
+	})
 )
@@ -127,6 +127,25 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 	return nil
 }
 
+// HealthCheck checks that the local files exist and, with a token, that the
+// Postman API accepts it.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for _, paths := range [][]string{s.conn.EnvironmentPaths, s.conn.CollectionPaths, s.conn.WorkspacePaths} {
+		for _, p := range paths {
+			if _, err := os.Stat(p); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if s.client != nil {
+		if _, err := s.client.EnumerateWorkspaces(); err != nil {
+			errs = append(errs, fmt.Errorf("error enumerating postman workspaces: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Chunks scans the Postman source and sends the data to the chunks chan.
 // It scans the local environment, collection, and workspace files, and then scans the Postman API if a token is provided.
 // The Postman source is different to our other sources in that we are not relying on the data we read from the source to contain
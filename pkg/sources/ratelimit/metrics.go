@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
+)
+
+var (
+	rateLimitWaits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "rate_limit_waits_total",
+		Help:      "Total number of requests that waited on an API rate limit.",
+	}, []string{"api"})
+
+	rateLimitSecondsWaited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "rate_limit_seconds_waited_total",
+		Help:      "Time requests spent waiting on API rate limits.",
+	}, []string{"api"})
+
+	tokenRotations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: common.MetricsNamespace,
+		Subsystem: common.MetricsSubsystem,
+		Name:      "rate_limit_token_rotations_total",
+		Help:      "Total number of times a token pool switched to another token.",
+	}, []string{"api"})
+)
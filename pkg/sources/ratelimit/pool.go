@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Pool is a set of tokens of one API that requests rotate between, so that a
+// scan can use the quota of every token instead of waiting on the first one
+// to be spent. The tokens should have the same access, since any of them may
+// be used for a request.
+type Pool struct {
+	limiter *Limiter
+	tokens  []string
+
+	mu   sync.Mutex
+	last string
+}
+
+// NewPool returns a pool of tokens, without duplicates, whose rate limits
+// are tracked by limiter.
+func NewPool(limiter *Limiter, tokens ...string) *Pool {
+	p := &Pool{limiter: limiter}
+	for _, token := range tokens {
+		if !slices.Contains(p.tokens, token) {
+			p.tokens = append(p.tokens, token)
+		}
+	}
+	return p
+}
+
+// Len returns the number of tokens in the pool.
+func (p *Pool) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.tokens)
+}
+
+// Tokens returns the tokens of the pool.
+func (p *Pool) Tokens() []string {
+	if p == nil {
+		return nil
+	}
+	return slices.Clone(p.tokens)
+}
+
+// Next returns the token to send the next request with, as picked by
+// Limiter.Pick.
+func (p *Pool) Next() string {
+	if p.Len() == 0 {
+		return ""
+	}
+	token := p.limiter.Pick(p.tokens...)
+	if token == "" {
+		token = p.tokens[0]
+	}
+	p.mu.Lock()
+	rotated := p.last != "" && p.last != token
+	p.last = token
+	p.mu.Unlock()
+	if rotated {
+		tokenRotations.WithLabelValues(p.limiter.name).Inc()
+	}
+	return token
+}
+
+// Ready reports whether a token of the pool can be used without waiting on
+// its rate limit.
+func (p *Pool) Ready() bool {
+	if p.Len() == 0 {
+		return false
+	}
+	return p.limiter.Ready(p.limiter.Pick(p.tokens...))
+}
+
+// TokenStatus is the health of a token of a pool.
+type TokenStatus struct {
+	// Key identifies the token without revealing it.
+	Key string `json:"key"`
+	// Remaining is the number of requests left in the quota of the token,
+	// or -1 if it isn't known.
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	// RateLimited counts the responses to the token that were rate limited.
+	RateLimited int `json:"rate_limited"`
+	// Rejected is set while the API rejects the token as invalid.
+	Rejected bool `json:"rejected"`
+}
+
+// Status returns the health of each token of the pool.
+func (p *Pool) Status() []TokenStatus {
+	if p.Len() == 0 {
+		return nil
+	}
+	l := p.limiter
+	statuses := make([]TokenStatus, 0, len(p.tokens))
+	for _, token := range p.tokens {
+		status := TokenStatus{Key: tokenKey(token), Remaining: -1}
+		if l != nil {
+			l.mu.Lock()
+			if q, ok := l.quotas[status.Key]; ok {
+				status.Remaining = q.remaining
+				status.Reset = q.reset
+				status.RateLimited = q.limited
+				status.Rejected = q.rejected
+			}
+			l.mu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Rotation(t *testing.T) {
+	l, clock := newTestLimiter()
+	remaining := map[string]int{"Bearer a": 2, "Bearer b": 2, "Bearer revoked": 100}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth == "Bearer revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		remaining[auth]--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining[auth]))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(l.now().Add(time.Hour).Unix(), 10))
+	}))
+	defer srv.Close()
+
+	pool := NewPool(l, "revoked", "a", "b", "a")
+	assert.Equal(t, []string{"revoked", "a", "b"}, pool.Tokens())
+	client := &http.Client{Transport: l.Transport(nil)}
+	var used []string
+	for i := 0; i < 5; i++ {
+		require.True(t, pool.Ready())
+		token := pool.Next()
+		used = append(used, token)
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	// The revoked token is dropped once rejected, and the others take turns
+	// until both are spent.
+	assert.Equal(t, []string{"revoked", "a", "b", "a", "b"}, used)
+	assert.Empty(t, clock.sleeps)
+	assert.False(t, pool.Ready())
+
+	status := pool.Status()
+	require.Len(t, status, 3)
+	assert.True(t, status[0].Rejected)
+	assert.Equal(t, tokenKey("a"), status[1].Key)
+	assert.Equal(t, 0, status[1].Remaining)
+	assert.False(t, status[1].Rejected)
+}
+
+func TestPool_AllRejected(t *testing.T) {
+	l, _ := newTestLimiter()
+	l.quotas[tokenKey("a")] = &quota{remaining: -1, rejected: true}
+	l.quotas[tokenKey("b")] = &quota{remaining: -1, rejected: true}
+
+	pool := NewPool(l, "a", "b")
+	assert.Equal(t, "a", pool.Next())
+	assert.False(t, pool.Ready())
+}
+
+func TestPool_Empty(t *testing.T) {
+	var pool *Pool
+	assert.Equal(t, 0, pool.Len())
+	assert.Equal(t, "", pool.Next())
+	assert.False(t, pool.Ready())
+	assert.Nil(t, pool.Status())
+}
@@ -0,0 +1,412 @@
+// Package ratelimit paces the requests sources make to APIs that publish their
+// rate limits in response headers, such as the X-RateLimit-* headers of GitHub
+// and the RateLimit-* headers of GitLab. Requests wait while the quota of
+// their credential is spent, or for the time a Retry-After header asks for,
+// and the wait is shown in the progress of the source so that a source
+// waiting on a limit doesn't look hung.
+package ratelimit
+
+import (
+	stdcontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// maxRetries is how many times a rate limited request is retried once its
+// wait is over.
+const maxRetries = 5
+
+// Limiter tracks the rate limits of one API, separately for each credential
+// requests are made with. It is safe for concurrent use, and a nil Limiter
+// doesn't limit anything.
+type Limiter struct {
+	name     string
+	progress *sources.Progress
+
+	mu      sync.Mutex
+	quotas  map[string]*quota
+	waiting int
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx stdcontext.Context, d time.Duration) error
+}
+
+// quota is what's known of the rate limit of a credential.
+type quota struct {
+	// remaining is -1 until a response reports it.
+	remaining int
+	reset     time.Time
+	// retryAt is when a Retry-After header allows requests again.
+	retryAt time.Time
+	// limited counts the responses that were rate limited.
+	limited int
+	// rejected is set while the API rejects the credential as invalid.
+	rejected bool
+}
+
+// Option configures a Limiter.
+type Option func(*Limiter)
+
+// WithProgress shows the waits of the limiter in progress.
+func WithProgress(progress *sources.Progress) Option {
+	return func(l *Limiter) { l.progress = progress }
+}
+
+// New returns a limiter of the API called name, such as "GitHub".
+func New(name string, opts ...Option) *Limiter {
+	l := &Limiter{
+		name:   name,
+		quotas: make(map[string]*quota),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func sleep(ctx stdcontext.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Transport returns a RoundTripper that waits before sending a request while
+// the quota of its credential is spent, and waits and retries requests that
+// were rate limited. It must wrap the transport that sends requests, below
+// whatever adds their credentials and above any client timeout, which would
+// otherwise cut waits short.
+func (l *Limiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{limiter: l, base: base}
+}
+
+type transport struct {
+	limiter *Limiter
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := requestKey(req)
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.waitFor(req.Context(), key); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if !t.limiter.update(req.Context(), key, resp) || attempt == maxRetries {
+			return resp, nil
+		}
+		// Retry once the limit allows it, if the request can be sent again.
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<12))
+		resp.Body.Close()
+	}
+}
+
+// Wait blocks until the time until, showing the wait in the progress of the
+// limiter. It is for clients that detect rate limits themselves, without
+// sending requests through Transport.
+func (l *Limiter) Wait(ctx stdcontext.Context, until time.Time) error {
+	if l == nil {
+		return sleep(ctx, time.Until(until))
+	}
+	return l.wait(ctx, until)
+}
+
+// waitFor waits until the quota of key allows a request, and counts the
+// request against it.
+func (l *Limiter) waitFor(ctx stdcontext.Context, key string) error {
+	for {
+		l.mu.Lock()
+		q := l.quota(key)
+		until := q.availableAt(l.now())
+		if until.IsZero() {
+			if q.remaining > 0 {
+				q.remaining--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+		if err := l.wait(ctx, until); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *Limiter) wait(ctx stdcontext.Context, until time.Time) error {
+	d := until.Sub(l.now())
+	if d <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	l.waiting++
+	first := l.waiting == 1
+	if first && l.progress != nil {
+		l.progress.SetProgressWaiting(fmt.Sprintf("Waiting on %s rate limit until %s", l.name, until.Format(time.TimeOnly)))
+	}
+	l.mu.Unlock()
+	// Concurrent requests usually wait on the same limit, so only the first
+	// wait is logged at the default level.
+	logger := context.AddLogger(ctx).Logger()
+	if !first {
+		logger = logger.V(2)
+	}
+	logger.Info("waiting on rate limit", "api", l.name, "wait", d.Round(time.Second).String(), "resume_time", until.Format(time.RFC3339))
+	rateLimitWaits.WithLabelValues(l.name).Inc()
+	rateLimitSecondsWaited.WithLabelValues(l.name).Add(d.Seconds())
+
+	err := l.sleep(ctx, d)
+
+	l.mu.Lock()
+	l.waiting--
+	if l.waiting == 0 && l.progress != nil {
+		l.progress.SetProgressWaiting("")
+	}
+	l.mu.Unlock()
+	return err
+}
+
+// update records the rate limit headers of resp, and reports whether the
+// request was rate limited.
+func (l *Limiter) update(ctx stdcontext.Context, key string, resp *http.Response) bool {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	q := l.quota(key)
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		if !q.rejected && key != "" {
+			context.AddLogger(ctx).Logger().Info("credential rejected, it won't be picked while others are valid", "api", l.name, "token", key)
+		}
+		q.rejected = true
+	case resp.StatusCode < http.StatusBadRequest:
+		q.rejected = false
+	}
+	if remaining, ok := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining"); ok {
+		q.remaining = remaining
+	}
+	if reset, ok := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+		q.reset = resetTime(now, reset)
+	}
+	retryAfter, hasRetryAfter := parseRetryAfter(now, resp.Header.Get("Retry-After"))
+	if hasRetryAfter {
+		q.retryAt = retryAfter
+	}
+
+	if !IsRateLimited(resp) {
+		return false
+	}
+	q.limited++
+	if !hasRetryAfter && q.remaining != 0 {
+		// Limited without saying for how long, as GitHub does for some of its
+		// secondary limits.
+		q.retryAt = now.Add(time.Minute)
+	}
+	return true
+}
+
+// quota returns the quota of key. l.mu must be held.
+func (l *Limiter) quota(key string) *quota {
+	q, ok := l.quotas[key]
+	if !ok {
+		q = &quota{remaining: -1}
+		l.quotas[key] = q
+	}
+	return q
+}
+
+// availableAt returns when the quota allows the next request, or the zero
+// time if it allows one now.
+func (q *quota) availableAt(now time.Time) time.Time {
+	var until time.Time
+	if q.retryAt.After(now) {
+		until = q.retryAt
+	}
+	if q.remaining == 0 && q.reset.After(now) && q.reset.After(until) {
+		until = q.reset
+	}
+	if until.IsZero() && q.remaining == 0 {
+		// The quota was reset, so how much of it is left is unknown again.
+		q.remaining = -1
+	}
+	return until
+}
+
+// Pick returns the token whose quota allows requests soonest. Among those
+// available, tokens that haven't been used yet come first, then the ones with
+// the most requests left. Tokens the API rejected are only picked if all of
+// them were. It returns "" if tokens is empty.
+func (l *Limiter) Pick(tokens ...string) string {
+	if l == nil || len(tokens) == 0 {
+		return ""
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if token, ok := l.pick(now, tokens, false); ok {
+		return token
+	}
+	token, _ := l.pick(now, tokens, true)
+	return token
+}
+
+// pick returns the best of tokens, skipping rejected ones unless
+// withRejected is set. l.mu must be held.
+func (l *Limiter) pick(now time.Time, tokens []string, withRejected bool) (string, bool) {
+	var (
+		best, found   = "", false
+		bestAt        time.Time
+		bestRemaining int
+	)
+	for _, token := range tokens {
+		at, remaining := now, math.MaxInt
+		if q, ok := l.quotas[tokenKey(token)]; ok {
+			if q.rejected && !withRejected {
+				continue
+			}
+			if until := q.availableAt(now); !until.IsZero() {
+				at = until
+			}
+			if q.remaining >= 0 {
+				remaining = q.remaining
+			}
+		}
+		if !found || at.Before(bestAt) || (at.Equal(bestAt) && remaining > bestRemaining) {
+			best, found, bestAt, bestRemaining = token, true, at, remaining
+		}
+	}
+	return best, found
+}
+
+// Ready reports whether token can be used now: it isn't rejected and its
+// quota allows a request.
+func (l *Limiter) Ready(token string) bool {
+	if l == nil {
+		return true
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	q, ok := l.quotas[tokenKey(token)]
+	return !ok || (!q.rejected && q.availableAt(now).IsZero())
+}
+
+// IsRateLimited reports whether resp rejected its request because of a rate
+// limit: a 429, or a 403 with a Retry-After header or an exhausted quota.
+func IsRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if resp.Header.Get("Retry-After") != "" {
+			return true
+		}
+		remaining, ok := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+		return ok && remaining == 0
+	default:
+		return false
+	}
+}
+
+// CheckRetry is the retry policy of common.RetryableHTTPClient, except that
+// rate limited responses are returned to be retried by Transport.
+func CheckRetry(ctx stdcontext.Context, resp *http.Response, err error) (bool, error) {
+	if err == nil && resp != nil && IsRateLimited(resp) {
+		return false, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+// requestKey identifies the credential of req, without keeping it.
+func requestKey(req *http.Request) string {
+	for _, header := range []string{"Authorization", "Private-Token", "Job-Token"} {
+		if v := req.Header.Get(header); v != "" {
+			// Drop the scheme of Authorization headers, such as "Bearer".
+			fields := strings.Fields(v)
+			return tokenKey(fields[len(fields)-1])
+		}
+	}
+	return ""
+}
+
+func tokenKey(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+func headerInt(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// resetTime interprets a reset header, which is a Unix time for GitHub and
+// GitLab but a number of seconds for APIs following the IETF RateLimit
+// header draft.
+func resetTime(now time.Time, reset int) time.Time {
+	const unixTimeThreshold = 1_000_000_000
+	if reset >= unixTimeThreshold {
+		return time.Unix(int64(reset), 0)
+	}
+	return now.Add(time.Duration(reset) * time.Second)
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(now time.Time, v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
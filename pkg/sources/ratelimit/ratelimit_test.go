@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	stdcontext "context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
+)
+
+// fakeClock advances when the limiter sleeps.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newTestLimiter(opts ...Option) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := New("Test", opts...)
+	l.now = func() time.Time {
+		clock.mu.Lock()
+		defer clock.mu.Unlock()
+		return clock.now
+	}
+	l.sleep = func(_ stdcontext.Context, d time.Duration) error {
+		clock.mu.Lock()
+		defer clock.mu.Unlock()
+		clock.sleeps = append(clock.sleeps, d)
+		clock.now = clock.now.Add(d)
+		return nil
+	}
+	return l, clock
+}
+
+func TestTransport_RetryAfter(t *testing.T) {
+	l, clock := newTestLimiter()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body := make([]byte, r.ContentLength)
+		_, _ = r.Body.Read(body)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: l.Transport(nil)}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []time.Duration{30 * time.Second}, clock.sleeps)
+}
+
+func TestTransport_ExhaustedQuota(t *testing.T) {
+	l, clock := newTestLimiter()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		reset := l.now().Add(time.Hour).Unix()
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(2-requests))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	get := func(token string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := (&http.Client{Transport: l.Transport(nil)}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	get("a")
+	get("a")
+	assert.Empty(t, clock.sleeps)
+	// Another token has its own quota.
+	get("b")
+	assert.Empty(t, clock.sleeps)
+	// The quota of a is spent until the reset an hour after the second request.
+	get("a")
+	assert.Equal(t, []time.Duration{time.Hour}, clock.sleeps)
+	assert.Equal(t, 4, requests)
+}
+
+func TestTransport_SecondaryLimit(t *testing.T) {
+	l, clock := newTestLimiter()
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		if requests == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if requests == 2 {
+			// Forbidden for another reason.
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := (&http.Client{Transport: l.Transport(nil)}).Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []time.Duration{time.Minute}, clock.sleeps)
+}
+
+func TestWait_Progress(t *testing.T) {
+	var progress sources.Progress
+	progress.SetProgressComplete(1, 4, "Scanning repo 1", "resume")
+	l := New("Test", WithProgress(&progress))
+
+	var waited bool
+	l.sleep = func(stdcontext.Context, time.Duration) error {
+		assert.True(t, strings.HasPrefix(progress.GetProgress().Message, "Waiting on Test rate limit until "))
+		assert.Equal(t, "resume", progress.GetProgress().EncodedResumeInfo)
+		assert.Equal(t, int32(1), progress.GetProgress().SectionsCompleted)
+		waited = true
+		return nil
+	}
+	require.NoError(t, l.Wait(context.Background(), time.Now().Add(time.Minute)))
+	assert.True(t, waited)
+	assert.Equal(t, "Scanning repo 1", progress.GetProgress().Message)
+
+	// Waiting in the past doesn't wait.
+	l.sleep = func(stdcontext.Context, time.Duration) error {
+		t.Fatal("unexpected wait")
+		return nil
+	}
+	require.NoError(t, l.Wait(context.Background(), time.Now().Add(-time.Second)))
+}
+
+func TestPick(t *testing.T) {
+	l, _ := newTestLimiter()
+	now := l.now()
+	l.quotas[tokenKey("spent")] = &quota{remaining: 0, reset: now.Add(time.Hour)}
+	l.quotas[tokenKey("soon")] = &quota{remaining: 0, reset: now.Add(time.Minute)}
+	l.quotas[tokenKey("low")] = &quota{remaining: 10, reset: now.Add(time.Hour)}
+	l.quotas[tokenKey("high")] = &quota{remaining: 1000, reset: now.Add(time.Hour)}
+
+	assert.Equal(t, "high", l.Pick("spent", "low", "high"))
+	assert.Equal(t, "unused", l.Pick("low", "high", "unused"))
+	assert.Equal(t, "soon", l.Pick("spent", "soon"))
+	assert.Equal(t, "", l.Pick())
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := map[string]struct {
+		status  int
+		headers map[string]string
+		want    bool
+	}{
+		"too many requests":   {status: http.StatusTooManyRequests, want: true},
+		"retry after":         {status: http.StatusForbidden, headers: map[string]string{"Retry-After": "60"}, want: true},
+		"exhausted":           {status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0"}, want: true},
+		"gitlab exhausted":    {status: http.StatusForbidden, headers: map[string]string{"RateLimit-Remaining": "0"}, want: true},
+		"forbidden":           {status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "12"}},
+		"ok with spent quota": {status: http.StatusOK, headers: map[string]string{"X-RateLimit-Remaining": "0"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, IsRateLimited(resp))
+		})
+	}
+}
+
+func TestResetTime(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	assert.Equal(t, time.Unix(1_700_003_600, 0), resetTime(now, 1_700_003_600))
+	assert.Equal(t, now.Add(90*time.Second), resetTime(now, 90))
+
+	at, ok := parseRetryAfter(now, "Wed, 15 Nov 2023 00:00:00 GMT")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC), at.UTC())
+	_, ok = parseRetryAfter(now, "soon")
+	assert.False(t, ok)
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	base := http.DefaultTransport
+	assert.Equal(t, base, l.Transport(base))
+	assert.Equal(t, "", l.Pick("a"))
+	assert.NoError(t, l.Wait(context.Background(), time.Now()))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/go-errors/errors"
@@ -23,6 +24,7 @@ import (
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/handlers"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/incremental"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sanitizer"
@@ -117,6 +119,11 @@ func (s *Source) Validate(ctx context.Context) []error {
 	return errs
 }
 
+// HealthCheck checks that the buckets can be read with each configured role.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return errors.Join(s.Validate(ctx)...)
+}
+
 // setMaxObjectSize sets the maximum size of objects that will be scanned. If
 // not set, set to a negative number, or set larger than the
 // maxObjectSizeLimit, the defaultMaxObjectSizeLimit will be used.
@@ -219,11 +226,12 @@ func (s *Source) scanBuckets(ctx context.Context, client *s3.S3, role string, bu
 		}
 
 		errorCount := sync.Map{}
+		visibility := bucketVisibility(ctx, regionalClient, bucket)
 
 		err = regionalClient.ListObjectsV2PagesWithContext(
 			ctx, &s3.ListObjectsV2Input{Bucket: &bucket},
 			func(page *s3.ListObjectsV2Output, last bool) bool {
-				s.pageChunker(ctx, regionalClient, chunksChan, bucket, page, &errorCount, i+1, &objectCount)
+				s.pageChunker(ctx, regionalClient, chunksChan, bucket, visibility, page, &errorCount, i+1, &objectCount)
 				return true
 			})
 
@@ -270,8 +278,49 @@ func (s *Source) getRegionalClientForBucket(ctx context.Context, defaultRegionCl
 	return regionalClient, nil
 }
 
+// publicGroups are the URIs of the grantees that grant an ACL to anyone,
+// signed in to AWS or not.
+var publicGroups = map[string]struct{}{
+	"http://acs.amazonaws.com/groups/global/AllUsers":           {},
+	"http://acs.amazonaws.com/groups/global/AuthenticatedUsers": {},
+}
+
+// bucketVisibility tells whether anyone can read the objects of bucket,
+// because its policy or its ACL makes it public, unless its public access
+// block ignores the ACL. It's unknown if the ACL can't be read.
+func bucketVisibility(ctx context.Context, client s3iface.S3API, bucket string) source_metadatapb.Visibility {
+	// Buckets without a policy have no policy status to read.
+	policy, err := client.GetBucketPolicyStatusWithContext(ctx, &s3.GetBucketPolicyStatusInput{Bucket: &bucket})
+	if err == nil && policy.PolicyStatus != nil && aws.BoolValue(policy.PolicyStatus.IsPublic) {
+		return source_metadatapb.Visibility_public
+	}
+
+	acl, err := client.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{Bucket: &bucket})
+	if err != nil {
+		ctx.Logger().V(3).Info("could not tell whether bucket is public", "error", err)
+		return source_metadatapb.Visibility_unknown
+	}
+	block, err := client.GetPublicAccessBlockWithContext(ctx, &s3.GetPublicAccessBlockInput{Bucket: &bucket})
+	if err == nil && block.PublicAccessBlockConfiguration != nil && aws.BoolValue(block.PublicAccessBlockConfiguration.IgnorePublicAcls) {
+		return source_metadatapb.Visibility_private
+	}
+	for _, grant := range acl.Grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		if _, ok := publicGroups[aws.StringValue(grant.Grantee.URI)]; !ok {
+			continue
+		}
+		switch aws.StringValue(grant.Permission) {
+		case s3.PermissionRead, s3.PermissionFullControl:
+			return source_metadatapb.Visibility_public
+		}
+	}
+	return source_metadatapb.Visibility_private
+}
+
 // pageChunker emits chunks onto the given channel from a page
-func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, page *s3.ListObjectsV2Output, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
+func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan *sources.Chunk, bucket string, visibility source_metadatapb.Visibility, page *s3.ListObjectsV2Output, errorCount *sync.Map, pageNumber int, objectCount *uint64) {
 	for _, obj := range page.Contents {
 		obj := obj
 		if common.IsDone(ctx) {
@@ -301,11 +350,19 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 		}
 
 		// skip incompatible extensions
-		if common.SkipFile(*obj.Key) {
+		if handlers.SkipFile(ctx, *obj.Key) {
 			s.log.V(5).Info("Skipping file with incompatible extension", "object", *obj.Key)
 			continue
 		}
 
+		// skip objects that haven't changed since the last incremental scan
+		objectURL := "s3://" + bucket + "/" + *obj.Key
+		etag := aws.StringValue(obj.ETag)
+		if incremental.FromContext(ctx).Unchanged(objectURL, etag) {
+			s.log.V(5).Info("Skipping object unchanged since last scan", "object", *obj.Key)
+			continue
+		}
+
 		s.jobPool.Go(func() error {
 			defer common.RecoverWithExit(ctx)
 
@@ -370,11 +427,12 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				SourceMetadata: &source_metadatapb.MetaData{
 					Data: &source_metadatapb.MetaData_S3{
 						S3: &source_metadatapb.S3{
-							Bucket:    bucket,
-							File:      sanitizer.UTF8(*obj.Key),
-							Link:      sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
-							Email:     sanitizer.UTF8(email),
-							Timestamp: sanitizer.UTF8(modified),
+							Bucket:     bucket,
+							File:       sanitizer.UTF8(*obj.Key),
+							Link:       sanitizer.UTF8(makeS3Link(bucket, *client.Config.Region, *obj.Key)),
+							Email:      sanitizer.UTF8(email),
+							Timestamp:  sanitizer.UTF8(modified),
+							Visibility: visibility,
 						},
 					},
 				},
@@ -385,6 +443,7 @@ func (s *Source) pageChunker(ctx context.Context, client *s3.S3, chunksChan chan
 				ctx.Logger().Error(err, "error handling file")
 				return nil
 			}
+			incremental.FromContext(ctx).SetObject(objectURL, etag)
 
 			atomic.AddUint64(objectCount, 1)
 			s.log.V(5).Info("S3 object scanned.", "object_count", objectCount, "page_number", pageNumber)
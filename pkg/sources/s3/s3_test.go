@@ -8,11 +8,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/kylelemons/godebug/pretty"
 	"github.com/stretchr/testify/assert"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/credentialspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/sources"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -139,3 +144,87 @@ func TestSource_Chunks(t *testing.T) {
 		})
 	}
 }
+
+// fakeBucketClient answers the calls bucketVisibility makes with the
+// responses of a bucket, or with err where they're nil.
+type fakeBucketClient struct {
+	s3iface.S3API
+	policy *s3.PolicyStatus
+	acl    []*s3.Grant
+	block  *s3.PublicAccessBlockConfiguration
+}
+
+var errFakeDenied = fmt.Errorf("AccessDenied")
+
+func (c fakeBucketClient) GetBucketPolicyStatusWithContext(aws.Context, *s3.GetBucketPolicyStatusInput, ...request.Option) (*s3.GetBucketPolicyStatusOutput, error) {
+	if c.policy == nil {
+		return nil, errFakeDenied
+	}
+	return &s3.GetBucketPolicyStatusOutput{PolicyStatus: c.policy}, nil
+}
+
+func (c fakeBucketClient) GetBucketAclWithContext(aws.Context, *s3.GetBucketAclInput, ...request.Option) (*s3.GetBucketAclOutput, error) {
+	if c.acl == nil {
+		return nil, errFakeDenied
+	}
+	return &s3.GetBucketAclOutput{Grants: c.acl}, nil
+}
+
+func (c fakeBucketClient) GetPublicAccessBlockWithContext(aws.Context, *s3.GetPublicAccessBlockInput, ...request.Option) (*s3.GetPublicAccessBlockOutput, error) {
+	if c.block == nil {
+		return nil, errFakeDenied
+	}
+	return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: c.block}, nil
+}
+
+func TestBucketVisibility(t *testing.T) {
+	owner := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeCanonicalUser), ID: aws.String("owner")},
+		Permission: aws.String(s3.PermissionFullControl),
+	}
+	allUsers := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String("http://acs.amazonaws.com/groups/global/AllUsers")},
+		Permission: aws.String(s3.PermissionRead),
+	}
+	allUsersACP := &s3.Grant{
+		Grantee:    &s3.Grantee{Type: aws.String(s3.TypeGroup), URI: aws.String("http://acs.amazonaws.com/groups/global/AllUsers")},
+		Permission: aws.String(s3.PermissionReadAcp),
+	}
+
+	tests := []struct {
+		name   string
+		client fakeBucketClient
+		want   source_metadatapb.Visibility
+	}{
+		{
+			name:   "public policy",
+			client: fakeBucketClient{policy: &s3.PolicyStatus{IsPublic: aws.Bool(true)}},
+			want:   source_metadatapb.Visibility_public,
+		},
+		{
+			name:   "public acl",
+			client: fakeBucketClient{acl: []*s3.Grant{owner, allUsers}},
+			want:   source_metadatapb.Visibility_public,
+		},
+		{
+			name:   "public acl ignored by access block",
+			client: fakeBucketClient{acl: []*s3.Grant{owner, allUsers}, block: &s3.PublicAccessBlockConfiguration{IgnorePublicAcls: aws.Bool(true)}},
+			want:   source_metadatapb.Visibility_private,
+		},
+		{
+			name:   "acl only public to read itself",
+			client: fakeBucketClient{policy: &s3.PolicyStatus{IsPublic: aws.Bool(false)}, acl: []*s3.Grant{owner, allUsersACP}},
+			want:   source_metadatapb.Visibility_private,
+		},
+		{
+			name:   "nothing readable",
+			client: fakeBucketClient{},
+			want:   source_metadatapb.Visibility_unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bucketVisibility(context.Background(), tt.client, "bucket"))
+		})
+	}
+}
@@ -9,11 +9,13 @@ import (
 	"time"
 
 	"github.com/marusama/semaphore/v2"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tracing"
 )
 
 // SourceManager provides an interface for starting and managing running
@@ -30,8 +32,23 @@ type SourceManager struct {
 	// Run the sources using source unit enumeration / chunking if available.
 	// Checked at runtime to allow feature flagging.
 	useSourceUnitsFunc func() bool
-	// Downstream chunks channel to be scanned.
-	outputChunks chan *Chunk
+	// Saves and restores the progress of sources, if set.
+	checkpoints *checkpointer
+	// Scans units outside of this process, if set.
+	unitDispatcher UnitDispatcher
+	// Provides units enumerated outside of this process, if set.
+	unitLeaser UnitLeaser
+	// Downstream channel of the batches of chunks to be scanned.
+	outputChunks chan []*Chunk
+	// The chunks of outputChunks one at a time, once Chunks() is called.
+	chunksOnce sync.Once
+	chunks     chan *Chunk
+	// Limits the chunk data buffered for the consumer of outputChunks, if set.
+	budget *memoryBudget
+	// Sizes of the chunks sources split data into, if set.
+	chunkSize, peekSize int
+	// Applied to the context of every source run, if set.
+	sourceContext func(context.Context) context.Context
 	// Set when Wait() returns.
 	firstErr chan error
 	waitErr  error
@@ -73,9 +90,18 @@ func WithConcurrentTargets(concurrency int) func(*SourceManager) {
 	}
 }
 
-// WithBufferedOutput sets the size of the buffer used for the Chunks() channel.
+// WithBufferedOutput sets the size of the buffer used for the Chunks() and
+// ChunkBatches() channels.
 func WithBufferedOutput(size int) func(*SourceManager) {
-	return func(mgr *SourceManager) { mgr.outputChunks = make(chan *Chunk, size) }
+	return func(mgr *SourceManager) { mgr.outputChunks = make(chan []*Chunk, size) }
+}
+
+// WithMemoryBudget limits the data of the chunks buffered between the
+// manager's sources and the consumer of Chunks() to size bytes. Sources are
+// paused once the budget is spent, until the consumer releases the chunks it
+// is done with using ReleaseChunk.
+func WithMemoryBudget(size int64) func(*SourceManager) {
+	return func(mgr *SourceManager) { mgr.budget = newMemoryBudget(size) }
 }
 
 // WithSourceUnits enables using source unit enumeration and chunking if the
@@ -110,7 +136,7 @@ func NewManager(opts ...func(*SourceManager)) *SourceManager {
 		api:          &headlessAPI{},
 		sem:          semaphore.New(runtime.NumCPU()),
 		prioritySem:  semaphore.New(runtime.NumCPU()),
-		outputChunks: make(chan *Chunk, defaultChannelSize),
+		outputChunks: make(chan []*Chunk, defaultChannelSize),
 		firstErr:     make(chan error, 1),
 	}
 	for _, opt := range opts {
@@ -170,13 +196,32 @@ func (s *SourceManager) Run(ctx context.Context, sourceName string, source Sourc
 }
 
 // Chunks returns the read only channel of all the chunks produced by all of
-// the sources managed by this manager.
+// the sources managed by this manager. It receives the chunks of
+// ChunkBatches() one at a time, so only one of them should be used.
 func (s *SourceManager) Chunks() <-chan *Chunk {
+	s.chunksOnce.Do(func() {
+		s.chunks = make(chan *Chunk, cap(s.outputChunks))
+		go func() {
+			defer close(s.chunks)
+			for batch := range s.outputChunks {
+				for _, chunk := range batch {
+					s.chunks <- chunk
+				}
+			}
+		}()
+	})
+	return s.chunks
+}
+
+// ChunkBatches returns the read only channel of the chunks produced by all of
+// the sources managed by this manager, in the batches the sources' units
+// emitted them in. Sources that don't scan units emit them one at a time.
+func (s *SourceManager) ChunkBatches() <-chan []*Chunk {
 	return s.outputChunks
 }
 
-// Wait blocks until all running sources are completed and closes the channel
-// returned by Chunks(). The manager should not be reused after calling this
+// Wait blocks until all running sources are completed and closes the channels
+// returned by Chunks() and ChunkBatches(). The manager should not be reused after calling this
 // method. This current implementation is not thread safe and should only be
 // called by one thread.
 func (s *SourceManager) Wait() error {
@@ -206,7 +251,27 @@ func (s *SourceManager) Wait() error {
 // This method should rarely be used. TODO(THOG-1577): Remove when dependencies
 // no longer rely on this functionality.
 func (s *SourceManager) ScanChunk(chunk *Chunk) {
-	s.outputChunks <- chunk
+	_ = s.budget.acquire(context.Background(), chunk)
+	s.outputChunks <- []*Chunk{chunk}
+}
+
+// ReleaseChunk returns the data of a chunk received from Chunks() or
+// ChunkBatches() to the memory budget once it has been scanned, and to the
+// pool if it was taken from it with AllocData. The data of the chunk must not
+// be used after.
+func (s *SourceManager) ReleaseChunk(chunk *Chunk) {
+	s.budget.release(chunk)
+	chunk.FreeData()
+}
+
+// chunkBufferSize is the size of the channels chunks are buffered in before
+// reaching outputChunks. With a memory budget, they're unbuffered so chunks
+// only wait once they're accounted for.
+func (s *SourceManager) chunkBufferSize() int {
+	if s.budget != nil {
+		return 0
+	}
+	return defaultChannelSize
 }
 
 // AvailableCapacity returns the number of concurrent jobs the manager can
@@ -235,6 +300,27 @@ func (s *SourceManager) SetMaxConcurrentSources(maxRunCount int) {
 	s.sem.SetLimit(maxRunCount)
 }
 
+// SetChunkSizes sets the sizes of the chunks the sources it runs split data
+// into, and how many bytes consecutive chunks share, as WithChunkSizes does.
+// It must be called before any source is run.
+func (s *SourceManager) SetChunkSizes(chunkSize, peekSize int) {
+	s.chunkSize, s.peekSize = max(chunkSize, 0), max(peekSize, 0)
+}
+
+// SetSourceContext sets a function applied to the context of every source it
+// runs, which engines use to scope their settings to the sources they scan.
+// It must be called before any source is run.
+func (s *SourceManager) SetSourceContext(f func(context.Context) context.Context) {
+	s.sourceContext = f
+}
+
+// ChunkSizes returns the sizes of the chunks the sources it runs split data
+// into, and how many bytes consecutive chunks share.
+func (s *SourceManager) ChunkSizes() (chunkSize, peekSize int) {
+	ctx := WithChunkSizes(context.Background(), s.chunkSize, s.peekSize)
+	return DefaultChunkSize(ctx), DefaultPeekSize(ctx)
+}
+
 // preflightChecks is a helper method to check the Manager or the context isn't
 // done.
 func (s *SourceManager) preflightChecks(ctx context.Context) error {
@@ -248,7 +334,13 @@ func (s *SourceManager) preflightChecks(ctx context.Context) error {
 // run is a helper method to synchronously run the source. It does not check for
 // acquired resources. An error is returned if there was a fatal error during
 // the run. This information is also recorded in the JobProgress.
-func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgress, targets ...ChunkingTarget) error {
+func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgress, targets ...ChunkingTarget) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "source.run",
+		attribute.String("source_name", report.SourceName),
+		attribute.String("source_type", source.Type().String()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	report.Start(time.Now())
 	defer func() { report.End(time.Now()) }()
 
@@ -271,13 +363,43 @@ func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgr
 	if ctx.Value("source_type") == "" {
 		ctx = context.WithValue(ctx, "source_type", source.Type().String())
 	}
+	if s.chunkSize > 0 || s.peekSize > 0 {
+		ctx = WithChunkSizes(ctx, s.chunkSize, s.peekSize)
+	}
+	if s.sourceContext != nil {
+		ctx = s.sourceContext(ctx)
+	}
+
+	var cp *sourceCheckpoint
+	if s.checkpoints != nil {
+		var loadErr error
+		cp, loadErr = s.checkpoints.resume(ctx, report.SourceName, source.GetProgress())
+		switch {
+		case loadErr != nil:
+			ctx.Logger().Error(loadErr, "could not load checkpoint, scanning source from the start")
+		case cp.done:
+			ctx.Logger().Info("skipping source completed by the resumed run")
+			return nil
+		default:
+			cp.start(ctx)
+			defer func() { cp.finish(ctx, err) }()
+		}
+	}
 
 	// Check for the preferred method of tracking source units.
 	canUseSourceUnits := len(targets) == 0 && s.useSourceUnitsFunc != nil
 	if enumChunker, ok := source.(SourceUnitEnumChunker); ok && canUseSourceUnits && s.useSourceUnitsFunc() {
 		ctx.Logger().Info("running source",
 			"with_units", true)
-		return s.runWithUnits(ctx, enumChunker, report)
+		if s.unitDispatcher != nil {
+			return s.dispatchUnits(ctx, source, enumChunker, report, cp)
+		}
+		return s.runWithUnits(ctx, enumChunker, report, cp)
+	}
+	if s.unitDispatcher != nil || s.unitLeaser != nil {
+		err := fmt.Errorf("%s sources can't be scanned by distributed workers", source.Type())
+		report.ReportError(Fatal{err})
+		return Fatal{err}
 	}
 	ctx.Logger().Info("running source",
 		"with_units", false,
@@ -290,7 +412,7 @@ func (s *SourceManager) run(ctx context.Context, source Source, report *JobProgr
 // job reporting.
 func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, report *JobProgress, targets ...ChunkingTarget) error {
 	// Introspect on the chunks we get from the Chunks method.
-	ch := make(chan *Chunk, defaultChannelSize)
+	ch := make(chan *Chunk, s.chunkBufferSize())
 	var wg sync.WaitGroup
 	// Consume chunks and export chunks.
 	wg.Add(1)
@@ -299,7 +421,11 @@ func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, repo
 		for chunk := range ch {
 			chunk.JobID = source.JobID()
 			report.ReportChunk(nil, chunk)
-			s.outputChunks <- chunk
+			// Chunks are only dropped once the source is cancelled.
+			if ctx.Err() != nil || s.budget.acquire(ctx, chunk) != nil {
+				continue
+			}
+			s.outputChunks <- []*Chunk{chunk}
 		}
 	}()
 	// Don't return from this function until the goroutine has finished
@@ -318,7 +444,7 @@ func (s *SourceManager) runWithoutUnits(ctx context.Context, source Source, repo
 // runWithUnits is a helper method to run a Source that is also a
 // SourceUnitEnumChunker. This allows better introspection of what is getting
 // scanned and any errors encountered.
-func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumChunker, report *JobProgress) error {
+func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumChunker, report *JobProgress, cp *sourceCheckpoint) error {
 	unitReporter := &mgrUnitReporter{
 		unitCh: make(chan SourceUnit, 1),
 		report: report,
@@ -338,8 +464,14 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 		report.StartEnumerating(time.Now())
 		defer func() { report.EndEnumerating(time.Now()) }()
 		defer close(unitReporter.unitCh)
-		ctx.Logger().V(2).Info("enumerating source")
-		if err := source.Enumerate(ctx, unitReporter); err != nil {
+		var err error
+		if s.unitLeaser != nil {
+			err = s.leaseUnits(ctx, source, report, unitReporter)
+		} else {
+			ctx.Logger().V(2).Info("enumerating source")
+			err = source.Enumerate(ctx, unitReporter)
+		}
+		if err != nil {
 			report.ReportError(Fatal{err})
 			catchFirstFatal(Fatal{err})
 		}
@@ -353,10 +485,22 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 	}
 	for unit := range unitReporter.unitCh {
 		unit := unit
+		if cp.unitCompleted(unit) {
+			id, _ := unit.SourceUnitID()
+			ctx.Logger().V(3).Info("skipping unit completed by the resumed run", "unit", id)
+			if s.unitLeaser != nil {
+				s.unitLeaser.ReleaseUnit(ctx, unit, nil)
+			}
+			continue
+		}
+		// chunkErr is set before chunkCh is closed, so it can be read once
+		// every chunk has been exported.
+		var chunkErr error
 		chunkReporter := &mgrChunkReporter{
 			unit:    unit,
-			chunkCh: make(chan *Chunk, defaultChannelSize),
+			chunkCh: make(chan []*Chunk, s.chunkBufferSize()),
 			report:  report,
+			budget:  s.budget,
 		}
 		// Consume units and produce chunks.
 		unitPool.Go(func() error {
@@ -365,10 +509,20 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 			defer close(chunkReporter.chunkCh)
 			id, kind := unit.SourceUnitID()
 			ctx := context.WithValues(ctx, "unit", id, "unit_kind", kind)
+			ctx, span := tracing.StartSpan(ctx, "source.chunk_unit",
+				attribute.String("unit", id),
+				attribute.String("unit_kind", string(kind)),
+			)
 			ctx.Logger().V(3).Info("chunking unit")
-			if err := source.ChunkUnit(ctx, unit, chunkReporter); err != nil {
-				report.ReportError(Fatal{ChunkError{Unit: unit, Err: err}})
-				catchFirstFatal(Fatal{err})
+			stopFlushing := chunkReporter.flushEvery(ctx, batchInterval)
+			chunkErr = source.ChunkUnit(ctx, unit, chunkReporter)
+			stopFlushing()
+			// The last chunks of the unit are dropped if it was cancelled.
+			_ = chunkReporter.flush(ctx)
+			tracing.End(span, chunkErr)
+			if chunkErr != nil {
+				report.ReportError(Fatal{ChunkError{Unit: unit, Err: chunkErr}})
+				catchFirstFatal(Fatal{chunkErr})
 			}
 			return nil
 		})
@@ -377,11 +531,31 @@ func (s *SourceManager) runWithUnits(ctx context.Context, source SourceUnitEnumC
 		go func() {
 			defer wg.Done()
 			defer func() { report.EndUnitChunking(unit, time.Now()) }()
-			for chunk := range chunkReporter.chunkCh {
-				if src, ok := source.(Source); ok {
-					chunk.JobID = src.JobID()
+			for batch := range chunkReporter.chunkCh {
+				// Chunks still buffered once the source is cancelled are
+				// dropped. Their unit isn't completed, so a resumed run
+				// scans it again.
+				if ctx.Err() != nil {
+					for _, chunk := range batch {
+						s.ReleaseChunk(chunk)
+					}
+					continue
+				}
+				for _, chunk := range batch {
+					if src, ok := source.(Source); ok {
+						chunk.JobID = src.JobID()
+					}
+					if s.unitLeaser != nil {
+						s.unitLeaser.ReportChunk(unit, chunk)
+					}
 				}
-				s.outputChunks <- chunk
+				s.outputChunks <- batch
+			}
+			if s.unitLeaser != nil {
+				s.unitLeaser.ReleaseUnit(ctx, unit, chunkErr)
+			}
+			if chunkErr == nil && ctx.Err() == nil {
+				cp.completeUnit(unit)
 			}
 		}()
 	}
@@ -432,15 +606,101 @@ var _ ChunkReporter = (*mgrChunkReporter)(nil)
 
 type mgrChunkReporter struct {
 	unit    SourceUnit
-	chunkCh chan *Chunk
+	chunkCh chan []*Chunk
 	report  *JobProgress
-}
+	budget  *memoryBudget
+
+	// batch holds the chunks not sent on chunkCh yet, and batchBytes their
+	// data, so sources emitting many small chunks don't pay for a channel
+	// send each.
+	mu         sync.Mutex
+	batch      []*Chunk
+	batchBytes int
+}
+
+const (
+	// maxBatchChunks and maxBatchBytes limit the chunks sent on chunkCh
+	// together.
+	maxBatchChunks = 64
+	maxBatchBytes  = 64 << 10 // 64KB
+	// batchInterval is the longest a chunk waits for its batch to fill, so
+	// units that emit chunks slowly don't hold them back.
+	batchInterval = 100 * time.Millisecond
+)
 
 // ChunkOk implements the ChunkReporter interface by recording the chunk and
-// its associated unit in the report and sending it on the Chunk channel.
+// its associated unit in the report and batching it to send on the Chunk
+// channel. It blocks while the memory budget is spent.
 func (s *mgrChunkReporter) ChunkOk(ctx context.Context, chunk Chunk) error {
 	s.report.ReportChunk(s.unit, &chunk)
-	return common.CancellableWrite(ctx, s.chunkCh, &chunk)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.budget.tryAcquire(&chunk) {
+		// The batched chunks must be sent for the budget to be released.
+		if err := s.flushLocked(ctx); err != nil {
+			chunk.FreeData()
+			return err
+		}
+		if err := s.budget.acquire(ctx, &chunk); err != nil {
+			chunk.FreeData()
+			return err
+		}
+	}
+	s.batch = append(s.batch, &chunk)
+	s.batchBytes += len(chunk.Data)
+	if len(s.batch) < maxBatchChunks && s.batchBytes < maxBatchBytes {
+		return nil
+	}
+	return s.flushLocked(ctx)
+}
+
+// flush sends the batched chunks on the Chunk channel. They're dropped if
+// ctx is cancelled first.
+func (s *mgrChunkReporter) flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked(ctx)
+}
+
+// flushEvery flushes the batched chunks every interval, until the returned
+// function is called. It returns once the chunks are no longer flushed.
+func (s *mgrChunkReporter) flushEvery(ctx context.Context, interval time.Duration) func() {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = s.flush(ctx)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *mgrChunkReporter) flushLocked(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch, s.batchBytes = nil, 0
+	if err := common.CancellableWrite(ctx, s.chunkCh, batch); err != nil {
+		for _, chunk := range batch {
+			s.budget.release(chunk)
+			chunk.FreeData()
+		}
+		return err
+	}
+	return nil
 }
 
 // ChunkErr implements the ChunkReporter interface by recording the error and
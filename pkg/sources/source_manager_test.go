@@ -30,7 +30,8 @@ func (d *DummySource) Init(_ context.Context, _ string, jobID JobID, sourceID So
 	d.jobID = jobID
 	return nil
 }
-func (d *DummySource) GetProgress() *Progress { return nil }
+func (d *DummySource) GetProgress() *Progress            { return nil }
+func (d *DummySource) HealthCheck(context.Context) error { return nil }
 
 // Interface to easily test different chunking methods.
 type chunker interface {
@@ -99,12 +100,13 @@ func buildDummy(chunkMethod chunker) (Source, error) {
 }
 
 // tryRead is a helper function that will try to read from a channel and return
-// an error if it cannot.
+// an error if it cannot. Chunks() receives the chunks of the output batches
+// from a goroutine, so they're only read once they're passed on.
 func tryRead(ch <-chan *Chunk) (*Chunk, error) {
 	select {
 	case chunk := <-ch:
 		return chunk, nil
-	default:
+	case <-time.After(50 * time.Millisecond):
 		return nil, fmt.Errorf("no chunk available")
 	}
 }
@@ -127,6 +129,32 @@ func TestSourceManagerRun(t *testing.T) {
 	}
 }
 
+// Chunk method that writes the value of its context's contextChunkerKey.
+type contextChunker struct{ counterChunker }
+
+type contextChunkerKey struct{}
+
+func (c *contextChunker) Chunks(ctx context.Context, ch chan *Chunk, _ ...ChunkingTarget) error {
+	value, _ := ctx.Value(contextChunkerKey{}).(string)
+	ch <- &Chunk{Data: []byte(value)}
+	return nil
+}
+
+func TestSourceManagerSourceContext(t *testing.T) {
+	mgr := NewManager(WithBufferedOutput(8))
+	mgr.SetSourceContext(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextChunkerKey{}, "scoped")
+	})
+	source, err := buildDummy(new(contextChunker))
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+	<-ref.Done()
+	chunk, err := tryRead(mgr.Chunks())
+	assert.NoError(t, err)
+	assert.Equal(t, "scoped", string(chunk.Data))
+}
+
 func TestSourceManagerWait(t *testing.T) {
 	mgr := NewManager()
 	source, err := buildDummy(&counterChunker{count: 1})
@@ -256,6 +284,30 @@ func TestSourceManagerContextCancelled(t *testing.T) {
 	assert.Error(t, report.FatalError())
 }
 
+func TestSourceManagerContextCancelledDropsChunks(t *testing.T) {
+	mgr := NewManager(WithBufferedOutput(1))
+	source, err := buildDummy(&counterChunker{count: 100})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ref, err := mgr.Run(ctx, "dummy", source)
+	assert.NoError(t, err)
+	<-mgr.Chunks()
+	// Let the source fill its buffer.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	go func() { _ = mgr.Wait() }()
+	var read int
+	for range mgr.Chunks() {
+		read++
+	}
+	<-ref.Done()
+	// Only the chunks already exported are read, not those the source had
+	// buffered.
+	assert.Less(t, read, defaultChannelSize)
+}
+
 type DummyAPI struct {
 	registerSource func(context.Context, string, sourcespb.SourceType) (SourceID, error)
 	getJobID       func(context.Context, SourceID) (JobID, error)
@@ -448,3 +500,175 @@ func TestSourceManagerUnitHookNoUnits(t *testing.T) {
 	assert.NotZero(t, m.ElapsedTime())
 	assert.Equal(t, 0, len(m.Errors))
 }
+
+func TestSourceManagerMemoryBudget(t *testing.T) {
+	for name, opts := range map[string][]func(*SourceManager){
+		"without units": nil,
+		"with units":    {WithSourceUnits()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mgr := NewManager(append(opts, WithBufferedOutput(8), WithMemoryBudget(2))...)
+			source, err := buildDummy(&counterChunker{count: 5})
+			assert.NoError(t, err)
+			ref, err := mgr.Run(context.Background(), "dummy", source)
+			assert.NoError(t, err)
+
+			read := func() *Chunk {
+				select {
+				case chunk := <-mgr.Chunks():
+					return chunk
+				case <-time.After(time.Second):
+					t.Fatal("no chunk available")
+					return nil
+				}
+			}
+			// Two 1 byte chunks fill the budget, pausing the source.
+			first, second := read(), read()
+			time.Sleep(50 * time.Millisecond)
+			_, err = tryRead(mgr.Chunks())
+			assert.Error(t, err)
+
+			mgr.ReleaseChunk(first)
+			mgr.ReleaseChunk(second)
+			for range 3 {
+				mgr.ReleaseChunk(read())
+			}
+			<-ref.Done()
+			assert.NoError(t, ref.Snapshot().FatalError())
+		})
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	budget := newMemoryBudget(4)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Chunks larger than the budget take all of it.
+	large := &Chunk{Data: make([]byte, 16)}
+	assert.NoError(t, budget.acquire(ctx, large))
+	// Releasing the chunk returns what was acquired, even if its data
+	// changed since.
+	large.Data = large.Data[:1]
+	small := &Chunk{Data: make([]byte, 1)}
+	assert.Error(t, budget.acquire(ctx, small))
+	budget.release(large)
+	assert.NoError(t, budget.acquire(context.Background(), small))
+
+	// Releasing unknown chunks or twice is harmless.
+	budget.release(&Chunk{Data: make([]byte, 4)})
+	budget.release(small)
+	budget.release(small)
+	assert.NoError(t, budget.acquire(context.Background(), &Chunk{Data: make([]byte, 4)}))
+}
+
+// batchChunker emits count chunks of one unit, then waits for wait to be
+// closed, if it's set.
+type batchChunker struct {
+	count int
+	wait  chan struct{}
+}
+
+func (c *batchChunker) Chunks(context.Context, chan *Chunk, ...ChunkingTarget) error { return nil }
+
+func (c *batchChunker) Enumerate(ctx context.Context, reporter UnitReporter) error {
+	return reporter.UnitOk(ctx, countChunk(0))
+}
+
+func (c *batchChunker) ChunkUnit(ctx context.Context, _ SourceUnit, reporter ChunkReporter) error {
+	for i := range c.count {
+		if err := reporter.ChunkOk(ctx, Chunk{Data: []byte{byte(i)}}); err != nil {
+			return err
+		}
+	}
+	if c.wait != nil {
+		<-c.wait
+	}
+	return nil
+}
+
+func TestSourceManagerBatchedChunks(t *testing.T) {
+	for name, opts := range map[string][]func(*SourceManager){
+		"unbudgeted": {WithSourceUnits()},
+		// The budget is spent long before a batch is full.
+		"budgeted": {WithSourceUnits(), WithMemoryBudget(10)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mgr := NewManager(opts...)
+			source, err := buildDummy(&batchChunker{count: 3*maxBatchChunks + 1})
+			assert.NoError(t, err)
+			ref, err := mgr.Run(context.Background(), "dummy", source)
+			assert.NoError(t, err)
+
+			for i := range 3*maxBatchChunks + 1 {
+				select {
+				case chunk := <-mgr.Chunks():
+					assert.Equal(t, []byte{byte(i)}, chunk.Data)
+					mgr.ReleaseChunk(chunk)
+				case <-time.After(time.Second):
+					t.Fatalf("chunk %d not available", i)
+				}
+			}
+			<-ref.Done()
+			assert.NoError(t, ref.Snapshot().FatalError())
+		})
+	}
+}
+
+func TestSourceManagerBatchedChunksFlushed(t *testing.T) {
+	mgr := NewManager(WithSourceUnits())
+	wait := make(chan struct{})
+	source, err := buildDummy(&batchChunker{count: 1, wait: wait})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+
+	// The chunk isn't held back until the unit is done.
+	select {
+	case chunk := <-mgr.Chunks():
+		assert.Equal(t, []byte{0}, chunk.Data)
+	case <-time.After(time.Second):
+		t.Fatal("chunk of a running unit not available")
+	}
+	close(wait)
+	<-ref.Done()
+	assert.NoError(t, ref.Snapshot().FatalError())
+}
+
+func TestSourceManagerChunkBatches(t *testing.T) {
+	mgr := NewManager(WithSourceUnits())
+	source, err := buildDummy(&batchChunker{count: maxBatchChunks})
+	assert.NoError(t, err)
+	ref, err := mgr.Run(context.Background(), "dummy", source)
+	assert.NoError(t, err)
+
+	// The batch of the unit reaches the consumer as it is.
+	select {
+	case batch := <-mgr.ChunkBatches():
+		assert.Len(t, batch, maxBatchChunks)
+	case <-time.After(time.Second):
+		t.Fatal("batch not available")
+	}
+	<-ref.Done()
+	assert.NoError(t, ref.Snapshot().FatalError())
+}
+
+func TestChunkAllocData(t *testing.T) {
+	mgr := NewManager()
+
+	small := &Chunk{}
+	data := small.AllocData(10)
+	assert.Len(t, data, 10)
+	assert.Len(t, small.Data, 10)
+	assert.True(t, small.pooled)
+	mgr.ReleaseChunk(small)
+	assert.Nil(t, small.Data)
+	assert.False(t, small.pooled)
+
+	// Large data isn't pooled, and is left to the garbage collector.
+	large := &Chunk{}
+	assert.Len(t, large.AllocData(pooledDataSize+1), pooledDataSize+1)
+	assert.False(t, large.pooled)
+	mgr.ReleaseChunk(large)
+	assert.Len(t, large.Data, pooledDataSize+1)
+}
@@ -2,11 +2,13 @@ package sources
 
 import (
 	"sync"
+	"time"
 
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/trufflesecurity/trufflehog/v3/pkg/common"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
 	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/sourcespb"
 )
@@ -43,6 +45,13 @@ type Chunk struct {
 
 	// Verify specifies whether any secrets in the Chunk should be verified.
 	Verify bool
+	// pooled is whether Data was taken from the pool by AllocData. It's
+	// next to Verify to fit in its padding.
+	pooled bool
+
+	// IgnoreFile holds the suppressions of the .trufflehogignore file of the
+	// repository or directory the Chunk was found in, if it has one.
+	IgnoreFile *ignorefile.File
 }
 
 // ChunkingTarget specifies criteria for a targeted chunking process.
@@ -75,6 +84,10 @@ type Source interface {
 	Chunks(ctx context.Context, chunksChan chan *Chunk, targets ...ChunkingTarget) error
 	// GetProgress is the completion progress (percentage) for Scanned Source.
 	GetProgress() *Progress
+	// HealthCheck checks that an initialized source can be scanned, such as
+	// that its credentials are valid and that what it points at can be
+	// reached, without scanning it.
+	HealthCheck(ctx context.Context) error
 }
 
 // SourceUnitEnumChunker are the two required interfaces to support enumerating
@@ -197,11 +210,16 @@ type GitConfig struct {
 	IncludePathsFile string
 	// ExcludePathsFile is the path to a file containing a list of regexps to exclude from the scan.
 	ExcludePathsFile string
+	// FilterRules are path, size and MIME type rules applied together with the paths files.
+	FilterRules *sourcespb.Filter
 	// ExcludeGlobs is a list of comma separated globs to exclude from the scan.
 	// This differs from the Filter exclusions as ExcludeGlobs is applied at the `git log -p` level
 	ExcludeGlobs string
 	// SkipBinaries allows skipping binary files from the scan.
 	SkipBinaries bool
+	// Remote is the name of the remote whose URL identifies the repository in
+	// findings. Defaults to origin.
+	Remote string
 }
 
 // GithubConfig defines the optional configuration for a github source.
@@ -210,6 +228,9 @@ type GithubConfig struct {
 	Endpoint string
 	// Token is the token to use to authenticate with the source.
 	Token string
+	// Tokens are additional tokens to rotate between with Token as their
+	// rate limits are hit.
+	Tokens []string
 	// IncludeForks indicates whether to include forks in the scan.
 	IncludeForks bool
 	// IncludeMembers indicates whether to include members in the scan.
@@ -236,6 +257,23 @@ type GithubConfig struct {
 	SkipBinaries bool
 	// IncludeWikis indicates whether to include repository wikis in the scan.
 	IncludeWikis bool
+	// SkipArchived skips archived repositories of organizations and users.
+	SkipArchived bool
+	// ExcludeTopics skips repositories of organizations and users with any of
+	// these topics.
+	ExcludeTopics []string
+	// IncludeTopics limits repositories of organizations and users to those
+	// with any of these topics.
+	IncludeTopics []string
+	// Languages limits repositories of organizations and users to those
+	// whose primary language is any of these.
+	Languages []string
+	// PushedWithin limits repositories of organizations and users to those
+	// pushed to this recently.
+	PushedWithin time.Duration
+	// MaxRepoSize limits repositories of organizations and users to those of
+	// at most this many bytes.
+	MaxRepoSize int64
 }
 
 // GitHubExperimentalConfig defines the optional configuration for an experimental GitHub source.
@@ -258,12 +296,38 @@ type GitlabConfig struct {
 	Endpoint string
 	// Token is the token to use to authenticate with the source.
 	Token string
+	// Tokens are additional tokens to rotate between with Token as their
+	// rate limits are hit.
+	Tokens []string
 	// Repos is the list of repositories to scan.
 	Repos []string
+	// ExcludeRepos is a list of repositories to exclude from the scan.
+	ExcludeRepos []string
 	// Filter is the filter to use to scan the source.
 	Filter *common.Filter
 	// SkipBinaries allows skipping binary files from the scan.
 	SkipBinaries bool
+	// SkipArchived skips archived projects when all accessible projects are
+	// scanned.
+	SkipArchived bool
+	// SkipForks skips forked projects when all accessible projects are
+	// scanned.
+	SkipForks bool
+	// ExcludeTopics skips projects with any of these topics when all
+	// accessible projects are scanned.
+	ExcludeTopics []string
+	// IncludeTopics limits the accessible projects scanned to those with any
+	// of these topics.
+	IncludeTopics []string
+	// Languages limits the accessible projects scanned to those whose
+	// primary language is any of these.
+	Languages []string
+	// PushedWithin limits the accessible projects scanned to those with
+	// activity this recently.
+	PushedWithin time.Duration
+	// MaxRepoSize limits the accessible projects scanned to those whose
+	// repository is at most this many bytes.
+	MaxRepoSize int64
 }
 
 // FilesystemConfig defines the optional configuration for a filesystem source.
@@ -274,6 +338,11 @@ type FilesystemConfig struct {
 	IncludePathsFile string
 	// ExcludePathsFile is the path to a file containing a list of regexps to exclude from the scan.
 	ExcludePathsFile string
+	// FilterRules are path, size and MIME type rules applied together with the paths files.
+	FilterRules *sourcespb.Filter
+	// Watch keeps scanning files as they are created or modified once the
+	// paths are scanned, until the scan is canceled.
+	Watch bool
 }
 
 // S3Config defines the optional configuration for an S3 source.
@@ -356,7 +425,8 @@ type ElasticsearchConfig struct {
 	BestEffortScan bool
 }
 
-// Progress is used to update job completion progress across sources.
+// Progress is used to update job completion progress across sources. Its
+// methods may be called from multiple goroutines.
 type Progress struct {
 	mut               sync.Mutex
 	PercentComplete   int64
@@ -364,6 +434,48 @@ type Progress struct {
 	EncodedResumeInfo string
 	SectionsCompleted int32
 	SectionsRemaining int32
+
+	// Stage is what the source is currently doing.
+	Stage ProgressStage
+	// BytesProcessed and ChunksProcessed count the data the source chunked.
+	BytesProcessed  uint64
+	ChunksProcessed uint64
+	// ErrorCount is the number of errors the source encountered.
+	ErrorCount uint64
+
+	// repos is the progress of every repository of sources that scan several,
+	// in the order they were started.
+	repos     []*RepoProgress
+	repoIndex map[string]*RepoProgress
+
+	// waiting is the message set by SetProgressWaiting, which replaced
+	// waitingOver.
+	waiting     string
+	waitingOver string
+}
+
+// ProgressStage is what a source, or one of its repositories, is busy with.
+type ProgressStage string
+
+// Stages of sources. Sources that verify what they found themselves, before
+// reporting it, are verifying while they do.
+const (
+	StageCloning   ProgressStage = "cloning"
+	StageScanning  ProgressStage = "scanning"
+	StageVerifying ProgressStage = "verifying"
+)
+
+// RepoProgress is the progress of a single repository of a source. Updating
+// it updates the totals of the Progress it belongs to.
+type RepoProgress struct {
+	progress *Progress
+
+	name   string
+	stage  ProgressStage
+	bytes  uint64
+	chunks uint64
+	errors uint64
+	done   bool
 }
 
 // Validator is an interface for validating a source. Sources can optionally implement this interface to validate
@@ -415,6 +527,174 @@ func (p *Progress) SetProgressOngoing(message string, encodedResumeInfo string)
 	p.SectionsRemaining = 0
 }
 
+// SetProgressWaiting shows message, such as why the source is waiting, in
+// place of the progress message until it is called with an empty message. The
+// completion and resume information are left as they are.
+func (p *Progress) SetProgressWaiting(message string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if message == "" {
+		// Progress made during the wait replaced the waiting message.
+		if p.waiting != "" && p.Message == p.waiting {
+			p.Message = p.waitingOver
+		}
+		p.waiting, p.waitingOver = "", ""
+		return
+	}
+	if p.waiting == "" || p.Message != p.waiting {
+		p.waitingOver = p.Message
+	}
+	p.waiting = message
+	p.Message = message
+}
+
+// SetProgressStage sets what the source is currently doing.
+func (p *Progress) SetProgressStage(stage ProgressStage) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.Stage = stage
+}
+
+// AddProgressProcessed counts chunks and bytes the source processed.
+func (p *Progress) AddProgressProcessed(chunks, bytes uint64) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.ChunksProcessed += chunks
+	p.BytesProcessed += bytes
+}
+
+// AddProgressError counts an error the source encountered.
+func (p *Progress) AddProgressError() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.ErrorCount++
+}
+
+// ProgressRepo returns the progress of the repository called name, which is
+// added to the progress of the source the first time it's requested.
+func (p *Progress) ProgressRepo(name string) *RepoProgress {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if r, ok := p.repoIndex[name]; ok {
+		return r
+	}
+	if p.repoIndex == nil {
+		p.repoIndex = make(map[string]*RepoProgress)
+	}
+	r := &RepoProgress{progress: p, name: name}
+	p.repos = append(p.repos, r)
+	p.repoIndex[name] = r
+	return r
+}
+
+// SetStage sets what the repository, and so its source, is currently doing.
+func (r *RepoProgress) SetStage(stage ProgressStage) {
+	r.progress.mut.Lock()
+	defer r.progress.mut.Unlock()
+	r.stage = stage
+	r.progress.Stage = stage
+}
+
+// AddProcessed counts chunks and bytes processed from the repository.
+func (r *RepoProgress) AddProcessed(chunks, bytes uint64) {
+	r.progress.mut.Lock()
+	defer r.progress.mut.Unlock()
+	r.chunks += chunks
+	r.bytes += bytes
+	r.progress.ChunksProcessed += chunks
+	r.progress.BytesProcessed += bytes
+}
+
+// AddError counts an error encountered scanning the repository.
+func (r *RepoProgress) AddError() {
+	r.progress.mut.Lock()
+	defer r.progress.mut.Unlock()
+	r.errors++
+	r.progress.ErrorCount++
+}
+
+// Reporter returns a reporter that counts the chunks and errors it reports to
+// reporter as the repository's.
+func (r *RepoProgress) Reporter(reporter ChunkReporter) ChunkReporter {
+	return repoProgressReporter{ChunkReporter: reporter, progress: r}
+}
+
+type repoProgressReporter struct {
+	ChunkReporter
+	progress *RepoProgress
+}
+
+func (r repoProgressReporter) ChunkOk(ctx context.Context, chunk Chunk) error {
+	r.progress.AddProcessed(1, uint64(len(chunk.Data)))
+	return r.ChunkReporter.ChunkOk(ctx, chunk)
+}
+
+func (r repoProgressReporter) ChunkErr(ctx context.Context, err error) error {
+	r.progress.AddError()
+	return r.ChunkReporter.ChunkErr(ctx, err)
+}
+
+// Finish marks the repository as scanned.
+func (r *RepoProgress) Finish() {
+	r.progress.mut.Lock()
+	defer r.progress.mut.Unlock()
+	r.done = true
+}
+
+// ProgressSnapshot is a copy of a Progress at some point in time, for
+// reporting it, such as from the server API.
+type ProgressSnapshot struct {
+	PercentComplete   int64                  `json:"percent_complete"`
+	Message           string                 `json:"message,omitempty"`
+	EncodedResumeInfo string                 `json:"encoded_resume_info,omitempty"`
+	SectionsCompleted int32                  `json:"sections_completed,omitempty"`
+	SectionsRemaining int32                  `json:"sections_remaining,omitempty"`
+	Stage             ProgressStage          `json:"stage,omitempty"`
+	BytesProcessed    uint64                 `json:"bytes_processed"`
+	ChunksProcessed   uint64                 `json:"chunks_processed"`
+	Errors            uint64                 `json:"errors"`
+	Repos             []RepoProgressSnapshot `json:"repos,omitempty"`
+}
+
+// RepoProgressSnapshot is a copy of a RepoProgress.
+type RepoProgressSnapshot struct {
+	Name            string        `json:"name"`
+	Stage           ProgressStage `json:"stage,omitempty"`
+	BytesProcessed  uint64        `json:"bytes_processed"`
+	ChunksProcessed uint64        `json:"chunks_processed"`
+	Errors          uint64        `json:"errors"`
+	Done            bool          `json:"done"`
+}
+
+// Snapshot returns a copy of the progress that is safe to keep and read.
+func (p *Progress) Snapshot() ProgressSnapshot {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	snap := ProgressSnapshot{
+		PercentComplete:   p.PercentComplete,
+		Message:           p.Message,
+		EncodedResumeInfo: p.EncodedResumeInfo,
+		SectionsCompleted: p.SectionsCompleted,
+		SectionsRemaining: p.SectionsRemaining,
+		Stage:             p.Stage,
+		BytesProcessed:    p.BytesProcessed,
+		ChunksProcessed:   p.ChunksProcessed,
+		Errors:            p.ErrorCount,
+	}
+	for _, r := range p.repos {
+		snap.Repos = append(snap.Repos, RepoProgressSnapshot{
+			Name:            r.name,
+			Stage:           r.stage,
+			BytesProcessed:  r.bytes,
+			ChunksProcessed: r.chunks,
+			Errors:          r.errors,
+			Done:            r.done,
+		})
+	}
+	return snap
+}
+
 // GetProgress gets job completion percentage for metrics reporting.
 func (p *Progress) GetProgress() *Progress {
 	p.mut.Lock()
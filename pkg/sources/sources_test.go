@@ -1,14 +1,93 @@
 package sources
 
 import (
+	"encoding/json"
+	"sync"
 	"testing"
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
 )
 
-// TestChunkSize ensures that the Chunk struct does not exceed 80 bytes.
+// TestChunkSize ensures that the Chunk struct does not exceed 88 bytes.
 func TestChunkSize(t *testing.T) {
 	t.Parallel()
-	assert.Equal(t, unsafe.Sizeof(Chunk{}), uintptr(80), "Chunk struct size exceeds 80 bytes")
+	assert.Equal(t, uintptr(88), unsafe.Sizeof(Chunk{}), "Chunk struct size exceeds 88 bytes")
+}
+
+func TestProgressWaiting(t *testing.T) {
+	var p Progress
+	p.SetProgressComplete(1, 4, "Repo 1", "resume")
+
+	p.SetProgressWaiting("Waiting on rate limit")
+	assert.Equal(t, "Waiting on rate limit", p.Message)
+	assert.Equal(t, int32(1), p.SectionsCompleted)
+	assert.Equal(t, "resume", p.EncodedResumeInfo)
+	p.SetProgressWaiting("")
+	assert.Equal(t, "Repo 1", p.Message)
+
+	// Progress made during the wait is kept.
+	p.SetProgressWaiting("Waiting on rate limit")
+	p.SetProgressComplete(2, 4, "Repo 2", "")
+	p.SetProgressWaiting("")
+	assert.Equal(t, "Repo 2", p.Message)
+}
+
+func TestProgressRepos(t *testing.T) {
+	var p Progress
+	p.SetProgressStage(StageScanning)
+	p.AddProgressProcessed(1, 10)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b"} {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				repo := p.ProgressRepo(name)
+				repo.AddProcessed(1, 100)
+				if i == 0 {
+					repo.AddError()
+				}
+				_ = p.Snapshot()
+			}()
+		}
+	}
+	wg.Wait()
+	p.ProgressRepo("a").Finish()
+	p.ProgressRepo("b").SetStage(StageCloning)
+
+	snap := p.Snapshot()
+	assert.Equal(t, StageCloning, snap.Stage)
+	assert.Equal(t, uint64(21), snap.ChunksProcessed)
+	assert.Equal(t, uint64(2010), snap.BytesProcessed)
+	assert.Equal(t, uint64(2), snap.Errors)
+	require.Len(t, snap.Repos, 2)
+	assert.ElementsMatch(t, []RepoProgressSnapshot{
+		{Name: "a", BytesProcessed: 1000, ChunksProcessed: 10, Errors: 1, Done: true},
+		{Name: "b", Stage: StageCloning, BytesProcessed: 1000, ChunksProcessed: 10, Errors: 1},
+	}, snap.Repos)
+
+	out, err := json.Marshal(snap)
+	require.NoError(t, err)
+	var decoded ProgressSnapshot
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, snap, decoded)
+}
+
+func TestRepoProgressReporter(t *testing.T) {
+	var p Progress
+	repo := p.ProgressRepo("repo")
+	reporter := repo.Reporter(ChanReporter{Ch: make(chan *Chunk, 2)})
+	ctx := context.Background()
+	require.NoError(t, reporter.ChunkOk(ctx, Chunk{Data: []byte("data")}))
+	require.NoError(t, reporter.ChunkOk(ctx, Chunk{Data: []byte("more data")}))
+	_ = reporter.ChunkErr(ctx, assert.AnError)
+
+	snap := p.Snapshot()
+	assert.Equal(t, []RepoProgressSnapshot{{Name: "repo", BytesProcessed: 13, ChunksProcessed: 2, Errors: 1}}, snap.Repos)
+	assert.Equal(t, uint64(1), snap.Errors)
 }
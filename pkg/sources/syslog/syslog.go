@@ -101,6 +101,11 @@ func (s *Source) Validate(ctx context.Context) []error {
 	return errs
 }
 
+// HealthCheck checks the configuration, and that the listen address is free.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	return errors.Join(s.Validate(ctx)...)
+}
+
 // Ensure the Source satisfies the interface at compile time.
 var _ sources.Source = (*Source)(nil)
 
@@ -89,6 +89,15 @@ func (s *Source) Init(ctx context.Context, name string, jobId sources.JobID, sou
 	return nil
 }
 
+// HealthCheck gets the user the token belongs to, which fails if the token
+// has been revoked since the source was initialized.
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if _, _, err := s.client.User.Current(ctx, nil); err != nil {
+		return fmt.Errorf("error getting current user: %w", err)
+	}
+	return nil
+}
+
 func (s *Source) Enumerate(ctx context.Context, reporter sources.UnitReporter) error {
 	for repoPage := 0; ; repoPage++ {
 		repositories, _, err := s.client.Repositories.List(ctx, &travis.RepositoriesOption{
@@ -0,0 +1,72 @@
+// Package tracing instruments the stages of a scan with OpenTelemetry spans.
+// Spans are only recorded once Init has been called with an OTLP endpoint;
+// until then every span is a no-op.
+package tracing
+
+import (
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/version"
+)
+
+const (
+	instrumentationName = "github.com/trufflesecurity/trufflehog/v3"
+	serviceName         = "trufflehog"
+	defaultTracesPath   = "/v1/traces"
+)
+
+var tracer = otel.Tracer(instrumentationName)
+
+// Init exports spans to the OTLP/HTTP collector at endpoint. If endpoint has
+// no path, spans are sent to the collector's default /v1/traces path. The
+// returned function flushes buffered spans and must be called before exit.
+// Sampling can be configured with the standard OTEL_TRACES_SAMPLER variables.
+func Init(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultTracesPath
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(u.String()))
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", version.BuildVersion),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error { return provider.Shutdown(ctx) }, nil
+}
+
+// StartSpan starts a span named name as a child of the span in ctx, if any.
+// The returned context carries the span and ctx's logger.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanCtx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return context.WithLogger(spanCtx, ctx.Logger()), span
+}
+
+// End records err on span, if it is non-nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	logger := logr.Discard().WithName("test")
+	ctx := context.WithLogger(context.Background(), logger)
+
+	parentCtx, parent := StartSpan(ctx, "parent")
+	assert.Equal(t, logger, parentCtx.Logger())
+	_, child := StartSpan(parentCtx, "child", attribute.String("detector", "AWS"))
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("detector", "AWS"))
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInit_InvalidEndpoint(t *testing.T) {
+	_, err := Init(context.Background(), "not a url")
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package trigger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"golang.org/x/oauth2/google"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+)
+
+// leaseSeconds is how long a received message is hidden from other consumers
+// of SQS and Pub/Sub, and how far extending its lease pushes that back.
+const leaseSeconds = 120
+
+type sqsSubscription struct {
+	client   *sqs.SQS
+	queueURL string
+}
+
+func newSQSSubscription(queueURL string) (*sqsSubscription, error) {
+	cfg := aws.NewConfig()
+	// Queue URLs name their region: https://sqs.<region>.amazonaws.com/...
+	if host := strings.Split(strings.TrimPrefix(queueURL, "https://"), "."); len(host) > 2 && host[0] == "sqs" {
+		cfg = cfg.WithRegion(host[1])
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %w", err)
+	}
+	return &sqsSubscription{client: sqs.New(sess), queueURL: queueURL}, nil
+}
+
+func (s *sqsSubscription) Receive(ctx context.Context) (*message, error) {
+	for {
+		out, err := s.client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.queueURL),
+			MaxNumberOfMessages: aws.Int64(1),
+			VisibilityTimeout:   aws.Int64(leaseSeconds),
+			WaitTimeSeconds:     aws.Int64(20),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(out.Messages) == 0 {
+			continue
+		}
+		m := out.Messages[0]
+		visibility := func(seconds int64) func(context.Context) error {
+			return func(ctx context.Context) error {
+				_, err := s.client.ChangeMessageVisibilityWithContext(ctx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(s.queueURL),
+					ReceiptHandle:     m.ReceiptHandle,
+					VisibilityTimeout: aws.Int64(seconds),
+				})
+				return err
+			}
+		}
+		return &message{
+			body: []byte(aws.StringValue(m.Body)),
+			ack: func(ctx context.Context) error {
+				_, err := s.client.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+					QueueUrl:      aws.String(s.queueURL),
+					ReceiptHandle: m.ReceiptHandle,
+				})
+				return err
+			},
+			nack:   visibility(0),
+			extend: visibility(leaseSeconds),
+		}, nil
+	}
+}
+
+func (s *sqsSubscription) Close() error { return nil }
+
+const pubSubScope = "https://www.googleapis.com/auth/pubsub"
+
+// pubSubSubscription pulls messages with the Pub/Sub REST API.
+type pubSubSubscription struct {
+	client          *http.Client
+	subscriptionURL string
+}
+
+func newPubSubSubscription(ctx context.Context, project, subscription string) (*pubSubSubscription, error) {
+	if subscription == "" {
+		return nil, fmt.Errorf("no Pub/Sub subscription given for project %s", project)
+	}
+	client, err := google.DefaultClient(ctx, pubSubScope)
+	if err != nil {
+		return nil, fmt.Errorf("could not find Google Cloud credentials: %w", err)
+	}
+	return &pubSubSubscription{
+		client: client,
+		subscriptionURL: fmt.Sprintf("https://pubsub.googleapis.com/v1/projects/%s/subscriptions/%s",
+			url.PathEscape(project), url.PathEscape(subscription)),
+	}, nil
+}
+
+func (s *pubSubSubscription) Receive(ctx context.Context) (*message, error) {
+	for {
+		var resp struct {
+			ReceivedMessages []struct {
+				AckID   string `json:"ackId"`
+				Message struct {
+					Data string `json:"data"`
+				} `json:"message"`
+			} `json:"receivedMessages"`
+		}
+		if err := s.call(ctx, "pull", map[string]any{"maxMessages": 1}, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.ReceivedMessages) == 0 {
+			continue
+		}
+		m := resp.ReceivedMessages[0]
+		body, err := base64.StdEncoding.DecodeString(m.Message.Data)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode Pub/Sub message: %w", err)
+		}
+		deadline := func(seconds int) func(context.Context) error {
+			return func(ctx context.Context) error {
+				return s.call(ctx, "modifyAckDeadline", map[string]any{"ackIds": []string{m.AckID}, "ackDeadlineSeconds": seconds}, nil)
+			}
+		}
+		if err := deadline(leaseSeconds)(ctx); err != nil {
+			return nil, err
+		}
+		return &message{
+			body: body,
+			ack: func(ctx context.Context) error {
+				return s.call(ctx, "acknowledge", map[string]any{"ackIds": []string{m.AckID}}, nil)
+			},
+			nack:   deadline(0),
+			extend: deadline(leaseSeconds),
+		}, nil
+	}
+}
+
+// call calls a method of the subscription, decoding its response into resp
+// unless it's nil.
+func (s *pubSubSubscription) call(ctx context.Context, method string, body any, resp any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.subscriptionURL+":"+method, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("pub/sub %s returned %s: %s", method, res.Status, bytes.TrimSpace(msg))
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
+
+func (s *pubSubSubscription) Close() error { return nil }
+
+// amqpSubscription consumes a RabbitMQ queue. Unacknowledged messages stay
+// reserved for the consumer until it acknowledges them or disconnects, so
+// their leases don't need to be extended.
+type amqpSubscription struct {
+	conn       *amqp.Connection
+	deliveries <-chan amqp.Delivery
+}
+
+func newAMQPSubscription(u *url.URL) (*amqpSubscription, error) {
+	query := u.Query()
+	queue := query.Get("queue")
+	if queue == "" {
+		return nil, fmt.Errorf("no queue given for %s, add ?queue=NAME", u.Redacted())
+	}
+	query.Del("queue")
+	dial := *u
+	dial.RawQuery = query.Encode()
+
+	conn, err := amqp.Dial(dial.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %w", u.Redacted(), err)
+	}
+	ch, err := conn.Channel()
+	if err == nil {
+		// Only hold the message being scanned, so others go to other
+		// consumers.
+		err = ch.Qos(1, 0, false)
+	}
+	var deliveries <-chan amqp.Delivery
+	if err == nil {
+		deliveries, err = ch.Consume(queue, "", false, false, false, false, nil)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not consume queue %s: %w", queue, err)
+	}
+	return &amqpSubscription{conn: conn, deliveries: deliveries}, nil
+}
+
+func (s *amqpSubscription) Receive(ctx context.Context) (*message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case d, ok := <-s.deliveries:
+		if !ok {
+			return nil, errors.New("connection closed")
+		}
+		return &message{
+			body:   d.Body,
+			ack:    func(context.Context) error { return d.Ack(false) },
+			nack:   func(context.Context) error { return d.Nack(false, true) },
+			extend: func(context.Context) error { return nil },
+		}, nil
+	}
+}
+
+func (s *amqpSubscription) Close() error { return s.conn.Close() }
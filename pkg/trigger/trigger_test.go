@@ -0,0 +1,145 @@
+package trigger
+
+import (
+	aCtx "context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/decoders"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/engine"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+)
+
+const fakeDetectorKeyword = "fakedetectorkeyword"
+
+type fakeDetector struct{}
+
+func (fakeDetector) FromData(_ aCtx.Context, _ bool, _ []byte) ([]detectors.Result, error) {
+	return []detectors.Result{{DetectorType: detectorspb.DetectorType(-1), Raw: []byte("fake secret")}}, nil
+}
+func (fakeDetector) Keywords() []string             { return []string{fakeDetectorKeyword} }
+func (fakeDetector) Type() detectorspb.DetectorType { return detectorspb.DetectorType(-1) }
+func (fakeDetector) Description() string            { return "" }
+
+// fakeSubscription hands out its messages, then blocks until the context is
+// cancelled once they have all been settled.
+type fakeSubscription struct {
+	messages chan []byte
+	cancel   context.CancelFunc
+
+	mu      sync.Mutex
+	pending int
+	settled []string
+}
+
+func newFakeSubscription(cancel context.CancelFunc, bodies ...string) *fakeSubscription {
+	s := &fakeSubscription{messages: make(chan []byte, len(bodies)), cancel: cancel, pending: len(bodies)}
+	for _, body := range bodies {
+		s.messages <- []byte(body)
+	}
+	return s
+}
+
+func (s *fakeSubscription) Receive(ctx context.Context) (*message, error) {
+	select {
+	case body := <-s.messages:
+		settle := func(outcome string) func(context.Context) error {
+			return func(context.Context) error {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				s.settled = append(s.settled, outcome)
+				if s.pending--; s.pending == 0 {
+					s.cancel()
+				}
+				return nil
+			}
+		}
+		return &message{
+			body:   body,
+			ack:    settle("ack"),
+			nack:   settle("nack"),
+			extend: func(context.Context) error { return nil },
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeSubscription) Close() error { return nil }
+
+type recordingPrinter struct {
+	err error
+
+	mu      sync.Mutex
+	results []string
+}
+
+func (p *recordingPrinter) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results = append(p.results, string(r.Raw))
+	return p.err
+}
+
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "creds.txt"), []byte(fakeDetectorKeyword), 0o644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "creds.txt"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "add creds"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return dir
+}
+
+func consume(t *testing.T, printer *recordingPrinter, bodies ...string) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := newFakeSubscription(cancel, bodies...)
+	cfg := engine.Config{
+		Concurrency: 1,
+		Decoders:    decoders.DefaultDecoders(),
+		Detectors:   []detectors.Detector{fakeDetector{}},
+		Dispatcher:  engine.NewPrinterDispatcher(printer),
+	}
+	require.NoError(t, newConsumer(cfg, "fake://queue", sub).Run(ctx))
+	return sub.settled
+}
+
+func TestConsumer(t *testing.T) {
+	repo := "file://" + gitRepo(t)
+
+	printer := new(recordingPrinter)
+	settled := consume(t, printer,
+		`{"repository": "`+repo+`", "requester": "ci"}`,
+		`not json`,
+		`{"requester": "ci"}`,
+	)
+	// Invalid requests are dropped rather than retried forever.
+	assert.Equal(t, []string{"ack", "ack", "ack"}, settled)
+	assert.Equal(t, []string{"fake secret"}, printer.results)
+
+	// A scan whose findings weren't delivered is retried.
+	settled = consume(t, &recordingPrinter{err: errors.New("webhook down")}, `{"repository": "`+repo+`"}`)
+	assert.Equal(t, []string{"nack"}, settled)
+
+	// So is a scan that failed.
+	settled = consume(t, new(recordingPrinter), `{"repository": "file://`+filepath.Join(t.TempDir(), "missing")+`"}`)
+	assert.Equal(t, []string{"nack"}, settled)
+}
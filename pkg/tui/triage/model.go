@@ -0,0 +1,354 @@
+package triage
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/tui/styles"
+)
+
+type (
+	findingMsg  Finding
+	scanDoneMsg struct{}
+	openedMsg   struct{ err error }
+)
+
+// verificationFilter selects the findings shown by their verification state.
+type verificationFilter int
+
+const (
+	showAll verificationFilter = iota
+	showVerified
+	showUnknown
+	showUnverified
+)
+
+func (f verificationFilter) String() string {
+	switch f {
+	case showVerified:
+		return "verified"
+	case showUnknown:
+		return "unknown"
+	case showUnverified:
+		return "unverified"
+	default:
+		return "all"
+	}
+}
+
+func (f verificationFilter) matches(finding Finding) bool {
+	switch f {
+	case showVerified:
+		return finding.Verified
+	case showUnknown:
+		return !finding.Verified && finding.VerificationError != ""
+	case showUnverified:
+		return !finding.Verified && finding.VerificationError == ""
+	default:
+		return true
+	}
+}
+
+type keyMap struct {
+	Up, Down, Open, FalsePositive, Confirm, Reset, Verification, Detector, Quit key.Binding
+}
+
+var keys = keyMap{
+	Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Open:          key.NewBinding(key.WithKeys("o", "enter"), key.WithHelp("o", "open link")),
+	FalsePositive: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "false positive")),
+	Confirm:       key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "confirm")),
+	Reset:         key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "untriage")),
+	Verification:  key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "filter verification")),
+	Detector:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "filter detector")),
+	Quit:          key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+var (
+	verifiedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color(styles.Colors["sprout"]))
+	unknownStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color(styles.Colors["gold"]))
+	falseStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color(styles.Colors["smoke"])).Strikethrough(true)
+	confirmedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(styles.Colors["coral"])).Bold(true)
+	selectedStyle   = lipgloss.NewStyle().Background(lipgloss.Color(styles.Colors["charcoal"]))
+	statusLineStyle = styles.HintTextStyle
+)
+
+// model is the bubbletea model of the triage UI.
+type model struct {
+	findings []Finding
+	// visible holds the indexes of the findings that pass the filters.
+	visible []int
+	cursor  int
+
+	verification verificationFilter
+	detectors    []string
+	// detector is empty when every detector is shown.
+	detector string
+
+	scanDone bool
+	status   string
+	width    int
+	height   int
+
+	// open opens a link in the browser.
+	open func(link string) error
+}
+
+func newModel() *model {
+	return &model{open: openLink}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case findingMsg:
+		m.add(Finding(msg))
+	case scanDoneMsg:
+		m.scanDone = true
+	case openedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("could not open link: %v", msg.err)
+		}
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return tea.Quit
+	case key.Matches(msg, keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, keys.Open):
+		f := m.selected()
+		if f == nil {
+			return nil
+		}
+		if f.Link == "" {
+			m.status = "this finding has no link"
+			return nil
+		}
+		link, open := f.Link, m.open
+		m.status = "opening " + link
+		return func() tea.Msg { return openedMsg{err: open(link)} }
+	case key.Matches(msg, keys.FalsePositive):
+		m.decide(FalsePositive)
+	case key.Matches(msg, keys.Confirm):
+		m.decide(Confirmed)
+	case key.Matches(msg, keys.Reset):
+		m.decide(Untriaged)
+	case key.Matches(msg, keys.Verification):
+		m.verification = (m.verification + 1) % (showUnverified + 1)
+		m.filter()
+	case key.Matches(msg, keys.Detector):
+		m.nextDetector()
+		m.filter()
+	}
+	return nil
+}
+
+func (m *model) add(f Finding) {
+	m.findings = append(m.findings, f)
+	i := sort.SearchStrings(m.detectors, f.Detector)
+	if i == len(m.detectors) || m.detectors[i] != f.Detector {
+		m.detectors = append(m.detectors, "")
+		copy(m.detectors[i+1:], m.detectors[i:])
+		m.detectors[i] = f.Detector
+	}
+	if m.shows(f) {
+		m.visible = append(m.visible, len(m.findings)-1)
+	}
+}
+
+func (m *model) shows(f Finding) bool {
+	return m.verification.matches(f) && (m.detector == "" || m.detector == f.Detector)
+}
+
+// filter recomputes the visible findings, keeping the cursor on the selected
+// finding when it is still shown.
+func (m *model) filter() {
+	selected := -1
+	if m.cursor < len(m.visible) {
+		selected = m.visible[m.cursor]
+	}
+	m.visible = m.visible[:0]
+	m.cursor = 0
+	for i, f := range m.findings {
+		if !m.shows(f) {
+			continue
+		}
+		if i <= selected {
+			m.cursor = len(m.visible)
+		}
+		m.visible = append(m.visible, i)
+	}
+}
+
+// nextDetector cycles the detector filter through every detector seen so
+// far, and back to showing all of them.
+func (m *model) nextDetector() {
+	if m.detector == "" {
+		if len(m.detectors) > 0 {
+			m.detector = m.detectors[0]
+		}
+		return
+	}
+	i := sort.SearchStrings(m.detectors, m.detector)
+	if i+1 < len(m.detectors) {
+		m.detector = m.detectors[i+1]
+	} else {
+		m.detector = ""
+	}
+}
+
+func (m *model) selected() *Finding {
+	if m.cursor >= len(m.visible) {
+		return nil
+	}
+	return &m.findings[m.visible[m.cursor]]
+}
+
+func (m *model) decide(d Decision) {
+	if f := m.selected(); f != nil {
+		f.Decision = d
+	}
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+
+	state := "scanning…"
+	if m.scanDone {
+		state = "scan finished"
+	}
+	counts := make(map[Decision]int)
+	for _, f := range m.findings {
+		counts[f.Decision]++
+	}
+	fmt.Fprintf(&b, "%s  %d findings, %d confirmed, %d false positives  %s\n",
+		styles.BoldTextStyle.Render("TruffleHog triage"), len(m.findings), counts[Confirmed], counts[FalsePositive],
+		statusLineStyle.Render(state))
+	detector := m.detector
+	if detector == "" {
+		detector = "all"
+	}
+	fmt.Fprintf(&b, "%s\n\n", statusLineStyle.Render(fmt.Sprintf(
+		"verification: %s  detector: %s  showing %d", m.verification, detector, len(m.visible))))
+
+	// Leave room for the header, the details of the selected finding and
+	// the help.
+	rows := m.height - 11
+	if rows < 3 {
+		rows = 10
+	}
+	start := 0
+	if m.cursor >= rows {
+		start = m.cursor - rows + 1
+	}
+	end := min(start+rows, len(m.visible))
+	if len(m.visible) == 0 {
+		b.WriteString(statusLineStyle.Render("  no findings to show") + "\n")
+	}
+	for i := start; i < end; i++ {
+		b.WriteString(m.row(m.findings[m.visible[i]], i == m.cursor) + "\n")
+	}
+
+	b.WriteString("\n")
+	if f := m.selected(); f != nil {
+		fmt.Fprintf(&b, "%s %s\n", styles.BoldTextStyle.Render("Location:"), f.Location)
+		if f.Link != "" {
+			fmt.Fprintf(&b, "%s %s\n", styles.BoldTextStyle.Render("Link:"), f.Link)
+		}
+		if f.VerificationError != "" {
+			fmt.Fprintf(&b, "%s %s\n", styles.BoldTextStyle.Render("Verification error:"), f.VerificationError)
+		}
+		fmt.Fprintf(&b, "%s %s\n", styles.BoldTextStyle.Render("Fingerprint:"), f.Fingerprint)
+	}
+	if m.status != "" {
+		b.WriteString(statusLineStyle.Render(m.status) + "\n")
+	}
+
+	var help []string
+	for _, k := range []key.Binding{keys.Up, keys.Down, keys.Open, keys.FalsePositive, keys.Confirm, keys.Reset, keys.Verification, keys.Detector, keys.Quit} {
+		help = append(help, k.Help().Key+" "+k.Help().Desc)
+	}
+	b.WriteString("\n" + statusLineStyle.Render(strings.Join(help, " • ")))
+	return styles.AppStyle.Render(b.String())
+}
+
+func (m *model) row(f Finding, selected bool) string {
+	state := "   "
+	switch {
+	case f.Verified:
+		state = verifiedStyle.Render(" ✓ ")
+	case f.VerificationError != "":
+		state = unknownStyle.Render(" ? ")
+	}
+	line := fmt.Sprintf("%-20s %-24s %s", truncate(f.Detector, 20), truncate(f.Preview, 24), f.Location)
+	if m.width > 0 {
+		line = truncate(line, m.width-12)
+	}
+	switch f.Decision {
+	case FalsePositive:
+		line = falseStyle.Render(line)
+	case Confirmed:
+		line = confirmedStyle.Render(line)
+	}
+	cursor := "  "
+	if selected {
+		cursor = "> "
+		line = selectedStyle.Render(line)
+	}
+	return cursor + state + line
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// openLink opens link with the default handler of the system.
+func openLink(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,118 @@
+package triage
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func press(m *model, keys string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, r := range keys {
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return cmd
+}
+
+func testModel(t *testing.T) *model {
+	t.Helper()
+	m := newModel()
+	for _, f := range []Finding{
+		{Fingerprint: "1", Detector: "Slack", Verified: true, Location: "a.txt:1", Link: "https://example.com/a"},
+		{Fingerprint: "2", Detector: "AWS", Location: "b.txt:2"},
+		{Fingerprint: "3", Detector: "AWS", Verified: true, Location: "c.txt:3"},
+		{Fingerprint: "4", Detector: "Github", VerificationError: "timeout", Location: "d.txt:4"},
+	} {
+		m.Update(findingMsg(f))
+	}
+	require.Len(t, m.visible, 4)
+	return m
+}
+
+func TestModel_Decisions(t *testing.T) {
+	m := testModel(t)
+
+	press(m, "fjcjjfu")
+	assert.Equal(t, FalsePositive, m.findings[0].Decision)
+	assert.Equal(t, Confirmed, m.findings[1].Decision)
+	assert.Equal(t, Untriaged, m.findings[2].Decision)
+	assert.Equal(t, Untriaged, m.findings[3].Decision)
+	assert.Equal(t, 3, m.cursor)
+
+	press(m, "jjj")
+	assert.Equal(t, 3, m.cursor, "cursor should stop at the last finding")
+	press(m, "kkkkk")
+	assert.Equal(t, 0, m.cursor)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd())
+}
+
+func TestModel_Filters(t *testing.T) {
+	m := testModel(t)
+	shown := func() []string {
+		var fps []string
+		for _, i := range m.visible {
+			fps = append(fps, m.findings[i].Fingerprint)
+		}
+		return fps
+	}
+
+	press(m, "jj")
+	press(m, "v")
+	assert.Equal(t, verificationFilter(showVerified), m.verification)
+	assert.Equal(t, []string{"1", "3"}, shown())
+	assert.Equal(t, "3", m.selected().Fingerprint, "selection should be kept")
+
+	press(m, "v")
+	assert.Equal(t, []string{"4"}, shown())
+	press(m, "v")
+	assert.Equal(t, []string{"2"}, shown())
+	press(m, "v")
+	assert.Len(t, shown(), 4)
+
+	press(m, "d")
+	assert.Equal(t, "AWS", m.detector)
+	assert.Equal(t, []string{"2", "3"}, shown())
+
+	m.Update(findingMsg(Finding{Fingerprint: "5", Detector: "AWS"}))
+	m.Update(findingMsg(Finding{Fingerprint: "6", Detector: "Slack"}))
+	assert.Equal(t, []string{"2", "3", "5"}, shown(), "new findings should be filtered too")
+
+	press(m, "ddd")
+	assert.Equal(t, "", m.detector)
+	assert.Len(t, shown(), 6)
+}
+
+func TestModel_Open(t *testing.T) {
+	m := testModel(t)
+	var opened []string
+	m.open = func(link string) error {
+		opened = append(opened, link)
+		return nil
+	}
+
+	cmd := press(m, "o")
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	assert.Equal(t, []string{"https://example.com/a"}, opened)
+
+	assert.Nil(t, press(m, "jo"))
+	assert.Equal(t, "this finding has no link", m.status)
+}
+
+func TestModel_View(t *testing.T) {
+	m := testModel(t)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	press(m, "f")
+	m.Update(scanDoneMsg{})
+
+	view := m.View()
+	assert.Contains(t, view, "4 findings, 0 confirmed, 1 false positives")
+	assert.Contains(t, view, "scan finished")
+	assert.Contains(t, view, "https://example.com/a")
+	assert.Contains(t, view, "d.txt:4")
+}
@@ -0,0 +1,158 @@
+// Package triage implements a terminal UI that lists findings as a scan
+// reports them, and lets the user open their locations, mark them as false
+// positives or confirmed, and filter them. The decisions are written out once
+// the user quits.
+package triage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/context"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+)
+
+// Decision is the outcome of triaging a finding.
+type Decision string
+
+const (
+	Untriaged     Decision = ""
+	Confirmed     Decision = "confirmed"
+	FalsePositive Decision = "false_positive"
+)
+
+// Finding is a result as it is shown and triaged. It never holds the secret
+// itself.
+type Finding struct {
+	Fingerprint       string   `json:"fingerprint"`
+	Detector          string   `json:"detector"`
+	Verified          bool     `json:"verified"`
+	VerificationError string   `json:"verification_error,omitempty"`
+	Preview           string   `json:"preview"`
+	Location          string   `json:"location"`
+	Link              string   `json:"link,omitempty"`
+	Decision          Decision `json:"decision,omitempty"`
+}
+
+func newFinding(r *detectors.ResultWithMetadata) Finding {
+	fingerprint := r.Fingerprint
+	if fingerprint == "" {
+		fingerprint = output.Fingerprint(r)
+	}
+	where, link := output.Location(r)
+	f := Finding{
+		Fingerprint: fingerprint,
+		Detector:    r.DetectorType.String(),
+		Verified:    r.Verified,
+		Preview:     output.SecretPreview(r),
+		Location:    where,
+		Link:        link,
+	}
+	if err := r.VerificationError(); err != nil {
+		f.VerificationError = err.Error()
+	}
+	return f
+}
+
+// Session is a printer that shows the results of a scan in the triage UI.
+type Session struct {
+	model   *model
+	program *tea.Program
+}
+
+// New creates a Session. Its UI is shown by Run.
+func New() *Session {
+	m := newModel()
+	return &Session{model: m, program: tea.NewProgram(m, tea.WithAltScreen())}
+}
+
+// Print adds the result to the UI. It blocks until the UI is running, and
+// drops the result once the user has quit.
+func (s *Session) Print(_ context.Context, r *detectors.ResultWithMetadata) error {
+	s.program.Send(findingMsg(newFinding(r)))
+	return nil
+}
+
+// ScanFinished tells the UI that no more results are coming.
+func (s *Session) ScanFinished() {
+	s.program.Send(scanDoneMsg{})
+}
+
+// Run shows the UI until the user quits, and returns the findings with their
+// decisions.
+func (s *Session) Run() ([]Finding, error) {
+	if _, err := s.program.Run(); err != nil {
+		return nil, fmt.Errorf("triage UI failed: %w", err)
+	}
+	return s.model.findings, nil
+}
+
+// WriteDecisions writes the findings and their decisions as indented JSON to
+// path.
+func WriteDecisions(path string, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	out, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal triage decisions: %w", err)
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		return fmt.Errorf("could not write triage decisions: %w", err)
+	}
+	return nil
+}
+
+// AppendFalsePositives appends the fingerprints of the findings marked as
+// false positives to the .trufflehogignore file at path, creating it if
+// needed. Fingerprints the file already suppresses aren't added again. It
+// returns the number of fingerprints added.
+func AppendFalsePositives(path string, findings []Finding) (int, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	ignore, err := ignorefile.Parse(bytes.NewReader(existing))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	var (
+		buf   bytes.Buffer
+		added int
+	)
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	seen := make(map[string]struct{})
+	for _, f := range findings {
+		if f.Decision != FalsePositive || ignore.SuppressesFingerprint(f.Fingerprint) {
+			continue
+		}
+		if _, ok := seen[f.Fingerprint]; ok {
+			continue
+		}
+		seen[f.Fingerprint] = struct{}{}
+		fmt.Fprintf(&buf, "# %s false positive in %s\nfingerprint:%s\n", f.Detector, f.Location, f.Fingerprint)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("could not open %s: %w", path, err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return added, file.Close()
+}
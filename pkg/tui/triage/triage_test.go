@@ -0,0 +1,100 @@
+package triage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trufflesecurity/trufflehog/v3/pkg/detectors"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/ignorefile"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/output"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/detectorspb"
+	"github.com/trufflesecurity/trufflehog/v3/pkg/pb/source_metadatapb"
+)
+
+func TestNewFinding(t *testing.T) {
+	r := &detectors.ResultWithMetadata{
+		SourceMetadata: &source_metadatapb.MetaData{
+			Data: &source_metadatapb.MetaData_Git{Git: &source_metadatapb.Git{
+				Repository: "https://github.com/org/repo.git",
+				Commit:     "c1",
+				File:       "config.yaml",
+				Line:       3,
+			}},
+		},
+		Result: detectors.Result{
+			DetectorType: detectorspb.DetectorType_AWS,
+			Raw:          []byte("AKIAEXAMPLESECRET"),
+			Verified:     true,
+		},
+	}
+
+	f := newFinding(r)
+	assert.Equal(t, output.Fingerprint(r), f.Fingerprint)
+	assert.Equal(t, "AWS", f.Detector)
+	assert.True(t, f.Verified)
+	assert.NotContains(t, f.Preview, "AKIAEXAMPLESECRET")
+	assert.Equal(t, "https://github.com/org/repo.git commit c1 config.yaml:3", f.Location)
+	assert.Equal(t, "https://github.com/org/repo/blob/c1/config.yaml#L3", f.Link)
+}
+
+var triaged = []Finding{
+	{Fingerprint: "aaa", Detector: "AWS", Location: "a.txt:1", Decision: FalsePositive},
+	{Fingerprint: "bbb", Detector: "AWS", Location: "b.txt:1", Decision: Confirmed},
+	{Fingerprint: "ccc", Detector: "Slack", Location: "c.txt:1", Decision: FalsePositive},
+	{Fingerprint: "ccc", Detector: "Slack", Location: "c.txt:1", Decision: FalsePositive},
+	{Fingerprint: "ddd", Detector: "Slack", Location: "d.txt:1"},
+}
+
+func TestAppendFalsePositives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ignorefile.Name)
+	require.NoError(t, os.WriteFile(path, []byte("vendor/\nfingerprint:ccc"), 0o644))
+
+	added, err := AppendFalsePositives(path, triaged)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	added, err = AppendFalsePositives(path, triaged)
+	require.NoError(t, err)
+	assert.Equal(t, 0, added, "fingerprints should only be added once")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "vendor/\nfingerprint:ccc\n# AWS false positive in a.txt:1\nfingerprint:aaa\n", string(data))
+
+	ignore, err := ignorefile.Parse(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.True(t, ignore.SuppressesFingerprint("aaa"))
+	assert.False(t, ignore.SuppressesFingerprint("bbb"))
+}
+
+func TestAppendFalsePositives_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ignorefile.Name)
+
+	added, err := AppendFalsePositives(path, triaged[1:2])
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+	assert.NoFileExists(t, path)
+
+	added, err = AppendFalsePositives(path, triaged)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.FileExists(t, path)
+}
+
+func TestWriteDecisions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.json")
+	require.NoError(t, WriteDecisions(path, triaged[:2]))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got []Finding
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, triaged[:2], got)
+	assert.Contains(t, string(data), `"decision": "false_positive"`)
+}